@@ -5,18 +5,32 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
+	"time"
 
 	"log/slog"
 
 	"github.com/ezmobilemechanic/platform/internal/config"
 	"github.com/ezmobilemechanic/platform/internal/database"
 	"github.com/ezmobilemechanic/platform/internal/domain"
+	"github.com/ezmobilemechanic/platform/internal/geo"
 	"github.com/ezmobilemechanic/platform/internal/httpapi"
+	"github.com/ezmobilemechanic/platform/internal/jobs"
 	"github.com/ezmobilemechanic/platform/internal/logger"
+	"github.com/ezmobilemechanic/platform/internal/maintenance"
+	"github.com/ezmobilemechanic/platform/internal/messaging"
+	"github.com/ezmobilemechanic/platform/internal/pii"
+	"github.com/ezmobilemechanic/platform/internal/pricing"
+	"github.com/ezmobilemechanic/platform/internal/recalls"
 	"github.com/ezmobilemechanic/platform/internal/server"
+	"github.com/ezmobilemechanic/platform/internal/specs"
+	"github.com/ezmobilemechanic/platform/internal/storage/cache"
+	"github.com/ezmobilemechanic/platform/internal/storage/filestore"
 	"github.com/ezmobilemechanic/platform/internal/storage/memory"
 	pgstorage "github.com/ezmobilemechanic/platform/internal/storage/postgres"
+	sqlitestorage "github.com/ezmobilemechanic/platform/internal/storage/sqlite"
 )
 
 func main() {
@@ -31,16 +45,13 @@ func main() {
 	baseCtx := context.Background()
 
 	var db *database.DB
-	if cfg.DataBackend == "postgres" {
-		db, err = database.Connect(baseCtx, database.Options{
-			Driver:          cfg.DatabaseDriver,
-			DSN:             cfg.DatabaseURL,
-			MaxOpenConns:    cfg.DBMaxOpenConns,
-			MaxIdleConns:    cfg.DBMaxIdleConns,
-			ConnMaxLifetime: cfg.DBConnMaxLifetime,
-			ConnMaxIdleTime: cfg.DBConnMaxIdleTime,
-			Logger:          logr,
-		})
+	if cfg.DataBackend == "postgres" || cfg.DataBackend == "sqlite" {
+		opts, err := databaseOptions(cfg, logr)
+		if err != nil {
+			logr.Error("invalid database configuration", "err", err)
+			os.Exit(1)
+		}
+		db, err = database.Connect(baseCtx, opts)
 		if err != nil {
 			logr.Error("failed to connect database", "err", err)
 			os.Exit(1)
@@ -51,23 +62,127 @@ func main() {
 			}
 		}()
 
-		migrator := database.NewSQLMigrator(db.DB, database.MigrationsFS(), "db/migrations", logr)
+		var migrator database.Migrator = database.NewPostgresMigrator(db.DB, logr)
+		if cfg.DataBackend == "sqlite" {
+			migrator = sqlitestorage.NewMigrator(db.DB, logr)
+		}
 		if err := db.RunMigrations(baseCtx, migrator); err != nil {
 			logr.Error("database migrations failed", "err", err)
 			os.Exit(1)
 		}
 	}
 
-	domainContainer, err := buildDomainContainer(cfg, logr, db)
+	keys, err := newKeyring(cfg, logr)
+	if err != nil {
+		logr.Error("failed to load pii keys", "err", err)
+		os.Exit(1)
+	}
+
+	repos := newMemoryRepositories()
+	if cfg.DataBackend == "file" {
+		store, err := filestore.Open(filestore.Options{
+			Dir:              cfg.DataDir,
+			SnapshotInterval: cfg.SnapshotInterval,
+			Logger:           logr,
+		}, repos.customers, repos.customers.Merges(), repos.customers.Addresses(), repos.customers.Consents(), repos.customers.Notes(),
+			repos.customers.Contacts(), repos.vehicles, repos.vehicles.Transfers(), repos.vehicles.Odometer(), repos.vehicles.Recalls(), repos.quotes, repos.quotes.History(),
+			repos.users, repos.erasures)
+		if err != nil {
+			logr.Error("failed to open file store", "err", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if cerr := store.Close(); cerr != nil {
+				logr.Error("error closing file store", "err", cerr)
+			}
+		}()
+	}
+
+	repoOpts, err := buildRepositories(cfg, logr, db, repos, keys)
 	if err != nil {
-		logr.Error("failed to init domain container", "err", err)
+		logr.Error("failed to init repositories", "err", err)
 		os.Exit(1)
 	}
 
+	// The memory and file backends already serve reads from process memory.
+	if db != nil && cfg.CacheTTL > 0 {
+		store, closeStore, err := newCacheStore(cfg, logr)
+		if err != nil {
+			logr.Error("failed to init cache", "err", err)
+			os.Exit(1)
+		}
+		defer closeStore()
+		repoOpts = withCache(repoOpts, cache.New(store, cfg.CacheTTL, logr))
+	}
+
+	repoOpts.PhoneRegion = cfg.PhoneRegion
+	repoOpts.Sender = messaging.LogSender{Logger: logr}
+	repoOpts.Geocoder, err = newGeocoder(cfg, logr)
+	if err != nil {
+		logr.Error("failed to init geocoder", "err", err)
+		os.Exit(1)
+	}
+	repoOpts.Schedule, err = loadSchedule(cfg, logr)
+	if err != nil {
+		logr.Error("failed to load maintenance rules", "err", err)
+		os.Exit(1)
+	}
+	repoOpts.Specs, err = loadSpecs(cfg, logr)
+	if err != nil {
+		logr.Error("failed to load vehicle specs", "err", err)
+		os.Exit(1)
+	}
+	repoOpts.Recalls, err = loadRecalls(cfg, logr)
+	if err != nil {
+		logr.Error("failed to load recalls", "err", err)
+		os.Exit(1)
+	}
+	repoOpts.Rates, err = loadRates(cfg, logr)
+	if err != nil {
+		logr.Error("failed to load labor rates", "err", err)
+		os.Exit(1)
+	}
+	domainContainer := domain.New(repoOpts)
+
 	srv := server.New(cfg, logr)
 
 	httpapi.Register(srv.Mux(), logr, domainContainer)
 
+	jobsCtx, stopJobs := context.WithCancel(baseCtx)
+	defer stopJobs()
+	if cfg.PurgeInterval > 0 {
+		go jobs.Every(jobsCtx, "customer-purge", cfg.PurgeInterval, logr, func(context.Context) error {
+			n, err := domainContainer.Customers.PurgeDeleted(cfg.CustomerRetention)
+			if n > 0 {
+				logr.Info("purged deleted customers", "count", n)
+			}
+			return err
+		})
+	}
+
+	if cfg.ReminderInterval > 0 {
+		go jobs.Every(jobsCtx, "maintenance-reminders", cfg.ReminderInterval, logr, func(context.Context) error {
+			reminders, err := domainContainer.Maintenance.Reminders(time.Now())
+			if err != nil {
+				return err
+			}
+			for _, r := range reminders {
+				services := make([]string, len(r.Items))
+				for i, item := range r.Items {
+					services[i] = item.Service + ":" + string(item.Status)
+				}
+				logr.Info("maintenance_reminder",
+					"customer_id", r.CustomerID,
+					"vehicle_id", r.VehicleID,
+					"rules_version", r.RulesVersion,
+					"services", strings.Join(services, ","),
+				)
+			}
+			logr.Info("maintenance reminder candidates", "count", len(reminders))
+			return nil
+		})
+	}
+
 	go func() {
 		if err := srv.Run(); err != nil {
 			logr.Error("server error", "err", err)
@@ -79,6 +194,8 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
+	stopJobs()
+
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer cancel()
 
@@ -88,29 +205,254 @@ func main() {
 	}
 }
 
-func buildDomainContainer(cfg config.Config, logr *slog.Logger, db *database.DB) (domain.Container, error) {
+// databaseOptions returns connection settings for the SQL backends. SQLite is
+// limited to a single connection: writes are serialized by the database
+// anyway, and it keeps per-connection pragmas in force.
+func databaseOptions(cfg config.Config, logr *slog.Logger) (database.Options, error) {
+	if cfg.DataBackend == "sqlite" {
+		if err := sqlitestorage.CheckDriver(cfg.DatabaseDriver); err != nil {
+			return database.Options{}, err
+		}
+		if err := os.MkdirAll(filepath.Dir(cfg.DatabaseURL), 0o755); err != nil {
+			return database.Options{}, fmt.Errorf("create sqlite dir: %w", err)
+		}
+		return database.Options{
+			Driver:       cfg.DatabaseDriver,
+			DSN:          sqlitestorage.DSN(cfg.DatabaseURL),
+			MaxOpenConns: 1,
+			MaxIdleConns: 1,
+			Logger:       logr,
+		}, nil
+	}
+	return database.Options{
+		Driver:          cfg.DatabaseDriver,
+		DSN:             cfg.DatabaseURL,
+		MaxOpenConns:    cfg.DBMaxOpenConns,
+		MaxIdleConns:    cfg.DBMaxIdleConns,
+		ConnMaxLifetime: cfg.DBConnMaxLifetime,
+		ConnMaxIdleTime: cfg.DBConnMaxIdleTime,
+		Logger:          logr,
+	}, nil
+}
+
+// newCacheStore returns the shared Redis store when REDIS_URL is set and an
+// in-process LRU otherwise.
+func newCacheStore(cfg config.Config, logr *slog.Logger) (cache.Store, func(), error) {
+	if cfg.RedisURL == "" {
+		logr.Info("using in-process cache", "size", cfg.CacheSize, "ttl", cfg.CacheTTL)
+		return cache.NewLRU(cfg.CacheSize), func() {}, nil
+	}
+
+	store, err := cache.NewRedis(cfg.RedisURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := store.Ping(); err != nil {
+		return nil, nil, fmt.Errorf("redis ping: %w", err)
+	}
+	logr.Info("using redis cache", "ttl", cfg.CacheTTL)
+	return store, func() { store.Close() }, nil
+}
+
+// newGeocoder returns the geocoder selected by GEOCODER.
+func newGeocoder(cfg config.Config, logr *slog.Logger) (geo.Geocoder, error) {
+	if cfg.Geocoder != "zip" {
+		return geo.Noop{}, nil
+	}
+	z, err := geo.LoadZIPCentroids(cfg.ZIPCentroidsFile)
+	if err != nil {
+		return nil, err
+	}
+	logr.Info("using zip centroid geocoder", "file", cfg.ZIPCentroidsFile, "zips", z.Len())
+	return z, nil
+}
+
+// loadSchedule reads the maintenance rules file, if configured. Without one
+// the domain falls back to the built-in schedule.
+func loadSchedule(cfg config.Config, logr *slog.Logger) (*maintenance.Schedule, error) {
+	if cfg.MaintenanceRulesFile == "" {
+		return nil, nil
+	}
+	s, err := maintenance.LoadFile(cfg.MaintenanceRulesFile)
+	if err != nil {
+		return nil, err
+	}
+	logr.Info("using maintenance rules", "file", cfg.MaintenanceRulesFile, "version", s.Version, "rules", len(s.Rules))
+	return &s, nil
+}
+
+// loadSpecs reads the vehicle spec catalog, if configured. Without one no
+// vehicle has specs.
+func loadSpecs(cfg config.Config, logr *slog.Logger) (*specs.Catalog, error) {
+	if cfg.SpecCatalogFile == "" {
+		return nil, nil
+	}
+	c, err := specs.LoadFile(cfg.SpecCatalogFile)
+	if err != nil {
+		return nil, err
+	}
+	logr.Info("using vehicle specs", "file", cfg.SpecCatalogFile, "version", c.Version, "specs", len(c.Specs))
+	return &c, nil
+}
+
+// loadRecalls reads the NHTSA recall and service bulletin datasets, if
+// configured. Without them no vehicle has recalls.
+func loadRecalls(cfg config.Config, logr *slog.Logger) (*recalls.Dataset, error) {
+	if cfg.RecallsFile == "" && cfg.TSBsFile == "" {
+		return nil, nil
+	}
+	d := recalls.NewDataset()
+	if cfg.RecallsFile != "" {
+		if err := d.LoadFile(recalls.KindRecall, cfg.RecallsFile); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.TSBsFile != "" {
+		if err := d.LoadFile(recalls.KindTSB, cfg.TSBsFile); err != nil {
+			return nil, err
+		}
+	}
+	n, tsbs := d.Counts()
+	logr.Info("using recall data", "recalls_file", cfg.RecallsFile, "tsbs_file", cfg.TSBsFile, "recalls", n, "tsbs", tsbs)
+	return d, nil
+}
+
+// loadRates reads the labor rate card, if configured. Without one quotes
+// charge for parts only.
+func loadRates(cfg config.Config, logr *slog.Logger) (*pricing.RateCard, error) {
+	if cfg.LaborRatesFile == "" {
+		logr.Warn("no labor rates configured, quotes will not charge for labor")
+		return nil, nil
+	}
+	c, err := pricing.LoadFile(cfg.LaborRatesFile)
+	if err != nil {
+		return nil, err
+	}
+	logr.Info("using labor rates", "file", cfg.LaborRatesFile, "version", c.Version, "default_rate", c.DefaultRate, "rates", len(c.Rates), "fees", len(c.Fees))
+	return &c, nil
+}
+
+// newKeyring loads the PII encryption keys, if configured. Only the SQL
+// backends encrypt; the memory backend holds nothing at rest and the file
+// backend's journal is expected to live on an encrypted volume.
+func newKeyring(cfg config.Config, logr *slog.Logger) (*pii.Keyring, error) {
+	keys, err := pii.Load(cfg.PIIKeys, cfg.PIIIndexKey, cfg.PIIKeyFile)
+	if err != nil || !keys.Enabled() {
+		return nil, err
+	}
+	if cfg.DataBackend != "postgres" && cfg.DataBackend != "sqlite" {
+		logr.Warn("pii keys are ignored by this data backend", "backend", cfg.DataBackend)
+		return nil, nil
+	}
+	logr.Info("encrypting customer pii", "key_version", keys.Version())
+	return keys, nil
+}
+
+// withCache wraps the hot-read repositories in read-through cache decorators.
+func withCache(opts domain.Options, c *cache.Cache) domain.Options {
+	opts.CustomerRepo = cache.NewCustomerRepository(opts.CustomerRepo, c)
+	opts.VehicleRepo = cache.NewVehicleRepository(opts.VehicleRepo, c)
+	opts.QuoteRepo = cache.NewQuoteRepository(opts.QuoteRepo, c)
+	return opts
+}
+
+// memoryRepositories backs both the memory and file backends; the file
+// backend journals and snapshots the same instances.
+type memoryRepositories struct {
+	customers *memory.CustomerRepository
+	vehicles  *memory.VehicleRepository
+	quotes    *memory.QuoteRepository
+	users     *memory.UserRepository
+	erasures  *memory.ErasureRequestRepository
+}
+
+func newMemoryRepositories() memoryRepositories {
+	repos := memoryRepositories{
+		customers: memory.NewCustomerRepository(),
+		vehicles:  memory.NewVehicleRepository(),
+		quotes:    memory.NewQuoteRepository(),
+		users:     memory.NewUserRepository(),
+		erasures:  memory.NewErasureRequestRepository(),
+	}
+	repos.customers.SetVehicles(repos.vehicles)
+	repos.customers.SetQuotes(repos.quotes)
+	return repos
+}
+
+func buildRepositories(cfg config.Config, logr *slog.Logger, db *database.DB, repos memoryRepositories, keys *pii.Keyring) (domain.Options, error) {
 	switch cfg.DataBackend {
-	case "memory":
-		logr.Info("using in-memory repositories (DATA_BACKEND=memory)")
-		return domain.New(domain.Options{
-			CustomerRepo: memory.NewCustomerRepository(),
-			VehicleRepo:  memory.NewVehicleRepository(),
-			QuoteRepo:    memory.NewQuoteRepository(),
-			UserRepo:     memory.NewUserRepository(),
-		}), nil
+	case "memory", "file":
+		logr.Info("using in-memory repositories", "backend", cfg.DataBackend)
+		repos.customers.SetPhoneRegion(cfg.PhoneRegion)
+		return domain.Options{
+			CustomerRepo: repos.customers,
+			AddressRepo:  repos.customers.Addresses(),
+			ConsentRepo:  repos.customers.Consents(),
+			NoteRepo:     repos.customers.Notes(),
+			ContactRepo:  repos.customers.Contacts(),
+			VehicleRepo:  repos.vehicles,
+			OdometerRepo: repos.vehicles.Odometer(),
+			RecallRepo:   repos.vehicles.Recalls(),
+			QuoteRepo:    repos.quotes,
+			QuoteHistory: repos.quotes.History(),
+			UserRepo:     repos.users,
+			ErasureRepo:  repos.erasures,
+		}, nil
+	case "sqlite":
+		if db == nil {
+			return domain.Options{}, fmt.Errorf("sqlite backend requires database connection")
+		}
+		logr.Info("using sqlite repositories (DATA_BACKEND=sqlite)", "path", cfg.DatabaseURL)
+		sqlDB := db.DB
+		customerRepo := sqlitestorage.NewCustomerRepository(sqlDB)
+		customerRepo.SetKeyring(keys)
+		customerRepo.SetPhoneRegion(cfg.PhoneRegion)
+		addressRepo := sqlitestorage.NewCustomerAddressRepository(sqlDB)
+		addressRepo.SetKeyring(keys)
+		contactRepo := sqlitestorage.NewFleetContactRepository(sqlDB)
+		contactRepo.SetKeyring(keys)
+		return domain.Options{
+			CustomerRepo: customerRepo,
+			AddressRepo:  addressRepo,
+			ConsentRepo:  sqlitestorage.NewCustomerConsentRepository(sqlDB),
+			NoteRepo:     sqlitestorage.NewCustomerNoteRepository(sqlDB),
+			ContactRepo:  contactRepo,
+			VehicleRepo:  sqlitestorage.NewVehicleRepository(sqlDB),
+			OdometerRepo: sqlitestorage.NewOdometerRepository(sqlDB),
+			RecallRepo:   sqlitestorage.NewRecallMarkRepository(sqlDB),
+			QuoteRepo:    sqlitestorage.NewQuoteRepository(sqlDB),
+			QuoteHistory: sqlitestorage.NewQuoteStatusLog(sqlDB),
+			UserRepo:     sqlitestorage.NewUserRepository(sqlDB),
+			ErasureRepo:  sqlitestorage.NewErasureRequestRepository(sqlDB),
+		}, nil
 	case "postgres":
 		if db == nil {
-			return domain.Container{}, fmt.Errorf("postgres backend requires database connection")
+			return domain.Options{}, fmt.Errorf("postgres backend requires database connection")
 		}
 		logr.Info("using postgres repositories (DATA_BACKEND=postgres)")
 		sqlDB := db.DB
-		return domain.New(domain.Options{
-			CustomerRepo: pgstorage.NewCustomerRepository(sqlDB),
+		customerRepo := pgstorage.NewCustomerRepository(sqlDB)
+		customerRepo.SetKeyring(keys)
+		customerRepo.SetPhoneRegion(cfg.PhoneRegion)
+		addressRepo := pgstorage.NewCustomerAddressRepository(sqlDB)
+		addressRepo.SetKeyring(keys)
+		contactRepo := pgstorage.NewFleetContactRepository(sqlDB)
+		contactRepo.SetKeyring(keys)
+		return domain.Options{
+			CustomerRepo: customerRepo,
+			AddressRepo:  addressRepo,
+			ConsentRepo:  pgstorage.NewCustomerConsentRepository(sqlDB),
+			NoteRepo:     pgstorage.NewCustomerNoteRepository(sqlDB),
+			ContactRepo:  contactRepo,
 			VehicleRepo:  pgstorage.NewVehicleRepository(sqlDB),
+			OdometerRepo: pgstorage.NewOdometerRepository(sqlDB),
+			RecallRepo:   pgstorage.NewRecallMarkRepository(sqlDB),
 			QuoteRepo:    pgstorage.NewQuoteRepository(sqlDB),
+			QuoteHistory: pgstorage.NewQuoteStatusLog(sqlDB),
 			UserRepo:     pgstorage.NewUserRepository(sqlDB),
-		}), nil
+			ErasureRepo:  pgstorage.NewErasureRequestRepository(sqlDB),
+		}, nil
 	default:
-		return domain.Container{}, fmt.Errorf("unsupported data backend: %s", cfg.DataBackend)
+		return domain.Options{}, fmt.Errorf("unsupported data backend: %s", cfg.DataBackend)
 	}
 }
@@ -0,0 +1,140 @@
+// Command encrypt-pii encrypts customer PII written before encryption was
+// enabled, and re-encrypts values sealed with older key versions after a key
+// rotation. It reads the same configuration as the API and is safe to run
+// while the API is serving.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ezmobilemechanic/platform/internal/config"
+	"github.com/ezmobilemechanic/platform/internal/database"
+	"github.com/ezmobilemechanic/platform/internal/logger"
+	"github.com/ezmobilemechanic/platform/internal/pii"
+	pgstorage "github.com/ezmobilemechanic/platform/internal/storage/postgres"
+	sqlitestorage "github.com/ezmobilemechanic/platform/internal/storage/sqlite"
+)
+
+// reencrypter is implemented by the SQL customer, address and contact
+// repositories.
+type reencrypter interface {
+	Reencrypt(batchSize int) (int, error)
+}
+
+func main() {
+	generate := flag.Bool("generate-key", false, "print a new random key and exit")
+	batchSize := flag.Int("batch", 500, "rows read per batch")
+	flag.Parse()
+
+	if *generate {
+		key, err := pii.GenerateKey()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "generate key:", err)
+			os.Exit(1)
+		}
+		fmt.Println(key)
+		return
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog := logger.New("development")
+		slog.Error("failed to load config", "err", err)
+		os.Exit(1)
+	}
+
+	logr := logger.New(cfg.Env)
+
+	keys, err := pii.Load(cfg.PIIKeys, cfg.PIIIndexKey, cfg.PIIKeyFile)
+	if err != nil {
+		logr.Error("failed to load pii keys", "err", err)
+		os.Exit(1)
+	}
+	if !keys.Enabled() {
+		logr.Error("encrypt-pii requires PII_KEYS and PII_INDEX_KEY, or PII_KEY_FILE")
+		os.Exit(1)
+	}
+	if *batchSize <= 0 {
+		logr.Error("batch must be positive")
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+
+	var (
+		opts database.Options
+		dsn  = cfg.DatabaseURL
+	)
+	switch cfg.DataBackend {
+	case "postgres":
+		opts = database.Options{Driver: cfg.DatabaseDriver, DSN: dsn, MaxOpenConns: 1, MaxIdleConns: 1, Logger: logr}
+	case "sqlite":
+		if err := sqlitestorage.CheckDriver(cfg.DatabaseDriver); err != nil {
+			logr.Error("cannot open sqlite database", "err", err)
+			os.Exit(1)
+		}
+		opts = database.Options{Driver: cfg.DatabaseDriver, DSN: sqlitestorage.DSN(dsn), MaxOpenConns: 1, MaxIdleConns: 1, Logger: logr}
+	default:
+		logr.Error("encrypt-pii requires DATA_BACKEND=postgres or sqlite", "backend", cfg.DataBackend)
+		os.Exit(1)
+	}
+
+	db, err := database.Connect(ctx, opts)
+	if err != nil {
+		logr.Error("failed to connect database", "err", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	var migrator database.Migrator = database.NewPostgresMigrator(db.DB, logr)
+	if cfg.DataBackend == "sqlite" {
+		migrator = sqlitestorage.NewMigrator(db.DB, logr)
+	}
+	if err := db.RunMigrations(ctx, migrator); err != nil {
+		logr.Error("migrations failed", "err", err)
+		os.Exit(1)
+	}
+
+	var customerRepo, addressRepo, contactRepo reencrypter
+	if cfg.DataBackend == "sqlite" {
+		c := sqlitestorage.NewCustomerRepository(db.DB)
+		c.SetKeyring(keys)
+		a := sqlitestorage.NewCustomerAddressRepository(db.DB)
+		a.SetKeyring(keys)
+		f := sqlitestorage.NewFleetContactRepository(db.DB)
+		f.SetKeyring(keys)
+		customerRepo, addressRepo, contactRepo = c, a, f
+	} else {
+		c := pgstorage.NewCustomerRepository(db.DB)
+		c.SetKeyring(keys)
+		a := pgstorage.NewCustomerAddressRepository(db.DB)
+		a.SetKeyring(keys)
+		f := pgstorage.NewFleetContactRepository(db.DB)
+		f.SetKeyring(keys)
+		customerRepo, addressRepo, contactRepo = c, a, f
+	}
+
+	n, err := customerRepo.Reencrypt(*batchSize)
+	if err != nil {
+		logr.Error("failed to encrypt customers", "rewritten", n, "err", err)
+		os.Exit(1)
+	}
+	logr.Info("encrypted customers", "rewritten", n, "key_version", keys.Version())
+
+	n, err = addressRepo.Reencrypt(*batchSize)
+	if err != nil {
+		logr.Error("failed to encrypt addresses", "rewritten", n, "err", err)
+		os.Exit(1)
+	}
+	logr.Info("encrypted addresses", "rewritten", n, "key_version", keys.Version())
+
+	n, err = contactRepo.Reencrypt(*batchSize)
+	if err != nil {
+		logr.Error("failed to encrypt contacts", "rewritten", n, "err", err)
+		os.Exit(1)
+	}
+	logr.Info("encrypted contacts", "rewritten", n, "key_version", keys.Version())
+}
@@ -11,6 +11,7 @@ import (
 	domainquotes "github.com/ezmobilemechanic/platform/internal/domain/quotes"
 	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
 	"github.com/ezmobilemechanic/platform/internal/logger"
+	"github.com/ezmobilemechanic/platform/internal/pii"
 	pgstorage "github.com/ezmobilemechanic/platform/internal/storage/postgres"
 )
 
@@ -46,24 +47,37 @@ func main() {
 	}
 	defer db.Close()
 
-	migrator := database.NewSQLMigrator(db.DB, database.MigrationsFS(), "db/migrations", logr)
+	migrator := database.NewPostgresMigrator(db.DB, logr)
 	if err := db.RunMigrations(ctx, migrator); err != nil {
 		logr.Error("migrations failed", "err", err)
 		os.Exit(1)
 	}
 
+	keys, err := pii.Load(cfg.PIIKeys, cfg.PIIIndexKey, cfg.PIIKeyFile)
+	if err != nil {
+		logr.Error("failed to load pii keys", "err", err)
+		os.Exit(1)
+	}
+
 	custRepo := pgstorage.NewCustomerRepository(db.DB)
+	custRepo.SetKeyring(keys)
+	custRepo.SetPhoneRegion(cfg.PhoneRegion)
 	vehicleRepo := pgstorage.NewVehicleRepository(db.DB)
 	quoteRepo := pgstorage.NewQuoteRepository(db.DB)
 
-	sampleCustomers := []customers.Customer{
-		{FirstName: "Alex", LastName: "Driver", Email: "alex@example.com", Phone: "904-555-0101", MarketingOpt: true},
+	// Customers go through the service so phone numbers are stored normalized.
+	customerService := customers.NewService(custRepo,
+		customers.WithPhoneRegion(cfg.PhoneRegion),
+		customers.WithConsents(pgstorage.NewCustomerConsentRepository(db.DB)),
+	)
+	sampleCustomers := []customers.CreateInput{
+		{FirstName: "Alex", LastName: "Driver", Email: "alex@example.com", Phone: "904-555-0101"},
 		{FirstName: "Jordan", LastName: "Mechanic", Email: "jordan@example.com", Phone: "904-555-0202"},
 	}
 
 	createdCustomers := make([]customers.Customer, 0, len(sampleCustomers))
 	for _, c := range sampleCustomers {
-		saved, err := custRepo.Save(c)
+		saved, err := customerService.Create(c)
 		if err != nil {
 			logr.Error("failed to seed customer", "email", c.Email, "err", err)
 			os.Exit(1)
@@ -71,6 +85,17 @@ func main() {
 		createdCustomers = append(createdCustomers, saved)
 	}
 
+	if _, err := customerService.RecordConsent(createdCustomers[0].ID, customers.ConsentInput{
+		Channel: customers.ChannelEmail,
+		Purpose: customers.PurposeMarketing,
+		Granted: true,
+		Source:  customers.SourceStaff,
+		Detail:  "seed",
+	}); err != nil {
+		logr.Error("failed to seed consent", "err", err)
+		os.Exit(1)
+	}
+
 	sampleVehicles := []vehicles.Vehicle{
 		{CustomerID: createdCustomers[0].ID, VIN: "1FTNE14W67DA12345", Year: 2017, Make: "Ford", Model: "Transit", Trim: "250", Engine: "3.7L V6", Mileage: 120000},
 		{CustomerID: createdCustomers[1].ID, VIN: "5YJSA1CN5DFP12345", Year: 2015, Make: "Tesla", Model: "Model S", Trim: "85", Engine: "Electric", Mileage: 85000},
@@ -107,7 +132,10 @@ func main() {
 	}
 
 	for i := range sampleQuotes {
-		sampleQuotes[i].TotalAmount = computeTotal(sampleQuotes[i].LineItems)
+		// Seeded quotes charge for parts only; quotes created through the
+		// API price labor from the rate card.
+		sampleQuotes[i].PartsAmount = computeTotal(sampleQuotes[i].LineItems)
+		sampleQuotes[i].TotalAmount = sampleQuotes[i].PartsAmount
 		saved, err := quoteRepo.Save(sampleQuotes[i])
 		if err != nil {
 			logr.Error("failed to seed quote", "customer_id", sampleQuotes[i].CustomerID, "err", err)
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS customers_deleted_idx;
+DROP INDEX IF EXISTS customers_active_email_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS customers_email_idx ON customers (LOWER(email)) WHERE email <> '';
+ALTER TABLE customers DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,9 @@
+-- Soft deletes for customers: deleted rows keep their history until purged
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- Emails only need to be unique among active customers, so a deleted
+-- customer's address can be reused.
+DROP INDEX IF EXISTS customers_email_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS customers_active_email_idx ON customers (LOWER(email)) WHERE email <> '' AND deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS customers_deleted_idx ON customers (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS vehicles_vin_trgm_idx;
+DROP INDEX IF EXISTS customers_phone_digits_trgm_idx;
+DROP INDEX IF EXISTS customers_email_trgm_idx;
+DROP INDEX IF EXISTS customers_name_trgm_idx;
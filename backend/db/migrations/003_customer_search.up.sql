@@ -0,0 +1,16 @@
+-- Trigram indexes backing customer search (GET /v1/customers?q=). Each
+-- expression must match the one used by the repository query for the planner
+-- to pick the index up.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS customers_name_trgm_idx
+    ON customers USING gin (lower(first_name || ' ' || last_name) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS customers_email_trgm_idx
+    ON customers USING gin (lower(email) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS customers_phone_digits_trgm_idx
+    ON customers USING gin (regexp_replace(phone, '[^0-9]', '', 'g') gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS vehicles_vin_trgm_idx
+    ON vehicles USING gin (upper(vin) gin_trgm_ops);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS customers_phone_e164_idx;
+ALTER TABLE customers DROP COLUMN IF EXISTS phone_e164;
@@ -0,0 +1,17 @@
+-- Normalized (E.164) customer phone numbers for matching caller IDs. The
+-- phone column keeps the display form.
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS phone_e164 TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS customers_phone_e164_idx ON customers (phone_e164) WHERE phone_e164 <> '';
+
+-- Backfill rows written before normalization. Only valid North American
+-- numbers (10 digits, or 11 with a leading 1) are converted, matching the
+-- default US region. Anything else is left for staff to correct and is
+-- normalized on its next update. Encrypted phones (010) are skipped, since
+-- their ciphertext can contain enough digits to look like a number.
+UPDATE customers
+   SET phone_e164 = '+1' || right(regexp_replace(phone, '[^0-9]', '', 'g'), 10),
+       phone = regexp_replace(right(regexp_replace(phone, '[^0-9]', '', 'g'), 10), '^([0-9]{3})([0-9]{3})([0-9]{4})$', '(\1) \2-\3')
+ WHERE phone_e164 = ''
+   AND phone NOT LIKE 'enc:%'
+   AND regexp_replace(phone, '[^0-9]', '', 'g') ~ '^1?[2-9][0-9]{2}[2-9][0-9]{6}$';
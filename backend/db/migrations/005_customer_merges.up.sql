@@ -0,0 +1,12 @@
+-- Audit trail of customer merges. A merged customer row is deleted, so its
+-- ID is kept here to redirect lookups to the survivor. survivor_id has no
+-- foreign key: the record must outlive a later purge or merge of the survivor.
+CREATE TABLE IF NOT EXISTS customer_merges (
+    merged_id UUID PRIMARY KEY,
+    survivor_id UUID NOT NULL,
+    vehicle_ids JSONB NOT NULL DEFAULT '[]',
+    quote_ids JSONB NOT NULL DEFAULT '[]',
+    merged_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS customer_merges_survivor_idx ON customer_merges (survivor_id);
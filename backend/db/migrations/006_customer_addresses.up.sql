@@ -0,0 +1,21 @@
+-- Service addresses of customers (home, work, fleet yard). latitude and
+-- longitude are NULL until the address is geocoded and geocoded_by names the
+-- source, e.g. zip_centroid or manual.
+CREATE TABLE IF NOT EXISTS customer_addresses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    label TEXT NOT NULL DEFAULT 'home',
+    line1 TEXT NOT NULL DEFAULT '',
+    line2 TEXT NOT NULL DEFAULT '',
+    city TEXT NOT NULL DEFAULT '',
+    region TEXT NOT NULL DEFAULT '',
+    postal_code TEXT NOT NULL DEFAULT '',
+    country TEXT NOT NULL DEFAULT 'US',
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    geocoded_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS customer_addresses_customer_idx ON customer_addresses (customer_id, created_at);
@@ -0,0 +1,30 @@
+-- Append-only TCPA consent ledger. The latest entry (by recorded_at, then
+-- seq) for a customer, channel and purpose is the effective consent.
+CREATE TABLE IF NOT EXISTS customer_consents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    seq BIGSERIAL,
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    channel TEXT NOT NULL,
+    purpose TEXT NOT NULL,
+    granted BOOLEAN NOT NULL,
+    source TEXT NOT NULL,
+    detail TEXT NOT NULL DEFAULT '',
+    ip_address TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    recorded_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS customer_consents_customer_idx ON customer_consents (customer_id, recorded_at, seq);
+
+-- customers.marketing_opt is no longer written. Carry existing opt-ins over
+-- as email marketing consent only: the flag never recorded a channel, so it
+-- is not written consent for marketing texts or calls. The column is kept so
+-- this statement stays valid when migrations re-run.
+INSERT INTO customer_consents (customer_id, channel, purpose, granted, source, detail, recorded_at)
+SELECT c.id, 'email', 'marketing', TRUE, 'migration', 'customers.marketing_opt', c.updated_at
+  FROM customers c
+ WHERE c.marketing_opt
+   AND NOT EXISTS (
+       SELECT 1 FROM customer_consents x
+        WHERE x.customer_id = c.id AND x.source = 'migration'
+   );
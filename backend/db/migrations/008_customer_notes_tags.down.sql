@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS quote_status_changes;
+DROP TABLE IF EXISTS customer_notes;
+DROP INDEX IF EXISTS customers_tags_idx;
+ALTER TABLE customers DROP COLUMN IF EXISTS tags;
@@ -0,0 +1,28 @@
+-- Free-form customer tags as a sorted JSON array of lower-case strings. The
+-- GIN index serves tag searches (tags @> '["fleet"]').
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '[]'::jsonb;
+CREATE INDEX IF NOT EXISTS customers_tags_idx ON customers USING GIN (tags jsonb_path_ops);
+
+-- Internal notes and logged calls or messages. Notes are never edited.
+CREATE TABLE IF NOT EXISTS customer_notes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL DEFAULT 'note',
+    body TEXT NOT NULL,
+    author TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS customer_notes_customer_idx ON customer_notes (customer_id, created_at);
+
+-- Quote status changes, recorded from now on. Earlier quotes only show their
+-- current status.
+CREATE TABLE IF NOT EXISTS quote_status_changes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    quote_id UUID NOT NULL REFERENCES quotes(id) ON DELETE CASCADE,
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS quote_status_changes_quote_idx ON quote_status_changes (quote_id, changed_at);
@@ -0,0 +1,18 @@
+-- Right-to-erasure requests. customer_id has no foreign key: a request and
+-- its audit trail are kept after the customer is purged, as proof the
+-- request was honoured. events is a JSON array, appended to as the request
+-- is handled.
+CREATE TABLE IF NOT EXISTS erasure_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    customer_id UUID NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    requested_by TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    due_at TIMESTAMPTZ NOT NULL,
+    closed_at TIMESTAMPTZ,
+    events JSONB NOT NULL DEFAULT '[]'::jsonb
+);
+
+CREATE INDEX IF NOT EXISTS erasure_requests_customer_idx ON erasure_requests (customer_id, requested_at);
+CREATE INDEX IF NOT EXISTS erasure_requests_status_idx ON erasure_requests (status, requested_at);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS customers_phone_bidx_idx;
+DROP INDEX IF EXISTS customers_active_email_bidx_idx;
+ALTER TABLE customers DROP COLUMN IF EXISTS phone_bidx;
+ALTER TABLE customers DROP COLUMN IF EXISTS email_bidx;
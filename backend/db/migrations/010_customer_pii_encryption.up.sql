@@ -0,0 +1,21 @@
+-- Blind indexes for exact-match lookups on customer emails and phones once
+-- those columns are encrypted (see internal/pii). email_bidx holds the HMAC
+-- of the lower-cased email and phone_bidx that of phone_e164. Without keys
+-- configured they hold the normalized values themselves.
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS email_bidx TEXT NOT NULL DEFAULT '';
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS phone_bidx TEXT NOT NULL DEFAULT '';
+
+-- Fill the indexes of plaintext rows. Encrypted rows always have theirs,
+-- written by the API or the encrypt-pii command.
+UPDATE customers
+   SET email_bidx = lower(email)
+ WHERE email_bidx = '' AND email <> '' AND email NOT LIKE 'enc:%';
+UPDATE customers
+   SET phone_bidx = phone_e164
+ WHERE phone_bidx = '' AND phone_e164 <> '' AND phone_e164 NOT LIKE 'enc:%';
+
+-- Email uniqueness among active customers now goes through the blind index.
+-- customers_active_email_idx (002) stays, and is trivially satisfied by
+-- encrypted values.
+CREATE UNIQUE INDEX IF NOT EXISTS customers_active_email_bidx_idx ON customers (email_bidx) WHERE email_bidx <> '' AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS customers_phone_bidx_idx ON customers (phone_bidx) WHERE phone_bidx <> '';
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS vehicle_transfers;
+ALTER TABLE vehicles DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE vehicles DROP COLUMN IF EXISTS plate;
+ALTER TABLE vehicles DROP COLUMN IF EXISTS color;
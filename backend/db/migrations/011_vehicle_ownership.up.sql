@@ -0,0 +1,18 @@
+-- Vehicle colour, licence plate and archival.
+ALTER TABLE vehicles ADD COLUMN IF NOT EXISTS color TEXT NOT NULL DEFAULT '';
+ALTER TABLE vehicles ADD COLUMN IF NOT EXISTS plate TEXT NOT NULL DEFAULT '';
+ALTER TABLE vehicles ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+
+-- Ownership transfers. The customer IDs have no foreign keys: the history
+-- outlives a purge of either owner.
+CREATE TABLE IF NOT EXISTS vehicle_transfers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    vehicle_id UUID NOT NULL REFERENCES vehicles(id) ON DELETE CASCADE,
+    from_customer_id UUID NOT NULL,
+    to_customer_id UUID NOT NULL,
+    mileage INT NOT NULL DEFAULT 0,
+    note TEXT NOT NULL DEFAULT '',
+    transferred_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS vehicle_transfers_vehicle_idx ON vehicle_transfers (vehicle_id, transferred_at);
@@ -0,0 +1,13 @@
+-- Odometer readings. vehicles.mileage keeps the latest trusted reading.
+CREATE TABLE IF NOT EXISTS odometer_readings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    vehicle_id UUID NOT NULL REFERENCES vehicles(id) ON DELETE CASCADE,
+    mileage INT NOT NULL,
+    source TEXT NOT NULL,
+    read_at TIMESTAMPTZ NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    flagged BOOLEAN NOT NULL DEFAULT FALSE,
+    recorded_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS odometer_readings_vehicle_idx ON odometer_readings (vehicle_id, read_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS quotes_vehicle_idx;
+ALTER TABLE quotes DROP COLUMN IF EXISTS technician_notes;
@@ -0,0 +1,4 @@
+-- Technician notes on quotes, shown in vehicle service histories.
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS technician_notes TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS quotes_vehicle_idx ON quotes (vehicle_id, created_at);
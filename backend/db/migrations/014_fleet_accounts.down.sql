@@ -0,0 +1,9 @@
+ALTER TABLE quotes DROP COLUMN IF EXISTS contact_id;
+ALTER TABLE quotes DROP COLUMN IF EXISTS po_number;
+ALTER TABLE vehicles DROP COLUMN IF EXISTS unit_number;
+DROP TABLE IF EXISTS fleet_contacts;
+DROP INDEX IF EXISTS customers_company_trgm_idx;
+ALTER TABLE customers DROP COLUMN IF EXISTS billing;
+ALTER TABLE customers DROP COLUMN IF EXISTS po_required;
+ALTER TABLE customers DROP COLUMN IF EXISTS company_name;
+ALTER TABLE customers DROP COLUMN IF EXISTS account_type;
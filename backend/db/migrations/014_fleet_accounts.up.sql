@@ -0,0 +1,33 @@
+-- Fleet and business accounts. account_type is individual or fleet and
+-- fleets carry a company name, whether work needs a purchase order and how
+-- they are billed (per_visit or monthly, recorded only).
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS account_type TEXT NOT NULL DEFAULT 'individual';
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS company_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS po_required BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS billing TEXT NOT NULL DEFAULT 'per_visit';
+
+CREATE INDEX IF NOT EXISTS customers_company_trgm_idx
+    ON customers USING gin (lower(company_name) gin_trgm_ops);
+
+-- People authorized to deal with us for a fleet. Email and phones are
+-- encrypted like those of customers when PII keys are configured.
+CREATE TABLE IF NOT EXISTS fleet_contacts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    role TEXT NOT NULL,
+    email TEXT NOT NULL DEFAULT '',
+    phone TEXT NOT NULL DEFAULT '',
+    phone_e164 TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS fleet_contacts_customer_idx ON fleet_contacts (customer_id, created_at);
+
+-- Fleet unit numbers on vehicles, and the purchase order and approving
+-- contact on quotes. contact_id is not a foreign key so that removing a
+-- contact keeps the record of who approved past work.
+ALTER TABLE vehicles ADD COLUMN IF NOT EXISTS unit_number TEXT NOT NULL DEFAULT '';
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS po_number TEXT NOT NULL DEFAULT '';
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS contact_id TEXT NOT NULL DEFAULT '';
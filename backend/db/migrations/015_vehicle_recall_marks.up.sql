@@ -0,0 +1,12 @@
+-- What staff have done about recalls and service bulletins on a vehicle.
+-- The bulletins themselves come from the NHTSA dataset file loaded at
+-- startup, so bulletin_id is not a foreign key.
+CREATE TABLE IF NOT EXISTS vehicle_recall_marks (
+    vehicle_id UUID NOT NULL REFERENCES vehicles(id) ON DELETE CASCADE,
+    bulletin_id TEXT NOT NULL,
+    status TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    customer_id TEXT NOT NULL DEFAULT '',
+    marked_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (vehicle_id, bulletin_id)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE quote_status_changes DROP COLUMN IF EXISTS reason;
+ALTER TABLE quote_status_changes DROP COLUMN IF EXISTS actor;
@@ -0,0 +1,3 @@
+-- Who moved a quote to a new status, and why.
+ALTER TABLE quote_status_changes ADD COLUMN IF NOT EXISTS actor TEXT NOT NULL DEFAULT '';
+ALTER TABLE quote_status_changes ADD COLUMN IF NOT EXISTS reason TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,10 @@
+ALTER TABLE quote_line_items DROP COLUMN IF EXISTS labor_amount;
+ALTER TABLE quote_line_items DROP COLUMN IF EXISTS labor_rate;
+ALTER TABLE quote_line_items DROP COLUMN IF EXISTS category;
+
+ALTER TABLE quotes DROP COLUMN IF EXISTS technician_tier;
+ALTER TABLE quotes DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE quotes DROP COLUMN IF EXISTS discount_amount;
+ALTER TABLE quotes DROP COLUMN IF EXISTS fees_amount;
+ALTER TABLE quotes DROP COLUMN IF EXISTS labor_amount;
+ALTER TABLE quotes DROP COLUMN IF EXISTS parts_amount;
@@ -0,0 +1,16 @@
+-- Quote totals broken down into parts, labor, fees, discount and tax, and
+-- the labor rate each line was priced at. Earlier quotes charged for parts
+-- only, so their total is all parts.
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS parts_amount BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS labor_amount BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS fees_amount BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS discount_amount BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS tax_amount BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS technician_tier TEXT NOT NULL DEFAULT '';
+
+UPDATE quotes SET parts_amount = total_amount
+ WHERE parts_amount = 0 AND labor_amount = 0 AND fees_amount = 0 AND tax_amount = 0;
+
+ALTER TABLE quote_line_items ADD COLUMN IF NOT EXISTS category TEXT NOT NULL DEFAULT '';
+ALTER TABLE quote_line_items ADD COLUMN IF NOT EXISTS labor_rate BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE quote_line_items ADD COLUMN IF NOT EXISTS labor_amount BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,166 @@
+// Package bulk reads customer import files and writes customer exports. Files
+// are streamed a row at a time; validation and deduplication are left to
+// customers.Service.Import.
+package bulk
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+)
+
+var (
+	// ErrInvalidMapping is returned for column mappings that name unknown
+	// fields or, in CSV files, columns missing from the header.
+	ErrInvalidMapping = errors.New("bulk: invalid column mapping")
+	// ErrUnreadable is returned when a file cannot be read at all, as opposed
+	// to rows that cannot be parsed, which are reported per row.
+	ErrUnreadable = errors.New("bulk: unreadable file")
+)
+
+// Field is a customer attribute an import column can be mapped to.
+type Field string
+
+const (
+	FieldExternalID Field = "external_id"
+	FieldFirstName  Field = "first_name"
+	FieldLastName   Field = "last_name"
+	// FieldName is a full name, split at the last space when neither first
+	// nor last name is mapped.
+	FieldName  Field = "name"
+	FieldEmail Field = "email"
+	FieldPhone Field = "phone"
+	// FieldTags holds tags separated by commas or semicolons.
+	FieldTags Field = "tags"
+)
+
+// Fields lists every importable field.
+var Fields = []Field{FieldExternalID, FieldFirstName, FieldLastName, FieldName, FieldEmail, FieldPhone, FieldTags}
+
+// aliases are the column names, normalized by columnKey, recognized for each
+// field when a file is read without an explicit mapping.
+var aliases = map[Field][]string{
+	FieldExternalID: {"id", "customerid", "legacyid"},
+	FieldFirstName:  {"first", "firstname", "givenname"},
+	FieldLastName:   {"last", "lastname", "surname", "familyname"},
+	FieldName:       {"fullname", "customername", "customer"},
+	FieldEmail:      {"emailaddress", "mail"},
+	FieldPhone:      {"phonenumber", "mobile", "cell", "cellphone", "telephone", "tel"},
+	FieldTags:       {"tag", "labels"},
+}
+
+// Mapping names the source column of each field. Fields left out are filled
+// from columns named after them or one of their aliases, compared ignoring
+// case, spaces and punctuation.
+type Mapping map[Field]string
+
+// ParseMapping reads "field:column" pairs, such as "phone:field_14".
+func ParseMapping(pairs []string) (Mapping, error) {
+	m := Mapping{}
+	for _, p := range pairs {
+		field, column, ok := strings.Cut(p, ":")
+		f := Field(strings.ToLower(strings.TrimSpace(field)))
+		column = strings.TrimSpace(column)
+		if !ok || column == "" {
+			return nil, fmt.Errorf("%w: %q is not field:column", ErrInvalidMapping, p)
+		}
+		if !slices.Contains(Fields, f) {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidMapping, field)
+		}
+		m[f] = column
+	}
+	return m, nil
+}
+
+// resolve returns the column of each field present in columns. Explicit
+// mappings come first, then exact field names, then aliases; a column is
+// used for at most one field. With strict set, explicitly mapped columns
+// must exist; otherwise a missing one falls back to matching by name, since
+// NDJSON objects need not share keys.
+func (m Mapping) resolve(columns []string, strict bool) (map[Field]string, error) {
+	byKey := map[string]string{}
+	for _, c := range columns {
+		if _, ok := byKey[columnKey(c)]; !ok {
+			byKey[columnKey(c)] = c
+		}
+	}
+	out := map[Field]string{}
+	used := map[string]bool{}
+	for f, column := range m {
+		if !slices.Contains(columns, column) {
+			if strict {
+				return nil, fmt.Errorf("%w: column %q for %s is not in the header", ErrInvalidMapping, column, f)
+			}
+			continue
+		}
+		out[f] = column
+		used[column] = true
+	}
+	match := func(f Field, key string) {
+		if _, done := out[f]; done {
+			return
+		}
+		if c, ok := byKey[key]; ok && !used[c] {
+			out[f] = c
+			used[c] = true
+		}
+	}
+	for _, f := range Fields {
+		match(f, columnKey(string(f)))
+	}
+	for _, f := range Fields {
+		for _, a := range aliases[f] {
+			match(f, a)
+		}
+	}
+	return out, nil
+}
+
+// columnKey lower-cases a column name and drops everything but letters and
+// digits, so "First Name", "first_name" and "FirstName" compare equal.
+func columnKey(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// input builds a CreateInput from the mapped fields of one row.
+func input(values map[Field]string) customers.CreateInput {
+	in := customers.CreateInput{
+		ExternalID: values[FieldExternalID],
+		FirstName:  values[FieldFirstName],
+		LastName:   values[FieldLastName],
+		Email:      values[FieldEmail],
+		Phone:      values[FieldPhone],
+	}
+	if name := strings.TrimSpace(values[FieldName]); name != "" && strings.TrimSpace(in.FirstName) == "" && strings.TrimSpace(in.LastName) == "" {
+		if i := strings.LastIndexByte(name, ' '); i > 0 {
+			in.FirstName, in.LastName = strings.TrimSpace(name[:i]), name[i+1:]
+		} else {
+			in.FirstName = name
+		}
+	}
+	if tags := values[FieldTags]; tags != "" {
+		in.Tags = strings.FieldsFunc(tags, func(r rune) bool { return r == ',' || r == ';' })
+	}
+	return in
+}
+
+// mappedColumns lists the fields found in a file, for reporting.
+func mappedColumns(cols map[Field]string) []string {
+	out := make([]string, 0, len(cols))
+	for f, c := range cols {
+		out = append(out, string(f)+":"+c)
+	}
+	sort.Strings(out)
+	return out
+}
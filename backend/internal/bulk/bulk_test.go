@@ -0,0 +1,150 @@
+package bulk_test
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ezmobilemechanic/platform/internal/bulk"
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
+	"github.com/ezmobilemechanic/platform/internal/storage/memory"
+)
+
+func readAll(t *testing.T, src customers.ImportSource) []customers.ImportRow {
+	t.Helper()
+	var out []customers.ImportRow
+	for {
+		row, err := src.Next()
+		if errors.Is(err, io.EOF) {
+			return out
+		}
+		if err != nil {
+			t.Fatalf("next: %v", err)
+		}
+		out = append(out, row)
+	}
+}
+
+func TestCSVReaderAliases(t *testing.T) {
+	file := "\ufeffFirst Name,Last Name,E-mail Address,Mobile,Tags,Notes\n" +
+		"Alex,Driver,alex@example.com,904-555-0101,\"fleet; vip\",ignored\n" +
+		"\n" +
+		"\"Sam\",Lee,sam@example.com,,,\n"
+	r, err := bulk.NewCSVReader(strings.NewReader(file), ',', nil)
+	if err != nil {
+		t.Fatalf("new reader: %v", err)
+	}
+	want := []string{"email:E-mail Address", "first_name:First Name", "last_name:Last Name", "phone:Mobile", "tags:Tags"}
+	if !slices.Equal(r.Columns(), want) {
+		t.Fatalf("expected columns %v, got %v", want, r.Columns())
+	}
+
+	got := readAll(t, r)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 rows, got %+v", got)
+	}
+	first := got[0].Input
+	if got[0].Line != 2 || first.FirstName != "Alex" || first.Email != "alex@example.com" || first.Phone != "904-555-0101" || !slices.Equal(first.Tags, []string{"fleet", " vip"}) {
+		t.Fatalf("unexpected first row %+v", got[0])
+	}
+	if got[1].Line != 4 || got[1].Input.LastName != "Lee" {
+		t.Fatalf("unexpected second row %+v", got[1])
+	}
+}
+
+func TestCSVReaderMapping(t *testing.T) {
+	// A legacy CRM dump: generic column names and a single name field.
+	file := "id\tfield_12\tfield_14\tfield_15\n" +
+		"7\tMary Ann Smith\t9045550101\tmary@example.com\n" +
+		"8\t\"broken\tx\t\t\n"
+	mapping, err := bulk.ParseMapping([]string{"name:field_12", "phone:field_14", "EMAIL:field_15"})
+	if err != nil {
+		t.Fatalf("parse mapping: %v", err)
+	}
+	r, err := bulk.NewCSVReader(strings.NewReader(file), '\t', mapping)
+	if err != nil {
+		t.Fatalf("new reader: %v", err)
+	}
+	got := readAll(t, r)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 rows, got %+v", got)
+	}
+	in := got[0].Input
+	if in.ExternalID != "7" || in.FirstName != "Mary Ann" || in.LastName != "Smith" || in.Phone != "9045550101" || in.Email != "mary@example.com" {
+		t.Fatalf("unexpected row %+v", in)
+	}
+	if got[1].Err == nil || got[1].Line != 3 {
+		t.Fatalf("expected a parse error on line 3, got %+v", got[1])
+	}
+
+	if _, err := bulk.NewCSVReader(strings.NewReader(file), '\t', bulk.Mapping{bulk.FieldPhone: "cell"}); !errors.Is(err, bulk.ErrInvalidMapping) {
+		t.Fatalf("expected ErrInvalidMapping for a missing column, got %v", err)
+	}
+	if _, err := bulk.ParseMapping([]string{"fax:field_9"}); !errors.Is(err, bulk.ErrInvalidMapping) {
+		t.Fatalf("expected ErrInvalidMapping for an unknown field, got %v", err)
+	}
+	if _, err := bulk.NewCSVReader(strings.NewReader(""), ',', nil); !errors.Is(err, bulk.ErrUnreadable) {
+		t.Fatalf("expected ErrUnreadable for an empty file, got %v", err)
+	}
+}
+
+func TestNDJSONReader(t *testing.T) {
+	file := `{"first_name":"Alex","email":"alex@example.com","phone":9045550101,"tags":["fleet","vip"],"extra":{"x":1}}
+
+{"Full Name":"Sam Lee","cell":"904-555-0199"}
+not json
+{"first_name":{"nested":true}}
+`
+	got := readAll(t, bulk.NewNDJSONReader(strings.NewReader(file), bulk.Mapping{bulk.FieldPhone: "cell"}))
+	if len(got) != 4 {
+		t.Fatalf("expected 4 rows, got %+v", got)
+	}
+	if in := got[0].Input; got[0].Err != nil || in.Phone != "9045550101" || !slices.Equal(in.Tags, []string{"fleet", "vip"}) {
+		t.Fatalf("unexpected first row %+v", got[0])
+	}
+	if in := got[1].Input; got[1].Line != 3 || in.FirstName != "Sam" || in.LastName != "Lee" || in.Phone != "904-555-0199" {
+		t.Fatalf("unexpected second row %+v", got[1])
+	}
+	if got[2].Err == nil || got[2].Line != 4 || got[3].Err == nil {
+		t.Fatalf("expected errors on lines 4 and 5, got %+v", got[2:])
+	}
+}
+
+func TestExportCSVRoundTrip(t *testing.T) {
+	svc := customers.NewService(memory.NewCustomerRepository())
+	for _, in := range []customers.CreateInput{
+		{ExternalID: "7", FirstName: "Alex", LastName: "Driver, Jr.", Email: "alex@example.com", Tags: []string{"fleet", "vip"}},
+		{FirstName: "Sam", Phone: "904-555-0199"},
+	} {
+		if _, err := svc.Create(in); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	var out strings.Builder
+	n, err := bulk.ExportCSV(&out, svc.List, listing.Spec{Sort: listing.Sort{Field: listing.SortCreatedAt}})
+	if err != nil || n != 2 {
+		t.Fatalf("export: %d, %v", n, err)
+	}
+	if !strings.HasPrefix(out.String(), strings.Join(bulk.ExportColumns, ",")+"\n") {
+		t.Fatalf("unexpected header in %q", out.String())
+	}
+
+	r, err := bulk.NewCSVReader(strings.NewReader(out.String()), ',', nil)
+	if err != nil {
+		t.Fatalf("read back: %v", err)
+	}
+	got := readAll(t, r)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 rows, got %+v", got)
+	}
+	if in := got[0].Input; in.ExternalID != "7" || in.LastName != "Driver, Jr." || in.Email != "alex@example.com" || len(in.Tags) != 2 {
+		t.Fatalf("unexpected first row %+v", in)
+	}
+	if in := got[1].Input; in.FirstName != "Sam" || in.Phone != "(904) 555-0199" {
+		t.Fatalf("unexpected second row %+v", in)
+	}
+}
@@ -0,0 +1,91 @@
+package bulk
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+)
+
+// CSVReader reads customers from a CSV file with a header row. It implements
+// customers.ImportSource.
+type CSVReader struct {
+	r    *csv.Reader
+	cols map[Field]string
+	// index is the position of each mapped column in a record.
+	index map[Field]int
+}
+
+// NewCSVReader reads the header row of r and resolves mapping against it.
+// comma is the field delimiter; use '\t' for tab-separated files such as
+// "mysql --batch" output.
+func NewCSVReader(r io.Reader, comma rune, mapping Mapping) (*CSVReader, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: no header row", ErrUnreadable)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: header: %w", ErrUnreadable, err)
+	}
+	header = append([]string(nil), header...)
+	if len(header) > 0 {
+		// Spreadsheet exports often start with a byte order mark.
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	cols, err := mapping.resolve(header, true)
+	if err != nil {
+		return nil, err
+	}
+	index := map[Field]int{}
+	for f, c := range cols {
+		for i, h := range header {
+			if h == c {
+				index[f] = i
+				break
+			}
+		}
+	}
+	return &CSVReader{r: cr, cols: cols, index: index}, nil
+}
+
+// Columns lists the mapped fields as "field:column".
+func (c *CSVReader) Columns() []string {
+	return mappedColumns(c.cols)
+}
+
+// Next returns the next record. Records that cannot be parsed are returned
+// with Err set. Blank lines are skipped.
+func (c *CSVReader) Next() (customers.ImportRow, error) {
+	record, err := c.r.Read()
+	if errors.Is(err, io.EOF) {
+		return customers.ImportRow{}, io.EOF
+	}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return customers.ImportRow{Line: parseErr.StartLine, Err: parseErr.Err}, nil
+	}
+	if err != nil {
+		return customers.ImportRow{}, fmt.Errorf("%w: %w", ErrUnreadable, err)
+	}
+
+	line, _ := c.r.FieldPos(0)
+	values := make(map[Field]string, len(c.index))
+	for f, i := range c.index {
+		if i < len(record) {
+			values[f] = record[i]
+		}
+	}
+	return customers.ImportRow{Line: line, Input: input(values)}, nil
+}
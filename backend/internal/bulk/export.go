@@ -0,0 +1,67 @@
+package bulk
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
+)
+
+// ExportColumns is the header of customer exports. The first seven columns
+// read back through the default import mapping.
+var ExportColumns = []string{
+	"id", "external_id", "first_name", "last_name", "email", "phone", "tags",
+	"phone_e164", "created_at", "updated_at",
+}
+
+// ListFunc returns a page of customers; customers.Service.List fits.
+type ListFunc func(spec listing.Spec) (listing.Page[customers.Customer], error)
+
+// ExportCSV writes every customer matching spec to w as CSV, fetching a page
+// at a time starting from spec's cursor. It returns the number of customers
+// written. Output already written stays written if a page fails.
+func ExportCSV(w io.Writer, list ListFunc, spec listing.Spec) (int, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(ExportColumns); err != nil {
+		return 0, err
+	}
+
+	spec.Limit = listing.MaxLimit
+	n := 0
+	record := make([]string, len(ExportColumns))
+	for {
+		page, err := list(spec)
+		if err != nil {
+			cw.Flush()
+			return n, err
+		}
+		for _, c := range page.Items {
+			record[0] = c.ID
+			record[1] = c.ExternalID
+			record[2] = c.FirstName
+			record[3] = c.LastName
+			record[4] = c.Email
+			record[5] = c.Phone
+			record[6] = strings.Join(c.Tags, ", ")
+			record[7] = c.PhoneE164
+			record[8] = c.CreatedAt.UTC().Format(time.RFC3339)
+			record[9] = c.UpdatedAt.UTC().Format(time.RFC3339)
+			if err := cw.Write(record); err != nil {
+				return n, err
+			}
+			n++
+		}
+		// Flush each page so large exports stream instead of buffering.
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return n, err
+		}
+		if !page.HasMore {
+			return n, nil
+		}
+		spec.Cursor = page.NextCursor
+	}
+}
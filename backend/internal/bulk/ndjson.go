@@ -0,0 +1,111 @@
+package bulk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+)
+
+// maxLineBytes bounds a single NDJSON line.
+const maxLineBytes = 1 << 20
+
+// NDJSONReader reads customers from newline-delimited JSON, one object per
+// line. Keys are mapped to fields as CSV columns are. It implements
+// customers.ImportSource.
+type NDJSONReader struct {
+	s       *bufio.Scanner
+	mapping Mapping
+	line    int
+}
+
+// NewNDJSONReader reads objects from r.
+func NewNDJSONReader(r io.Reader, mapping Mapping) *NDJSONReader {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+	return &NDJSONReader{s: s, mapping: mapping}
+}
+
+// Next returns the next object. Lines that are not JSON objects of strings,
+// numbers or string arrays are returned with Err set. Blank lines are
+// skipped.
+func (n *NDJSONReader) Next() (customers.ImportRow, error) {
+	for n.s.Scan() {
+		n.line++
+		raw := bytes.TrimSpace(n.s.Bytes())
+		if n.line == 1 {
+			raw = bytes.TrimPrefix(raw, []byte("\ufeff"))
+		}
+		if len(raw) == 0 {
+			continue
+		}
+		row, err := n.parse(raw)
+		return customers.ImportRow{Line: n.line, Input: row, Err: err}, nil
+	}
+	if err := n.s.Err(); err != nil {
+		return customers.ImportRow{}, fmt.Errorf("%w: line %d: %w", ErrUnreadable, n.line+1, err)
+	}
+	return customers.ImportRow{}, io.EOF
+}
+
+func (n *NDJSONReader) parse(raw []byte) (customers.CreateInput, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var obj map[string]any
+	if err := dec.Decode(&obj); err != nil {
+		return customers.CreateInput{}, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if obj == nil {
+		return customers.CreateInput{}, fmt.Errorf("not a JSON object")
+	}
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	cols, err := n.mapping.resolve(keys, false)
+	if err != nil {
+		return customers.CreateInput{}, err
+	}
+	values := make(map[Field]string, len(cols))
+	for f, k := range cols {
+		v, ok := obj[k]
+		if !ok {
+			continue
+		}
+		s, err := jsonString(v)
+		if err != nil {
+			return customers.CreateInput{}, fmt.Errorf("%s: %w", k, err)
+		}
+		values[f] = s
+	}
+	return input(values), nil
+}
+
+// jsonString flattens a JSON value into the text a CSV cell would hold.
+func jsonString(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return "", fmt.Errorf("arrays may only hold strings")
+			}
+			parts = append(parts, s)
+		}
+		return strings.Join(parts, ","), nil
+	default:
+		return "", fmt.Errorf("unsupported value %v", v)
+	}
+}
@@ -3,8 +3,12 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/phone"
 )
 
 // Config holds application configuration loaded from environment variables.
@@ -14,7 +18,9 @@ type Config struct {
 	ShutdownTimeout   time.Duration
 	ReadHeaderTimeout time.Duration
 
-	DataBackend       string
+	DataBackend      string
+	DataDir          string
+	SnapshotInterval time.Duration
 
 	DatabaseDriver    string
 	DatabaseURL       string
@@ -23,7 +29,38 @@ type Config struct {
 	DBConnMaxLifetime time.Duration
 	DBConnMaxIdleTime time.Duration
 
-	RedisURL string
+	RedisURL  string
+	CacheTTL  time.Duration
+	CacheSize int
+
+	CustomerRetention time.Duration
+	PurgeInterval     time.Duration
+
+	PhoneRegion string
+
+	// MaintenanceRulesFile replaces the built-in maintenance schedule.
+	MaintenanceRulesFile string
+	ReminderInterval     time.Duration
+
+	// SpecCatalogFile is the vehicle spec catalog, as .csv or .json.
+	SpecCatalogFile string
+
+	// RecallsFile and TSBsFile are NHTSA recall and service bulletin
+	// datasets.
+	RecallsFile string
+	TSBsFile    string
+
+	// LaborRatesFile is the rate card pricing quote labor, fees and tax.
+	LaborRatesFile string
+
+	Geocoder         string
+	ZIPCentroidsFile string
+
+	// PII encryption keys, either inline or from a key file. Unset leaves
+	// customer PII in plaintext.
+	PIIKeys     string
+	PIIIndexKey string
+	PIIKeyFile  string
 
 	JWTSecret       string
 	JWTExpiry       time.Duration
@@ -36,14 +73,28 @@ const (
 	defaultShutdownTimeout   = 10 * time.Second
 	defaultReadHeaderTimeout = 5 * time.Second
 
-	defaultDataBackend       = "memory"
+	defaultDataBackend      = "memory"
+	defaultDataDir          = "data"
+	defaultSnapshotInterval = 5 * time.Minute
 
 	defaultDatabaseDriver    = "postgres"
+	defaultSQLiteDriver      = "sqlite"
+	defaultSQLiteFile        = "ezm.db"
 	defaultDBMaxOpenConns    = 10
 	defaultDBMaxIdleConns    = 5
 	defaultDBConnMaxLifetime = time.Hour
 	defaultDBConnMaxIdleTime = 30 * time.Minute
 
+	defaultCacheTTL  = time.Minute
+	defaultCacheSize = 10000
+
+	defaultCustomerRetention = 30 * 24 * time.Hour
+	defaultPurgeInterval     = time.Hour
+
+	defaultReminderInterval = 24 * time.Hour
+
+	defaultGeocoder = "none"
+
 	defaultJWTExpiry       = 24 * time.Hour
 	defaultRefreshTokenTTL = 30 * 24 * time.Hour
 )
@@ -56,7 +107,9 @@ func Load() (Config, error) {
 		ShutdownTimeout:   getDuration("SHUTDOWN_TIMEOUT", defaultShutdownTimeout),
 		ReadHeaderTimeout: getDuration("READ_HEADER_TIMEOUT", defaultReadHeaderTimeout),
 
-		DataBackend:       getEnv("DATA_BACKEND", defaultDataBackend),
+		DataBackend:      getEnv("DATA_BACKEND", defaultDataBackend),
+		DataDir:          getEnv("DATA_DIR", defaultDataDir),
+		SnapshotInterval: getDuration("SNAPSHOT_INTERVAL", defaultSnapshotInterval),
 
 		DatabaseDriver:    getEnv("DATABASE_DRIVER", defaultDatabaseDriver),
 		DatabaseURL:       os.Getenv("DATABASE_URL"),
@@ -65,7 +118,31 @@ func Load() (Config, error) {
 		DBConnMaxLifetime: getDuration("DB_CONN_MAX_LIFETIME", defaultDBConnMaxLifetime),
 		DBConnMaxIdleTime: getDuration("DB_CONN_MAX_IDLE_TIME", defaultDBConnMaxIdleTime),
 
-		RedisURL: os.Getenv("REDIS_URL"),
+		RedisURL:  os.Getenv("REDIS_URL"),
+		CacheTTL:  getDuration("CACHE_TTL", defaultCacheTTL),
+		CacheSize: getInt("CACHE_SIZE", defaultCacheSize),
+
+		CustomerRetention: getDuration("CUSTOMER_RETENTION", defaultCustomerRetention),
+		PurgeInterval:     getDuration("PURGE_INTERVAL", defaultPurgeInterval),
+
+		PhoneRegion: strings.ToUpper(getEnv("PHONE_REGION", phone.DefaultRegion)),
+
+		MaintenanceRulesFile: os.Getenv("MAINTENANCE_RULES_FILE"),
+		ReminderInterval:     getDuration("REMINDER_INTERVAL", defaultReminderInterval),
+
+		SpecCatalogFile: os.Getenv("SPEC_CATALOG_FILE"),
+
+		RecallsFile: os.Getenv("RECALLS_FILE"),
+		TSBsFile:    os.Getenv("TSBS_FILE"),
+
+		LaborRatesFile: os.Getenv("LABOR_RATES_FILE"),
+
+		Geocoder:         getEnv("GEOCODER", defaultGeocoder),
+		ZIPCentroidsFile: os.Getenv("ZIP_CENTROIDS_FILE"),
+
+		PIIKeys:     os.Getenv("PII_KEYS"),
+		PIIIndexKey: os.Getenv("PII_INDEX_KEY"),
+		PIIKeyFile:  os.Getenv("PII_KEY_FILE"),
 
 		JWTSecret:       os.Getenv("JWT_SECRET"),
 		JWTExpiry:       getDuration("JWT_EXPIRY", defaultJWTExpiry),
@@ -76,9 +153,48 @@ func Load() (Config, error) {
 		return Config{}, fmt.Errorf("JWT_SECRET is required")
 	}
 
+	if !phone.SupportedRegion(cfg.PhoneRegion) {
+		return Config{}, fmt.Errorf("unsupported PHONE_REGION value: %s", cfg.PhoneRegion)
+	}
+
+	switch cfg.Geocoder {
+	case "none":
+		// no-op
+	case "zip":
+		if cfg.ZIPCentroidsFile == "" {
+			return Config{}, fmt.Errorf("ZIP_CENTROIDS_FILE is required when GEOCODER=zip")
+		}
+	default:
+		return Config{}, fmt.Errorf("unknown GEOCODER value: %s", cfg.Geocoder)
+	}
+
+	if cfg.PIIKeyFile != "" && (cfg.PIIKeys != "" || cfg.PIIIndexKey != "") {
+		return Config{}, fmt.Errorf("PII_KEY_FILE cannot be combined with PII_KEYS or PII_INDEX_KEY")
+	}
+	if (cfg.PIIKeys == "") != (cfg.PIIIndexKey == "") {
+		return Config{}, fmt.Errorf("PII_KEYS and PII_INDEX_KEY must be set together")
+	}
+
+	if cfg.CustomerRetention < 0 {
+		return Config{}, fmt.Errorf("CUSTOMER_RETENTION must not be negative")
+	}
+
 	switch cfg.DataBackend {
 	case "memory":
 		// no-op
+	case "file":
+		if cfg.SnapshotInterval <= 0 {
+			return Config{}, fmt.Errorf("SNAPSHOT_INTERVAL must be positive when DATA_BACKEND=file")
+		}
+	case "sqlite":
+		// DATABASE_DRIVER selects the SQL dialect; default it to the SQLite
+		// driver and keep the database file alongside other local state.
+		if os.Getenv("DATABASE_DRIVER") == "" {
+			cfg.DatabaseDriver = defaultSQLiteDriver
+		}
+		if cfg.DatabaseURL == "" {
+			cfg.DatabaseURL = filepath.Join(cfg.DataDir, defaultSQLiteFile)
+		}
 	case "postgres":
 		if cfg.DatabaseURL == "" {
 			return Config{}, fmt.Errorf("DATABASE_URL is required when DATA_BACKEND=postgres")
@@ -19,11 +19,22 @@ type Migrator interface {
 }
 
 // SQLMigrator executes .sql migration files against a database connection.
+//
+// By default every file is re-run on each Up, so statements must be
+// idempotent. When VersionTable is set, each file is applied once inside a
+// transaction and recorded in that table, so a shipped file never has to be
+// edited to stay safe to re-run.
+//
+// Legacy, if set, is consulted while VersionTable is still empty. It returns
+// the files a database migrated before versions were tracked has applied and
+// must not run again; they are recorded without being run.
 type SQLMigrator struct {
-	Logger *slog.Logger
-	DB     *sql.DB
-	FS     fs.FS
-	Path   string
+	Logger       *slog.Logger
+	DB           *sql.DB
+	FS           fs.FS
+	Path         string
+	VersionTable string
+	Legacy       func(ctx context.Context, db *sql.DB) ([]string, error)
 }
 
 // NewSQLMigrator builds a migrator that runs SQL statements from the provided filesystem.
@@ -60,13 +71,18 @@ func (m *SQLMigrator) Up(ctx context.Context) error {
 		return entries[i].Name() < entries[j].Name()
 	})
 
+	done, err := m.appliedVersions(ctx)
+	if err != nil {
+		return err
+	}
+
 	applied := 0
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
 		}
 		name := entry.Name()
-		if !strings.HasSuffix(name, ".up.sql") {
+		if !strings.HasSuffix(name, ".up.sql") || done[name] {
 			continue
 		}
 
@@ -81,13 +97,8 @@ func (m *SQLMigrator) Up(ctx context.Context) error {
 			continue
 		}
 
-		for i, stmt := range statements {
-			if stmt == "" {
-				continue
-			}
-			if _, err := m.DB.ExecContext(ctx, stmt); err != nil {
-				return fmt.Errorf("exec %s [%d]: %w", name, i+1, err)
-			}
+		if err := m.apply(ctx, name, statements); err != nil {
+			return err
 		}
 		applied++
 		logger.Info("migration applied", "file", name)
@@ -99,6 +110,91 @@ func (m *SQLMigrator) Up(ctx context.Context) error {
 	return nil
 }
 
+func (m *SQLMigrator) apply(ctx context.Context, name string, statements []string) error {
+	if m.VersionTable == "" {
+		for i, stmt := range statements {
+			if _, err := m.DB.ExecContext(ctx, stmt); err != nil {
+				return fmt.Errorf("exec %s [%d]: %w", name, i+1, err)
+			}
+		}
+		return nil
+	}
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin %s: %w", name, err)
+	}
+	for i, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("exec %s [%d]: %w", name, i+1, err)
+		}
+	}
+	if err := m.record(ctx, tx, name); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit %s: %w", name, err)
+	}
+	return nil
+}
+
+// appliedVersions returns the files already recorded in VersionTable.
+func (m *SQLMigrator) appliedVersions(ctx context.Context) (map[string]bool, error) {
+	done := map[string]bool{}
+	if m.VersionTable == "" {
+		return done, nil
+	}
+
+	if _, err := m.DB.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+m.VersionTable+" (version TEXT PRIMARY KEY)"); err != nil {
+		return nil, fmt.Errorf("create %s: %w", m.VersionTable, err)
+	}
+	rows, err := m.DB.QueryContext(ctx, "SELECT version FROM "+m.VersionTable)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", m.VersionTable, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		done[v] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(done) > 0 || m.Legacy == nil {
+		return done, nil
+	}
+
+	legacy, err := m.Legacy(ctx, m.DB)
+	if err != nil {
+		return nil, fmt.Errorf("find legacy migrations: %w", err)
+	}
+	for _, name := range legacy {
+		if err := m.record(ctx, m.DB, name); err != nil {
+			return nil, err
+		}
+		done[name] = true
+	}
+	return done, nil
+}
+
+// execer runs statements on a *sql.DB or within a *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// record marks the file name as applied.
+func (m *SQLMigrator) record(ctx context.Context, db execer, name string) error {
+	if _, err := db.ExecContext(ctx, "INSERT INTO "+m.VersionTable+" (version) VALUES ('"+strings.ReplaceAll(name, "'", "''")+"')"); err != nil {
+		return fmt.Errorf("record %s: %w", name, err)
+	}
+	return nil
+}
+
 func splitSQLStatements(sqlText string) []string {
 	raw := strings.Split(sqlText, ";")
 	out := make([]string, 0, len(raw))
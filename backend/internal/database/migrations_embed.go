@@ -1,7 +1,10 @@
 package database
 
 import (
+	"context"
+	"database/sql"
 	"io/fs"
+	"log/slog"
 	"os"
 )
 
@@ -11,3 +14,28 @@ import (
 func MigrationsFS() fs.FS {
 	return os.DirFS("db/migrations")
 }
+
+// NewPostgresMigrator returns a migrator for the Postgres schema in
+// db/migrations. Applied files are tracked in app_migrations and each runs
+// once; schema_migrations is left to golang-migrate, which
+// scripts/db/migrate.sh uses.
+//
+// Databases migrated before then re-ran every file on each start, so their
+// first tracked start runs them all once more, except 001_init_schema: it
+// created customers_email_idx, which 002 replaced, and recreating it fails
+// once deleted customers share an email.
+func NewPostgresMigrator(db *sql.DB, logger *slog.Logger) *SQLMigrator {
+	m := NewSQLMigrator(db, MigrationsFS(), "db/migrations", logger)
+	m.VersionTable = "app_migrations"
+	m.Legacy = func(ctx context.Context, db *sql.DB) ([]string, error) {
+		var migrated bool
+		if err := db.QueryRowContext(ctx, "SELECT to_regclass('customers') IS NOT NULL").Scan(&migrated); err != nil {
+			return nil, err
+		}
+		if !migrated {
+			return nil, nil
+		}
+		return []string{"001_init_schema.up.sql"}, nil
+	}
+	return m
+}
@@ -0,0 +1,256 @@
+package customers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/geo"
+)
+
+// Address errors.
+var (
+	ErrAddressNotFound = errors.New("address not found")
+	ErrInvalidAddress  = errors.New("invalid address")
+)
+
+// Address labels. Label is free of meaning to storage; the service accepts
+// only these values.
+const (
+	LabelHome      = "home"
+	LabelWork      = "work"
+	LabelFleetYard = "fleet_yard"
+	LabelOther     = "other"
+)
+
+// GeocodedManual marks a Location entered by staff rather than a geocoder.
+const GeocodedManual = "manual"
+
+// Address is a place where a customer's vehicles are serviced.
+type Address struct {
+	ID         string
+	CustomerID string
+	Label      string
+	Line1      string
+	Line2      string
+	City       string
+	// Region is the state or province.
+	Region     string
+	PostalCode string
+	Country    string
+	// Location is nil until the address is geocoded. GeocodedBy names the
+	// source of Location (see geo.Result.Source and GeocodedManual).
+	Location   *geo.Point
+	GeocodedBy string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Query returns the geocoder query for the address.
+func (a Address) Query() geo.Query {
+	return geo.Query{
+		Street:     strings.TrimSpace(a.Line1 + " " + a.Line2),
+		City:       a.City,
+		Region:     a.Region,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+	}
+}
+
+// AddressRepository persists customer addresses. ListByCustomer orders
+// addresses by creation. Addresses are removed with their customer when it is
+// purged and move to the survivor when it is merged.
+type AddressRepository interface {
+	FindByID(id string) (Address, error)
+	ListByCustomer(customerID string) ([]Address, error)
+	Save(address Address) (Address, error)
+	Delete(id string) error
+}
+
+// NullAddressRepository stub implementation returning ErrNotImplemented.
+type NullAddressRepository struct{}
+
+func (NullAddressRepository) FindByID(id string) (Address, error) {
+	return Address{}, ErrNotImplemented
+}
+
+func (NullAddressRepository) ListByCustomer(customerID string) ([]Address, error) {
+	return nil, ErrNotImplemented
+}
+
+func (NullAddressRepository) Save(address Address) (Address, error) {
+	return Address{}, ErrNotImplemented
+}
+
+func (NullAddressRepository) Delete(id string) error {
+	return ErrNotImplemented
+}
+
+// AddressInput defines data for adding an address. Label defaults to
+// LabelHome and Country to "US". When Location is nil the address is
+// geocoded.
+type AddressInput struct {
+	Label      string
+	Line1      string
+	Line2      string
+	City       string
+	Region     string
+	PostalCode string
+	Country    string
+	Location   *geo.Point
+}
+
+// AddressUpdate defines data for updating an address. Changing any part of
+// the address re-geocodes it unless Location is also given.
+type AddressUpdate struct {
+	Label      *string
+	Line1      *string
+	Line2      *string
+	City       *string
+	Region     *string
+	PostalCode *string
+	Country    *string
+	Location   *geo.Point
+}
+
+// WithAddresses sets the repository for customer addresses.
+func WithAddresses(repo AddressRepository) Option {
+	return func(s *service) { s.addresses = repo }
+}
+
+// WithGeocoder sets the geocoder used to locate addresses. Without one,
+// addresses have no Location unless it is entered.
+func WithGeocoder(g geo.Geocoder) Option {
+	return func(s *service) { s.geocoder = g }
+}
+
+func (s *service) Addresses(customerID string) ([]Address, error) {
+	if _, err := s.repo.FindByID(customerID); err != nil {
+		return nil, err
+	}
+	return s.addresses.ListByCustomer(customerID)
+}
+
+func (s *service) AddAddress(customerID string, input AddressInput) (Address, error) {
+	if _, err := s.repo.FindByID(customerID); err != nil {
+		return Address{}, err
+	}
+	a := Address{
+		CustomerID: customerID,
+		Label:      input.Label,
+		Line1:      input.Line1,
+		Line2:      input.Line2,
+		City:       input.City,
+		Region:     input.Region,
+		PostalCode: input.PostalCode,
+		Country:    input.Country,
+	}
+	if err := s.prepareAddress(&a, input.Location, true); err != nil {
+		return Address{}, err
+	}
+	return s.addresses.Save(a)
+}
+
+func (s *service) UpdateAddress(customerID, addressID string, input AddressUpdate) (Address, error) {
+	a, err := s.customerAddress(customerID, addressID)
+	if err != nil {
+		return Address{}, err
+	}
+
+	before := a.Query()
+	if input.Label != nil {
+		a.Label = *input.Label
+	}
+	if input.Line1 != nil {
+		a.Line1 = *input.Line1
+	}
+	if input.Line2 != nil {
+		a.Line2 = *input.Line2
+	}
+	if input.City != nil {
+		a.City = *input.City
+	}
+	if input.Region != nil {
+		a.Region = *input.Region
+	}
+	if input.PostalCode != nil {
+		a.PostalCode = *input.PostalCode
+	}
+	if input.Country != nil {
+		a.Country = *input.Country
+	}
+
+	if err := s.prepareAddress(&a, input.Location, a.Query() != before); err != nil {
+		return Address{}, err
+	}
+	return s.addresses.Save(a)
+}
+
+func (s *service) DeleteAddress(customerID, addressID string) error {
+	if _, err := s.customerAddress(customerID, addressID); err != nil {
+		return err
+	}
+	return s.addresses.Delete(addressID)
+}
+
+// customerAddress loads an address, treating addresses of other customers as
+// missing.
+func (s *service) customerAddress(customerID, addressID string) (Address, error) {
+	if _, err := s.repo.FindByID(customerID); err != nil {
+		return Address{}, err
+	}
+	a, err := s.addresses.FindByID(addressID)
+	if err != nil {
+		return Address{}, err
+	}
+	if a.CustomerID != customerID {
+		return Address{}, ErrAddressNotFound
+	}
+	return a, nil
+}
+
+// prepareAddress normalizes and validates a, then sets its Location: to
+// location when given, otherwise by geocoding when relocate is set. A failed
+// lookup leaves the address without a Location rather than rejecting it.
+func (s *service) prepareAddress(a *Address, location *geo.Point, relocate bool) error {
+	a.Label = strings.ToLower(strings.TrimSpace(a.Label))
+	if a.Label == "" {
+		a.Label = LabelHome
+	}
+	a.Line1 = strings.TrimSpace(a.Line1)
+	a.Line2 = strings.TrimSpace(a.Line2)
+	a.City = strings.TrimSpace(a.City)
+	a.Region = strings.ToUpper(strings.TrimSpace(a.Region))
+	a.PostalCode = strings.ToUpper(strings.TrimSpace(a.PostalCode))
+	a.Country = strings.ToUpper(strings.TrimSpace(a.Country))
+	if a.Country == "" {
+		a.Country = "US"
+	}
+
+	switch {
+	case a.Label != LabelHome && a.Label != LabelWork && a.Label != LabelFleetYard && a.Label != LabelOther:
+		return fmt.Errorf("%w: unknown label %q", ErrInvalidAddress, a.Label)
+	case a.Line1 == "":
+		return fmt.Errorf("%w: line1 is required", ErrInvalidAddress)
+	case a.City == "" && a.PostalCode == "":
+		return fmt.Errorf("%w: city or postal code is required", ErrInvalidAddress)
+	}
+
+	if location != nil {
+		if location.Lat < -90 || location.Lat > 90 || location.Lng < -180 || location.Lng > 180 {
+			return fmt.Errorf("%w: location is out of range", ErrInvalidAddress)
+		}
+		p := *location
+		a.Location, a.GeocodedBy = &p, GeocodedManual
+		return nil
+	}
+	if !relocate {
+		return nil
+	}
+	a.Location, a.GeocodedBy = nil, ""
+	if res, err := s.geocoder.Geocode(a.Query()); err == nil {
+		a.Location, a.GeocodedBy = &res.Point, res.Source
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package customers_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/geo"
+	"github.com/ezmobilemechanic/platform/internal/storage/memory"
+)
+
+func TestServiceAddresses(t *testing.T) {
+	zips, err := geo.ReadZIPCentroids(strings.NewReader("zip,lat,lng\n32207,30.292,-81.636\n32256,30.206,-81.552\n"))
+	if err != nil {
+		t.Fatalf("load zips: %v", err)
+	}
+	repo := memory.NewCustomerRepository()
+	svc := customers.NewService(repo, customers.WithAddresses(repo.Addresses()), customers.WithGeocoder(zips))
+
+	owner, err := svc.Create(customers.CreateInput{FirstName: "Alex", Email: "alex@example.com"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	other, err := svc.Create(customers.CreateInput{FirstName: "Sam", Email: "sam@example.com"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	home, err := svc.AddAddress(owner.ID, customers.AddressInput{Line1: " 1 Main St ", City: "Jacksonville", Region: "fl", PostalCode: "32207-1234"})
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if home.Label != customers.LabelHome || home.Country != "US" || home.Region != "FL" || home.Line1 != "1 Main St" {
+		t.Fatalf("expected normalized defaults, got %+v", home)
+	}
+	if home.Location == nil || *home.Location != (geo.Point{Lat: 30.292, Lng: -81.636}) || home.GeocodedBy != geo.SourceZIPCentroid {
+		t.Fatalf("expected zip centroid location, got %+v", home)
+	}
+
+	yard, err := svc.AddAddress(owner.ID, customers.AddressInput{
+		Label:    "fleet_yard",
+		Line1:    "9 Depot Rd",
+		City:     "Orange Park",
+		Location: &geo.Point{Lat: 30.166, Lng: -81.706},
+	})
+	if err != nil {
+		t.Fatalf("add yard: %v", err)
+	}
+	if yard.GeocodedBy != customers.GeocodedManual {
+		t.Fatalf("expected manual location, got %+v", yard)
+	}
+
+	for _, in := range []customers.AddressInput{
+		{Label: "cabin", Line1: "1 Lake Rd", PostalCode: "32207"},
+		{PostalCode: "32207"},
+		{Line1: "1 Main St"},
+		{Line1: "1 Main St", City: "X", Location: &geo.Point{Lat: 91}},
+	} {
+		if _, err := svc.AddAddress(owner.ID, in); !errors.Is(err, customers.ErrInvalidAddress) {
+			t.Fatalf("add %+v: expected ErrInvalidAddress, got %v", in, err)
+		}
+	}
+	if _, err := svc.AddAddress("missing", customers.AddressInput{Line1: "1 Main St", City: "X"}); !errors.Is(err, customers.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for unknown customer, got %v", err)
+	}
+
+	// Moving the address re-geocodes it; relabelling keeps its location.
+	zip := "32256"
+	moved, err := svc.UpdateAddress(owner.ID, home.ID, customers.AddressUpdate{PostalCode: &zip})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if moved.Location == nil || moved.Location.Lat != 30.206 {
+		t.Fatalf("expected new centroid, got %+v", moved.Location)
+	}
+	label := "work"
+	relabelled, err := svc.UpdateAddress(owner.ID, yard.ID, customers.AddressUpdate{Label: &label})
+	if err != nil {
+		t.Fatalf("relabel: %v", err)
+	}
+	if relabelled.Label != customers.LabelWork || relabelled.GeocodedBy != customers.GeocodedManual {
+		t.Fatalf("expected manual location to survive relabel, got %+v", relabelled)
+	}
+	unknown := "99999"
+	if ungeocoded, err := svc.UpdateAddress(owner.ID, home.ID, customers.AddressUpdate{PostalCode: &unknown}); err != nil || ungeocoded.Location != nil {
+		t.Fatalf("expected unknown zip to clear location, got %+v (%v)", ungeocoded, err)
+	}
+
+	if _, err := svc.UpdateAddress(other.ID, home.ID, customers.AddressUpdate{Label: &label}); !errors.Is(err, customers.ErrAddressNotFound) {
+		t.Fatalf("expected ErrAddressNotFound for another customer's address, got %v", err)
+	}
+	if err := svc.DeleteAddress(other.ID, home.ID); !errors.Is(err, customers.ErrAddressNotFound) {
+		t.Fatalf("expected ErrAddressNotFound deleting another customer's address, got %v", err)
+	}
+
+	if err := svc.DeleteAddress(owner.ID, home.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	list, err := svc.Addresses(owner.ID)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != yard.ID {
+		t.Fatalf("expected only the yard address, got %+v", list)
+	}
+}
@@ -0,0 +1,259 @@
+package customers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Consent errors.
+var (
+	ErrNoConsent      = errors.New("customer has not consented to this message")
+	ErrInvalidConsent = errors.New("invalid consent")
+)
+
+// Channel is a medium messages are sent through.
+type Channel string
+
+const (
+	ChannelSMS   Channel = "sms"
+	ChannelEmail Channel = "email"
+	ChannelVoice Channel = "voice"
+)
+
+// Channels lists every channel, in display order.
+var Channels = []Channel{ChannelSMS, ChannelEmail, ChannelVoice}
+
+// Purpose separates messages about the customer's own service from
+// promotions. TCPA requires prior express written consent for marketing texts
+// and calls; transactional messages only need the customer to have provided
+// the number.
+type Purpose string
+
+const (
+	PurposeTransactional Purpose = "transactional"
+	PurposeMarketing     Purpose = "marketing"
+)
+
+// Purposes lists every purpose, in display order.
+var Purposes = []Purpose{PurposeTransactional, PurposeMarketing}
+
+// Sources of consent entries.
+const (
+	SourceWebForm   = "web_form"
+	SourceKeyword   = "keyword"
+	SourceStaff     = "staff"
+	SourceImport    = "import"
+	SourceMigration = "migration"
+	SourceErasure   = "erasure"
+)
+
+// ConsentEntry is one grant or revocation in a customer's consent ledger.
+// Entries are never updated or deleted; the latest entry for a channel and
+// purpose decides.
+type ConsentEntry struct {
+	ID         string
+	CustomerID string
+	Channel    Channel
+	Purpose    Purpose
+	Granted    bool
+	Source     string
+	// Detail qualifies the source: the keyword texted, the form URL or the
+	// staff member.
+	Detail     string
+	IPAddress  string
+	UserAgent  string
+	RecordedAt time.Time
+}
+
+// ConsentState is the effective consent for a channel and purpose. Entry is
+// nil when no entry was recorded and Granted holds the default.
+type ConsentState struct {
+	Channel Channel
+	Purpose Purpose
+	Granted bool
+	Entry   *ConsentEntry
+}
+
+// ConsentRepository stores the consent ledger. ListByCustomer returns entries
+// oldest first. Entries move to the survivor when customers are merged and
+// are removed when the customer is purged.
+type ConsentRepository interface {
+	Append(entry ConsentEntry) (ConsentEntry, error)
+	ListByCustomer(customerID string) ([]ConsentEntry, error)
+}
+
+// NullConsentRepository stub implementation returning ErrNotImplemented.
+type NullConsentRepository struct{}
+
+func (NullConsentRepository) Append(entry ConsentEntry) (ConsentEntry, error) {
+	return ConsentEntry{}, ErrNotImplemented
+}
+
+func (NullConsentRepository) ListByCustomer(customerID string) ([]ConsentEntry, error) {
+	return nil, ErrNotImplemented
+}
+
+// ConsentInput records a grant or revocation. An empty Purpose applies to
+// every purpose.
+type ConsentInput struct {
+	Channel   Channel
+	Purpose   Purpose
+	Granted   bool
+	Source    string
+	Detail    string
+	IPAddress string
+	UserAgent string
+}
+
+// WithConsents sets the repository for the consent ledger.
+func WithConsents(repo ConsentRepository) Option {
+	return func(s *service) { s.consents = repo }
+}
+
+// Keyword replies recognised by ApplyKeyword, following the CTIA defaults.
+var (
+	stopKeywords  = []string{"STOP", "STOPALL", "UNSUBSCRIBE", "CANCEL", "END", "QUIT"}
+	startKeywords = []string{"START", "UNSTOP", "YES"}
+)
+
+func (s *service) RecordConsent(customerID string, input ConsentInput) ([]ConsentEntry, error) {
+	if _, err := s.repo.FindByID(customerID); err != nil {
+		return nil, err
+	}
+
+	input.Source = strings.TrimSpace(input.Source)
+	switch {
+	case !validChannel(input.Channel):
+		return nil, fmt.Errorf("%w: unknown channel %q", ErrInvalidConsent, input.Channel)
+	case input.Purpose != "" && !validPurpose(input.Purpose):
+		return nil, fmt.Errorf("%w: unknown purpose %q", ErrInvalidConsent, input.Purpose)
+	case input.Source == "":
+		return nil, fmt.Errorf("%w: source is required", ErrInvalidConsent)
+	}
+
+	purposes := Purposes
+	if input.Purpose != "" {
+		purposes = []Purpose{input.Purpose}
+	}
+	entries := make([]ConsentEntry, 0, len(purposes))
+	for _, p := range purposes {
+		e, err := s.consents.Append(ConsentEntry{
+			CustomerID: customerID,
+			Channel:    input.Channel,
+			Purpose:    p,
+			Granted:    input.Granted,
+			Source:     input.Source,
+			Detail:     strings.TrimSpace(input.Detail),
+			IPAddress:  strings.TrimSpace(input.IPAddress),
+			UserAgent:  strings.TrimSpace(input.UserAgent),
+		})
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// ApplyKeyword records the effect of an SMS reply. STOP and its synonyms
+// revoke every SMS purpose; START re-enables transactional texts only, since
+// marketing needs fresh written consent. Other replies record nothing.
+func (s *service) ApplyKeyword(customerID, keyword string) ([]ConsentEntry, error) {
+	word := strings.ToUpper(strings.TrimSpace(keyword))
+	input := ConsentInput{Channel: ChannelSMS, Source: SourceKeyword, Detail: word}
+	switch {
+	case contains(stopKeywords, word):
+		input.Granted = false
+	case contains(startKeywords, word):
+		input.Granted, input.Purpose = true, PurposeTransactional
+	default:
+		return nil, nil
+	}
+	return s.RecordConsent(customerID, input)
+}
+
+func (s *service) ConsentHistory(customerID string) ([]ConsentEntry, error) {
+	if _, err := s.repo.FindByID(customerID); err != nil {
+		return nil, err
+	}
+	return s.consents.ListByCustomer(customerID)
+}
+
+func (s *service) EffectiveConsent(customerID string) ([]ConsentState, error) {
+	history, err := s.ConsentHistory(customerID)
+	if err != nil {
+		return nil, err
+	}
+	return effectiveConsent(history), nil
+}
+
+func (s *service) CheckConsent(customerID string, channel Channel, purpose Purpose) error {
+	states, err := s.EffectiveConsent(customerID)
+	if err != nil {
+		return err
+	}
+	for _, st := range states {
+		if st.Channel == channel && st.Purpose == purpose {
+			if !st.Granted {
+				return ErrNoConsent
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: unknown channel %q or purpose %q", ErrInvalidConsent, channel, purpose)
+}
+
+// effectiveConsent folds an oldest-first ledger into the state of every
+// channel and purpose. Without an entry, transactional messages are allowed
+// and marketing is not.
+func effectiveConsent(history []ConsentEntry) []ConsentState {
+	latest := make(map[Channel]map[Purpose]*ConsentEntry)
+	for i := range history {
+		e := &history[i]
+		if latest[e.Channel] == nil {
+			latest[e.Channel] = make(map[Purpose]*ConsentEntry)
+		}
+		latest[e.Channel][e.Purpose] = e
+	}
+
+	states := make([]ConsentState, 0, len(Channels)*len(Purposes))
+	for _, c := range Channels {
+		for _, p := range Purposes {
+			st := ConsentState{Channel: c, Purpose: p, Granted: p == PurposeTransactional}
+			if e := latest[c][p]; e != nil {
+				st.Granted, st.Entry = e.Granted, e
+			}
+			states = append(states, st)
+		}
+	}
+	return states
+}
+
+func validChannel(c Channel) bool {
+	for _, v := range Channels {
+		if c == v {
+			return true
+		}
+	}
+	return false
+}
+
+func validPurpose(p Purpose) bool {
+	for _, v := range Purposes {
+		if p == v {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
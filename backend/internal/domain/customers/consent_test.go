@@ -0,0 +1,96 @@
+package customers_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/storage/memory"
+)
+
+func TestServiceConsent(t *testing.T) {
+	repo := memory.NewCustomerRepository()
+	svc := customers.NewService(repo, customers.WithConsents(repo.Consents()))
+
+	c, err := svc.Create(customers.CreateInput{FirstName: "Alex", Email: "alex@example.com"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if err := svc.CheckConsent(c.ID, customers.ChannelSMS, customers.PurposeTransactional); err != nil {
+		t.Fatalf("transactional texts are allowed by default, got %v", err)
+	}
+	if err := svc.CheckConsent(c.ID, customers.ChannelSMS, customers.PurposeMarketing); !errors.Is(err, customers.ErrNoConsent) {
+		t.Fatalf("marketing texts need consent, got %v", err)
+	}
+
+	entries, err := svc.RecordConsent(c.ID, customers.ConsentInput{Channel: customers.ChannelSMS, Granted: true, Source: customers.SourceWebForm, IPAddress: "203.0.113.7"})
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if len(entries) != len(customers.Purposes) {
+		t.Fatalf("expected one entry per purpose, got %+v", entries)
+	}
+	if err := svc.CheckConsent(c.ID, customers.ChannelSMS, customers.PurposeMarketing); err != nil {
+		t.Fatalf("expected marketing consent, got %v", err)
+	}
+
+	if _, err := svc.ApplyKeyword(c.ID, " stop "); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	for _, p := range customers.Purposes {
+		if err := svc.CheckConsent(c.ID, customers.ChannelSMS, p); !errors.Is(err, customers.ErrNoConsent) {
+			t.Fatalf("STOP must revoke %s texts, got %v", p, err)
+		}
+	}
+	if err := svc.CheckConsent(c.ID, customers.ChannelEmail, customers.PurposeTransactional); err != nil {
+		t.Fatalf("STOP must not affect email, got %v", err)
+	}
+
+	if _, err := svc.ApplyKeyword(c.ID, "START"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if err := svc.CheckConsent(c.ID, customers.ChannelSMS, customers.PurposeTransactional); err != nil {
+		t.Fatalf("START must restore transactional texts, got %v", err)
+	}
+	if err := svc.CheckConsent(c.ID, customers.ChannelSMS, customers.PurposeMarketing); !errors.Is(err, customers.ErrNoConsent) {
+		t.Fatalf("START must not restore marketing texts, got %v", err)
+	}
+	if entries, err := svc.ApplyKeyword(c.ID, "thanks"); err != nil || len(entries) != 0 {
+		t.Fatalf("other replies must record nothing, got %v (%v)", entries, err)
+	}
+
+	history, err := svc.ConsentHistory(c.ID)
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if len(history) != 5 || history[0].IPAddress != "203.0.113.7" || history[4].Detail != "START" {
+		t.Fatalf("unexpected history %+v", history)
+	}
+	states, err := svc.EffectiveConsent(c.ID)
+	if err != nil {
+		t.Fatalf("effective: %v", err)
+	}
+	for _, st := range states {
+		if st.Channel == customers.ChannelSMS && st.Purpose == customers.PurposeTransactional && (st.Entry == nil || st.Entry.ID != history[4].ID) {
+			t.Fatalf("expected the START entry to decide, got %+v", st)
+		}
+		if st.Channel == customers.ChannelVoice && st.Entry != nil {
+			t.Fatalf("expected voice defaults, got %+v", st)
+		}
+	}
+
+	bad := []customers.ConsentInput{
+		{Channel: "fax", Granted: true, Source: customers.SourceStaff},
+		{Channel: customers.ChannelEmail, Purpose: "surveys", Source: customers.SourceStaff},
+		{Channel: customers.ChannelEmail, Source: " "},
+	}
+	for _, in := range bad {
+		if _, err := svc.RecordConsent(c.ID, in); !errors.Is(err, customers.ErrInvalidConsent) {
+			t.Fatalf("%+v: expected ErrInvalidConsent, got %v", in, err)
+		}
+	}
+	if _, err := svc.RecordConsent("missing", customers.ConsentInput{Channel: customers.ChannelEmail, Source: customers.SourceStaff}); !errors.Is(err, customers.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,204 @@
+package customers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Contact errors.
+var (
+	ErrContactNotFound = errors.New("contact not found")
+	ErrInvalidContact  = errors.New("invalid contact")
+	// ErrNotFleet is returned when adding contacts to an individual.
+	ErrNotFleet = errors.New("customer is not a fleet account")
+)
+
+// ContactRole says what a contact may do for a fleet account.
+type ContactRole string
+
+const (
+	// RoleFleetManager runs the account and approves work.
+	RoleFleetManager ContactRole = "fleet_manager"
+	// RoleBilling receives invoices and statements.
+	RoleBilling ContactRole = "billing"
+	// RoleDriver brings vehicles in and reports problems but cannot approve
+	// work.
+	RoleDriver ContactRole = "driver"
+)
+
+// ContactRoles lists every role, in display order.
+var ContactRoles = []ContactRole{RoleFleetManager, RoleBilling, RoleDriver}
+
+// Contact is a person authorized to deal with us for a fleet account.
+type Contact struct {
+	ID         string
+	CustomerID string
+	Name       string
+	Role       ContactRole
+	Email      string
+	// Phone is the display form of the number and PhoneE164 its normalized
+	// form, as for customers.
+	Phone     string
+	PhoneE164 string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// CanApprove reports whether the contact may approve quotes.
+func (c Contact) CanApprove() bool {
+	return c.Role == RoleFleetManager
+}
+
+// ContactRepository persists fleet contacts. ListByCustomer orders contacts
+// by creation. Contacts are removed with their customer when it is purged
+// and move to the survivor when it is merged.
+type ContactRepository interface {
+	FindByID(id string) (Contact, error)
+	ListByCustomer(customerID string) ([]Contact, error)
+	Save(contact Contact) (Contact, error)
+	Delete(id string) error
+}
+
+// NullContactRepository stub implementation returning ErrNotImplemented.
+type NullContactRepository struct{}
+
+func (NullContactRepository) FindByID(id string) (Contact, error) {
+	return Contact{}, ErrNotImplemented
+}
+
+func (NullContactRepository) ListByCustomer(customerID string) ([]Contact, error) {
+	return nil, ErrNotImplemented
+}
+
+func (NullContactRepository) Save(contact Contact) (Contact, error) {
+	return Contact{}, ErrNotImplemented
+}
+
+func (NullContactRepository) Delete(id string) error {
+	return ErrNotImplemented
+}
+
+// ContactInput defines a contact to add. A contact needs a name, a role and
+// an email or phone.
+type ContactInput struct {
+	Name  string
+	Role  ContactRole
+	Email string
+	Phone string
+}
+
+// ContactUpdate defines data for updating a contact.
+type ContactUpdate struct {
+	Name  *string
+	Role  *ContactRole
+	Email *string
+	Phone *string
+}
+
+// WithContacts sets the repository for fleet contacts.
+func WithContacts(repo ContactRepository) Option {
+	return func(s *service) { s.contacts = repo }
+}
+
+func (s *service) Contacts(customerID string) ([]Contact, error) {
+	if _, err := s.repo.FindByID(customerID); err != nil {
+		return nil, err
+	}
+	return s.contacts.ListByCustomer(customerID)
+}
+
+func (s *service) AddContact(customerID string, input ContactInput) (Contact, error) {
+	c, err := s.repo.FindByID(customerID)
+	if err != nil {
+		return Contact{}, err
+	}
+	if !c.IsFleet() {
+		return Contact{}, ErrNotFleet
+	}
+	contact := Contact{CustomerID: c.ID, Name: input.Name, Role: input.Role, Email: input.Email}
+	if err := s.prepareContact(&contact, &input.Phone); err != nil {
+		return Contact{}, err
+	}
+	return s.contacts.Save(contact)
+}
+
+func (s *service) UpdateContact(customerID, contactID string, input ContactUpdate) (Contact, error) {
+	contact, err := s.customerContact(customerID, contactID)
+	if err != nil {
+		return Contact{}, err
+	}
+	if input.Name != nil {
+		contact.Name = *input.Name
+	}
+	if input.Role != nil {
+		contact.Role = *input.Role
+	}
+	if input.Email != nil {
+		contact.Email = *input.Email
+	}
+	if err := s.prepareContact(&contact, input.Phone); err != nil {
+		return Contact{}, err
+	}
+	return s.contacts.Save(contact)
+}
+
+func (s *service) DeleteContact(customerID, contactID string) error {
+	if _, err := s.customerContact(customerID, contactID); err != nil {
+		return err
+	}
+	return s.contacts.Delete(contactID)
+}
+
+// customerContact loads a contact, treating contacts of other customers as
+// missing.
+func (s *service) customerContact(customerID, contactID string) (Contact, error) {
+	if _, err := s.repo.FindByID(customerID); err != nil {
+		return Contact{}, err
+	}
+	c, err := s.contacts.FindByID(contactID)
+	if err != nil {
+		return Contact{}, err
+	}
+	if c.CustomerID != customerID {
+		return Contact{}, ErrContactNotFound
+	}
+	return c, nil
+}
+
+// prepareContact normalizes and validates c, setting its phone from phone
+// when that is not nil.
+func (s *service) prepareContact(c *Contact, phoneNumber *string) error {
+	c.Name = strings.TrimSpace(c.Name)
+	c.Role = ContactRole(strings.ToLower(strings.TrimSpace(string(c.Role))))
+	c.Email = strings.TrimSpace(c.Email)
+	if phoneNumber != nil {
+		var holder Customer
+		if err := s.setPhone(&holder, *phoneNumber); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidContact, err)
+		}
+		c.Phone, c.PhoneE164 = holder.Phone, holder.PhoneE164
+	}
+
+	switch {
+	case c.Name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidContact)
+	case !validRole(c.Role):
+		return fmt.Errorf("%w: unknown role %q", ErrInvalidContact, c.Role)
+	case c.Email == "" && c.Phone == "":
+		return fmt.Errorf("%w: email or phone required", ErrInvalidContact)
+	case checkEmail(c.Email) != nil:
+		return fmt.Errorf("%w: %q is not an email address", ErrInvalidContact, c.Email)
+	}
+	return nil
+}
+
+func validRole(r ContactRole) bool {
+	for _, v := range ContactRoles {
+		if r == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package customers_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
+	"github.com/ezmobilemechanic/platform/internal/storage/memory"
+)
+
+func TestServiceFleetAccounts(t *testing.T) {
+	repo := memory.NewCustomerRepository()
+	svc := customers.NewService(repo, customers.WithContacts(repo.Contacts()))
+
+	person, err := svc.Create(customers.CreateInput{FirstName: "Alex", Email: "alex@example.com"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if person.Type != customers.AccountIndividual || person.Billing != customers.BillingPerVisit || person.IsFleet() {
+		t.Fatalf("expected an individual billed per visit, got %+v", person)
+	}
+
+	for name, in := range map[string]customers.CreateInput{
+		"no company":       {Type: customers.AccountFleet, Email: "a@example.com"},
+		"unknown type":     {Type: "corporate", CompanyName: "Acme", Email: "b@example.com"},
+		"individual po":    {FirstName: "Jo", PORequired: true, Email: "c@example.com"},
+		"individual month": {FirstName: "Jo", Billing: customers.BillingMonthly, Email: "d@example.com"},
+		"unknown billing":  {Type: customers.AccountFleet, CompanyName: "Acme", Billing: "weekly", Email: "e@example.com"},
+	} {
+		if _, err := svc.Create(in); !errors.Is(err, customers.ErrInvalid) {
+			t.Errorf("%s: expected ErrInvalid, got %v", name, err)
+		}
+	}
+
+	fleet, err := svc.Create(customers.CreateInput{
+		Type:        " Fleet ",
+		CompanyName: " Acme Plumbing ",
+		Email:       "office@acme.example",
+		PORequired:  true,
+		Billing:     customers.BillingMonthly,
+	})
+	if err != nil {
+		t.Fatalf("create fleet: %v", err)
+	}
+	if !fleet.IsFleet() || fleet.CompanyName != "Acme Plumbing" || !fleet.PORequired || fleet.Billing != customers.BillingMonthly {
+		t.Fatalf("unexpected fleet %+v", fleet)
+	}
+
+	if _, err := svc.AddContact(person.ID, customers.ContactInput{Name: "Sam", Role: customers.RoleDriver, Email: "sam@example.com"}); !errors.Is(err, customers.ErrNotFleet) {
+		t.Fatalf("expected ErrNotFleet, got %v", err)
+	}
+	for _, in := range []customers.ContactInput{
+		{Role: customers.RoleDriver, Email: "x@example.com"},
+		{Name: "Sam", Role: "owner", Email: "x@example.com"},
+		{Name: "Sam", Role: customers.RoleDriver},
+		{Name: "Sam", Role: customers.RoleDriver, Email: "not an email"},
+	} {
+		if _, err := svc.AddContact(fleet.ID, in); !errors.Is(err, customers.ErrInvalidContact) {
+			t.Errorf("%+v: expected ErrInvalidContact, got %v", in, err)
+		}
+	}
+
+	manager, err := svc.AddContact(fleet.ID, customers.ContactInput{Name: " Dana Reyes ", Role: "Fleet_Manager", Phone: "(904) 555-0100"})
+	if err != nil {
+		t.Fatalf("add contact: %v", err)
+	}
+	if manager.Name != "Dana Reyes" || manager.Role != customers.RoleFleetManager || manager.PhoneE164 != "+19045550100" || !manager.CanApprove() {
+		t.Fatalf("unexpected contact %+v", manager)
+	}
+	driver, err := svc.AddContact(fleet.ID, customers.ContactInput{Name: "Lee", Role: customers.RoleDriver, Email: "lee@acme.example"})
+	if err != nil {
+		t.Fatalf("add contact: %v", err)
+	}
+	if driver.CanApprove() {
+		t.Fatalf("drivers must not approve work")
+	}
+
+	role := customers.RoleBilling
+	updated, err := svc.UpdateContact(fleet.ID, driver.ID, customers.ContactUpdate{Role: &role})
+	if err != nil || updated.Role != customers.RoleBilling || updated.Email != "lee@acme.example" {
+		t.Fatalf("update contact: %+v, %v", updated, err)
+	}
+	if _, err := svc.UpdateContact(person.ID, driver.ID, customers.ContactUpdate{Role: &role}); !errors.Is(err, customers.ErrContactNotFound) {
+		t.Fatalf("expected contacts of other customers to be hidden, got %v", err)
+	}
+
+	individual := customers.AccountIndividual
+	if _, err := svc.Update(fleet.ID, customers.UpdateInput{Type: &individual}); !errors.Is(err, customers.ErrInvalid) {
+		t.Fatalf("expected a fleet with contacts to stay a fleet, got %v", err)
+	}
+
+	if err := svc.DeleteContact(fleet.ID, driver.ID); err != nil {
+		t.Fatalf("delete contact: %v", err)
+	}
+	list, err := svc.Contacts(fleet.ID)
+	if err != nil || len(list) != 1 || list[0].ID != manager.ID {
+		t.Fatalf("contacts: %+v, %v", list, err)
+	}
+
+	page, err := svc.List(listing.Spec{Search: "plumb"})
+	if err != nil || len(page.Items) != 1 || page.Items[0].ID != fleet.ID {
+		t.Fatalf("expected search by company name, got %+v, %v", page.Items, err)
+	}
+}
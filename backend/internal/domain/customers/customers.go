@@ -2,33 +2,132 @@ package customers
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
+	"github.com/ezmobilemechanic/platform/internal/geo"
+	"github.com/ezmobilemechanic/platform/internal/phone"
 )
 
 // Domain-level errors for customers.
 var (
 	ErrNotImplemented = errors.New("customers repository: not implemented")
 	ErrNotFound       = errors.New("customer not found")
+	ErrEmailExists    = errors.New("customer email already in use")
+	ErrInvalidPhone   = errors.New("invalid customer phone number")
+	ErrInvalid        = errors.New("invalid customer")
+	ErrMergeSelf      = errors.New("cannot merge a customer into itself")
+)
+
+// AccountType says whether a customer is a person or a business.
+type AccountType string
+
+const (
+	AccountIndividual AccountType = "individual"
+	// AccountFleet is a business with several vehicles. It has contacts who
+	// deal with us on its behalf, and its vehicles carry unit numbers.
+	AccountFleet AccountType = "fleet"
+)
+
+// Billing says how a customer's work is invoiced.
+type Billing string
+
+const (
+	BillingPerVisit Billing = "per_visit"
+	// BillingMonthly gathers a fleet account's work into one invoice a
+	// month.
+	BillingMonthly Billing = "monthly"
 )
 
 // Customer represents a service customer in our domain.
 type Customer struct {
-	ID           string
-	ExternalID   string
-	FirstName    string
-	LastName     string
-	Email        string
-	Phone        string
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
-	MarketingOpt bool
+	ID         string
+	ExternalID string
+	// Type is empty for customers created before fleet accounts, which are
+	// individuals; IsFleet tells the two apart.
+	Type AccountType
+	// CompanyName names a fleet account. FirstName and LastName are then its
+	// main contact and may be blank.
+	CompanyName string
+	FirstName   string
+	LastName    string
+	Email       string
+	// Phone is the display form of the number and PhoneE164 its normalized
+	// form. Both are empty when no phone is on file.
+	Phone     string
+	PhoneE164 string
+	// Tags are free-form labels such as "fleet" or "prefers text", kept
+	// normalized by NormalizeTags.
+	Tags []string
+	// PORequired makes the customer's quotes need a purchase order number
+	// before they are accepted. Only fleet accounts set it.
+	PORequired bool
+	// Billing is empty for customers created before it was recorded, which
+	// are billed per visit.
+	Billing   Billing
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// DeletedAt is set while the customer is soft-deleted.
+	DeletedAt *time.Time
+}
+
+// IsFleet reports whether the customer is a fleet account.
+func (c Customer) IsFleet() bool {
+	return c.Type == AccountFleet
+}
+
+// Merge records that one customer was folded into another. It is kept as an
+// audit trail and to redirect lookups of the merged ID.
+type Merge struct {
+	MergedID   string
+	SurvivorID string
+	// VehicleIDs and QuoteIDs list the records re-parented to the survivor.
+	VehicleIDs []string
+	QuoteIDs   []string
+	MergedAt   time.Time
+}
+
+// MatchKeys selects possible duplicates of a customer: active customers other
+// than ExcludeID sharing the email, E.164 phone or last name
+// (case-insensitively). Empty keys are ignored.
+type MatchKeys struct {
+	ExcludeID string
+	Email     string
+	PhoneE164 string
+	LastName  string
 }
 
 // Repository abstracts persistence for customers.
+//
+// List honours the created range and Search filters of the spec; Status does
+// not apply to customers. Search is interpreted by ParseSearch, in the phone
+// region the repository was given, and matches names, email, phone digits and
+// the VINs of the customer's vehicles, or exactly one tag.
+//
+// Soft-deleted customers are invisible to FindByID, Save and List, and their
+// email may be reused. Delete and Restore return ErrNotFound when the customer
+// is not in the expected state. Purge permanently removes customers deleted
+// before the cutoff, with their addresses, contacts, consent ledger and
+// notes, and reports how many were removed.
+//
+// Merge atomically moves every vehicle, quote, address, contact, consent
+// entry and note of the customer mergedID to survivor, saves survivor, removes the
+// merged customer and records the Merge. It returns ErrNotFound unless both
+// customers are active. MergedInto returns the customer a merged ID now
+// resolves to, following chains of merges, or ErrNotFound if id was never
+// merged.
 type Repository interface {
 	FindByID(id string) (Customer, error)
 	Save(customer Customer) (Customer, error)
-	List(offset, limit int) ([]Customer, error)
+	List(spec listing.Spec) (listing.Page[Customer], error)
+	Delete(id string) (Customer, error)
+	Restore(id string) (Customer, error)
+	Purge(deletedBefore time.Time) (int, error)
+	FindMatches(keys MatchKeys) ([]Customer, error)
+	Merge(survivor Customer, mergedID string) (Merge, error)
+	MergedInto(id string) (string, error)
 }
 
 // NullRepository stub implementation returning ErrNotImplemented.
@@ -42,85 +141,428 @@ func (NullRepository) Save(customer Customer) (Customer, error) {
 	return Customer{}, ErrNotImplemented
 }
 
-func (NullRepository) List(offset, limit int) ([]Customer, error) {
+func (NullRepository) List(spec listing.Spec) (listing.Page[Customer], error) {
+	return listing.Page[Customer]{}, ErrNotImplemented
+}
+
+func (NullRepository) Delete(id string) (Customer, error) {
+	return Customer{}, ErrNotImplemented
+}
+
+func (NullRepository) Restore(id string) (Customer, error) {
+	return Customer{}, ErrNotImplemented
+}
+
+func (NullRepository) Purge(deletedBefore time.Time) (int, error) {
+	return 0, ErrNotImplemented
+}
+
+func (NullRepository) FindMatches(keys MatchKeys) ([]Customer, error) {
 	return nil, ErrNotImplemented
 }
 
+func (NullRepository) Merge(survivor Customer, mergedID string) (Merge, error) {
+	return Merge{}, ErrNotImplemented
+}
+
+func (NullRepository) MergedInto(id string) (string, error) {
+	return "", ErrNotImplemented
+}
+
 // Service exposes business operations over customers.
 type Service interface {
+	// Get returns the customer, following merges: looking up a merged ID
+	// returns the surviving customer.
 	Get(id string) (Customer, error)
+	// Create returns ErrInvalid unless the customer has a name, or a
+	// company name for fleet accounts, and an email or phone.
 	Create(input CreateInput) (Customer, error)
 	Update(id string, input UpdateInput) (Customer, error)
-	List(offset, limit int) ([]Customer, error)
+	List(spec listing.Spec) (listing.Page[Customer], error)
+	Delete(id string) error
+	Restore(id string) (Customer, error)
+	// PurgeDeleted permanently removes customers soft-deleted longer than
+	// retention ago.
+	PurgeDeleted(retention time.Duration) (int, error)
+	// FindDuplicates returns likely duplicates of the customer, best first.
+	FindDuplicates(id string) ([]Candidate, error)
+	// Merge folds duplicateID into survivorID. Fields the survivor lacks are
+	// taken from the duplicate.
+	Merge(survivorID, duplicateID string) (Customer, Merge, error)
+
+	// Addresses returns the customer's service addresses.
+	Addresses(customerID string) ([]Address, error)
+	// AddAddress validates and geocodes a new address for the customer.
+	AddAddress(customerID string, input AddressInput) (Address, error)
+	UpdateAddress(customerID, addressID string, input AddressUpdate) (Address, error)
+	DeleteAddress(customerID, addressID string) error
+
+	// Contacts returns a fleet account's contacts.
+	Contacts(customerID string) ([]Contact, error)
+	// AddContact returns ErrNotFleet unless the customer is a fleet account.
+	AddContact(customerID string, input ContactInput) (Contact, error)
+	UpdateContact(customerID, contactID string, input ContactUpdate) (Contact, error)
+	DeleteContact(customerID, contactID string) error
+
+	// RecordConsent appends grants or revocations to the consent ledger and
+	// returns the new entries.
+	RecordConsent(customerID string, input ConsentInput) ([]ConsentEntry, error)
+	// ApplyKeyword records the effect of an SMS reply such as STOP.
+	ApplyKeyword(customerID, keyword string) ([]ConsentEntry, error)
+	ConsentHistory(customerID string) ([]ConsentEntry, error)
+	// EffectiveConsent returns the state of every channel and purpose.
+	EffectiveConsent(customerID string) ([]ConsentState, error)
+	// CheckConsent returns ErrNoConsent unless a message on channel for
+	// purpose may be sent to the customer. Senders must call it first.
+	CheckConsent(customerID string, channel Channel, purpose Purpose) error
+
+	// Notes returns the customer's notes, oldest first.
+	Notes(customerID string) ([]Note, error)
+	AddNote(customerID string, input NoteInput) (Note, error)
+	DeleteNote(customerID, noteID string) error
+
+	// Anonymize erases the customer's personal data; see Erasure.
+	Anonymize(id string) (Erasure, error)
+
+	// Import creates a customer for every row of src that is valid and not a
+	// duplicate of an existing customer or an earlier row.
+	Import(src ImportSource, opts ImportOptions) (ImportReport, error)
 }
 
-// CreateInput defines data required to create a customer.
+// CreateInput defines data required to create a customer. Type defaults to
+// AccountIndividual and Billing to BillingPerVisit.
 type CreateInput struct {
-	FirstName    string
-	LastName     string
-	Email        string
-	Phone        string
-	MarketingOpt bool
+	// ExternalID identifies the customer in the system it came from.
+	ExternalID  string
+	Type        AccountType
+	CompanyName string
+	FirstName   string
+	LastName    string
+	Email       string
+	Phone       string
+	Tags        []string
+	PORequired  bool
+	Billing     Billing
 }
 
 // UpdateInput defines data for updating a customer.
 type UpdateInput struct {
-	FirstName    *string
-	LastName     *string
-	Email        *string
-	Phone        *string
-	MarketingOpt *bool
+	// Type converts an individual into a fleet account or back. A fleet
+	// account with contacts cannot become an individual.
+	Type        *AccountType
+	CompanyName *string
+	FirstName   *string
+	LastName    *string
+	Email       *string
+	Phone       *string
+	// Tags replaces every tag when set.
+	Tags       *[]string
+	PORequired *bool
+	Billing    *Billing
+}
+
+// Option configures a customer service.
+type Option func(*service)
+
+// WithPhoneRegion sets the region assumed for phone numbers entered without a
+// country code. The default is phone.DefaultRegion.
+func WithPhoneRegion(region string) Option {
+	return func(s *service) { s.phoneRegion = region }
 }
 
 // NewService builds a customer service with the given repository.
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+func NewService(repo Repository, opts ...Option) Service {
+	s := &service{
+		repo:        repo,
+		addresses:   NullAddressRepository{},
+		contacts:    NullContactRepository{},
+		consents:    NullConsentRepository{},
+		notes:       NullNoteRepository{},
+		geocoder:    geo.Noop{},
+		phoneRegion: phone.DefaultRegion,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 type service struct {
-	repo Repository
+	repo        Repository
+	addresses   AddressRepository
+	contacts    ContactRepository
+	consents    ConsentRepository
+	notes       NoteRepository
+	geocoder    geo.Geocoder
+	phoneRegion string
 }
 
 func (s *service) Get(id string) (Customer, error) {
-	return s.repo.FindByID(id)
+	c, err := s.repo.FindByID(id)
+	if !errors.Is(err, ErrNotFound) {
+		return c, err
+	}
+	survivorID, merr := s.repo.MergedInto(id)
+	if merr != nil {
+		if errors.Is(merr, ErrNotFound) {
+			return Customer{}, err
+		}
+		return Customer{}, merr
+	}
+	return s.repo.FindByID(survivorID)
 }
 
 func (s *service) Create(input CreateInput) (Customer, error) {
-	customer := Customer{
-		FirstName:    input.FirstName,
-		LastName:     input.LastName,
-		Email:        input.Email,
-		Phone:        input.Phone,
-		MarketingOpt: input.MarketingOpt,
+	customer, err := s.newCustomer(input)
+	if err != nil {
+		return Customer{}, err
 	}
 	return s.repo.Save(customer)
 }
 
+// newCustomer validates and normalizes input into an unsaved customer.
+func (s *service) newCustomer(input CreateInput) (Customer, error) {
+	customer := Customer{
+		ExternalID:  strings.TrimSpace(input.ExternalID),
+		Type:        input.Type,
+		CompanyName: input.CompanyName,
+		FirstName:   strings.TrimSpace(input.FirstName),
+		LastName:    strings.TrimSpace(input.LastName),
+		Email:       strings.TrimSpace(input.Email),
+		PORequired:  input.PORequired,
+		Billing:     input.Billing,
+	}
+	if err := checkAccount(&customer); err != nil {
+		return Customer{}, err
+	}
+	if err := checkEmail(customer.Email); err != nil {
+		return Customer{}, err
+	}
+	if err := s.setPhone(&customer, input.Phone); err != nil {
+		return Customer{}, err
+	}
+	if err := checkRequired(customer); err != nil {
+		return Customer{}, err
+	}
+	tags, err := NormalizeTags(input.Tags)
+	if err != nil {
+		return Customer{}, err
+	}
+	customer.Tags = tags
+	return customer, nil
+}
+
 func (s *service) Update(id string, input UpdateInput) (Customer, error) {
 	customer, err := s.repo.FindByID(id)
 	if err != nil {
 		return Customer{}, err
 	}
 
+	if input.Type != nil {
+		if customer.IsFleet() && *input.Type != AccountFleet {
+			contacts, err := s.contacts.ListByCustomer(customer.ID)
+			if err != nil {
+				return Customer{}, err
+			}
+			if len(contacts) > 0 {
+				return Customer{}, fmt.Errorf("%w: remove the account's contacts before making it an individual", ErrInvalid)
+			}
+		}
+		customer.Type = *input.Type
+	}
+	if input.CompanyName != nil {
+		customer.CompanyName = *input.CompanyName
+	}
+	if input.PORequired != nil {
+		customer.PORequired = *input.PORequired
+	}
+	if input.Billing != nil {
+		customer.Billing = *input.Billing
+	}
 	if input.FirstName != nil {
-		customer.FirstName = *input.FirstName
+		customer.FirstName = strings.TrimSpace(*input.FirstName)
 	}
 	if input.LastName != nil {
-		customer.LastName = *input.LastName
+		customer.LastName = strings.TrimSpace(*input.LastName)
 	}
 	if input.Email != nil {
-		customer.Email = *input.Email
+		email := strings.TrimSpace(*input.Email)
+		if err := checkEmail(email); err != nil {
+			return Customer{}, err
+		}
+		customer.Email = email
 	}
 	if input.Phone != nil {
-		customer.Phone = *input.Phone
+		if err := s.setPhone(&customer, *input.Phone); err != nil {
+			return Customer{}, err
+		}
+	}
+	if input.Tags != nil {
+		tags, err := NormalizeTags(*input.Tags)
+		if err != nil {
+			return Customer{}, err
+		}
+		customer.Tags = tags
 	}
-	if input.MarketingOpt != nil {
-		customer.MarketingOpt = *input.MarketingOpt
+	if err := checkAccount(&customer); err != nil {
+		return Customer{}, err
+	}
+	if err := checkRequired(customer); err != nil {
+		return Customer{}, err
 	}
 
 	return s.repo.Save(customer)
 }
 
-func (s *service) List(offset, limit int) ([]Customer, error) {
-	return s.repo.List(offset, limit)
+// checkRequired rejects customers without a name, unless they are a fleet,
+// or without an email or phone.
+func checkRequired(c Customer) error {
+	if c.FirstName == "" && c.LastName == "" && !c.IsFleet() {
+		return fmt.Errorf("%w: first or last name required", ErrInvalid)
+	}
+	if c.Email == "" && c.Phone == "" {
+		return fmt.Errorf("%w: email or phone required", ErrInvalid)
+	}
+	return nil
+}
+
+// checkAccount normalizes the account type, company name and billing, and
+// rejects fleet settings on individuals.
+func checkAccount(c *Customer) error {
+	c.Type = AccountType(strings.ToLower(strings.TrimSpace(string(c.Type))))
+	c.CompanyName = strings.TrimSpace(c.CompanyName)
+	c.Billing = Billing(strings.ToLower(strings.TrimSpace(string(c.Billing))))
+	if c.Type == "" {
+		c.Type = AccountIndividual
+	}
+	if c.Billing == "" {
+		c.Billing = BillingPerVisit
+	}
+	switch {
+	case c.Type != AccountIndividual && c.Type != AccountFleet:
+		return fmt.Errorf("%w: unknown account type %q", ErrInvalid, c.Type)
+	case c.Billing != BillingPerVisit && c.Billing != BillingMonthly:
+		return fmt.Errorf("%w: unknown billing %q", ErrInvalid, c.Billing)
+	case c.IsFleet() && c.CompanyName == "":
+		return fmt.Errorf("%w: company name required for fleet accounts", ErrInvalid)
+	case !c.IsFleet() && (c.PORequired || c.Billing == BillingMonthly):
+		return fmt.Errorf("%w: purchase orders and monthly billing are for fleet accounts", ErrInvalid)
+	}
+	return nil
+}
+
+// checkEmail rejects addresses that are clearly not email addresses, such as
+// a phone number typed into the wrong column. Blank is allowed.
+func checkEmail(email string) error {
+	if email == "" {
+		return nil
+	}
+	at := strings.IndexByte(email, '@')
+	if at <= 0 || at != strings.LastIndexByte(email, '@') || at == len(email)-1 || strings.ContainsAny(email, " \t\r\n,;<>") {
+		return fmt.Errorf("%w: %q is not an email address", ErrInvalid, email)
+	}
+	return nil
+}
+
+// setPhone stores raw in both display and E.164 form. A blank number clears
+// the phone.
+func (s *service) setPhone(c *Customer, raw string) error {
+	if strings.TrimSpace(raw) == "" {
+		c.Phone, c.PhoneE164 = "", ""
+		return nil
+	}
+	n, err := phone.Parse(raw, s.phoneRegion)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidPhone, err)
+	}
+	c.Phone, c.PhoneE164 = n.Display, n.E164
+	return nil
+}
+
+func (s *service) List(spec listing.Spec) (listing.Page[Customer], error) {
+	return s.repo.List(spec.Normalize())
+}
+
+func (s *service) Delete(id string) error {
+	_, err := s.repo.Delete(id)
+	return err
+}
+
+func (s *service) Restore(id string) (Customer, error) {
+	return s.repo.Restore(id)
+}
+
+func (s *service) PurgeDeleted(retention time.Duration) (int, error) {
+	return s.repo.Purge(time.Now().Add(-retention))
+}
+
+func (s *service) FindDuplicates(id string) ([]Candidate, error) {
+	c, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := s.repo.FindMatches(MatchKeys{
+		ExcludeID: c.ID,
+		Email:     c.Email,
+		PhoneE164: c.PhoneE164,
+		LastName:  c.LastName,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rankCandidates(c, matches), nil
+}
+
+func (s *service) Merge(survivorID, duplicateID string) (Customer, Merge, error) {
+	if survivorID == duplicateID {
+		return Customer{}, Merge{}, ErrMergeSelf
+	}
+	survivor, err := s.repo.FindByID(survivorID)
+	if err != nil {
+		return Customer{}, Merge{}, err
+	}
+	duplicate, err := s.repo.FindByID(duplicateID)
+	if err != nil {
+		return Customer{}, Merge{}, err
+	}
+
+	if survivor.FirstName == "" && survivor.LastName == "" {
+		survivor.FirstName, survivor.LastName = duplicate.FirstName, duplicate.LastName
+	}
+	if survivor.Email == "" {
+		survivor.Email = duplicate.Email
+	}
+	if survivor.Phone == "" {
+		survivor.Phone, survivor.PhoneE164 = duplicate.Phone, duplicate.PhoneE164
+	}
+	if survivor.ExternalID == "" {
+		survivor.ExternalID = duplicate.ExternalID
+	}
+	if !survivor.IsFleet() && duplicate.IsFleet() {
+		survivor.Type, survivor.CompanyName = duplicate.Type, duplicate.CompanyName
+		survivor.PORequired, survivor.Billing = duplicate.PORequired, duplicate.Billing
+	}
+	// Normalizing the union can only fail on the tag count; the survivor
+	// then keeps its own tags.
+	if tags, err := NormalizeTags(append(append([]string{}, survivor.Tags...), duplicate.Tags...)); err == nil {
+		survivor.Tags = tags
+	}
+
+	m, err := s.repo.Merge(survivor, duplicate.ID)
+	if err != nil {
+		return Customer{}, Merge{}, err
+	}
+	merged, err := s.repo.FindByID(survivor.ID)
+	if err != nil {
+		return Customer{}, Merge{}, err
+	}
+	return merged, m, nil
+}
+
+// SortKey exposes the sort column value and ID of a customer for paginators.
+func SortKey(c Customer, field listing.SortField) (time.Time, string) {
+	if field == listing.SortUpdatedAt {
+		return c.UpdatedAt, c.ID
+	}
+	return c.CreatedAt, c.ID
 }
@@ -1,9 +1,14 @@
 package customers_test
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
 	"github.com/ezmobilemechanic/platform/internal/storage/memory"
 )
 
@@ -12,11 +17,10 @@ func TestServiceCreateAndGet(t *testing.T) {
 	svc := customers.NewService(repo)
 
 	created, err := svc.Create(customers.CreateInput{
-		FirstName:    "Alex",
-		LastName:     "Driver",
-		Email:        "alex@example.com",
-		Phone:        "555-1234",
-		MarketingOpt: true,
+		FirstName: "Alex",
+		LastName:  "Driver",
+		Email:     "alex@example.com",
+		Phone:     "904-555-1234",
 	})
 	if err != nil {
 		t.Fatalf("create failed: %v", err)
@@ -35,6 +39,37 @@ func TestServiceCreateAndGet(t *testing.T) {
 	if fetched.Email != "alex@example.com" {
 		t.Fatalf("unexpected email: %s", fetched.Email)
 	}
+	if fetched.Phone != "(904) 555-1234" || fetched.PhoneE164 != "+19045551234" {
+		t.Fatalf("unexpected phone: %q / %q", fetched.Phone, fetched.PhoneE164)
+	}
+}
+
+func TestServicePhoneNormalization(t *testing.T) {
+	svc := customers.NewService(memory.NewCustomerRepository(), customers.WithPhoneRegion("GB"))
+
+	if _, err := svc.Create(customers.CreateInput{FirstName: "Bad", Phone: "555-0101"}); !errors.Is(err, customers.ErrInvalidPhone) {
+		t.Fatalf("expected ErrInvalidPhone, got %v", err)
+	}
+
+	created, err := svc.Create(customers.CreateInput{FirstName: "Pat", Email: "pat@example.com", Phone: "020 7946 0000"})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if created.PhoneE164 != "+442079460000" {
+		t.Fatalf("expected national number in the configured region, got %q", created.PhoneE164)
+	}
+
+	blank := " "
+	updated, err := svc.Update(created.ID, customers.UpdateInput{Phone: &blank})
+	if err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if updated.Phone != "" || updated.PhoneE164 != "" {
+		t.Fatalf("expected phone to be cleared, got %q / %q", updated.Phone, updated.PhoneE164)
+	}
+	if _, err := svc.Update(created.ID, customers.UpdateInput{Email: &blank}); !errors.Is(err, customers.ErrInvalid) {
+		t.Fatalf("expected ErrInvalid clearing the last way to reach them, got %v", err)
+	}
 }
 
 func TestServiceUpdate(t *testing.T) {
@@ -57,6 +92,14 @@ func TestServiceUpdate(t *testing.T) {
 	if updated.Email != newEmail {
 		t.Fatalf("email not updated: got %s", updated.Email)
 	}
+
+	first, last := " Joanna ", "  "
+	if updated, err = svc.Update(created.ID, customers.UpdateInput{FirstName: &first, LastName: &last}); err != nil || updated.FirstName != "Joanna" || updated.LastName != "" {
+		t.Fatalf("expected names trimmed, got %+v, %v", updated, err)
+	}
+	if _, err := svc.Update(created.ID, customers.UpdateInput{FirstName: &last}); !errors.Is(err, customers.ErrInvalid) {
+		t.Fatalf("expected ErrInvalid removing the name, got %v", err)
+	}
 }
 
 func TestServiceList(t *testing.T) {
@@ -64,17 +107,176 @@ func TestServiceList(t *testing.T) {
 	svc := customers.NewService(repo)
 
 	for i := 0; i < 3; i++ {
-		_, err := svc.Create(customers.CreateInput{FirstName: "Foo", Email: "foo@example.com"})
+		_, err := svc.Create(customers.CreateInput{FirstName: "Foo", Email: fmt.Sprintf("foo%d@example.com", i)})
 		if err != nil {
 			t.Fatalf("create failed: %v", err)
 		}
 	}
 
-	got, err := svc.List(0, 2)
+	got, err := svc.List(listing.Spec{Limit: 2})
 	if err != nil {
 		t.Fatalf("list failed: %v", err)
 	}
-	if len(got) != 2 {
-		t.Fatalf("expected 2 customers, got %d", len(got))
+	if len(got.Items) != 2 {
+		t.Fatalf("expected 2 customers, got %d", len(got.Items))
+	}
+	if !got.HasMore || got.NextCursor == "" {
+		t.Fatalf("expected another page")
+	}
+	if got.Total != 3 {
+		t.Fatalf("expected total 3, got %d", got.Total)
+	}
+
+	next, err := svc.List(listing.Spec{Limit: 2, Cursor: got.NextCursor})
+	if err != nil {
+		t.Fatalf("list next page failed: %v", err)
+	}
+	if len(next.Items) != 1 || next.HasMore {
+		t.Fatalf("expected final page with 1 customer, got %d (has_more=%v)", len(next.Items), next.HasMore)
+	}
+}
+
+func TestServiceListSearch(t *testing.T) {
+	repo := memory.NewCustomerRepository()
+	svc := customers.NewService(repo)
+
+	for _, in := range []customers.CreateInput{
+		{FirstName: "Alex", LastName: "Driver", Email: "alex@example.com"},
+		{FirstName: "Jordan", LastName: "Mechanic", Phone: "904-555-0202"},
+	} {
+		if _, err := svc.Create(in); err != nil {
+			t.Fatalf("create failed: %v", err)
+		}
+	}
+
+	got, err := svc.List(listing.Spec{Search: "MECH"})
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(got.Items) != 1 || got.Items[0].FirstName != "Jordan" {
+		t.Fatalf("expected Jordan to match search, got %+v", got.Items)
+	}
+}
+
+func TestServiceDeleteRestoreAndPurge(t *testing.T) {
+	repo := memory.NewCustomerRepository()
+	svc := customers.NewService(repo)
+
+	created, err := svc.Create(customers.CreateInput{FirstName: "Gone", Email: "gone@example.com"})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	if err := svc.Delete(created.ID); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if _, err := svc.Get(created.ID); !errors.Is(err, customers.ErrNotFound) {
+		t.Fatalf("expected deleted customer to be hidden, got %v", err)
+	}
+	if _, err := svc.Restore(created.ID); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if _, err := svc.Get(created.ID); err != nil {
+		t.Fatalf("get after restore failed: %v", err)
+	}
+
+	if err := svc.Delete(created.ID); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if n, err := svc.PurgeDeleted(time.Hour); err != nil || n != 0 {
+		t.Fatalf("expected retention to keep the record, purged %d (%v)", n, err)
+	}
+	if n, err := svc.PurgeDeleted(-time.Second); err != nil || n != 1 {
+		t.Fatalf("expected record past retention to be purged, purged %d (%v)", n, err)
+	}
+	if _, err := svc.Restore(created.ID); !errors.Is(err, customers.ErrNotFound) {
+		t.Fatalf("expected purged customer to be gone, got %v", err)
+	}
+}
+
+func newLinkedRepo() *memory.CustomerRepository {
+	repo := memory.NewCustomerRepository()
+	repo.SetVehicles(memory.NewVehicleRepository())
+	repo.SetQuotes(memory.NewQuoteRepository())
+	return repo
+}
+
+func TestServiceFindDuplicates(t *testing.T) {
+	svc := customers.NewService(newLinkedRepo())
+
+	create := func(in customers.CreateInput) customers.Customer {
+		t.Helper()
+		c, err := svc.Create(in)
+		if err != nil {
+			t.Fatalf("create failed: %v", err)
+		}
+		return c
+	}
+	target := create(customers.CreateInput{FirstName: "Jonathan", LastName: "Smith", Email: "jon@example.com", Phone: "904-555-0101"})
+	both := create(customers.CreateInput{FirstName: "Jonathon", LastName: "Smith", Phone: "(904) 555-0101"})
+	phoneOnly := create(customers.CreateInput{FirstName: "Office", LastName: "Line", Phone: "+1 904 555 0101"})
+	nameOnly := create(customers.CreateInput{FirstName: "Jonathan", LastName: "smith", Email: "j.smith@example.com"})
+	create(customers.CreateInput{FirstName: "Jane", LastName: "Smith", Email: "jane@example.com"})
+
+	got, err := svc.FindDuplicates(target.ID)
+	if err != nil {
+		t.Fatalf("find duplicates failed: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 candidates, got %+v", got)
+	}
+	for i, want := range []struct {
+		id      string
+		reasons string
+	}{
+		{both.ID, "phone,name"},
+		{phoneOnly.ID, "phone"},
+		{nameOnly.ID, "name"},
+	} {
+		if got[i].Customer.ID != want.id || strings.Join(got[i].Reasons, ",") != want.reasons {
+			t.Fatalf("candidate %d: expected %s (%s), got %s (%v)", i, want.id, want.reasons, got[i].Customer.ID, got[i].Reasons)
+		}
+		if got[i].Score <= 0 || got[i].Score > 1 {
+			t.Fatalf("candidate %d: score out of range: %v", i, got[i].Score)
+		}
+	}
+}
+
+func TestServiceMerge(t *testing.T) {
+	svc := customers.NewService(newLinkedRepo())
+
+	survivor, err := svc.Create(customers.CreateInput{FirstName: "Alex", LastName: "Driver", Phone: "904-555-0101"})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	dup, err := svc.Create(customers.CreateInput{FirstName: "Alexander", LastName: "Driver", Email: "alex@example.com", Phone: "904-555-0202"})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	if _, _, err := svc.Merge(survivor.ID, survivor.ID); !errors.Is(err, customers.ErrMergeSelf) {
+		t.Fatalf("expected ErrMergeSelf, got %v", err)
+	}
+
+	merged, record, err := svc.Merge(survivor.ID, dup.ID)
+	if err != nil {
+		t.Fatalf("merge failed: %v", err)
+	}
+	if merged.FirstName != "Alex" || merged.Email != "alex@example.com" || merged.PhoneE164 != "+19045550101" {
+		t.Fatalf("expected survivor fields kept and gaps filled, got %+v", merged)
+	}
+	if record.MergedID != dup.ID || record.SurvivorID != survivor.ID {
+		t.Fatalf("unexpected merge record %+v", record)
+	}
+
+	fetched, err := svc.Get(dup.ID)
+	if err != nil {
+		t.Fatalf("get merged id failed: %v", err)
+	}
+	if fetched.ID != survivor.ID {
+		t.Fatalf("expected lookup of merged id to return survivor, got %s", fetched.ID)
+	}
+	if _, _, err := svc.Merge(survivor.ID, dup.ID); !errors.Is(err, customers.ErrNotFound) {
+		t.Fatalf("merging an already merged customer: expected ErrNotFound, got %v", err)
 	}
 }
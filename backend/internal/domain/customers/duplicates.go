@@ -0,0 +1,144 @@
+package customers
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Reasons a customer is reported as a possible duplicate.
+const (
+	ReasonEmail = "email"
+	ReasonPhone = "phone"
+	ReasonName  = "name"
+)
+
+// Signal weights: the chance that a match on that signal alone means the two
+// records are the same person. They combine as independent evidence.
+const (
+	emailWeight = 0.9
+	phoneWeight = 0.8
+	nameWeight  = 0.6
+
+	// nameThreshold is the similarity from which two full names count as a
+	// match. It tolerates a typo or a nickname's shared prefix.
+	nameThreshold = 0.88
+)
+
+// Candidate is a possible duplicate of a customer.
+type Candidate struct {
+	Customer Customer
+	// Score is between 0 and 1; higher is more likely the same person.
+	Score   float64
+	Reasons []string
+}
+
+// rankCandidates scores matches against c and returns those with at least
+// one matching signal, best first.
+func rankCandidates(c Customer, matches []Customer) []Candidate {
+	name := fullName(c)
+	out := make([]Candidate, 0, len(matches))
+	for _, m := range matches {
+		if m.ID == c.ID {
+			continue
+		}
+
+		miss := 1.0
+		var reasons []string
+		if c.Email != "" && strings.EqualFold(c.Email, m.Email) {
+			miss *= 1 - emailWeight
+			reasons = append(reasons, ReasonEmail)
+		}
+		if c.PhoneE164 != "" && c.PhoneE164 == m.PhoneE164 {
+			miss *= 1 - phoneWeight
+			reasons = append(reasons, ReasonPhone)
+		}
+		if sim := nameSimilarity(name, fullName(m)); sim >= nameThreshold {
+			miss *= 1 - nameWeight*sim
+			reasons = append(reasons, ReasonName)
+		}
+		if len(reasons) == 0 {
+			continue
+		}
+		out = append(out, Candidate{Customer: m, Score: 1 - miss, Reasons: reasons})
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		return out[i].Customer.ID < out[j].Customer.ID
+	})
+	return out
+}
+
+// fullName lower-cases the name and drops everything but letters and single
+// spaces, so punctuation and spacing differences do not count.
+func fullName(c Customer) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(c.FirstName + " " + c.LastName) {
+		switch {
+		case unicode.IsLetter(r):
+			b.WriteRune(r)
+		case unicode.IsSpace(r) && b.Len() > 0 && !strings.HasSuffix(b.String(), " "):
+			b.WriteRune(' ')
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// nameSimilarity is the Jaro-Winkler similarity of a and b, which favours
+// strings sharing a prefix. Two empty names are not similar.
+func nameSimilarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+
+	window := max(len(ra), len(rb))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+	matchedA := make([]bool, len(ra))
+	matchedB := make([]bool, len(rb))
+	matches := 0
+	for i := range ra {
+		lo, hi := max(0, i-window), min(len(rb), i+window+1)
+		for j := lo; j < hi; j++ {
+			if !matchedB[j] && ra[i] == rb[j] {
+				matchedA[i], matchedB[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions, j := 0, 0
+	for i := range ra {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		if ra[i] != rb[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(ra), len(rb)) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
@@ -0,0 +1,79 @@
+package customers
+
+// TagErased marks customers whose personal data was erased, so they can be
+// told apart from records that were never filled in.
+const TagErased = "erased"
+
+// Erasure summarizes what Anonymize removed.
+type Erasure struct {
+	// Customer is the record after erasure.
+	Customer  Customer
+	Addresses int
+	Contacts  int
+	Notes     int
+}
+
+// Anonymize erases the customer's personal data for a right-to-erasure
+// request. The name, email, phone and external ID are cleared and the tags
+// replaced by TagErased. Addresses, fleet contacts and notes, which include
+// logged calls and messages, are deleted. A fleet account keeps its company
+// name, which is not personal data. Every channel and purpose is revoked in the
+// consent ledger; earlier entries are kept as evidence of the consent that
+// was given. The customer ID stays valid, so quotes and other bookkeeping
+// records remain intact.
+func (s *service) Anonymize(id string) (Erasure, error) {
+	c, err := s.repo.FindByID(id)
+	if err != nil {
+		return Erasure{}, err
+	}
+
+	var result Erasure
+	addresses, err := s.addresses.ListByCustomer(c.ID)
+	if err != nil {
+		return Erasure{}, err
+	}
+	for _, a := range addresses {
+		if err := s.addresses.Delete(a.ID); err != nil {
+			return Erasure{}, err
+		}
+		result.Addresses++
+	}
+	if c.IsFleet() {
+		contacts, err := s.contacts.ListByCustomer(c.ID)
+		if err != nil {
+			return Erasure{}, err
+		}
+		for _, ct := range contacts {
+			if err := s.contacts.Delete(ct.ID); err != nil {
+				return Erasure{}, err
+			}
+			result.Contacts++
+		}
+	}
+	notes, err := s.notes.ListByCustomer(c.ID)
+	if err != nil {
+		return Erasure{}, err
+	}
+	for _, n := range notes {
+		if err := s.notes.Delete(n.ID); err != nil {
+			return Erasure{}, err
+		}
+		result.Notes++
+	}
+	for _, ch := range Channels {
+		if _, err := s.RecordConsent(c.ID, ConsentInput{Channel: ch, Source: SourceErasure}); err != nil {
+			return Erasure{}, err
+		}
+	}
+
+	c.ExternalID = ""
+	c.FirstName, c.LastName = "", ""
+	c.Email = ""
+	c.Phone, c.PhoneE164 = "", ""
+	c.Tags = []string{TagErased}
+	result.Customer, err = s.repo.Save(c)
+	if err != nil {
+		return Erasure{}, err
+	}
+	return result, nil
+}
@@ -0,0 +1,205 @@
+package customers
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// importDuplicateScore is the candidate score from which an imported row is
+// taken to be a customer already on file. A shared email is enough, a shared
+// phone needs a matching name too, since households share landlines.
+const importDuplicateScore = 0.85
+
+// MaxImportProblems caps the rows listed in an ImportReport. Rows past the cap
+// are still counted.
+const MaxImportProblems = 1000
+
+// ImportRow is one customer read from an import file.
+type ImportRow struct {
+	// Line is the row's line number in the file, reported back with problems.
+	Line  int
+	Input CreateInput
+	// Err is set when the row could not be read. The row is reported as
+	// invalid and the import carries on.
+	Err error
+}
+
+// ImportSource yields the rows of an import file. Next returns io.EOF after
+// the last row; any other error aborts the import.
+type ImportSource interface {
+	Next() (ImportRow, error)
+}
+
+// ImportOptions configures an import.
+type ImportOptions struct {
+	// DryRun validates and dedupes every row without saving anything.
+	DryRun bool
+}
+
+// ImportOutcome says what happened to a row.
+type ImportOutcome string
+
+const (
+	ImportCreated   ImportOutcome = "created"
+	ImportDuplicate ImportOutcome = "duplicate"
+	ImportInvalid   ImportOutcome = "invalid"
+)
+
+// ImportProblem reports a row that was not imported.
+type ImportProblem struct {
+	Line    int
+	Outcome ImportOutcome
+	// Error explains an invalid row.
+	Error string
+	// CustomerID is the existing customer a duplicate matched, and
+	// DuplicateOfLine the earlier row it matched instead.
+	CustomerID      string
+	DuplicateOfLine int
+	Reasons         []string
+}
+
+// ImportReport summarizes an import. In a dry run Created counts the rows
+// that would have been created.
+type ImportReport struct {
+	DryRun     bool
+	Rows       int
+	Created    int
+	Duplicates int
+	Invalid    int
+	// Problems lists duplicate and invalid rows in file order, up to
+	// MaxImportProblems.
+	Problems []ImportProblem
+}
+
+func (r *ImportReport) problem(p ImportProblem) {
+	if p.Outcome == ImportDuplicate {
+		r.Duplicates++
+	} else {
+		r.Invalid++
+	}
+	if len(r.Problems) < MaxImportProblems {
+		r.Problems = append(r.Problems, p)
+	}
+}
+
+// Import validates each row as Create does and checks it for duplicates
+// among active customers and the rows before it, scored as FindDuplicates
+// does. Rows are saved one at a time, so an aborted import keeps the rows
+// already created; running it again skips them as duplicates.
+func (s *service) Import(src ImportSource, opts ImportOptions) (ImportReport, error) {
+	report := ImportReport{DryRun: opts.DryRun, Problems: []ImportProblem{}}
+	seen := newImportIndex()
+	for {
+		row, err := src.Next()
+		if errors.Is(err, io.EOF) {
+			return report, nil
+		}
+		if err != nil {
+			return report, err
+		}
+		report.Rows++
+
+		if row.Err != nil {
+			report.problem(ImportProblem{Line: row.Line, Outcome: ImportInvalid, Error: row.Err.Error()})
+			continue
+		}
+		c, err := s.newCustomer(row.Input)
+		if err != nil {
+			if !isInvalidInput(err) {
+				return report, err
+			}
+			report.problem(ImportProblem{Line: row.Line, Outcome: ImportInvalid, Error: err.Error()})
+			continue
+		}
+
+		if p, ok, err := s.importDuplicate(c, seen); err != nil {
+			return report, err
+		} else if ok {
+			p.Line = row.Line
+			report.problem(p)
+			continue
+		}
+
+		if !opts.DryRun {
+			saved, err := s.repo.Save(c)
+			if errors.Is(err, ErrEmailExists) {
+				// Created concurrently since the duplicate check.
+				report.problem(ImportProblem{Line: row.Line, Outcome: ImportDuplicate, Reasons: []string{ReasonEmail}})
+				continue
+			}
+			if err != nil {
+				return report, err
+			}
+			c = saved
+		}
+		seen.add(row.Line, c)
+		report.Created++
+	}
+}
+
+// importDuplicate looks for c among existing customers, then among earlier
+// rows.
+func (s *service) importDuplicate(c Customer, seen *importIndex) (ImportProblem, bool, error) {
+	matches, err := s.repo.FindMatches(MatchKeys{Email: c.Email, PhoneE164: c.PhoneE164, LastName: c.LastName})
+	if err != nil {
+		return ImportProblem{}, false, err
+	}
+	if best, ok := bestImportMatch(c, matches); ok {
+		return ImportProblem{Outcome: ImportDuplicate, CustomerID: best.Customer.ID, Reasons: best.Reasons}, true, nil
+	}
+	if best, ok := bestImportMatch(c, seen.candidates(c)); ok {
+		p := ImportProblem{Outcome: ImportDuplicate, Reasons: best.Reasons}
+		p.DuplicateOfLine, _ = strconv.Atoi(best.Customer.ID)
+		return p, true, nil
+	}
+	return ImportProblem{}, false, nil
+}
+
+func bestImportMatch(c Customer, matches []Customer) (Candidate, bool) {
+	ranked := rankCandidates(c, matches)
+	if len(ranked) == 0 || ranked[0].Score < importDuplicateScore {
+		return Candidate{}, false
+	}
+	return ranked[0], true
+}
+
+// importIndex holds the rows accepted so far by email and phone. Entries
+// carry their line number as ID, so they can be ranked like stored customers
+// even in a dry run.
+type importIndex struct {
+	byEmail map[string]Customer
+	byPhone map[string][]Customer
+}
+
+func newImportIndex() *importIndex {
+	return &importIndex{byEmail: map[string]Customer{}, byPhone: map[string][]Customer{}}
+}
+
+func (x *importIndex) add(line int, c Customer) {
+	c.ID = strconv.Itoa(line)
+	if c.Email != "" {
+		x.byEmail[strings.ToLower(c.Email)] = c
+	}
+	if c.PhoneE164 != "" {
+		x.byPhone[c.PhoneE164] = append(x.byPhone[c.PhoneE164], c)
+	}
+}
+
+func (x *importIndex) candidates(c Customer) []Customer {
+	var out []Customer
+	if m, ok := x.byEmail[strings.ToLower(c.Email)]; ok && c.Email != "" {
+		out = append(out, m)
+	}
+	if c.PhoneE164 != "" {
+		out = append(out, x.byPhone[c.PhoneE164]...)
+	}
+	return out
+}
+
+// isInvalidInput reports whether err rejects the row rather than signalling
+// a storage failure.
+func isInvalidInput(err error) bool {
+	return errors.Is(err, ErrInvalid) || errors.Is(err, ErrInvalidPhone) || errors.Is(err, ErrInvalidTag)
+}
@@ -0,0 +1,102 @@
+package customers_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
+	"github.com/ezmobilemechanic/platform/internal/storage/memory"
+)
+
+// rows is an ImportSource over a fixed list.
+type rows []customers.ImportRow
+
+func (r *rows) Next() (customers.ImportRow, error) {
+	if len(*r) == 0 {
+		return customers.ImportRow{}, io.EOF
+	}
+	row := (*r)[0]
+	*r = (*r)[1:]
+	return row, nil
+}
+
+func TestServiceImport(t *testing.T) {
+	repo := memory.NewCustomerRepository()
+	svc := customers.NewService(repo)
+
+	existing, err := svc.Create(customers.CreateInput{FirstName: "Alex", LastName: "Driver", Email: "alex@example.com", Phone: "904-555-0101"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	file := func() *rows {
+		return &rows{
+			{Line: 2, Input: customers.CreateInput{ExternalID: "101", FirstName: "Sam", LastName: "Lee", Email: "sam@example.com", Tags: []string{"Fleet"}}},
+			{Line: 3, Input: customers.CreateInput{FirstName: "Alexander", Email: "ALEX@example.com"}},
+			{Line: 4, Input: customers.CreateInput{FirstName: "Sam", LastName: "Lee", Phone: "904 555 0199", Email: "sam@example.com"}},
+			{Line: 5, Input: customers.CreateInput{FirstName: "Pat", Phone: "555"}},
+			{Line: 6, Input: customers.CreateInput{LastName: "Nobody"}},
+			{Line: 7, Input: customers.CreateInput{FirstName: "Kim", Email: "kim at example"}},
+			{Line: 8, Err: io.ErrUnexpectedEOF},
+			// Shares the existing customer's phone but not the name: a
+			// household, not a duplicate.
+			{Line: 9, Input: customers.CreateInput{FirstName: "Robin", LastName: "Driver", Phone: "(904) 555-0101"}},
+		}
+	}
+
+	dry, err := svc.Import(file(), customers.ImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if !dry.DryRun || dry.Rows != 8 || dry.Created != 2 || dry.Duplicates != 2 || dry.Invalid != 4 {
+		t.Fatalf("unexpected dry run report %+v", dry)
+	}
+	if page, _ := svc.List(listing.Spec{}); page.Total != 1 {
+		t.Fatalf("dry run must not save, found %d customers", page.Total)
+	}
+
+	want := []customers.ImportProblem{
+		{Line: 3, Outcome: customers.ImportDuplicate, CustomerID: existing.ID},
+		{Line: 4, Outcome: customers.ImportDuplicate, DuplicateOfLine: 2},
+		{Line: 5, Outcome: customers.ImportInvalid},
+		{Line: 6, Outcome: customers.ImportInvalid},
+		{Line: 7, Outcome: customers.ImportInvalid},
+		{Line: 8, Outcome: customers.ImportInvalid},
+	}
+	if len(dry.Problems) != len(want) {
+		t.Fatalf("expected %d problems, got %+v", len(want), dry.Problems)
+	}
+	for i, w := range want {
+		got := dry.Problems[i]
+		if got.Line != w.Line || got.Outcome != w.Outcome || got.CustomerID != w.CustomerID || got.DuplicateOfLine != w.DuplicateOfLine {
+			t.Fatalf("problem %d: expected %+v, got %+v", i, w, got)
+		}
+		if w.Outcome == customers.ImportInvalid && got.Error == "" {
+			t.Fatalf("problem %d: expected an error message", i)
+		}
+	}
+
+	report, err := svc.Import(file(), customers.ImportOptions{})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if report.Created != 2 || report.Duplicates != 2 || report.Invalid != 4 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	page, err := svc.List(listing.Spec{Search: "tag:fleet"})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(page.Items) != 1 || page.Items[0].ExternalID != "101" {
+		t.Fatalf("expected the imported fleet customer, got %+v", page.Items)
+	}
+
+	again, err := svc.Import(file(), customers.ImportOptions{})
+	if err != nil {
+		t.Fatalf("re-import: %v", err)
+	}
+	if again.Created != 0 || again.Duplicates != 4 {
+		t.Fatalf("re-running an import must skip saved rows, got %+v", again)
+	}
+}
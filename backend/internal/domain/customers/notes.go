@@ -0,0 +1,188 @@
+package customers
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Note and tag errors.
+var (
+	ErrNoteNotFound = errors.New("customer note not found")
+	ErrInvalidNote  = errors.New("invalid customer note")
+	ErrInvalidTag   = errors.New("invalid customer tag")
+)
+
+// NoteKind says whether a note is an internal remark or a record of a
+// communication with the customer.
+type NoteKind string
+
+const (
+	NoteKindNote  NoteKind = "note"
+	NoteKindCall  NoteKind = "call"
+	NoteKindSMS   NoteKind = "sms"
+	NoteKindEmail NoteKind = "email"
+)
+
+// NoteKinds lists every kind, in display order.
+var NoteKinds = []NoteKind{NoteKindNote, NoteKindCall, NoteKindSMS, NoteKindEmail}
+
+// IsCommunication reports whether the note records contact with the customer
+// rather than an internal remark.
+func (k NoteKind) IsCommunication() bool {
+	return k == NoteKindCall || k == NoteKindSMS || k == NoteKindEmail
+}
+
+// Limits on notes and tags.
+const (
+	MaxNoteLength = 10000
+	MaxTagLength  = 40
+	MaxTags       = 20
+)
+
+// Note is an internal note about a customer, or a record of a call or
+// message. Notes are not edited; a correction is a new note.
+type Note struct {
+	ID         string
+	CustomerID string
+	Kind       NoteKind
+	Body       string
+	// Author is the staff member who wrote the note, or "system" for
+	// messages the platform sent.
+	Author    string
+	CreatedAt time.Time
+}
+
+// NoteRepository persists customer notes. ListByCustomer returns notes oldest
+// first. Notes move to the survivor when customers are merged and are removed
+// when the customer is purged.
+type NoteRepository interface {
+	FindByID(id string) (Note, error)
+	ListByCustomer(customerID string) ([]Note, error)
+	Add(note Note) (Note, error)
+	Delete(id string) error
+}
+
+// NullNoteRepository stub implementation returning ErrNotImplemented.
+type NullNoteRepository struct{}
+
+func (NullNoteRepository) FindByID(id string) (Note, error) {
+	return Note{}, ErrNotImplemented
+}
+
+func (NullNoteRepository) ListByCustomer(customerID string) ([]Note, error) {
+	return nil, ErrNotImplemented
+}
+
+func (NullNoteRepository) Add(note Note) (Note, error) {
+	return Note{}, ErrNotImplemented
+}
+
+func (NullNoteRepository) Delete(id string) error {
+	return ErrNotImplemented
+}
+
+// NoteInput defines data for adding a note. Kind defaults to NoteKindNote.
+type NoteInput struct {
+	Kind   NoteKind
+	Body   string
+	Author string
+}
+
+// WithNotes sets the repository for customer notes.
+func WithNotes(repo NoteRepository) Option {
+	return func(s *service) { s.notes = repo }
+}
+
+func (s *service) Notes(customerID string) ([]Note, error) {
+	if _, err := s.repo.FindByID(customerID); err != nil {
+		return nil, err
+	}
+	return s.notes.ListByCustomer(customerID)
+}
+
+func (s *service) AddNote(customerID string, input NoteInput) (Note, error) {
+	if _, err := s.repo.FindByID(customerID); err != nil {
+		return Note{}, err
+	}
+
+	n := Note{
+		CustomerID: customerID,
+		Kind:       input.Kind,
+		Body:       strings.TrimSpace(input.Body),
+		Author:     strings.TrimSpace(input.Author),
+	}
+	if n.Kind == "" {
+		n.Kind = NoteKindNote
+	}
+	switch {
+	case !validNoteKind(n.Kind):
+		return Note{}, fmt.Errorf("%w: unknown kind %q", ErrInvalidNote, n.Kind)
+	case n.Body == "":
+		return Note{}, fmt.Errorf("%w: body is required", ErrInvalidNote)
+	case utf8.RuneCountInString(n.Body) > MaxNoteLength:
+		return Note{}, fmt.Errorf("%w: body is longer than %d characters", ErrInvalidNote, MaxNoteLength)
+	}
+	return s.notes.Add(n)
+}
+
+func (s *service) DeleteNote(customerID, noteID string) error {
+	if _, err := s.repo.FindByID(customerID); err != nil {
+		return err
+	}
+	n, err := s.notes.FindByID(noteID)
+	if err != nil {
+		return err
+	}
+	if n.CustomerID != customerID {
+		return ErrNoteNotFound
+	}
+	return s.notes.Delete(noteID)
+}
+
+func validNoteKind(k NoteKind) bool {
+	for _, v := range NoteKinds {
+		if k == v {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeTags trims and lower-cases tags, collapsing inner whitespace to a
+// single space, and returns them sorted without duplicates. Blank tags are
+// dropped. It returns ErrInvalidTag for tags longer than MaxTagLength or when
+// more than MaxTags remain.
+func NormalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.ToLower(strings.Join(strings.Fields(t), " "))
+		if t == "" || seen[t] {
+			continue
+		}
+		if utf8.RuneCountInString(t) > MaxTagLength {
+			return nil, fmt.Errorf("%w: %q is longer than %d characters", ErrInvalidTag, t, MaxTagLength)
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	if len(out) > MaxTags {
+		return nil, fmt.Errorf("%w: more than %d tags", ErrInvalidTag, MaxTags)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// HasTag reports whether the customer carries tag, which must be normalized.
+func (c Customer) HasTag(tag string) bool {
+	for _, t := range c.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package customers_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
+	"github.com/ezmobilemechanic/platform/internal/storage/memory"
+)
+
+func TestServiceNotes(t *testing.T) {
+	repo := memory.NewCustomerRepository()
+	svc := customers.NewService(repo, customers.WithNotes(repo.Notes()))
+
+	c, err := svc.Create(customers.CreateInput{FirstName: "Alex", Email: "alex@example.com"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	other, err := svc.Create(customers.CreateInput{FirstName: "Sam", Email: "sam@example.com"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	note, err := svc.AddNote(c.ID, customers.NoteInput{Body: "  Gate code 4411  ", Author: "dispatch"})
+	if err != nil {
+		t.Fatalf("add note: %v", err)
+	}
+	if note.Kind != customers.NoteKindNote || note.Body != "Gate code 4411" || note.CreatedAt.IsZero() {
+		t.Fatalf("unexpected note %+v", note)
+	}
+	call, err := svc.AddNote(c.ID, customers.NoteInput{Kind: customers.NoteKindCall, Body: "Left voicemail"})
+	if err != nil {
+		t.Fatalf("add call: %v", err)
+	}
+
+	bad := []customers.NoteInput{
+		{Body: " "},
+		{Kind: "fax", Body: "sent"},
+		{Body: strings.Repeat("x", customers.MaxNoteLength+1)},
+	}
+	for _, in := range bad {
+		if _, err := svc.AddNote(c.ID, in); !errors.Is(err, customers.ErrInvalidNote) {
+			t.Fatalf("expected ErrInvalidNote, got %v", err)
+		}
+	}
+	if _, err := svc.AddNote("missing", customers.NoteInput{Body: "hi"}); !errors.Is(err, customers.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	if err := svc.DeleteNote(other.ID, note.ID); !errors.Is(err, customers.ErrNoteNotFound) {
+		t.Fatalf("notes must only be deleted through their customer, got %v", err)
+	}
+	if err := svc.DeleteNote(c.ID, note.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	notes, err := svc.Notes(c.ID)
+	if err != nil {
+		t.Fatalf("notes: %v", err)
+	}
+	if len(notes) != 1 || notes[0].ID != call.ID {
+		t.Fatalf("expected only the call note, got %+v", notes)
+	}
+}
+
+func TestServiceTags(t *testing.T) {
+	svc := customers.NewService(memory.NewCustomerRepository())
+
+	c, err := svc.Create(customers.CreateInput{FirstName: "Alex", Email: "alex@example.com", Tags: []string{"Fleet", " vip  customer", "fleet", ""}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if want := []string{"fleet", "vip customer"}; !slices.Equal(c.Tags, want) {
+		t.Fatalf("expected tags %v, got %v", want, c.Tags)
+	}
+
+	tags := []string{"warranty"}
+	updated, err := svc.Update(c.ID, customers.UpdateInput{Tags: &tags})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if !slices.Equal(updated.Tags, tags) {
+		t.Fatalf("tags must be replaced, got %v", updated.Tags)
+	}
+
+	long := []string{strings.Repeat("t", customers.MaxTagLength+1)}
+	if _, err := svc.Update(c.ID, customers.UpdateInput{Tags: &long}); !errors.Is(err, customers.ErrInvalidTag) {
+		t.Fatalf("expected ErrInvalidTag, got %v", err)
+	}
+
+	page, err := svc.List(listing.Spec{Search: "tag:Warranty"})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(page.Items) != 1 || page.Items[0].ID != c.ID {
+		t.Fatalf("expected tag search to find the customer, got %+v", page.Items)
+	}
+}
@@ -0,0 +1,133 @@
+package customers
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/ezmobilemechanic/platform/internal/phone"
+)
+
+// minVINFragment is the shortest query tried against vehicle VINs. Shorter
+// alphanumeric strings match too many vehicles to be useful.
+const minVINFragment = 4
+
+// minPhoneDigits is the fewest digits a query needs to be matched against
+// phone numbers.
+const minPhoneDigits = 3
+
+// SearchTerms is a free-text customer query broken into the form each field
+// is compared in. A customer matches when any non-empty term is a substring
+// of the corresponding field, or when it carries Tag.
+type SearchTerms struct {
+	// Text is the lower-cased query, matched against the full name
+	// ("first last"), the company name and the email address.
+	Text string
+	// Digits is set when the query looks like a phone number and holds its
+	// digits, matched against the digits of the stored phone.
+	Digits string
+	// PhoneE164 is set when the query is a whole phone number and holds it in
+	// E.164, for exact matches on encrypted phones. Numbers without a country
+	// code are read as national numbers of the region passed to ParseSearch.
+	PhoneE164 string
+	// VIN is set when the query could be part of a VIN and holds it upper-cased
+	// without spaces, matched against the VINs of the customer's vehicles.
+	VIN string
+	// Tag is set by queries of the form "tag:<tag>" and holds the normalized
+	// tag. The other terms are then empty.
+	Tag string
+}
+
+// tagPrefix introduces a tag query.
+const tagPrefix = "tag:"
+
+// ParseSearch splits a search query into SearchTerms, reading phone numbers
+// without a country code as national numbers of region. It must be the region
+// phones are stored in (see WithPhoneRegion), or encrypted phones will not
+// match. A blank query yields the zero value.
+func ParseSearch(q, region string) SearchTerms {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return SearchTerms{}
+	}
+
+	if len(q) > len(tagPrefix) && strings.EqualFold(q[:len(tagPrefix)], tagPrefix) {
+		if tags, err := NormalizeTags([]string{q[len(tagPrefix):]}); err == nil && len(tags) == 1 {
+			return SearchTerms{Tag: tags[0]}
+		}
+	}
+
+	terms := SearchTerms{Text: strings.ToLower(q)}
+	if isPhoneLike(q) {
+		digits := PhoneDigits(q)
+		// A leading NANP country code is not part of stored 10-digit
+		// numbers.
+		if phone.CallingCode(region) == "1" && len(digits) == 11 && digits[0] == '1' {
+			digits = digits[1:]
+		}
+		if len(digits) >= minPhoneDigits {
+			terms.Digits = digits
+		}
+		terms.PhoneE164 = phone.Normalize(q, region)
+	}
+	if vin := strings.ToUpper(strings.ReplaceAll(q, " ", "")); len(vin) >= minVINFragment && isAlphanumeric(vin) {
+		terms.VIN = vin
+	}
+	return terms
+}
+
+// IsZero reports whether the terms match everything.
+func (t SearchTerms) IsZero() bool {
+	return t == SearchTerms{}
+}
+
+// Matches reports whether the customer's own fields match. VIN terms are
+// checked separately with MatchesVIN because vehicles live elsewhere.
+func (t SearchTerms) Matches(c Customer) bool {
+	if t.Tag != "" {
+		return c.HasTag(t.Tag)
+	}
+	if t.Text != "" {
+		if strings.Contains(strings.ToLower(c.FirstName+" "+c.LastName), t.Text) ||
+			strings.Contains(strings.ToLower(c.CompanyName), t.Text) ||
+			strings.Contains(strings.ToLower(c.Email), t.Text) {
+			return true
+		}
+	}
+	return t.Digits != "" && strings.Contains(PhoneDigits(c.Phone), t.Digits)
+}
+
+// MatchesVIN reports whether vin contains the VIN term.
+func (t SearchTerms) MatchesVIN(vin string) bool {
+	return t.VIN != "" && strings.Contains(strings.ToUpper(vin), t.VIN)
+}
+
+// PhoneDigits strips everything but ASCII digits from s.
+func PhoneDigits(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// isPhoneLike reports whether s contains only digits and the punctuation
+// people type in phone numbers.
+func isPhoneLike(s string) bool {
+	for _, r := range s {
+		if !(r >= '0' && r <= '9') && !strings.ContainsRune(" -().+", r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlphanumeric(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,34 @@
+package customers_test
+
+import (
+	"testing"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+)
+
+func TestParseSearch(t *testing.T) {
+	for _, tc := range []struct {
+		q      string
+		region string
+		want   customers.SearchTerms
+	}{
+		{"", "US", customers.SearchTerms{}},
+		{"  Jo ", "US", customers.SearchTerms{Text: "jo"}},
+		{"Driver", "US", customers.SearchTerms{Text: "driver", VIN: "DRIVER"}},
+		{"+1 (904) 555-0101", "US", customers.SearchTerms{Text: "+1 (904) 555-0101", Digits: "9045550101", PhoneE164: "+19045550101"}},
+		{"555-03", "US", customers.SearchTerms{Text: "555-03", Digits: "55503"}},
+		{"0101", "US", customers.SearchTerms{Text: "0101", Digits: "0101", VIN: "0101"}},
+		{"12", "US", customers.SearchTerms{Text: "12"}},
+		{"hka 12345", "US", customers.SearchTerms{Text: "hka 12345", VIN: "HKA12345"}},
+		{"a@b.co", "US", customers.SearchTerms{Text: "a@b.co"}},
+		// National numbers are read in the region phones are stored in, and
+		// only NANP regions drop a leading 1.
+		{"01632 960000", "GB", customers.SearchTerms{Text: "01632 960000", Digits: "01632960000", PhoneE164: "+441632960000", VIN: "01632960000"}},
+		{"1 904 555 0101", "GB", customers.SearchTerms{Text: "1 904 555 0101", Digits: "19045550101", VIN: "19045550101"}},
+		{"+1 (904) 555-0101", "GB", customers.SearchTerms{Text: "+1 (904) 555-0101", Digits: "19045550101", PhoneE164: "+19045550101"}},
+	} {
+		if got := customers.ParseSearch(tc.q, tc.region); got != tc.want {
+			t.Errorf("ParseSearch(%q, %q) = %+v, want %+v", tc.q, tc.region, got, tc.want)
+		}
+	}
+}
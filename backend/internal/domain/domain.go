@@ -1,10 +1,23 @@
 package domain
 
 import (
+	"errors"
+	"log/slog"
+
 	"github.com/ezmobilemechanic/platform/internal/domain/customers"
 	"github.com/ezmobilemechanic/platform/internal/domain/quotes"
 	"github.com/ezmobilemechanic/platform/internal/domain/users"
 	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+	"github.com/ezmobilemechanic/platform/internal/geo"
+	"github.com/ezmobilemechanic/platform/internal/maintenance"
+	"github.com/ezmobilemechanic/platform/internal/messaging"
+	"github.com/ezmobilemechanic/platform/internal/phone"
+	"github.com/ezmobilemechanic/platform/internal/pricing"
+	"github.com/ezmobilemechanic/platform/internal/privacy"
+	"github.com/ezmobilemechanic/platform/internal/recalls"
+	"github.com/ezmobilemechanic/platform/internal/servicehistory"
+	"github.com/ezmobilemechanic/platform/internal/specs"
+	"github.com/ezmobilemechanic/platform/internal/timeline"
 )
 
 // Container wires domain services together. In the future this will manage
@@ -14,14 +27,63 @@ type Container struct {
 	Vehicles  vehicles.Service
 	Quotes    quotes.Service
 	Users     users.Service
+
+	// PhoneRegion is the region assumed for phone numbers entered without a
+	// country code.
+	PhoneRegion string
+	// Geocoder locates addresses for scheduling and travel pricing.
+	Geocoder geo.Geocoder
+	// Messages delivers outbound messages after checking the customer's
+	// consent, and notes each one on the customer.
+	Messages messaging.Sender
+	// Timeline merges a customer's quotes, vehicles, notes and consent
+	// changes.
+	Timeline timeline.Service
+	// Privacy exports customer data and handles erasure requests.
+	Privacy privacy.Service
+	// ServiceHistory assembles a vehicle's service record.
+	ServiceHistory servicehistory.Service
+	// Maintenance works out which scheduled services vehicles are due for.
+	Maintenance maintenance.Service
+	// Specs looks up fluid capacities, tire sizes and torque specs.
+	Specs specs.Service
+	// Recalls matches NHTSA recalls and service bulletins to vehicles.
+	Recalls recalls.Service
 }
 
 // Options configures the domain container.
 type Options struct {
 	CustomerRepo customers.Repository
+	AddressRepo  customers.AddressRepository
+	ConsentRepo  customers.ConsentRepository
+	NoteRepo     customers.NoteRepository
+	ContactRepo  customers.ContactRepository
 	VehicleRepo  vehicles.Repository
+	OdometerRepo vehicles.OdometerRepository
+	RecallRepo   vehicles.RecallRepository
 	QuoteRepo    quotes.Repository
+	QuoteHistory quotes.HistoryRepository
 	UserRepo     users.Repository
+	ErasureRepo  privacy.Repository
+
+	// PhoneRegion defaults to phone.DefaultRegion.
+	PhoneRegion string
+	// Geocoder defaults to geo.Noop.
+	Geocoder geo.Geocoder
+	// Sender delivers messages once consent is checked. It defaults to
+	// logging them.
+	Sender messaging.Sender
+	// Schedule holds the maintenance rules. It defaults to
+	// maintenance.Default.
+	Schedule *maintenance.Schedule
+	// Specs is the vehicle spec catalog. It defaults to an empty one.
+	Specs *specs.Catalog
+	// Recalls holds the NHTSA recalls and service bulletins. It defaults to
+	// an empty dataset.
+	Recalls *recalls.Dataset
+	// Rates is the labor rate card. Without one quotes charge for parts
+	// only.
+	Rates *pricing.RateCard
 }
 
 // New constructs a domain container with provided repositories.
@@ -31,25 +93,160 @@ func New(opts Options) Container {
 		customerRepo = customers.NullRepository{}
 	}
 
+	addressRepo := opts.AddressRepo
+	if addressRepo == nil {
+		addressRepo = customers.NullAddressRepository{}
+	}
+
+	consentRepo := opts.ConsentRepo
+	if consentRepo == nil {
+		consentRepo = customers.NullConsentRepository{}
+	}
+
+	noteRepo := opts.NoteRepo
+	if noteRepo == nil {
+		noteRepo = customers.NullNoteRepository{}
+	}
+
+	contactRepo := opts.ContactRepo
+	if contactRepo == nil {
+		contactRepo = customers.NullContactRepository{}
+	}
+
 	vehicleRepo := opts.VehicleRepo
 	if vehicleRepo == nil {
 		vehicleRepo = vehicles.NullRepository{}
 	}
 
+	odometerRepo := opts.OdometerRepo
+	if odometerRepo == nil {
+		odometerRepo = vehicles.NullOdometerRepository{}
+	}
+
+	recallRepo := opts.RecallRepo
+	if recallRepo == nil {
+		recallRepo = vehicles.NullRecallRepository{}
+	}
+
 	quoteRepo := opts.QuoteRepo
 	if quoteRepo == nil {
 		quoteRepo = quotes.NullRepository{}
 	}
 
+	quoteHistory := opts.QuoteHistory
+	if quoteHistory == nil {
+		quoteHistory = quotes.NullHistoryRepository{}
+	}
+
 	userRepo := opts.UserRepo
 	if userRepo == nil {
 		userRepo = users.NullRepository{}
 	}
 
+	erasureRepo := opts.ErasureRepo
+	if erasureRepo == nil {
+		erasureRepo = privacy.NullRepository{}
+	}
+
+	phoneRegion := opts.PhoneRegion
+	if phoneRegion == "" {
+		phoneRegion = phone.DefaultRegion
+	}
+
+	geocoder := opts.Geocoder
+	if geocoder == nil {
+		geocoder = geo.Noop{}
+	}
+
+	sender := opts.Sender
+	if sender == nil {
+		sender = messaging.LogSender{Logger: slog.Default()}
+	}
+
+	schedule := maintenance.Default()
+	if opts.Schedule != nil {
+		schedule = *opts.Schedule
+	}
+
+	catalog := specs.Catalog{Specs: []specs.Spec{}}
+	if opts.Specs != nil {
+		catalog = *opts.Specs
+	}
+
+	dataset := opts.Recalls
+	if dataset == nil {
+		dataset = recalls.NewDataset()
+	}
+
+	var rates pricing.RateCard
+	if opts.Rates != nil {
+		rates = *opts.Rates
+	}
+
+	customerService := customers.NewService(customerRepo,
+		customers.WithPhoneRegion(phoneRegion),
+		customers.WithAddresses(addressRepo),
+		customers.WithConsents(consentRepo),
+		customers.WithNotes(noteRepo),
+		customers.WithContacts(contactRepo),
+		customers.WithGeocoder(geocoder),
+	)
+	vehicleService := vehicles.NewService(vehicleRepo,
+		vehicles.WithOdometer(odometerRepo),
+		vehicles.WithRecalls(recallRepo),
+		vehicles.WithOwners(func(customerID string) (string, error) {
+			c, err := customerService.Get(customerID)
+			if errors.Is(err, customers.ErrNotFound) {
+				return "", vehicles.ErrOwnerNotFound
+			}
+			return c.ID, err
+		}),
+	)
+	specService := specs.NewService(catalog, vehicleService)
+	quoteService := quotes.NewService(quoteRepo,
+		quotes.WithHistory(quoteHistory),
+		quotes.WithAccounts(func(customerID string) (quotes.Account, error) {
+			c, err := customerService.Get(customerID)
+			if errors.Is(err, customers.ErrNotFound) {
+				return quotes.Account{}, nil
+			}
+			if err != nil || !c.IsFleet() {
+				return quotes.Account{}, err
+			}
+			contacts, err := customerService.Contacts(c.ID)
+			if err != nil && !errors.Is(err, customers.ErrNotImplemented) {
+				return quotes.Account{}, err
+			}
+			account := quotes.Account{PORequired: c.PORequired, Contacts: map[string]bool{}}
+			for _, contact := range contacts {
+				account.Contacts[contact.ID] = contact.CanApprove()
+			}
+			return account, nil
+		}),
+		quotes.WithQuantities(func(vehicleID, description string) (int, error) {
+			n, err := specService.Quantity(vehicleID, description)
+			if errors.Is(err, vehicles.ErrNotImplemented) {
+				return 0, nil
+			}
+			return n, err
+		}),
+		quotes.WithRates(rates),
+	)
+	historyService := servicehistory.NewService(vehicleService, quoteService)
+
 	return Container{
-		Customers: customers.NewService(customerRepo),
-		Vehicles:  vehicles.NewService(vehicleRepo),
-		Quotes:    quotes.NewService(quoteRepo),
-		Users:     users.NewService(userRepo),
+		PhoneRegion:    phoneRegion,
+		Geocoder:       geocoder,
+		Messages:       messaging.RequireConsent(messaging.Record(sender, customerService), customerService),
+		Timeline:       timeline.NewService(customerService, vehicleService, quoteService),
+		Privacy:        privacy.NewService(erasureRepo, customerService, vehicleService, quoteService),
+		ServiceHistory: historyService,
+		Maintenance:    maintenance.NewService(schedule, customerService, vehicleService, historyService),
+		Specs:          specService,
+		Recalls:        recalls.NewService(dataset, vehicleService),
+		Customers:      customerService,
+		Vehicles:       vehicleService,
+		Quotes:         quoteService,
+		Users:          users.NewService(userRepo),
 	}
 }
@@ -0,0 +1,234 @@
+package listing
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Domain-level errors for list queries.
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidSort   = errors.New("invalid sort")
+)
+
+const (
+	// DefaultLimit is applied when a Spec does not set a limit.
+	DefaultLimit = 50
+	// MaxLimit caps the page size a caller may request.
+	MaxLimit = 200
+)
+
+// SortField names a column that list queries can be ordered by.
+type SortField string
+
+const (
+	SortCreatedAt SortField = "created_at"
+	SortUpdatedAt SortField = "updated_at"
+)
+
+// Sort describes the ordering of a list query. Ties are always broken by ID
+// in the same direction so that cursors are stable across backends.
+type Sort struct {
+	Field SortField
+	Desc  bool
+}
+
+// DefaultSort orders newest records first.
+var DefaultSort = Sort{Field: SortCreatedAt, Desc: true}
+
+// ParseSort parses the JSON:API style sort parameter ("created_at" for
+// ascending, "-created_at" for descending). An empty value yields DefaultSort.
+func ParseSort(v string) (Sort, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return DefaultSort, nil
+	}
+
+	s := Sort{}
+	if strings.HasPrefix(v, "-") {
+		s.Desc = true
+		v = v[1:]
+	}
+	switch SortField(v) {
+	case SortCreatedAt, SortUpdatedAt:
+		s.Field = SortField(v)
+	default:
+		return Sort{}, fmt.Errorf("%w: %q", ErrInvalidSort, v)
+	}
+	return s, nil
+}
+
+// String renders the sort in the same format accepted by ParseSort.
+func (s Sort) String() string {
+	if s.Desc {
+		return "-" + string(s.Field)
+	}
+	return string(s.Field)
+}
+
+// Spec is the backend-agnostic description of a list query. Every repository
+// that supports listing must interpret it identically.
+type Spec struct {
+	Cursor string
+	Limit  int
+	Sort   Sort
+
+	// Filters. Zero values disable the filter. CreatedFrom is inclusive and
+	// CreatedTo is exclusive.
+	Status      string
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	Search      string
+}
+
+// MatchesCreated reports whether t falls inside the spec's created range.
+func (s Spec) MatchesCreated(t time.Time) bool {
+	if !s.CreatedFrom.IsZero() && t.Before(s.CreatedFrom) {
+		return false
+	}
+	if !s.CreatedTo.IsZero() && !t.Before(s.CreatedTo) {
+		return false
+	}
+	return true
+}
+
+// Normalize applies defaults and clamps the limit.
+func (s Spec) Normalize() Spec {
+	if s.Sort.Field == "" {
+		s.Sort = DefaultSort
+	}
+	if s.Limit <= 0 {
+		s.Limit = DefaultLimit
+	}
+	if s.Limit > MaxLimit {
+		s.Limit = MaxLimit
+	}
+	s.Status = strings.TrimSpace(s.Status)
+	s.Search = strings.TrimSpace(s.Search)
+	return s
+}
+
+// Page is a single page of list results. Total counts every row matching the
+// filters, independent of the cursor.
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+	HasMore    bool
+	Total      int
+}
+
+// Cursor identifies the last row of a page: the value of the sort column and
+// the row ID used as tie-breaker.
+type Cursor struct {
+	Field SortField
+	Value time.Time
+	ID    string
+}
+
+// Encode serializes the cursor into an opaque URL-safe token.
+func (c Cursor) Encode() string {
+	raw := string(c.Field) + "|" + c.Value.UTC().Format(time.RFC3339Nano) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a token produced by Cursor.Encode and verifies that it
+// was issued for the given sort field.
+func DecodeCursor(token string, field SortField) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(raw), "|", 3)
+	if len(parts) != 3 || parts[2] == "" {
+		return Cursor{}, ErrInvalidCursor
+	}
+	if SortField(parts[0]) != field {
+		return Cursor{}, fmt.Errorf("%w: issued for sort %q", ErrInvalidCursor, parts[0])
+	}
+	ts, err := time.Parse(time.RFC3339Nano, parts[1])
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return Cursor{Field: field, Value: ts, ID: parts[2]}, nil
+}
+
+// After reports whether a row with the given sort value and ID comes after the
+// cursor position under sort s.
+func (c Cursor) After(s Sort, value time.Time, id string) bool {
+	if !value.Equal(c.Value) {
+		if s.Desc {
+			return value.Before(c.Value)
+		}
+		return value.After(c.Value)
+	}
+	if s.Desc {
+		return id < c.ID
+	}
+	return id > c.ID
+}
+
+// Less orders two rows under sort s, breaking ties by ID.
+func Less(s Sort, aValue time.Time, aID string, bValue time.Time, bID string) bool {
+	if !aValue.Equal(bValue) {
+		if s.Desc {
+			return aValue.After(bValue)
+		}
+		return aValue.Before(bValue)
+	}
+	if s.Desc {
+		return aID > bID
+	}
+	return aID < bID
+}
+
+// KeyFunc returns the value of the sort column and the ID of an item.
+type KeyFunc[T any] func(item T, field SortField) (time.Time, string)
+
+// Paginate orders already-filtered items under spec, skips everything up to
+// and including the cursor and cuts a page. It is the reference
+// implementation that SQL backends mirror with keyset queries.
+func Paginate[T any](items []T, spec Spec, key KeyFunc[T]) (Page[T], error) {
+	spec = spec.Normalize()
+
+	var after *Cursor
+	if spec.Cursor != "" {
+		c, err := DecodeCursor(spec.Cursor, spec.Sort.Field)
+		if err != nil {
+			return Page[T]{}, err
+		}
+		after = &c
+	}
+
+	sorted := make([]T, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		av, aid := key(sorted[i], spec.Sort.Field)
+		bv, bid := key(sorted[j], spec.Sort.Field)
+		return Less(spec.Sort, av, aid, bv, bid)
+	})
+
+	out := make([]T, 0, spec.Limit)
+	hasMore := false
+	for _, item := range sorted {
+		v, id := key(item, spec.Sort.Field)
+		if after != nil && !after.After(spec.Sort, v, id) {
+			continue
+		}
+		if len(out) == spec.Limit {
+			hasMore = true
+			break
+		}
+		out = append(out, item)
+	}
+
+	page := Page[T]{Items: out, HasMore: hasMore, Total: len(items)}
+	if hasMore {
+		v, id := key(out[len(out)-1], spec.Sort.Field)
+		page.NextCursor = Cursor{Field: spec.Sort.Field, Value: v, ID: id}.Encode()
+	}
+	return page, nil
+}
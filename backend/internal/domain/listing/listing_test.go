@@ -0,0 +1,88 @@
+package listing_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
+)
+
+type row struct {
+	id      string
+	created time.Time
+}
+
+func rowKey(r row, _ listing.SortField) (time.Time, string) {
+	return r.created, r.id
+}
+
+func TestPaginateWalksAllPagesInOrder(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := []row{
+		{id: "c", created: base.Add(time.Minute)},
+		{id: "a", created: base},
+		{id: "b", created: base.Add(time.Minute)}, // ties with "c"
+		{id: "d", created: base.Add(2 * time.Minute)},
+	}
+
+	for _, tc := range []struct {
+		sort listing.Sort
+		want []string
+	}{
+		{sort: listing.Sort{Field: listing.SortCreatedAt, Desc: true}, want: []string{"d", "c", "b", "a"}},
+		{sort: listing.Sort{Field: listing.SortCreatedAt}, want: []string{"a", "b", "c", "d"}},
+	} {
+		var got []string
+		spec := listing.Spec{Limit: 3, Sort: tc.sort}
+		for {
+			page, err := listing.Paginate(rows, spec, rowKey)
+			if err != nil {
+				t.Fatalf("paginate failed: %v", err)
+			}
+			if page.Total != len(rows) {
+				t.Fatalf("expected total %d, got %d", len(rows), page.Total)
+			}
+			for _, r := range page.Items {
+				got = append(got, r.id)
+			}
+			if !page.HasMore {
+				break
+			}
+			spec.Cursor = page.NextCursor
+		}
+
+		if len(got) != len(tc.want) {
+			t.Fatalf("sort %s: expected %v, got %v", tc.sort, tc.want, got)
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Fatalf("sort %s: expected %v, got %v", tc.sort, tc.want, got)
+			}
+		}
+	}
+}
+
+func TestDecodeCursorRejectsMismatchedSort(t *testing.T) {
+	token := listing.Cursor{Field: listing.SortCreatedAt, Value: time.Now(), ID: "x"}.Encode()
+
+	if _, err := listing.DecodeCursor(token, listing.SortUpdatedAt); !errors.Is(err, listing.ErrInvalidCursor) {
+		t.Fatalf("expected ErrInvalidCursor, got %v", err)
+	}
+	if _, err := listing.DecodeCursor("not-a-cursor", listing.SortCreatedAt); !errors.Is(err, listing.ErrInvalidCursor) {
+		t.Fatalf("expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	s, err := listing.ParseSort("-updated_at")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if s.Field != listing.SortUpdatedAt || !s.Desc {
+		t.Fatalf("unexpected sort %+v", s)
+	}
+	if _, err := listing.ParseSort("banana"); !errors.Is(err, listing.ErrInvalidSort) {
+		t.Fatalf("expected ErrInvalidSort, got %v", err)
+	}
+}
@@ -2,24 +2,56 @@ package quotes
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
+	"github.com/ezmobilemechanic/platform/internal/pricing"
 )
 
 var (
 	ErrNotImplemented = errors.New("quotes repository: not implemented")
 	ErrNotFound       = errors.New("quote not found")
+	ErrInvalid        = errors.New("invalid quote")
+	// ErrPORequired is returned when accepting a quote without a purchase
+	// order number for an account that requires one.
+	ErrPORequired = errors.New("purchase order number required")
+	// ErrApproverRequired is returned when accepting a fleet quote without
+	// naming a contact allowed to approve work.
+	ErrApproverRequired = errors.New("approving contact required")
+	// ErrLocked is returned when changing the purchase order number or
+	// contact of a quote that has been accepted, converted or voided.
+	ErrLocked = errors.New("quote approval is locked")
 )
 
 // Quote represents a customer quote with line items.
 type Quote struct {
-	ID          string
-	CustomerID  string
-	VehicleID   string
-	Status      Status
-	TotalAmount int64 // store in cents
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	LineItems   []LineItem
+	ID         string
+	CustomerID string
+	VehicleID  string
+	Status     Status
+	// The amounts are in cents, worked out when the quote is created (see
+	// package pricing). TotalAmount is PartsAmount + LaborAmount +
+	// FeesAmount - DiscountAmount + TaxAmount.
+	PartsAmount    int64
+	LaborAmount    int64
+	FeesAmount     int64
+	DiscountAmount int64
+	TaxAmount      int64
+	TotalAmount    int64
+	// TechnicianTier is the tier of technician the labor was priced for.
+	TechnicianTier string
+	// TechnicianNotes records what the technician found and did, for the
+	// vehicle's service history.
+	TechnicianNotes string
+	// PONumber is the customer's purchase order number for the work.
+	PONumber string
+	// ContactID is the fleet contact who requested or approved the work.
+	ContactID string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	LineItems []LineItem
 }
 
 // LineItem represents an item within a quote.
@@ -30,6 +62,12 @@ type LineItem struct {
 	Quantity    int
 	UnitPrice   int64
 	LaborHours  float64
+	// Category is the kind of service, which may have its own labor rate.
+	Category string
+	// LaborRate is the hourly rate the line was priced at and LaborAmount
+	// its labor charge, both in cents.
+	LaborRate   int64
+	LaborAmount int64
 	SortOrder   int
 }
 
@@ -42,13 +80,32 @@ const (
 	StatusAccepted  Status = "accepted"
 	StatusDeclined  Status = "declined"
 	StatusConverted Status = "converted"
+	// StatusExpired is a sent quote the customer did not answer in time.
+	StatusExpired Status = "expired"
+	// StatusVoid is a quote withdrawn by staff, such as one made in error
+	// or for a job cancelled after it was accepted.
+	StatusVoid Status = "void"
 )
 
 // Repository abstracts quote persistence.
+//
+// ListByCustomer honours the Status, created range and Search (a
+// case-insensitive substring of any line item description) filters.
+// ListByVehicle returns every quote for the vehicle, whoever the customer,
+// oldest first.
+//
+// UpdateStatus saves an existing quote with its new status change.To and
+// appends change to the quote's status history in one transaction. It saves
+// nothing and returns ErrStatusChanged when the stored quote's status is no
+// longer change.From. Line items are left as they are. A change whose From
+// and To are the same saves the quote's other fields under the same check
+// and records no history; the service saves every edit this way.
 type Repository interface {
 	FindByID(id string) (Quote, error)
 	Save(quote Quote) (Quote, error)
-	ListByCustomer(customerID string, offset, limit int) ([]Quote, error)
+	UpdateStatus(quote Quote, change StatusChange) (Quote, error)
+	ListByCustomer(customerID string, spec listing.Spec) (listing.Page[Quote], error)
+	ListByVehicle(vehicleID string) ([]Quote, error)
 }
 
 // NullRepository returns ErrNotImplemented for all operations.
@@ -62,16 +119,82 @@ func (NullRepository) Save(quote Quote) (Quote, error) {
 	return Quote{}, ErrNotImplemented
 }
 
-func (NullRepository) ListByCustomer(customerID string, offset, limit int) ([]Quote, error) {
+func (NullRepository) UpdateStatus(quote Quote, change StatusChange) (Quote, error) {
+	return Quote{}, ErrNotImplemented
+}
+
+func (NullRepository) ListByCustomer(customerID string, spec listing.Spec) (listing.Page[Quote], error) {
+	return listing.Page[Quote]{}, ErrNotImplemented
+}
+
+func (NullRepository) ListByVehicle(vehicleID string) ([]Quote, error) {
+	return nil, ErrNotImplemented
+}
+
+// StatusChange records a quote moving from one status to another.
+type StatusChange struct {
+	ID      string
+	QuoteID string
+	From    Status
+	To      Status
+	// Actor is the staff member who made the change and Reason why, as
+	// given in StatusInput.
+	Actor     string
+	Reason    string
+	ChangedAt time.Time
+}
+
+// HistoryRepository stores quote status changes. ListByQuote returns them
+// oldest first. Changes are removed with their quote. The quote service
+// records changes through Repository.UpdateStatus and only reads them here.
+type HistoryRepository interface {
+	Append(change StatusChange) (StatusChange, error)
+	ListByQuote(quoteID string) ([]StatusChange, error)
+}
+
+// NullHistoryRepository returns ErrNotImplemented for all operations.
+type NullHistoryRepository struct{}
+
+func (NullHistoryRepository) Append(change StatusChange) (StatusChange, error) {
+	return StatusChange{}, ErrNotImplemented
+}
+
+func (NullHistoryRepository) ListByQuote(quoteID string) ([]StatusChange, error) {
 	return nil, ErrNotImplemented
 }
 
 // Service provides business logic around quotes.
 type Service interface {
 	Get(id string) (Quote, error)
+	// Create prices the quote's parts, labor, fees and tax at the current
+	// rates. The amounts are kept as they are when the rates change later.
 	Create(input CreateInput) (Quote, error)
-	UpdateStatus(id string, status Status) (Quote, error)
-	ListForCustomer(customerID string, offset, limit int) ([]Quote, error)
+	// UpdateStatus moves the quote to input.Status and records the change
+	// with its actor and reason, unless the quote already has that status.
+	// An unknown status returns ErrInvalid. A move the state machine does
+	// not allow, or one a guard refuses, returns a *TransitionError: a
+	// quote is sent or accepted only with line items, voided only with a
+	// reason, and accepted or converted only with the purchase order number
+	// or approving contact the customer's account needs (ErrPORequired,
+	// ErrApproverRequired). A quote moved on by another change meanwhile
+	// returns a *TransitionError wrapping ErrStatusChanged.
+	UpdateStatus(id string, input StatusInput) (Quote, error)
+	// Update changes the fields set in input. A contact must be on the
+	// customer's account. Once a quote is accepted, converted or voided its
+	// purchase order number and contact are fixed (ErrLocked), and an
+	// accepted quote is only saved while its account would still approve
+	// it. When input.Status is set the quote also moves to
+	// it, as UpdateStatus does, with the guards seeing the edited quote;
+	// nothing is saved if the move is refused. Edits alone are saved only
+	// while the quote keeps the status they were checked against, and
+	// return ErrStatusChanged once another change has moved it on.
+	Update(id string, input UpdateInput) (Quote, error)
+	ListForCustomer(customerID string, spec listing.Spec) (listing.Page[Quote], error)
+	// ListForVehicle returns the vehicle's quotes oldest first, including
+	// those made for its previous owners.
+	ListForVehicle(vehicleID string) ([]Quote, error)
+	// StatusHistory returns the quote's status changes, oldest first.
+	StatusHistory(id string) ([]StatusChange, error)
 }
 
 // CreateInput is used to create new quotes.
@@ -79,23 +202,105 @@ type CreateInput struct {
 	CustomerID string
 	VehicleID  string
 	LineItems  []CreateLineItem
+	// TechnicianNotes is usually filled in later, once the work is done.
+	TechnicianNotes string
+	PONumber        string
+	ContactID       string
+	// TechnicianTier selects the labor rates, along with each line's
+	// category. Left empty, the shop's default rates apply.
+	TechnicianTier string
+	// Discount is taken off the quote, in cents, and capped at its parts,
+	// labor and fees.
+	Discount int64
 }
 
-// CreateLineItem describes line items when creating a quote.
+// UpdateInput carries the fields to change; nil fields are left as they are.
+type UpdateInput struct {
+	TechnicianNotes *string
+	PONumber        *string
+	ContactID       *string
+	Status          *StatusInput
+}
+
+// Account is what quotes need to know of the customer's account.
+type Account struct {
+	// PORequired is set for accounts that need a purchase order number
+	// before work is approved.
+	PORequired bool
+	// Contacts maps the IDs of the account's contacts to whether they may
+	// approve work. Once any contact may, accepting a quote needs one.
+	Contacts map[string]bool
+}
+
+func (a Account) hasApprovers() bool {
+	for _, ok := range a.Contacts {
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateLineItem describes line items when creating a quote. A Quantity
+// left at 0 is filled in from the vehicle's specs for fluid work such as an
+// oil change (see WithQuantities), and otherwise defaults to 1.
 type CreateLineItem struct {
 	Description string
 	Quantity    int
 	UnitPrice   int64
 	LaborHours  float64
+	Category    string
+}
+
+// Option configures a quote service.
+type Option func(*service)
+
+// WithHistory sets the repository recording status changes. Without one,
+// changes are not recorded.
+func WithHistory(repo HistoryRepository) Option {
+	return func(s *service) { s.history = repo }
+}
+
+// WithAccounts looks up the customer's account with lookup, to check
+// purchase orders and approving contacts. Without it every customer is
+// treated as an individual.
+func WithAccounts(lookup func(customerID string) (Account, error)) Option {
+	return func(s *service) { s.account = lookup }
+}
+
+// WithQuantities looks up how much of a part a line item needs for the
+// quote's vehicle with lookup, which returns 0 when it cannot say. Without
+// it quantities left out default to 1.
+func WithQuantities(lookup func(vehicleID, description string) (int, error)) Option {
+	return func(s *service) { s.quantity = lookup }
+}
+
+// WithRates prices labor, fees and tax with the shop's rate card. Without
+// it quotes charge for parts only.
+func WithRates(card pricing.RateCard) Option {
+	return func(s *service) { s.rates = card }
 }
 
 // NewService builds a quote service.
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+func NewService(repo Repository, opts ...Option) Service {
+	s := &service{
+		repo:     repo,
+		history:  NullHistoryRepository{},
+		account:  func(string) (Account, error) { return Account{}, nil },
+		quantity: func(string, string) (int, error) { return 0, nil },
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 type service struct {
-	repo Repository
+	repo     Repository
+	history  HistoryRepository
+	account  func(customerID string) (Account, error)
+	quantity func(vehicleID, description string) (int, error)
+	rates    pricing.RateCard
 }
 
 func (s *service) Get(id string) (Quote, error) {
@@ -104,34 +309,204 @@ func (s *service) Get(id string) (Quote, error) {
 
 func (s *service) Create(input CreateInput) (Quote, error) {
 	quote := Quote{
-		CustomerID: input.CustomerID,
-		VehicleID:  input.VehicleID,
-		Status:     StatusDraft,
+		CustomerID:      input.CustomerID,
+		VehicleID:       input.VehicleID,
+		Status:          StatusDraft,
+		TechnicianNotes: strings.TrimSpace(input.TechnicianNotes),
+		PONumber:        strings.TrimSpace(input.PONumber),
+		ContactID:       strings.TrimSpace(input.ContactID),
+		TechnicianTier:  strings.TrimSpace(input.TechnicianTier),
+	}
+	if input.Discount < 0 {
+		return Quote{}, fmt.Errorf("%w: discount must not be negative", ErrInvalid)
+	}
+	if quote.ContactID != "" {
+		account, err := s.account(quote.CustomerID)
+		if err != nil {
+			return Quote{}, err
+		}
+		if err := checkContact(account, quote.ContactID); err != nil {
+			return Quote{}, err
+		}
 	}
 
+	lines := make([]pricing.Line, 0, len(input.LineItems))
 	for idx, item := range input.LineItems {
+		if item.LaborHours < 0 {
+			return Quote{}, fmt.Errorf("%w: line %d: labor hours must not be negative", ErrInvalid, idx+1)
+		}
+		if item.Quantity <= 0 {
+			n, err := s.fillQuantity(quote.VehicleID, item.Description)
+			if err != nil {
+				return Quote{}, err
+			}
+			item.Quantity = n
+		}
 		quote.LineItems = append(quote.LineItems, LineItem{
 			Description: item.Description,
 			Quantity:    item.Quantity,
 			UnitPrice:   item.UnitPrice,
 			LaborHours:  item.LaborHours,
+			Category:    strings.TrimSpace(item.Category),
 			SortOrder:   idx,
 		})
-		quote.TotalAmount += int64(item.Quantity) * item.UnitPrice
+		lines = append(lines, pricing.Line{
+			Quantity:   item.Quantity,
+			UnitPrice:  item.UnitPrice,
+			LaborHours: item.LaborHours,
+			Category:   item.Category,
+		})
+	}
+
+	b := s.rates.Price(lines, quote.TechnicianTier, input.Discount)
+	for i, l := range b.Lines {
+		quote.LineItems[i].LaborRate = l.LaborRate
+		quote.LineItems[i].LaborAmount = l.Labor
 	}
+	quote.PartsAmount = b.Parts
+	quote.LaborAmount = b.Labor
+	quote.FeesAmount = b.Fees
+	quote.DiscountAmount = b.Discount
+	quote.TaxAmount = b.Tax
+	quote.TotalAmount = b.Total
 
 	return s.repo.Save(quote)
 }
 
-func (s *service) UpdateStatus(id string, status Status) (Quote, error) {
+func (s *service) UpdateStatus(id string, input StatusInput) (Quote, error) {
+	return s.Update(id, UpdateInput{Status: &input})
+}
+
+func (s *service) Update(id string, input UpdateInput) (Quote, error) {
+	if input.Status != nil && !input.Status.Status.Valid() {
+		return Quote{}, fmt.Errorf("%w: unknown status %q", ErrInvalid, input.Status.Status)
+	}
 	quote, err := s.repo.FindByID(id)
 	if err != nil {
 		return Quote{}, err
 	}
-	quote.Status = status
-	return s.repo.Save(quote)
+	if input.TechnicianNotes != nil {
+		quote.TechnicianNotes = strings.TrimSpace(*input.TechnicianNotes)
+	}
+	// The purchase order number and contact are checked against the status
+	// read here. The edits are saved only while the quote still has it, so
+	// an accept racing them cannot let a change through.
+	if input.PONumber != nil {
+		po := strings.TrimSpace(*input.PONumber)
+		if po != quote.PONumber && quote.Status.approved() {
+			return Quote{}, fmt.Errorf("%w: cannot change the purchase order number of a %s quote", ErrLocked, quote.Status)
+		}
+		quote.PONumber = po
+	}
+	if input.ContactID != nil {
+		contactID := strings.TrimSpace(*input.ContactID)
+		if contactID != quote.ContactID && quote.Status.approved() {
+			return Quote{}, fmt.Errorf("%w: cannot change the contact of a %s quote", ErrLocked, quote.Status)
+		}
+		quote.ContactID = contactID
+		if quote.ContactID != "" {
+			account, err := s.account(quote.CustomerID)
+			if err != nil {
+				return Quote{}, err
+			}
+			if err := checkContact(account, quote.ContactID); err != nil {
+				return Quote{}, err
+			}
+		}
+	}
+	if input.Status == nil || input.Status.Status == quote.Status {
+		if input.TechnicianNotes == nil && input.PONumber == nil && input.ContactID == nil {
+			return quote, nil
+		}
+		if quote.Status == StatusAccepted {
+			if err := s.checkApproval(quote); err != nil {
+				return Quote{}, err
+			}
+		}
+		status := quote.Status
+		quote, err = s.repo.UpdateStatus(quote, StatusChange{QuoteID: quote.ID, From: status, To: status})
+		if errors.Is(err, ErrStatusChanged) {
+			return Quote{}, fmt.Errorf("%w: quote is no longer %s", err, status)
+		}
+		return quote, err
+	}
+
+	from := quote.Status
+	if err := s.checkTransition(quote, *input.Status); err != nil {
+		return Quote{}, err
+	}
+	quote.Status = input.Status.Status
+	quote, err = s.repo.UpdateStatus(quote, StatusChange{
+		QuoteID: quote.ID,
+		From:    from,
+		To:      quote.Status,
+		Actor:   strings.TrimSpace(input.Status.Actor),
+		Reason:  strings.TrimSpace(input.Status.Reason),
+	})
+	if errors.Is(err, ErrStatusChanged) {
+		return Quote{}, &TransitionError{From: from, To: input.Status.Status, Err: err}
+	}
+	return quote, err
 }
 
-func (s *service) ListForCustomer(customerID string, offset, limit int) ([]Quote, error) {
-	return s.repo.ListByCustomer(customerID, offset, limit)
+// fillQuantity returns the quantity for a line item that left it out.
+func (s *service) fillQuantity(vehicleID, description string) (int, error) {
+	if vehicleID != "" {
+		n, err := s.quantity(vehicleID, description)
+		if err != nil {
+			return 0, err
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+	return 1, nil
+}
+
+// checkApproval checks that the customer's account is ready to approve
+// quote.
+func (s *service) checkApproval(quote Quote) error {
+	account, err := s.account(quote.CustomerID)
+	if err != nil {
+		return err
+	}
+	if account.PORequired && quote.PONumber == "" {
+		return ErrPORequired
+	}
+	if account.hasApprovers() && !account.Contacts[quote.ContactID] {
+		return ErrApproverRequired
+	}
+	return nil
+}
+
+// checkContact returns ErrInvalid unless contactID is one of the account's
+// contacts.
+func checkContact(account Account, contactID string) error {
+	if _, ok := account.Contacts[contactID]; !ok {
+		return fmt.Errorf("%w: contact %s is not on the customer's account", ErrInvalid, contactID)
+	}
+	return nil
+}
+
+func (s *service) StatusHistory(id string) ([]StatusChange, error) {
+	if _, err := s.repo.FindByID(id); err != nil {
+		return nil, err
+	}
+	return s.history.ListByQuote(id)
+}
+
+func (s *service) ListForCustomer(customerID string, spec listing.Spec) (listing.Page[Quote], error) {
+	return s.repo.ListByCustomer(customerID, spec.Normalize())
+}
+
+func (s *service) ListForVehicle(vehicleID string) ([]Quote, error) {
+	return s.repo.ListByVehicle(vehicleID)
+}
+
+// SortKey exposes the sort column value and ID of a quote for paginators.
+func SortKey(q Quote, field listing.SortField) (time.Time, string) {
+	if field == listing.SortUpdatedAt {
+		return q.UpdatedAt, q.ID
+	}
+	return q.CreatedAt, q.ID
 }
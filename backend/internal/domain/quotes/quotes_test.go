@@ -1,9 +1,12 @@
 package quotes_test
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
 	"github.com/ezmobilemechanic/platform/internal/domain/quotes"
+	"github.com/ezmobilemechanic/platform/internal/pricing"
 	"github.com/ezmobilemechanic/platform/internal/storage/memory"
 )
 
@@ -36,12 +39,15 @@ func TestQuoteServiceUpdateStatus(t *testing.T) {
 	repo := memory.NewQuoteRepository()
 	svc := quotes.NewService(repo)
 
-	q, err := svc.Create(quotes.CreateInput{CustomerID: "cust", VehicleID: "veh"})
+	q, err := svc.Create(quotes.CreateInput{CustomerID: "cust", VehicleID: "veh", LineItems: []quotes.CreateLineItem{{Description: "Oil change", Quantity: 1, UnitPrice: 7999}}})
 	if err != nil {
 		t.Fatalf("create quote failed: %v", err)
 	}
 
-	updated, err := svc.UpdateStatus(q.ID, quotes.StatusAccepted)
+	if _, err := svc.UpdateStatus(q.ID, quotes.StatusInput{Status: quotes.StatusSent}); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	updated, err := svc.UpdateStatus(q.ID, quotes.StatusInput{Status: quotes.StatusAccepted})
 	if err != nil {
 		t.Fatalf("update status failed: %v", err)
 	}
@@ -50,22 +56,400 @@ func TestQuoteServiceUpdateStatus(t *testing.T) {
 	}
 }
 
+func TestQuoteServiceStatusHistory(t *testing.T) {
+	repo := memory.NewQuoteRepository()
+	svc := quotes.NewService(repo, quotes.WithHistory(repo.History()))
+
+	q, err := svc.Create(quotes.CreateInput{CustomerID: "cust", VehicleID: "veh", LineItems: []quotes.CreateLineItem{{Description: "Oil change", Quantity: 1, UnitPrice: 7999}}})
+	if err != nil {
+		t.Fatalf("create quote failed: %v", err)
+	}
+	for _, status := range []quotes.Status{quotes.StatusSent, quotes.StatusSent, quotes.StatusAccepted} {
+		if _, err := svc.UpdateStatus(q.ID, quotes.StatusInput{Status: status, Actor: "sam"}); err != nil {
+			t.Fatalf("update status failed: %v", err)
+		}
+	}
+
+	changes, err := svc.StatusHistory(q.ID)
+	if err != nil {
+		t.Fatalf("status history failed: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("expected repeated statuses to be skipped, got %+v", changes)
+	}
+	if changes[0].From != quotes.StatusDraft || changes[0].To != quotes.StatusSent || changes[1].To != quotes.StatusAccepted {
+		t.Fatalf("unexpected changes %+v", changes)
+	}
+	if changes[1].Actor != "sam" {
+		t.Fatalf("expected the actor recorded, got %+v", changes[1])
+	}
+}
+
+func TestQuoteServiceStatusTransitions(t *testing.T) {
+	repo := memory.NewQuoteRepository()
+	svc := quotes.NewService(repo, quotes.WithHistory(repo.History()))
+
+	empty, err := svc.Create(quotes.CreateInput{CustomerID: "cust"})
+	if err != nil {
+		t.Fatalf("create quote failed: %v", err)
+	}
+	if _, err := svc.UpdateStatus(empty.ID, quotes.StatusInput{Status: "banana"}); !errors.Is(err, quotes.ErrInvalid) {
+		t.Fatalf("expected ErrInvalid for an unknown status, got %v", err)
+	}
+	_, err = svc.UpdateStatus(empty.ID, quotes.StatusInput{Status: quotes.StatusSent})
+	if !errors.Is(err, quotes.ErrNoLineItems) || !errors.Is(err, quotes.ErrInvalidTransition) {
+		t.Fatalf("expected ErrNoLineItems sending an empty quote, got %v", err)
+	}
+	if _, err := svc.UpdateStatus(empty.ID, quotes.StatusInput{Status: quotes.StatusVoid, Reason: "  "}); !errors.Is(err, quotes.ErrReasonRequired) {
+		t.Fatalf("expected ErrReasonRequired, got %v", err)
+	}
+	voided, err := svc.UpdateStatus(empty.ID, quotes.StatusInput{Status: quotes.StatusVoid, Actor: " sam ", Reason: " Duplicate "})
+	if err != nil || voided.Status != quotes.StatusVoid {
+		t.Fatalf("void: %+v, %v", voided, err)
+	}
+	changes, err := svc.StatusHistory(empty.ID)
+	if err != nil || len(changes) != 1 || changes[0].Actor != "sam" || changes[0].Reason != "Duplicate" || changes[0].ChangedAt.IsZero() {
+		t.Fatalf("expected the void recorded with actor and reason, got %+v, %v", changes, err)
+	}
+
+	q, err := svc.Create(quotes.CreateInput{CustomerID: "cust", LineItems: []quotes.CreateLineItem{{Description: "Oil change", Quantity: 1, UnitPrice: 7999}}})
+	if err != nil {
+		t.Fatalf("create quote failed: %v", err)
+	}
+	_, err = svc.UpdateStatus(q.ID, quotes.StatusInput{Status: quotes.StatusConverted})
+	var terr *quotes.TransitionError
+	if !errors.As(err, &terr) || terr.From != quotes.StatusDraft || terr.To != quotes.StatusConverted {
+		t.Fatalf("expected a TransitionError converting a draft, got %v", err)
+	}
+	for _, status := range []quotes.Status{quotes.StatusSent, quotes.StatusExpired, quotes.StatusDraft, quotes.StatusSent, quotes.StatusAccepted, quotes.StatusConverted} {
+		if _, err := svc.UpdateStatus(q.ID, quotes.StatusInput{Status: status}); err != nil {
+			t.Fatalf("%s: %v", status, err)
+		}
+	}
+	if _, err := svc.UpdateStatus(q.ID, quotes.StatusInput{Status: quotes.StatusDraft}); !errors.Is(err, quotes.ErrInvalidTransition) {
+		t.Fatalf("expected converted quotes to be final, got %v", err)
+	}
+}
+
+// racingRepo moves each quote it finds to racingRepo.to before returning
+// it unchanged, as if another request got there first.
+type racingRepo struct {
+	*memory.QuoteRepository
+	to quotes.Status
+}
+
+func (r racingRepo) FindByID(id string) (quotes.Quote, error) {
+	q, err := r.QuoteRepository.FindByID(id)
+	if err == nil {
+		moved := q
+		moved.Status = r.to
+		_, err = r.QuoteRepository.Save(moved)
+	}
+	return q, err
+}
+
+func TestQuoteServiceStatusRace(t *testing.T) {
+	repo := memory.NewQuoteRepository()
+	q, err := quotes.NewService(repo).Create(quotes.CreateInput{CustomerID: "cust", LineItems: []quotes.CreateLineItem{{Description: "Oil change", Quantity: 1, UnitPrice: 7999}}})
+	if err != nil {
+		t.Fatalf("create quote failed: %v", err)
+	}
+
+	svc := quotes.NewService(racingRepo{repo, quotes.StatusVoid}, quotes.WithHistory(repo.History()))
+	notes := "Sent twice"
+	_, err = svc.Update(q.ID, quotes.UpdateInput{TechnicianNotes: &notes, Status: &quotes.StatusInput{Status: quotes.StatusSent}})
+	var terr *quotes.TransitionError
+	if !errors.As(err, &terr) || !errors.Is(err, quotes.ErrStatusChanged) || terr.From != quotes.StatusDraft {
+		t.Fatalf("expected ErrStatusChanged, got %v", err)
+	}
+	got, _ := repo.FindByID(q.ID)
+	changes, _ := repo.History().ListByQuote(q.ID)
+	if got.Status != quotes.StatusVoid || got.TechnicianNotes != "" || len(changes) != 0 {
+		t.Fatalf("expected the losing change to save nothing, got %+v and %+v", got, changes)
+	}
+}
+
+func TestQuoteServiceEditRace(t *testing.T) {
+	repo := memory.NewQuoteRepository()
+	q, err := quotes.NewService(repo).Create(quotes.CreateInput{CustomerID: "cust", LineItems: []quotes.CreateLineItem{{Description: "Oil change", Quantity: 1, UnitPrice: 7999}}})
+	if err != nil {
+		t.Fatalf("create quote failed: %v", err)
+	}
+
+	svc := quotes.NewService(racingRepo{repo, quotes.StatusSent})
+	notes := "Rear pads worn"
+	if _, err := svc.Update(q.ID, quotes.UpdateInput{TechnicianNotes: &notes}); !errors.Is(err, quotes.ErrStatusChanged) {
+		t.Fatalf("expected ErrStatusChanged, got %v", err)
+	}
+	got, _ := repo.FindByID(q.ID)
+	if got.Status != quotes.StatusSent || got.TechnicianNotes != "" {
+		t.Fatalf("expected the edit to leave the sent quote alone, got %+v", got)
+	}
+}
+
+func TestQuoteServiceTechnicianNotes(t *testing.T) {
+	repo := memory.NewQuoteRepository()
+	svc := quotes.NewService(repo)
+
+	q, err := svc.Create(quotes.CreateInput{CustomerID: "cust", VehicleID: "veh", TechnicianNotes: "  Check rear pads next visit "})
+	if err != nil {
+		t.Fatalf("create quote failed: %v", err)
+	}
+	if q.TechnicianNotes != "Check rear pads next visit" {
+		t.Fatalf("expected trimmed notes, got %q", q.TechnicianNotes)
+	}
+
+	notes := "Rear pads at 3mm"
+	updated, err := svc.Update(q.ID, quotes.UpdateInput{TechnicianNotes: &notes})
+	if err != nil {
+		t.Fatalf("set notes failed: %v", err)
+	}
+	if updated.TechnicianNotes != "Rear pads at 3mm" || updated.Status != quotes.StatusDraft {
+		t.Fatalf("unexpected quote %+v", updated)
+	}
+
+	list, err := svc.ListForVehicle("veh")
+	if err != nil || len(list) != 1 || list[0].TechnicianNotes != "Rear pads at 3mm" {
+		t.Fatalf("list for vehicle: %+v, %v", list, err)
+	}
+}
+
+func TestQuoteServiceFleetApproval(t *testing.T) {
+	repo := memory.NewQuoteRepository()
+	account := quotes.Account{
+		PORequired: true,
+		Contacts:   map[string]bool{"manager": true, "driver": false},
+	}
+	svc := quotes.NewService(repo, quotes.WithAccounts(func(customerID string) (quotes.Account, error) {
+		if customerID == "fleet" {
+			return account, nil
+		}
+		return quotes.Account{}, nil
+	}))
+
+	if _, err := svc.Create(quotes.CreateInput{CustomerID: "fleet", ContactID: "stranger"}); !errors.Is(err, quotes.ErrInvalid) {
+		t.Fatalf("expected ErrInvalid for a contact of another account, got %v", err)
+	}
+	q, err := svc.Create(quotes.CreateInput{CustomerID: "fleet", ContactID: "driver", LineItems: []quotes.CreateLineItem{{Description: "Oil change", Quantity: 1, UnitPrice: 7999}}})
+	if err != nil {
+		t.Fatalf("create quote failed: %v", err)
+	}
+	if _, err := svc.UpdateStatus(q.ID, quotes.StatusInput{Status: quotes.StatusSent}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	if _, err := svc.UpdateStatus(q.ID, quotes.StatusInput{Status: quotes.StatusAccepted}); !errors.Is(err, quotes.ErrPORequired) {
+		t.Fatalf("expected ErrPORequired, got %v", err)
+	}
+	// A refused move saves none of the edits made with it.
+	notes, manager := "Fleet rate", "manager"
+	_, err = svc.Update(q.ID, quotes.UpdateInput{TechnicianNotes: &notes, ContactID: &manager, Status: &quotes.StatusInput{Status: quotes.StatusAccepted}})
+	if !errors.Is(err, quotes.ErrPORequired) {
+		t.Fatalf("expected ErrPORequired, got %v", err)
+	}
+	if got, _ := svc.Get(q.ID); got.TechnicianNotes != "" || got.ContactID != "driver" || got.Status != quotes.StatusSent {
+		t.Fatalf("expected the refused update to save nothing, got %+v", got)
+	}
+	po := " PO-1042 "
+	if q, err = svc.Update(q.ID, quotes.UpdateInput{PONumber: &po}); err != nil || q.PONumber != "PO-1042" {
+		t.Fatalf("set po number: %+v, %v", q, err)
+	}
+	if _, err := svc.UpdateStatus(q.ID, quotes.StatusInput{Status: quotes.StatusAccepted}); !errors.Is(err, quotes.ErrApproverRequired) {
+		t.Fatalf("expected ErrApproverRequired for a driver, got %v", err)
+	}
+	if _, err := svc.Update(q.ID, quotes.UpdateInput{ContactID: &manager}); err != nil {
+		t.Fatalf("set contact: %v", err)
+	}
+	if q, err = svc.UpdateStatus(q.ID, quotes.StatusInput{Status: quotes.StatusAccepted}); err != nil || q.Status != quotes.StatusAccepted {
+		t.Fatalf("accept: %+v, %v", q, err)
+	}
+
+	// The purchase order and contact are fixed once the quote is accepted.
+	other := "PO-2000"
+	if _, err := svc.Update(q.ID, quotes.UpdateInput{PONumber: &other}); !errors.Is(err, quotes.ErrLocked) {
+		t.Fatalf("expected ErrLocked changing the po number, got %v", err)
+	}
+	if _, err := svc.Update(q.ID, quotes.UpdateInput{ContactID: new(string)}); !errors.Is(err, quotes.ErrLocked) {
+		t.Fatalf("expected ErrLocked clearing the contact, got %v", err)
+	}
+	if q, err = svc.Update(q.ID, quotes.UpdateInput{PONumber: &po, TechnicianNotes: &notes}); err != nil || q.TechnicianNotes != notes {
+		t.Fatalf("edit notes: %+v, %v", q, err)
+	}
+	// Edits to an accepted quote check that the account still approves it.
+	account.Contacts["manager"] = false
+	account.Contacts["owner"] = true
+	if _, err := svc.Update(q.ID, quotes.UpdateInput{TechnicianNotes: &notes}); !errors.Is(err, quotes.ErrApproverRequired) {
+		t.Fatalf("expected ErrApproverRequired, got %v", err)
+	}
+
+	// Individuals accept without either.
+	individual, err := svc.Create(quotes.CreateInput{CustomerID: "cust", LineItems: []quotes.CreateLineItem{{Description: "Oil change", Quantity: 1, UnitPrice: 7999}}})
+	if err != nil {
+		t.Fatalf("create quote failed: %v", err)
+	}
+	for _, status := range []quotes.Status{quotes.StatusSent, quotes.StatusAccepted, quotes.StatusConverted} {
+		if _, err := svc.UpdateStatus(individual.ID, quotes.StatusInput{Status: status}); err != nil {
+			t.Fatalf("%s: %v", status, err)
+		}
+	}
+}
+
+func TestQuoteServiceLockRace(t *testing.T) {
+	repo := memory.NewQuoteRepository()
+	svc := quotes.NewService(repo)
+	q, err := svc.Create(quotes.CreateInput{CustomerID: "cust", PONumber: "PO-1", LineItems: []quotes.CreateLineItem{{Description: "Oil change", Quantity: 1, UnitPrice: 7999}}})
+	if err != nil {
+		t.Fatalf("create quote failed: %v", err)
+	}
+	if _, err := svc.UpdateStatus(q.ID, quotes.StatusInput{Status: quotes.StatusSent}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	// The quote is accepted between reading it and saving the new number.
+	racing := quotes.NewService(racingRepo{repo, quotes.StatusAccepted})
+	po := "PO-2"
+	if _, err := racing.Update(q.ID, quotes.UpdateInput{PONumber: &po}); !errors.Is(err, quotes.ErrStatusChanged) {
+		t.Fatalf("expected ErrStatusChanged, got %v", err)
+	}
+	if got, _ := repo.FindByID(q.ID); got.Status != quotes.StatusAccepted || got.PONumber != "PO-1" {
+		t.Fatalf("expected the accepted quote to keep its po number, got %+v", got)
+	}
+}
+
+func TestQuoteServiceFillsQuantities(t *testing.T) {
+	repo := memory.NewQuoteRepository()
+	svc := quotes.NewService(repo, quotes.WithQuantities(func(vehicleID, description string) (int, error) {
+		if vehicleID == "veh-1" && description == "Oil change" {
+			return 5, nil
+		}
+		return 0, nil
+	}))
+
+	q, err := svc.Create(quotes.CreateInput{
+		CustomerID: "cust-1",
+		VehicleID:  "veh-1",
+		LineItems: []quotes.CreateLineItem{
+			{Description: "Oil change", UnitPrice: 900},
+			{Description: "Oil filter", UnitPrice: 1200},
+			{Description: "Oil change", Quantity: 6, UnitPrice: 900},
+		},
+	})
+	if err != nil {
+		t.Fatalf("create quote failed: %v", err)
+	}
+	for i, want := range []int{5, 1, 6} {
+		if got := q.LineItems[i].Quantity; got != want {
+			t.Errorf("line %d: expected quantity %d, got %d", i, want, got)
+		}
+	}
+	if want := int64(5*900 + 1200 + 6*900); q.TotalAmount != want {
+		t.Fatalf("expected total %d, got %d", want, q.TotalAmount)
+	}
+
+	// Without a vehicle there are no specs to go on.
+	q, err = svc.Create(quotes.CreateInput{CustomerID: "cust-1", LineItems: []quotes.CreateLineItem{{Description: "Oil change"}}})
+	if err != nil || q.LineItems[0].Quantity != 1 {
+		t.Fatalf("expected quantity 1 without a vehicle, got %+v, %v", q.LineItems, err)
+	}
+}
+
+func TestQuoteServicePricesLabor(t *testing.T) {
+	repo := memory.NewQuoteRepository()
+	svc := quotes.NewService(repo, quotes.WithRates(pricing.RateCard{
+		DefaultRate: 12000,
+		Rates:       []pricing.Rate{{Category: "diagnostics", Hourly: 15000}, {Tier: "master", Hourly: 14000}},
+		Fees:        []pricing.Fee{{Name: "Trip charge", Amount: 4900}},
+		TaxRate:     7.5,
+	}))
+
+	q, err := svc.Create(quotes.CreateInput{
+		CustomerID:     "cust",
+		TechnicianTier: " master ",
+		Discount:       1000,
+		LineItems: []quotes.CreateLineItem{
+			{Description: "Brake Pads", Quantity: 1, UnitPrice: 8999, LaborHours: 1.5, Category: "brakes"},
+			{Description: "Check engine light", LaborHours: 0.5, Category: "diagnostics"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("create quote failed: %v", err)
+	}
+	if q.TechnicianTier != "master" {
+		t.Fatalf("expected trimmed tier, got %q", q.TechnicianTier)
+	}
+	if l := q.LineItems[0]; l.Category != "brakes" || l.LaborRate != 14000 || l.LaborAmount != 21000 {
+		t.Fatalf("unexpected line %+v", l)
+	}
+	if l := q.LineItems[1]; l.LaborRate != 15000 || l.LaborAmount != 7500 {
+		t.Fatalf("unexpected line %+v", l)
+	}
+	// 7.5% tax on the parts, less their 212 share of the discount.
+	if q.PartsAmount != 8999 || q.LaborAmount != 28500 || q.FeesAmount != 4900 || q.DiscountAmount != 1000 || q.TaxAmount != 659 {
+		t.Fatalf("unexpected breakdown %+v", q)
+	}
+	if want := q.PartsAmount + q.LaborAmount + q.FeesAmount - q.DiscountAmount + q.TaxAmount; q.TotalAmount != want {
+		t.Fatalf("expected total %d, got %d", want, q.TotalAmount)
+	}
+
+	if _, err := svc.Create(quotes.CreateInput{CustomerID: "cust", Discount: -1}); !errors.Is(err, quotes.ErrInvalid) {
+		t.Fatalf("expected ErrInvalid for a negative discount, got %v", err)
+	}
+	if _, err := svc.Create(quotes.CreateInput{CustomerID: "cust", LineItems: []quotes.CreateLineItem{{Description: "Oil change", LaborHours: -1}}}); !errors.Is(err, quotes.ErrInvalid) {
+		t.Fatalf("expected ErrInvalid for negative labor hours, got %v", err)
+	}
+}
+
 func TestQuoteServiceListByCustomer(t *testing.T) {
 	repo := memory.NewQuoteRepository()
 	svc := quotes.NewService(repo)
 
 	const customerID = "cust"
+	var created []quotes.Quote
 	for i := 0; i < 3; i++ {
+		q, err := svc.Create(quotes.CreateInput{CustomerID: customerID})
+		if err != nil {
+			t.Fatalf("create failed: %v", err)
+		}
+		created = append(created, q)
+	}
+
+	list, err := svc.ListForCustomer(customerID, listing.Spec{Limit: 2})
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(list.Items) != 2 {
+		t.Fatalf("expected 2 quotes, got %d", len(list.Items))
+	}
+	if list.Items[0].ID != created[2].ID {
+		t.Fatalf("expected newest quote first")
+	}
+}
+
+func TestQuoteServiceListByCustomerStatusFilter(t *testing.T) {
+	repo := memory.NewQuoteRepository()
+	svc := quotes.NewService(repo)
+
+	const customerID = "cust"
+	for i := 0; i < 2; i++ {
 		if _, err := svc.Create(quotes.CreateInput{CustomerID: customerID}); err != nil {
 			t.Fatalf("create failed: %v", err)
 		}
 	}
+	q, err := svc.Create(quotes.CreateInput{CustomerID: customerID, LineItems: []quotes.CreateLineItem{{Description: "Oil change", Quantity: 1, UnitPrice: 7999}}})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	for _, status := range []quotes.Status{quotes.StatusSent, quotes.StatusAccepted} {
+		if _, err := svc.UpdateStatus(q.ID, quotes.StatusInput{Status: status}); err != nil {
+			t.Fatalf("update status failed: %v", err)
+		}
+	}
 
-	list, err := svc.ListForCustomer(customerID, 0, 2)
+	list, err := svc.ListForCustomer(customerID, listing.Spec{Status: string(quotes.StatusAccepted)})
 	if err != nil {
 		t.Fatalf("list failed: %v", err)
 	}
-	if len(list) != 2 {
-		t.Fatalf("expected 2 quotes, got %d", len(list))
+	if len(list.Items) != 1 || list.Items[0].ID != q.ID {
+		t.Fatalf("expected only the accepted quote, got %d", len(list.Items))
 	}
 }
@@ -0,0 +1,124 @@
+package quotes
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Transition errors. Each is returned wrapped in a *TransitionError, so
+// errors.Is matches both it and ErrInvalidTransition.
+var (
+	// ErrInvalidTransition is returned for a move between statuses that the
+	// state machine does not allow, and matches every *TransitionError.
+	ErrInvalidTransition = errors.New("invalid status transition")
+	// ErrNoLineItems is returned when sending or accepting an empty quote.
+	ErrNoLineItems = errors.New("quote has no line items")
+	// ErrReasonRequired is returned when voiding a quote without saying why.
+	ErrReasonRequired = errors.New("reason required")
+	// ErrStatusChanged is returned when another change moved the quote on
+	// while this one was being made.
+	ErrStatusChanged = errors.New("quote status changed")
+)
+
+// TransitionError reports a status change that was refused. Err is
+// ErrInvalidTransition for a move the state machine does not allow, or the
+// guard that failed, such as ErrNoLineItems or ErrPORequired.
+type TransitionError struct {
+	From Status
+	To   Status
+	Err  error
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot move quote from %s to %s: %v", e.From, e.To, e.Err)
+}
+
+func (e *TransitionError) Unwrap() error { return e.Err }
+
+// Is makes every TransitionError match ErrInvalidTransition.
+func (e *TransitionError) Is(target error) bool { return target == ErrInvalidTransition }
+
+// transitions lists the statuses each status may move to. Converted and
+// void quotes are final; a declined or expired quote can be reopened as a
+// draft, revised and sent again.
+var transitions = map[Status][]Status{
+	StatusDraft:     {StatusSent, StatusVoid},
+	StatusSent:      {StatusAccepted, StatusDeclined, StatusExpired, StatusDraft, StatusVoid},
+	StatusAccepted:  {StatusConverted, StatusVoid},
+	StatusDeclined:  {StatusDraft, StatusVoid},
+	StatusExpired:   {StatusDraft, StatusVoid},
+	StatusConverted: nil,
+	StatusVoid:      nil,
+}
+
+// Statuses lists every status, in the order a quote usually passes through
+// them.
+var Statuses = []Status{StatusDraft, StatusSent, StatusAccepted, StatusDeclined, StatusExpired, StatusConverted, StatusVoid}
+
+// Valid reports whether s is a known status.
+func (s Status) Valid() bool {
+	_, ok := transitions[s]
+	return ok
+}
+
+// CanMoveTo reports whether the state machine allows a quote to move from s
+// to to. It does not run the guards that depend on the quote.
+func (s Status) CanMoveTo(to Status) bool {
+	for _, next := range transitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Next returns the statuses s may move to.
+func (s Status) Next() []Status {
+	return append([]Status{}, transitions[s]...)
+}
+
+// approved reports whether a quote with status s has been approved or voided,
+// fixing its purchase order number and contact.
+func (s Status) approved() bool {
+	return s == StatusAccepted || s == StatusConverted || s == StatusVoid
+}
+
+// StatusInput moves a quote to a new status.
+type StatusInput struct {
+	Status Status
+	// Actor is the staff member making the change.
+	Actor string
+	// Reason says why, and is required to void a quote.
+	Reason string
+}
+
+// checkTransition returns a *TransitionError unless quote may move to
+// input.Status.
+func (s *service) checkTransition(quote Quote, input StatusInput) error {
+	refuse := func(err error) error {
+		return &TransitionError{From: quote.Status, To: input.Status, Err: err}
+	}
+	if !quote.Status.CanMoveTo(input.Status) {
+		return refuse(ErrInvalidTransition)
+	}
+	switch input.Status {
+	case StatusSent, StatusAccepted:
+		if len(quote.LineItems) == 0 {
+			return refuse(ErrNoLineItems)
+		}
+	case StatusVoid:
+		if strings.TrimSpace(input.Reason) == "" {
+			return refuse(ErrReasonRequired)
+		}
+	}
+	if input.Status == StatusAccepted || input.Status == StatusConverted {
+		if err := s.checkApproval(quote); err != nil {
+			if errors.Is(err, ErrPORequired) || errors.Is(err, ErrApproverRequired) {
+				return refuse(err)
+			}
+			return err
+		}
+	}
+	return nil
+}
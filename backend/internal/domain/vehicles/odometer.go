@@ -0,0 +1,374 @@
+package vehicles
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Odometer errors.
+var (
+	ErrReadingNotFound = errors.New("odometer reading not found")
+	// ErrMileageBackwards is returned for a reading lower than an earlier one
+	// or higher than a later one. Record it with ReadingInput.Flag to keep it
+	// for review without trusting it.
+	ErrMileageBackwards = errors.New("odometer reading goes backwards")
+)
+
+// ReadingSource says where an odometer reading came from.
+type ReadingSource string
+
+const (
+	SourceIntake     ReadingSource = "intake"
+	SourceTechnician ReadingSource = "technician"
+	SourceInvoice    ReadingSource = "invoice"
+	// SourceTransfer is the reading given when a vehicle changes owner.
+	SourceTransfer ReadingSource = "transfer"
+	// SourceManual is a mileage typed in by staff when editing the vehicle.
+	SourceManual ReadingSource = "manual"
+)
+
+// ReadingSources lists every source, in display order.
+var ReadingSources = []ReadingSource{SourceIntake, SourceTechnician, SourceInvoice, SourceTransfer, SourceManual}
+
+// Reading is an odometer reading. Readings are not edited; a wrong one is
+// flagged, which leaves it out of the vehicle's mileage and estimates.
+type Reading struct {
+	ID        string
+	VehicleID string
+	Mileage   int
+	Source    ReadingSource
+	// ReadAt is when the odometer showed Mileage, which for invoices and
+	// imported records may be well before RecordedAt.
+	ReadAt     time.Time
+	Note       string
+	Flagged    bool
+	RecordedAt time.Time
+}
+
+// OdometerRepository persists odometer readings. ListByVehicle returns a
+// vehicle's readings ordered by ReadAt, then RecordedAt. SetFlagged returns
+// ErrReadingNotFound for unknown IDs. Readings are removed with their
+// vehicle.
+type OdometerRepository interface {
+	ListByVehicle(vehicleID string) ([]Reading, error)
+	Add(reading Reading) (Reading, error)
+	SetFlagged(id string, flagged bool) (Reading, error)
+}
+
+// NullOdometerRepository stub implementation returning ErrNotImplemented.
+type NullOdometerRepository struct{}
+
+func (NullOdometerRepository) ListByVehicle(vehicleID string) ([]Reading, error) {
+	return nil, ErrNotImplemented
+}
+
+func (NullOdometerRepository) Add(reading Reading) (Reading, error) {
+	return Reading{}, ErrNotImplemented
+}
+
+func (NullOdometerRepository) SetFlagged(id string, flagged bool) (Reading, error) {
+	return Reading{}, ErrNotImplemented
+}
+
+// ReadingInput defines an odometer reading to record. ReadAt defaults to
+// now.
+type ReadingInput struct {
+	Mileage int
+	Source  ReadingSource
+	ReadAt  time.Time
+	Note    string
+	// Flag records a reading that goes backwards instead of rejecting it.
+	// The reading is kept flagged and does not change the vehicle's mileage.
+	Flag bool
+}
+
+// Estimate is a vehicle's projected mileage on a date.
+type Estimate struct {
+	At      time.Time
+	Mileage int
+	// MilesPerDay is the rate projected forward from the last reading.
+	MilesPerDay float64
+	// DefaultRate is set when the readings span too short a time to give a
+	// rate and DefaultMilesPerYear was used instead.
+	DefaultRate bool
+	// LastMileage and LastReadAt are the latest trusted reading. LastReadAt
+	// is zero when the vehicle has none.
+	LastMileage int
+	LastReadAt  time.Time
+}
+
+// Estimation parameters.
+const (
+	// DefaultMilesPerYear is roughly the US average and is assumed for
+	// vehicles without enough history.
+	DefaultMilesPerYear = 13500
+	// RateWindow bounds how far back readings count towards the rate, so
+	// that a change of driver or use shows up within a couple of years.
+	RateWindow = 2 * 365 * 24 * time.Hour
+	// MinRateSpan is the shortest span of readings trusted for a rate.
+	MinRateSpan = 30 * 24 * time.Hour
+)
+
+// WithOdometer sets the repository for odometer readings.
+func WithOdometer(repo OdometerRepository) Option {
+	return func(s *service) { s.odometer = repo }
+}
+
+func (s *service) Readings(id string) ([]Reading, error) {
+	if _, err := s.repo.FindByID(id); err != nil {
+		return nil, err
+	}
+	return s.odometer.ListByVehicle(id)
+}
+
+func (s *service) RecordReading(id string, input ReadingInput) (Reading, error) {
+	vehicle, err := s.active(id)
+	if err != nil {
+		return Reading{}, err
+	}
+	reading, _, err := s.recordReading(vehicle, input)
+	return reading, err
+}
+
+// recordReading checks and stores a reading for vehicle, then brings the
+// vehicle's mileage in line with its readings. It returns the vehicle as
+// saved.
+func (s *service) recordReading(vehicle Vehicle, input ReadingInput) (Reading, Vehicle, error) {
+	add, readings, err := s.checkNewReading(vehicle, input)
+	if err != nil {
+		return Reading{}, Vehicle{}, err
+	}
+	readings, err = s.addReadings(readings, add)
+	if err != nil {
+		return Reading{}, Vehicle{}, err
+	}
+	vehicle, err = s.syncMileage(vehicle, readings)
+	return readings[len(readings)-1], vehicle, err
+}
+
+// checkNewReading builds the reading input describes for vehicle and checks
+// it against the vehicle's readings, storing nothing. It returns the
+// readings to add, the new one last, and those the vehicle already has.
+func (s *service) checkNewReading(vehicle Vehicle, input ReadingInput) ([]Reading, []Reading, error) {
+	reading := Reading{
+		VehicleID: vehicle.ID,
+		Mileage:   input.Mileage,
+		Source:    input.Source,
+		ReadAt:    input.ReadAt.UTC().Truncate(time.Microsecond),
+		Note:      strings.TrimSpace(input.Note),
+	}
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	if input.ReadAt.IsZero() {
+		reading.ReadAt = now
+	}
+	switch {
+	case !validSource(reading.Source):
+		return nil, nil, fmt.Errorf("%w: unknown reading source %q", ErrInvalid, reading.Source)
+	case reading.Mileage < 0:
+		return nil, nil, fmt.Errorf("%w: mileage must not be negative", ErrInvalid)
+	case reading.ReadAt.After(now.Add(time.Minute)):
+		return nil, nil, fmt.Errorf("%w: reading date is in the future", ErrInvalid)
+	}
+
+	readings, err := s.odometer.ListByVehicle(vehicle.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkReading(trusted(vehicle, readings), reading); err != nil {
+		if !input.Flag {
+			return nil, nil, err
+		}
+		reading.Flagged = true
+	}
+
+	var add []Reading
+	if len(readings) == 0 && vehicle.Mileage > 0 {
+		// Keep the mileage entered before readings were recorded, so a
+		// backdated first reading cannot lower it.
+		add = append(add, baselineReading(vehicle))
+	}
+	return append(add, reading), readings, nil
+}
+
+// addReadings stores add and returns readings with them appended.
+func (s *service) addReadings(readings, add []Reading) ([]Reading, error) {
+	for _, r := range add {
+		saved, err := s.odometer.Add(r)
+		if err != nil {
+			return nil, err
+		}
+		readings = append(readings, saved)
+	}
+	return readings, nil
+}
+
+// baselineReading is a reading of the mileage a vehicle had before its
+// readings were kept, as of its last update.
+func baselineReading(vehicle Vehicle) Reading {
+	return Reading{
+		VehicleID: vehicle.ID,
+		Mileage:   vehicle.Mileage,
+		Source:    SourceManual,
+		ReadAt:    vehicle.UpdatedAt,
+		Note:      "mileage on record before readings were kept",
+	}
+}
+
+func (s *service) FlagReading(id, readingID string, flagged bool) (Reading, error) {
+	vehicle, err := s.repo.FindByID(id)
+	if err != nil {
+		return Reading{}, err
+	}
+	readings, err := s.odometer.ListByVehicle(id)
+	if err != nil {
+		return Reading{}, err
+	}
+	i := indexOfReading(readings, readingID)
+	if i < 0 {
+		return Reading{}, ErrReadingNotFound
+	}
+	if readings[i].Flagged == flagged {
+		return readings[i], nil
+	}
+	if !flagged {
+		others := append(append([]Reading{}, readings[:i]...), readings[i+1:]...)
+		if err := checkReading(trusted(vehicle, others), readings[i]); err != nil {
+			return Reading{}, err
+		}
+	}
+
+	reading, err := s.odometer.SetFlagged(readingID, flagged)
+	if err != nil {
+		return Reading{}, err
+	}
+	readings[i] = reading
+	if _, err := s.syncMileage(vehicle, readings); err != nil {
+		return Reading{}, err
+	}
+	return reading, nil
+}
+
+func (s *service) EstimateMileage(id string, at time.Time) (Estimate, error) {
+	vehicle, err := s.repo.FindByID(id)
+	if err != nil {
+		return Estimate{}, err
+	}
+	readings, err := s.odometer.ListByVehicle(id)
+	if err != nil {
+		return Estimate{}, err
+	}
+	return estimate(trusted(vehicle, readings), at.UTC()), nil
+}
+
+// syncMileage sets the vehicle's mileage to its latest trusted reading,
+// saving it if that changes anything.
+func (s *service) syncMileage(vehicle Vehicle, readings []Reading) (Vehicle, error) {
+	latest, ok := latestReading(readings)
+	if !ok || latest.Mileage == vehicle.Mileage {
+		return vehicle, nil
+	}
+	vehicle.Mileage = latest.Mileage
+	return s.repo.Save(vehicle)
+}
+
+// trusted returns the unflagged readings in date order. A vehicle that has
+// a mileage but no readings, such as one created before readings were kept,
+// gets a reading of its mileage as of its last update.
+func trusted(vehicle Vehicle, readings []Reading) []Reading {
+	out := make([]Reading, 0, len(readings))
+	for _, r := range readings {
+		if !r.Flagged {
+			out = append(out, r)
+		}
+	}
+	if len(readings) == 0 && vehicle.Mileage > 0 {
+		out = append(out, Reading{VehicleID: vehicle.ID, Mileage: vehicle.Mileage, ReadAt: vehicle.UpdatedAt})
+	}
+	return out
+}
+
+// checkReading returns ErrMileageBackwards if r is below a trusted reading
+// taken at or before it, or above one taken after it.
+func checkReading(trusted []Reading, r Reading) error {
+	for _, t := range trusted {
+		switch {
+		case !t.ReadAt.After(r.ReadAt) && r.Mileage < t.Mileage:
+			return fmt.Errorf("%w: %d is below %d read on %s", ErrMileageBackwards, r.Mileage, t.Mileage, t.ReadAt.Format(time.DateOnly))
+		case t.ReadAt.After(r.ReadAt) && r.Mileage > t.Mileage:
+			return fmt.Errorf("%w: %d is above %d read later on %s", ErrMileageBackwards, r.Mileage, t.Mileage, t.ReadAt.Format(time.DateOnly))
+		}
+	}
+	return nil
+}
+
+// latestReading returns the unflagged reading with the latest ReadAt.
+func latestReading(readings []Reading) (Reading, bool) {
+	var (
+		latest Reading
+		found  bool
+	)
+	for _, r := range readings {
+		if !r.Flagged && (!found || !r.ReadAt.Before(latest.ReadAt)) {
+			latest, found = r, true
+		}
+	}
+	return latest, found
+}
+
+// estimate projects the mileage at from trusted readings in date order. The
+// rate is the mileage covered between the latest reading and the earliest
+// one within RateWindow of it. Dates before the latest reading get the
+// latest reading taken by then.
+func estimate(trusted []Reading, at time.Time) Estimate {
+	e := Estimate{At: at, MilesPerDay: DefaultMilesPerYear / 365.0, DefaultRate: true}
+	if len(trusted) == 0 {
+		return e
+	}
+	last := trusted[len(trusted)-1]
+	e.LastMileage, e.LastReadAt = last.Mileage, last.ReadAt
+
+	for _, r := range trusted {
+		if last.ReadAt.Sub(r.ReadAt) > RateWindow {
+			continue
+		}
+		if span := last.ReadAt.Sub(r.ReadAt); span >= MinRateSpan {
+			e.MilesPerDay = float64(last.Mileage-r.Mileage) / (span.Hours() / 24)
+			e.DefaultRate = false
+		}
+		break
+	}
+
+	if !at.After(last.ReadAt) {
+		e.Mileage = trusted[0].Mileage
+		for _, r := range trusted {
+			if !r.ReadAt.After(at) {
+				e.Mileage = r.Mileage
+			}
+		}
+		return e
+	}
+	days := at.Sub(last.ReadAt).Hours() / 24
+	e.Mileage = last.Mileage + int(math.Round(e.MilesPerDay*days))
+	return e
+}
+
+func indexOfReading(readings []Reading, id string) int {
+	for i, r := range readings {
+		if r.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func validSource(s ReadingSource) bool {
+	for _, v := range ReadingSources {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
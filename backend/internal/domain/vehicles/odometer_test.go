@@ -0,0 +1,200 @@
+package vehicles_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+	"github.com/ezmobilemechanic/platform/internal/storage/memory"
+)
+
+func newOdometerService(t *testing.T) (vehicles.Service, *memory.VehicleRepository) {
+	t.Helper()
+	repo := memory.NewVehicleRepository()
+	return vehicles.NewService(repo, vehicles.WithOdometer(repo.Odometer())), repo
+}
+
+func TestServiceRecordReading(t *testing.T) {
+	svc, _ := newOdometerService(t)
+	v, err := svc.Create(vehicles.CreateInput{CustomerID: "c1", Make: "Ford", Mileage: 40000})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	now := time.Now().UTC()
+
+	if _, err := svc.RecordReading(v.ID, vehicles.ReadingInput{Mileage: 41000, Source: vehicles.SourceTechnician}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if got, _ := svc.Get(v.ID); got.Mileage != 41000 {
+		t.Fatalf("expected mileage 41000, got %d", got.Mileage)
+	}
+
+	// Backdated between intake and the technician's reading: fine, and the
+	// vehicle keeps its latest mileage.
+	readings, err := svc.Readings(v.ID)
+	if err != nil || len(readings) != 2 {
+		t.Fatalf("readings: %+v, %v", readings, err)
+	}
+	between := readings[0].ReadAt.Add(readings[1].ReadAt.Sub(readings[0].ReadAt) / 2)
+	if _, err := svc.RecordReading(v.ID, vehicles.ReadingInput{Mileage: 40500, Source: vehicles.SourceInvoice, ReadAt: between}); err != nil {
+		t.Fatalf("record backdated: %v", err)
+	}
+	if got, _ := svc.Get(v.ID); got.Mileage != 41000 {
+		t.Fatalf("expected mileage to stay 41000, got %d", got.Mileage)
+	}
+
+	if _, err := svc.RecordReading(v.ID, vehicles.ReadingInput{Mileage: 30000, Source: vehicles.SourceIntake}); !errors.Is(err, vehicles.ErrMileageBackwards) {
+		t.Fatalf("expected ErrMileageBackwards for a lower reading, got %v", err)
+	}
+	if _, err := svc.RecordReading(v.ID, vehicles.ReadingInput{Mileage: 45000, Source: vehicles.SourceInvoice, ReadAt: now.AddDate(0, 0, -1)}); !errors.Is(err, vehicles.ErrMileageBackwards) {
+		t.Fatalf("expected ErrMileageBackwards for a backdated reading above later ones, got %v", err)
+	}
+
+	flagged, err := svc.RecordReading(v.ID, vehicles.ReadingInput{Mileage: 30000, Source: vehicles.SourceIntake, Flag: true})
+	if err != nil {
+		t.Fatalf("record flagged: %v", err)
+	}
+	if !flagged.Flagged {
+		t.Fatalf("expected the backwards reading to be flagged, got %+v", flagged)
+	}
+	if got, _ := svc.Get(v.ID); got.Mileage != 41000 {
+		t.Fatalf("expected a flagged reading to leave mileage at 41000, got %d", got.Mileage)
+	}
+
+	readings, err = svc.Readings(v.ID)
+	if err != nil {
+		t.Fatalf("readings: %v", err)
+	}
+	var got []int
+	for _, r := range readings {
+		got = append(got, r.Mileage)
+	}
+	if len(got) != 4 || got[0] != 40000 || got[1] != 40500 || got[2] != 41000 || got[3] != 30000 {
+		t.Fatalf("expected readings in date order, got %v", got)
+	}
+
+	for _, in := range []vehicles.ReadingInput{
+		{Mileage: 50000, Source: "guess"},
+		{Mileage: -1, Source: vehicles.SourceIntake},
+		{Mileage: 50000, Source: vehicles.SourceIntake, ReadAt: now.AddDate(0, 0, 2)},
+	} {
+		if _, err := svc.RecordReading(v.ID, in); !errors.Is(err, vehicles.ErrInvalid) {
+			t.Fatalf("record %+v: expected ErrInvalid, got %v", in, err)
+		}
+	}
+}
+
+func TestServiceFlagReading(t *testing.T) {
+	svc, _ := newOdometerService(t)
+	v, _ := svc.Create(vehicles.CreateInput{CustomerID: "c1", Make: "Ford", Mileage: 40000})
+
+	typo, err := svc.RecordReading(v.ID, vehicles.ReadingInput{Mileage: 410000, Source: vehicles.SourceTechnician})
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if _, err := svc.RecordReading(v.ID, vehicles.ReadingInput{Mileage: 41000, Source: vehicles.SourceTechnician}); !errors.Is(err, vehicles.ErrMileageBackwards) {
+		t.Fatalf("expected the typo to block the real reading, got %v", err)
+	}
+
+	if _, err := svc.FlagReading(v.ID, typo.ID, true); err != nil {
+		t.Fatalf("flag: %v", err)
+	}
+	if got, _ := svc.Get(v.ID); got.Mileage != 40000 {
+		t.Fatalf("expected mileage back at 40000, got %d", got.Mileage)
+	}
+	if _, err := svc.RecordReading(v.ID, vehicles.ReadingInput{Mileage: 41000, Source: vehicles.SourceTechnician}); err != nil {
+		t.Fatalf("record after flagging: %v", err)
+	}
+
+	if _, err := svc.FlagReading(v.ID, typo.ID, false); !errors.Is(err, vehicles.ErrMileageBackwards) {
+		t.Fatalf("expected unflagging the typo to be refused, got %v", err)
+	}
+	if _, err := svc.FlagReading(v.ID, "missing", true); !errors.Is(err, vehicles.ErrReadingNotFound) {
+		t.Fatalf("expected ErrReadingNotFound, got %v", err)
+	}
+}
+
+func TestServiceUpdateMileageRecordsReading(t *testing.T) {
+	svc, _ := newOdometerService(t)
+	v, _ := svc.Create(vehicles.CreateInput{CustomerID: "c1", Make: "Ford", Mileage: 40000})
+
+	lower := 39000
+	if _, err := svc.Update(v.ID, vehicles.UpdateInput{Mileage: &lower}); !errors.Is(err, vehicles.ErrMileageBackwards) {
+		t.Fatalf("expected ErrMileageBackwards, got %v", err)
+	}
+	higher := 42000
+	if _, err := svc.Update(v.ID, vehicles.UpdateInput{Mileage: &higher}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	readings, _ := svc.Readings(v.ID)
+	if len(readings) != 2 || readings[0].Source != vehicles.SourceIntake || readings[1].Source != vehicles.SourceManual {
+		t.Fatalf("expected intake and manual readings, got %+v", readings)
+	}
+}
+
+func TestServiceReadingOnVehicleWithoutHistory(t *testing.T) {
+	svc, repo := newOdometerService(t)
+	// Saved straight to the repository, as vehicles were before readings.
+	v, err := repo.Save(vehicles.Vehicle{CustomerID: "c1", Make: "Ford", Mileage: 80000})
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	if _, err := svc.RecordReading(v.ID, vehicles.ReadingInput{Mileage: 70000, Source: vehicles.SourceInvoice, ReadAt: v.UpdatedAt.AddDate(-1, 0, 0)}); err != nil {
+		t.Fatalf("record backdated: %v", err)
+	}
+	if got, _ := svc.Get(v.ID); got.Mileage != 80000 {
+		t.Fatalf("expected a backdated reading to leave mileage at 80000, got %d", got.Mileage)
+	}
+	readings, _ := svc.Readings(v.ID)
+	if len(readings) != 2 || readings[1].Mileage != 80000 {
+		t.Fatalf("expected the earlier mileage kept as a reading, got %+v", readings)
+	}
+}
+
+func TestServiceEstimateMileage(t *testing.T) {
+	svc, repo := newOdometerService(t)
+	v, _ := svc.Create(vehicles.CreateInput{CustomerID: "c1", Make: "Ford"})
+
+	none, err := svc.EstimateMileage(v.ID, time.Now())
+	if err != nil {
+		t.Fatalf("estimate: %v", err)
+	}
+	if !none.DefaultRate || none.Mileage != 0 || !none.LastReadAt.IsZero() {
+		t.Fatalf("expected an empty estimate at the default rate, got %+v", none)
+	}
+
+	last := time.Now().UTC().AddDate(0, 0, -10)
+	for _, r := range []vehicles.Reading{
+		// Outside the rate window, so the faster early driving is ignored.
+		{Mileage: 1000, ReadAt: last.AddDate(-3, 0, 0)},
+		{Mileage: 30000, ReadAt: last.AddDate(0, 0, -100)},
+		{Mileage: 40000, ReadAt: last},
+	} {
+		if _, err := svc.RecordReading(v.ID, vehicles.ReadingInput{Mileage: r.Mileage, Source: vehicles.SourceInvoice, ReadAt: r.ReadAt}); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+
+	e, err := svc.EstimateMileage(v.ID, last.AddDate(0, 0, 50))
+	if err != nil {
+		t.Fatalf("estimate: %v", err)
+	}
+	if e.DefaultRate || e.MilesPerDay != 100 || e.Mileage != 45000 || e.LastMileage != 40000 {
+		t.Fatalf("expected 45000 at 100 miles a day, got %+v", e)
+	}
+
+	past, _ := svc.EstimateMileage(v.ID, last.AddDate(0, 0, -50))
+	if past.Mileage != 30000 {
+		t.Fatalf("expected the reading in force on a past date, got %+v", past)
+	}
+
+	// A single recent reading gives no rate of its own.
+	fresh, _ := repo.Save(vehicles.Vehicle{CustomerID: "c1", Make: "Honda", Mileage: 10000})
+	e, _ = svc.EstimateMileage(fresh.ID, fresh.UpdatedAt.AddDate(1, 0, 0))
+	if !e.DefaultRate || e.Mileage < 10000+vehicles.DefaultMilesPerYear-50 || e.Mileage > 10000+vehicles.DefaultMilesPerYear+50 {
+		t.Fatalf("expected a year at the default rate, got %+v", e)
+	}
+}
@@ -0,0 +1,118 @@
+package vehicles
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrRecallMarkNotFound is returned when a vehicle has no mark for a
+// bulletin.
+var ErrRecallMarkNotFound = errors.New("recall mark not found")
+
+// RecallStatus is what staff have done about a recall or service bulletin on
+// a vehicle.
+type RecallStatus string
+
+const (
+	// RecallInformed means the owner has been told. It lapses when the
+	// vehicle changes owner.
+	RecallInformed RecallStatus = "informed"
+	// RecallAddressed means the remedy has been carried out, by us or
+	// elsewhere.
+	RecallAddressed RecallStatus = "addressed"
+)
+
+// RecallMark records staff marking a recall or service bulletin on a
+// vehicle. A vehicle has at most one mark per bulletin; marking it again
+// replaces the mark.
+type RecallMark struct {
+	VehicleID string
+	// BulletinID is the NHTSA campaign number of a recall, or the NHTSA item
+	// number of a service bulletin.
+	BulletinID string
+	Status     RecallStatus
+	Note       string
+	// CustomerID is the vehicle's owner when it was marked.
+	CustomerID string
+	MarkedAt   time.Time
+}
+
+// Current reports whether the mark still applies to a vehicle owned by
+// customerID. Informing one owner does not inform the next.
+func (m RecallMark) Current(customerID string) bool {
+	return m.Status == RecallAddressed || m.CustomerID == customerID
+}
+
+// RecallRepository persists recall marks. ListByVehicle returns a vehicle's
+// marks oldest first. Save replaces the vehicle's mark for the bulletin, if
+// any. Delete returns ErrRecallMarkNotFound when there is none. Marks are
+// removed with their vehicle.
+type RecallRepository interface {
+	ListByVehicle(vehicleID string) ([]RecallMark, error)
+	Save(mark RecallMark) (RecallMark, error)
+	Delete(vehicleID, bulletinID string) error
+}
+
+// NullRecallRepository stub implementation returning ErrNotImplemented.
+type NullRecallRepository struct{}
+
+func (NullRecallRepository) ListByVehicle(vehicleID string) ([]RecallMark, error) {
+	return nil, ErrNotImplemented
+}
+
+func (NullRecallRepository) Save(mark RecallMark) (RecallMark, error) {
+	return RecallMark{}, ErrNotImplemented
+}
+
+func (NullRecallRepository) Delete(vehicleID, bulletinID string) error {
+	return ErrNotImplemented
+}
+
+// RecallMarkInput marks a bulletin on a vehicle.
+type RecallMarkInput struct {
+	BulletinID string
+	Status     RecallStatus
+	Note       string
+}
+
+// WithRecalls sets the repository for recall marks.
+func WithRecalls(repo RecallRepository) Option {
+	return func(s *service) { s.recalls = repo }
+}
+
+func (s *service) RecallMarks(id string) ([]RecallMark, error) {
+	if _, err := s.repo.FindByID(id); err != nil {
+		return nil, err
+	}
+	return s.recalls.ListByVehicle(id)
+}
+
+func (s *service) MarkRecall(id string, input RecallMarkInput) (RecallMark, error) {
+	vehicle, err := s.repo.FindByID(id)
+	if err != nil {
+		return RecallMark{}, err
+	}
+	mark := RecallMark{
+		VehicleID:  vehicle.ID,
+		BulletinID: strings.TrimSpace(input.BulletinID),
+		Status:     input.Status,
+		Note:       strings.TrimSpace(input.Note),
+		CustomerID: vehicle.CustomerID,
+	}
+	switch {
+	case mark.BulletinID == "":
+		return RecallMark{}, fmt.Errorf("%w: bulletin is required", ErrInvalid)
+	case mark.Status != RecallInformed && mark.Status != RecallAddressed:
+		return RecallMark{}, fmt.Errorf("%w: recall status must be %q or %q", ErrInvalid, RecallInformed, RecallAddressed)
+	}
+	return s.recalls.Save(mark)
+}
+
+func (s *service) UnmarkRecall(id, bulletinID string) error {
+	if _, err := s.repo.FindByID(id); err != nil {
+		return err
+	}
+	return s.recalls.Delete(id, strings.TrimSpace(bulletinID))
+}
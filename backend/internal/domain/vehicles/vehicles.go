@@ -2,12 +2,27 @@ package vehicles
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/vin"
 )
 
 var (
 	ErrNotImplemented = errors.New("vehicles repository: not implemented")
 	ErrNotFound       = errors.New("vehicle not found")
+	// ErrInvalidVIN is returned by Create for VINs that fail validation.
+	ErrInvalidVIN = vin.ErrInvalid
+	ErrInvalid    = errors.New("invalid vehicle")
+	// ErrArchived is returned when changing an archived vehicle.
+	ErrArchived = errors.New("vehicle is archived")
+	// ErrOwnerNotFound is returned when transferring a vehicle to a customer
+	// that does not exist.
+	ErrOwnerNotFound = errors.New("new owner not found")
+	// ErrUnitNumberExists is returned when another of the customer's active
+	// vehicles already has the unit number.
+	ErrUnitNumberExists = errors.New("unit number already in use")
 )
 
 // Vehicle describes a customer's vehicle.
@@ -20,16 +35,46 @@ type Vehicle struct {
 	Model      string
 	Trim       string
 	Engine     string
+	Color      string
+	Plate      string
+	// UnitNumber is the fleet's own number for the vehicle, unique among
+	// the owner's active vehicles regardless of case.
+	UnitNumber string
 	Mileage    int
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
+	// ArchivedAt is set once the vehicle is sold, scrapped or otherwise no
+	// longer serviced. Archived vehicles are left out of active lists.
+	ArchivedAt *time.Time
+}
+
+// Transfer records a vehicle changing owner. Quotes and other records made
+// for the previous owner stay with them; the new owner starts afresh.
+type Transfer struct {
+	ID             string
+	VehicleID      string
+	FromCustomerID string
+	ToCustomerID   string
+	// Mileage is the odometer reading at the handover, if known.
+	Mileage       int
+	Note          string
+	TransferredAt time.Time
 }
 
 // Repository abstracts persistence for vehicles.
+//
+// ListByCustomer includes archived vehicles. Transfer atomically moves the
+// vehicle t.VehicleID from t.FromCustomerID to t.ToCustomerID, clears its
+// unit number and records t along with the odometer readings, raising the
+// vehicle's mileage to t.Mileage if that is higher. It returns ErrNotFound
+// unless the vehicle exists and is still owned by t.FromCustomerID.
+// ListTransfers returns a vehicle's transfers, oldest first.
 type Repository interface {
 	FindByID(id string) (Vehicle, error)
 	ListByCustomer(customerID string) ([]Vehicle, error)
 	Save(vehicle Vehicle) (Vehicle, error)
+	Transfer(t Transfer, readings []Reading) (Transfer, error)
+	ListTransfers(vehicleID string) ([]Transfer, error)
 }
 
 // NullRepository stub implementation returning ErrNotImplemented.
@@ -47,11 +92,54 @@ func (NullRepository) Save(vehicle Vehicle) (Vehicle, error) {
 	return Vehicle{}, ErrNotImplemented
 }
 
+func (NullRepository) Transfer(t Transfer, readings []Reading) (Transfer, error) {
+	return Transfer{}, ErrNotImplemented
+}
+
+func (NullRepository) ListTransfers(vehicleID string) ([]Transfer, error) {
+	return nil, ErrNotImplemented
+}
+
 // Service defines operations for vehicle management.
 type Service interface {
 	Get(id string) (Vehicle, error)
-	ListForCustomer(customerID string) ([]Vehicle, error)
+	// ListForCustomer returns the customer's vehicles, oldest first. Archived
+	// vehicles are included only when includeArchived is set.
+	ListForCustomer(customerID string, includeArchived bool) ([]Vehicle, error)
 	Create(input CreateInput) (Vehicle, error)
+	// Update changes the fields set in input. It returns ErrArchived for
+	// archived vehicles.
+	Update(id string, input UpdateInput) (Vehicle, error)
+	// Transfer hands the vehicle to another customer and records the
+	// change of ownership.
+	Transfer(id string, input TransferInput) (Vehicle, error)
+	ListTransfers(id string) ([]Transfer, error)
+	Archive(id string) (Vehicle, error)
+	Unarchive(id string) (Vehicle, error)
+	// Readings returns the vehicle's odometer readings in date order.
+	Readings(id string) ([]Reading, error)
+	// RecordReading stores an odometer reading and updates the vehicle's
+	// mileage to its latest trusted reading. A reading that goes backwards
+	// returns ErrMileageBackwards unless input.Flag is set.
+	RecordReading(id string, input ReadingInput) (Reading, error)
+	// FlagReading marks a reading as wrong, or trusts it again, and updates
+	// the vehicle's mileage to match.
+	FlagReading(id, readingID string, flagged bool) (Reading, error)
+	// EstimateMileage projects the vehicle's mileage on a date from its
+	// readings.
+	EstimateMileage(id string, at time.Time) (Estimate, error)
+	// RecallMarks returns what staff have marked about recalls and service
+	// bulletins on the vehicle.
+	RecallMarks(id string) ([]RecallMark, error)
+	// MarkRecall marks a bulletin on the vehicle as informed or addressed,
+	// replacing any earlier mark. Whether the bulletin applies to the
+	// vehicle is for the caller to check.
+	MarkRecall(id string, input RecallMarkInput) (RecallMark, error)
+	// UnmarkRecall removes the vehicle's mark for a bulletin, reopening it.
+	UnmarkRecall(id, bulletinID string) error
+	// Anonymize clears the VIN and plate, which identify the owner through
+	// registration records. Year, make and model stay for service records.
+	Anonymize(id string) (Vehicle, error)
 }
 
 // CreateInput is used to create a new vehicle.
@@ -63,36 +151,313 @@ type CreateInput struct {
 	Model      string
 	Trim       string
 	Engine     string
+	Color      string
+	Plate      string
+	UnitNumber string
+	Mileage    int
+	// LegacyVIN accepts a VIN issued before the 17-character standard took
+	// effect for model year 1981, storing it unchecked. Year must be set and
+	// before 1981.
+	LegacyVIN bool
+}
+
+// UpdateInput carries the fields to change; nil fields are left as they are.
+type UpdateInput struct {
+	Mileage    *int
+	Trim       *string
+	Engine     *string
+	Color      *string
+	Plate      *string
+	UnitNumber *string
+}
+
+// TransferInput names the new owner of a vehicle.
+type TransferInput struct {
+	CustomerID string
 	Mileage    int
+	Note       string
+}
+
+// Option configures a vehicle service.
+type Option func(*service)
+
+// WithOwners makes Transfer look up the new owner with resolve, which
+// returns the ID of the customer to transfer to (following merges) or
+// ErrOwnerNotFound. Without it, customer IDs are taken as given.
+func WithOwners(resolve func(customerID string) (string, error)) Option {
+	return func(s *service) { s.resolveOwner = resolve }
 }
 
 // NewService creates a vehicle service.
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+func NewService(repo Repository, opts ...Option) Service {
+	s := &service{
+		repo:         repo,
+		odometer:     NullOdometerRepository{},
+		recalls:      NullRecallRepository{},
+		resolveOwner: func(id string) (string, error) { return id, nil },
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 type service struct {
-	repo Repository
+	repo         Repository
+	odometer     OdometerRepository
+	recalls      RecallRepository
+	resolveOwner func(customerID string) (string, error)
 }
 
 func (s *service) Get(id string) (Vehicle, error) {
 	return s.repo.FindByID(id)
 }
 
-func (s *service) ListForCustomer(customerID string) ([]Vehicle, error) {
-	return s.repo.ListByCustomer(customerID)
+func (s *service) ListForCustomer(customerID string, includeArchived bool) ([]Vehicle, error) {
+	list, err := s.repo.ListByCustomer(customerID)
+	if err != nil || includeArchived {
+		return list, err
+	}
+	active := list[:0]
+	for _, v := range list {
+		if v.ArchivedAt == nil {
+			active = append(active, v)
+		}
+	}
+	return active, nil
 }
 
+// Create validates the VIN, if given, and fills in the year and make from it
+// when they are missing.
 func (s *service) Create(input CreateInput) (Vehicle, error) {
+	number := vin.Normalize(input.VIN)
+	switch {
+	case number == "":
+	case input.LegacyVIN:
+		if input.Year <= 0 || input.Year >= 1981 {
+			return Vehicle{}, fmt.Errorf("%w: legacy VINs are only accepted for model years before 1981", ErrInvalidVIN)
+		}
+	default:
+		info, err := vin.Decode(number)
+		if err != nil {
+			return Vehicle{}, err
+		}
+		if input.Year == 0 {
+			input.Year = info.Year
+		}
+		if input.Make == "" {
+			input.Make = info.Make
+		}
+	}
+	if input.Mileage < 0 {
+		return Vehicle{}, fmt.Errorf("%w: mileage must not be negative", ErrInvalid)
+	}
+
 	vehicle := Vehicle{
 		CustomerID: input.CustomerID,
-		VIN:        input.VIN,
+		VIN:        number,
 		Year:       input.Year,
 		Make:       input.Make,
 		Model:      input.Model,
 		Trim:       input.Trim,
 		Engine:     input.Engine,
+		Color:      input.Color,
+		Plate:      normalizePlate(input.Plate),
+		UnitNumber: strings.TrimSpace(input.UnitNumber),
 		Mileage:    input.Mileage,
 	}
+	if err := s.checkUnitNumber(vehicle); err != nil {
+		return Vehicle{}, err
+	}
+	vehicle, err := s.repo.Save(vehicle)
+	if err != nil || vehicle.Mileage == 0 {
+		return vehicle, err
+	}
+	if _, err := s.odometer.Add(Reading{
+		VehicleID: vehicle.ID,
+		Mileage:   vehicle.Mileage,
+		Source:    SourceIntake,
+		ReadAt:    vehicle.CreatedAt,
+	}); err != nil {
+		return Vehicle{}, err
+	}
+	return vehicle, nil
+}
+
+func (s *service) Update(id string, input UpdateInput) (Vehicle, error) {
+	vehicle, err := s.active(id)
+	if err != nil {
+		return Vehicle{}, err
+	}
+
+	// Everything is checked before the reading is stored, so a refused
+	// update changes nothing.
+	var add, readings []Reading
+	if input.Mileage != nil {
+		if add, readings, err = s.checkNewReading(vehicle, ReadingInput{Mileage: *input.Mileage, Source: SourceManual}); err != nil {
+			return Vehicle{}, err
+		}
+	}
+	if input.Trim != nil {
+		vehicle.Trim = *input.Trim
+	}
+	if input.Engine != nil {
+		vehicle.Engine = *input.Engine
+	}
+	if input.Color != nil {
+		vehicle.Color = *input.Color
+	}
+	if input.Plate != nil {
+		vehicle.Plate = normalizePlate(*input.Plate)
+	}
+	if input.UnitNumber != nil {
+		vehicle.UnitNumber = strings.TrimSpace(*input.UnitNumber)
+		if err := s.checkUnitNumber(vehicle); err != nil {
+			return Vehicle{}, err
+		}
+	}
+
+	if len(add) > 0 {
+		if readings, err = s.addReadings(readings, add); err != nil {
+			return Vehicle{}, err
+		}
+		if latest, ok := latestReading(readings); ok {
+			vehicle.Mileage = latest.Mileage
+		}
+	}
 	return s.repo.Save(vehicle)
 }
+
+func (s *service) Transfer(id string, input TransferInput) (Vehicle, error) {
+	vehicle, err := s.active(id)
+	if err != nil {
+		return Vehicle{}, err
+	}
+	to := strings.TrimSpace(input.CustomerID)
+	if to == "" {
+		return Vehicle{}, fmt.Errorf("%w: new owner is required", ErrInvalid)
+	}
+	if input.Mileage < 0 {
+		return Vehicle{}, fmt.Errorf("%w: mileage must not be negative", ErrInvalid)
+	}
+	if to, err = s.resolveOwner(to); err != nil {
+		return Vehicle{}, err
+	}
+	if to == vehicle.CustomerID {
+		return Vehicle{}, fmt.Errorf("%w: vehicle already belongs to customer %s", ErrInvalid, to)
+	}
+	note := strings.TrimSpace(input.Note)
+
+	// The handover mileage is recorded as a reading with the transfer, so
+	// both are kept or neither is.
+	var add []Reading
+	if input.Mileage > 0 {
+		readings, err := s.odometer.ListByVehicle(id)
+		if err != nil {
+			return Vehicle{}, err
+		}
+		reading := Reading{
+			VehicleID: vehicle.ID,
+			Mileage:   input.Mileage,
+			Source:    SourceTransfer,
+			ReadAt:    time.Now().UTC().Truncate(time.Microsecond),
+			Note:      note,
+		}
+		if err := checkReading(trusted(vehicle, readings), reading); err != nil {
+			return Vehicle{}, err
+		}
+		if len(readings) == 0 && vehicle.Mileage > 0 {
+			add = append(add, baselineReading(vehicle))
+		}
+		add = append(add, reading)
+	}
+
+	// The repository also clears the unit number, which is the previous
+	// owner's fleet numbering.
+	if _, err := s.repo.Transfer(Transfer{
+		VehicleID:      vehicle.ID,
+		FromCustomerID: vehicle.CustomerID,
+		ToCustomerID:   to,
+		Mileage:        input.Mileage,
+		Note:           note,
+	}, add); err != nil {
+		return Vehicle{}, err
+	}
+	return s.repo.FindByID(id)
+}
+
+func (s *service) ListTransfers(id string) ([]Transfer, error) {
+	if _, err := s.repo.FindByID(id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListTransfers(id)
+}
+
+func (s *service) Archive(id string) (Vehicle, error) {
+	vehicle, err := s.repo.FindByID(id)
+	if err != nil || vehicle.ArchivedAt != nil {
+		return vehicle, err
+	}
+	// Microseconds, the precision the SQL backends store.
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	vehicle.ArchivedAt = &now
+	return s.repo.Save(vehicle)
+}
+
+func (s *service) Unarchive(id string) (Vehicle, error) {
+	vehicle, err := s.repo.FindByID(id)
+	if err != nil || vehicle.ArchivedAt == nil {
+		return vehicle, err
+	}
+	vehicle.ArchivedAt = nil
+	if err := s.checkUnitNumber(vehicle); err != nil {
+		return Vehicle{}, err
+	}
+	return s.repo.Save(vehicle)
+}
+
+func (s *service) Anonymize(id string) (Vehicle, error) {
+	vehicle, err := s.repo.FindByID(id)
+	if err != nil {
+		return Vehicle{}, err
+	}
+	vehicle.VIN = ""
+	vehicle.Plate = ""
+	return s.repo.Save(vehicle)
+}
+
+// active fetches a vehicle that is not archived.
+func (s *service) active(id string) (Vehicle, error) {
+	vehicle, err := s.repo.FindByID(id)
+	if err != nil {
+		return Vehicle{}, err
+	}
+	if vehicle.ArchivedAt != nil {
+		return Vehicle{}, ErrArchived
+	}
+	return vehicle, nil
+}
+
+// checkUnitNumber returns ErrUnitNumberExists if another active vehicle of
+// the owner has v's unit number.
+func (s *service) checkUnitNumber(v Vehicle) error {
+	if v.UnitNumber == "" {
+		return nil
+	}
+	list, err := s.repo.ListByCustomer(v.CustomerID)
+	if err != nil {
+		return err
+	}
+	for _, other := range list {
+		if other.ID != v.ID && other.ArchivedAt == nil && strings.EqualFold(other.UnitNumber, v.UnitNumber) {
+			return fmt.Errorf("%w: %s", ErrUnitNumberExists, v.UnitNumber)
+		}
+	}
+	return nil
+}
+
+// normalizePlate upper-cases a licence plate and collapses its spacing, so
+// "abc 1234" and "ABC  1234" are stored alike.
+func normalizePlate(plate string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(plate), " "))
+}
@@ -1,6 +1,7 @@
 package vehicles_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
@@ -13,7 +14,7 @@ func TestVehicleServiceCreateAndGet(t *testing.T) {
 
 	created, err := svc.Create(vehicles.CreateInput{
 		CustomerID: "cust-123",
-		VIN:        "VIN123",
+		VIN:        "1FTNE14W67DA12345",
 		Year:       2020,
 		Make:       "Ford",
 		Model:      "Transit",
@@ -49,7 +50,7 @@ func TestVehicleServiceListForCustomer(t *testing.T) {
 		t.Fatalf("create failed: %v", err)
 	}
 
-	list, err := svc.ListForCustomer(custA)
+	list, err := svc.ListForCustomer(custA, false)
 	if err != nil {
 		t.Fatalf("list failed: %v", err)
 	}
@@ -57,3 +58,194 @@ func TestVehicleServiceListForCustomer(t *testing.T) {
 		t.Fatalf("expected 2 vehicles for customer A, got %d", len(list))
 	}
 }
+
+func TestVehicleServiceCreateDecodesVIN(t *testing.T) {
+	repo := memory.NewVehicleRepository()
+	svc := vehicles.NewService(repo)
+
+	v, err := svc.Create(vehicles.CreateInput{CustomerID: "c1", VIN: " 1ftne14w67da12345 ", Model: "E-250"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if v.VIN != "1FTNE14W67DA12345" || v.Year != 2007 || v.Make != "Ford" {
+		t.Errorf("expected normalized VIN, year and make from the VIN, got %+v", v)
+	}
+
+	// Given values win over decoded ones.
+	v, err = svc.Create(vehicles.CreateInput{CustomerID: "c1", VIN: "1FTNE14W67DA12345", Year: 2008, Make: "FORD"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if v.Year != 2008 || v.Make != "FORD" {
+		t.Errorf("expected given year and make kept, got %+v", v)
+	}
+}
+
+func TestVehicleServiceCreateRejectsInvalidVIN(t *testing.T) {
+	repo := memory.NewVehicleRepository()
+	svc := vehicles.NewService(repo)
+
+	for _, input := range []vehicles.CreateInput{
+		{CustomerID: "c1", VIN: "1FTNE14W57DA12345"},
+		{CustomerID: "c1", VIN: "VIN123"},
+		{CustomerID: "c1", VIN: "F10GLS12345", LegacyVIN: true, Year: 1985},
+		{CustomerID: "c1", VIN: "F10GLS12345", LegacyVIN: true},
+	} {
+		if _, err := svc.Create(input); !errors.Is(err, vehicles.ErrInvalidVIN) {
+			t.Errorf("Create(%+v): expected vehicles.ErrInvalidVIN, got %v", input, err)
+		}
+	}
+	if list, _ := svc.ListForCustomer("c1", true); len(list) != 0 {
+		t.Errorf("expected nothing saved, got %d vehicles", len(list))
+	}
+
+	v, err := svc.Create(vehicles.CreateInput{CustomerID: "c1", VIN: "f10gls12345", LegacyVIN: true, Year: 1972})
+	if err != nil {
+		t.Fatalf("expected legacy VIN accepted, got %v", err)
+	}
+	if v.VIN != "F10GLS12345" || v.Year != 1972 {
+		t.Errorf("expected legacy VIN stored as given, got %+v", v)
+	}
+}
+
+func TestVehicleServiceUpdate(t *testing.T) {
+	repo := memory.NewVehicleRepository()
+	svc := vehicles.NewService(repo, vehicles.WithOdometer(repo.Odometer()))
+	v, err := svc.Create(vehicles.CreateInput{CustomerID: "c1", Make: "Ford", Mileage: 1000})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	mileage, color, plate := 1500, "Oxford White", " fl  abc1234 "
+	updated, err := svc.Update(v.ID, vehicles.UpdateInput{Mileage: &mileage, Color: &color, Plate: &plate})
+	if err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if updated.Mileage != 1500 || updated.Color != color || updated.Plate != "FL ABC1234" || updated.Make != "Ford" {
+		t.Fatalf("unexpected update result: %+v", updated)
+	}
+
+	negative := -1
+	if _, err := svc.Update(v.ID, vehicles.UpdateInput{Mileage: &negative}); !errors.Is(err, vehicles.ErrInvalid) {
+		t.Fatalf("expected ErrInvalid for negative mileage, got %v", err)
+	}
+}
+
+func TestVehicleServiceTransfer(t *testing.T) {
+	repo := memory.NewVehicleRepository()
+	owners := map[string]string{"seller": "seller", "buyer": "buyer", "merged-buyer": "buyer"}
+	svc := vehicles.NewService(repo, vehicles.WithOdometer(repo.Odometer()), vehicles.WithOwners(func(id string) (string, error) {
+		if resolved, ok := owners[id]; ok {
+			return resolved, nil
+		}
+		return "", vehicles.ErrOwnerNotFound
+	}))
+	v, err := svc.Create(vehicles.CreateInput{CustomerID: "seller", Make: "Honda", Mileage: 50000})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	if _, err := svc.Transfer(v.ID, vehicles.TransferInput{CustomerID: "nobody"}); !errors.Is(err, vehicles.ErrOwnerNotFound) {
+		t.Fatalf("expected ErrOwnerNotFound, got %v", err)
+	}
+	if _, err := svc.Transfer(v.ID, vehicles.TransferInput{CustomerID: "seller"}); !errors.Is(err, vehicles.ErrInvalid) {
+		t.Fatalf("expected ErrInvalid for the current owner, got %v", err)
+	}
+
+	moved, err := svc.Transfer(v.ID, vehicles.TransferInput{CustomerID: "merged-buyer", Mileage: 51200, Note: " private sale "})
+	if err != nil {
+		t.Fatalf("transfer failed: %v", err)
+	}
+	if moved.CustomerID != "buyer" || moved.Mileage != 51200 {
+		t.Fatalf("expected vehicle with buyer at 51200, got %+v", moved)
+	}
+	if list, _ := svc.ListForCustomer("seller", true); len(list) != 0 {
+		t.Fatalf("expected seller to have no vehicles, got %d", len(list))
+	}
+
+	history, err := svc.ListTransfers(v.ID)
+	if err != nil {
+		t.Fatalf("list transfers failed: %v", err)
+	}
+	if len(history) != 1 || history[0].FromCustomerID != "seller" || history[0].ToCustomerID != "buyer" || history[0].Note != "private sale" {
+		t.Fatalf("unexpected transfer history: %+v", history)
+	}
+}
+
+func TestVehicleServiceArchive(t *testing.T) {
+	svc := vehicles.NewService(memory.NewVehicleRepository())
+	kept, _ := svc.Create(vehicles.CreateInput{CustomerID: "c1", Make: "Ford"})
+	sold, _ := svc.Create(vehicles.CreateInput{CustomerID: "c1", Make: "Honda"})
+
+	archived, err := svc.Archive(sold.ID)
+	if err != nil || archived.ArchivedAt == nil {
+		t.Fatalf("archive: %+v, %v", archived, err)
+	}
+
+	active, _ := svc.ListForCustomer("c1", false)
+	if len(active) != 1 || active[0].ID != kept.ID {
+		t.Fatalf("expected only the kept vehicle listed, got %+v", active)
+	}
+	if all, _ := svc.ListForCustomer("c1", true); len(all) != 2 {
+		t.Fatalf("expected both vehicles with archived included, got %d", len(all))
+	}
+
+	mileage := 10
+	if _, err := svc.Update(sold.ID, vehicles.UpdateInput{Mileage: &mileage}); !errors.Is(err, vehicles.ErrArchived) {
+		t.Fatalf("expected ErrArchived updating an archived vehicle, got %v", err)
+	}
+	if _, err := svc.Transfer(sold.ID, vehicles.TransferInput{CustomerID: "c2"}); !errors.Is(err, vehicles.ErrArchived) {
+		t.Fatalf("expected ErrArchived transferring an archived vehicle, got %v", err)
+	}
+
+	restored, err := svc.Unarchive(sold.ID)
+	if err != nil || restored.ArchivedAt != nil {
+		t.Fatalf("unarchive: %+v, %v", restored, err)
+	}
+}
+
+func TestVehicleServiceUnitNumbers(t *testing.T) {
+	repo := memory.NewVehicleRepository()
+	svc := vehicles.NewService(repo, vehicles.WithOdometer(repo.Odometer()))
+	van, err := svc.Create(vehicles.CreateInput{CustomerID: "fleet", Make: "Ford", UnitNumber: " Van 12 "})
+	if err != nil || van.UnitNumber != "Van 12" {
+		t.Fatalf("create: %+v, %v", van, err)
+	}
+	if _, err := svc.Create(vehicles.CreateInput{CustomerID: "fleet", Make: "Ram", UnitNumber: "VAN 12"}); !errors.Is(err, vehicles.ErrUnitNumberExists) {
+		t.Fatalf("expected ErrUnitNumberExists, got %v", err)
+	}
+	if _, err := svc.Create(vehicles.CreateInput{CustomerID: "other", Make: "Ram", UnitNumber: "Van 12"}); err != nil {
+		t.Fatalf("unit numbers are per customer: %v", err)
+	}
+
+	truck, err := svc.Create(vehicles.CreateInput{CustomerID: "fleet", Make: "Ram", UnitNumber: "Truck 3", Mileage: 40000})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	taken, mileage := "van 12", 41000
+	if _, err := svc.Update(truck.ID, vehicles.UpdateInput{UnitNumber: &taken, Mileage: &mileage}); !errors.Is(err, vehicles.ErrUnitNumberExists) {
+		t.Fatalf("expected ErrUnitNumberExists, got %v", err)
+	}
+	// The refused update records no reading either.
+	readings, _ := svc.Readings(truck.ID)
+	if got, _ := svc.Get(truck.ID); got.Mileage != 40000 || len(readings) != 1 {
+		t.Fatalf("expected the refused update to change nothing, got %+v and %+v", got, readings)
+	}
+
+	// Archived vehicles free their number.
+	if _, err := svc.Archive(van.ID); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if _, err := svc.Update(truck.ID, vehicles.UpdateInput{UnitNumber: &taken}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if _, err := svc.Unarchive(van.ID); !errors.Is(err, vehicles.ErrUnitNumberExists) {
+		t.Fatalf("expected ErrUnitNumberExists unarchiving, got %v", err)
+	}
+
+	// The new owner does not inherit the fleet's numbering.
+	moved, err := svc.Transfer(truck.ID, vehicles.TransferInput{CustomerID: "buyer"})
+	if err != nil || moved.UnitNumber != "" {
+		t.Fatalf("transfer: %+v, %v", moved, err)
+	}
+}
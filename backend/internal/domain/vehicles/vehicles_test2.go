@@ -37,6 +37,14 @@ func (m *mockRepository) Save(vehicle Vehicle) (Vehicle, error) {
 	return vehicle, nil
 }
 
+func (m *mockRepository) Transfer(t Transfer, readings []Reading) (Transfer, error) {
+	return t, nil
+}
+
+func (m *mockRepository) ListTransfers(vehicleID string) ([]Transfer, error) {
+	return nil, nil
+}
+
 func TestService_Get(t *testing.T) {
 	repo := &mockRepository{
 		vehicles: map[string]Vehicle{
@@ -69,7 +77,7 @@ func TestService_ListForCustomer(t *testing.T) {
 	}
 	svc := NewService(repo)
 
-	list, err := svc.ListForCustomer("c1")
+	list, err := svc.ListForCustomer("c1", false)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -84,8 +92,8 @@ func TestService_Create(t *testing.T) {
 
 	input := CreateInput{
 		CustomerID: "c1",
-		VIN:        "VIN123",
-		Year:       2020,
+		VIN:        "4T1BF1FK7EU123456",
+		Year:       2014,
 		Make:       "Toyota",
 		Model:      "Camry",
 		Trim:       "LE",
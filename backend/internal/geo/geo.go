@@ -0,0 +1,83 @@
+// Package geo resolves service addresses to coordinates for scheduling and
+// travel pricing. Geocoding goes through the Geocoder interface so a hosted
+// provider can replace the offline ZIP centroid lookup.
+package geo
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+// Errors returned by geocoders.
+var (
+	ErrNoMatch     = errors.New("geocode: no match")
+	ErrUnavailable = errors.New("geocode: no geocoder configured")
+)
+
+// Point is a WGS84 coordinate.
+type Point struct {
+	Lat float64
+	Lng float64
+}
+
+// Query is the address to geocode. Region is the state or province.
+type Query struct {
+	Street     string
+	City       string
+	Region     string
+	PostalCode string
+	Country    string
+}
+
+// Result is a geocoded location.
+type Result struct {
+	Point Point
+	// Source names the geocoder and its precision, e.g. "zip_centroid". A
+	// centroid is good enough for travel estimates, not for navigation.
+	Source string
+}
+
+// Geocoder resolves addresses to coordinates. Implementations return
+// ErrNoMatch when the address cannot be located.
+type Geocoder interface {
+	Geocode(q Query) (Result, error)
+}
+
+// Noop is the Geocoder used when none is configured.
+type Noop struct{}
+
+// Geocode always returns ErrUnavailable.
+func (Noop) Geocode(Query) (Result, error) {
+	return Result{}, ErrUnavailable
+}
+
+const earthRadiusMiles = 3958.8
+
+// DistanceMiles returns the great-circle distance between a and b.
+func DistanceMiles(a, b Point) float64 {
+	rad := func(d float64) float64 { return d * math.Pi / 180 }
+	dLat := rad(b.Lat - a.Lat)
+	dLng := rad(b.Lng - a.Lng)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(rad(a.Lat))*math.Cos(rad(b.Lat))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusMiles * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// ZIP5 returns the five-digit ZIP code at the start of s ("32207-1234" gives
+// "32207"), or "" if s does not start with one.
+func ZIP5(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) < 5 {
+		return ""
+	}
+	for _, r := range s[:5] {
+		if r < '0' || r > '9' {
+			return ""
+		}
+	}
+	if len(s) > 5 && s[5] != '-' && s[5] != ' ' {
+		return ""
+	}
+	return s[:5]
+}
@@ -0,0 +1,71 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestReadZIPCentroidsCSV(t *testing.T) {
+	z, err := ReadZIPCentroids(strings.NewReader("zip,lat,lng\n32207,30.292,-81.636\n02134-0001, 42.353, -71.132\n"))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if z.Len() != 2 {
+		t.Fatalf("expected 2 zips, got %d", z.Len())
+	}
+
+	res, err := z.Geocode(Query{Street: "1 Main St", PostalCode: "32207-1234"})
+	if err != nil {
+		t.Fatalf("geocode: %v", err)
+	}
+	if res.Point != (Point{Lat: 30.292, Lng: -81.636}) || res.Source != SourceZIPCentroid {
+		t.Fatalf("unexpected result %+v", res)
+	}
+
+	for _, q := range []Query{{PostalCode: "99999"}, {PostalCode: ""}, {PostalCode: "32207", Country: "CA"}} {
+		if _, err := z.Geocode(q); !errors.Is(err, ErrNoMatch) {
+			t.Fatalf("geocode %+v: expected ErrNoMatch, got %v", q, err)
+		}
+	}
+}
+
+func TestLoadZIPCentroidsGazetteer(t *testing.T) {
+	z, err := LoadZIPCentroids("testdata/gazetteer.txt")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if _, err := z.Geocode(Query{PostalCode: "32256", Country: "US"}); err != nil {
+		t.Fatalf("geocode: %v", err)
+	}
+}
+
+func TestReadZIPCentroidsRejectsBadInput(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"zip,name\n32207,Jacksonville\n",
+		"zip,lat,lng\n32207,north,-81.6\n",
+	} {
+		if _, err := ReadZIPCentroids(strings.NewReader(in)); err == nil {
+			t.Fatalf("expected error for %q", in)
+		}
+	}
+}
+
+func TestDistanceMiles(t *testing.T) {
+	jax := Point{Lat: 30.332, Lng: -81.656}
+	orlando := Point{Lat: 28.538, Lng: -81.379}
+	if d := DistanceMiles(jax, orlando); math.Abs(d-125) > 3 {
+		t.Fatalf("expected about 125 miles, got %.1f", d)
+	}
+	if d := DistanceMiles(jax, jax); d != 0 {
+		t.Fatalf("expected 0, got %v", d)
+	}
+}
+
+func TestNoop(t *testing.T) {
+	if _, err := (Noop{}).Geocode(Query{PostalCode: "32207"}); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package geo
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// SourceZIPCentroid identifies results from ZIPCentroids.
+const SourceZIPCentroid = "zip_centroid"
+
+// ZIPCentroids geocodes US addresses to the centroid of their ZIP code using
+// a local file, so no network access or API key is needed.
+type ZIPCentroids struct {
+	points map[string]Point
+}
+
+// LoadZIPCentroids reads a delimited file with a header row naming a ZIP
+// column (zip, zcta, zcta5, geoid or postal_code), a latitude column (lat,
+// latitude or intptlat) and a longitude column (lng, lon, longitude or
+// intptlong). Commas and tabs are accepted, so the Census Bureau ZCTA
+// gazetteer file can be used unmodified.
+func LoadZIPCentroids(path string) (*ZIPCentroids, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	z, err := ReadZIPCentroids(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return z, nil
+}
+
+// ReadZIPCentroids parses the format described at LoadZIPCentroids.
+func ReadZIPCentroids(r io.Reader) (*ZIPCentroids, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	cr := csv.NewReader(strings.NewReader(string(data)))
+	if first, _, _ := strings.Cut(string(data), "\n"); strings.Contains(first, "\t") {
+		cr.Comma = '\t'
+	}
+	cr.TrimLeadingSpace = true
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	zipCol, latCol, lngCol := -1, -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "zip", "zcta", "zcta5", "geoid", "postal_code":
+			zipCol = i
+		case "lat", "latitude", "intptlat":
+			latCol = i
+		case "lng", "lon", "longitude", "intptlong":
+			lngCol = i
+		}
+	}
+	if zipCol < 0 || latCol < 0 || lngCol < 0 {
+		return nil, fmt.Errorf("header must name zip, latitude and longitude columns, got %q", header)
+	}
+
+	z := &ZIPCentroids{points: make(map[string]Point)}
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if len(rec) <= max(zipCol, latCol, lngCol) {
+			return nil, fmt.Errorf("line %d: expected at least %d fields", line, max(zipCol, latCol, lngCol)+1)
+		}
+		zip := ZIP5(rec[zipCol])
+		lat, latErr := strconv.ParseFloat(strings.TrimSpace(rec[latCol]), 64)
+		lng, lngErr := strconv.ParseFloat(strings.TrimSpace(rec[lngCol]), 64)
+		if zip == "" || latErr != nil || lngErr != nil {
+			return nil, fmt.Errorf("line %d: invalid zip or coordinates", line)
+		}
+		z.points[zip] = Point{Lat: lat, Lng: lng}
+	}
+	return z, nil
+}
+
+// Len returns the number of ZIP codes loaded.
+func (z *ZIPCentroids) Len() int {
+	return len(z.points)
+}
+
+// Geocode returns the centroid of the query's ZIP code. Only US addresses
+// (or those without a country) are resolved.
+func (z *ZIPCentroids) Geocode(q Query) (Result, error) {
+	if c := strings.ToUpper(strings.TrimSpace(q.Country)); c != "" && c != "US" && c != "USA" {
+		return Result{}, ErrNoMatch
+	}
+	p, ok := z.points[ZIP5(q.PostalCode)]
+	if !ok {
+		return Result{}, ErrNoMatch
+	}
+	return Result{Point: p, Source: SourceZIPCentroid}, nil
+}
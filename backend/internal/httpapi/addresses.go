@@ -0,0 +1,167 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"log/slog"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/geo"
+)
+
+func registerCustomerAddressRoutes(mux *http.ServeMux, logger *slog.Logger, service customers.Service) {
+	mux.HandleFunc("/v1/customers/{id}/addresses", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimSpace(r.PathValue("id"))
+		switch r.Method {
+		case http.MethodGet:
+			handleAddressList(w, id, logger, service)
+		case http.MethodPost:
+			handleAddressCreate(w, r, id, logger, service)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/v1/customers/{id}/addresses/{addressID}", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimSpace(r.PathValue("id"))
+		addressID := strings.TrimSpace(r.PathValue("addressID"))
+		switch r.Method {
+		case http.MethodPatch:
+			handleAddressUpdate(w, r, id, addressID, logger, service)
+		case http.MethodDelete:
+			handleAddressDelete(w, id, addressID, logger, service)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// addressPayload is the JSON form of an address. Pointer fields distinguish
+// absent from empty for PATCH.
+type addressPayload struct {
+	Label      *string `json:"label"`
+	Line1      *string `json:"line1"`
+	Line2      *string `json:"line2"`
+	City       *string `json:"city"`
+	Region     *string `json:"region"`
+	PostalCode *string `json:"postal_code"`
+	Country    *string `json:"country"`
+	Location   *struct {
+		Lat float64 `json:"lat"`
+		Lng float64 `json:"lng"`
+	} `json:"location"`
+}
+
+func (p addressPayload) point() *geo.Point {
+	if p.Location == nil {
+		return nil
+	}
+	return &geo.Point{Lat: p.Location.Lat, Lng: p.Location.Lng}
+}
+
+func (p addressPayload) empty() bool {
+	return p.Label == nil && p.Line1 == nil && p.Line2 == nil && p.City == nil &&
+		p.Region == nil && p.PostalCode == nil && p.Country == nil && p.Location == nil
+}
+
+func handleAddressList(w http.ResponseWriter, id string, logger *slog.Logger, service customers.Service) {
+	list, err := service.Addresses(id)
+	if err != nil {
+		respondAddressError(w, err, "list addresses", logger)
+		return
+	}
+	if list == nil {
+		list = []customers.Address{}
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"data": list, "count": len(list)})
+}
+
+func handleAddressCreate(w http.ResponseWriter, r *http.Request, id string, logger *slog.Logger, service customers.Service) {
+	var payload addressPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	value := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	address, err := service.AddAddress(id, customers.AddressInput{
+		Label:      value(payload.Label),
+		Line1:      value(payload.Line1),
+		Line2:      value(payload.Line2),
+		City:       value(payload.City),
+		Region:     value(payload.Region),
+		PostalCode: value(payload.PostalCode),
+		Country:    value(payload.Country),
+		Location:   payload.point(),
+	})
+	if err != nil {
+		respondAddressError(w, err, "add address", logger)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, address)
+}
+
+// handleAddressUpdate applies a partial update: only fields present in the
+// payload change.
+func handleAddressUpdate(w http.ResponseWriter, r *http.Request, id, addressID string, logger *slog.Logger, service customers.Service) {
+	var payload addressPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+	if payload.empty() {
+		respondError(w, http.StatusBadRequest, "no fields to update")
+		return
+	}
+
+	address, err := service.UpdateAddress(id, addressID, customers.AddressUpdate{
+		Label:      payload.Label,
+		Line1:      payload.Line1,
+		Line2:      payload.Line2,
+		City:       payload.City,
+		Region:     payload.Region,
+		PostalCode: payload.PostalCode,
+		Country:    payload.Country,
+		Location:   payload.point(),
+	})
+	if err != nil {
+		respondAddressError(w, err, "update address", logger)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, address)
+}
+
+func handleAddressDelete(w http.ResponseWriter, id, addressID string, logger *slog.Logger, service customers.Service) {
+	if err := service.DeleteAddress(id, addressID); err != nil {
+		respondAddressError(w, err, "delete address", logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func respondAddressError(w http.ResponseWriter, err error, action string, logger *slog.Logger) {
+	switch {
+	case errors.Is(err, customers.ErrNotImplemented):
+		respondError(w, http.StatusNotImplemented, action+" not yet implemented")
+	case errors.Is(err, customers.ErrNotFound):
+		respondError(w, http.StatusNotFound, "customer not found")
+	case errors.Is(err, customers.ErrAddressNotFound):
+		respondError(w, http.StatusNotFound, "address not found")
+	case errors.Is(err, customers.ErrInvalidAddress):
+		respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		logger.Error(action+" failed", "err", err)
+		respondError(w, http.StatusInternalServerError, "internal error")
+	}
+}
@@ -0,0 +1,155 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+)
+
+func registerConsentRoutes(mux *http.ServeMux, logger *slog.Logger, service customers.Service) {
+	mux.HandleFunc("/v1/customers/{id}/consents", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimSpace(r.PathValue("id"))
+		switch r.Method {
+		case http.MethodGet:
+			handleConsentGet(w, id, logger, service)
+		case http.MethodPost:
+			handleConsentRecord(w, r, id, logger, service)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/v1/customers/{id}/consents/keyword", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		handleConsentKeyword(w, r, strings.TrimSpace(r.PathValue("id")), logger, service)
+	})
+}
+
+// consentStateJSON is the wire form of customers.ConsentState. Source and
+// recorded_at are empty when the state is the default.
+type consentStateJSON struct {
+	Channel    customers.Channel `json:"channel"`
+	Purpose    customers.Purpose `json:"purpose"`
+	Granted    bool              `json:"granted"`
+	Default    bool              `json:"default"`
+	Source     string            `json:"source,omitempty"`
+	RecordedAt *time.Time        `json:"recorded_at,omitempty"`
+}
+
+func handleConsentGet(w http.ResponseWriter, id string, logger *slog.Logger, service customers.Service) {
+	states, err := service.EffectiveConsent(id)
+	if err != nil {
+		respondConsentError(w, err, "get consent", logger)
+		return
+	}
+	history, err := service.ConsentHistory(id)
+	if err != nil {
+		respondConsentError(w, err, "get consent", logger)
+		return
+	}
+
+	effective := make([]consentStateJSON, 0, len(states))
+	for _, st := range states {
+		s := consentStateJSON{Channel: st.Channel, Purpose: st.Purpose, Granted: st.Granted, Default: st.Entry == nil}
+		if st.Entry != nil {
+			s.Source, s.RecordedAt = st.Entry.Source, &st.Entry.RecordedAt
+		}
+		effective = append(effective, s)
+	}
+	if history == nil {
+		history = []customers.ConsentEntry{}
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"effective": effective, "history": history})
+}
+
+// handleConsentRecord appends a grant or revocation. The source defaults to
+// staff; the caller's IP address and user agent are recorded with it.
+func handleConsentRecord(w http.ResponseWriter, r *http.Request, id string, logger *slog.Logger, service customers.Service) {
+	var payload struct {
+		Channel customers.Channel `json:"channel"`
+		Purpose customers.Purpose `json:"purpose"`
+		Granted *bool             `json:"granted"`
+		Source  string            `json:"source"`
+		Detail  string            `json:"detail"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+	if payload.Granted == nil {
+		respondError(w, http.StatusBadRequest, "granted required")
+		return
+	}
+	if strings.TrimSpace(payload.Source) == "" {
+		payload.Source = customers.SourceStaff
+	}
+
+	entries, err := service.RecordConsent(id, customers.ConsentInput{
+		Channel:   payload.Channel,
+		Purpose:   payload.Purpose,
+		Granted:   *payload.Granted,
+		Source:    payload.Source,
+		Detail:    payload.Detail,
+		IPAddress: clientIP(r),
+		UserAgent: r.UserAgent(),
+	})
+	if err != nil {
+		respondConsentError(w, err, "record consent", logger)
+		return
+	}
+	respondJSON(w, http.StatusCreated, map[string]any{"data": entries, "count": len(entries)})
+}
+
+func handleConsentKeyword(w http.ResponseWriter, r *http.Request, id string, logger *slog.Logger, service customers.Service) {
+	var payload struct {
+		Keyword string `json:"keyword"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	entries, err := service.ApplyKeyword(id, payload.Keyword)
+	if err != nil {
+		respondConsentError(w, err, "apply keyword", logger)
+		return
+	}
+	if entries == nil {
+		entries = []customers.ConsentEntry{}
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"data": entries, "count": len(entries)})
+}
+
+func respondConsentError(w http.ResponseWriter, err error, action string, logger *slog.Logger) {
+	switch {
+	case errors.Is(err, customers.ErrNotImplemented):
+		respondError(w, http.StatusNotImplemented, action+" not yet implemented")
+	case errors.Is(err, customers.ErrNotFound):
+		respondError(w, http.StatusNotFound, "customer not found")
+	case errors.Is(err, customers.ErrInvalidConsent):
+		respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		logger.Error(action+" failed", "err", err)
+		respondError(w, http.StatusInternalServerError, "internal error")
+	}
+}
+
+// clientIP returns the address of the connection. X-Forwarded-For is not
+// used because the API is not deployed behind a proxy that would vouch for it.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
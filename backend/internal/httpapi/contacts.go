@@ -0,0 +1,149 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"log/slog"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+)
+
+func registerContactRoutes(mux *http.ServeMux, logger *slog.Logger, service customers.Service) {
+	mux.HandleFunc("/v1/customers/{id}/contacts", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimSpace(r.PathValue("id"))
+		switch r.Method {
+		case http.MethodGet:
+			handleContactList(w, id, logger, service)
+		case http.MethodPost:
+			handleContactCreate(w, r, id, logger, service)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/v1/customers/{id}/contacts/{contactID}", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimSpace(r.PathValue("id"))
+		contactID := strings.TrimSpace(r.PathValue("contactID"))
+		switch r.Method {
+		case http.MethodPatch:
+			handleContactUpdate(w, r, id, contactID, logger, service)
+		case http.MethodDelete:
+			handleContactDelete(w, id, contactID, logger, service)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// contactPayload is the JSON form of a fleet contact. Pointer fields
+// distinguish absent from empty for PATCH.
+type contactPayload struct {
+	Name  *string `json:"name"`
+	Role  *string `json:"role"`
+	Email *string `json:"email"`
+	Phone *string `json:"phone"`
+}
+
+func (p contactPayload) role() *customers.ContactRole {
+	if p.Role == nil {
+		return nil
+	}
+	role := customers.ContactRole(*p.Role)
+	return &role
+}
+
+func handleContactList(w http.ResponseWriter, id string, logger *slog.Logger, service customers.Service) {
+	list, err := service.Contacts(id)
+	if err != nil {
+		respondContactError(w, err, "list contacts", logger)
+		return
+	}
+	if list == nil {
+		list = []customers.Contact{}
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"data": list, "count": len(list)})
+}
+
+func handleContactCreate(w http.ResponseWriter, r *http.Request, id string, logger *slog.Logger, service customers.Service) {
+	var payload contactPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	value := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	contact, err := service.AddContact(id, customers.ContactInput{
+		Name:  value(payload.Name),
+		Role:  customers.ContactRole(value(payload.Role)),
+		Email: value(payload.Email),
+		Phone: value(payload.Phone),
+	})
+	if err != nil {
+		respondContactError(w, err, "add contact", logger)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, contact)
+}
+
+// handleContactUpdate applies a partial update: only fields present in the
+// payload change.
+func handleContactUpdate(w http.ResponseWriter, r *http.Request, id, contactID string, logger *slog.Logger, service customers.Service) {
+	var payload contactPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+	if payload == (contactPayload{}) {
+		respondError(w, http.StatusBadRequest, "no fields to update")
+		return
+	}
+
+	contact, err := service.UpdateContact(id, contactID, customers.ContactUpdate{
+		Name:  payload.Name,
+		Role:  payload.role(),
+		Email: payload.Email,
+		Phone: payload.Phone,
+	})
+	if err != nil {
+		respondContactError(w, err, "update contact", logger)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, contact)
+}
+
+func handleContactDelete(w http.ResponseWriter, id, contactID string, logger *slog.Logger, service customers.Service) {
+	if err := service.DeleteContact(id, contactID); err != nil {
+		respondContactError(w, err, "delete contact", logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func respondContactError(w http.ResponseWriter, err error, action string, logger *slog.Logger) {
+	switch {
+	case errors.Is(err, customers.ErrNotImplemented):
+		respondError(w, http.StatusNotImplemented, action+" not yet implemented")
+	case errors.Is(err, customers.ErrNotFound):
+		respondError(w, http.StatusNotFound, "customer not found")
+	case errors.Is(err, customers.ErrContactNotFound):
+		respondError(w, http.StatusNotFound, "contact not found")
+	case errors.Is(err, customers.ErrNotFleet):
+		respondError(w, http.StatusConflict, "customer is not a fleet account")
+	case errors.Is(err, customers.ErrInvalidContact):
+		respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		logger.Error(action+" failed", "err", err)
+		respondError(w, http.StatusInternalServerError, "internal error")
+	}
+}
@@ -0,0 +1,195 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"github.com/ezmobilemechanic/platform/internal/bulk"
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
+)
+
+// maxImportBytes bounds the size of an uploaded import file.
+const maxImportBytes = 50 << 20
+
+func registerCustomerImportRoutes(mux *http.ServeMux, logger *slog.Logger, service customers.Service) {
+	mux.HandleFunc("/v1/customers/import", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		handleCustomerImport(w, r, logger, service)
+	})
+
+	mux.HandleFunc("/v1/customers/export", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		handleCustomerExport(w, r, logger, service)
+	})
+}
+
+// importProblemJSON is the wire form of customers.ImportProblem.
+type importProblemJSON struct {
+	Line            int                     `json:"line"`
+	Outcome         customers.ImportOutcome `json:"outcome"`
+	Error           string                  `json:"error,omitempty"`
+	CustomerID      string                  `json:"customer_id,omitempty"`
+	DuplicateOfLine int                     `json:"duplicate_of_line,omitempty"`
+	Reasons         []string                `json:"reasons,omitempty"`
+}
+
+// handleCustomerImport streams the request body through the customer import.
+// The format comes from ?format= (csv, tsv or ndjson) or the Content-Type;
+// ?map=field:column pairs override the column mapping and ?dry_run=true
+// reports without saving.
+func handleCustomerImport(w http.ResponseWriter, r *http.Request, logger *slog.Logger, service customers.Service) {
+	query := r.URL.Query()
+	dryRun, err := parseBoolParam(query.Get("dry_run"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid dry_run parameter")
+		return
+	}
+	mapping, err := bulk.ParseMapping(query["map"])
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	format := importFormat(query.Get("format"), r.Header.Get("Content-Type"))
+
+	body := http.MaxBytesReader(w, r.Body, maxImportBytes)
+	var (
+		src     customers.ImportSource
+		columns []string
+	)
+	switch format {
+	case "csv", "tsv":
+		comma := ','
+		if format == "tsv" {
+			comma = '\t'
+		}
+		cr, err := bulk.NewCSVReader(body, comma, mapping)
+		if err != nil {
+			respondImportError(w, err, logger)
+			return
+		}
+		src, columns = cr, cr.Columns()
+	case "ndjson":
+		src = bulk.NewNDJSONReader(body, mapping)
+	default:
+		respondError(w, http.StatusUnsupportedMediaType, "import accepts text/csv, text/tab-separated-values or application/x-ndjson")
+		return
+	}
+
+	report, err := service.Import(src, customers.ImportOptions{DryRun: dryRun})
+	if err != nil {
+		respondImportError(w, err, logger)
+		return
+	}
+
+	problems := make([]importProblemJSON, 0, len(report.Problems))
+	for _, p := range report.Problems {
+		problems = append(problems, importProblemJSON{
+			Line:            p.Line,
+			Outcome:         p.Outcome,
+			Error:           p.Error,
+			CustomerID:      p.CustomerID,
+			DuplicateOfLine: p.DuplicateOfLine,
+			Reasons:         p.Reasons,
+		})
+	}
+	resp := map[string]any{
+		"dry_run":    report.DryRun,
+		"rows":       report.Rows,
+		"created":    report.Created,
+		"duplicates": report.Duplicates,
+		"invalid":    report.Invalid,
+		"problems":   problems,
+	}
+	if columns != nil {
+		resp["columns"] = columns
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// importFormat picks the file format from the format parameter, falling back
+// to the media type.
+func importFormat(param, contentType string) string {
+	if param != "" {
+		return strings.ToLower(param)
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv", "application/csv":
+		return "csv"
+	case "text/tab-separated-values":
+		return "tsv"
+	case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/x-jsonlines":
+		return "ndjson"
+	}
+	return ""
+}
+
+func respondImportError(w http.ResponseWriter, err error, logger *slog.Logger) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		respondError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("import file is larger than %d MB", maxImportBytes>>20))
+	case errors.Is(err, bulk.ErrInvalidMapping), errors.Is(err, bulk.ErrUnreadable):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, customers.ErrNotImplemented):
+		respondError(w, http.StatusNotImplemented, "import customers not yet implemented")
+	default:
+		logger.Error("import customers failed", "err", err)
+		respondError(w, http.StatusInternalServerError, "internal error")
+	}
+}
+
+// handleCustomerExport streams customers as CSV. It takes the list filters
+// and sort of GET /v1/customers; limit is ignored.
+func handleCustomerExport(w http.ResponseWriter, r *http.Request, logger *slog.Logger, service customers.Service) {
+	spec, err := parseListSpec(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// Errors after the first row are written can only truncate the file, so
+	// check the query first.
+	probe := spec
+	probe.Limit = 1
+	if _, err := service.List(probe); err != nil {
+		switch {
+		case errors.Is(err, customers.ErrNotImplemented):
+			respondError(w, http.StatusNotImplemented, "export customers not yet implemented")
+		case errors.Is(err, listing.ErrInvalidCursor):
+			respondError(w, http.StatusBadRequest, "invalid cursor parameter")
+		default:
+			logger.Error("export customers failed", "err", err)
+			respondError(w, http.StatusInternalServerError, "internal error")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="customers-%s.csv"`, time.Now().UTC().Format("20060102")))
+	w.WriteHeader(http.StatusOK)
+	if n, err := bulk.ExportCSV(w, service.List, spec); err != nil {
+		logger.Error("export customers failed", "err", err, "written", n)
+	}
+}
+
+// parseBoolParam accepts the forms strconv.ParseBool does; blank is false.
+func parseBoolParam(v string) (bool, error) {
+	if v == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(v)
+}
@@ -4,12 +4,13 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
-	"strconv"
+	"net/url"
 	"strings"
 
 	"log/slog"
 
 	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
 )
 
 func registerCustomerRoutes(mux *http.ServeMux, logger *slog.Logger, service customers.Service) {
@@ -24,103 +25,348 @@ func registerCustomerRoutes(mux *http.ServeMux, logger *slog.Logger, service cus
 		}
 	})
 
-	mux.HandleFunc("/v1/customers/", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodGet {
+	mux.HandleFunc("/v1/customers/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimSpace(r.PathValue("id"))
+		if id == "" {
+			respondError(w, http.StatusBadRequest, "missing customer id")
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			handleCustomerGet(w, r, id, logger, service)
+		case http.MethodPatch:
+			handleCustomerUpdate(w, r, id, logger, service)
+		case http.MethodDelete:
+			handleCustomerDelete(w, id, logger, service)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/v1/customers/{id}/restore", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
 			w.WriteHeader(http.StatusMethodNotAllowed)
 			return
 		}
+		handleCustomerRestore(w, strings.TrimSpace(r.PathValue("id")), logger, service)
+	})
 
-		id := strings.TrimPrefix(r.URL.Path, "/v1/customers/")
-		if id == "" {
-			respondError(w, http.StatusBadRequest, "missing customer id")
+	mux.HandleFunc("/v1/customers/{id}/duplicates", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
 			return
 		}
+		handleCustomerDuplicates(w, strings.TrimSpace(r.PathValue("id")), logger, service)
+	})
 
-		customer, err := service.Get(id)
-		if err != nil {
-			switch {
-			case errors.Is(err, customers.ErrNotImplemented):
-				respondError(w, http.StatusNotImplemented, "get customer not yet implemented")
-			case errors.Is(err, customers.ErrNotFound):
-				respondError(w, http.StatusNotFound, "customer not found")
-			default:
-				logger.Error("get customer failed", "err", err)
-				respondError(w, http.StatusInternalServerError, "internal error")
-			}
+	mux.HandleFunc("/v1/customers/{id}/merge", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
 			return
 		}
+		handleCustomerMerge(w, r, strings.TrimSpace(r.PathValue("id")), logger, service)
+	})
+
+	registerCustomerAddressRoutes(mux, logger, service)
+	registerContactRoutes(mux, logger, service)
+	registerConsentRoutes(mux, logger, service)
+	registerNoteRoutes(mux, logger, service)
+	registerCustomerImportRoutes(mux, logger, service)
+}
+
+// handleCustomerGet redirects lookups of a merged customer to the survivor.
+func handleCustomerGet(w http.ResponseWriter, r *http.Request, id string, logger *slog.Logger, service customers.Service) {
+	customer, err := service.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, customers.ErrNotImplemented):
+			respondError(w, http.StatusNotImplemented, "get customer not yet implemented")
+		case errors.Is(err, customers.ErrNotFound):
+			respondError(w, http.StatusNotFound, "customer not found")
+		default:
+			logger.Error("get customer failed", "err", err)
+			respondError(w, http.StatusInternalServerError, "internal error")
+		}
+		return
+	}
+	if customer.ID != id {
+		http.Redirect(w, r, "/v1/customers/"+url.PathEscape(customer.ID), http.StatusMovedPermanently)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, customer)
+}
+
+// handleCustomerUpdate applies a partial update: only fields present in the
+// payload change, and tags replace the customer's tags. marketing_opt is kept
+// for older clients and recorded in the consent ledger; see
+// recordMarketingOpt.
+func handleCustomerUpdate(w http.ResponseWriter, r *http.Request, id string, logger *slog.Logger, service customers.Service) {
+	var payload struct {
+		Type         *string   `json:"type"`
+		CompanyName  *string   `json:"company_name"`
+		FirstName    *string   `json:"first_name"`
+		LastName     *string   `json:"last_name"`
+		Email        *string   `json:"email"`
+		Phone        *string   `json:"phone"`
+		Tags         *[]string `json:"tags"`
+		PORequired   *bool     `json:"po_required"`
+		Billing      *string   `json:"billing"`
+		MarketingOpt *bool     `json:"marketing_opt"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	input := customers.UpdateInput{
+		CompanyName: trimmed(payload.CompanyName),
+		FirstName:   trimmed(payload.FirstName),
+		LastName:    trimmed(payload.LastName),
+		Email:       trimmed(payload.Email),
+		Phone:       trimmed(payload.Phone),
+		Tags:        payload.Tags,
+		PORequired:  payload.PORequired,
+	}
+	if payload.Type != nil {
+		t := customers.AccountType(*payload.Type)
+		input.Type = &t
+	}
+	if payload.Billing != nil {
+		b := customers.Billing(*payload.Billing)
+		input.Billing = &b
+	}
+	fieldsChanged := input != (customers.UpdateInput{})
+	if !fieldsChanged && payload.MarketingOpt == nil {
+		respondError(w, http.StatusBadRequest, "no fields to update")
+		return
+	}
+
+	var (
+		customer customers.Customer
+		err      error
+	)
+	if fieldsChanged {
+		customer, err = service.Update(id, input)
+	} else {
+		customer, err = service.Get(id)
+		if err == nil && customer.ID != id {
+			err = customers.ErrNotFound
+		}
+	}
+	if err == nil && payload.MarketingOpt != nil {
+		err = recordMarketingOpt(r, service, customer.ID, *payload.MarketingOpt)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, customers.ErrNotImplemented):
+			respondError(w, http.StatusNotImplemented, "update customer not yet implemented")
+		case errors.Is(err, customers.ErrNotFound):
+			respondError(w, http.StatusNotFound, "customer not found")
+		case errors.Is(err, customers.ErrEmailExists):
+			respondError(w, http.StatusConflict, "email already in use")
+		case errors.Is(err, customers.ErrInvalidPhone):
+			respondError(w, http.StatusBadRequest, "phone is not a valid phone number")
+		case errors.Is(err, customers.ErrInvalid), errors.Is(err, customers.ErrInvalidTag):
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			logger.Error("update customer failed", "err", err)
+			respondError(w, http.StatusInternalServerError, "internal error")
+		}
+		return
+	}
 
-		respondJSON(w, http.StatusOK, customer)
+	respondJSON(w, http.StatusOK, customer)
+}
+
+// recordMarketingOpt maps the legacy marketing_opt flag onto the consent
+// ledger. The flag never named a channel, so it only covers email: marketing
+// texts and calls need consent recorded for that channel.
+func recordMarketingOpt(r *http.Request, service customers.Service, id string, granted bool) error {
+	_, err := service.RecordConsent(id, customers.ConsentInput{
+		Channel:   customers.ChannelEmail,
+		Purpose:   customers.PurposeMarketing,
+		Granted:   granted,
+		Source:    customers.SourceStaff,
+		Detail:    "marketing_opt",
+		IPAddress: clientIP(r),
+		UserAgent: r.UserAgent(),
 	})
+	return err
 }
 
-func handleCustomerList(w http.ResponseWriter, r *http.Request, logger *slog.Logger, service customers.Service) {
-	query := r.URL.Query()
-	offset, limit := 0, 50
-	if v := query.Get("offset"); v != "" {
-		parsed, err := strconv.Atoi(v)
-		if err != nil || parsed < 0 {
-			respondError(w, http.StatusBadRequest, "invalid offset parameter")
-			return
+func handleCustomerDelete(w http.ResponseWriter, id string, logger *slog.Logger, service customers.Service) {
+	if err := service.Delete(id); err != nil {
+		switch {
+		case errors.Is(err, customers.ErrNotImplemented):
+			respondError(w, http.StatusNotImplemented, "delete customer not yet implemented")
+		case errors.Is(err, customers.ErrNotFound):
+			respondError(w, http.StatusNotFound, "customer not found")
+		default:
+			logger.Error("delete customer failed", "err", err)
+			respondError(w, http.StatusInternalServerError, "internal error")
 		}
-		offset = parsed
+		return
 	}
-	if v := query.Get("limit"); v != "" {
-		parsed, err := strconv.Atoi(v)
-		if err != nil || parsed < 0 {
-			respondError(w, http.StatusBadRequest, "invalid limit parameter")
-			return
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func handleCustomerRestore(w http.ResponseWriter, id string, logger *slog.Logger, service customers.Service) {
+	customer, err := service.Restore(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, customers.ErrNotImplemented):
+			respondError(w, http.StatusNotImplemented, "restore customer not yet implemented")
+		case errors.Is(err, customers.ErrNotFound):
+			respondError(w, http.StatusNotFound, "deleted customer not found")
+		case errors.Is(err, customers.ErrEmailExists):
+			respondError(w, http.StatusConflict, "email now used by another customer")
+		default:
+			logger.Error("restore customer failed", "err", err)
+			respondError(w, http.StatusInternalServerError, "internal error")
 		}
-		limit = parsed
+		return
 	}
 
-	results, err := service.List(offset, limit)
+	respondJSON(w, http.StatusOK, customer)
+}
+
+func handleCustomerDuplicates(w http.ResponseWriter, id string, logger *slog.Logger, service customers.Service) {
+	candidates, err := service.FindDuplicates(id)
 	if err != nil {
-		if errors.Is(err, customers.ErrNotImplemented) {
-			respondError(w, http.StatusNotImplemented, "list customers not yet implemented")
-			return
+		switch {
+		case errors.Is(err, customers.ErrNotImplemented):
+			respondError(w, http.StatusNotImplemented, "duplicate detection not yet implemented")
+		case errors.Is(err, customers.ErrNotFound):
+			respondError(w, http.StatusNotFound, "customer not found")
+		default:
+			logger.Error("find duplicate customers failed", "err", err)
+			respondError(w, http.StatusInternalServerError, "internal error")
+		}
+		return
+	}
+
+	type candidate struct {
+		Customer customers.Customer `json:"customer"`
+		Score    float64            `json:"score"`
+		Reasons  []string           `json:"reasons"`
+	}
+	data := make([]candidate, 0, len(candidates))
+	for _, c := range candidates {
+		data = append(data, candidate{Customer: c.Customer, Score: c.Score, Reasons: c.Reasons})
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"data": data, "count": len(data)})
+}
+
+// handleCustomerMerge folds the customer named in the payload into the one in
+// the path, which survives.
+func handleCustomerMerge(w http.ResponseWriter, r *http.Request, id string, logger *slog.Logger, service customers.Service) {
+	var payload struct {
+		DuplicateID string `json:"duplicate_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+	payload.DuplicateID = strings.TrimSpace(payload.DuplicateID)
+	if payload.DuplicateID == "" {
+		respondError(w, http.StatusBadRequest, "duplicate_id required")
+		return
+	}
+
+	customer, merge, err := service.Merge(id, payload.DuplicateID)
+	if err != nil {
+		switch {
+		case errors.Is(err, customers.ErrNotImplemented):
+			respondError(w, http.StatusNotImplemented, "merge customers not yet implemented")
+		case errors.Is(err, customers.ErrMergeSelf):
+			respondError(w, http.StatusBadRequest, "cannot merge a customer into itself")
+		case errors.Is(err, customers.ErrNotFound):
+			respondError(w, http.StatusNotFound, "customer not found")
+		case errors.Is(err, customers.ErrEmailExists):
+			respondError(w, http.StatusConflict, "email already in use")
+		default:
+			logger.Error("merge customers failed", "err", err)
+			respondError(w, http.StatusInternalServerError, "internal error")
 		}
-		logger.Error("list customers failed", "err", err)
-		respondError(w, http.StatusInternalServerError, "internal error")
 		return
 	}
 
 	respondJSON(w, http.StatusOK, map[string]any{
-		"data":  results,
-		"count": len(results),
+		"customer": customer,
+		"merge": map[string]any{
+			"merged_id":   merge.MergedID,
+			"survivor_id": merge.SurvivorID,
+			"vehicle_ids": merge.VehicleIDs,
+			"quote_ids":   merge.QuoteIDs,
+			"merged_at":   merge.MergedAt,
+		},
 	})
 }
 
-func handleCustomerCreate(w http.ResponseWriter, r *http.Request, logger *slog.Logger, service customers.Service) {
-	var input customers.CreateInput
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		respondError(w, http.StatusBadRequest, "invalid JSON payload")
+// trimmed returns a pointer to the trimmed value, preserving nil.
+func trimmed(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := strings.TrimSpace(*s)
+	return &v
+}
+
+func handleCustomerList(w http.ResponseWriter, r *http.Request, logger *slog.Logger, service customers.Service) {
+	spec, err := parseListSpec(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	if strings.TrimSpace(input.FirstName) == "" && strings.TrimSpace(input.LastName) == "" {
-		respondError(w, http.StatusBadRequest, "first_name or last_name required")
+	page, err := service.List(spec)
+	if err != nil {
+		switch {
+		case errors.Is(err, customers.ErrNotImplemented):
+			respondError(w, http.StatusNotImplemented, "list customers not yet implemented")
+		case errors.Is(err, listing.ErrInvalidCursor):
+			respondError(w, http.StatusBadRequest, "invalid cursor parameter")
+		default:
+			logger.Error("list customers failed", "err", err)
+			respondError(w, http.StatusInternalServerError, "internal error")
+		}
 		return
 	}
-	if strings.TrimSpace(input.Email) == "" && strings.TrimSpace(input.Phone) == "" {
-		respondError(w, http.StatusBadRequest, "email or phone required")
+
+	respondPage(w, page)
+}
+
+func handleCustomerCreate(w http.ResponseWriter, r *http.Request, logger *slog.Logger, service customers.Service) {
+	var input struct {
+		customers.CreateInput
+		MarketingOpt bool
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid JSON payload")
 		return
 	}
 
-	customer, err := service.Create(customers.CreateInput{
-		FirstName:    strings.TrimSpace(input.FirstName),
-		LastName:     strings.TrimSpace(input.LastName),
-		Email:        strings.TrimSpace(input.Email),
-		Phone:        strings.TrimSpace(input.Phone),
-		MarketingOpt: input.MarketingOpt,
-	})
+	customer, err := service.Create(input.CreateInput)
+	if err == nil && input.MarketingOpt {
+		err = recordMarketingOpt(r, service, customer.ID, true)
+	}
 	if err != nil {
-		if errors.Is(err, customers.ErrNotImplemented) {
+		switch {
+		case errors.Is(err, customers.ErrNotImplemented):
 			respondError(w, http.StatusNotImplemented, "create customer not yet implemented")
-			return
+		case errors.Is(err, customers.ErrEmailExists):
+			respondError(w, http.StatusConflict, "email already in use")
+		case errors.Is(err, customers.ErrInvalidPhone):
+			respondError(w, http.StatusBadRequest, "phone is not a valid phone number")
+		case errors.Is(err, customers.ErrInvalid), errors.Is(err, customers.ErrInvalidTag):
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			logger.Error("create customer failed", "err", err)
+			respondError(w, http.StatusInternalServerError, "internal error")
 		}
-		logger.Error("create customer failed", "err", err)
-		respondError(w, http.StatusInternalServerError, "internal error")
 		return
 	}
 
@@ -0,0 +1,75 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
+)
+
+// parseListSpec reads the shared list query parameters: cursor, limit, sort,
+// status, created_from, created_to and q.
+func parseListSpec(r *http.Request) (listing.Spec, error) {
+	query := r.URL.Query()
+	spec := listing.Spec{
+		Cursor: query.Get("cursor"),
+		Status: query.Get("status"),
+		Search: query.Get("q"),
+	}
+
+	if v := query.Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 0 {
+			return listing.Spec{}, errors.New("invalid limit parameter")
+		}
+		spec.Limit = parsed
+	}
+
+	sort, err := listing.ParseSort(query.Get("sort"))
+	if err != nil {
+		return listing.Spec{}, errors.New("invalid sort parameter")
+	}
+	spec.Sort = sort
+
+	if v := query.Get("created_from"); v != "" {
+		t, err := parseTimeParam(v)
+		if err != nil {
+			return listing.Spec{}, errors.New("invalid created_from parameter")
+		}
+		spec.CreatedFrom = t
+	}
+	if v := query.Get("created_to"); v != "" {
+		t, err := parseTimeParam(v)
+		if err != nil {
+			return listing.Spec{}, errors.New("invalid created_to parameter")
+		}
+		spec.CreatedTo = t
+	}
+
+	return spec.Normalize(), nil
+}
+
+// parseTimeParam accepts RFC 3339 timestamps or plain YYYY-MM-DD dates (UTC).
+func parseTimeParam(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, v)
+}
+
+// respondPage writes a list page using the shared envelope.
+func respondPage[T any](w http.ResponseWriter, page listing.Page[T]) {
+	items := page.Items
+	if items == nil {
+		items = []T{}
+	}
+	respondJSON(w, http.StatusOK, map[string]any{
+		"data":        items,
+		"count":       len(items),
+		"total":       page.Total,
+		"next_cursor": page.NextCursor,
+		"has_more":    page.HasMore,
+	})
+}
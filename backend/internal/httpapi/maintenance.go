@@ -0,0 +1,114 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/quotes"
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+	"github.com/ezmobilemechanic/platform/internal/maintenance"
+)
+
+func registerMaintenanceRoutes(mux *http.ServeMux, logger *slog.Logger, service maintenance.Service) {
+	// Due and overdue services for a vehicle as of now, or ?at=. ?all=true
+	// includes services not yet due.
+	mux.HandleFunc("/v1/vehicles/{id}/due-services", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		at, ok := atParam(w, r)
+		if !ok {
+			return
+		}
+		all := false
+		if v := r.URL.Query().Get("all"); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				respondError(w, http.StatusBadRequest, "invalid all parameter")
+				return
+			}
+			all = b
+		}
+
+		due, err := service.DueServices(strings.TrimSpace(r.PathValue("id")), at)
+		if err != nil {
+			respondMaintenanceError(w, err, "due services", logger)
+			return
+		}
+		if !all {
+			items := []maintenance.Item{}
+			for _, item := range due.Items {
+				if item.Status == maintenance.StatusOverdue || item.Status == maintenance.StatusDue {
+					items = append(items, item)
+				}
+			}
+			due.Items = items
+		}
+		respondJSON(w, http.StatusOK, due)
+	})
+
+	// The reminder candidates the scheduled job would produce, for staff to
+	// review or contact by hand.
+	mux.HandleFunc("/v1/maintenance/reminders", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		at, ok := atParam(w, r)
+		if !ok {
+			return
+		}
+		reminders, err := service.Reminders(at)
+		if err != nil {
+			respondMaintenanceError(w, err, "maintenance reminders", logger)
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]any{
+			"data":          reminders,
+			"count":         len(reminders),
+			"rules_version": service.Schedule().Version,
+		})
+	})
+
+	mux.HandleFunc("/v1/maintenance/schedule", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		respondJSON(w, http.StatusOK, service.Schedule())
+	})
+}
+
+// atParam parses the optional ?at= date, defaulting to now. It responds with
+// 400 and returns false when the date is invalid.
+func atParam(w http.ResponseWriter, r *http.Request) (time.Time, bool) {
+	v := r.URL.Query().Get("at")
+	if v == "" {
+		return time.Now(), true
+	}
+	t, err := parseTimeParam(v)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid at parameter")
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func respondMaintenanceError(w http.ResponseWriter, err error, action string, logger *slog.Logger) {
+	switch {
+	case errors.Is(err, vehicles.ErrNotImplemented), errors.Is(err, quotes.ErrNotImplemented), errors.Is(err, customers.ErrNotImplemented):
+		respondError(w, http.StatusNotImplemented, action+" not yet implemented")
+	case errors.Is(err, vehicles.ErrNotFound):
+		respondError(w, http.StatusNotFound, "vehicle not found")
+	default:
+		logger.Error(action+" failed", "err", err)
+		respondError(w, http.StatusInternalServerError, "internal error")
+	}
+}
@@ -0,0 +1,93 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"log/slog"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+)
+
+func registerNoteRoutes(mux *http.ServeMux, logger *slog.Logger, service customers.Service) {
+	mux.HandleFunc("/v1/customers/{id}/notes", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimSpace(r.PathValue("id"))
+		switch r.Method {
+		case http.MethodGet:
+			handleNoteList(w, id, logger, service)
+		case http.MethodPost:
+			handleNoteAdd(w, r, id, logger, service)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/v1/customers/{id}/notes/{note_id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		id := strings.TrimSpace(r.PathValue("id"))
+		noteID := strings.TrimSpace(r.PathValue("note_id"))
+		if err := service.DeleteNote(id, noteID); err != nil {
+			respondNoteError(w, err, "delete note", logger)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func handleNoteList(w http.ResponseWriter, id string, logger *slog.Logger, service customers.Service) {
+	notes, err := service.Notes(id)
+	if err != nil {
+		respondNoteError(w, err, "list notes", logger)
+		return
+	}
+	if notes == nil {
+		notes = []customers.Note{}
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"data": notes, "count": len(notes)})
+}
+
+// handleNoteAdd records a note or a logged call, text or email. The kind
+// defaults to note.
+func handleNoteAdd(w http.ResponseWriter, r *http.Request, id string, logger *slog.Logger, service customers.Service) {
+	var payload struct {
+		Kind   customers.NoteKind `json:"kind"`
+		Body   string             `json:"body"`
+		Author string             `json:"author"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	note, err := service.AddNote(id, customers.NoteInput{
+		Kind:   payload.Kind,
+		Body:   payload.Body,
+		Author: strings.TrimSpace(payload.Author),
+	})
+	if err != nil {
+		respondNoteError(w, err, "add note", logger)
+		return
+	}
+	respondJSON(w, http.StatusCreated, note)
+}
+
+func respondNoteError(w http.ResponseWriter, err error, action string, logger *slog.Logger) {
+	switch {
+	case errors.Is(err, customers.ErrNotImplemented):
+		respondError(w, http.StatusNotImplemented, action+" not yet implemented")
+	case errors.Is(err, customers.ErrNotFound):
+		respondError(w, http.StatusNotFound, "customer not found")
+	case errors.Is(err, customers.ErrNoteNotFound):
+		respondError(w, http.StatusNotFound, "note not found")
+	case errors.Is(err, customers.ErrInvalidNote):
+		respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		logger.Error(action+" failed", "err", err)
+		respondError(w, http.StatusInternalServerError, "internal error")
+	}
+}
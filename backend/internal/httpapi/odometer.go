@@ -0,0 +1,109 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+)
+
+// handleOdometer lists a vehicle's readings with an estimate of its mileage
+// now, or on the date given by ?at=.
+func handleOdometer(w http.ResponseWriter, r *http.Request, id string, logger *slog.Logger, service vehicles.Service) {
+	at := time.Now()
+	if v := r.URL.Query().Get("at"); v != "" {
+		t, err := parseTimeParam(v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid at parameter")
+			return
+		}
+		at = t
+	}
+
+	readings, err := service.Readings(id)
+	if err != nil {
+		respondVehicleError(w, err, "list odometer readings", logger)
+		return
+	}
+	estimate, err := service.EstimateMileage(id, at)
+	if err != nil {
+		respondVehicleError(w, err, "estimate mileage", logger)
+		return
+	}
+	if readings == nil {
+		readings = []vehicles.Reading{}
+	}
+	respondJSON(w, http.StatusOK, map[string]any{
+		"data":     readings,
+		"count":    len(readings),
+		"estimate": estimate,
+	})
+}
+
+// readingPayload is the JSON form of an odometer reading. read_at takes an
+// RFC 3339 time or a date and defaults to now.
+type readingPayload struct {
+	Mileage *int   `json:"mileage"`
+	Source  string `json:"source"`
+	ReadAt  string `json:"read_at"`
+	Note    string `json:"note"`
+	Flag    bool   `json:"flag"`
+}
+
+func handleReadingCreate(w http.ResponseWriter, r *http.Request, id string, logger *slog.Logger, service vehicles.Service) {
+	var payload readingPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+	if payload.Mileage == nil {
+		respondError(w, http.StatusBadRequest, "mileage is required")
+		return
+	}
+	input := vehicles.ReadingInput{
+		Mileage: *payload.Mileage,
+		Source:  vehicles.ReadingSource(strings.ToLower(strings.TrimSpace(payload.Source))),
+		Note:    payload.Note,
+		Flag:    payload.Flag,
+	}
+	if payload.ReadAt != "" {
+		t, err := parseTimeParam(strings.TrimSpace(payload.ReadAt))
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid read_at")
+			return
+		}
+		input.ReadAt = t
+	}
+
+	reading, err := service.RecordReading(id, input)
+	if err != nil {
+		respondVehicleError(w, err, "record odometer reading", logger)
+		return
+	}
+	respondJSON(w, http.StatusCreated, reading)
+}
+
+func handleReadingFlag(w http.ResponseWriter, r *http.Request, id, readingID string, logger *slog.Logger, service vehicles.Service) {
+	var payload struct {
+		Flagged *bool `json:"flagged"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+	if payload.Flagged == nil {
+		respondError(w, http.StatusBadRequest, "flagged is required")
+		return
+	}
+
+	reading, err := service.FlagReading(id, readingID, *payload.Flagged)
+	if err != nil {
+		respondVehicleError(w, err, "flag odometer reading", logger)
+		return
+	}
+	respondJSON(w, http.StatusOK, reading)
+}
@@ -0,0 +1,165 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"log/slog"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/quotes"
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+	"github.com/ezmobilemechanic/platform/internal/privacy"
+)
+
+func registerPrivacyRoutes(mux *http.ServeMux, logger *slog.Logger, service privacy.Service) {
+	mux.HandleFunc("/v1/customers/{id}/personal-data", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		handlePersonalDataExport(w, strings.TrimSpace(r.PathValue("id")), logger, service)
+	})
+
+	mux.HandleFunc("/v1/customers/{id}/erasure-requests", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		var payload struct {
+			RequestedBy string `json:"requested_by"`
+			Reason      string `json:"reason"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			respondError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		req, err := service.RequestErasure(strings.TrimSpace(r.PathValue("id")), privacy.RequestInput{
+			RequestedBy: payload.RequestedBy,
+			Reason:      payload.Reason,
+		})
+		if err != nil {
+			respondPrivacyError(w, err, "request erasure", logger)
+			return
+		}
+		respondJSON(w, http.StatusCreated, req)
+	})
+
+	mux.HandleFunc("/v1/erasure-requests", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		list, err := service.Erasures(privacy.Status(strings.TrimSpace(r.URL.Query().Get("status"))))
+		if err != nil {
+			respondPrivacyError(w, err, "list erasure requests", logger)
+			return
+		}
+		if list == nil {
+			list = []privacy.ErasureRequest{}
+		}
+		respondJSON(w, http.StatusOK, map[string]any{"data": list, "count": len(list)})
+	})
+
+	mux.HandleFunc("/v1/erasure-requests/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		req, err := service.Erasure(strings.TrimSpace(r.PathValue("id")))
+		if err != nil {
+			respondPrivacyError(w, err, "get erasure request", logger)
+			return
+		}
+		respondJSON(w, http.StatusOK, req)
+	})
+
+	mux.HandleFunc("/v1/erasure-requests/{id}/complete", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		var payload struct {
+			Actor string `json:"actor"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			respondError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		req, err := service.CompleteErasure(strings.TrimSpace(r.PathValue("id")), payload.Actor)
+		if err != nil {
+			respondPrivacyError(w, err, "complete erasure", logger)
+			return
+		}
+		logger.Info("erasure completed", "request_id", req.ID, "customer_id", req.CustomerID, "actor", payload.Actor)
+		respondJSON(w, http.StatusOK, req)
+	})
+
+	mux.HandleFunc("/v1/erasure-requests/{id}/reject", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		var payload struct {
+			Actor  string `json:"actor"`
+			Reason string `json:"reason"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			respondError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		req, err := service.RejectErasure(strings.TrimSpace(r.PathValue("id")), payload.Actor, payload.Reason)
+		if err != nil {
+			respondPrivacyError(w, err, "reject erasure", logger)
+			return
+		}
+		respondJSON(w, http.StatusOK, req)
+	})
+}
+
+// handlePersonalDataExport answers a subject access request with the
+// customer's archive as a downloadable JSON file. Exports are logged, since
+// the file carries all of the customer's PII.
+func handlePersonalDataExport(w http.ResponseWriter, id string, logger *slog.Logger, service privacy.Service) {
+	a, err := service.Export(id)
+	if err != nil {
+		respondPrivacyError(w, err, "export personal data", logger)
+		return
+	}
+	logger.Info("personal data exported", "customer_id", a.Customer.ID)
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="customer-%s-%s.json"`, a.Customer.ID, a.GeneratedAt.Format("20060102")))
+	respondJSON(w, http.StatusOK, map[string]any{
+		"generated_at":     a.GeneratedAt,
+		"customer":         a.Customer,
+		"addresses":        a.Addresses,
+		"vehicles":         a.Vehicles,
+		"quotes":           a.Quotes,
+		"communications":   a.Communications,
+		"notes":            a.Notes,
+		"consent":          a.Consent,
+		"erasure_requests": a.ErasureRequests,
+	})
+}
+
+func respondPrivacyError(w http.ResponseWriter, err error, action string, logger *slog.Logger) {
+	switch {
+	case errors.Is(err, privacy.ErrNotImplemented), errors.Is(err, customers.ErrNotImplemented),
+		errors.Is(err, vehicles.ErrNotImplemented), errors.Is(err, quotes.ErrNotImplemented):
+		respondError(w, http.StatusNotImplemented, action+" not yet implemented")
+	case errors.Is(err, customers.ErrNotFound):
+		respondError(w, http.StatusNotFound, "customer not found")
+	case errors.Is(err, privacy.ErrNotFound):
+		respondError(w, http.StatusNotFound, "erasure request not found")
+	case errors.Is(err, privacy.ErrInvalid):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, privacy.ErrOpenRequest), errors.Is(err, privacy.ErrClosed):
+		respondError(w, http.StatusConflict, err.Error())
+	default:
+		logger.Error(action+" failed", "err", err)
+		respondError(w, http.StatusInternalServerError, "internal error")
+	}
+}
@@ -2,14 +2,18 @@ package httpapi
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
 	"log/slog"
+
+	"github.com/ezmobilemechanic/platform/internal/geo"
+	"github.com/ezmobilemechanic/platform/internal/phone"
 )
 
 // registerPublicRoutes exposes unauthenticated endpoints for quote intake.
-func registerPublicRoutes(mux *http.ServeMux, logger *slog.Logger) {
+func registerPublicRoutes(mux *http.ServeMux, logger *slog.Logger, phoneRegion string, geocoder geo.Geocoder) {
 	mux.HandleFunc("/public/quote-intake", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			w.WriteHeader(http.StatusMethodNotAllowed)
@@ -22,18 +26,24 @@ func registerPublicRoutes(mux *http.ServeMux, logger *slog.Logger) {
 			return
 		}
 
-		payload.Normalize()
+		payload.Normalize(phoneRegion)
 		if err := payload.Validate(); err != nil {
 			respondError(w, http.StatusBadRequest, err.Error())
 			return
 		}
 
-		logger.Info("quote_intake_received",
+		attrs := []any{
 			"name", payload.Name,
 			"phone", payload.Phone,
 			"vehicle", payload.Vehicle,
+			"service_location", payload.Location.ServiceLocation,
+			"zip", payload.Location.Zip,
 			"source", payload.Source,
-		)
+		}
+		if res, err := geocoder.Geocode(payload.Location.Query()); err == nil {
+			attrs = append(attrs, "lat", res.Point.Lat, "lng", res.Point.Lng, "geocoded_by", res.Source)
+		}
+		logger.Info("quote_intake_received", attrs...)
 
 		respondJSON(w, http.StatusAccepted, map[string]any{
 			"status":  "accepted",
@@ -48,7 +58,7 @@ type QuoteIntakeRequest struct {
 	Phone         string            `json:"phone"`
 	Email         string            `json:"email,omitempty"`
 	Vehicle       map[string]string `json:"vehicle,omitempty"`
-	Location      map[string]string `json:"location,omitempty"`
+	Location      IntakeLocation    `json:"location"`
 	Repair        string            `json:"repair,omitempty"`
 	Mileage       *int              `json:"mileage,omitempty"`
 	LaborHours    *float64          `json:"labor_hours,omitempty"`
@@ -59,12 +69,42 @@ type QuoteIntakeRequest struct {
 	Estimate      map[string]any    `json:"estimate,omitempty"`
 	Extra         map[string]any    `json:"extra,omitempty"`
 	Source        string            `json:"source,omitempty"`
+
+	phoneValid bool
+}
+
+// Service locations offered by the website form.
+const (
+	ServiceLocationHome     = "home"
+	ServiceLocationWork     = "work"
+	ServiceLocationRoadside = "roadside"
+)
+
+// IntakeLocation is where the customer wants the vehicle serviced.
+type IntakeLocation struct {
+	Street          string `json:"street,omitempty"`
+	City            string `json:"city,omitempty"`
+	State           string `json:"state,omitempty"`
+	Zip             string `json:"zip,omitempty"`
+	ServiceLocation string `json:"service_location,omitempty"`
 }
 
-// Normalize trims whitespace and ensures nested maps exist.
-func (q *QuoteIntakeRequest) Normalize() {
+// Query returns the geocoder query for the location.
+func (l IntakeLocation) Query() geo.Query {
+	return geo.Query{Street: l.Street, City: l.City, Region: l.State, PostalCode: l.Zip}
+}
+
+// Normalize trims whitespace, converts the phone number to E.164 (numbers
+// without a country code are read as national numbers of phoneRegion) and
+// ensures nested maps exist. A number that cannot be parsed is kept as
+// submitted for Validate to reject.
+func (q *QuoteIntakeRequest) Normalize(phoneRegion string) {
 	q.Name = strings.TrimSpace(q.Name)
 	q.Phone = strings.TrimSpace(q.Phone)
+	q.phoneValid = q.Phone == ""
+	if n, err := phone.Parse(q.Phone, phoneRegion); err == nil {
+		q.Phone, q.phoneValid = n.E164, true
+	}
 	q.Email = strings.TrimSpace(q.Email)
 	q.Repair = strings.TrimSpace(q.Repair)
 	q.VIN = strings.TrimSpace(q.VIN)
@@ -72,12 +112,15 @@ func (q *QuoteIntakeRequest) Normalize() {
 	q.Concern = strings.TrimSpace(q.Concern)
 	q.Source = strings.TrimSpace(q.Source)
 
+	q.Location.Street = strings.TrimSpace(q.Location.Street)
+	q.Location.City = strings.TrimSpace(q.Location.City)
+	q.Location.State = strings.ToUpper(strings.TrimSpace(q.Location.State))
+	q.Location.Zip = strings.TrimSpace(q.Location.Zip)
+	q.Location.ServiceLocation = strings.ToLower(strings.TrimSpace(q.Location.ServiceLocation))
+
 	if q.Vehicle == nil {
 		q.Vehicle = make(map[string]string)
 	}
-	if q.Location == nil {
-		q.Location = make(map[string]string)
-	}
 	if q.Extra == nil {
 		q.Extra = make(map[string]any)
 	}
@@ -91,6 +134,14 @@ func (q *QuoteIntakeRequest) Validate() error {
 	if q.Phone == "" {
 		return errRequiredField("phone")
 	}
+	if !q.phoneValid {
+		return errors.New("phone is not a valid phone number")
+	}
+	switch q.Location.ServiceLocation {
+	case "", ServiceLocationHome, ServiceLocationWork, ServiceLocationRoadside:
+	default:
+		return errors.New("location.service_location must be home, work or roadside")
+	}
 	return nil
 }
 
@@ -4,11 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
-	"strconv"
 	"strings"
 
 	"log/slog"
 
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
 	"github.com/ezmobilemechanic/platform/internal/domain/quotes"
 )
 
@@ -33,17 +33,8 @@ func registerQuoteRoutes(mux *http.ServeMux, logger *slog.Logger, service quotes
 		}
 	})
 
-	mux.HandleFunc("/v1/customers/", func(w http.ResponseWriter, r *http.Request) {
-		if !strings.HasPrefix(r.URL.Path, "/v1/customers/") {
-			w.WriteHeader(http.StatusNotFound)
-			return
-		}
-		remainder := strings.TrimPrefix(r.URL.Path, "/v1/customers/")
-		if !strings.HasSuffix(remainder, "/quotes") {
-			return
-		}
-		customerID := strings.TrimSuffix(remainder, "/quotes")
-		customerID = strings.TrimSuffix(customerID, "/")
+	mux.HandleFunc("/v1/customers/{customerID}/quotes", func(w http.ResponseWriter, r *http.Request) {
+		customerID := strings.TrimSpace(r.PathValue("customerID"))
 		if customerID == "" {
 			respondError(w, http.StatusBadRequest, "missing customer id")
 			return
@@ -71,23 +62,28 @@ func handleQuoteCreate(w http.ResponseWriter, r *http.Request, logger *slog.Logg
 
 	for idx := range input.LineItems {
 		input.LineItems[idx].Description = strings.TrimSpace(input.LineItems[idx].Description)
-		if input.LineItems[idx].Quantity <= 0 {
-			input.LineItems[idx].Quantity = 1
-		}
 	}
 
 	quote, err := service.Create(quotes.CreateInput{
-		CustomerID: strings.TrimSpace(input.CustomerID),
-		VehicleID:  strings.TrimSpace(input.VehicleID),
-		LineItems:  input.LineItems,
+		CustomerID:      strings.TrimSpace(input.CustomerID),
+		VehicleID:       strings.TrimSpace(input.VehicleID),
+		LineItems:       input.LineItems,
+		TechnicianNotes: input.TechnicianNotes,
+		PONumber:        input.PONumber,
+		ContactID:       input.ContactID,
+		TechnicianTier:  input.TechnicianTier,
+		Discount:        input.Discount,
 	})
 	if err != nil {
-		if errors.Is(err, quotes.ErrNotImplemented) {
+		switch {
+		case errors.Is(err, quotes.ErrNotImplemented):
 			respondError(w, http.StatusNotImplemented, "create quote not yet implemented")
-			return
+		case errors.Is(err, quotes.ErrInvalid):
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			logger.Error("create quote failed", "err", err)
+			respondError(w, http.StatusInternalServerError, "internal error")
 		}
-		logger.Error("create quote failed", "err", err)
-		respondError(w, http.StatusInternalServerError, "internal error")
 		return
 	}
 
@@ -126,25 +122,43 @@ func handleQuoteUpdateStatus(w http.ResponseWriter, r *http.Request, logger *slo
 	}
 
 	var payload struct {
-		Status string `json:"status"`
+		Status          string  `json:"status"`
+		Actor           string  `json:"actor"`
+		Reason          string  `json:"reason"`
+		TechnicianNotes *string `json:"technician_notes"`
+		PONumber        *string `json:"po_number"`
+		ContactID       *string `json:"contact_id"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 		respondError(w, http.StatusBadRequest, "invalid JSON payload")
 		return
 	}
-	status := quotes.Status(strings.TrimSpace(payload.Status))
-	if status == "" {
-		respondError(w, http.StatusBadRequest, "status is required")
+	update := quotes.UpdateInput{
+		TechnicianNotes: payload.TechnicianNotes,
+		PONumber:        payload.PONumber,
+		ContactID:       payload.ContactID,
+	}
+	if status := quotes.Status(strings.TrimSpace(payload.Status)); status != "" {
+		update.Status = &quotes.StatusInput{Status: status, Actor: payload.Actor, Reason: payload.Reason}
+	}
+	if update == (quotes.UpdateInput{}) {
+		respondError(w, http.StatusBadRequest, "status, technician_notes, po_number or contact_id is required")
 		return
 	}
 
-	quote, err := service.UpdateStatus(id, status)
+	// The edits and the status change are saved together, or not at all.
+	quote, err := service.Update(id, update)
 	if err != nil {
 		switch {
 		case errors.Is(err, quotes.ErrNotImplemented):
 			respondError(w, http.StatusNotImplemented, "update quote not yet implemented")
 		case errors.Is(err, quotes.ErrNotFound):
 			respondError(w, http.StatusNotFound, "quote not found")
+		case errors.Is(err, quotes.ErrInvalid):
+			respondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, quotes.ErrInvalidTransition), errors.Is(err, quotes.ErrStatusChanged), errors.Is(err, quotes.ErrLocked),
+			errors.Is(err, quotes.ErrPORequired), errors.Is(err, quotes.ErrApproverRequired):
+			respondError(w, http.StatusConflict, err.Error())
 		default:
 			logger.Error("update quote status failed", "err", err)
 			respondError(w, http.StatusInternalServerError, "internal error")
@@ -156,38 +170,25 @@ func handleQuoteUpdateStatus(w http.ResponseWriter, r *http.Request, logger *slo
 }
 
 func handleQuoteListByCustomer(w http.ResponseWriter, r *http.Request, logger *slog.Logger, service quotes.Service, customerID string) {
-	query := r.URL.Query()
-	offset, limit := 0, 50
-	if v := query.Get("offset"); v != "" {
-		parsed, err := strconv.Atoi(v)
-		if err != nil || parsed < 0 {
-			respondError(w, http.StatusBadRequest, "invalid offset parameter")
-			return
-		}
-		offset = parsed
-	}
-	if v := query.Get("limit"); v != "" {
-		parsed, err := strconv.Atoi(v)
-		if err != nil || parsed < 0 {
-			respondError(w, http.StatusBadRequest, "invalid limit parameter")
-			return
-		}
-		limit = parsed
+	spec, err := parseListSpec(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	quotesList, err := service.ListForCustomer(customerID, offset, limit)
+	page, err := service.ListForCustomer(customerID, spec)
 	if err != nil {
-		if errors.Is(err, quotes.ErrNotImplemented) {
+		switch {
+		case errors.Is(err, quotes.ErrNotImplemented):
 			respondError(w, http.StatusNotImplemented, "list quotes not yet implemented")
-			return
+		case errors.Is(err, listing.ErrInvalidCursor):
+			respondError(w, http.StatusBadRequest, "invalid cursor parameter")
+		default:
+			logger.Error("list quotes failed", "err", err, "customer_id", customerID)
+			respondError(w, http.StatusInternalServerError, "internal error")
 		}
-		logger.Error("list quotes failed", "err", err, "customer_id", customerID)
-		respondError(w, http.StatusInternalServerError, "internal error")
 		return
 	}
 
-	respondJSON(w, http.StatusOK, map[string]any{
-		"data":  quotesList,
-		"count": len(quotesList),
-	})
+	respondPage(w, page)
 }
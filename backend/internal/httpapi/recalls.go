@@ -0,0 +1,79 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"log/slog"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+	"github.com/ezmobilemechanic/platform/internal/recalls"
+)
+
+func registerRecallRoutes(mux *http.ServeMux, logger *slog.Logger, service recalls.Service) {
+	mux.HandleFunc("/v1/vehicles/{id}/recalls", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		summary, err := service.ForVehicle(strings.TrimSpace(r.PathValue("id")))
+		if err != nil {
+			respondRecallError(w, err, "list recalls", logger)
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]any{
+			"data":         summary.Bulletins,
+			"count":        len(summary.Bulletins),
+			"open_recalls": summary.OpenRecalls,
+		})
+	})
+
+	// PUT marks a recall or service bulletin as addressed or the owner as
+	// informed; DELETE reopens it.
+	mux.HandleFunc("/v1/vehicles/{id}/recalls/{bulletinID}", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimSpace(r.PathValue("id"))
+		bulletinID := strings.TrimSpace(r.PathValue("bulletinID"))
+		switch r.Method {
+		case http.MethodPut:
+			var payload struct {
+				Status string `json:"status"`
+				Note   string `json:"note"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				respondError(w, http.StatusBadRequest, "invalid JSON payload")
+				return
+			}
+			marked, err := service.Mark(id, vehicles.RecallMarkInput{
+				BulletinID: bulletinID,
+				Status:     vehicles.RecallStatus(strings.TrimSpace(payload.Status)),
+				Note:       payload.Note,
+			})
+			if err != nil {
+				respondRecallError(w, err, "mark recall", logger)
+				return
+			}
+			respondJSON(w, http.StatusOK, marked)
+		case http.MethodDelete:
+			if err := service.Unmark(id, bulletinID); err != nil {
+				respondRecallError(w, err, "unmark recall", logger)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func respondRecallError(w http.ResponseWriter, err error, action string, logger *slog.Logger) {
+	switch {
+	case errors.Is(err, recalls.ErrBulletinNotFound):
+		respondError(w, http.StatusNotFound, "recall or bulletin not found for this vehicle")
+	case errors.Is(err, vehicles.ErrRecallMarkNotFound):
+		respondError(w, http.StatusNotFound, "recall is not marked")
+	default:
+		respondVehicleError(w, err, action, logger)
+	}
+}
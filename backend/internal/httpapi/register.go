@@ -27,8 +27,14 @@ func Register(mux *http.ServeMux, logger *slog.Logger, domainServices domain.Con
 	})
 
 	registerCustomerRoutes(mux, logger, domainServices.Customers)
-	registerVehicleRoutes(mux, logger, domainServices.Vehicles)
+	registerTimelineRoutes(mux, logger, domainServices.Timeline)
+	registerPrivacyRoutes(mux, logger, domainServices.Privacy)
+	registerVehicleRoutes(mux, logger, domainServices.Vehicles, domainServices.Recalls)
+	registerRecallRoutes(mux, logger, domainServices.Recalls)
+	registerVehicleHistoryRoutes(mux, logger, domainServices.ServiceHistory)
+	registerMaintenanceRoutes(mux, logger, domainServices.Maintenance)
+	registerSpecRoutes(mux, logger, domainServices.Specs)
 	registerQuoteRoutes(mux, logger, domainServices.Quotes)
 	registerAuthRoutes(mux, logger, domainServices.Users)
-	registerPublicRoutes(mux, logger)
+	registerPublicRoutes(mux, logger, domainServices.PhoneRegion, domainServices.Geocoder)
 }
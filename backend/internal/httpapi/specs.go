@@ -0,0 +1,47 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"log/slog"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+	"github.com/ezmobilemechanic/platform/internal/specs"
+)
+
+func registerSpecRoutes(mux *http.ServeMux, logger *slog.Logger, service specs.Service) {
+	// Fluids, capacities, tire sizes and torque specs for a vehicle, from
+	// the spec catalog.
+	mux.HandleFunc("/v1/vehicles/{id}/specs", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		spec, err := service.ForVehicle(strings.TrimSpace(r.PathValue("id")))
+		if err != nil {
+			switch {
+			case errors.Is(err, vehicles.ErrNotImplemented):
+				respondError(w, http.StatusNotImplemented, "vehicle specs not yet implemented")
+			case errors.Is(err, vehicles.ErrNotFound):
+				respondError(w, http.StatusNotFound, "vehicle not found")
+			case errors.Is(err, specs.ErrNoSpec):
+				respondError(w, http.StatusNotFound, "no specs for this vehicle")
+			default:
+				logger.Error("vehicle specs failed", "err", err)
+				respondError(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+		respondJSON(w, http.StatusOK, spec)
+	})
+
+	mux.HandleFunc("/v1/vehicle-specs", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		respondJSON(w, http.StatusOK, service.Catalog())
+	})
+}
@@ -0,0 +1,47 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"log/slog"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
+	"github.com/ezmobilemechanic/platform/internal/domain/quotes"
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+	"github.com/ezmobilemechanic/platform/internal/timeline"
+)
+
+func registerTimelineRoutes(mux *http.ServeMux, logger *slog.Logger, service timeline.Service) {
+	mux.HandleFunc("/v1/customers/{id}/timeline", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		spec, err := parseListSpec(r)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		page, err := service.ForCustomer(strings.TrimSpace(r.PathValue("id")), spec)
+		if err != nil {
+			switch {
+			case errors.Is(err, customers.ErrNotImplemented), errors.Is(err, vehicles.ErrNotImplemented),
+				errors.Is(err, quotes.ErrNotImplemented):
+				respondError(w, http.StatusNotImplemented, "customer timeline not yet implemented")
+			case errors.Is(err, customers.ErrNotFound):
+				respondError(w, http.StatusNotFound, "customer not found")
+			case errors.Is(err, listing.ErrInvalidCursor):
+				respondError(w, http.StatusBadRequest, "invalid cursor parameter")
+			default:
+				logger.Error("customer timeline failed", "err", err)
+				respondError(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+		respondPage(w, page)
+	})
+}
@@ -0,0 +1,150 @@
+package httpapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"log/slog"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/quotes"
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+	"github.com/ezmobilemechanic/platform/internal/servicehistory"
+)
+
+func registerVehicleHistoryRoutes(mux *http.ServeMux, logger *slog.Logger, service servicehistory.Service) {
+	// JSON by default; ?format=html, or a browser asking for text/html, gets
+	// a printable page.
+	mux.HandleFunc("/v1/vehicles/{id}/history", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		history, err := service.ForVehicle(strings.TrimSpace(r.PathValue("id")))
+		if err != nil {
+			switch {
+			case errors.Is(err, vehicles.ErrNotImplemented), errors.Is(err, quotes.ErrNotImplemented):
+				respondError(w, http.StatusNotImplemented, "vehicle history not yet implemented")
+			case errors.Is(err, vehicles.ErrNotFound):
+				respondError(w, http.StatusNotFound, "vehicle not found")
+			default:
+				logger.Error("vehicle history failed", "err", err)
+				respondError(w, http.StatusInternalServerError, "internal error")
+			}
+			return
+		}
+
+		switch historyFormat(r) {
+		case "html":
+			var buf bytes.Buffer
+			if err := historyPage.Execute(&buf, history); err != nil {
+				logger.Error("render vehicle history failed", "err", err)
+				respondError(w, http.StatusInternalServerError, "internal error")
+				return
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			w.Write(buf.Bytes())
+		case "json":
+			respondJSON(w, http.StatusOK, history)
+		default:
+			respondError(w, http.StatusBadRequest, "format must be json or html")
+		}
+	})
+}
+
+func historyFormat(r *http.Request) string {
+	if f := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format"))); f != "" {
+		return f
+	}
+	if strings.Contains(r.Header.Get("Accept"), "text/html") {
+		return "html"
+	}
+	return "json"
+}
+
+// formatCents renders an amount in cents as dollars.
+func formatCents(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	dollars := fmt.Sprint(cents / 100)
+	for i := len(dollars) - 3; i > 0; i -= 3 {
+		dollars = dollars[:i] + "," + dollars[i:]
+	}
+	return fmt.Sprintf("%s$%s.%02d", sign, dollars, cents%100)
+}
+
+// formatMiles renders a mileage with thousands separators, or a dash when
+// unknown.
+func formatMiles(miles int) string {
+	if miles <= 0 {
+		return "—"
+	}
+	s := fmt.Sprint(miles)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+var historyPage = template.Must(template.New("history").Funcs(template.FuncMap{
+	"cents": formatCents,
+	"miles": formatMiles,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Service history{{with .Vehicle}} – {{if .Year}}{{.Year}} {{end}}{{.Make}} {{.Model}}{{end}}</title>
+<style>
+  body { font-family: Helvetica, Arial, sans-serif; color: #222; margin: 2em; }
+  h1 { margin-bottom: 0.2em; }
+  .vehicle { margin-bottom: 2em; }
+  .vehicle dt { float: left; clear: left; width: 8em; color: #666; }
+  .entry { border-top: 1px solid #999; padding-top: 0.8em; margin-bottom: 1.5em; page-break-inside: avoid; }
+  .entry h2 { font-size: 1.1em; margin: 0 0 0.5em; }
+  table { border-collapse: collapse; width: 100%; }
+  th, td { text-align: left; padding: 0.2em 0.5em; }
+  td.num, th.num { text-align: right; }
+  tfoot td { font-weight: bold; border-top: 1px solid #ccc; }
+  .notes { white-space: pre-wrap; margin-top: 0.5em; }
+  footer { color: #666; font-size: 0.85em; margin-top: 3em; }
+  @media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+<h1>Vehicle service history</h1>
+{{with .Vehicle}}
+<dl class="vehicle">
+  <dt>Vehicle</dt><dd>{{if .Year}}{{.Year}} {{end}}{{.Make}} {{.Model}} {{.Trim}}</dd>
+  {{if .VIN}}<dt>VIN</dt><dd>{{.VIN}}</dd>{{end}}
+  {{if .Engine}}<dt>Engine</dt><dd>{{.Engine}}</dd>{{end}}
+  {{if .Color}}<dt>Color</dt><dd>{{.Color}}</dd>{{end}}
+  <dt>Mileage</dt><dd>{{miles .Mileage}}</dd>
+</dl>
+{{end}}
+{{range .Entries}}
+<section class="entry">
+  <h2>{{.Date.Format "January 2, 2006"}} · {{miles .Mileage}} miles</h2>
+  <table>
+    <thead><tr><th>Work</th><th class="num">Qty</th><th class="num">Labor hrs</th><th class="num">Price</th><th class="num">Labor</th></tr></thead>
+    <tbody>
+    {{range .LineItems}}
+      <tr><td>{{.Description}}</td><td class="num">{{.Quantity}}</td><td class="num">{{if .LaborHours}}{{printf "%.2f" .LaborHours}}{{end}}</td><td class="num">{{cents .UnitPrice}}</td><td class="num">{{if .LaborAmount}}{{cents .LaborAmount}}{{end}}</td></tr>
+    {{end}}
+    </tbody>
+    <tfoot><tr><td colspan="4">Total</td><td class="num">{{cents .TotalAmount}}</td></tr></tfoot>
+  </table>
+  {{if .TechnicianNotes}}<p class="notes"><strong>Technician notes:</strong> {{.TechnicianNotes}}</p>{{end}}
+</section>
+{{else}}
+<p>No service recorded yet.</p>
+{{end}}
+<footer>Generated {{.GeneratedAt.Format "January 2, 2006"}} by EZ Mobile Mechanic.</footer>
+</body>
+</html>
+`))
@@ -9,9 +9,11 @@ import (
 	"log/slog"
 
 	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+	"github.com/ezmobilemechanic/platform/internal/recalls"
+	"github.com/ezmobilemechanic/platform/internal/vin"
 )
 
-func registerVehicleRoutes(mux *http.ServeMux, logger *slog.Logger, service vehicles.Service) {
+func registerVehicleRoutes(mux *http.ServeMux, logger *slog.Logger, service vehicles.Service, recallService recalls.Service) {
 	mux.HandleFunc("/v1/vehicles", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodPost:
@@ -21,29 +23,93 @@ func registerVehicleRoutes(mux *http.ServeMux, logger *slog.Logger, service vehi
 		}
 	})
 
-	mux.HandleFunc("/v1/vehicles/", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/v1/vehicles/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimSpace(r.PathValue("id"))
 		switch r.Method {
 		case http.MethodGet:
-			handleVehicleGet(w, r, logger, service)
+			handleVehicleGet(w, id, logger, service, recallService)
+		case http.MethodPatch:
+			handleVehicleUpdate(w, r, id, logger, service)
 		default:
 			w.WriteHeader(http.StatusMethodNotAllowed)
 		}
 	})
 
-	mux.HandleFunc("/v1/customers/", func(w http.ResponseWriter, r *http.Request) {
-		if !strings.HasPrefix(r.URL.Path, "/v1/customers/") {
-			w.WriteHeader(http.StatusNotFound)
+	mux.HandleFunc("/v1/vehicles/{id}/transfers", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimSpace(r.PathValue("id"))
+		switch r.Method {
+		case http.MethodGet:
+			handleVehicleTransferList(w, id, logger, service)
+		case http.MethodPost:
+			handleVehicleTransfer(w, r, id, logger, service)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/v1/vehicles/{id}/odometer", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimSpace(r.PathValue("id"))
+		switch r.Method {
+		case http.MethodGet:
+			handleOdometer(w, r, id, logger, service)
+		case http.MethodPost:
+			handleReadingCreate(w, r, id, logger, service)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/v1/vehicles/{id}/odometer/{readingID}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			w.WriteHeader(http.StatusMethodNotAllowed)
 			return
 		}
+		handleReadingFlag(w, r, strings.TrimSpace(r.PathValue("id")), strings.TrimSpace(r.PathValue("readingID")), logger, service)
+	})
 
-		remainder := strings.TrimPrefix(r.URL.Path, "/v1/customers/")
-		if !strings.HasSuffix(remainder, "/vehicles") {
-			w.WriteHeader(http.StatusNotFound)
+	mux.HandleFunc("/v1/vehicles/{id}/archive", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
 			return
 		}
+		vehicle, err := service.Archive(strings.TrimSpace(r.PathValue("id")))
+		if err != nil {
+			respondVehicleError(w, err, "archive vehicle", logger)
+			return
+		}
+		respondJSON(w, http.StatusOK, vehicle)
+	})
 
-		customerID := strings.TrimSuffix(remainder, "/vehicles")
-		customerID = strings.TrimSuffix(customerID, "/")
+	mux.HandleFunc("/v1/vehicles/{id}/unarchive", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		vehicle, err := service.Unarchive(strings.TrimSpace(r.PathValue("id")))
+		if err != nil {
+			respondVehicleError(w, err, "unarchive vehicle", logger)
+			return
+		}
+		respondJSON(w, http.StatusOK, vehicle)
+	})
+
+	// Decoding needs no stored vehicle, so intake can check a VIN read over
+	// the phone before creating one.
+	mux.HandleFunc("/v1/vins/{vin}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		info, err := vin.Decode(r.PathValue("vin"))
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondJSON(w, http.StatusOK, info)
+	})
+
+	mux.HandleFunc("/v1/customers/{customerID}/vehicles", func(w http.ResponseWriter, r *http.Request) {
+		customerID := strings.TrimSpace(r.PathValue("customerID"))
 		if customerID == "" {
 			respondError(w, http.StatusBadRequest, "missing customer id")
 			return
@@ -78,47 +144,151 @@ func handleVehicleCreate(w http.ResponseWriter, r *http.Request, logger *slog.Lo
 		Model:      strings.TrimSpace(input.Model),
 		Trim:       strings.TrimSpace(input.Trim),
 		Engine:     strings.TrimSpace(input.Engine),
+		Color:      strings.TrimSpace(input.Color),
+		Plate:      input.Plate,
+		UnitNumber: input.UnitNumber,
 		Mileage:    input.Mileage,
+		LegacyVIN:  input.LegacyVIN,
 	})
 	if err != nil {
-		if errors.Is(err, vehicles.ErrNotImplemented) {
-			respondError(w, http.StatusNotImplemented, "create vehicle not yet implemented")
-			return
-		}
-		logger.Error("create vehicle failed", "err", err)
-		respondError(w, http.StatusInternalServerError, "internal error")
+		respondVehicleError(w, err, "create vehicle", logger)
 		return
 	}
 
 	respondJSON(w, http.StatusCreated, vehicle)
 }
 
-func handleVehicleGet(w http.ResponseWriter, r *http.Request, logger *slog.Logger, service vehicles.Service) {
-	id := strings.TrimPrefix(r.URL.Path, "/v1/vehicles/")
-	if id == "" {
-		respondError(w, http.StatusBadRequest, "missing vehicle id")
+// handleVehicleGet returns the vehicle with the recalls and service
+// bulletins that apply to it, so they can be raised when booking.
+func handleVehicleGet(w http.ResponseWriter, id string, logger *slog.Logger, service vehicles.Service, recallService recalls.Service) {
+	vehicle, err := service.Get(id)
+	if err != nil {
+		respondVehicleError(w, err, "get vehicle", logger)
 		return
 	}
-
-	vehicle, err := service.Get(id)
+	summary, err := recallService.ForVehicle(vehicle.ID)
 	if err != nil {
-		switch {
-		case errors.Is(err, vehicles.ErrNotImplemented):
-			respondError(w, http.StatusNotImplemented, "get vehicle not yet implemented")
-		case errors.Is(err, vehicles.ErrNotFound):
-			respondError(w, http.StatusNotFound, "vehicle not found")
-		default:
-			logger.Error("get vehicle failed", "err", err)
-			respondError(w, http.StatusInternalServerError, "internal error")
+		respondVehicleError(w, err, "get vehicle recalls", logger)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, struct {
+		vehicles.Vehicle
+		OpenRecalls int
+		Recalls     []recalls.VehicleBulletin
+	}{vehicle, summary.OpenRecalls, summary.Bulletins})
+}
+
+// vehicleUpdatePayload is the JSON form of a vehicle update. Only fields
+// present change.
+type vehicleUpdatePayload struct {
+	Mileage    *int    `json:"mileage"`
+	Trim       *string `json:"trim"`
+	Engine     *string `json:"engine"`
+	Color      *string `json:"color"`
+	Plate      *string `json:"plate"`
+	UnitNumber *string `json:"unit_number"`
+}
+
+func handleVehicleUpdate(w http.ResponseWriter, r *http.Request, id string, logger *slog.Logger, service vehicles.Service) {
+	var payload vehicleUpdatePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+	if payload == (vehicleUpdatePayload{}) {
+		respondError(w, http.StatusBadRequest, "no fields to update")
+		return
+	}
+
+	trim := func(s *string) *string {
+		if s == nil {
+			return nil
 		}
+		t := strings.TrimSpace(*s)
+		return &t
+	}
+	vehicle, err := service.Update(id, vehicles.UpdateInput{
+		Mileage:    payload.Mileage,
+		Trim:       trim(payload.Trim),
+		Engine:     trim(payload.Engine),
+		Color:      trim(payload.Color),
+		Plate:      payload.Plate,
+		UnitNumber: payload.UnitNumber,
+	})
+	if err != nil {
+		respondVehicleError(w, err, "update vehicle", logger)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, vehicle)
+}
+
+// vehicleTransferPayload names a vehicle's new owner.
+type vehicleTransferPayload struct {
+	CustomerID string `json:"customer_id"`
+	Mileage    int    `json:"mileage"`
+	Note       string `json:"note"`
+}
+
+func handleVehicleTransfer(w http.ResponseWriter, r *http.Request, id string, logger *slog.Logger, service vehicles.Service) {
+	var payload vehicleTransferPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	vehicle, err := service.Transfer(id, vehicles.TransferInput{
+		CustomerID: payload.CustomerID,
+		Mileage:    payload.Mileage,
+		Note:       payload.Note,
+	})
+	if err != nil {
+		respondVehicleError(w, err, "transfer vehicle", logger)
 		return
 	}
 
 	respondJSON(w, http.StatusOK, vehicle)
 }
 
+func handleVehicleTransferList(w http.ResponseWriter, id string, logger *slog.Logger, service vehicles.Service) {
+	list, err := service.ListTransfers(id)
+	if err != nil {
+		respondVehicleError(w, err, "list transfers", logger)
+		return
+	}
+	if list == nil {
+		list = []vehicles.Transfer{}
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"data": list, "count": len(list)})
+}
+
+func respondVehicleError(w http.ResponseWriter, err error, action string, logger *slog.Logger) {
+	switch {
+	case errors.Is(err, vehicles.ErrNotImplemented):
+		respondError(w, http.StatusNotImplemented, action+" not yet implemented")
+	case errors.Is(err, vehicles.ErrNotFound):
+		respondError(w, http.StatusNotFound, "vehicle not found")
+	case errors.Is(err, vehicles.ErrReadingNotFound):
+		respondError(w, http.StatusNotFound, "odometer reading not found")
+	case errors.Is(err, vehicles.ErrMileageBackwards), errors.Is(err, vehicles.ErrUnitNumberExists):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, vehicles.ErrOwnerNotFound):
+		respondError(w, http.StatusBadRequest, "new owner not found")
+	case errors.Is(err, vehicles.ErrArchived):
+		respondError(w, http.StatusConflict, "vehicle is archived; unarchive it first")
+	case errors.Is(err, vehicles.ErrInvalid), errors.Is(err, vehicles.ErrInvalidVIN):
+		respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		logger.Error(action+" failed", "err", err)
+		respondError(w, http.StatusInternalServerError, "internal error")
+	}
+}
+
+// handleVehicleListByCustomer lists a customer's active vehicles, or all of
+// them with ?include_archived=true.
 func handleVehicleListByCustomer(w http.ResponseWriter, r *http.Request, logger *slog.Logger, service vehicles.Service, customerID string) {
-	list, err := service.ListForCustomer(customerID)
+	list, err := service.ListForCustomer(customerID, r.URL.Query().Get("include_archived") == "true")
 	if err != nil {
 		if errors.Is(err, vehicles.ErrNotImplemented) {
 			respondError(w, http.StatusNotImplemented, "list vehicles not yet implemented")
@@ -0,0 +1,35 @@
+// Package jobs runs periodic background maintenance alongside the API server.
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Func is one run of a periodic job.
+type Func func(ctx context.Context) error
+
+// Every runs fn once immediately and then at each interval until ctx is
+// cancelled. Failures are logged and the job keeps its schedule. Every blocks;
+// callers start it in a goroutine.
+func Every(ctx context.Context, name string, interval time.Duration, logger *slog.Logger, fn Func) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger = logger.With("job", name)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := fn(ctx); err != nil && ctx.Err() == nil {
+			logger.Error("job failed", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
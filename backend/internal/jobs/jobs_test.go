@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEveryRunsUntilCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var runs atomic.Int32
+
+	done := make(chan struct{})
+	go func() {
+		Every(ctx, "test", time.Millisecond, nil, func(context.Context) error {
+			if runs.Add(1) == 3 {
+				cancel()
+			}
+			return errors.New("keeps going")
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Every did not return after cancel")
+	}
+	if n := runs.Load(); n != 3 {
+		t.Fatalf("expected 3 runs, got %d", n)
+	}
+}
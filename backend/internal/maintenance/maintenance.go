@@ -0,0 +1,258 @@
+// Package maintenance works out which scheduled services a vehicle is due
+// for. Intervals come from a versioned rules file (see Schedule); the last
+// time each service was done comes from the vehicle's service history, and
+// the mileage between readings is estimated from its odometer history.
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+	"github.com/ezmobilemechanic/platform/internal/servicehistory"
+)
+
+// Status says how close a service is to its interval.
+type Status string
+
+const (
+	StatusOverdue Status = "overdue"
+	// StatusDue is within the schedule's DueSoon window of the interval.
+	StatusDue Status = "due"
+	StatusOK  Status = "ok"
+	// StatusUnknown is a mileage-only service on a vehicle with no mileage
+	// on record.
+	StatusUnknown Status = "unknown"
+)
+
+// Item is one scheduled service for a vehicle.
+type Item struct {
+	Service        string
+	Name           string
+	Status         Status
+	IntervalMiles  int
+	IntervalMonths int
+	// LastServiceAt, LastQuoteID and LastServiceMileage describe the last
+	// time the work was done. They are empty when there is no record of it,
+	// in which case the interval counts from when the vehicle was added.
+	LastServiceAt      *time.Time
+	LastQuoteID        string
+	LastServiceMileage int
+	// DueMileage is 0 when the service has no mileage interval or the
+	// vehicle's mileage is unknown; DueAt is nil without a time interval.
+	DueMileage int
+	DueAt      *time.Time
+}
+
+// DueServices is a vehicle's maintenance schedule on a date.
+type DueServices struct {
+	VehicleID    string
+	RulesVersion string
+	At           time.Time
+	// Mileage is the vehicle's estimated mileage at At.
+	Mileage int
+	// Items lists overdue services first, then due, ok and unknown ones.
+	Items []Item
+}
+
+// Reminder is a vehicle with services due or overdue, for contacting its
+// owner.
+type Reminder struct {
+	CustomerID   string
+	VehicleID    string
+	Vehicle      string
+	RulesVersion string
+	Mileage      int
+	// Items holds only the due and overdue services.
+	Items []Item
+}
+
+// Service computes due services.
+type Service interface {
+	// DueServices returns every scheduled service for the vehicle as of at,
+	// or vehicles.ErrNotFound.
+	DueServices(vehicleID string, at time.Time) (DueServices, error)
+	// Reminders returns the active vehicles of active customers that have a
+	// service due or overdue at at, oldest customer first.
+	Reminders(at time.Time) ([]Reminder, error)
+	// Schedule returns the rules in use.
+	Schedule() Schedule
+}
+
+// NewService builds a maintenance service over the domain services.
+func NewService(schedule Schedule, c customers.Service, v vehicles.Service, h servicehistory.Service) Service {
+	return &service{schedule: schedule, customers: c, vehicles: v, history: h}
+}
+
+type service struct {
+	schedule  Schedule
+	customers customers.Service
+	vehicles  vehicles.Service
+	history   servicehistory.Service
+}
+
+func (s *service) Schedule() Schedule {
+	return s.schedule
+}
+
+func (s *service) DueServices(vehicleID string, at time.Time) (DueServices, error) {
+	v, err := s.vehicles.Get(vehicleID)
+	if err != nil {
+		return DueServices{}, err
+	}
+	return s.dueServices(v, at.UTC())
+}
+
+func (s *service) dueServices(v vehicles.Vehicle, at time.Time) (DueServices, error) {
+	history, err := s.history.ForVehicle(v.ID)
+	if err != nil {
+		return DueServices{}, err
+	}
+	mileage, known, err := s.mileage(v.ID, at)
+	if err != nil {
+		return DueServices{}, err
+	}
+
+	d := DueServices{VehicleID: v.ID, RulesVersion: s.schedule.Version, At: at, Mileage: mileage, Items: []Item{}}
+	for _, rule := range s.schedule.RulesFor(v) {
+		item := Item{
+			Service:        rule.Service,
+			Name:           rule.Name,
+			IntervalMiles:  rule.Miles,
+			IntervalMonths: rule.Months,
+		}
+		since, sinceMileage := v.CreatedAt, 0
+		if last, ok := lastService(history.Entries, rule); ok {
+			date := last.Date
+			item.LastServiceAt, item.LastQuoteID = &date, last.RefID
+			item.LastServiceMileage = last.Mileage
+			since, sinceMileage = last.Date, last.Mileage
+		}
+		if sinceMileage == 0 && known {
+			if sinceMileage, _, err = s.mileage(v.ID, since); err != nil {
+				return DueServices{}, err
+			}
+		}
+		if rule.Miles > 0 && known {
+			item.DueMileage = sinceMileage + rule.Miles
+		}
+		if rule.Months > 0 {
+			due := since.UTC().AddDate(0, rule.Months, 0)
+			item.DueAt = &due
+		}
+		item.Status = s.status(item, mileage, at)
+		d.Items = append(d.Items, item)
+	}
+	sort.SliceStable(d.Items, func(i, j int) bool { return rank(d.Items[i].Status) < rank(d.Items[j].Status) })
+	return d, nil
+}
+
+func (s *service) Reminders(at time.Time) ([]Reminder, error) {
+	at = at.UTC()
+	out := []Reminder{}
+	spec := listing.Spec{Limit: listing.MaxLimit, Sort: listing.Sort{Field: listing.SortCreatedAt}}
+	for {
+		page, err := s.customers.List(spec)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range page.Items {
+			list, err := s.vehicles.ListForCustomer(c.ID, false)
+			if err != nil {
+				return nil, err
+			}
+			for _, v := range list {
+				d, err := s.dueServices(v, at)
+				if err != nil {
+					return nil, err
+				}
+				r := Reminder{
+					CustomerID:   c.ID,
+					VehicleID:    v.ID,
+					Vehicle:      describe(v),
+					RulesVersion: d.RulesVersion,
+					Mileage:      d.Mileage,
+				}
+				for _, item := range d.Items {
+					if item.Status == StatusOverdue || item.Status == StatusDue {
+						r.Items = append(r.Items, item)
+					}
+				}
+				if len(r.Items) > 0 {
+					out = append(out, r)
+				}
+			}
+		}
+		if !page.HasMore {
+			return out, nil
+		}
+		spec.Cursor = page.NextCursor
+	}
+}
+
+// mileage estimates the vehicle's mileage at at. It reports false when the
+// vehicle has no mileage on record.
+func (s *service) mileage(vehicleID string, at time.Time) (int, bool, error) {
+	e, err := s.vehicles.EstimateMileage(vehicleID, at)
+	if errors.Is(err, vehicles.ErrNotImplemented) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return e.Mileage, !e.LastReadAt.IsZero(), nil
+}
+
+func (s *service) status(item Item, mileage int, at time.Time) Status {
+	soonMiles := s.schedule.DueSoon.Miles
+	soonDays := s.schedule.DueSoon.Days
+	switch {
+	case item.DueMileage > 0 && mileage >= item.DueMileage,
+		item.DueAt != nil && !at.Before(*item.DueAt):
+		return StatusOverdue
+	case item.DueMileage > 0 && mileage >= item.DueMileage-soonMiles,
+		item.DueAt != nil && !at.Before(item.DueAt.AddDate(0, 0, -soonDays)):
+		return StatusDue
+	case item.DueMileage == 0 && item.DueAt == nil:
+		return StatusUnknown
+	}
+	return StatusOK
+}
+
+// lastService returns the latest history entry with a line item covered by
+// the rule.
+func lastService(entries []servicehistory.Entry, rule Rule) (servicehistory.Entry, bool) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		for _, item := range entries[i].LineItems {
+			if rule.matches(item.Description) {
+				return entries[i], true
+			}
+		}
+	}
+	return servicehistory.Entry{}, false
+}
+
+func rank(s Status) int {
+	switch s {
+	case StatusOverdue:
+		return 0
+	case StatusDue:
+		return 1
+	case StatusOK:
+		return 2
+	}
+	return 3
+}
+
+func describe(v vehicles.Vehicle) string {
+	out := v.Make + " " + v.Model
+	if v.Year > 0 {
+		out = fmt.Sprintf("%d %s", v.Year, out)
+	}
+	return strings.TrimSpace(out)
+}
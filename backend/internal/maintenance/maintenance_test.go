@@ -0,0 +1,205 @@
+package maintenance_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/quotes"
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+	"github.com/ezmobilemechanic/platform/internal/maintenance"
+	"github.com/ezmobilemechanic/platform/internal/servicehistory"
+	"github.com/ezmobilemechanic/platform/internal/storage/memory"
+)
+
+const testRules = `{
+  "schema": 1,
+  "version": "test-1",
+  "due_soon": {"miles": 500, "days": 30},
+  "rules": [
+    {"service": "oil-change", "name": "Oil change", "miles": 5000, "months": 6, "match": ["oil change"]},
+    {"service": "brake-fluid", "name": "Brake fluid flush", "months": 24, "match": ["brake fluid"]},
+    {"service": "spark-plugs", "name": "Spark plugs", "miles": 100000},
+    {"service": "oil-change", "name": "Oil change", "make": "Toyota", "miles": 10000, "months": 12, "match": ["oil change"]},
+    {"service": "oil-change", "name": "Oil change", "make": "Toyota", "engine": "hybrid", "miles": 10000, "months": 24, "match": ["oil change"]}
+  ]
+}`
+
+func TestRead(t *testing.T) {
+	s, err := maintenance.Read(strings.NewReader(testRules))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if s.Version != "test-1" || len(s.Rules) != 5 {
+		t.Fatalf("unexpected schedule %+v", s)
+	}
+	if got := s.Rules[2].Match; len(got) != 1 || got[0] != "spark plugs" {
+		t.Fatalf("expected match to default to the lowercased name, got %v", got)
+	}
+
+	for name, doc := range map[string]string{
+		"schema":      `{"schema": 2, "version": "x", "rules": []}`,
+		"version":     `{"schema": 1, "rules": []}`,
+		"interval":    `{"schema": 1, "version": "x", "rules": [{"service": "a", "name": "A"}]}`,
+		"unknown key": `{"schema": 1, "version": "x", "rules": [{"service": "a", "name": "A", "mile": 5000}]}`,
+	} {
+		if _, err := maintenance.Read(strings.NewReader(doc)); !errors.Is(err, maintenance.ErrInvalidSchedule) {
+			t.Errorf("%s: expected ErrInvalidSchedule, got %v", name, err)
+		}
+	}
+
+	if d := maintenance.Default(); d.Version == "" || len(d.Rules) == 0 {
+		t.Fatalf("expected the built-in schedule to load, got %+v", d)
+	}
+}
+
+func TestRulesFor(t *testing.T) {
+	s, err := maintenance.Read(strings.NewReader(testRules))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	oil := func(v vehicles.Vehicle) maintenance.Rule {
+		t.Helper()
+		rules := s.RulesFor(v)
+		if len(rules) != 3 || rules[0].Service != "oil-change" {
+			t.Fatalf("expected one rule per service in file order, got %+v", rules)
+		}
+		return rules[0]
+	}
+	if r := oil(vehicles.Vehicle{Make: "Ford"}); r.Miles != 5000 {
+		t.Fatalf("expected the generic interval, got %+v", r)
+	}
+	if r := oil(vehicles.Vehicle{Make: "toyota", Engine: "2.5L I4"}); r.Miles != 10000 || r.Months != 12 {
+		t.Fatalf("expected the Toyota interval, got %+v", r)
+	}
+	if r := oil(vehicles.Vehicle{Make: "Toyota", Engine: "2.5L I4 Hybrid"}); r.Months != 24 {
+		t.Fatalf("expected the hybrid interval, got %+v", r)
+	}
+}
+
+func TestDueServicesAndReminders(t *testing.T) {
+	schedule, err := maintenance.Read(strings.NewReader(testRules))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	customerRepo := memory.NewCustomerRepository()
+	vehicleRepo := memory.NewVehicleRepository()
+	customerSvc := customers.NewService(customerRepo)
+	vehicleSvc := vehicles.NewService(vehicleRepo, vehicles.WithOdometer(vehicleRepo.Odometer()))
+	quoteRepo := memory.NewQuoteRepository()
+	quoteSvc := quotes.NewService(quoteRepo, quotes.WithHistory(quoteRepo.History()))
+	svc := maintenance.NewService(schedule, customerSvc, vehicleSvc, servicehistory.NewService(vehicleSvc, quoteSvc))
+
+	c, err := customerSvc.Create(customers.CreateInput{FirstName: "Alex", Email: "alex@example.com"})
+	if err != nil {
+		t.Fatalf("create customer: %v", err)
+	}
+	v, err := vehicleSvc.Create(vehicles.CreateInput{CustomerID: c.ID, Year: 2019, Make: "Toyota", Model: "Camry"})
+	if err != nil {
+		t.Fatalf("create vehicle: %v", err)
+	}
+	now := time.Now().UTC()
+	for _, r := range []struct {
+		mileage int
+		at      time.Time
+	}{
+		{30000, now.AddDate(0, 0, -365)},
+		{48000, now.AddDate(0, 0, -1)},
+	} {
+		if _, err := vehicleSvc.RecordReading(v.ID, vehicles.ReadingInput{Mileage: r.mileage, Source: vehicles.SourceInvoice, ReadAt: r.at}); err != nil {
+			t.Fatalf("record reading: %v", err)
+		}
+	}
+	q, err := quoteSvc.Create(quotes.CreateInput{CustomerID: c.ID, VehicleID: v.ID, LineItems: []quotes.CreateLineItem{
+		{Description: "Synthetic oil change", Quantity: 1, UnitPrice: 8999},
+	}})
+	if err != nil {
+		t.Fatalf("create quote: %v", err)
+	}
+	for _, status := range []quotes.Status{quotes.StatusSent, quotes.StatusAccepted} {
+		if _, err := quoteSvc.UpdateStatus(q.ID, quotes.StatusInput{Status: status}); err != nil {
+			t.Fatalf("accept quote: %v", err)
+		}
+	}
+
+	item := func(d maintenance.DueServices, service string) maintenance.Item {
+		t.Helper()
+		for _, it := range d.Items {
+			if it.Service == service {
+				return it
+			}
+		}
+		t.Fatalf("no %s in %+v", service, d.Items)
+		return maintenance.Item{}
+	}
+
+	d, err := svc.DueServices(v.ID, now)
+	if err != nil {
+		t.Fatalf("due services: %v", err)
+	}
+	// The last reading was yesterday, so today's mileage is projected a
+	// day on from it.
+	if d.RulesVersion != "test-1" || d.Mileage < 48000 || d.Mileage > 48100 || len(d.Items) != 3 {
+		t.Fatalf("unexpected due services %+v", d)
+	}
+	oil := item(d, "oil-change")
+	if oil.Status != maintenance.StatusOK || oil.LastQuoteID != q.ID || oil.LastServiceMileage != 48000 || oil.DueMileage != 58000 {
+		t.Fatalf("unexpected oil change %+v", oil)
+	}
+	brake := item(d, "brake-fluid")
+	if brake.LastServiceAt != nil || brake.LastServiceMileage != 0 || brake.DueMileage != 0 || brake.DueAt == nil || !brake.DueAt.Equal(v.CreatedAt.UTC().AddDate(2, 0, 0)) {
+		t.Fatalf("expected brake fluid due two years after the vehicle was added, got %+v", brake)
+	}
+
+	// About 50 miles a day puts the vehicle within 500 miles of the oil
+	// change after roughly 190 days and past it after 200.
+	d, err = svc.DueServices(v.ID, now.AddDate(0, 0, 240))
+	if err != nil {
+		t.Fatalf("due services: %v", err)
+	}
+	if d.Items[0].Service != "oil-change" || d.Items[0].Status != maintenance.StatusOverdue {
+		t.Fatalf("expected the oil change overdue and listed first, got %+v", d.Items)
+	}
+
+	// A vehicle without mileage is only scheduled by time.
+	other, err := customerSvc.Create(customers.CreateInput{FirstName: "Sam", Email: "sam@example.com"})
+	if err != nil {
+		t.Fatalf("create customer: %v", err)
+	}
+	bare, err := vehicleSvc.Create(vehicles.CreateInput{CustomerID: other.ID, Make: "Ford", Model: "Focus"})
+	if err != nil {
+		t.Fatalf("create vehicle: %v", err)
+	}
+	d, err = svc.DueServices(bare.ID, now)
+	if err != nil {
+		t.Fatalf("due services: %v", err)
+	}
+	if plugs := item(d, "spark-plugs"); plugs.Status != maintenance.StatusUnknown {
+		t.Fatalf("expected spark plugs unknown without mileage, got %+v", plugs)
+	}
+
+	reminders, err := svc.Reminders(now.AddDate(0, 0, 240))
+	if err != nil {
+		t.Fatalf("reminders: %v", err)
+	}
+	if len(reminders) != 2 || reminders[0].VehicleID != v.ID || reminders[0].Vehicle != "2019 Toyota Camry" {
+		t.Fatalf("unexpected reminders %+v", reminders)
+	}
+	for _, it := range reminders[0].Items {
+		if it.Status != maintenance.StatusOverdue && it.Status != maintenance.StatusDue {
+			t.Fatalf("expected only due work in reminders, got %+v", it)
+		}
+	}
+	if _, err := vehicleSvc.Archive(bare.ID); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if reminders, err = svc.Reminders(now.AddDate(0, 0, 240)); err != nil || len(reminders) != 1 {
+		t.Fatalf("expected archived vehicles left out, got %+v, %v", reminders, err)
+	}
+
+	if _, err := svc.DueServices("missing", now); !errors.Is(err, vehicles.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+package maintenance
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+)
+
+// SchemaVersion is the rules file format this package reads. Files declare
+// it in "schema"; a change that old readers would misread bumps it.
+const SchemaVersion = 1
+
+// ErrInvalidSchedule is returned for rules files that cannot be used.
+var ErrInvalidSchedule = errors.New("invalid maintenance schedule")
+
+// Rule is a maintenance interval. Rules with Make, Model or Engine set apply
+// only to matching vehicles and take precedence over less specific rules for
+// the same Service, so a manufacturer's interval replaces the generic one.
+type Rule struct {
+	// Service identifies the kind of work, such as "oil-change". Rules for
+	// the same service replace one another.
+	Service string `json:"service"`
+	Name    string `json:"name"`
+	Make    string `json:"make,omitempty"`
+	Model   string `json:"model,omitempty"`
+	// Engine matches when it appears in the vehicle's engine description,
+	// so "2.0L" covers "2.0L I4 Turbo".
+	Engine string `json:"engine,omitempty"`
+	// Miles and Months are the interval; whichever comes first applies. At
+	// least one is set.
+	Miles  int `json:"miles,omitempty"`
+	Months int `json:"months,omitempty"`
+	// Match lists phrases that identify the work in quote line items. It
+	// defaults to Name.
+	Match []string `json:"match,omitempty"`
+}
+
+// DueSoon is how close to its interval a service is reported as due.
+type DueSoon struct {
+	Miles int `json:"miles"`
+	Days  int `json:"days"`
+}
+
+// Schedule is a set of maintenance rules loaded from a rules file.
+type Schedule struct {
+	Schema int `json:"schema"`
+	// Version names this revision of the rules and is reported with every
+	// result, so a reminder can be traced to the rules that produced it.
+	Version string  `json:"version"`
+	DueSoon DueSoon `json:"due_soon"`
+	Rules   []Rule  `json:"rules"`
+}
+
+//go:embed schedule.json
+var defaultSchedule []byte
+
+// Default returns the schedule built into the binary.
+func Default() Schedule {
+	s, err := Read(bytes.NewReader(defaultSchedule))
+	if err != nil {
+		panic("maintenance: embedded schedule: " + err.Error())
+	}
+	return s
+}
+
+// LoadFile reads a schedule from a rules file.
+func LoadFile(path string) (Schedule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Schedule{}, err
+	}
+	defer f.Close()
+
+	s, err := Read(f)
+	if err != nil {
+		return Schedule{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Read parses and validates a schedule. Unknown fields are rejected so that
+// a misspelt key does not silently drop a condition from a rule.
+func Read(r io.Reader) (Schedule, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var s Schedule
+	if err := dec.Decode(&s); err != nil {
+		return Schedule{}, fmt.Errorf("%w: %w", ErrInvalidSchedule, err)
+	}
+	if err := s.validate(); err != nil {
+		return Schedule{}, err
+	}
+	return s, nil
+}
+
+func (s *Schedule) validate() error {
+	switch {
+	case s.Schema != SchemaVersion:
+		return fmt.Errorf("%w: schema %d is not supported, expected %d", ErrInvalidSchedule, s.Schema, SchemaVersion)
+	case strings.TrimSpace(s.Version) == "":
+		return fmt.Errorf("%w: version is required", ErrInvalidSchedule)
+	case s.DueSoon.Miles < 0 || s.DueSoon.Days < 0:
+		return fmt.Errorf("%w: due_soon must not be negative", ErrInvalidSchedule)
+	}
+	for i := range s.Rules {
+		r := &s.Rules[i]
+		r.Service = strings.TrimSpace(r.Service)
+		r.Name = strings.TrimSpace(r.Name)
+		switch {
+		case r.Service == "" || r.Name == "":
+			return fmt.Errorf("%w: rule %d: service and name are required", ErrInvalidSchedule, i+1)
+		case r.Miles < 0 || r.Months < 0:
+			return fmt.Errorf("%w: rule %d (%s): interval must not be negative", ErrInvalidSchedule, i+1, r.Service)
+		case r.Miles == 0 && r.Months == 0:
+			return fmt.Errorf("%w: rule %d (%s): miles or months is required", ErrInvalidSchedule, i+1, r.Service)
+		}
+		if len(r.Match) == 0 {
+			r.Match = []string{r.Name}
+		}
+		for j, m := range r.Match {
+			r.Match[j] = strings.ToLower(strings.TrimSpace(m))
+			if r.Match[j] == "" {
+				return fmt.Errorf("%w: rule %d (%s): empty match phrase", ErrInvalidSchedule, i+1, r.Service)
+			}
+		}
+	}
+	return nil
+}
+
+// RulesFor returns the rules that apply to a vehicle, one per service in
+// file order. Of the rules for a service, the one naming the most of make,
+// model and engine wins, and the earlier one wins a tie.
+func (s Schedule) RulesFor(v vehicles.Vehicle) []Rule {
+	var (
+		out   []Rule
+		score = map[string]int{}
+		index = map[string]int{}
+	)
+	for _, r := range s.Rules {
+		n, ok := r.applies(v)
+		if !ok {
+			continue
+		}
+		i, seen := index[r.Service]
+		switch {
+		case !seen:
+			index[r.Service] = len(out)
+			score[r.Service] = n
+			out = append(out, r)
+		case n > score[r.Service]:
+			score[r.Service] = n
+			out[i] = r
+		}
+	}
+	return out
+}
+
+// applies reports whether the rule covers the vehicle and how many of its
+// conditions it names.
+func (r Rule) applies(v vehicles.Vehicle) (int, bool) {
+	n := 0
+	if r.Make != "" {
+		if !strings.EqualFold(r.Make, strings.TrimSpace(v.Make)) {
+			return 0, false
+		}
+		n++
+	}
+	if r.Model != "" {
+		if !strings.EqualFold(r.Model, strings.TrimSpace(v.Model)) {
+			return 0, false
+		}
+		n++
+	}
+	if r.Engine != "" {
+		if !strings.Contains(strings.ToLower(v.Engine), strings.ToLower(r.Engine)) {
+			return 0, false
+		}
+		n++
+	}
+	return n, true
+}
+
+// matches reports whether a line item description is work covered by the
+// rule.
+func (r Rule) matches(description string) bool {
+	d := strings.ToLower(description)
+	for _, m := range r.Match {
+		if strings.Contains(d, m) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+// Package messaging sends texts, emails and calls to customers. Every sender
+// is wrapped by RequireConsent so nothing reaches a customer without the
+// effective consent in the customer's ledger, and by Record so every message
+// sent appears on the customer's timeline.
+package messaging
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"log/slog"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+)
+
+// Message is one outbound message. Purpose decides which consent applies:
+// reminders about the customer's own vehicle are transactional, promotions are
+// marketing.
+type Message struct {
+	CustomerID string
+	Channel    customers.Channel
+	Purpose    customers.Purpose
+	// To is the phone number or email address.
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers messages.
+type Sender interface {
+	Send(m Message) error
+}
+
+// ConsentChecker reports whether a message may be sent; customers.Service
+// implements it.
+type ConsentChecker interface {
+	CheckConsent(customerID string, channel customers.Channel, purpose customers.Purpose) error
+}
+
+// RequireConsent returns a Sender that checks consent before passing messages
+// to next. Messages without consent fail with customers.ErrNoConsent.
+func RequireConsent(next Sender, checker ConsentChecker) Sender {
+	return consentGate{next: next, checker: checker}
+}
+
+type consentGate struct {
+	next    Sender
+	checker ConsentChecker
+}
+
+func (g consentGate) Send(m Message) error {
+	if m.CustomerID == "" {
+		return fmt.Errorf("messaging: message to %q has no customer", m.To)
+	}
+	if err := g.checker.CheckConsent(m.CustomerID, m.Channel, m.Purpose); err != nil {
+		return fmt.Errorf("messaging: %s %s message to customer %s: %w", m.Purpose, m.Channel, m.CustomerID, err)
+	}
+	return g.next.Send(m)
+}
+
+// ErrNotRecorded is returned by senders from Record when a message was
+// delivered but could not be noted on the customer. Callers must not resend
+// it.
+var ErrNotRecorded = errors.New("messaging: message sent but not recorded")
+
+// NoteRecorder stores a note about a customer; customers.Service implements
+// it.
+type NoteRecorder interface {
+	AddNote(customerID string, input customers.NoteInput) (customers.Note, error)
+}
+
+// Record returns a Sender that passes messages to next and notes each one
+// delivered on the customer, authored by "system".
+func Record(next Sender, notes NoteRecorder) Sender {
+	return noteRecorder{next: next, notes: notes}
+}
+
+type noteRecorder struct {
+	next  Sender
+	notes NoteRecorder
+}
+
+func (r noteRecorder) Send(m Message) error {
+	if err := r.next.Send(m); err != nil {
+		return err
+	}
+	kind := customers.NoteKindSMS
+	switch m.Channel {
+	case customers.ChannelEmail:
+		kind = customers.NoteKindEmail
+	case customers.ChannelVoice:
+		kind = customers.NoteKindCall
+	}
+	body := strings.TrimSpace(strings.TrimSpace(m.Subject) + "\n\n" + strings.TrimSpace(m.Body))
+	if utf8.RuneCountInString(body) > customers.MaxNoteLength {
+		body = string([]rune(body)[:customers.MaxNoteLength])
+	}
+	if body == "" {
+		body = fmt.Sprintf("%s %s message", m.Purpose, m.Channel)
+	}
+	if _, err := r.notes.AddNote(m.CustomerID, customers.NoteInput{Kind: kind, Body: body, Author: "system"}); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotRecorded, err)
+	}
+	return nil
+}
+
+// LogSender logs messages instead of delivering them. It stands in until a
+// provider such as Twilio is wired up.
+type LogSender struct {
+	Logger *slog.Logger
+}
+
+// Send logs the message.
+func (s LogSender) Send(m Message) error {
+	s.Logger.Info("outbound_message",
+		"customer_id", m.CustomerID,
+		"channel", m.Channel,
+		"purpose", m.Purpose,
+		"to", m.To,
+		"subject", m.Subject,
+	)
+	return nil
+}
@@ -0,0 +1,82 @@
+package messaging_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/messaging"
+	"github.com/ezmobilemechanic/platform/internal/storage/memory"
+)
+
+type recorder struct {
+	sent []messaging.Message
+}
+
+func (r *recorder) Send(m messaging.Message) error {
+	r.sent = append(r.sent, m)
+	return nil
+}
+
+func TestRequireConsent(t *testing.T) {
+	repo := memory.NewCustomerRepository()
+	svc := customers.NewService(repo, customers.WithConsents(repo.Consents()))
+	c, err := svc.Create(customers.CreateInput{FirstName: "Alex", Phone: "904-555-0101"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	next := &recorder{}
+	sender := messaging.RequireConsent(next, svc)
+	reminder := messaging.Message{CustomerID: c.ID, Channel: customers.ChannelSMS, Purpose: customers.PurposeTransactional, To: c.PhoneE164}
+	promo := messaging.Message{CustomerID: c.ID, Channel: customers.ChannelSMS, Purpose: customers.PurposeMarketing, To: c.PhoneE164}
+
+	if err := sender.Send(reminder); err != nil {
+		t.Fatalf("transactional text without entries should be allowed: %v", err)
+	}
+	if err := sender.Send(promo); !errors.Is(err, customers.ErrNoConsent) {
+		t.Fatalf("marketing text without consent: expected ErrNoConsent, got %v", err)
+	}
+
+	if _, err := svc.ApplyKeyword(c.ID, "stop"); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if err := sender.Send(reminder); !errors.Is(err, customers.ErrNoConsent) {
+		t.Fatalf("text after STOP: expected ErrNoConsent, got %v", err)
+	}
+	if err := sender.Send(messaging.Message{Channel: customers.ChannelSMS, Purpose: customers.PurposeTransactional}); err == nil {
+		t.Fatal("expected error for message without customer")
+	}
+
+	if len(next.sent) != 1 || next.sent[0] != reminder {
+		t.Fatalf("expected only the first reminder to be delivered, got %+v", next.sent)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	repo := memory.NewCustomerRepository()
+	svc := customers.NewService(repo, customers.WithNotes(repo.Notes()))
+	c, err := svc.Create(customers.CreateInput{FirstName: "Alex", Email: "alex@example.com"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	next := &recorder{}
+	sender := messaging.Record(next, svc)
+	if err := sender.Send(messaging.Message{CustomerID: c.ID, Channel: customers.ChannelEmail, Purpose: customers.PurposeTransactional, To: c.Email, Subject: "Oil change due", Body: "Book at example.com"}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	notes, err := svc.Notes(c.ID)
+	if err != nil {
+		t.Fatalf("notes: %v", err)
+	}
+	if len(next.sent) != 1 || len(notes) != 1 || notes[0].Kind != customers.NoteKindEmail ||
+		notes[0].Body != "Oil change due\n\nBook at example.com" || notes[0].Author != "system" {
+		t.Fatalf("expected the email to be sent and noted, got %+v", notes)
+	}
+
+	err = sender.Send(messaging.Message{CustomerID: "missing", Channel: customers.ChannelSMS, Purpose: customers.PurposeTransactional, Body: "hi"})
+	if !errors.Is(err, messaging.ErrNotRecorded) || len(next.sent) != 2 {
+		t.Fatalf("expected a sent but unrecorded message, got %v", err)
+	}
+}
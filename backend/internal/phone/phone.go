@@ -0,0 +1,184 @@
+// Package phone normalizes phone numbers to E.164 so numbers typed by staff,
+// submitted through the website and reported as caller IDs by Twilio compare
+// equal. It mirrors lib/utils/PhoneNormalizer.php on the PHP side but
+// validates NANP numbers strictly and supports regions other than the US.
+package phone
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultRegion is the region assumed for numbers written without a country
+// code.
+const DefaultRegion = "US"
+
+// Errors returned by Parse.
+var (
+	ErrInvalid       = errors.New("invalid phone number")
+	ErrUnknownRegion = errors.New("unsupported phone region")
+)
+
+// Number is a parsed phone number.
+type Number struct {
+	// E164 is the canonical form, e.g. "+19045550101".
+	E164 string
+	// Display is the form shown to staff, e.g. "(904) 555-0101". Numbers from
+	// outside the default region are displayed internationally.
+	Display string
+}
+
+// region describes the national numbering plan of an ISO 3166 region.
+type region struct {
+	code  string // country calling code
+	trunk string // national trunk prefix dialled before the number
+	// lengths lists the valid national significant number lengths.
+	lengths []int
+}
+
+// nanp is shared by every member of the North American Numbering Plan.
+var nanp = region{code: "1", trunk: "1", lengths: []int{10}}
+
+var regions = map[string]region{
+	"US": nanp,
+	"CA": nanp,
+	"PR": nanp,
+	"VI": nanp,
+	"GU": nanp,
+	"MX": {code: "52", lengths: []int{10}},
+	"GB": {code: "44", trunk: "0", lengths: []int{9, 10}},
+	"AU": {code: "61", trunk: "0", lengths: []int{9}},
+	"DE": {code: "49", trunk: "0", lengths: []int{6, 7, 8, 9, 10, 11}},
+}
+
+// SupportedRegion reports whether region can be used as a default region.
+func SupportedRegion(r string) bool {
+	_, ok := regions[strings.ToUpper(r)]
+	return ok
+}
+
+// CallingCode returns the country calling code of region, such as "1" for
+// the US, or "" for an unsupported region.
+func CallingCode(r string) string {
+	return regions[strings.ToUpper(r)].code
+}
+
+// Parse normalizes raw, interpreting numbers without a country code as
+// national numbers of defaultRegion. International numbers are written with
+// a leading "+" or an international prefix ("00", or "011" in NANP regions).
+// Letters, including extensions, are rejected.
+func Parse(raw, defaultRegion string) (Number, error) {
+	home, ok := regions[strings.ToUpper(defaultRegion)]
+	if !ok {
+		return Number{}, fmt.Errorf("%w: %q", ErrUnknownRegion, defaultRegion)
+	}
+
+	s := strings.TrimSpace(raw)
+	international := strings.HasPrefix(s, "+")
+	digits, ok := digitsOf(strings.TrimPrefix(s, "+"))
+	if !ok || digits == "" {
+		return Number{}, fmt.Errorf("%w: %q", ErrInvalid, raw)
+	}
+
+	if !international {
+		switch {
+		case home.code == nanp.code && strings.HasPrefix(digits, "011"):
+			digits, international = digits[3:], true
+		case strings.HasPrefix(digits, "00"):
+			digits, international = digits[2:], true
+		}
+	}
+
+	var e164 string
+	if international {
+		if len(digits) < 8 || len(digits) > 15 {
+			return Number{}, fmt.Errorf("%w: %q", ErrInvalid, raw)
+		}
+		// Numbers in a plan we know are held to its rules.
+		if r, national, ok := lookup(digits); ok && !r.valid(national) {
+			return Number{}, fmt.Errorf("%w: %q", ErrInvalid, raw)
+		}
+		e164 = "+" + digits
+	} else {
+		national := digits
+		if home.trunk != "" && !home.valid(national) && strings.HasPrefix(national, home.trunk) {
+			national = strings.TrimPrefix(national, home.trunk)
+		}
+		if !home.valid(national) {
+			return Number{}, fmt.Errorf("%w: %q", ErrInvalid, raw)
+		}
+		e164 = "+" + home.code + national
+	}
+
+	return Number{E164: e164, Display: display(e164, home)}, nil
+}
+
+// Normalize returns the E.164 form of raw, or "" if it cannot be parsed.
+func Normalize(raw, defaultRegion string) string {
+	n, err := Parse(raw, defaultRegion)
+	if err != nil {
+		return ""
+	}
+	return n.E164
+}
+
+func (r region) valid(national string) bool {
+	ok := false
+	for _, n := range r.lengths {
+		if len(national) == n {
+			ok = true
+		}
+	}
+	if !ok {
+		return false
+	}
+	if r.code == nanp.code {
+		// Area codes and exchanges never start with 0 or 1.
+		return national[0] >= '2' && national[3] >= '2'
+	}
+	return national[0] != '0'
+}
+
+// lookup finds the known region whose calling code prefixes digits.
+func lookup(digits string) (region, string, bool) {
+	for _, r := range regions {
+		if strings.HasPrefix(digits, r.code) {
+			return r, digits[len(r.code):], true
+		}
+	}
+	return region{}, "", false
+}
+
+// display formats NANP numbers in the familiar national style when home is a
+// NANP region and everything else as "+<code> <national number>".
+func display(e164 string, home region) string {
+	digits := e164[1:]
+	r, national, ok := lookup(digits)
+	if !ok {
+		return e164
+	}
+	if r.code == nanp.code && home.code == nanp.code {
+		return fmt.Sprintf("(%s) %s-%s", national[:3], national[3:6], national[6:])
+	}
+	if r.code == nanp.code {
+		return fmt.Sprintf("+1 %s-%s-%s", national[:3], national[3:6], national[6:])
+	}
+	return "+" + r.code + " " + national
+}
+
+// digitsOf strips the punctuation people type in phone numbers and reports
+// false if anything else is present.
+func digitsOf(s string) (string, bool) {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case strings.ContainsRune(" -().\t", r):
+		default:
+			return "", false
+		}
+	}
+	return b.String(), true
+}
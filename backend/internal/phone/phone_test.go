@@ -0,0 +1,64 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		raw, region   string
+		e164, display string
+	}{
+		{"904-555-0101", "US", "+19045550101", "(904) 555-0101"},
+		{"(904) 555.0101", "US", "+19045550101", "(904) 555-0101"},
+		{"1 904 555 0101", "US", "+19045550101", "(904) 555-0101"},
+		{"+1 (904) 555-0101", "us", "+19045550101", "(904) 555-0101"},
+		{"011 44 20 7946 0000", "US", "+442079460000", "+44 2079460000"},
+		{"+52 55 1234 5678", "US", "+525512345678", "+52 5512345678"},
+		{"+33 1 23 45 67 89", "US", "+33123456789", "+33123456789"},
+		{"020 7946 0000", "GB", "+442079460000", "+44 2079460000"},
+		{"+1 416 555 0199", "GB", "+14165550199", "+1 416-555-0199"},
+		{"0412 345 678", "AU", "+61412345678", "+61 412345678"},
+	} {
+		n, err := Parse(tc.raw, tc.region)
+		if err != nil {
+			t.Errorf("Parse(%q, %s): %v", tc.raw, tc.region, err)
+			continue
+		}
+		if n.E164 != tc.e164 || n.Display != tc.display {
+			t.Errorf("Parse(%q, %s) = %+v, want %s / %s", tc.raw, tc.region, n, tc.e164, tc.display)
+		}
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	for _, raw := range []string{
+		"",
+		"555-0101",
+		"904-555-01011",
+		"104-555-0101",
+		"904-155-0101",
+		"+1 904 555 010",
+		"904-555-0101 x12",
+		"call me",
+		"+123",
+	} {
+		if _, err := Parse(raw, DefaultRegion); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Parse(%q): expected ErrInvalid, got %v", raw, err)
+		}
+	}
+
+	if _, err := Parse("904-555-0101", "XX"); !errors.Is(err, ErrUnknownRegion) {
+		t.Errorf("expected ErrUnknownRegion, got %v", err)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if got := Normalize("904-555-0101", DefaultRegion); got != "+19045550101" {
+		t.Fatalf("Normalize = %q", got)
+	}
+	if got := Normalize("bogus", DefaultRegion); got != "" {
+		t.Fatalf("Normalize(bogus) = %q, want empty", got)
+	}
+}
@@ -0,0 +1,359 @@
+// Package pii encrypts personal data before it is written to the database, so
+// that backups and database dumps do not expose it.
+//
+// Values are sealed with envelope encryption: each value gets a fresh data
+// key, the data key is wrapped with a versioned key-encryption key, and both
+// are stored together. Rotating to a new key version only requires rewrapping
+// rows, which the encrypt-pii command does. Encrypted values are random, so
+// exact-match lookups go through blind indexes: keyed HMACs of the normalized
+// value, stored alongside it.
+package pii
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidKey is returned for keys that are not KeySize bytes of
+	// base64, or key specs that cannot be parsed.
+	ErrInvalidKey = errors.New("pii: invalid key")
+	// ErrUnknownVersion is returned when decrypting a value sealed with a key
+	// version the keyring does not hold.
+	ErrUnknownVersion = errors.New("pii: unknown key version")
+	// ErrMalformed is returned for encrypted values that are truncated or
+	// fail authentication.
+	ErrMalformed = errors.New("pii: malformed ciphertext")
+)
+
+// KeySize is the size in bytes of key-encryption keys, data keys and the
+// index key (AES-256, HMAC-SHA256).
+const KeySize = 32
+
+// prefix marks encrypted values, followed by the key version and a colon.
+// Anything else is read as plaintext written before encryption was enabled.
+const prefix = "enc:v"
+
+// indexSize is the number of HMAC bytes kept in a blind index. 16 bytes make
+// accidental collisions negligible while leaking less than the full MAC.
+const indexSize = 16
+
+// Field names an encrypted column. It is bound to the ciphertext, so a value
+// copied into another column fails to decrypt, and it separates the blind
+// indexes of different fields.
+type Field string
+
+const (
+	FieldEmail        Field = "customers.email"
+	FieldPhone        Field = "customers.phone"
+	FieldPhoneE164    Field = "customers.phone_e164"
+	FieldAddressLine1 Field = "customer_addresses.line1"
+	FieldAddressLine2 Field = "customer_addresses.line2"
+	FieldContactEmail Field = "fleet_contacts.email"
+	FieldContactPhone Field = "fleet_contacts.phone"
+	FieldContactE164  Field = "fleet_contacts.phone_e164"
+)
+
+// Keyring holds the key-encryption keys by version and the blind index key.
+// New values are sealed with the highest version. A nil Keyring disables
+// encryption: values are stored as given, and blind indexes are the
+// normalized values themselves, so the same lookups work either way.
+type Keyring struct {
+	current  int
+	keys     map[int]cipher.AEAD
+	indexKey []byte
+}
+
+// NewKeyring builds a keyring from raw keys. Versions must be positive.
+func NewKeyring(keys map[int][]byte, indexKey []byte) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: no key-encryption keys", ErrInvalidKey)
+	}
+	if len(indexKey) != KeySize {
+		return nil, fmt.Errorf("%w: index key must be %d bytes", ErrInvalidKey, KeySize)
+	}
+	k := &Keyring{keys: make(map[int]cipher.AEAD, len(keys)), indexKey: append([]byte(nil), indexKey...)}
+	for version, key := range keys {
+		if version <= 0 {
+			return nil, fmt.Errorf("%w: version %d must be positive", ErrInvalidKey, version)
+		}
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("%w: key version %d must be %d bytes", ErrInvalidKey, version, KeySize)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		k.keys[version] = aead
+		if version > k.current {
+			k.current = version
+		}
+	}
+	return k, nil
+}
+
+// ParseKeys reads key-encryption keys as comma-separated version:key pairs,
+// such as "1:<base64>,2:<base64>", and a base64 index key.
+func ParseKeys(spec, indexKey string) (*Keyring, error) {
+	keys := map[int][]byte{}
+	for _, pair := range strings.Split(spec, ",") {
+		v, key, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		version, err := strconv.Atoi(v)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("%w: %q is not version:key", ErrInvalidKey, pair)
+		}
+		if _, dup := keys[version]; dup {
+			return nil, fmt.Errorf("%w: version %d is given twice", ErrInvalidKey, version)
+		}
+		if keys[version], err = decodeKey(key); err != nil {
+			return nil, fmt.Errorf("%w: key version %d is not base64", ErrInvalidKey, version)
+		}
+	}
+	index, err := decodeKey(indexKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: index key is not base64", ErrInvalidKey)
+	}
+	return NewKeyring(keys, index)
+}
+
+// keyFile is the layout of a key file.
+type keyFile struct {
+	// Keys maps versions to base64 keys.
+	Keys     map[string]string `json:"keys"`
+	IndexKey string            `json:"index_key"`
+}
+
+// LoadKeyFile reads a JSON key file:
+//
+//	{"keys": {"1": "<base64>", "2": "<base64>"}, "index_key": "<base64>"}
+func LoadKeyFile(path string) (*Keyring, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read key file: %w", err)
+	}
+	var f keyFile
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, fmt.Errorf("%w: key file: %v", ErrInvalidKey, err)
+	}
+	pairs := make([]string, 0, len(f.Keys))
+	for v, key := range f.Keys {
+		pairs = append(pairs, v+":"+key)
+	}
+	return ParseKeys(strings.Join(pairs, ","), f.IndexKey)
+}
+
+// Load returns the keyring configured inline or in a key file, or nil when
+// neither is set.
+func Load(spec, indexKey, keyFile string) (*Keyring, error) {
+	switch {
+	case keyFile != "":
+		return LoadKeyFile(keyFile)
+	case spec != "":
+		return ParseKeys(spec, indexKey)
+	default:
+		return nil, nil
+	}
+}
+
+// GenerateKey returns a random key, base64 encoded, for use in PII_KEYS,
+// PII_INDEX_KEY or a key file.
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// Enabled reports whether values are encrypted.
+func (k *Keyring) Enabled() bool {
+	return k != nil
+}
+
+// Version returns the key version new values are sealed with, or 0 when
+// encryption is disabled.
+func (k *Keyring) Version() int {
+	if k == nil {
+		return 0
+	}
+	return k.current
+}
+
+// Encrypt seals a value of field with the current key. Blank values stay
+// blank, so "is set" checks keep working on encrypted columns.
+func (k *Keyring) Encrypt(field Field, plaintext string) (string, error) {
+	if k == nil || plaintext == "" {
+		return plaintext, nil
+	}
+	dataKey := make([]byte, KeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("pii: generate data key: %w", err)
+	}
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	kek := k.keys[k.current]
+	aad := additionalData(field, k.current)
+
+	// nonce and wrapped data key, then nonce and sealed value.
+	out := make([]byte, 0, 2*(kek.NonceSize()+kek.Overhead())+KeySize+len(plaintext))
+	out, err = seal(kek, out, dataKey, aad)
+	if err != nil {
+		return "", err
+	}
+	out, err = seal(data, out, []byte(plaintext), aad)
+	if err != nil {
+		return "", err
+	}
+	return prefix + strconv.Itoa(k.current) + ":" + base64.RawURLEncoding.EncodeToString(out), nil
+}
+
+// Decrypt opens a value of field. Values without the encryption prefix were
+// stored before encryption was enabled and are returned unchanged.
+func (k *Keyring) Decrypt(field Field, value string) (string, error) {
+	version, payload, ok := parse(value)
+	if !ok {
+		return value, nil
+	}
+	if k == nil {
+		return "", fmt.Errorf("%w: v%d (no keys configured)", ErrUnknownVersion, version)
+	}
+	kek, ok := k.keys[version]
+	if !ok {
+		return "", fmt.Errorf("%w: v%d", ErrUnknownVersion, version)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", ErrMalformed
+	}
+	aad := additionalData(field, version)
+
+	wrappedLen := kek.NonceSize() + KeySize + kek.Overhead()
+	if len(raw) < wrappedLen {
+		return "", ErrMalformed
+	}
+	dataKey, err := open(kek, raw[:wrappedLen], aad)
+	if err != nil {
+		return "", err
+	}
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(data, raw[wrappedLen:], aad)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// Current reports whether value needs no rewriting: it is blank, or sealed
+// with the current key. Plaintext values are current only when encryption
+// is disabled.
+func (k *Keyring) Current(value string) bool {
+	if value == "" {
+		return true
+	}
+	version, _, ok := parse(value)
+	if k == nil {
+		return !ok
+	}
+	return ok && version == k.current
+}
+
+// Index returns the blind index of an already normalized value: a truncated
+// HMAC keyed with the index key, or the value itself when encryption is
+// disabled. Blank values have a blank index.
+func (k *Keyring) Index(field Field, normalized string) string {
+	if k == nil || normalized == "" {
+		return normalized
+	}
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(normalized))
+	return hex.EncodeToString(mac.Sum(nil)[:indexSize])
+}
+
+// EmailIndex returns the blind index of an email, compared ignoring case.
+func (k *Keyring) EmailIndex(email string) string {
+	return k.Index(FieldEmail, strings.ToLower(strings.TrimSpace(email)))
+}
+
+// PhoneIndex returns the blind index of an E.164 phone number.
+func (k *Keyring) PhoneIndex(e164 string) string {
+	return k.Index(FieldPhoneE164, strings.TrimSpace(e164))
+}
+
+// IsEncrypted reports whether value carries the encryption prefix.
+func IsEncrypted(value string) bool {
+	_, _, ok := parse(value)
+	return ok
+}
+
+func parse(value string) (version int, payload string, ok bool) {
+	rest, found := strings.CutPrefix(value, prefix)
+	if !found {
+		return 0, "", false
+	}
+	v, payload, found := strings.Cut(rest, ":")
+	if !found {
+		return 0, "", false
+	}
+	version, err := strconv.Atoi(v)
+	if err != nil || version <= 0 {
+		return 0, "", false
+	}
+	return version, payload, true
+}
+
+func additionalData(field Field, version int) []byte {
+	return []byte(string(field) + "\x00v" + strconv.Itoa(version))
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal appends a random nonce and the sealed plaintext to dst.
+func seal(aead cipher.AEAD, dst, plaintext, aad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("pii: generate nonce: %w", err)
+	}
+	dst = append(dst, nonce...)
+	return aead.Seal(dst, nonce, plaintext, aad), nil
+}
+
+// open reverses seal.
+func open(aead cipher.AEAD, sealed, aad []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrMalformed
+	}
+	n := aead.NonceSize()
+	plaintext, err := aead.Open(nil, sealed[:n], sealed[n:], aad)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	return plaintext, nil
+}
+
+func decodeKey(s string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+}
@@ -0,0 +1,159 @@
+package pii_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ezmobilemechanic/platform/internal/pii"
+)
+
+func testKey(b byte) []byte { return bytes.Repeat([]byte{b}, pii.KeySize) }
+
+func b64(key []byte) string { return base64.StdEncoding.EncodeToString(key) }
+
+func mustKeyring(t *testing.T, keys map[int][]byte) *pii.Keyring {
+	t.Helper()
+	k, err := pii.NewKeyring(keys, testKey(9))
+	if err != nil {
+		t.Fatalf("keyring: %v", err)
+	}
+	return k
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	k := mustKeyring(t, map[int][]byte{1: testKey(1)})
+
+	sealed, err := k.Encrypt(pii.FieldEmail, "alex@example.com")
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if !strings.HasPrefix(sealed, "enc:v1:") || strings.Contains(sealed, "alex") {
+		t.Fatalf("unexpected ciphertext %q", sealed)
+	}
+	again, _ := k.Encrypt(pii.FieldEmail, "alex@example.com")
+	if again == sealed {
+		t.Fatal("expected a fresh data key and nonce per value")
+	}
+	if got, err := k.Decrypt(pii.FieldEmail, sealed); err != nil || got != "alex@example.com" {
+		t.Fatalf("decrypt: got %q (%v)", got, err)
+	}
+
+	if _, err := k.Decrypt(pii.FieldPhone, sealed); !errors.Is(err, pii.ErrMalformed) {
+		t.Fatalf("expected a value moved to another field to fail, got %v", err)
+	}
+	mid := len(sealed) / 2
+	flip := byte('A')
+	if sealed[mid] == 'A' {
+		flip = 'B'
+	}
+	tampered := sealed[:mid] + string(flip) + sealed[mid+1:]
+	if _, err := k.Decrypt(pii.FieldEmail, tampered); !errors.Is(err, pii.ErrMalformed) {
+		t.Fatalf("expected tampering to be detected, got %v", err)
+	}
+
+	if blank, _ := k.Encrypt(pii.FieldEmail, ""); blank != "" {
+		t.Fatalf("expected blank to stay blank, got %q", blank)
+	}
+	if plain, err := k.Decrypt(pii.FieldEmail, "legacy@example.com"); err != nil || plain != "legacy@example.com" {
+		t.Fatalf("expected plaintext to pass through, got %q (%v)", plain, err)
+	}
+}
+
+func TestRotation(t *testing.T) {
+	old := mustKeyring(t, map[int][]byte{1: testKey(1)})
+	sealed, _ := old.Encrypt(pii.FieldPhone, "(904) 555-0100")
+
+	k := mustKeyring(t, map[int][]byte{1: testKey(1), 2: testKey(2)})
+	if k.Version() != 2 {
+		t.Fatalf("expected the highest version to be current, got %d", k.Version())
+	}
+	if k.Current(sealed) || k.Current("(904) 555-0100") || !k.Current("") {
+		t.Fatal("expected v1 and plaintext values to need rewriting")
+	}
+	if got, err := k.Decrypt(pii.FieldPhone, sealed); err != nil || got != "(904) 555-0100" {
+		t.Fatalf("decrypt old version: got %q (%v)", got, err)
+	}
+	resealed, _ := k.Encrypt(pii.FieldPhone, "(904) 555-0100")
+	if !k.Current(resealed) {
+		t.Fatalf("expected %q to be current", resealed)
+	}
+
+	retired := mustKeyring(t, map[int][]byte{2: testKey(2)})
+	if _, err := retired.Decrypt(pii.FieldPhone, sealed); !errors.Is(err, pii.ErrUnknownVersion) {
+		t.Fatalf("expected ErrUnknownVersion, got %v", err)
+	}
+	var disabled *pii.Keyring
+	if _, err := disabled.Decrypt(pii.FieldPhone, sealed); !errors.Is(err, pii.ErrUnknownVersion) {
+		t.Fatalf("expected ErrUnknownVersion without keys, got %v", err)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	k := mustKeyring(t, map[int][]byte{1: testKey(1)})
+	if k.EmailIndex("Alex@Example.com ") != k.EmailIndex("alex@example.com") {
+		t.Fatal("expected email indexes to ignore case and spaces")
+	}
+	if idx := k.EmailIndex("alex@example.com"); len(idx) != 32 || strings.Contains(idx, "alex") {
+		t.Fatalf("unexpected index %q", idx)
+	}
+	if k.Index(pii.FieldEmail, "x") == k.Index(pii.FieldPhoneE164, "x") {
+		t.Fatal("expected indexes to differ by field")
+	}
+	rotated := mustKeyring(t, map[int][]byte{1: testKey(1), 2: testKey(2)})
+	if rotated.PhoneIndex("+19045550100") != k.PhoneIndex("+19045550100") {
+		t.Fatal("expected indexes to survive key rotation")
+	}
+	if k.EmailIndex("") != "" {
+		t.Fatal("expected a blank index for a blank value")
+	}
+
+	var disabled *pii.Keyring
+	if disabled.Enabled() || disabled.EmailIndex("Alex@Example.com") != "alex@example.com" {
+		t.Fatal("expected the normalized value as index without keys")
+	}
+	if v, _ := disabled.Encrypt(pii.FieldEmail, "alex@example.com"); v != "alex@example.com" {
+		t.Fatalf("expected plaintext without keys, got %q", v)
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	k, err := pii.ParseKeys("1:"+b64(testKey(1))+", 3:"+b64(testKey(3)), b64(testKey(9)))
+	if err != nil || k.Version() != 3 {
+		t.Fatalf("parse: %v (version %d)", err, k.Version())
+	}
+	for _, spec := range []string{"", "1", "x:" + b64(testKey(1)), "1:short", "0:" + b64(testKey(1)), "1:" + b64(testKey(1)) + ",1:" + b64(testKey(2))} {
+		if _, err := pii.ParseKeys(spec, b64(testKey(9))); !errors.Is(err, pii.ErrInvalidKey) {
+			t.Errorf("spec %q: expected ErrInvalidKey, got %v", spec, err)
+		}
+	}
+	if _, err := pii.ParseKeys("1:"+b64(testKey(1)), ""); !errors.Is(err, pii.ErrInvalidKey) {
+		t.Errorf("expected the index key to be required, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "keys.json")
+	file := `{"keys": {"1": "` + b64(testKey(1)) + `", "2": "` + b64(testKey(2)) + `"}, "index_key": "` + b64(testKey(9)) + `"}`
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fromFile, err := pii.LoadKeyFile(path)
+	if err != nil || fromFile.Version() != 2 {
+		t.Fatalf("load key file: %v", err)
+	}
+	sealed, _ := fromFile.Encrypt(pii.FieldEmail, "alex@example.com")
+	if got, err := k.Decrypt(pii.FieldEmail, sealed); !errors.Is(err, pii.ErrUnknownVersion) {
+		t.Fatalf("expected v2 to be unknown to the env keyring, got %q (%v)", got, err)
+	}
+
+	generated, err := pii.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pii.ParseKeys("1:"+generated, generated); err != nil {
+		t.Fatalf("generated key rejected: %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+// Package pricing works out quote totals from the shop's rate card. All
+// amounts are integer cents, and each rounding is to the nearest cent with
+// halves rounded away from zero:
+//
+//   - a line's labor is its hours, counted to the hundredth of an hour,
+//     times its hourly rate;
+//   - the labor subtotal is the sum of the rounded line amounts, so the
+//     lines add up to it;
+//   - a fee's labor percentage is rounded before the fee is capped;
+//   - the discount is capped at parts, labor and fees together, and reduces
+//     the taxable amount in proportion to how much of them is taxed;
+//   - tax is rounded once, on the whole taxable amount, not per line.
+package pricing
+
+import "math"
+
+// Line is a quote line item to price.
+type Line struct {
+	Quantity   int
+	UnitPrice  int64
+	LaborHours float64
+	Category   string
+}
+
+// PricedLine is the labor charged on a line.
+type PricedLine struct {
+	// LaborRate is the hourly rate the line was priced at.
+	LaborRate int64
+	Labor     int64
+}
+
+// Breakdown is a quote's totals. Total is Parts + Labor + Fees - Discount +
+// Tax.
+type Breakdown struct {
+	Parts    int64
+	Labor    int64
+	Fees     int64
+	Discount int64
+	Tax      int64
+	Total    int64
+	// Lines holds the labor of each line, in order.
+	Lines []PricedLine
+}
+
+// Price works out the totals of a quote with lines, whose labor is done by
+// a technician of tier, less a discount. Fees apply only to quotes with
+// lines.
+func (c RateCard) Price(lines []Line, tier string, discount int64) Breakdown {
+	var b Breakdown
+	b.Lines = make([]PricedLine, len(lines))
+	for i, l := range lines {
+		b.Parts += int64(l.Quantity) * l.UnitPrice
+		rate := c.LaborRate(l.Category, tier)
+		labor := roundDiv(int64(math.Round(l.LaborHours*100))*rate, 100)
+		b.Lines[i] = PricedLine{LaborRate: rate, Labor: labor}
+		b.Labor += labor
+	}
+	if len(lines) > 0 {
+		for _, f := range c.Fees {
+			fee := f.Amount + percentOf(b.Labor, f.LaborPercent)
+			if f.Max > 0 && fee > f.Max {
+				fee = f.Max
+			}
+			b.Fees += fee
+		}
+	}
+
+	subtotal := b.Parts + b.Labor + b.Fees
+	b.Discount = max(min(discount, subtotal), 0)
+
+	taxed := b.Parts
+	if c.TaxLabor {
+		taxed += b.Labor
+	}
+	if c.TaxFees {
+		taxed += b.Fees
+	}
+	if b.Discount > 0 {
+		taxed -= roundDiv(b.Discount*taxed, subtotal)
+	}
+	b.Tax = percentOf(max(taxed, 0), c.TaxRate)
+
+	b.Total = subtotal - b.Discount + b.Tax
+	return b
+}
+
+// percentOf returns pct percent of amount. The percentage counts to the
+// ten-thousandth, enough for tax rates such as 6.625.
+func percentOf(amount int64, pct float64) int64 {
+	return roundDiv(amount*int64(math.Round(pct*10000)), 1000000)
+}
+
+// roundDiv divides a by b, which is positive, rounding halves away from
+// zero.
+func roundDiv(a, b int64) int64 {
+	if a < 0 {
+		return -roundDiv(-a, b)
+	}
+	return (a + b/2) / b
+}
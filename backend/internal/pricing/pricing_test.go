@@ -0,0 +1,121 @@
+package pricing_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ezmobilemechanic/platform/internal/pricing"
+)
+
+const testCard = `{
+	"version": "2026.1",
+	"default_rate": 12000,
+	"rates": [
+		{"category": "diagnostics", "hourly": 15000},
+		{"tier": "master", "hourly": 14000},
+		{"category": "diagnostics", "tier": "master", "hourly": 17500}
+	],
+	"fees": [
+		{"name": "Trip charge", "amount": 4900},
+		{"name": "Shop supplies", "labor_percent": 10, "max": 3500}
+	],
+	"tax_rate": 7.5,
+	"tax_fees": true
+}`
+
+func TestLaborRate(t *testing.T) {
+	c, err := pricing.Read(strings.NewReader(testCard))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	for _, tc := range []struct {
+		category, tier string
+		want           int64
+	}{
+		{"", "", 12000},
+		{"brakes", "", 12000},
+		{"Diagnostics", "", 15000},
+		{"brakes", "master", 14000},
+		{"diagnostics", "Master", 17500},
+		{"diagnostics", "apprentice", 15000},
+	} {
+		if got := c.LaborRate(tc.category, tc.tier); got != tc.want {
+			t.Errorf("LaborRate(%q, %q) = %d, want %d", tc.category, tc.tier, got, tc.want)
+		}
+	}
+}
+
+func TestPrice(t *testing.T) {
+	c, err := pricing.Read(strings.NewReader(testCard))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	b := c.Price([]pricing.Line{
+		{Quantity: 1, UnitPrice: 8999, LaborHours: 1.5, Category: "brakes"},
+		{Quantity: 1, LaborHours: 0.35, Category: "diagnostics"},
+		// Hours count to the hundredth: 0.33 h at $120.00.
+		{Quantity: 2, UnitPrice: 6450, LaborHours: 0.333},
+	}, "", 2500)
+
+	want := pricing.Breakdown{
+		Parts: 8999 + 2*6450,
+		Labor: 18000 + 5250 + 3960,
+		// The trip charge plus 10% of labor, under the cap.
+		Fees:     4900 + 2721,
+		Discount: 2500,
+		// Parts and fees come to 29520 of the 56730 subtotal, so 1301 of
+		// the discount comes off them: 7.5% of 28219 is 2116.425.
+		Tax:   2116,
+		Total: 56730 - 2500 + 2116,
+	}
+	if b.Parts != want.Parts || b.Labor != want.Labor || b.Fees != want.Fees || b.Discount != want.Discount || b.Tax != want.Tax || b.Total != want.Total {
+		t.Fatalf("unexpected breakdown:\nwant %+v\n got %+v", want, b)
+	}
+	if len(b.Lines) != 3 || b.Lines[1] != (pricing.PricedLine{LaborRate: 15000, Labor: 5250}) {
+		t.Fatalf("unexpected lines %+v", b.Lines)
+	}
+
+	// A discount larger than the quote leaves nothing to pay or tax.
+	if b := c.Price([]pricing.Line{{Quantity: 1, UnitPrice: 1000}}, "", 1000000); b.Discount != 1000+4900 || b.Tax != 0 || b.Total != 0 {
+		t.Fatalf("expected the discount capped, got %+v", b)
+	}
+	// Quotes without lines have no fees.
+	if b := c.Price(nil, "", 0); b.Fees != 0 || b.Total != 0 {
+		t.Fatalf("expected an empty quote to cost nothing, got %+v", b)
+	}
+}
+
+func TestPriceRoundsHalvesUp(t *testing.T) {
+	c := pricing.RateCard{
+		DefaultRate: 12345,
+		Fees:        []pricing.Fee{{Name: "Shop supplies", LaborPercent: 10, Max: 500}},
+		TaxRate:     6.625,
+		TaxLabor:    true,
+	}
+	b := c.Price([]pricing.Line{{Quantity: 1, UnitPrice: 15, LaborHours: 0.5}}, "", 0)
+	// Half an hour at $123.45 is $61.725; 6.625% of $61.88 is $4.09955.
+	if b.Labor != 6173 || b.Fees != 500 || b.Tax != 410 || b.Total != 15+6173+500+410 {
+		t.Fatalf("unexpected breakdown %+v", b)
+	}
+
+	// The zero rate card charges for parts only.
+	var zero pricing.RateCard
+	if b := zero.Price([]pricing.Line{{Quantity: 2, UnitPrice: 999, LaborHours: 3}}, "master", 0); b.Labor != 0 || b.Total != 1998 {
+		t.Fatalf("expected parts only, got %+v", b)
+	}
+}
+
+func TestReadRejectsBadCards(t *testing.T) {
+	for name, card := range map[string]string{
+		"unknown field":  `{"default_rate": 12000, "labour_rate": 1}`,
+		"duplicate rate": `{"default_rate": 12000, "rates": [{"tier": "master", "hourly": 1}, {"tier": "MASTER", "hourly": 2}]}`,
+		"rate for all":   `{"default_rate": 12000, "rates": [{"hourly": 1}]}`,
+		"negative fee":   `{"default_rate": 12000, "fees": [{"name": "Trip", "amount": -100}]}`,
+		"tax rate":       `{"default_rate": 12000, "tax_rate": 150}`,
+	} {
+		if _, err := pricing.Read(strings.NewReader(card)); !errors.Is(err, pricing.ErrInvalidRateCard) {
+			t.Errorf("%s: expected ErrInvalidRateCard, got %v", name, err)
+		}
+	}
+}
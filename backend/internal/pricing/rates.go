@@ -0,0 +1,158 @@
+package pricing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+// ErrInvalidRateCard is returned for rate card files that cannot be used.
+var ErrInvalidRateCard = errors.New("invalid rate card")
+
+// Rate is an hourly labor rate for a service category, a technician tier or
+// both. A rate naming both takes precedence over one naming only the
+// category, which takes precedence over one naming only the tier.
+type Rate struct {
+	// Category matches the category of a quote line item, such as
+	// "diagnostics" or "electrical".
+	Category string `json:"category,omitempty"`
+	// Tier matches the technician tier a quote is priced for, such as
+	// "master".
+	Tier string `json:"tier,omitempty"`
+	// Hourly is the rate in cents per hour.
+	Hourly int64 `json:"hourly"`
+}
+
+// Fee is a charge added to every quote with line items. It is Amount plus
+// LaborPercent of the labor subtotal, capped at Max when Max is set, so a
+// trip charge sets Amount and a shop supplies charge sets LaborPercent.
+type Fee struct {
+	Name         string  `json:"name"`
+	Amount       int64   `json:"amount,omitempty"`
+	LaborPercent float64 `json:"labor_percent,omitempty"`
+	Max          int64   `json:"max,omitempty"`
+}
+
+// RateCard is the shop's labor rates, fees and sales tax, loaded from a
+// rate card file. Amounts are in cents. The zero RateCard prices labor at
+// nothing and charges no fees or tax.
+type RateCard struct {
+	// Version names this revision of the rates and is logged at startup.
+	Version string `json:"version,omitempty"`
+	// DefaultRate is the shop rate in cents per hour, used when no Rate
+	// matches.
+	DefaultRate int64  `json:"default_rate"`
+	Rates       []Rate `json:"rates,omitempty"`
+	Fees        []Fee  `json:"fees,omitempty"`
+	// TaxRate is the sales tax rate in percent, such as 7.5. Parts are
+	// always taxed; labor and fees only when TaxLabor and TaxFees say so.
+	TaxRate  float64 `json:"tax_rate,omitempty"`
+	TaxLabor bool    `json:"tax_labor,omitempty"`
+	TaxFees  bool    `json:"tax_fees,omitempty"`
+}
+
+// LoadFile reads a rate card from a JSON file.
+func LoadFile(path string) (RateCard, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return RateCard{}, err
+	}
+	defer f.Close()
+
+	c, err := Read(f)
+	if err != nil {
+		return RateCard{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Read parses and validates a JSON rate card. Unknown fields are rejected
+// so that a misspelt key does not silently drop a rate.
+func Read(r io.Reader) (RateCard, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var c RateCard
+	if err := dec.Decode(&c); err != nil {
+		return RateCard{}, fmt.Errorf("%w: %w", ErrInvalidRateCard, err)
+	}
+	if err := c.validate(); err != nil {
+		return RateCard{}, err
+	}
+	return c, nil
+}
+
+func (c *RateCard) validate() error {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %s", ErrInvalidRateCard, fmt.Sprintf(format, args...))
+	}
+	if c.DefaultRate < 0 {
+		return invalid("default_rate must not be negative")
+	}
+	seen := map[string]bool{}
+	for i := range c.Rates {
+		r := &c.Rates[i]
+		r.Category = strings.TrimSpace(r.Category)
+		r.Tier = strings.TrimSpace(r.Tier)
+		switch {
+		case r.Category == "" && r.Tier == "":
+			return invalid("rate %d: category or tier is required", i+1)
+		case r.Hourly < 0:
+			return invalid("rate %d: hourly must not be negative", i+1)
+		}
+		key := rateKey(r.Category, r.Tier)
+		if seen[key] {
+			return invalid("rate %d: duplicate rate for category %q and tier %q", i+1, r.Category, r.Tier)
+		}
+		seen[key] = true
+	}
+	for i := range c.Fees {
+		f := &c.Fees[i]
+		f.Name = strings.TrimSpace(f.Name)
+		switch {
+		case f.Name == "":
+			return invalid("fee %d: name is required", i+1)
+		case f.Amount < 0 || f.Max < 0:
+			return invalid("fee %q: amounts must not be negative", f.Name)
+		case f.LaborPercent < 0 || f.LaborPercent > 100:
+			return invalid("fee %q: labor_percent must be between 0 and 100", f.Name)
+		}
+	}
+	if c.TaxRate < 0 || c.TaxRate > 100 || math.IsNaN(c.TaxRate) {
+		return invalid("tax_rate must be between 0 and 100")
+	}
+	return nil
+}
+
+// LaborRate returns the hourly rate in cents for work in category done by a
+// technician of tier. Categories and tiers match ignoring case.
+func (c RateCard) LaborRate(category, tier string) int64 {
+	category, tier = strings.TrimSpace(category), strings.TrimSpace(tier)
+	best, rank := c.DefaultRate, 0
+	for _, r := range c.Rates {
+		if r.Category != "" && !strings.EqualFold(r.Category, category) {
+			continue
+		}
+		if r.Tier != "" && !strings.EqualFold(r.Tier, tier) {
+			continue
+		}
+		n := 1
+		if r.Category != "" {
+			n += 2
+		}
+		if r.Tier != "" {
+			n++
+		}
+		if n > rank {
+			best, rank = r.Hourly, n
+		}
+	}
+	return best
+}
+
+func rateKey(category, tier string) string {
+	return strings.ToLower(category) + "|" + strings.ToLower(tier)
+}
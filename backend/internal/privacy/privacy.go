@@ -0,0 +1,403 @@
+// Package privacy answers data subject requests under GDPR and CPRA: access,
+// by assembling everything held about a customer into one archive, and
+// erasure, through tracked requests that anonymize the customer once staff
+// complete them.
+package privacy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
+	"github.com/ezmobilemechanic/platform/internal/domain/quotes"
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+)
+
+var (
+	ErrNotImplemented = errors.New("erasure repository: not implemented")
+	ErrNotFound       = errors.New("erasure request not found")
+	ErrInvalid        = errors.New("invalid erasure request")
+	// ErrOpenRequest is returned when the customer already has a pending
+	// erasure request.
+	ErrOpenRequest = errors.New("customer already has a pending erasure request")
+	// ErrClosed is returned when completing or rejecting a request that is
+	// no longer pending.
+	ErrClosed = errors.New("erasure request is closed")
+)
+
+// ResponseWindow is how long after a request the erasure is due. GDPR allows
+// one month and CPRA 45 days; the stricter applies.
+const ResponseWindow = 30 * 24 * time.Hour
+
+// Status is the state of an erasure request.
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusCompleted Status = "completed"
+	StatusRejected  Status = "rejected"
+)
+
+// Statuses lists every status.
+var Statuses = []Status{StatusPending, StatusCompleted, StatusRejected}
+
+// Actions recorded in an erasure request's audit trail.
+const (
+	ActionRequested = "requested"
+	ActionCompleted = "completed"
+	ActionRejected  = "rejected"
+)
+
+// Event is one entry in an erasure request's audit trail.
+type Event struct {
+	At     time.Time
+	Action string
+	// Actor is the staff member who acted.
+	Actor  string
+	Detail string
+}
+
+// ErasureRequest tracks a customer's request to have their data erased.
+// Requests outlive the customer's personal data, and the customer record
+// itself if it is later purged, as proof the request was honoured.
+type ErasureRequest struct {
+	ID         string
+	CustomerID string
+	Status     Status
+	// RequestedBy is the staff member who logged the request, and Reason how
+	// it was received.
+	RequestedBy string
+	Reason      string
+	RequestedAt time.Time
+	DueAt       time.Time
+	// ClosedAt is set once the request is completed or rejected.
+	ClosedAt *time.Time
+	// Events is the audit trail, oldest first. Entries are only appended.
+	Events []Event
+}
+
+// Repository stores erasure requests. Save inserts requests without an ID,
+// assigning one, and replaces the others. Lists are ordered by RequestedAt,
+// then ID; List returns every request when status is empty.
+type Repository interface {
+	FindByID(id string) (ErasureRequest, error)
+	Save(req ErasureRequest) (ErasureRequest, error)
+	List(status Status) ([]ErasureRequest, error)
+	ListByCustomer(customerID string) ([]ErasureRequest, error)
+}
+
+// NullRepository returns ErrNotImplemented for all operations.
+type NullRepository struct{}
+
+func (NullRepository) FindByID(id string) (ErasureRequest, error) {
+	return ErasureRequest{}, ErrNotImplemented
+}
+
+func (NullRepository) Save(req ErasureRequest) (ErasureRequest, error) {
+	return ErasureRequest{}, ErrNotImplemented
+}
+
+func (NullRepository) List(status Status) ([]ErasureRequest, error) {
+	return nil, ErrNotImplemented
+}
+
+func (NullRepository) ListByCustomer(customerID string) ([]ErasureRequest, error) {
+	return nil, ErrNotImplemented
+}
+
+// QuoteRecord is a quote with its status history.
+type QuoteRecord struct {
+	Quote         quotes.Quote
+	StatusHistory []quotes.StatusChange
+}
+
+// Archive is everything held about a customer.
+type Archive struct {
+	GeneratedAt time.Time
+	Customer    customers.Customer
+	Addresses   []customers.Address
+	// Contacts are the people on a fleet account.
+	Contacts []customers.Contact
+	Vehicles []vehicles.Vehicle
+	Quotes   []QuoteRecord
+	// Communications are logged calls, texts and emails; Notes are staff
+	// notes.
+	Communications  []customers.Note
+	Notes           []customers.Note
+	Consent         []customers.ConsentEntry
+	ErasureRequests []ErasureRequest
+}
+
+// RequestInput logs an erasure request.
+type RequestInput struct {
+	RequestedBy string
+	Reason      string
+}
+
+// Service handles data subject requests.
+type Service interface {
+	// Export assembles the customer's archive. Merged customer IDs resolve to
+	// the survivor.
+	Export(customerID string) (Archive, error)
+
+	// RequestErasure opens a pending erasure request.
+	RequestErasure(customerID string, input RequestInput) (ErasureRequest, error)
+	Erasure(id string) (ErasureRequest, error)
+	// Erasures lists requests with the status, or all when it is empty.
+	Erasures(status Status) ([]ErasureRequest, error)
+	// CompleteErasure anonymizes the customer and closes the request.
+	CompleteErasure(id, actor string) (ErasureRequest, error)
+	// RejectErasure closes the request without erasing anything, for
+	// instance when the requester's identity could not be verified.
+	RejectErasure(id, actor, reason string) (ErasureRequest, error)
+}
+
+// NewService builds a privacy service over the domain services.
+func NewService(repo Repository, c customers.Service, v vehicles.Service, q quotes.Service) Service {
+	return &service{repo: repo, customers: c, vehicles: v, quotes: q}
+}
+
+type service struct {
+	repo      Repository
+	customers customers.Service
+	vehicles  vehicles.Service
+	quotes    quotes.Service
+}
+
+func (s *service) Export(customerID string) (Archive, error) {
+	c, err := s.customers.Get(customerID)
+	if err != nil {
+		return Archive{}, err
+	}
+	a := Archive{GeneratedAt: time.Now().UTC(), Customer: c}
+
+	if a.Addresses, err = s.customers.Addresses(c.ID); err != nil {
+		return Archive{}, err
+	}
+	if c.IsFleet() {
+		if a.Contacts, err = s.customers.Contacts(c.ID); err != nil {
+			return Archive{}, err
+		}
+	}
+	if a.Vehicles, err = s.vehicles.ListForCustomer(c.ID, true); err != nil {
+		return Archive{}, err
+	}
+	if a.Quotes, err = s.quoteRecords(c.ID); err != nil {
+		return Archive{}, err
+	}
+	notes, err := s.customers.Notes(c.ID)
+	if err != nil {
+		return Archive{}, err
+	}
+	for _, n := range notes {
+		if n.Kind.IsCommunication() {
+			a.Communications = append(a.Communications, n)
+		} else {
+			a.Notes = append(a.Notes, n)
+		}
+	}
+	if a.Consent, err = s.customers.ConsentHistory(c.ID); err != nil {
+		return Archive{}, err
+	}
+	if a.ErasureRequests, err = s.repo.ListByCustomer(c.ID); err != nil {
+		return Archive{}, err
+	}
+	return a.withEmptyLists(), nil
+}
+
+// withEmptyLists replaces nil lists so the archive shows [] for sections with
+// nothing in them.
+func (a Archive) withEmptyLists() Archive {
+	if a.Addresses == nil {
+		a.Addresses = []customers.Address{}
+	}
+	if a.Contacts == nil {
+		a.Contacts = []customers.Contact{}
+	}
+	if a.Vehicles == nil {
+		a.Vehicles = []vehicles.Vehicle{}
+	}
+	if a.Quotes == nil {
+		a.Quotes = []QuoteRecord{}
+	}
+	if a.Communications == nil {
+		a.Communications = []customers.Note{}
+	}
+	if a.Notes == nil {
+		a.Notes = []customers.Note{}
+	}
+	if a.Consent == nil {
+		a.Consent = []customers.ConsentEntry{}
+	}
+	if a.ErasureRequests == nil {
+		a.ErasureRequests = []ErasureRequest{}
+	}
+	return a
+}
+
+func (s *service) quoteRecords(customerID string) ([]QuoteRecord, error) {
+	var records []QuoteRecord
+	spec := listing.Spec{Limit: listing.MaxLimit, Sort: listing.Sort{Field: listing.SortCreatedAt}}
+	for {
+		page, err := s.quotes.ListForCustomer(customerID, spec)
+		if err != nil {
+			return nil, err
+		}
+		for _, q := range page.Items {
+			history, err := s.quotes.StatusHistory(q.ID)
+			if err != nil && !errors.Is(err, quotes.ErrNotImplemented) {
+				return nil, err
+			}
+			records = append(records, QuoteRecord{Quote: q, StatusHistory: history})
+		}
+		if !page.HasMore {
+			return records, nil
+		}
+		spec.Cursor = page.NextCursor
+	}
+}
+
+func (s *service) RequestErasure(customerID string, input RequestInput) (ErasureRequest, error) {
+	input.RequestedBy = strings.TrimSpace(input.RequestedBy)
+	input.Reason = strings.TrimSpace(input.Reason)
+	if input.RequestedBy == "" {
+		return ErasureRequest{}, fmt.Errorf("%w: requested_by is required", ErrInvalid)
+	}
+	c, err := s.customers.Get(customerID)
+	if err != nil {
+		return ErasureRequest{}, err
+	}
+	existing, err := s.repo.ListByCustomer(c.ID)
+	if err != nil {
+		return ErasureRequest{}, err
+	}
+	for _, r := range existing {
+		if r.Status == StatusPending {
+			return ErasureRequest{}, fmt.Errorf("%w: %s", ErrOpenRequest, r.ID)
+		}
+	}
+
+	at := now()
+	return s.repo.Save(ErasureRequest{
+		CustomerID:  c.ID,
+		Status:      StatusPending,
+		RequestedBy: input.RequestedBy,
+		Reason:      input.Reason,
+		RequestedAt: at,
+		DueAt:       at.Add(ResponseWindow),
+		Events:      []Event{{At: at, Action: ActionRequested, Actor: input.RequestedBy, Detail: input.Reason}},
+	})
+}
+
+func (s *service) Erasure(id string) (ErasureRequest, error) {
+	return s.repo.FindByID(id)
+}
+
+func (s *service) Erasures(status Status) ([]ErasureRequest, error) {
+	if status != "" && !validStatus(status) {
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalid, status)
+	}
+	return s.repo.List(status)
+}
+
+func (s *service) CompleteErasure(id, actor string) (ErasureRequest, error) {
+	req, err := s.pending(id, actor)
+	if err != nil {
+		return ErasureRequest{}, err
+	}
+
+	// The customer may have been merged into another record since the
+	// request, or purged.
+	c, err := s.customers.Get(req.CustomerID)
+	if errors.Is(err, customers.ErrNotFound) {
+		return s.close(req, StatusCompleted, ActionCompleted, strings.TrimSpace(actor), "customer record no longer exists; nothing to erase")
+	}
+	if err != nil {
+		return ErasureRequest{}, err
+	}
+	erased, err := s.customers.Anonymize(c.ID)
+	if err != nil {
+		return ErasureRequest{}, err
+	}
+	vehicleList, err := s.vehicles.ListForCustomer(c.ID, true)
+	if err != nil {
+		return ErasureRequest{}, err
+	}
+	for _, v := range vehicleList {
+		if _, err := s.vehicles.Anonymize(v.ID); err != nil {
+			return ErasureRequest{}, err
+		}
+	}
+
+	removed := plural(erased.Addresses, "address", "addresses") + " and " + plural(erased.Notes, "note", "notes")
+	if erased.Contacts > 0 {
+		removed = plural(erased.Addresses, "address", "addresses") + ", " + plural(erased.Notes, "note", "notes") +
+			" and " + plural(erased.Contacts, "fleet contact", "fleet contacts")
+	}
+	detail := fmt.Sprintf("removed %s, cleared %s; quotes kept for bookkeeping",
+		removed, plural(len(vehicleList), "vehicle VIN", "vehicle VINs"))
+	if c.ID != req.CustomerID {
+		detail = "erased customer " + c.ID + ", which it was merged into: " + detail
+	}
+	return s.close(req, StatusCompleted, ActionCompleted, strings.TrimSpace(actor), detail)
+}
+
+func (s *service) RejectErasure(id, actor, reason string) (ErasureRequest, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return ErasureRequest{}, fmt.Errorf("%w: reason is required", ErrInvalid)
+	}
+	req, err := s.pending(id, actor)
+	if err != nil {
+		return ErasureRequest{}, err
+	}
+	return s.close(req, StatusRejected, ActionRejected, strings.TrimSpace(actor), reason)
+}
+
+// pending returns the request if it can still be acted on.
+func (s *service) pending(id, actor string) (ErasureRequest, error) {
+	if strings.TrimSpace(actor) == "" {
+		return ErasureRequest{}, fmt.Errorf("%w: actor is required", ErrInvalid)
+	}
+	req, err := s.repo.FindByID(id)
+	if err != nil {
+		return ErasureRequest{}, err
+	}
+	if req.Status != StatusPending {
+		return ErasureRequest{}, fmt.Errorf("%w: %s", ErrClosed, req.Status)
+	}
+	return req, nil
+}
+
+func (s *service) close(req ErasureRequest, status Status, action, actor, detail string) (ErasureRequest, error) {
+	at := now()
+	req.Status = status
+	req.ClosedAt = &at
+	req.Events = append(req.Events, Event{At: at, Action: action, Actor: actor, Detail: detail})
+	return s.repo.Save(req)
+}
+
+// now returns the current time at the precision the SQL backends store, so
+// requests returned from Save compare equal to those read back later.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func validStatus(s Status) bool {
+	for _, known := range Statuses {
+		if s == known {
+			return true
+		}
+	}
+	return false
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
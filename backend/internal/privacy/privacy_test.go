@@ -0,0 +1,185 @@
+package privacy_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/quotes"
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+	"github.com/ezmobilemechanic/platform/internal/privacy"
+	"github.com/ezmobilemechanic/platform/internal/storage/memory"
+)
+
+type fixture struct {
+	customers customers.Service
+	vehicles  vehicles.Service
+	quotes    quotes.Service
+	privacy   privacy.Service
+
+	customer customers.Customer
+	vehicle  vehicles.Vehicle
+	quote    quotes.Quote
+}
+
+// newFixture stores a customer with an address, a vehicle, a sent quote, a
+// note, a logged call and an SMS consent grant.
+func newFixture(t *testing.T) fixture {
+	t.Helper()
+	customerRepo := memory.NewCustomerRepository()
+	vehicleRepo := memory.NewVehicleRepository()
+	quoteRepo := memory.NewQuoteRepository()
+	customerRepo.SetVehicles(vehicleRepo)
+	customerRepo.SetQuotes(quoteRepo)
+
+	f := fixture{
+		customers: customers.NewService(customerRepo,
+			customers.WithAddresses(customerRepo.Addresses()),
+			customers.WithConsents(customerRepo.Consents()),
+			customers.WithNotes(customerRepo.Notes()),
+		),
+		vehicles: vehicles.NewService(vehicleRepo),
+		quotes:   quotes.NewService(quoteRepo, quotes.WithHistory(quoteRepo.History())),
+	}
+	f.privacy = privacy.NewService(memory.NewErasureRequestRepository(), f.customers, f.vehicles, f.quotes)
+
+	var err error
+	if f.customer, err = f.customers.Create(customers.CreateInput{FirstName: "Alex", LastName: "Rivera", Email: "alex@example.com", Phone: "904-555-0100"}); err != nil {
+		t.Fatalf("create customer: %v", err)
+	}
+	if _, err := f.customers.AddAddress(f.customer.ID, customers.AddressInput{Line1: "1 Main St", City: "Jacksonville", Region: "FL", PostalCode: "32207"}); err != nil {
+		t.Fatalf("add address: %v", err)
+	}
+	if f.vehicle, err = f.vehicles.Create(vehicles.CreateInput{CustomerID: f.customer.ID, VIN: "1FTBW2CM2HKA12345", Year: 2017, Make: "Ford", Model: "Transit"}); err != nil {
+		t.Fatalf("create vehicle: %v", err)
+	}
+	if f.quote, err = f.quotes.Create(quotes.CreateInput{CustomerID: f.customer.ID, VehicleID: f.vehicle.ID, LineItems: []quotes.CreateLineItem{
+		{Description: "Brake Pads", Quantity: 1, UnitPrice: 15000},
+	}}); err != nil {
+		t.Fatalf("create quote: %v", err)
+	}
+	if _, err := f.quotes.UpdateStatus(f.quote.ID, quotes.StatusInput{Status: quotes.StatusSent}); err != nil {
+		t.Fatalf("update status: %v", err)
+	}
+	if _, err := f.customers.AddNote(f.customer.ID, customers.NoteInput{Body: "Gate code 4411"}); err != nil {
+		t.Fatalf("add note: %v", err)
+	}
+	if _, err := f.customers.AddNote(f.customer.ID, customers.NoteInput{Kind: customers.NoteKindCall, Body: "Confirmed Tuesday"}); err != nil {
+		t.Fatalf("add call: %v", err)
+	}
+	if _, err := f.customers.RecordConsent(f.customer.ID, customers.ConsentInput{Channel: customers.ChannelSMS, Purpose: customers.PurposeMarketing, Granted: true, Source: customers.SourceStaff}); err != nil {
+		t.Fatalf("record consent: %v", err)
+	}
+	return f
+}
+
+func TestExport(t *testing.T) {
+	f := newFixture(t)
+
+	a, err := f.privacy.Export(f.customer.ID)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if a.Customer.ID != f.customer.ID || a.GeneratedAt.IsZero() {
+		t.Fatalf("unexpected archive header: %+v", a)
+	}
+	if len(a.Addresses) != 1 || len(a.Vehicles) != 1 || len(a.Notes) != 1 || len(a.Communications) != 1 || len(a.Consent) != 1 {
+		t.Fatalf("expected one of each record, got %+v", a)
+	}
+	if len(a.Quotes) != 1 || a.Quotes[0].Quote.ID != f.quote.ID || len(a.Quotes[0].StatusHistory) != 1 {
+		t.Fatalf("expected the quote with its status history, got %+v", a.Quotes)
+	}
+	if a.ErasureRequests == nil || len(a.ErasureRequests) != 0 {
+		t.Fatalf("expected an empty erasure request list, got %#v", a.ErasureRequests)
+	}
+
+	if _, err := f.privacy.Export("missing"); !errors.Is(err, customers.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestCompleteErasure(t *testing.T) {
+	f := newFixture(t)
+
+	req, err := f.privacy.RequestErasure(f.customer.ID, privacy.RequestInput{RequestedBy: "front-desk", Reason: "emailed privacy@"})
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	if req.Status != privacy.StatusPending || !req.DueAt.Equal(req.RequestedAt.Add(privacy.ResponseWindow)) || len(req.Events) != 1 {
+		t.Fatalf("unexpected request: %+v", req)
+	}
+	if _, err := f.privacy.RequestErasure(f.customer.ID, privacy.RequestInput{RequestedBy: "front-desk"}); !errors.Is(err, privacy.ErrOpenRequest) {
+		t.Fatalf("expected ErrOpenRequest for a second request, got %v", err)
+	}
+
+	done, err := f.privacy.CompleteErasure(req.ID, "privacy-officer")
+	if err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if done.Status != privacy.StatusCompleted || done.ClosedAt == nil || len(done.Events) != 2 || done.Events[1].Actor != "privacy-officer" {
+		t.Fatalf("unexpected completed request: %+v", done)
+	}
+
+	c, err := f.customers.Get(f.customer.ID)
+	if err != nil {
+		t.Fatalf("get customer: %v", err)
+	}
+	if c.FirstName != "" || c.LastName != "" || c.Email != "" || c.Phone != "" || len(c.Tags) != 1 || c.Tags[0] != customers.TagErased {
+		t.Fatalf("expected anonymized customer, got %+v", c)
+	}
+	if list, _ := f.customers.Addresses(c.ID); len(list) != 0 {
+		t.Fatalf("expected addresses removed, got %v", list)
+	}
+	if list, _ := f.customers.Notes(c.ID); len(list) != 0 {
+		t.Fatalf("expected notes removed, got %v", list)
+	}
+	if err := f.customers.CheckConsent(c.ID, customers.ChannelSMS, customers.PurposeMarketing); !errors.Is(err, customers.ErrNoConsent) {
+		t.Fatalf("expected consent revoked, got %v", err)
+	}
+	if v, err := f.vehicles.Get(f.vehicle.ID); err != nil || v.VIN != "" || v.Make != "Ford" {
+		t.Fatalf("expected VIN cleared and vehicle kept, got %+v (%v)", v, err)
+	}
+	if q, err := f.quotes.Get(f.quote.ID); err != nil || q.CustomerID != f.customer.ID {
+		t.Fatalf("expected quote kept, got %+v (%v)", q, err)
+	}
+
+	if _, err := f.privacy.CompleteErasure(req.ID, "privacy-officer"); !errors.Is(err, privacy.ErrClosed) {
+		t.Fatalf("expected ErrClosed on a completed request, got %v", err)
+	}
+	if _, err := f.privacy.RequestErasure(f.customer.ID, privacy.RequestInput{RequestedBy: "front-desk"}); err != nil {
+		t.Fatalf("a closed request must not block a new one: %v", err)
+	}
+}
+
+func TestRejectErasure(t *testing.T) {
+	f := newFixture(t)
+
+	req, err := f.privacy.RequestErasure(f.customer.ID, privacy.RequestInput{RequestedBy: "front-desk"})
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	if _, err := f.privacy.RejectErasure(req.ID, "privacy-officer", " "); !errors.Is(err, privacy.ErrInvalid) {
+		t.Fatalf("expected ErrInvalid without a reason, got %v", err)
+	}
+	rejected, err := f.privacy.RejectErasure(req.ID, "privacy-officer", "identity not verified")
+	if err != nil {
+		t.Fatalf("reject: %v", err)
+	}
+	if rejected.Status != privacy.StatusRejected || rejected.Events[1].Detail != "identity not verified" {
+		t.Fatalf("unexpected rejected request: %+v", rejected)
+	}
+	if c, _ := f.customers.Get(f.customer.ID); c.Email != f.customer.Email {
+		t.Fatalf("rejecting must not erase, got %+v", c)
+	}
+	if _, err := f.privacy.CompleteErasure(req.ID, "privacy-officer"); !errors.Is(err, privacy.ErrClosed) {
+		t.Fatalf("expected ErrClosed on a rejected request, got %v", err)
+	}
+
+	pending, err := f.privacy.Erasures(privacy.StatusPending)
+	if err != nil || len(pending) != 0 {
+		t.Fatalf("expected no pending requests, got %v (%v)", pending, err)
+	}
+	if _, err := f.privacy.Erasures("bogus"); !errors.Is(err, privacy.ErrInvalid) {
+		t.Fatalf("expected ErrInvalid for an unknown status, got %v", err)
+	}
+}
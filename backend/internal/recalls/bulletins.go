@@ -0,0 +1,325 @@
+package recalls
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+)
+
+// ErrInvalidDataset is returned for dataset files that cannot be used.
+var ErrInvalidDataset = errors.New("invalid recall dataset")
+
+// Kind says whether a bulletin is a safety recall or a technical service
+// bulletin.
+type Kind string
+
+const (
+	KindRecall Kind = "recall"
+	KindTSB    Kind = "tsb"
+)
+
+// AnyYear is the model year NHTSA gives when it is unknown. Such rows apply
+// to every year of the model, since a missed recall costs more than a
+// needless check.
+const AnyYear = 9999
+
+// Bulletin is a recall campaign or service bulletin for one make and model.
+// NHTSA lists each model year on its own row; they are gathered into Years.
+type Bulletin struct {
+	Kind Kind
+	// ID is the NHTSA campaign number of a recall, such as "23V123000", or
+	// the NHTSA item number of a service bulletin.
+	ID string
+	// Number is the manufacturer's own campaign or bulletin number.
+	Number       string
+	Make         string
+	Model        string
+	Years        []int
+	Component    string
+	Manufacturer string
+	Summary      string
+	Consequence  string
+	Remedy       string
+	// Date is when the recall was reported to NHTSA or the bulletin issued.
+	Date time.Time
+}
+
+// Applies reports whether the bulletin covers a vehicle.
+func (b Bulletin) Applies(v vehicles.Vehicle) bool {
+	if matchKey(b.Make) != matchKey(v.Make) || matchKey(b.Model) != matchKey(v.Model) {
+		return false
+	}
+	for _, y := range b.Years {
+		if y == v.Year || y == AnyYear {
+			return true
+		}
+	}
+	return false
+}
+
+// matchKey folds a make or model to upper-case letters and digits, so that
+// "F-150" in the dataset matches "F150" typed at intake.
+func matchKey(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Dataset is the recalls and service bulletins loaded from NHTSA files,
+// indexed by make and model.
+type Dataset struct {
+	bulletins map[string][]*Bulletin
+	byID      map[string][]*Bulletin
+	recalls   int
+	tsbs      int
+}
+
+// NewDataset returns an empty dataset.
+func NewDataset() *Dataset {
+	return &Dataset{bulletins: map[string][]*Bulletin{}, byID: map[string][]*Bulletin{}}
+}
+
+// Counts returns the number of recall campaigns and service bulletins
+// loaded.
+func (d *Dataset) Counts() (recalls, tsbs int) {
+	return d.recalls, d.tsbs
+}
+
+// For returns the bulletins that apply to a vehicle: recalls before service
+// bulletins, newest first.
+func (d *Dataset) For(v vehicles.Vehicle) []Bulletin {
+	out := []Bulletin{}
+	for _, b := range d.bulletins[matchKey(v.Make)+"|"+matchKey(v.Model)] {
+		if b.Applies(v) {
+			out = append(out, *b)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Kind != out[j].Kind {
+			return out[i].Kind == KindRecall
+		}
+		if !out[i].Date.Equal(out[j].Date) {
+			return out[i].Date.After(out[j].Date)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// add records a row of the dataset, merging it into the bulletin for the
+// same ID, make and model.
+func (d *Dataset) add(row Bulletin, year int) {
+	key := matchKey(row.Make) + "|" + matchKey(row.Model)
+	for _, b := range d.byID[row.ID] {
+		if matchKey(b.Make)+"|"+matchKey(b.Model) == key {
+			b.addYear(year)
+			return
+		}
+	}
+	if len(d.byID[row.ID]) == 0 {
+		if row.Kind == KindRecall {
+			d.recalls++
+		} else {
+			d.tsbs++
+		}
+	}
+	b := row
+	b.Years = nil
+	b.addYear(year)
+	d.bulletins[key] = append(d.bulletins[key], &b)
+	d.byID[row.ID] = append(d.byID[row.ID], &b)
+}
+
+func (b *Bulletin) addYear(year int) {
+	i := sort.SearchInts(b.Years, year)
+	if i < len(b.Years) && b.Years[i] == year {
+		return
+	}
+	b.Years = append(b.Years, 0)
+	copy(b.Years[i+1:], b.Years[i:])
+	b.Years[i] = year
+}
+
+// Field positions of the NHTSA flat files, which are tab-delimited and have
+// no header row. See RCL.txt and TSBS.txt in the NHTSA downloads.
+var (
+	recallColumns = map[string]int{
+		"CAMPNO": 1, "MAKETXT": 2, "MODELTXT": 3, "YEARTXT": 4, "MFGCAMPNO": 5,
+		"COMPNAME": 6, "MFGNAME": 7, "RCLTYPECD": 10, "RCDATE": 15,
+		"DESC_DEFECT": 19, "CONEQUENCE_DEFECT": 20, "CORRECTIVE_ACTION": 21,
+	}
+	tsbColumns = map[string]int{
+		"BULNO": 0, "ID": 2, "BULDTE": 3, "COMPNAME": 4, "MAKETXT": 5,
+		"MODELTXT": 6, "YEARTXT": 7, "SUMMARY": 9,
+	}
+)
+
+// LoadFile adds the bulletins of kind in a dataset file to d. The file is
+// either an NHTSA flat file as downloaded (FLAT_RCL.txt for recalls,
+// FLAT_TSBS.txt for service bulletins), a .csv file whose header row names
+// the NHTSA fields, or a .json file holding an array of objects keyed by
+// them. Unknown fields are ignored, as NHTSA adds them over time.
+func (d *Dataset) LoadFile(kind Kind, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = d.ReadJSON(kind, f)
+	} else {
+		err = d.Read(kind, f)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// Read adds the bulletins of kind in a delimited dataset to d. A first row
+// naming YEARTXT is taken as a header; otherwise the rows follow the NHTSA
+// flat file layout. Commas and tabs are accepted.
+func (d *Dataset) Read(kind Kind, r io.Reader) error {
+	br := bufio.NewReader(r)
+	first, err := br.Peek(4096)
+	if err != nil && err != io.EOF && !errors.Is(err, bufio.ErrBufferFull) {
+		return err
+	}
+	line, _, _ := bytes.Cut(first, []byte("\n"))
+
+	cr := csv.NewReader(br)
+	if bytes.Contains(line, []byte("\t")) {
+		cr.Comma = '\t'
+		// The flat files are not quoted and their free text contains
+		// stray quotes.
+		cr.LazyQuotes = true
+	}
+	cr.FieldsPerRecord = -1
+
+	cols := recallColumns
+	if kind == KindTSB {
+		cols = tsbColumns
+	}
+	start := 1
+	if bytes.Contains(bytes.ToUpper(line), []byte("YEARTXT")) {
+		header, err := cr.Read()
+		if err != nil {
+			return fmt.Errorf("%w: read header: %w", ErrInvalidDataset, err)
+		}
+		cols = map[string]int{}
+		for i, name := range header {
+			cols[strings.ToUpper(strings.TrimSpace(name))] = i
+		}
+		start = 2
+	}
+
+	for line := start; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: line %d: %w", ErrInvalidDataset, line, err)
+		}
+		field := func(name string) string {
+			i, ok := cols[name]
+			if !ok || i >= len(rec) {
+				return ""
+			}
+			return strings.TrimSpace(rec[i])
+		}
+		if err := d.addRow(kind, field); err != nil {
+			return fmt.Errorf("%w: line %d: %w", ErrInvalidDataset, line, err)
+		}
+	}
+}
+
+// ReadJSON adds the bulletins of kind in a JSON dataset, an array of objects
+// keyed by the NHTSA field names, to d.
+func (d *Dataset) ReadJSON(kind Kind, r io.Reader) error {
+	var rows []map[string]any
+	if err := json.NewDecoder(r).Decode(&rows); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidDataset, err)
+	}
+	for i, row := range rows {
+		upper := make(map[string]string, len(row))
+		for k, v := range row {
+			switch v := v.(type) {
+			case string:
+				upper[strings.ToUpper(k)] = strings.TrimSpace(v)
+			case float64:
+				upper[strings.ToUpper(k)] = strconv.FormatFloat(v, 'f', -1, 64)
+			}
+		}
+		field := func(name string) string { return upper[name] }
+		if err := d.addRow(kind, field); err != nil {
+			return fmt.Errorf("%w: record %d: %w", ErrInvalidDataset, i+1, err)
+		}
+	}
+	return nil
+}
+
+// addRow adds one dataset row, read through field by NHTSA field name.
+// Equipment, tire and child seat recalls are skipped, since they are not
+// matched by vehicle.
+func (d *Dataset) addRow(kind Kind, field func(string) string) error {
+	row := Bulletin{
+		Kind:      kind,
+		Make:      field("MAKETXT"),
+		Model:     field("MODELTXT"),
+		Component: field("COMPNAME"),
+	}
+	var date string
+	switch kind {
+	case KindRecall:
+		if t := field("RCLTYPECD"); t != "" && t != "V" {
+			return nil
+		}
+		row.ID = field("CAMPNO")
+		row.Number = field("MFGCAMPNO")
+		row.Manufacturer = field("MFGNAME")
+		row.Summary = field("DESC_DEFECT")
+		row.Consequence = field("CONEQUENCE_DEFECT")
+		row.Remedy = field("CORRECTIVE_ACTION")
+		date = field("RCDATE")
+	case KindTSB:
+		row.ID = field("ID")
+		row.Number = field("BULNO")
+		row.Summary = field("SUMMARY")
+		date = field("BULDTE")
+	default:
+		return fmt.Errorf("unknown bulletin kind %q", kind)
+	}
+	if row.ID == "" || row.Make == "" || row.Model == "" {
+		return errors.New("bulletin number, make and model are required")
+	}
+	year, err := strconv.Atoi(field("YEARTXT"))
+	if err != nil || year < 1900 {
+		return fmt.Errorf("invalid model year %q", field("YEARTXT"))
+	}
+	if date != "" {
+		if row.Date, err = time.Parse("20060102", date); err != nil {
+			return fmt.Errorf("invalid date %q", date)
+		}
+	}
+	d.add(row, year)
+	return nil
+}
@@ -0,0 +1,134 @@
+// Package recalls matches NHTSA safety recalls and technical service
+// bulletins to vehicles. Bulletins come from NHTSA dataset files loaded at
+// startup (see Dataset); what staff have done about them on each vehicle is
+// kept as vehicles.RecallMark.
+package recalls
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+)
+
+// ErrBulletinNotFound is returned when marking a bulletin that does not
+// apply to the vehicle.
+var ErrBulletinNotFound = errors.New("bulletin not found for vehicle")
+
+// Status is where a bulletin stands for a vehicle: open, or as marked by
+// staff.
+type Status = vehicles.RecallStatus
+
+// StatusOpen is a bulletin nobody has marked, or one whose owner was
+// informed before the vehicle changed hands.
+const StatusOpen Status = "open"
+
+// VehicleBulletin is a bulletin that applies to a vehicle and where it
+// stands.
+type VehicleBulletin struct {
+	Bulletin
+	Status Status
+	// Note and MarkedAt come from the staff mark, if any.
+	Note     string
+	MarkedAt *time.Time
+}
+
+// Summary counts the bulletins that apply to a vehicle.
+type Summary struct {
+	// OpenRecalls counts recalls neither addressed nor told to the current
+	// owner, the ones to raise at booking.
+	OpenRecalls int
+	Bulletins   []VehicleBulletin
+}
+
+// Service matches bulletins to vehicles.
+type Service interface {
+	// ForVehicle returns the bulletins that apply to the vehicle, recalls
+	// first, or vehicles.ErrNotFound.
+	ForVehicle(vehicleID string) (Summary, error)
+	// Mark records that the bulletin was addressed or the owner informed.
+	// It returns ErrBulletinNotFound unless the bulletin applies to the
+	// vehicle.
+	Mark(vehicleID string, input vehicles.RecallMarkInput) (VehicleBulletin, error)
+	// Unmark reopens a bulletin on the vehicle.
+	Unmark(vehicleID, bulletinID string) error
+	// Counts returns the number of recalls and service bulletins loaded.
+	Counts() (recalls, tsbs int)
+}
+
+// NewService builds a recall service over the dataset and vehicles service.
+func NewService(dataset *Dataset, v vehicles.Service) Service {
+	return &service{dataset: dataset, vehicles: v}
+}
+
+type service struct {
+	dataset  *Dataset
+	vehicles vehicles.Service
+}
+
+func (s *service) Counts() (int, int) {
+	return s.dataset.Counts()
+}
+
+func (s *service) ForVehicle(vehicleID string) (Summary, error) {
+	v, err := s.vehicles.Get(vehicleID)
+	if err != nil {
+		return Summary{}, err
+	}
+	bulletins := s.dataset.For(v)
+	summary := Summary{Bulletins: make([]VehicleBulletin, 0, len(bulletins))}
+	if len(bulletins) == 0 {
+		return summary, nil
+	}
+	marks, err := s.vehicles.RecallMarks(v.ID)
+	if err != nil && !errors.Is(err, vehicles.ErrNotImplemented) {
+		return Summary{}, err
+	}
+	byID := make(map[string]vehicles.RecallMark, len(marks))
+	for _, m := range marks {
+		byID[m.BulletinID] = m
+	}
+	for _, b := range bulletins {
+		vb := VehicleBulletin{Bulletin: b, Status: StatusOpen}
+		if m, ok := byID[b.ID]; ok && m.Current(v.CustomerID) {
+			at := m.MarkedAt
+			vb.Status, vb.Note, vb.MarkedAt = m.Status, m.Note, &at
+		}
+		if vb.Kind == KindRecall && vb.Status == StatusOpen {
+			summary.OpenRecalls++
+		}
+		summary.Bulletins = append(summary.Bulletins, vb)
+	}
+	return summary, nil
+}
+
+func (s *service) Mark(vehicleID string, input vehicles.RecallMarkInput) (VehicleBulletin, error) {
+	v, err := s.vehicles.Get(vehicleID)
+	if err != nil {
+		return VehicleBulletin{}, err
+	}
+	input.BulletinID = strings.TrimSpace(input.BulletinID)
+	b, ok := s.find(v, input.BulletinID)
+	if !ok {
+		return VehicleBulletin{}, ErrBulletinNotFound
+	}
+	m, err := s.vehicles.MarkRecall(v.ID, input)
+	if err != nil {
+		return VehicleBulletin{}, err
+	}
+	return VehicleBulletin{Bulletin: b, Status: m.Status, Note: m.Note, MarkedAt: &m.MarkedAt}, nil
+}
+
+func (s *service) Unmark(vehicleID, bulletinID string) error {
+	return s.vehicles.UnmarkRecall(vehicleID, bulletinID)
+}
+
+func (s *service) find(v vehicles.Vehicle, bulletinID string) (Bulletin, bool) {
+	for _, b := range s.dataset.For(v) {
+		if b.ID == bulletinID {
+			return b, true
+		}
+	}
+	return Bulletin{}, false
+}
@@ -0,0 +1,175 @@
+package recalls_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+	"github.com/ezmobilemechanic/platform/internal/recalls"
+	"github.com/ezmobilemechanic/platform/internal/storage/memory"
+)
+
+// flatRecalls follows the NHTSA FLAT_RCL.txt layout: tab-delimited, no
+// header, 24 fields.
+var flatRecalls = strings.Join([]string{
+	flatRecall("1", "23V123000", "FORD", "F-150", "2021", "V", "20230301", "FUEL SYSTEM", "Fuel line may leak."),
+	flatRecall("2", "23V123000", "FORD", "F-150", "2022", "V", "20230301", "FUEL SYSTEM", "Fuel line may leak."),
+	flatRecall("3", "19V001000", "FORD", "F-150", "2021", "V", "20190105", "AIR BAGS", `Inflator "may" rupture.`),
+	flatRecall("4", "20E050000", "FORD", "F-150", "2021", "E", "20200105", "EQUIPMENT", "Aftermarket lamp."),
+	flatRecall("5", "18V999000", "FORD", "RANGER", "9999", "V", "20180105", "BRAKES", "Unknown years."),
+}, "\n") + "\n"
+
+func flatRecall(record, campaign, maker, model, year, kind, date, component, defect string) string {
+	f := make([]string, 24)
+	f[0], f[1], f[2], f[3], f[4] = record, campaign, maker, model, year
+	f[5], f[6], f[7] = "MFR-"+campaign, component, "Ford Motor Company"
+	f[10], f[15], f[19], f[20], f[21] = kind, date, defect, "Crash risk.", "Dealers will repair."
+	return strings.Join(f, "\t")
+}
+
+const tsbCSV = `BULNO,ID,BULDTE,COMPNAME,MAKETXT,MODELTXT,YEARTXT,SUMMARY
+21-2101,10191234,20210615,ENGINE,FORD,F150,2021,Rough idle when cold.
+`
+
+func loadDataset(t *testing.T) *recalls.Dataset {
+	t.Helper()
+	d := recalls.NewDataset()
+	if err := d.Read(recalls.KindRecall, strings.NewReader(flatRecalls)); err != nil {
+		t.Fatalf("read recalls: %v", err)
+	}
+	if err := d.Read(recalls.KindTSB, strings.NewReader(tsbCSV)); err != nil {
+		t.Fatalf("read tsbs: %v", err)
+	}
+	return d
+}
+
+func TestDatasetFor(t *testing.T) {
+	d := loadDataset(t)
+	if n, tsbs := d.Counts(); n != 3 || tsbs != 1 {
+		t.Fatalf("expected 3 recalls and 1 tsb, got %d and %d", n, tsbs)
+	}
+
+	list := d.For(vehicles.Vehicle{Year: 2021, Make: "Ford", Model: "F150"})
+	var ids []string
+	for _, b := range list {
+		ids = append(ids, b.ID)
+	}
+	if got := strings.Join(ids, ","); got != "23V123000,19V001000,10191234" {
+		t.Fatalf("expected recalls newest first then the tsb, got %s", got)
+	}
+	if b := list[0]; b.Kind != recalls.KindRecall || len(b.Years) != 2 || b.Years[0] != 2021 || b.Years[1] != 2022 ||
+		b.Number != "MFR-23V123000" || b.Remedy != "Dealers will repair." || b.Date.Format("2006-01-02") != "2023-03-01" {
+		t.Fatalf("unexpected bulletin %+v", b)
+	}
+	if b := list[1]; b.Summary != `Inflator "may" rupture.` {
+		t.Fatalf("expected stray quotes kept, got %q", b.Summary)
+	}
+	if b := list[2]; b.Kind != recalls.KindTSB || b.Number != "21-2101" || b.Summary != "Rough idle when cold." {
+		t.Fatalf("unexpected tsb %+v", b)
+	}
+
+	if list := d.For(vehicles.Vehicle{Year: 2022, Make: "FORD", Model: "F-150"}); len(list) != 1 {
+		t.Fatalf("expected only the 2022 recall, got %+v", list)
+	}
+	if list := d.For(vehicles.Vehicle{Year: 2005, Make: "Ford", Model: "Ranger"}); len(list) != 1 {
+		t.Fatalf("expected a recall of unknown years to match, got %+v", list)
+	}
+	if list := d.For(vehicles.Vehicle{Year: 2021, Make: "Ford", Model: "Escape"}); len(list) != 0 {
+		t.Fatalf("expected no bulletins, got %+v", list)
+	}
+}
+
+func TestDatasetReadJSONAndErrors(t *testing.T) {
+	d := recalls.NewDataset()
+	err := d.ReadJSON(recalls.KindRecall, strings.NewReader(`[
+		{"CAMPNO": "24V010000", "MAKETXT": "HONDA", "MODELTXT": "CIVIC", "YEARTXT": 2019, "RCDATE": "20240110", "NEW_FIELD": "x"}
+	]`))
+	if err != nil {
+		t.Fatalf("read json: %v", err)
+	}
+	if list := d.For(vehicles.Vehicle{Year: 2019, Make: "Honda", Model: "Civic"}); len(list) != 1 || list[0].ID != "24V010000" {
+		t.Fatalf("unexpected bulletins %+v", list)
+	}
+
+	for name, doc := range map[string]string{
+		"year": "CAMPNO,MAKETXT,MODELTXT,YEARTXT\n24V010000,HONDA,CIVIC,soon\n",
+		"make": "CAMPNO,MAKETXT,MODELTXT,YEARTXT\n24V010000,,CIVIC,2019\n",
+		"date": "CAMPNO,MAKETXT,MODELTXT,YEARTXT,RCDATE\n24V010000,HONDA,CIVIC,2019,Jan 2024\n",
+	} {
+		if err := recalls.NewDataset().Read(recalls.KindRecall, strings.NewReader(doc)); !errors.Is(err, recalls.ErrInvalidDataset) {
+			t.Errorf("%s: expected ErrInvalidDataset, got %v", name, err)
+		}
+	}
+}
+
+func TestServiceMarks(t *testing.T) {
+	customerRepo := memory.NewCustomerRepository()
+	vehicleRepo := memory.NewVehicleRepository()
+	customerSvc := customers.NewService(customerRepo)
+	vehicleSvc := vehicles.NewService(vehicleRepo, vehicles.WithRecalls(vehicleRepo.Recalls()))
+	svc := recalls.NewService(loadDataset(t), vehicleSvc)
+
+	owner, err := customerSvc.Create(customers.CreateInput{FirstName: "Alex", Email: "alex@example.com"})
+	if err != nil {
+		t.Fatalf("create customer: %v", err)
+	}
+	buyer, err := customerSvc.Create(customers.CreateInput{FirstName: "Sam", Email: "sam@example.com"})
+	if err != nil {
+		t.Fatalf("create customer: %v", err)
+	}
+	truck, err := vehicleSvc.Create(vehicles.CreateInput{CustomerID: owner.ID, Year: 2021, Make: "Ford", Model: "F-150"})
+	if err != nil {
+		t.Fatalf("create vehicle: %v", err)
+	}
+
+	summary, err := svc.ForVehicle(truck.ID)
+	if err != nil {
+		t.Fatalf("for vehicle: %v", err)
+	}
+	if summary.OpenRecalls != 2 || len(summary.Bulletins) != 3 || summary.Bulletins[0].Status != recalls.StatusOpen {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+
+	if _, err := svc.Mark(truck.ID, vehicles.RecallMarkInput{BulletinID: "24V010000", Status: vehicles.RecallAddressed}); !errors.Is(err, recalls.ErrBulletinNotFound) {
+		t.Fatalf("expected ErrBulletinNotFound, got %v", err)
+	}
+	if _, err := svc.Mark(truck.ID, vehicles.RecallMarkInput{BulletinID: "23V123000", Status: "done"}); !errors.Is(err, vehicles.ErrInvalid) {
+		t.Fatalf("expected ErrInvalid for an unknown status, got %v", err)
+	}
+	marked, err := svc.Mark(truck.ID, vehicles.RecallMarkInput{BulletinID: " 23V123000 ", Status: vehicles.RecallInformed, Note: "told at booking"})
+	if err != nil || marked.Status != vehicles.RecallInformed || marked.MarkedAt == nil || marked.ID != "23V123000" {
+		t.Fatalf("mark: %+v, %v", marked, err)
+	}
+	if _, err := svc.Mark(truck.ID, vehicles.RecallMarkInput{BulletinID: "19V001000", Status: vehicles.RecallAddressed}); err != nil {
+		t.Fatalf("mark: %v", err)
+	}
+	if summary, err = svc.ForVehicle(truck.ID); err != nil || summary.OpenRecalls != 0 {
+		t.Fatalf("expected no open recalls, got %+v, %v", summary, err)
+	}
+
+	// The new owner has not been told, but the repair stays done.
+	if _, err := vehicleSvc.Transfer(truck.ID, vehicles.TransferInput{CustomerID: buyer.ID}); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if summary, err = svc.ForVehicle(truck.ID); err != nil || summary.OpenRecalls != 1 {
+		t.Fatalf("expected the informed recall to reopen, got %+v, %v", summary, err)
+	}
+	if b := summary.Bulletins[0]; b.ID != "23V123000" || b.Status != recalls.StatusOpen || b.Note != "" {
+		t.Fatalf("unexpected bulletin %+v", b)
+	}
+
+	if err := svc.Unmark(truck.ID, "19V001000"); err != nil {
+		t.Fatalf("unmark: %v", err)
+	}
+	if err := svc.Unmark(truck.ID, "19V001000"); !errors.Is(err, vehicles.ErrRecallMarkNotFound) {
+		t.Fatalf("expected ErrRecallMarkNotFound, got %v", err)
+	}
+	if summary, err = svc.ForVehicle(truck.ID); err != nil || summary.OpenRecalls != 2 {
+		t.Fatalf("expected both recalls open, got %+v, %v", summary, err)
+	}
+	if _, err := svc.ForVehicle("missing"); !errors.Is(err, vehicles.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+// Package servicehistory assembles a vehicle's service record from the work
+// done on it: accepted and converted quotes today, jobs and invoices once
+// they exist. The record follows the vehicle across owners and carries no
+// customer details, so an owner can hand it to a buyer.
+package servicehistory
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/quotes"
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+)
+
+// Kind says what an entry was built from.
+type Kind string
+
+const (
+	KindQuote Kind = "quote"
+)
+
+// Vehicle describes the vehicle at the top of a history.
+type Vehicle struct {
+	ID      string
+	VIN     string
+	Year    int
+	Make    string
+	Model   string
+	Trim    string
+	Engine  string
+	Color   string
+	Mileage int
+}
+
+// LineItem is a part or labour line of an entry, in cents.
+type LineItem struct {
+	Description string
+	Quantity    int
+	UnitPrice   int64
+	LaborHours  float64
+	LaborAmount int64
+}
+
+// Entry is one visit or piece of work.
+type Entry struct {
+	Kind Kind
+	// RefID is the ID of the quote the entry was built from.
+	RefID string
+	// Date is when the work was agreed: the quote's move to accepted or
+	// converted, or its creation when that was not recorded.
+	Date   time.Time
+	Status string
+	// Mileage is the latest trusted odometer reading on or before the day
+	// of Date, or 0 when there is none.
+	Mileage         int
+	LineItems       []LineItem
+	TotalAmount     int64
+	TechnicianNotes string
+}
+
+// History is a vehicle's service record, oldest entry first.
+type History struct {
+	Vehicle     Vehicle
+	Entries     []Entry
+	GeneratedAt time.Time
+}
+
+// Service builds service histories.
+type Service interface {
+	// ForVehicle returns the vehicle's history, or vehicles.ErrNotFound.
+	ForVehicle(vehicleID string) (History, error)
+}
+
+// NewService builds a history over the domain services.
+func NewService(v vehicles.Service, q quotes.Service) Service {
+	return &service{vehicles: v, quotes: q}
+}
+
+type service struct {
+	vehicles vehicles.Service
+	quotes   quotes.Service
+}
+
+func (s *service) ForVehicle(vehicleID string) (History, error) {
+	v, err := s.vehicles.Get(vehicleID)
+	if err != nil {
+		return History{}, err
+	}
+	readings, err := s.vehicles.Readings(v.ID)
+	if err != nil && !errors.Is(err, vehicles.ErrNotImplemented) {
+		return History{}, err
+	}
+	list, err := s.quotes.ListForVehicle(v.ID)
+	if err != nil {
+		return History{}, err
+	}
+
+	h := History{
+		Vehicle: Vehicle{
+			ID:      v.ID,
+			VIN:     v.VIN,
+			Year:    v.Year,
+			Make:    v.Make,
+			Model:   v.Model,
+			Trim:    v.Trim,
+			Engine:  v.Engine,
+			Color:   v.Color,
+			Mileage: v.Mileage,
+		},
+		Entries:     []Entry{},
+		GeneratedAt: time.Now().UTC(),
+	}
+	for _, q := range list {
+		if q.Status != quotes.StatusAccepted && q.Status != quotes.StatusConverted {
+			continue
+		}
+		date, err := s.agreedAt(q)
+		if err != nil {
+			return History{}, err
+		}
+		e := Entry{
+			Kind:            KindQuote,
+			RefID:           q.ID,
+			Date:            date,
+			Status:          string(q.Status),
+			Mileage:         mileageOn(readings, date),
+			LineItems:       make([]LineItem, 0, len(q.LineItems)),
+			TotalAmount:     q.TotalAmount,
+			TechnicianNotes: q.TechnicianNotes,
+		}
+		for _, item := range q.LineItems {
+			e.LineItems = append(e.LineItems, LineItem{
+				Description: item.Description,
+				Quantity:    item.Quantity,
+				UnitPrice:   item.UnitPrice,
+				LaborHours:  item.LaborHours,
+				LaborAmount: item.LaborAmount,
+			})
+		}
+		h.Entries = append(h.Entries, e)
+	}
+	sort.SliceStable(h.Entries, func(i, j int) bool { return h.Entries[i].Date.Before(h.Entries[j].Date) })
+	return h, nil
+}
+
+// agreedAt returns when the quote was first accepted or converted, or when
+// it was created if its status history does not say.
+func (s *service) agreedAt(q quotes.Quote) (time.Time, error) {
+	changes, err := s.quotes.StatusHistory(q.ID)
+	if err != nil && !errors.Is(err, quotes.ErrNotImplemented) {
+		return time.Time{}, err
+	}
+	for _, c := range changes {
+		if c.To == quotes.StatusAccepted || c.To == quotes.StatusConverted {
+			return c.ChangedAt, nil
+		}
+	}
+	return q.CreatedAt, nil
+}
+
+// mileageOn returns the latest unflagged reading taken by the end of the
+// UTC day of at. Readings are in date order.
+func mileageOn(readings []vehicles.Reading, at time.Time) int {
+	end := at.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+	mileage := 0
+	for _, r := range readings {
+		if r.Flagged {
+			continue
+		}
+		if !r.ReadAt.Before(end) {
+			break
+		}
+		mileage = r.Mileage
+	}
+	return mileage
+}
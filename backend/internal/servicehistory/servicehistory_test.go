@@ -0,0 +1,92 @@
+package servicehistory_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/quotes"
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+	"github.com/ezmobilemechanic/platform/internal/servicehistory"
+	"github.com/ezmobilemechanic/platform/internal/storage/memory"
+)
+
+func TestForVehicle(t *testing.T) {
+	vehicleRepo := memory.NewVehicleRepository()
+	vehicleSvc := vehicles.NewService(vehicleRepo, vehicles.WithOdometer(vehicleRepo.Odometer()))
+	quoteRepo := memory.NewQuoteRepository()
+	quoteSvc := quotes.NewService(quoteRepo, quotes.WithHistory(quoteRepo.History()))
+	svc := servicehistory.NewService(vehicleSvc, quoteSvc)
+
+	v, err := vehicleSvc.Create(vehicles.CreateInput{CustomerID: "seller", Year: 2017, Make: "Ford", Model: "Transit"})
+	if err != nil {
+		t.Fatalf("create vehicle: %v", err)
+	}
+	today := time.Now().UTC()
+	for _, r := range []struct {
+		mileage int
+		at      time.Time
+	}{
+		{38000, today.AddDate(0, 0, -400)},
+		{52000, today.AddDate(0, 0, -2)},
+	} {
+		if _, err := vehicleSvc.RecordReading(v.ID, vehicles.ReadingInput{Mileage: r.mileage, Source: vehicles.SourceInvoice, ReadAt: r.at}); err != nil {
+			t.Fatalf("record reading: %v", err)
+		}
+	}
+
+	create := func(customerID, notes string, status quotes.Status) quotes.Quote {
+		t.Helper()
+		q, err := quoteSvc.Create(quotes.CreateInput{
+			CustomerID:      customerID,
+			VehicleID:       v.ID,
+			TechnicianNotes: notes,
+			LineItems:       []quotes.CreateLineItem{{Description: "Oil change", Quantity: 1, UnitPrice: 7999, LaborHours: 0.5}},
+		})
+		if err != nil {
+			t.Fatalf("create quote: %v", err)
+		}
+		// Walk the quote through the statuses before the one wanted.
+		steps := map[quotes.Status][]quotes.Status{
+			quotes.StatusAccepted:  {quotes.StatusSent, quotes.StatusAccepted},
+			quotes.StatusConverted: {quotes.StatusSent, quotes.StatusAccepted, quotes.StatusConverted},
+			quotes.StatusDeclined:  {quotes.StatusSent, quotes.StatusDeclined},
+		}
+		for _, step := range steps[status] {
+			if q, err = quoteSvc.UpdateStatus(q.ID, quotes.StatusInput{Status: step}); err != nil {
+				t.Fatalf("update status: %v", err)
+			}
+		}
+		return q
+	}
+	done := create("seller", "Drain plug gasket replaced", quotes.StatusConverted)
+	create("seller", "", quotes.StatusDeclined)
+	create("buyer", "", quotes.StatusDraft)
+	// The buyer's work still belongs on the vehicle's record.
+	accepted := create("buyer", "", quotes.StatusAccepted)
+
+	h, err := svc.ForVehicle(v.ID)
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if h.Vehicle.Make != "Ford" || h.Vehicle.Mileage != 52000 {
+		t.Fatalf("unexpected vehicle %+v", h.Vehicle)
+	}
+	if len(h.Entries) != 2 || h.Entries[0].RefID != done.ID || h.Entries[1].RefID != accepted.ID {
+		t.Fatalf("expected the converted and accepted quotes, got %+v", h.Entries)
+	}
+	first := h.Entries[0]
+	if first.Mileage != 52000 || first.TechnicianNotes != "Drain plug gasket replaced" || first.TotalAmount != 7999 {
+		t.Fatalf("unexpected entry %+v", first)
+	}
+	if len(first.LineItems) != 1 || first.LineItems[0].Description != "Oil change" || first.LineItems[0].LaborHours != 0.5 {
+		t.Fatalf("unexpected line items %+v", first.LineItems)
+	}
+	if first.Date.Before(done.CreatedAt) {
+		t.Fatalf("expected the entry dated by its conversion, got %v before %v", first.Date, done.CreatedAt)
+	}
+
+	if _, err := svc.ForVehicle("missing"); !errors.Is(err, vehicles.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,246 @@
+package specs
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+)
+
+// ErrInvalidCatalog is returned for catalog files that cannot be used.
+var ErrInvalidCatalog = errors.New("invalid spec catalog")
+
+// Spec is the service data for vehicles of a year range, make and model.
+// Specs with Engine set apply only to matching engines and take precedence
+// over those without, since fluid capacities usually differ by engine.
+type Spec struct {
+	YearFrom int    `json:"year_from"`
+	YearTo   int    `json:"year_to,omitempty"`
+	Make     string `json:"make"`
+	Model    string `json:"model"`
+	// Engine matches when it appears in the vehicle's engine description,
+	// so "2.5L" covers "2.5L I4".
+	Engine string `json:"engine,omitempty"`
+
+	OilType string `json:"oil_type,omitempty"`
+	// OilQuarts is the engine oil capacity with a filter change.
+	OilQuarts     float64 `json:"oil_quarts,omitempty"`
+	CoolantType   string  `json:"coolant_type,omitempty"`
+	CoolantQuarts float64 `json:"coolant_quarts,omitempty"`
+	TireSize      string  `json:"tire_size,omitempty"`
+	// FrontTirePSI and RearTirePSI are the cold inflation pressures on the
+	// door placard.
+	FrontTirePSI   int `json:"front_tire_psi,omitempty"`
+	RearTirePSI    int `json:"rear_tire_psi,omitempty"`
+	LugTorqueFtLbs int `json:"lug_torque_ft_lbs,omitempty"`
+}
+
+// Catalog is a set of vehicle specs loaded from a catalog file.
+type Catalog struct {
+	// Version names this revision of the catalog, usually after the data
+	// source's release.
+	Version string `json:"version,omitempty"`
+	Specs   []Spec `json:"specs"`
+}
+
+// LoadFile reads a catalog from a .csv or .json file.
+func LoadFile(path string) (Catalog, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Catalog{}, err
+	}
+	defer f.Close()
+
+	var c Catalog
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		c, err = ReadCSV(f)
+	case ".json":
+		c, err = Read(f)
+	default:
+		return Catalog{}, fmt.Errorf("%s: %w: expected a .csv or .json file", path, ErrInvalidCatalog)
+	}
+	if err != nil {
+		return Catalog{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Read parses and validates a JSON catalog. Unknown fields are rejected so
+// that a misspelt key does not silently drop a value.
+func Read(r io.Reader) (Catalog, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var c Catalog
+	if err := dec.Decode(&c); err != nil {
+		return Catalog{}, fmt.Errorf("%w: %w", ErrInvalidCatalog, err)
+	}
+	if err := c.validate(); err != nil {
+		return Catalog{}, err
+	}
+	return c, nil
+}
+
+// ReadCSV parses and validates a CSV catalog. The header row names columns
+// as the JSON spec keys do, in any order; year_from, make and model are
+// required and unknown columns are rejected. A CSV catalog has no version.
+func ReadCSV(r io.Reader) (Catalog, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return Catalog{}, fmt.Errorf("%w: read header: %w", ErrInvalidCatalog, err)
+	}
+	fields := csvFields()
+	cols := make([]int, len(header))
+	seen := map[string]bool{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		idx, ok := fields[name]
+		if !ok {
+			return Catalog{}, fmt.Errorf("%w: unknown column %q", ErrInvalidCatalog, name)
+		}
+		if seen[name] {
+			return Catalog{}, fmt.Errorf("%w: duplicate column %q", ErrInvalidCatalog, name)
+		}
+		seen[name] = true
+		cols[i] = idx
+	}
+	for _, name := range []string{"year_from", "make", "model"} {
+		if !seen[name] {
+			return Catalog{}, fmt.Errorf("%w: header must name a %s column", ErrInvalidCatalog, name)
+		}
+	}
+
+	c := Catalog{Specs: []Spec{}}
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Catalog{}, fmt.Errorf("%w: line %d: %w", ErrInvalidCatalog, line, err)
+		}
+		var spec Spec
+		v := reflect.ValueOf(&spec).Elem()
+		for i, raw := range rec {
+			raw = strings.TrimSpace(raw)
+			if raw == "" {
+				continue
+			}
+			f := v.Field(cols[i])
+			switch f.Kind() {
+			case reflect.String:
+				f.SetString(raw)
+			case reflect.Int:
+				n, err := strconv.Atoi(raw)
+				if err != nil {
+					return Catalog{}, fmt.Errorf("%w: line %d: %s: %q is not a whole number", ErrInvalidCatalog, line, header[i], raw)
+				}
+				f.SetInt(int64(n))
+			case reflect.Float64:
+				n, err := strconv.ParseFloat(raw, 64)
+				if err != nil {
+					return Catalog{}, fmt.Errorf("%w: line %d: %s: %q is not a number", ErrInvalidCatalog, line, header[i], raw)
+				}
+				f.SetFloat(n)
+			}
+		}
+		c.Specs = append(c.Specs, spec)
+	}
+	if err := c.validate(); err != nil {
+		return Catalog{}, err
+	}
+	return c, nil
+}
+
+// csvFields maps CSV column names, which are the Spec JSON keys, to Spec
+// field indexes.
+func csvFields() map[string]int {
+	t := reflect.TypeOf(Spec{})
+	out := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		out[name] = i
+	}
+	return out
+}
+
+func (c *Catalog) validate() error {
+	c.Version = strings.TrimSpace(c.Version)
+	if c.Specs == nil {
+		c.Specs = []Spec{}
+	}
+	keys := map[string]int{}
+	for i := range c.Specs {
+		s := &c.Specs[i]
+		s.Make = strings.TrimSpace(s.Make)
+		s.Model = strings.TrimSpace(s.Model)
+		s.Engine = strings.TrimSpace(s.Engine)
+		s.OilType = strings.TrimSpace(s.OilType)
+		s.CoolantType = strings.TrimSpace(s.CoolantType)
+		s.TireSize = strings.TrimSpace(s.TireSize)
+		if s.YearTo == 0 {
+			s.YearTo = s.YearFrom
+		}
+		switch {
+		case s.Make == "" || s.Model == "":
+			return fmt.Errorf("%w: spec %d: make and model are required", ErrInvalidCatalog, i+1)
+		case s.YearFrom < 1900 || s.YearTo < s.YearFrom:
+			return fmt.Errorf("%w: spec %d (%s %s): invalid year range %d-%d", ErrInvalidCatalog, i+1, s.Make, s.Model, s.YearFrom, s.YearTo)
+		case s.OilQuarts < 0 || s.CoolantQuarts < 0 || s.FrontTirePSI < 0 || s.RearTirePSI < 0 || s.LugTorqueFtLbs < 0:
+			return fmt.Errorf("%w: spec %d (%s %s): values must not be negative", ErrInvalidCatalog, i+1, s.Make, s.Model)
+		}
+		key := strings.ToLower(fmt.Sprintf("%d-%d|%s|%s|%s", s.YearFrom, s.YearTo, s.Make, s.Model, s.Engine))
+		if prev, ok := keys[key]; ok {
+			return fmt.Errorf("%w: spec %d (%s %s) repeats spec %d", ErrInvalidCatalog, i+1, s.Make, s.Model, prev)
+		}
+		keys[key] = i + 1
+	}
+	return nil
+}
+
+// For returns the spec that applies to a vehicle. Of the specs covering its
+// year, make and model, one naming the engine wins over one that does not,
+// then the narrower year range, then the earlier spec.
+func (c Catalog) For(v vehicles.Vehicle) (Spec, bool) {
+	var (
+		best  Spec
+		found bool
+	)
+	for _, s := range c.Specs {
+		if !s.applies(v) {
+			continue
+		}
+		switch {
+		case !found:
+		case (s.Engine != "") != (best.Engine != ""):
+			if s.Engine == "" {
+				continue
+			}
+		case s.YearTo-s.YearFrom >= best.YearTo-best.YearFrom:
+			continue
+		}
+		best, found = s, true
+	}
+	return best, found
+}
+
+func (s Spec) applies(v vehicles.Vehicle) bool {
+	if v.Year < s.YearFrom || v.Year > s.YearTo {
+		return false
+	}
+	if !strings.EqualFold(s.Make, strings.TrimSpace(v.Make)) || !strings.EqualFold(s.Model, strings.TrimSpace(v.Model)) {
+		return false
+	}
+	return s.Engine == "" || strings.Contains(strings.ToLower(v.Engine), strings.ToLower(s.Engine))
+}
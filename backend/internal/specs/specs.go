@@ -0,0 +1,101 @@
+// Package specs looks up service data for vehicles: fluid types and
+// capacities, tire sizes and pressures and lug nut torque. The data comes
+// from a catalog file (see Catalog) imported from CSV or JSON, usually an
+// export from a licensed data provider; none is built in.
+package specs
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+)
+
+// ErrNoSpec is returned when the catalog has no spec for a vehicle.
+var ErrNoSpec = errors.New("no spec for vehicle")
+
+// VehicleSpec is the spec found for a vehicle.
+type VehicleSpec struct {
+	VehicleID      string
+	CatalogVersion string
+	Spec           Spec
+}
+
+// Service looks up vehicle specs.
+type Service interface {
+	// ForVehicle returns the spec for the vehicle, vehicles.ErrNotFound or
+	// ErrNoSpec.
+	ForVehicle(vehicleID string) (VehicleSpec, error)
+	// Quantity returns how much of a fluid a quote line item needs for the
+	// vehicle, such as the quarts of oil for an oil change, or 0 when the
+	// description is not fluid work or the catalog does not say.
+	Quantity(vehicleID, description string) (int, error)
+	// Catalog returns the catalog in use.
+	Catalog() Catalog
+}
+
+// NewService builds a spec service over the vehicles service.
+func NewService(catalog Catalog, v vehicles.Service) Service {
+	return &service{catalog: catalog, vehicles: v}
+}
+
+type service struct {
+	catalog  Catalog
+	vehicles vehicles.Service
+}
+
+func (s *service) Catalog() Catalog {
+	return s.catalog
+}
+
+func (s *service) ForVehicle(vehicleID string) (VehicleSpec, error) {
+	v, err := s.vehicles.Get(vehicleID)
+	if err != nil {
+		return VehicleSpec{}, err
+	}
+	spec, ok := s.catalog.For(v)
+	if !ok {
+		return VehicleSpec{}, ErrNoSpec
+	}
+	return VehicleSpec{VehicleID: v.ID, CatalogVersion: s.catalog.Version, Spec: spec}, nil
+}
+
+// fills says which spec capacity a line item's quantity comes from, by
+// phrases in its description. Earlier entries win, so "oil change" is not
+// read as coolant work when the description mentions both.
+var fills = []struct {
+	match    []string
+	capacity func(Spec) float64
+}{
+	{[]string{"oil change", "oil and filter", "oil & filter", "motor oil", "engine oil"}, func(s Spec) float64 { return s.OilQuarts }},
+	{[]string{"coolant", "antifreeze"}, func(s Spec) float64 { return s.CoolantQuarts }},
+}
+
+func (s *service) Quantity(vehicleID, description string) (int, error) {
+	d := strings.ToLower(description)
+	for _, f := range fills {
+		if !containsAny(d, f.match) {
+			continue
+		}
+		vs, err := s.ForVehicle(vehicleID)
+		if errors.Is(err, ErrNoSpec) || errors.Is(err, vehicles.ErrNotFound) {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		// Fluids are bought by the whole quart.
+		return int(math.Ceil(f.capacity(vs.Spec))), nil
+	}
+	return 0, nil
+}
+
+func containsAny(s string, phrases []string) bool {
+	for _, p := range phrases {
+		if strings.Contains(s, p) {
+			return true
+		}
+	}
+	return false
+}
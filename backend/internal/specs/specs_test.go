@@ -0,0 +1,133 @@
+package specs_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+	"github.com/ezmobilemechanic/platform/internal/specs"
+	"github.com/ezmobilemechanic/platform/internal/storage/memory"
+)
+
+const testCSV = `year_from,year_to,make,model,engine,oil_type,oil_quarts,coolant_quarts,tire_size,front_tire_psi,rear_tire_psi,lug_torque_ft_lbs
+2018,2024,Toyota,Camry,,0W-16,4.8,8.9,215/55R17,35,35,76
+2018,2024,Toyota,Camry,3.5L,0W-20,6.4,9.2,235/45R18,35,35,76
+2021,2021,Toyota,Camry,,0W-16,4.5,,215/55R17,36,36,76
+`
+
+func TestReadCSVAndJSON(t *testing.T) {
+	c, err := specs.ReadCSV(strings.NewReader(testCSV))
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if len(c.Specs) != 3 {
+		t.Fatalf("expected 3 specs, got %+v", c.Specs)
+	}
+	if s := c.Specs[0]; s.OilQuarts != 4.8 || s.TireSize != "215/55R17" || s.LugTorqueFtLbs != 76 || s.Engine != "" {
+		t.Fatalf("unexpected spec %+v", s)
+	}
+	if s := c.Specs[2]; s.YearTo != 2021 || s.CoolantQuarts != 0 {
+		t.Fatalf("unexpected spec %+v", s)
+	}
+
+	j, err := specs.Read(strings.NewReader(`{"version": "2026.2", "specs": [
+		{"year_from": 2019, "make": "Honda", "model": "Civic", "oil_type": "0W-20", "oil_quarts": 3.7}
+	]}`))
+	if err != nil {
+		t.Fatalf("read json: %v", err)
+	}
+	if j.Version != "2026.2" || len(j.Specs) != 1 || j.Specs[0].YearTo != 2019 {
+		t.Fatalf("expected year_to to default to year_from, got %+v", j)
+	}
+
+	for name, doc := range map[string]string{
+		"unknown column": "year_from,make,model,oil_qts\n2019,Honda,Civic,3.7\n",
+		"missing model":  "year_from,make\n2019,Honda\n",
+		"not a number":   "year_from,make,model,oil_quarts\n2019,Honda,Civic,lots\n",
+		"year range":     "year_from,year_to,make,model\n2020,2019,Honda,Civic\n",
+		"duplicate":      "year_from,make,model\n2019,Honda,Civic\n2019,honda,civic\n",
+	} {
+		if _, err := specs.ReadCSV(strings.NewReader(doc)); !errors.Is(err, specs.ErrInvalidCatalog) {
+			t.Errorf("%s: expected ErrInvalidCatalog, got %v", name, err)
+		}
+	}
+	if _, err := specs.Read(strings.NewReader(`{"specs": [{"year_from": 2019, "make": "Honda", "model": "Civic", "oil_qts": 3.7}]}`)); !errors.Is(err, specs.ErrInvalidCatalog) {
+		t.Errorf("expected unknown JSON keys to be rejected, got %v", err)
+	}
+}
+
+func TestCatalogFor(t *testing.T) {
+	c, err := specs.ReadCSV(strings.NewReader(testCSV))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	for _, tc := range []struct {
+		name      string
+		v         vehicles.Vehicle
+		oilQuarts float64
+	}{
+		{"generic engine", vehicles.Vehicle{Year: 2019, Make: "toyota", Model: "CAMRY", Engine: "2.5L I4"}, 4.8},
+		{"engine-specific", vehicles.Vehicle{Year: 2019, Make: "Toyota", Model: "Camry", Engine: "3.5L V6"}, 6.4},
+		{"narrower years", vehicles.Vehicle{Year: 2021, Make: "Toyota", Model: "Camry"}, 4.5},
+	} {
+		s, ok := c.For(tc.v)
+		if !ok || s.OilQuarts != tc.oilQuarts {
+			t.Errorf("%s: expected %.1f quarts, got %+v (found %v)", tc.name, tc.oilQuarts, s, ok)
+		}
+	}
+	if s, ok := c.For(vehicles.Vehicle{Year: 2017, Make: "Toyota", Model: "Camry"}); ok {
+		t.Errorf("expected no spec outside the year range, got %+v", s)
+	}
+}
+
+func TestServiceForVehicleAndQuantity(t *testing.T) {
+	c, err := specs.ReadCSV(strings.NewReader(testCSV))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	customerSvc := customers.NewService(memory.NewCustomerRepository())
+	vehicleSvc := vehicles.NewService(memory.NewVehicleRepository())
+	svc := specs.NewService(c, vehicleSvc)
+
+	cust, err := customerSvc.Create(customers.CreateInput{FirstName: "Alex", Email: "alex@example.com"})
+	if err != nil {
+		t.Fatalf("create customer: %v", err)
+	}
+	camry, err := vehicleSvc.Create(vehicles.CreateInput{CustomerID: cust.ID, Year: 2019, Make: "Toyota", Model: "Camry", Engine: "2.5L I4"})
+	if err != nil {
+		t.Fatalf("create vehicle: %v", err)
+	}
+	civic, err := vehicleSvc.Create(vehicles.CreateInput{CustomerID: cust.ID, Year: 2019, Make: "Honda", Model: "Civic"})
+	if err != nil {
+		t.Fatalf("create vehicle: %v", err)
+	}
+
+	vs, err := svc.ForVehicle(camry.ID)
+	if err != nil || vs.VehicleID != camry.ID || vs.Spec.TireSize != "215/55R17" {
+		t.Fatalf("unexpected spec %+v, err %v", vs, err)
+	}
+	if _, err := svc.ForVehicle(civic.ID); !errors.Is(err, specs.ErrNoSpec) {
+		t.Fatalf("expected ErrNoSpec, got %v", err)
+	}
+	if _, err := svc.ForVehicle("missing"); !errors.Is(err, vehicles.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	for _, tc := range []struct {
+		vehicleID, description string
+		want                   int
+	}{
+		{camry.ID, "Synthetic oil change", 5},
+		{camry.ID, "Coolant flush", 9},
+		{camry.ID, "Brake pads", 0},
+		{civic.ID, "Oil change", 0},
+		{"missing", "Oil change", 0},
+	} {
+		got, err := svc.Quantity(tc.vehicleID, tc.description)
+		if err != nil || got != tc.want {
+			t.Errorf("%q: expected %d, got %d (err %v)", tc.description, tc.want, got, err)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Package cache provides read-through caching decorators for the customer,
+// vehicle and quote repositories. Records are cached by ID and invalidated on
+// Save; lists always go to the underlying repository. The backing Store is
+// Redis when REDIS_URL is configured and an in-process LRU otherwise.
+package cache
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"time"
+)
+
+// Store is a byte-oriented key/value cache.
+type Store interface {
+	// Get returns the cached value and whether it was present.
+	Get(key string) ([]byte, bool, error)
+	Set(key string, value []byte, ttl time.Duration) error
+	Delete(key string) error
+}
+
+// keyPrefix namespaces cache keys; bump the version when the encoded shape of
+// a domain type changes so stale entries are ignored after a deploy.
+const keyPrefix = "ezm:v2:"
+
+// Cache binds a Store to the entry TTL used by the repository decorators.
+// Store failures are logged and treated as misses so an unavailable cache
+// never fails a request.
+type Cache struct {
+	store  Store
+	ttl    time.Duration
+	logger *slog.Logger
+}
+
+// New returns a Cache that keeps entries for ttl.
+func New(store Store, ttl time.Duration, logger *slog.Logger) *Cache {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Cache{store: store, ttl: ttl, logger: logger}
+}
+
+func key(kind, id string) string {
+	return keyPrefix + kind + ":" + id
+}
+
+// readThrough returns the cached value for key, calling fetch and populating
+// the cache on a miss. Errors from fetch are returned and never cached.
+//
+// key holds a generation token and the value is cached under key and token.
+// The token is in place before fetch runs, and invalidate removes it, so a
+// fill that read the record before a concurrent write lands under a token no
+// reader will use again, instead of overwriting the invalidation.
+func readThrough[T any](c *Cache, key string, fetch func() (T, error)) (T, error) {
+	gen, ok := c.generation(key)
+	if ok {
+		data, hit, err := c.store.Get(key + "@" + gen)
+		if err != nil {
+			c.logger.Warn("cache get failed", "key", key, "err", err)
+		} else if hit {
+			var v T
+			if err := json.Unmarshal(data, &v); err == nil {
+				return v, nil
+			}
+			c.logger.Warn("discarding undecodable cache entry", "key", key, "err", err)
+		}
+	}
+
+	v, err := fetch()
+	if err != nil || !ok {
+		return v, err
+	}
+	if data, err := json.Marshal(v); err != nil {
+		c.logger.Warn("cache encode failed", "key", key, "err", err)
+	} else if err := c.store.Set(key+"@"+gen, data, c.ttl); err != nil {
+		c.logger.Warn("cache set failed", "key", key, "err", err)
+	}
+	return v, nil
+}
+
+// generation returns the current generation token of key, starting a new one
+// if it has none. It reports false when the store cannot be used.
+func (c *Cache) generation(key string) (string, bool) {
+	data, ok, err := c.store.Get(key)
+	if err != nil {
+		c.logger.Warn("cache get failed", "key", key, "err", err)
+		return "", false
+	}
+	if ok {
+		return string(data), true
+	}
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		c.logger.Warn("cache generation failed", "key", key, "err", err)
+		return "", false
+	}
+	gen := hex.EncodeToString(b)
+	if err := c.store.Set(key, []byte(gen), c.ttl); err != nil {
+		c.logger.Warn("cache set failed", "key", key, "err", err)
+		return "", false
+	}
+	return gen, true
+}
+
+// invalidate drops key's generation, orphaning every value cached under it.
+// A failure leaves the entry to expire with its TTL.
+func (c *Cache) invalidate(key string) {
+	if err := c.store.Delete(key); err != nil {
+		c.logger.Warn("cache invalidate failed", "key", key, "err", err)
+	}
+}
@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRU(2)
+	c.Set("a", []byte("1"), 0)
+	c.Set("b", []byte("2"), 0)
+	if _, ok, _ := c.Get("a"); !ok {
+		t.Fatalf("expected a to be cached")
+	}
+	c.Set("c", []byte("3"), 0)
+
+	if _, ok, _ := c.Get("b"); ok {
+		t.Fatalf("expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok, _ := c.Get(key); !ok {
+			t.Fatalf("expected %s to be cached", key)
+		}
+	}
+	if c.Len() != 2 {
+		t.Fatalf("expected 2 entries, got %d", c.Len())
+	}
+}
+
+func TestLRUExpiresEntries(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewLRU(10)
+	c.now = func() time.Time { return now }
+
+	c.Set("k", []byte("v"), time.Minute)
+	now = now.Add(59 * time.Second)
+	if v, ok, _ := c.Get("k"); !ok || string(v) != "v" {
+		t.Fatalf("expected entry before expiry, got %q %v", v, ok)
+	}
+	now = now.Add(time.Second)
+	if _, ok, _ := c.Get("k"); ok {
+		t.Fatalf("expected entry to expire")
+	}
+	if c.Len() != 0 {
+		t.Fatalf("expected expired entry to be dropped")
+	}
+}
+
+func TestLRUDelete(t *testing.T) {
+	c := NewLRU(10)
+	c.Set("k", []byte("v"), 0)
+	c.Delete("k")
+	if _, ok, _ := c.Get("k"); ok {
+		t.Fatalf("expected entry to be deleted")
+	}
+}
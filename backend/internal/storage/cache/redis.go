@@ -0,0 +1,256 @@
+package cache
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Redis is a Store backed by a Redis server. It speaks the subset of RESP2
+// needed for GET/SET/DEL over a small pool of connections.
+type Redis struct {
+	addr     string
+	username string
+	password string
+	db       int
+	useTLS   bool
+	timeout  time.Duration
+	pool     chan *redisConn
+}
+
+const (
+	defaultRedisPoolSize = 8
+	defaultRedisTimeout  = 500 * time.Millisecond
+)
+
+// redisError is an error reply from the server. The connection remains usable.
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+// NewRedis parses a redis:// or rediss:// URL
+// (redis://[user:password@]host[:port][/db]). No connection is made until the
+// first command; call Ping to verify connectivity at startup.
+func NewRedis(rawURL string) (*Redis, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse redis url: %w", err)
+	}
+	if u.Scheme != "redis" && u.Scheme != "rediss" {
+		return nil, fmt.Errorf("unsupported redis url scheme %q", u.Scheme)
+	}
+
+	r := &Redis{
+		addr:    u.Host,
+		useTLS:  u.Scheme == "rediss",
+		timeout: defaultRedisTimeout,
+		pool:    make(chan *redisConn, defaultRedisPoolSize),
+	}
+	if u.Port() == "" {
+		r.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		r.username = u.User.Username()
+		r.password, _ = u.User.Password()
+	}
+	if dbPath := strings.Trim(u.Path, "/"); dbPath != "" {
+		if r.db, err = strconv.Atoi(dbPath); err != nil {
+			return nil, fmt.Errorf("invalid redis database %q", dbPath)
+		}
+	}
+	return r, nil
+}
+
+// Ping checks that the server is reachable and the credentials are valid.
+func (r *Redis) Ping() error {
+	_, err := r.do("PING")
+	return err
+}
+
+// Get implements Store.
+func (r *Redis) Get(key string) ([]byte, bool, error) {
+	reply, err := r.do("GET", key)
+	if err != nil || reply == nil {
+		return nil, false, err
+	}
+	b, ok := reply.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("redis: unexpected GET reply %T", reply)
+	}
+	return b, true, nil
+}
+
+// Set implements Store.
+func (r *Redis) Set(key string, value []byte, ttl time.Duration) error {
+	if ttl > 0 {
+		_, err := r.do("SET", key, string(value), "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+		return err
+	}
+	_, err := r.do("SET", key, string(value))
+	return err
+}
+
+// Delete implements Store.
+func (r *Redis) Delete(key string) error {
+	_, err := r.do("DEL", key)
+	return err
+}
+
+// Close releases pooled connections.
+func (r *Redis) Close() error {
+	for {
+		select {
+		case c := <-r.pool:
+			c.conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+func (r *Redis) do(args ...string) (any, error) {
+	c, err := r.get()
+	if err != nil {
+		return nil, err
+	}
+	reply, err := c.do(r.timeout, args...)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		// The stream may be out of sync; never reuse the connection.
+		c.conn.Close()
+		return nil, err
+	}
+	r.put(c)
+	return reply, err
+}
+
+func (r *Redis) get() (*redisConn, error) {
+	select {
+	case c := <-r.pool:
+		return c, nil
+	default:
+		return r.dial()
+	}
+}
+
+func (r *Redis) put(c *redisConn) {
+	select {
+	case r.pool <- c:
+	default:
+		c.conn.Close()
+	}
+}
+
+func (r *Redis) dial() (*redisConn, error) {
+	dialer := &net.Dialer{Timeout: r.timeout}
+	var conn net.Conn
+	var err error
+	if r.useTLS {
+		host, _, _ := net.SplitHostPort(r.addr)
+		conn, err = tls.DialWithDialer(dialer, "tcp", r.addr, &tls.Config{ServerName: host})
+	} else {
+		conn, err = dialer.Dial("tcp", r.addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redis dial: %w", err)
+	}
+
+	c := &redisConn{conn: conn, r: bufio.NewReader(conn)}
+	if r.password != "" {
+		auth := []string{"AUTH", r.password}
+		if r.username != "" {
+			auth = []string{"AUTH", r.username, r.password}
+		}
+		if _, err := c.do(r.timeout, auth...); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if r.db != 0 {
+		if _, err := c.do(r.timeout, "SELECT", strconv.Itoa(r.db)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+type redisConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func (c *redisConn) do(timeout time.Duration, args ...string) (any, error) {
+	if err := c.conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.conn, b.String()); err != nil {
+		return nil, err
+	}
+	return readReply(c.r)
+}
+
+// readReply decodes one RESP2 value: simple strings as string, integers as
+// int64, bulk strings as []byte, arrays as []any and nil bulk/array as nil.
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || !strings.HasSuffix(line, "\r\n") {
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, redisError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("redis: bad bulk length %q", body)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("redis: bad array length %q", body)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		out := make([]any, n)
+		for i := range out {
+			if out[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("redis: unknown reply type %q", kind)
+	}
+}
@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis serves GET/SET/DEL/PING/AUTH/SELECT from a map.
+type fakeRedis struct {
+	ln       net.Listener
+	password string
+
+	mu   sync.Mutex
+	data map[string]string
+	cmds []string
+}
+
+func startFakeRedis(t *testing.T, password string) *fakeRedis {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	f := &fakeRedis{ln: ln, password: password, data: map[string]string{}}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	authed := f.password == ""
+	for {
+		reply, err := readReply(r)
+		if err != nil {
+			return
+		}
+		var args []string
+		for _, a := range reply.([]any) {
+			args = append(args, string(a.([]byte)))
+		}
+
+		f.mu.Lock()
+		f.cmds = append(f.cmds, strings.Join(args, " "))
+		var out string
+		switch cmd := strings.ToUpper(args[0]); {
+		case cmd == "AUTH":
+			authed = args[len(args)-1] == f.password
+			out = "+OK\r\n"
+			if !authed {
+				out = "-WRONGPASS invalid password\r\n"
+			}
+		case !authed:
+			out = "-NOAUTH Authentication required.\r\n"
+		case cmd == "PING":
+			out = "+PONG\r\n"
+		case cmd == "SELECT":
+			out = "+OK\r\n"
+		case cmd == "GET":
+			if v, ok := f.data[args[1]]; ok {
+				out = "$" + strconv.Itoa(len(v)) + "\r\n" + v + "\r\n"
+			} else {
+				out = "$-1\r\n"
+			}
+		case cmd == "SET":
+			f.data[args[1]] = args[2]
+			out = "+OK\r\n"
+		case cmd == "DEL":
+			_, ok := f.data[args[1]]
+			delete(f.data, args[1])
+			out = ":0\r\n"
+			if ok {
+				out = ":1\r\n"
+			}
+		default:
+			out = "-ERR unknown command\r\n"
+		}
+		f.mu.Unlock()
+
+		if _, err := conn.Write([]byte(out)); err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakeRedis) commands() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.cmds...)
+}
+
+func TestRedisStore(t *testing.T) {
+	srv := startFakeRedis(t, "secret")
+	r, err := NewRedis("redis://:secret@" + srv.ln.Addr().String() + "/2")
+	if err != nil {
+		t.Fatalf("new redis: %v", err)
+	}
+	defer r.Close()
+
+	if err := r.Ping(); err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+	if _, ok, err := r.Get("k"); err != nil || ok {
+		t.Fatalf("expected miss, got %v %v", ok, err)
+	}
+	if err := r.Set("k", []byte("value\r\nwith crlf"), time.Minute); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	v, ok, err := r.Get("k")
+	if err != nil || !ok || string(v) != "value\r\nwith crlf" {
+		t.Fatalf("expected hit, got %q %v %v", v, ok, err)
+	}
+	if err := r.Delete("k"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, ok, _ := r.Get("k"); ok {
+		t.Fatalf("expected miss after delete")
+	}
+
+	cmds := srv.commands()
+	if cmds[0] != "AUTH secret" || cmds[1] != "SELECT 2" {
+		t.Fatalf("expected AUTH and SELECT on connect, got %v", cmds[:2])
+	}
+	var sawTTL bool
+	for _, c := range cmds {
+		if strings.HasPrefix(c, "SET k ") && strings.HasSuffix(c, " PX 60000") {
+			sawTTL = true
+		}
+	}
+	if !sawTTL {
+		t.Fatalf("expected SET with PX ttl, got %v", cmds)
+	}
+}
+
+func TestRedisAuthFailure(t *testing.T) {
+	srv := startFakeRedis(t, "secret")
+	r, err := NewRedis("redis://:wrong@" + srv.ln.Addr().String())
+	if err != nil {
+		t.Fatalf("new redis: %v", err)
+	}
+	if err := r.Ping(); err == nil {
+		t.Fatalf("expected auth failure")
+	}
+}
+
+func TestNewRedisRejectsBadURL(t *testing.T) {
+	for _, raw := range []string{"http://localhost", "redis://localhost/notanumber"} {
+		if _, err := NewRedis(raw); err == nil {
+			t.Fatalf("expected error for %q", raw)
+		}
+	}
+}
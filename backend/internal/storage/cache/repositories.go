@@ -0,0 +1,179 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
+	"github.com/ezmobilemechanic/platform/internal/domain/quotes"
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+)
+
+// CustomerRepository caches customers.Repository lookups by ID.
+type CustomerRepository struct {
+	next  customers.Repository
+	cache *Cache
+}
+
+// NewCustomerRepository wraps next with c.
+func NewCustomerRepository(next customers.Repository, c *Cache) *CustomerRepository {
+	return &CustomerRepository{next: next, cache: c}
+}
+
+func (r *CustomerRepository) FindByID(id string) (customers.Customer, error) {
+	return readThrough(r.cache, key("customer", id), func() (customers.Customer, error) {
+		return r.next.FindByID(id)
+	})
+}
+
+func (r *CustomerRepository) Save(customer customers.Customer) (customers.Customer, error) {
+	saved, err := r.next.Save(customer)
+	if err != nil {
+		return saved, err
+	}
+	r.cache.invalidate(key("customer", saved.ID))
+	return saved, nil
+}
+
+func (r *CustomerRepository) List(spec listing.Spec) (listing.Page[customers.Customer], error) {
+	return r.next.List(spec)
+}
+
+func (r *CustomerRepository) Delete(id string) (customers.Customer, error) {
+	deleted, err := r.next.Delete(id)
+	if err != nil {
+		return deleted, err
+	}
+	r.cache.invalidate(key("customer", id))
+	return deleted, nil
+}
+
+// Restore needs no invalidation: deleted customers are never cached.
+func (r *CustomerRepository) Restore(id string) (customers.Customer, error) {
+	return r.next.Restore(id)
+}
+
+// Purge only removes customers that were already evicted by Delete.
+func (r *CustomerRepository) Purge(deletedBefore time.Time) (int, error) {
+	return r.next.Purge(deletedBefore)
+}
+
+func (r *CustomerRepository) FindMatches(keys customers.MatchKeys) ([]customers.Customer, error) {
+	return r.next.FindMatches(keys)
+}
+
+// Merge evicts both customers and every re-parented vehicle and quote, whose
+// cached copies still name the merged customer.
+func (r *CustomerRepository) Merge(survivor customers.Customer, mergedID string) (customers.Merge, error) {
+	m, err := r.next.Merge(survivor, mergedID)
+	if err != nil {
+		return m, err
+	}
+	r.cache.invalidate(key("customer", survivor.ID))
+	r.cache.invalidate(key("customer", mergedID))
+	for _, id := range m.VehicleIDs {
+		r.cache.invalidate(key("vehicle", id))
+	}
+	for _, id := range m.QuoteIDs {
+		r.cache.invalidate(key("quote", id))
+	}
+	return m, nil
+}
+
+func (r *CustomerRepository) MergedInto(id string) (string, error) {
+	return r.next.MergedInto(id)
+}
+
+// VehicleRepository caches vehicles.Repository lookups by ID.
+type VehicleRepository struct {
+	next  vehicles.Repository
+	cache *Cache
+}
+
+// NewVehicleRepository wraps next with c.
+func NewVehicleRepository(next vehicles.Repository, c *Cache) *VehicleRepository {
+	return &VehicleRepository{next: next, cache: c}
+}
+
+func (r *VehicleRepository) FindByID(id string) (vehicles.Vehicle, error) {
+	return readThrough(r.cache, key("vehicle", id), func() (vehicles.Vehicle, error) {
+		return r.next.FindByID(id)
+	})
+}
+
+func (r *VehicleRepository) ListByCustomer(customerID string) ([]vehicles.Vehicle, error) {
+	return r.next.ListByCustomer(customerID)
+}
+
+func (r *VehicleRepository) Save(vehicle vehicles.Vehicle) (vehicles.Vehicle, error) {
+	saved, err := r.next.Save(vehicle)
+	if err != nil {
+		return saved, err
+	}
+	r.cache.invalidate(key("vehicle", saved.ID))
+	return saved, nil
+}
+
+func (r *VehicleRepository) Transfer(t vehicles.Transfer, readings []vehicles.Reading) (vehicles.Transfer, error) {
+	saved, err := r.next.Transfer(t, readings)
+	if err != nil {
+		return saved, err
+	}
+	r.cache.invalidate(key("vehicle", t.VehicleID))
+	return saved, nil
+}
+
+func (r *VehicleRepository) ListTransfers(vehicleID string) ([]vehicles.Transfer, error) {
+	return r.next.ListTransfers(vehicleID)
+}
+
+// QuoteRepository caches quotes.Repository lookups by ID, line items
+// included.
+type QuoteRepository struct {
+	next  quotes.Repository
+	cache *Cache
+}
+
+// NewQuoteRepository wraps next with c.
+func NewQuoteRepository(next quotes.Repository, c *Cache) *QuoteRepository {
+	return &QuoteRepository{next: next, cache: c}
+}
+
+func (r *QuoteRepository) FindByID(id string) (quotes.Quote, error) {
+	return readThrough(r.cache, key("quote", id), func() (quotes.Quote, error) {
+		return r.next.FindByID(id)
+	})
+}
+
+func (r *QuoteRepository) Save(q quotes.Quote) (quotes.Quote, error) {
+	saved, err := r.next.Save(q)
+	if err != nil {
+		return saved, err
+	}
+	r.cache.invalidate(key("quote", saved.ID))
+	return saved, nil
+}
+
+func (r *QuoteRepository) UpdateStatus(q quotes.Quote, change quotes.StatusChange) (quotes.Quote, error) {
+	saved, err := r.next.UpdateStatus(q, change)
+	if err != nil {
+		return saved, err
+	}
+	r.cache.invalidate(key("quote", saved.ID))
+	return saved, nil
+}
+
+func (r *QuoteRepository) ListByCustomer(customerID string, spec listing.Spec) (listing.Page[quotes.Quote], error) {
+	return r.next.ListByCustomer(customerID, spec)
+}
+
+func (r *QuoteRepository) ListByVehicle(vehicleID string) ([]quotes.Quote, error) {
+	return r.next.ListByVehicle(vehicleID)
+}
+
+// Ensure decorators satisfy the repository interfaces.
+var (
+	_ customers.Repository = (*CustomerRepository)(nil)
+	_ vehicles.Repository  = (*VehicleRepository)(nil)
+	_ quotes.Repository    = (*QuoteRepository)(nil)
+)
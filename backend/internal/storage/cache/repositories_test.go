@@ -0,0 +1,157 @@
+package cache_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/quotes"
+	"github.com/ezmobilemechanic/platform/internal/storage/cache"
+	"github.com/ezmobilemechanic/platform/internal/storage/memory"
+	"github.com/ezmobilemechanic/platform/internal/storage/storagetest"
+)
+
+// countingCustomers records how many lookups reach the underlying repository.
+type countingCustomers struct {
+	customers.Repository
+	finds int
+}
+
+func (r *countingCustomers) FindByID(id string) (customers.Customer, error) {
+	r.finds++
+	return r.Repository.FindByID(id)
+}
+
+func TestCustomerReadThroughAndInvalidate(t *testing.T) {
+	next := &countingCustomers{Repository: memory.NewCustomerRepository()}
+	repo := cache.NewCustomerRepository(next, cache.New(cache.NewLRU(100), 0, nil))
+
+	saved, err := repo.Save(customers.Customer{FirstName: "Ada", Email: "ada@example.com"})
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		got, err := repo.FindByID(saved.ID)
+		if err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		if got.FirstName != "Ada" || !got.CreatedAt.Equal(saved.CreatedAt) {
+			t.Fatalf("unexpected customer %+v", got)
+		}
+	}
+	if next.finds != 1 {
+		t.Fatalf("expected one underlying lookup, got %d", next.finds)
+	}
+
+	saved.FirstName = "Grace"
+	if _, err := repo.Save(saved); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	got, err := repo.FindByID(saved.ID)
+	if err != nil {
+		t.Fatalf("find after update: %v", err)
+	}
+	if got.FirstName != "Grace" || next.finds != 2 {
+		t.Fatalf("expected fresh read after save, got %q with %d lookups", got.FirstName, next.finds)
+	}
+}
+
+// racingCustomers runs onFind once, after reading a customer and before
+// returning it, as a write landing while a cache fill is in flight.
+type racingCustomers struct {
+	customers.Repository
+	onFind func()
+}
+
+func (r *racingCustomers) FindByID(id string) (customers.Customer, error) {
+	c, err := r.Repository.FindByID(id)
+	if hook := r.onFind; hook != nil {
+		r.onFind = nil
+		hook()
+	}
+	return c, err
+}
+
+func TestLateFillDoesNotOutliveSave(t *testing.T) {
+	next := &racingCustomers{Repository: memory.NewCustomerRepository()}
+	repo := cache.NewCustomerRepository(next, cache.New(cache.NewLRU(100), 0, nil))
+
+	saved, err := repo.Save(customers.Customer{FirstName: "Ada", Email: "ada@example.com"})
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	next.onFind = func() {
+		updated := saved
+		updated.FirstName = "Grace"
+		if _, err := repo.Save(updated); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+	}
+	if got, err := repo.FindByID(saved.ID); err != nil || got.FirstName != "Ada" {
+		t.Fatalf("expected the record as read, got %+v, %v", got, err)
+	}
+	got, err := repo.FindByID(saved.ID)
+	if err != nil || got.FirstName != "Grace" {
+		t.Fatalf("expected the fill read before the save to be dropped, got %+v, %v", got, err)
+	}
+}
+
+func TestNotFoundIsNotCached(t *testing.T) {
+	next := &countingCustomers{Repository: memory.NewCustomerRepository()}
+	repo := cache.NewCustomerRepository(next, cache.New(cache.NewLRU(100), 0, nil))
+
+	for i := 0; i < 2; i++ {
+		if _, err := repo.FindByID("missing"); !errors.Is(err, customers.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	}
+	if next.finds != 2 {
+		t.Fatalf("expected misses to reach the repository, got %d lookups", next.finds)
+	}
+}
+
+func TestQuoteLineItemsSurviveCache(t *testing.T) {
+	repo := cache.NewQuoteRepository(memory.NewQuoteRepository(), cache.New(cache.NewLRU(100), 0, nil))
+
+	saved, err := repo.Save(quotes.Quote{
+		CustomerID: "c1",
+		Status:     quotes.StatusDraft,
+		LineItems:  []quotes.LineItem{{Description: "Pads", Quantity: 2, UnitPrice: 4500, LaborHours: 1.25}},
+	})
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		got, err := repo.FindByID(saved.ID)
+		if err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		if len(got.LineItems) != 1 || got.LineItems[0] != saved.LineItems[0] {
+			t.Fatalf("line items mismatch: %+v", got.LineItems)
+		}
+	}
+}
+
+// The decorators must be transparent to the repository contract.
+func TestRepositoryContract(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storagetest.Backend {
+		c := cache.New(cache.NewLRU(100), 0, nil)
+		customerRepo, vehicleRepo, quoteRepo := memory.NewCustomerRepository(), memory.NewVehicleRepository(), memory.NewQuoteRepository()
+		customerRepo.SetVehicles(vehicleRepo)
+		customerRepo.SetQuotes(quoteRepo)
+		return storagetest.Backend{
+			Customers:    cache.NewCustomerRepository(customerRepo, c),
+			Addresses:    customerRepo.Addresses(),
+			Consents:     customerRepo.Consents(),
+			Notes:        customerRepo.Notes(),
+			Contacts:     customerRepo.Contacts(),
+			Vehicles:     cache.NewVehicleRepository(vehicleRepo, c),
+			Odometer:     vehicleRepo.Odometer(),
+			Recalls:      vehicleRepo.Recalls(),
+			Quotes:       cache.NewQuoteRepository(quoteRepo, c),
+			QuoteHistory: quoteRepo.History(),
+			Users:        memory.NewUserRepository(),
+			Erasures:     memory.NewErasureRequestRepository(),
+		}
+	})
+}
@@ -0,0 +1,402 @@
+// Package filestore makes the in-memory repositories durable for single-node
+// deployments without Postgres (DATA_BACKEND=file). Every mutation is appended
+// to a write-ahead log before it is applied; the full state is periodically
+// written to a JSON snapshot, after which the log is discarded. On boot the
+// snapshot is loaded and the log replayed on top of it.
+package filestore
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/storage/memory"
+)
+
+// ErrClosed is returned when recording a mutation after Close.
+var ErrClosed = errors.New("file store closed")
+
+const (
+	snapshotName   = "snapshot.json"
+	walName        = "wal.log"
+	rotatedWALName = "wal.log.1"
+
+	snapshotVersion = 1
+
+	// DefaultSnapshotInterval is used when Options.SnapshotInterval is zero.
+	DefaultSnapshotInterval = 5 * time.Minute
+)
+
+// Options configures the file store.
+type Options struct {
+	Dir string
+	// SnapshotInterval controls periodic snapshots; negative disables them
+	// (a final snapshot is still taken on Close).
+	SnapshotInterval time.Duration
+	Logger           *slog.Logger
+}
+
+// Store persists a set of memory repositories under a data directory.
+type Store struct {
+	dir    string
+	logger *slog.Logger
+	tables []memory.Persistent
+	byName map[string]memory.Persistent
+
+	mu  sync.Mutex // guards wal
+	wal *os.File
+
+	snapMu sync.Mutex // serializes snapshots
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type snapshot struct {
+	Version int                        `json:"version"`
+	TakenAt time.Time                  `json:"taken_at"`
+	Tables  map[string]json.RawMessage `json:"tables"`
+}
+
+type walEntry struct {
+	Table string          `json:"table"`
+	Op    memory.Op       `json:"op"`
+	ID    string          `json:"id"`
+	Data  json.RawMessage `json:"data,omitempty"`
+}
+
+// Open restores the tables from dir, installs the store as their journal and
+// starts periodic snapshots. Callers must Close the store on shutdown.
+func Open(opts Options, tables ...memory.Persistent) (*Store, error) {
+	if opts.Dir == "" {
+		return nil, errors.New("file store requires a data directory")
+	}
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create data dir: %w", err)
+	}
+
+	s := &Store{
+		dir:    opts.Dir,
+		logger: logger,
+		tables: tables,
+		byName: make(map[string]memory.Persistent, len(tables)),
+	}
+	for _, t := range tables {
+		if _, dup := s.byName[t.Table()]; dup {
+			return nil, fmt.Errorf("duplicate table %q", t.Table())
+		}
+		s.byName[t.Table()] = t
+	}
+
+	if err := s.loadSnapshot(); err != nil {
+		return nil, err
+	}
+	replayed := 0
+	for _, name := range []string{rotatedWALName, walName} {
+		n, err := s.replay(filepath.Join(s.dir, name))
+		if err != nil {
+			return nil, err
+		}
+		replayed += n
+	}
+
+	if err := s.openWAL(); err != nil {
+		return nil, err
+	}
+	for _, t := range tables {
+		t.SetJournal(s)
+	}
+
+	// Fold the replayed log into a fresh snapshot so the log starts empty.
+	if err := s.Snapshot(); err != nil {
+		s.wal.Close()
+		return nil, err
+	}
+	logger.Info("file store opened", "dir", s.dir, "replayed", replayed)
+
+	interval := opts.SnapshotInterval
+	if interval == 0 {
+		interval = DefaultSnapshotInterval
+	}
+	if interval > 0 {
+		s.stop = make(chan struct{})
+		s.done = make(chan struct{})
+		go s.loop(interval)
+	}
+	return s, nil
+}
+
+// Record implements memory.Journal by appending an fsynced log entry.
+func (s *Store) Record(table string, op memory.Op, id string, value any) error {
+	entry := walEntry{Table: table, Op: op, ID: id}
+	if op == memory.OpPut {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		entry.Data = data
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.wal == nil {
+		return ErrClosed
+	}
+	if _, err := s.wal.Write(line); err != nil {
+		return err
+	}
+	return s.wal.Sync()
+}
+
+// Snapshot writes the current state of every table and discards the log
+// entries it covers. It is safe to call concurrently with writes.
+func (s *Store) Snapshot() error {
+	s.snapMu.Lock()
+	defer s.snapMu.Unlock()
+
+	if err := s.rotateWAL(); err != nil {
+		return err
+	}
+
+	// Writers that logged to the rotated file hold their repository lock
+	// until the change is applied, so exporting afterwards observes them.
+	snap := snapshot{
+		Version: snapshotVersion,
+		TakenAt: time.Now().UTC(),
+		Tables:  make(map[string]json.RawMessage, len(s.tables)),
+	}
+	for _, t := range s.tables {
+		data, err := json.Marshal(t.Export())
+		if err != nil {
+			return fmt.Errorf("export %s: %w", t.Table(), err)
+		}
+		snap.Tables[t.Table()] = data
+	}
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(s.dir, snapshotName), data); err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+
+	if err := os.Remove(filepath.Join(s.dir, rotatedWALName)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove rotated log: %w", err)
+	}
+	return nil
+}
+
+// Close stops periodic snapshots, takes a final snapshot and closes the log.
+func (s *Store) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		if s.stop != nil {
+			close(s.stop)
+			<-s.done
+		}
+		err = s.Snapshot()
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if cerr := s.wal.Close(); err == nil {
+			err = cerr
+		}
+		s.wal = nil
+	})
+	return err
+}
+
+func (s *Store) loop(interval time.Duration) {
+	defer close(s.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if err := s.Snapshot(); err != nil {
+				s.logger.Error("periodic snapshot failed", "err", err)
+			}
+		}
+	}
+}
+
+func (s *Store) loadSnapshot() error {
+	data, err := os.ReadFile(filepath.Join(s.dir, snapshotName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read snapshot: %w", err)
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("decode snapshot: %w", err)
+	}
+	if snap.Version != snapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", snap.Version)
+	}
+	for name, raw := range snap.Tables {
+		t, ok := s.byName[name]
+		if !ok {
+			s.logger.Warn("snapshot contains unknown table; ignoring", "table", name)
+			continue
+		}
+		if err := t.Import(raw); err != nil {
+			return fmt.Errorf("import %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// replay applies the entries of one log file. A torn final line, left by a
+// crash mid-append, is ignored; corruption anywhere else is an error.
+func (s *Store) replay(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("read %s: %w", filepath.Base(path), err)
+	}
+
+	applied := 0
+	reader := bufio.NewReader(bytes.NewReader(data))
+	for lineNo := 1; ; lineNo++ {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(bytes.TrimSpace(line)) > 0 {
+				s.logger.Warn("ignoring torn log entry", "file", filepath.Base(path), "line", lineNo)
+			}
+			return applied, nil
+		}
+		if err != nil {
+			return applied, err
+		}
+
+		var entry walEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return applied, fmt.Errorf("%s line %d: %w", filepath.Base(path), lineNo, err)
+		}
+		t, ok := s.byName[entry.Table]
+		if !ok {
+			s.logger.Warn("log contains unknown table; ignoring", "table", entry.Table)
+			continue
+		}
+		if err := t.Apply(entry.Op, entry.ID, entry.Data); err != nil {
+			return applied, fmt.Errorf("%s line %d: %w", filepath.Base(path), lineNo, err)
+		}
+		applied++
+	}
+}
+
+func (s *Store) openWAL() error {
+	f, err := os.OpenFile(filepath.Join(s.dir, walName), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open log: %w", err)
+	}
+	s.wal = f
+	return nil
+}
+
+// rotateWAL moves the live log aside so a snapshot can supersede it. If an
+// earlier snapshot failed and left a rotated log behind, the live log is
+// appended to it rather than overwriting it.
+func (s *Store) rotateWAL() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.wal == nil {
+		return ErrClosed
+	}
+
+	livePath := filepath.Join(s.dir, walName)
+	rotatedPath := filepath.Join(s.dir, rotatedWALName)
+
+	if err := s.wal.Close(); err != nil {
+		return err
+	}
+	s.wal = nil
+
+	if _, err := os.Stat(rotatedPath); err == nil {
+		if err := appendFile(rotatedPath, livePath); err != nil {
+			return fmt.Errorf("merge log: %w", err)
+		}
+		if err := os.Remove(livePath); err != nil {
+			return err
+		}
+	} else if err := os.Rename(livePath, rotatedPath); err != nil {
+		return fmt.Errorf("rotate log: %w", err)
+	}
+
+	return s.openWAL()
+}
+
+func appendFile(dst, src string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(dst, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	dir, err := os.Open(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
@@ -0,0 +1,181 @@
+package filestore_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/quotes"
+	"github.com/ezmobilemechanic/platform/internal/storage/filestore"
+	"github.com/ezmobilemechanic/platform/internal/storage/memory"
+	"github.com/ezmobilemechanic/platform/internal/storage/storagetest"
+)
+
+type repos struct {
+	customers *memory.CustomerRepository
+	vehicles  *memory.VehicleRepository
+	quotes    *memory.QuoteRepository
+	users     *memory.UserRepository
+	erasures  *memory.ErasureRequestRepository
+}
+
+func open(t *testing.T, dir string) (repos, *filestore.Store) {
+	t.Helper()
+	r := repos{
+		customers: memory.NewCustomerRepository(),
+		vehicles:  memory.NewVehicleRepository(),
+		quotes:    memory.NewQuoteRepository(),
+		users:     memory.NewUserRepository(),
+		erasures:  memory.NewErasureRequestRepository(),
+	}
+	r.customers.SetVehicles(r.vehicles)
+	r.customers.SetQuotes(r.quotes)
+	store, err := filestore.Open(filestore.Options{Dir: dir, SnapshotInterval: -1},
+		r.customers, r.customers.Merges(), r.customers.Addresses(), r.customers.Consents(), r.customers.Notes(),
+		r.customers.Contacts(), r.vehicles, r.vehicles.Transfers(), r.vehicles.Odometer(), r.vehicles.Recalls(), r.quotes, r.quotes.History(), r.users, r.erasures)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	return r, store
+}
+
+func TestRepositoryContract(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storagetest.Backend {
+		r, store := open(t, t.TempDir())
+		t.Cleanup(func() { store.Close() })
+		return storagetest.Backend{
+			Customers:    r.customers,
+			Addresses:    r.customers.Addresses(),
+			Consents:     r.customers.Consents(),
+			Notes:        r.customers.Notes(),
+			Contacts:     r.customers.Contacts(),
+			Vehicles:     r.vehicles,
+			Odometer:     r.vehicles.Odometer(),
+			Recalls:      r.vehicles.Recalls(),
+			Quotes:       r.quotes,
+			QuoteHistory: r.quotes.History(),
+			Users:        r.users,
+			Erasures:     r.erasures,
+		}
+	})
+}
+
+func TestReloadAfterClose(t *testing.T) {
+	dir := t.TempDir()
+	r, store := open(t, dir)
+
+	c, err := r.customers.Save(customers.Customer{FirstName: "Ada", Email: "ada@example.com"})
+	if err != nil {
+		t.Fatalf("save customer: %v", err)
+	}
+	q, err := r.quotes.Save(quotes.Quote{
+		CustomerID: c.ID,
+		Status:     quotes.StatusDraft,
+		LineItems:  []quotes.LineItem{{Description: "Oil change", Quantity: 1, UnitPrice: 4500}},
+	})
+	if err != nil {
+		t.Fatalf("save quote: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if info, err := os.Stat(filepath.Join(dir, "wal.log")); err != nil || info.Size() != 0 {
+		t.Fatalf("expected empty log after close, got %v / %v", info, err)
+	}
+
+	reopened, store := open(t, dir)
+	defer store.Close()
+
+	gotCustomer, err := reopened.customers.FindByID(c.ID)
+	if err != nil {
+		t.Fatalf("find customer: %v", err)
+	}
+	if gotCustomer.Email != c.Email || !gotCustomer.CreatedAt.Equal(c.CreatedAt) {
+		t.Fatalf("customer mismatch: want %+v, got %+v", c, gotCustomer)
+	}
+	gotQuote, err := reopened.quotes.FindByID(q.ID)
+	if err != nil {
+		t.Fatalf("find quote: %v", err)
+	}
+	if len(gotQuote.LineItems) != 1 || gotQuote.LineItems[0] != q.LineItems[0] {
+		t.Fatalf("line items mismatch: want %+v, got %+v", q.LineItems, gotQuote.LineItems)
+	}
+}
+
+func TestReplayAfterCrash(t *testing.T) {
+	dir := t.TempDir()
+	r, _ := open(t, dir)
+
+	// Writes after the boot snapshot live only in the log; the store is
+	// abandoned without Close to simulate a crash.
+	first, err := r.customers.Save(customers.Customer{FirstName: "Before", Email: "crash@example.com"})
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	first.FirstName = "After"
+	if _, err := r.customers.Save(first); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	// A half-written trailing entry must not prevent recovery.
+	f, err := os.OpenFile(filepath.Join(dir, "wal.log"), os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("open log: %v", err)
+	}
+	if _, err := f.WriteString(`{"table":"customers","op":"put","id":"x","da`); err != nil {
+		t.Fatalf("write torn entry: %v", err)
+	}
+	f.Close()
+
+	reopened, store := open(t, dir)
+	defer store.Close()
+
+	got, err := reopened.customers.FindByID(first.ID)
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if got.FirstName != "After" {
+		t.Fatalf("expected replayed update, got %q", got.FirstName)
+	}
+	if _, err := reopened.customers.FindByID("x"); err == nil {
+		t.Fatalf("expected torn entry to be ignored")
+	}
+}
+
+func TestSnapshotTruncatesLog(t *testing.T) {
+	dir := t.TempDir()
+	r, store := open(t, dir)
+	defer store.Close()
+
+	if _, err := r.customers.Save(customers.Customer{FirstName: "Snap"}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	logPath := filepath.Join(dir, "wal.log")
+	if info, err := os.Stat(logPath); err != nil || info.Size() == 0 {
+		t.Fatalf("expected logged write, got %v / %v", info, err)
+	}
+
+	if err := store.Snapshot(); err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if info, err := os.Stat(logPath); err != nil || info.Size() != 0 {
+		t.Fatalf("expected empty log after snapshot, got %v / %v", info, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "wal.log.1")); !os.IsNotExist(err) {
+		t.Fatalf("expected rotated log to be removed, got %v", err)
+	}
+}
+
+func TestWriteAfterCloseFails(t *testing.T) {
+	r, store := open(t, t.TempDir())
+	if err := store.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if _, err := r.customers.Save(customers.Customer{FirstName: "Late"}); err == nil {
+		t.Fatalf("expected write after close to fail")
+	}
+	if got := r.customers.Export(); len(got.([]customers.Customer)) != 0 {
+		t.Fatalf("expected rejected write not to be applied")
+	}
+}
@@ -0,0 +1,160 @@
+package memory
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+)
+
+// CustomerAddressRepository is an in-memory implementation of
+// customers.AddressRepository. It belongs to a CustomerRepository so that
+// Merge and Purge can move and remove addresses; obtain it with
+// CustomerRepository.Addresses and register it with the file backend.
+type CustomerAddressRepository struct {
+	mu        sync.RWMutex
+	addresses map[string]customers.Address
+	journal   Journal
+}
+
+func newCustomerAddressRepository() *CustomerAddressRepository {
+	return &CustomerAddressRepository{addresses: make(map[string]customers.Address)}
+}
+
+func (r *CustomerAddressRepository) FindByID(id string) (customers.Address, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	a, ok := r.addresses[id]
+	if !ok {
+		return customers.Address{}, customers.ErrAddressNotFound
+	}
+	return a, nil
+}
+
+func (r *CustomerAddressRepository) ListByCustomer(customerID string) ([]customers.Address, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var list []customers.Address
+	for _, a := range r.addresses {
+		if a.CustomerID == customerID {
+			list = append(list, a)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+
+	return list, nil
+}
+
+func (r *CustomerAddressRepository) Save(address customers.Address) (customers.Address, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := timestamp()
+	if address.ID == "" {
+		address.ID = newID()
+		address.CreatedAt = now
+	} else {
+		existing, ok := r.addresses[address.ID]
+		if !ok {
+			return customers.Address{}, customers.ErrAddressNotFound
+		}
+		address.CreatedAt = existing.CreatedAt
+	}
+	address.UpdatedAt = now
+	if err := record(r.journal, r.Table(), OpPut, address.ID, address); err != nil {
+		return customers.Address{}, err
+	}
+	r.addresses[address.ID] = address
+	return address, nil
+}
+
+func (r *CustomerAddressRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.addresses[id]; !ok {
+		return customers.ErrAddressNotFound
+	}
+	if err := record(r.journal, r.Table(), OpDelete, id, nil); err != nil {
+		return err
+	}
+	delete(r.addresses, id)
+	return nil
+}
+
+// reparentLocked moves every address of customer from to customer to. The
+// caller holds r.mu.
+func (r *CustomerAddressRepository) reparentLocked(from, to string, now time.Time) error {
+	for id, a := range r.addresses {
+		if a.CustomerID != from {
+			continue
+		}
+		a.CustomerID, a.UpdatedAt = to, now
+		if err := record(r.journal, r.Table(), OpPut, id, a); err != nil {
+			return err
+		}
+		r.addresses[id] = a
+	}
+	return nil
+}
+
+// deleteCustomerLocked removes every address of the customer. The caller
+// holds r.mu.
+func (r *CustomerAddressRepository) deleteCustomerLocked(customerID string) error {
+	for id, a := range r.addresses {
+		if a.CustomerID != customerID {
+			continue
+		}
+		if err := record(r.journal, r.Table(), OpDelete, id, nil); err != nil {
+			return err
+		}
+		delete(r.addresses, id)
+	}
+	return nil
+}
+
+// Table implements Persistent.
+func (r *CustomerAddressRepository) Table() string { return "customer_addresses" }
+
+// SetJournal implements Persistent.
+func (r *CustomerAddressRepository) SetJournal(j Journal) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.journal = j
+}
+
+// Export implements Persistent.
+func (r *CustomerAddressRepository) Export() any {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return exportRows(r.addresses)
+}
+
+// Import implements Persistent.
+func (r *CustomerAddressRepository) Import(raw json.RawMessage) error {
+	rows, err := importRows(raw, func(a customers.Address) string { return a.ID })
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.addresses = rows
+	return nil
+}
+
+// Apply implements Persistent.
+func (r *CustomerAddressRepository) Apply(op Op, id string, raw json.RawMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return applyRow(r.addresses, op, id, raw)
+}
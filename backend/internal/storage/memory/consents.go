@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+)
+
+// CustomerConsentLedger is an in-memory implementation of
+// customers.ConsentRepository. Like CustomerAddressRepository it belongs to a
+// CustomerRepository; obtain it with CustomerRepository.Consents.
+type CustomerConsentLedger struct {
+	mu      sync.RWMutex
+	entries map[string]customers.ConsentEntry
+	last    time.Time
+	journal Journal
+}
+
+func newCustomerConsentLedger() *CustomerConsentLedger {
+	return &CustomerConsentLedger{entries: make(map[string]customers.ConsentEntry)}
+}
+
+// Append adds an entry. RecordedAt strictly increases so entries recorded in
+// the same microsecond keep their order.
+func (l *CustomerConsentLedger) Append(entry customers.ConsentEntry) (customers.ConsentEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := timestamp()
+	if !now.After(l.last) {
+		now = l.last.Add(time.Microsecond)
+	}
+	entry.ID = newID()
+	entry.RecordedAt = now
+	if err := record(l.journal, l.Table(), OpPut, entry.ID, entry); err != nil {
+		return customers.ConsentEntry{}, err
+	}
+	l.entries[entry.ID] = entry
+	l.last = now
+	return entry, nil
+}
+
+func (l *CustomerConsentLedger) ListByCustomer(customerID string) ([]customers.ConsentEntry, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var list []customers.ConsentEntry
+	for _, e := range l.entries {
+		if e.CustomerID == customerID {
+			list = append(list, e)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].RecordedAt.Equal(list[j].RecordedAt) {
+			return list[i].RecordedAt.Before(list[j].RecordedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list, nil
+}
+
+// reparentLocked moves every entry of customer from to customer to. The
+// caller holds l.mu.
+func (l *CustomerConsentLedger) reparentLocked(from, to string) error {
+	for id, e := range l.entries {
+		if e.CustomerID != from {
+			continue
+		}
+		e.CustomerID = to
+		if err := record(l.journal, l.Table(), OpPut, id, e); err != nil {
+			return err
+		}
+		l.entries[id] = e
+	}
+	return nil
+}
+
+// deleteCustomerLocked removes every entry of the customer. The caller holds
+// l.mu.
+func (l *CustomerConsentLedger) deleteCustomerLocked(customerID string) error {
+	for id, e := range l.entries {
+		if e.CustomerID != customerID {
+			continue
+		}
+		if err := record(l.journal, l.Table(), OpDelete, id, nil); err != nil {
+			return err
+		}
+		delete(l.entries, id)
+	}
+	return nil
+}
+
+// Table implements Persistent.
+func (l *CustomerConsentLedger) Table() string { return "customer_consents" }
+
+// SetJournal implements Persistent.
+func (l *CustomerConsentLedger) SetJournal(j Journal) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.journal = j
+}
+
+// Export implements Persistent.
+func (l *CustomerConsentLedger) Export() any {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return exportRows(l.entries)
+}
+
+// Import implements Persistent.
+func (l *CustomerConsentLedger) Import(raw json.RawMessage) error {
+	rows, err := importRows(raw, func(e customers.ConsentEntry) string { return e.ID })
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = rows
+	for _, e := range rows {
+		if e.RecordedAt.After(l.last) {
+			l.last = e.RecordedAt
+		}
+	}
+	return nil
+}
+
+// Apply implements Persistent.
+func (l *CustomerConsentLedger) Apply(op Op, id string, raw json.RawMessage) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := applyRow(l.entries, op, id, raw); err != nil {
+		return err
+	}
+	if e, ok := l.entries[id]; ok && e.RecordedAt.After(l.last) {
+		l.last = e.RecordedAt
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package memory
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+)
+
+// FleetContactRepository is an in-memory implementation of
+// customers.ContactRepository. It belongs to a CustomerRepository so that
+// Merge and Purge can move and remove contacts; obtain it with
+// CustomerRepository.Contacts and register it with the file backend.
+type FleetContactRepository struct {
+	mu       sync.RWMutex
+	contacts map[string]customers.Contact
+	journal  Journal
+}
+
+func newFleetContactRepository() *FleetContactRepository {
+	return &FleetContactRepository{contacts: make(map[string]customers.Contact)}
+}
+
+func (r *FleetContactRepository) FindByID(id string) (customers.Contact, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.contacts[id]
+	if !ok {
+		return customers.Contact{}, customers.ErrContactNotFound
+	}
+	return c, nil
+}
+
+func (r *FleetContactRepository) ListByCustomer(customerID string) ([]customers.Contact, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var list []customers.Contact
+	for _, c := range r.contacts {
+		if c.CustomerID == customerID {
+			list = append(list, c)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+
+	return list, nil
+}
+
+func (r *FleetContactRepository) Save(contact customers.Contact) (customers.Contact, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := timestamp()
+	if contact.ID == "" {
+		contact.ID = newID()
+		contact.CreatedAt = now
+	} else {
+		existing, ok := r.contacts[contact.ID]
+		if !ok {
+			return customers.Contact{}, customers.ErrContactNotFound
+		}
+		contact.CreatedAt = existing.CreatedAt
+	}
+	contact.UpdatedAt = now
+	if err := record(r.journal, r.Table(), OpPut, contact.ID, contact); err != nil {
+		return customers.Contact{}, err
+	}
+	r.contacts[contact.ID] = contact
+	return contact, nil
+}
+
+func (r *FleetContactRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.contacts[id]; !ok {
+		return customers.ErrContactNotFound
+	}
+	if err := record(r.journal, r.Table(), OpDelete, id, nil); err != nil {
+		return err
+	}
+	delete(r.contacts, id)
+	return nil
+}
+
+// reparentLocked moves every contact of customer from to customer to. The
+// caller holds r.mu.
+func (r *FleetContactRepository) reparentLocked(from, to string, now time.Time) error {
+	for id, c := range r.contacts {
+		if c.CustomerID != from {
+			continue
+		}
+		c.CustomerID, c.UpdatedAt = to, now
+		if err := record(r.journal, r.Table(), OpPut, id, c); err != nil {
+			return err
+		}
+		r.contacts[id] = c
+	}
+	return nil
+}
+
+// deleteCustomerLocked removes every contact of the customer. The caller
+// holds r.mu.
+func (r *FleetContactRepository) deleteCustomerLocked(customerID string) error {
+	for id, c := range r.contacts {
+		if c.CustomerID != customerID {
+			continue
+		}
+		if err := record(r.journal, r.Table(), OpDelete, id, nil); err != nil {
+			return err
+		}
+		delete(r.contacts, id)
+	}
+	return nil
+}
+
+// Table implements Persistent.
+func (r *FleetContactRepository) Table() string { return "fleet_contacts" }
+
+// SetJournal implements Persistent.
+func (r *FleetContactRepository) SetJournal(j Journal) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.journal = j
+}
+
+// Export implements Persistent.
+func (r *FleetContactRepository) Export() any {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return exportRows(r.contacts)
+}
+
+// Import implements Persistent.
+func (r *FleetContactRepository) Import(raw json.RawMessage) error {
+	rows, err := importRows(raw, func(c customers.Contact) string { return c.ID })
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.contacts = rows
+	return nil
+}
+
+// Apply implements Persistent.
+func (r *FleetContactRepository) Apply(op Op, id string, raw json.RawMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return applyRow(r.contacts, op, id, raw)
+}
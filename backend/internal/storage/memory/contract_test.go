@@ -0,0 +1,30 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/ezmobilemechanic/platform/internal/storage/memory"
+	"github.com/ezmobilemechanic/platform/internal/storage/storagetest"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storagetest.Backend {
+		customerRepo, vehicleRepo, quoteRepo := memory.NewCustomerRepository(), memory.NewVehicleRepository(), memory.NewQuoteRepository()
+		customerRepo.SetVehicles(vehicleRepo)
+		customerRepo.SetQuotes(quoteRepo)
+		return storagetest.Backend{
+			Customers:    customerRepo,
+			Addresses:    customerRepo.Addresses(),
+			Consents:     customerRepo.Consents(),
+			Notes:        customerRepo.Notes(),
+			Contacts:     customerRepo.Contacts(),
+			Vehicles:     vehicleRepo,
+			Odometer:     vehicleRepo.Odometer(),
+			Recalls:      vehicleRepo.Recalls(),
+			Quotes:       quoteRepo,
+			QuoteHistory: quoteRepo.History(),
+			Users:        memory.NewUserRepository(),
+			Erasures:     memory.NewErasureRequestRepository(),
+		}
+	})
+}
@@ -1,82 +1,417 @@
 package memory
 
 import (
+	"encoding/json"
+	"errors"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
+	"github.com/ezmobilemechanic/platform/internal/phone"
 )
 
 // CustomerRepository is an in-memory implementation of customers.Repository.
 type CustomerRepository struct {
 	mu        sync.RWMutex
 	customers map[string]customers.Customer
+	journal   Journal
+	merges    *CustomerMergeLog
+	addresses *CustomerAddressRepository
+	contacts  *FleetContactRepository
+	consents  *CustomerConsentLedger
+	notes     *CustomerNoteRepository
+	vehicles  *VehicleRepository
+	quotes    *QuoteRepository
+	// phoneRegion is read by List, see SetPhoneRegion.
+	phoneRegion string
 }
 
 // NewCustomerRepository returns an initialized in-memory repository.
 func NewCustomerRepository() *CustomerRepository {
 	return &CustomerRepository{
-		customers: make(map[string]customers.Customer),
+		customers:   make(map[string]customers.Customer),
+		merges:      newCustomerMergeLog(),
+		addresses:   newCustomerAddressRepository(),
+		contacts:    newFleetContactRepository(),
+		consents:    newCustomerConsentLedger(),
+		notes:       newCustomerNoteRepository(),
+		phoneRegion: phone.DefaultRegion,
 	}
 }
 
+// SetPhoneRegion sets the region search reads phone numbers in when they are
+// typed without a country code. It must match the customer service's
+// (customers.WithPhoneRegion). The default is phone.DefaultRegion.
+func (r *CustomerRepository) SetPhoneRegion(region string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.phoneRegion = region
+}
+
+// SetVehicles lets List search match customers by the VINs of their vehicles
+// in v, and Merge re-parent them. Without it, VIN terms match nothing.
+func (r *CustomerRepository) SetVehicles(v *VehicleRepository) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.vehicles = v
+}
+
+// SetQuotes lets Merge re-parent quotes in q. Merge fails until both
+// SetVehicles and SetQuotes have been called.
+func (r *CustomerRepository) SetQuotes(q *QuoteRepository) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.quotes = q
+}
+
+// Merges returns the log of merge records, for registration with the file
+// backend.
+func (r *CustomerRepository) Merges() *CustomerMergeLog {
+	return r.merges
+}
+
+// Addresses returns the repository of customer addresses, which Merge and
+// Purge keep in step with the customers.
+func (r *CustomerRepository) Addresses() *CustomerAddressRepository {
+	return r.addresses
+}
+
+// Contacts returns the repository of fleet contacts, which Merge and Purge
+// keep in step with the customers.
+func (r *CustomerRepository) Contacts() *FleetContactRepository {
+	return r.contacts
+}
+
+// Consents returns the consent ledger, which Merge and Purge keep in step
+// with the customers.
+func (r *CustomerRepository) Consents() *CustomerConsentLedger {
+	return r.consents
+}
+
+// Notes returns the repository of customer notes, which Merge and Purge keep
+// in step with the customers.
+func (r *CustomerRepository) Notes() *CustomerNoteRepository {
+	return r.notes
+}
+
 // FindByID returns a customer by identifier.
 func (r *CustomerRepository) FindByID(id string) (customers.Customer, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	c, ok := r.customers[id]
-	if !ok {
+	if !ok || c.DeletedAt != nil {
 		return customers.Customer{}, customers.ErrNotFound
 	}
 	return c, nil
 }
 
-// Save inserts or updates a customer record.
+// Save inserts or updates a customer record. Updating an unknown or deleted ID
+// returns customers.ErrNotFound and emails are unique case-insensitively among
+// active customers, matching the Postgres schema.
 func (r *CustomerRepository) Save(customer customers.Customer) (customers.Customer, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	now := time.Now().UTC()
+	now := timestamp()
 	if customer.ID == "" {
 		customer.ID = newID()
 		customer.CreatedAt = now
 	} else {
 		existing, ok := r.customers[customer.ID]
-		if ok {
-			if customer.CreatedAt.IsZero() {
-				customer.CreatedAt = existing.CreatedAt
-			}
-		} else {
-			customer.CreatedAt = now
+		if !ok || existing.DeletedAt != nil {
+			return customers.Customer{}, customers.ErrNotFound
 		}
+		customer.CreatedAt = existing.CreatedAt
+	}
+	customer.DeletedAt = nil
+	customer.Tags = append([]string{}, customer.Tags...)
+	if r.emailTaken(customer.Email, customer.ID) {
+		return customers.Customer{}, customers.ErrEmailExists
 	}
 	customer.UpdatedAt = now
+	if err := record(r.journal, r.Table(), OpPut, customer.ID, customer); err != nil {
+		return customers.Customer{}, err
+	}
 	r.customers[customer.ID] = customer
 	return customer, nil
 }
 
-// List returns customers with simple offset/limit pagination.
-func (r *CustomerRepository) List(offset, limit int) ([]customers.Customer, error) {
+func (r *CustomerRepository) emailTaken(email, exceptID string) bool {
+	if email == "" {
+		return false
+	}
+	for id, c := range r.customers {
+		if id != exceptID && c.DeletedAt == nil && strings.EqualFold(c.Email, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// List returns a page of customers matching the spec.
+func (r *CustomerRepository) List(spec listing.Spec) (listing.Page[customers.Customer], error) {
+	spec = spec.Normalize()
+
+	// Resolve VIN matches before taking our lock so the two repositories'
+	// locks are never held together.
+	r.mu.RLock()
+	vehicleRepo := r.vehicles
+	terms := customers.ParseSearch(spec.Search, r.phoneRegion)
+	r.mu.RUnlock()
+	var byVIN map[string]bool
+	if terms.VIN != "" && vehicleRepo != nil {
+		byVIN = vehicleRepo.customersMatching(terms)
+	}
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	list := make([]customers.Customer, 0, len(r.customers))
 	for _, c := range r.customers {
+		if c.DeletedAt != nil || !spec.MatchesCreated(c.CreatedAt) {
+			continue
+		}
+		if !terms.IsZero() && !terms.Matches(c) && !byVIN[c.ID] {
+			continue
+		}
 		list = append(list, c)
 	}
 
-	sort.Slice(list, func(i, j int) bool {
-		return list[i].CreatedAt.Before(list[j].CreatedAt)
-	})
+	return listing.Paginate(list, spec, customers.SortKey)
+}
+
+// Delete soft-deletes an active customer.
+func (r *CustomerRepository) Delete(id string) (customers.Customer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.customers[id]
+	if !ok || c.DeletedAt != nil {
+		return customers.Customer{}, customers.ErrNotFound
+	}
+	now := timestamp()
+	c.DeletedAt = &now
+	c.UpdatedAt = now
+	if err := record(r.journal, r.Table(), OpPut, c.ID, c); err != nil {
+		return customers.Customer{}, err
+	}
+	r.customers[id] = c
+	return c, nil
+}
+
+// Restore reactivates a soft-deleted customer. It fails with
+// customers.ErrEmailExists if an active customer has since taken the email.
+func (r *CustomerRepository) Restore(id string) (customers.Customer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.customers[id]
+	if !ok || c.DeletedAt == nil {
+		return customers.Customer{}, customers.ErrNotFound
+	}
+	if r.emailTaken(c.Email, c.ID) {
+		return customers.Customer{}, customers.ErrEmailExists
+	}
+	c.DeletedAt = nil
+	c.UpdatedAt = timestamp()
+	if err := record(r.journal, r.Table(), OpPut, c.ID, c); err != nil {
+		return customers.Customer{}, err
+	}
+	r.customers[id] = c
+	return c, nil
+}
+
+// Purge removes customers soft-deleted before the cutoff along with their
+// addresses, contacts, consent entries and notes.
+func (r *CustomerRepository) Purge(deletedBefore time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.addresses.mu.Lock()
+	defer r.addresses.mu.Unlock()
+	r.contacts.mu.Lock()
+	defer r.contacts.mu.Unlock()
+	r.consents.mu.Lock()
+	defer r.consents.mu.Unlock()
+	r.notes.mu.Lock()
+	defer r.notes.mu.Unlock()
+
+	purged := 0
+	for id, c := range r.customers {
+		if c.DeletedAt == nil || !c.DeletedAt.Before(deletedBefore) {
+			continue
+		}
+		if err := r.addresses.deleteCustomerLocked(id); err != nil {
+			return purged, err
+		}
+		if err := r.contacts.deleteCustomerLocked(id); err != nil {
+			return purged, err
+		}
+		if err := r.consents.deleteCustomerLocked(id); err != nil {
+			return purged, err
+		}
+		if err := r.notes.deleteCustomerLocked(id); err != nil {
+			return purged, err
+		}
+		if err := record(r.journal, r.Table(), OpDelete, id, nil); err != nil {
+			return purged, err
+		}
+		delete(r.customers, id)
+		purged++
+	}
+	return purged, nil
+}
+
+// FindMatches returns active customers sharing any of the keys.
+func (r *CustomerRepository) FindMatches(keys customers.MatchKeys) ([]customers.Customer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []customers.Customer
+	for _, c := range r.customers {
+		if c.ID == keys.ExcludeID || c.DeletedAt != nil {
+			continue
+		}
+		if (keys.Email != "" && strings.EqualFold(c.Email, keys.Email)) ||
+			(keys.PhoneE164 != "" && c.PhoneE164 == keys.PhoneE164) ||
+			(keys.LastName != "" && strings.EqualFold(c.LastName, keys.LastName)) {
+			out = append(out, c)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// Merge folds mergedID into survivor. Locks are taken customers, then
+// vehicles, then quotes, then addresses, then contacts, then consents, then
+// notes; Purge is the only other code path holding two of them and keeps the
+// same order. The whole merge is applied without readers seeing it half done.
+func (r *CustomerRepository) Merge(survivor customers.Customer, mergedID string) (customers.Merge, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.vehicles == nil || r.quotes == nil {
+		return customers.Merge{}, errors.New("memory: customer repository is not linked to vehicles and quotes")
+	}
+
+	existing, ok := r.customers[survivor.ID]
+	if !ok || existing.DeletedAt != nil || survivor.ID == mergedID {
+		return customers.Merge{}, customers.ErrNotFound
+	}
+	if c, ok := r.customers[mergedID]; !ok || c.DeletedAt != nil {
+		return customers.Merge{}, customers.ErrNotFound
+	}
+	if survivor.Email != "" {
+		for id, c := range r.customers {
+			if id != survivor.ID && id != mergedID && c.DeletedAt == nil && strings.EqualFold(c.Email, survivor.Email) {
+				return customers.Merge{}, customers.ErrEmailExists
+			}
+		}
+	}
+
+	r.vehicles.mu.Lock()
+	defer r.vehicles.mu.Unlock()
+	r.quotes.mu.Lock()
+	defer r.quotes.mu.Unlock()
+	r.addresses.mu.Lock()
+	defer r.addresses.mu.Unlock()
+	r.contacts.mu.Lock()
+	defer r.contacts.mu.Unlock()
+	r.consents.mu.Lock()
+	defer r.consents.mu.Unlock()
+	r.notes.mu.Lock()
+	defer r.notes.mu.Unlock()
+	r.vehicles.recalls.mu.Lock()
+	defer r.vehicles.recalls.mu.Unlock()
+
+	now := timestamp()
+	m := customers.Merge{MergedID: mergedID, SurvivorID: survivor.ID, MergedAt: now}
+	var err error
+	if m.VehicleIDs, err = r.vehicles.reparentLocked(mergedID, survivor.ID, now); err != nil {
+		return customers.Merge{}, err
+	}
+	if m.QuoteIDs, err = r.quotes.reparentLocked(mergedID, survivor.ID, now); err != nil {
+		return customers.Merge{}, err
+	}
+	if err := r.addresses.reparentLocked(mergedID, survivor.ID, now); err != nil {
+		return customers.Merge{}, err
+	}
+	if err := r.contacts.reparentLocked(mergedID, survivor.ID, now); err != nil {
+		return customers.Merge{}, err
+	}
+	if err := r.consents.reparentLocked(mergedID, survivor.ID); err != nil {
+		return customers.Merge{}, err
+	}
+	if err := r.notes.reparentLocked(mergedID, survivor.ID); err != nil {
+		return customers.Merge{}, err
+	}
+	if err := r.vehicles.recalls.reparentLocked(mergedID, survivor.ID); err != nil {
+		return customers.Merge{}, err
+	}
+
+	if err := record(r.journal, r.Table(), OpDelete, mergedID, nil); err != nil {
+		return customers.Merge{}, err
+	}
+	delete(r.customers, mergedID)
+
+	survivor.CreatedAt = existing.CreatedAt
+	survivor.UpdatedAt = now
+	survivor.DeletedAt = nil
+	survivor.Tags = append([]string{}, survivor.Tags...)
+	if err := record(r.journal, r.Table(), OpPut, survivor.ID, survivor); err != nil {
+		return customers.Merge{}, err
+	}
+	r.customers[survivor.ID] = survivor
 
-	if offset > len(list) {
-		return []customers.Customer{}, nil
+	if err := r.merges.add(m); err != nil {
+		return customers.Merge{}, err
 	}
-	end := len(list)
-	if limit > 0 && offset+limit < end {
-		end = offset + limit
+	return m, nil
+}
+
+// MergedInto resolves a merged customer ID to its survivor.
+func (r *CustomerRepository) MergedInto(id string) (string, error) {
+	survivor, ok := r.merges.resolve(id)
+	if !ok {
+		return "", customers.ErrNotFound
 	}
-	return list[offset:end], nil
+	return survivor, nil
+}
+
+// Table implements Persistent.
+func (r *CustomerRepository) Table() string { return "customers" }
+
+// SetJournal implements Persistent.
+func (r *CustomerRepository) SetJournal(j Journal) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.journal = j
+}
+
+// Export implements Persistent.
+func (r *CustomerRepository) Export() any {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return exportRows(r.customers)
+}
+
+// Import implements Persistent.
+func (r *CustomerRepository) Import(raw json.RawMessage) error {
+	rows, err := importRows(raw, func(c customers.Customer) string { return c.ID })
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.customers = rows
+	return nil
+}
+
+// Apply implements Persistent.
+func (r *CustomerRepository) Apply(op Op, id string, raw json.RawMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return applyRow(r.customers, op, id, raw)
 }
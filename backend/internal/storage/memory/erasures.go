@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"encoding/json"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/ezmobilemechanic/platform/internal/privacy"
+)
+
+// ErasureRequestRepository is an in-memory implementation of
+// privacy.Repository. Requests are kept when their customer is purged.
+type ErasureRequestRepository struct {
+	mu       sync.RWMutex
+	requests map[string]privacy.ErasureRequest
+	journal  Journal
+}
+
+// NewErasureRequestRepository creates an empty repository.
+func NewErasureRequestRepository() *ErasureRequestRepository {
+	return &ErasureRequestRepository{requests: make(map[string]privacy.ErasureRequest)}
+}
+
+func (r *ErasureRequestRepository) FindByID(id string) (privacy.ErasureRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	req, ok := r.requests[id]
+	if !ok {
+		return privacy.ErasureRequest{}, privacy.ErrNotFound
+	}
+	return cloneErasureRequest(req), nil
+}
+
+func (r *ErasureRequestRepository) Save(req privacy.ErasureRequest) (privacy.ErasureRequest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if req.ID == "" {
+		req.ID = newID()
+	} else if _, ok := r.requests[req.ID]; !ok {
+		return privacy.ErasureRequest{}, privacy.ErrNotFound
+	}
+	req = cloneErasureRequest(req)
+	if err := record(r.journal, r.Table(), OpPut, req.ID, req); err != nil {
+		return privacy.ErasureRequest{}, err
+	}
+	r.requests[req.ID] = req
+	return cloneErasureRequest(req), nil
+}
+
+func (r *ErasureRequestRepository) List(status privacy.Status) ([]privacy.ErasureRequest, error) {
+	return r.filter(func(req privacy.ErasureRequest) bool { return status == "" || req.Status == status }), nil
+}
+
+func (r *ErasureRequestRepository) ListByCustomer(customerID string) ([]privacy.ErasureRequest, error) {
+	return r.filter(func(req privacy.ErasureRequest) bool { return req.CustomerID == customerID }), nil
+}
+
+func (r *ErasureRequestRepository) filter(keep func(privacy.ErasureRequest) bool) []privacy.ErasureRequest {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var list []privacy.ErasureRequest
+	for _, req := range r.requests {
+		if keep(req) {
+			list = append(list, cloneErasureRequest(req))
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].RequestedAt.Equal(list[j].RequestedAt) {
+			return list[i].RequestedAt.Before(list[j].RequestedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// cloneErasureRequest copies the parts of a request callers could mutate.
+func cloneErasureRequest(req privacy.ErasureRequest) privacy.ErasureRequest {
+	req.Events = slices.Clone(req.Events)
+	if req.ClosedAt != nil {
+		t := *req.ClosedAt
+		req.ClosedAt = &t
+	}
+	return req
+}
+
+// Table implements Persistent.
+func (r *ErasureRequestRepository) Table() string { return "erasure_requests" }
+
+// SetJournal implements Persistent.
+func (r *ErasureRequestRepository) SetJournal(j Journal) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.journal = j
+}
+
+// Export implements Persistent.
+func (r *ErasureRequestRepository) Export() any {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return exportRows(r.requests)
+}
+
+// Import implements Persistent.
+func (r *ErasureRequestRepository) Import(raw json.RawMessage) error {
+	rows, err := importRows(raw, func(req privacy.ErasureRequest) string { return req.ID })
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = rows
+	return nil
+}
+
+// Apply implements Persistent.
+func (r *ErasureRequestRepository) Apply(op Op, id string, raw json.RawMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return applyRow(r.requests, op, id, raw)
+}
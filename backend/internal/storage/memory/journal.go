@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Op names a mutation recorded in a Journal.
+type Op string
+
+const (
+	OpPut    Op = "put"
+	OpDelete Op = "delete"
+)
+
+// Journal receives every mutation before a repository applies it. When Record
+// returns an error the mutation is abandoned, so a durable journal (the file
+// backend's write-ahead log) never falls behind the in-memory state.
+type Journal interface {
+	Record(table string, op Op, id string, value any) error
+}
+
+// Persistent is implemented by repositories whose contents can be
+// snapshotted and restored by the file backend.
+type Persistent interface {
+	// Table is the stable name used in snapshots and journal records.
+	Table() string
+	// SetJournal installs the journal that receives future mutations.
+	SetJournal(j Journal)
+	// Export returns every record, ordered by ID, for a snapshot.
+	Export() any
+	// Import replaces the repository contents with a snapshot section.
+	Import(raw json.RawMessage) error
+	// Apply replays a single journal record.
+	Apply(op Op, id string, raw json.RawMessage) error
+}
+
+// record forwards a mutation to j when one is installed.
+func record(j Journal, table string, op Op, id string, value any) error {
+	if j == nil {
+		return nil
+	}
+	if err := j.Record(table, op, id, value); err != nil {
+		return fmt.Errorf("journal %s %s: %w", table, op, err)
+	}
+	return nil
+}
+
+func exportRows[T any](rows map[string]T) []T {
+	ids := make([]string, 0, len(rows))
+	for id := range rows {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	out := make([]T, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, rows[id])
+	}
+	return out
+}
+
+func importRows[T any](raw json.RawMessage, id func(T) string) (map[string]T, error) {
+	var list []T
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &list); err != nil {
+			return nil, err
+		}
+	}
+	rows := make(map[string]T, len(list))
+	for _, row := range list {
+		rows[id(row)] = row
+	}
+	return rows, nil
+}
+
+func applyRow[T any](rows map[string]T, op Op, id string, raw json.RawMessage) error {
+	switch op {
+	case OpPut:
+		var row T
+		if err := json.Unmarshal(raw, &row); err != nil {
+			return err
+		}
+		rows[id] = row
+	case OpDelete:
+		delete(rows, id)
+	default:
+		return fmt.Errorf("unknown journal op %q", op)
+	}
+	return nil
+}
+
+// Ensure repositories can be persisted by the file backend.
+var (
+	_ Persistent = (*CustomerRepository)(nil)
+	_ Persistent = (*CustomerMergeLog)(nil)
+	_ Persistent = (*CustomerAddressRepository)(nil)
+	_ Persistent = (*FleetContactRepository)(nil)
+	_ Persistent = (*CustomerConsentLedger)(nil)
+	_ Persistent = (*CustomerNoteRepository)(nil)
+	_ Persistent = (*VehicleRepository)(nil)
+	_ Persistent = (*VehicleTransferLog)(nil)
+	_ Persistent = (*OdometerRepository)(nil)
+	_ Persistent = (*RecallMarkRepository)(nil)
+	_ Persistent = (*QuoteRepository)(nil)
+	_ Persistent = (*QuoteStatusLog)(nil)
+	_ Persistent = (*ErasureRequestRepository)(nil)
+	_ Persistent = (*UserRepository)(nil)
+)
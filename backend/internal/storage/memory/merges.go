@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+)
+
+// maxMergeHops bounds how far MergedInto follows a chain of merges.
+const maxMergeHops = 32
+
+// CustomerMergeLog stores customer merge records for a CustomerRepository.
+// It is a separate table so the file backend can persist it; register it
+// alongside the repository returned by CustomerRepository.Merges.
+type CustomerMergeLog struct {
+	mu      sync.RWMutex
+	merges  map[string]customers.Merge
+	journal Journal
+}
+
+func newCustomerMergeLog() *CustomerMergeLog {
+	return &CustomerMergeLog{merges: make(map[string]customers.Merge)}
+}
+
+func (l *CustomerMergeLog) add(m customers.Merge) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := record(l.journal, l.Table(), OpPut, m.MergedID, m); err != nil {
+		return err
+	}
+	l.merges[m.MergedID] = m
+	return nil
+}
+
+// resolve follows merges starting at id and returns the last survivor.
+func (l *CustomerMergeLog) resolve(id string) (string, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	m, ok := l.merges[id]
+	if !ok {
+		return "", false
+	}
+	survivor := m.SurvivorID
+	for i := 0; i < maxMergeHops; i++ {
+		next, ok := l.merges[survivor]
+		if !ok {
+			break
+		}
+		survivor = next.SurvivorID
+	}
+	return survivor, true
+}
+
+// Table implements Persistent.
+func (l *CustomerMergeLog) Table() string { return "customer_merges" }
+
+// SetJournal implements Persistent.
+func (l *CustomerMergeLog) SetJournal(j Journal) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.journal = j
+}
+
+// Export implements Persistent.
+func (l *CustomerMergeLog) Export() any {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return exportRows(l.merges)
+}
+
+// Import implements Persistent.
+func (l *CustomerMergeLog) Import(raw json.RawMessage) error {
+	rows, err := importRows(raw, func(m customers.Merge) string { return m.MergedID })
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.merges = rows
+	return nil
+}
+
+// Apply implements Persistent.
+func (l *CustomerMergeLog) Apply(op Op, id string, raw json.RawMessage) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return applyRow(l.merges, op, id, raw)
+}
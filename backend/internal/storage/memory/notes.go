@@ -0,0 +1,146 @@
+package memory
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+)
+
+// CustomerNoteRepository is an in-memory implementation of
+// customers.NoteRepository. Like CustomerAddressRepository it belongs to a
+// CustomerRepository; obtain it with CustomerRepository.Notes.
+type CustomerNoteRepository struct {
+	mu      sync.RWMutex
+	notes   map[string]customers.Note
+	journal Journal
+}
+
+func newCustomerNoteRepository() *CustomerNoteRepository {
+	return &CustomerNoteRepository{notes: make(map[string]customers.Note)}
+}
+
+func (r *CustomerNoteRepository) FindByID(id string) (customers.Note, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	n, ok := r.notes[id]
+	if !ok {
+		return customers.Note{}, customers.ErrNoteNotFound
+	}
+	return n, nil
+}
+
+func (r *CustomerNoteRepository) ListByCustomer(customerID string) ([]customers.Note, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var list []customers.Note
+	for _, n := range r.notes {
+		if n.CustomerID == customerID {
+			list = append(list, n)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list, nil
+}
+
+func (r *CustomerNoteRepository) Add(note customers.Note) (customers.Note, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	note.ID = newID()
+	note.CreatedAt = timestamp()
+	if err := record(r.journal, r.Table(), OpPut, note.ID, note); err != nil {
+		return customers.Note{}, err
+	}
+	r.notes[note.ID] = note
+	return note, nil
+}
+
+func (r *CustomerNoteRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.notes[id]; !ok {
+		return customers.ErrNoteNotFound
+	}
+	if err := record(r.journal, r.Table(), OpDelete, id, nil); err != nil {
+		return err
+	}
+	delete(r.notes, id)
+	return nil
+}
+
+// reparentLocked moves every note of customer from to customer to. The
+// caller holds r.mu.
+func (r *CustomerNoteRepository) reparentLocked(from, to string) error {
+	for id, n := range r.notes {
+		if n.CustomerID != from {
+			continue
+		}
+		n.CustomerID = to
+		if err := record(r.journal, r.Table(), OpPut, id, n); err != nil {
+			return err
+		}
+		r.notes[id] = n
+	}
+	return nil
+}
+
+// deleteCustomerLocked removes every note of the customer. The caller holds
+// r.mu.
+func (r *CustomerNoteRepository) deleteCustomerLocked(customerID string) error {
+	for id, n := range r.notes {
+		if n.CustomerID != customerID {
+			continue
+		}
+		if err := record(r.journal, r.Table(), OpDelete, id, nil); err != nil {
+			return err
+		}
+		delete(r.notes, id)
+	}
+	return nil
+}
+
+// Table implements Persistent.
+func (r *CustomerNoteRepository) Table() string { return "customer_notes" }
+
+// SetJournal implements Persistent.
+func (r *CustomerNoteRepository) SetJournal(j Journal) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.journal = j
+}
+
+// Export implements Persistent.
+func (r *CustomerNoteRepository) Export() any {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return exportRows(r.notes)
+}
+
+// Import implements Persistent.
+func (r *CustomerNoteRepository) Import(raw json.RawMessage) error {
+	rows, err := importRows(raw, func(n customers.Note) string { return n.ID })
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notes = rows
+	return nil
+}
+
+// Apply implements Persistent.
+func (r *CustomerNoteRepository) Apply(op Op, id string, raw json.RawMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return applyRow(r.notes, op, id, raw)
+}
@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+)
+
+// OdometerRepository is an in-memory implementation of
+// vehicles.OdometerRepository. It belongs to a VehicleRepository; obtain it
+// with VehicleRepository.Odometer.
+type OdometerRepository struct {
+	mu       sync.RWMutex
+	readings map[string]vehicles.Reading
+	journal  Journal
+}
+
+func newOdometerRepository() *OdometerRepository {
+	return &OdometerRepository{readings: make(map[string]vehicles.Reading)}
+}
+
+func (r *OdometerRepository) ListByVehicle(vehicleID string) ([]vehicles.Reading, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := []vehicles.Reading{}
+	for _, reading := range r.readings {
+		if reading.VehicleID == vehicleID {
+			list = append(list, reading)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].ReadAt.Equal(list[j].ReadAt) {
+			return list[i].ReadAt.Before(list[j].ReadAt)
+		}
+		if !list[i].RecordedAt.Equal(list[j].RecordedAt) {
+			return list[i].RecordedAt.Before(list[j].RecordedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list, nil
+}
+
+func (r *OdometerRepository) Add(reading vehicles.Reading) (vehicles.Reading, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reading.ID = newID()
+	reading.RecordedAt = timestamp()
+	if err := record(r.journal, r.Table(), OpPut, reading.ID, reading); err != nil {
+		return vehicles.Reading{}, err
+	}
+	r.readings[reading.ID] = reading
+	return reading, nil
+}
+
+// remove undoes Add for a reading taken with a transfer that could not be
+// completed. As for VehicleTransferLog.remove, a failure to journal the
+// removal is not reported.
+func (r *OdometerRepository) remove(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	record(r.journal, r.Table(), OpDelete, id, nil)
+	delete(r.readings, id)
+}
+
+func (r *OdometerRepository) SetFlagged(id string, flagged bool) (vehicles.Reading, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reading, ok := r.readings[id]
+	if !ok {
+		return vehicles.Reading{}, vehicles.ErrReadingNotFound
+	}
+	reading.Flagged = flagged
+	if err := record(r.journal, r.Table(), OpPut, id, reading); err != nil {
+		return vehicles.Reading{}, err
+	}
+	r.readings[id] = reading
+	return reading, nil
+}
+
+// Table implements Persistent.
+func (r *OdometerRepository) Table() string { return "odometer_readings" }
+
+// SetJournal implements Persistent.
+func (r *OdometerRepository) SetJournal(j Journal) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.journal = j
+}
+
+// Export implements Persistent.
+func (r *OdometerRepository) Export() any {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return exportRows(r.readings)
+}
+
+// Import implements Persistent.
+func (r *OdometerRepository) Import(raw json.RawMessage) error {
+	rows, err := importRows(raw, func(reading vehicles.Reading) string { return reading.ID })
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.readings = rows
+	return nil
+}
+
+// Apply implements Persistent.
+func (r *OdometerRepository) Apply(op Op, id string, raw json.RawMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return applyRow(r.readings, op, id, raw)
+}
@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/quotes"
+)
+
+// QuoteStatusLog is an in-memory implementation of quotes.HistoryRepository,
+// owned by a QuoteRepository. Quotes are never removed from the memory
+// backends, so neither are their status changes.
+type QuoteStatusLog struct {
+	mu      sync.RWMutex
+	changes map[string]quotes.StatusChange
+	journal Journal
+}
+
+func newQuoteStatusLog() *QuoteStatusLog {
+	return &QuoteStatusLog{changes: make(map[string]quotes.StatusChange)}
+}
+
+func (l *QuoteStatusLog) Append(change quotes.StatusChange) (quotes.StatusChange, error) {
+	change.ID = newID()
+	change.ChangedAt = timestamp()
+	if err := l.add(change); err != nil {
+		return quotes.StatusChange{}, err
+	}
+	return change, nil
+}
+
+func (l *QuoteStatusLog) add(change quotes.StatusChange) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := record(l.journal, l.Table(), OpPut, change.ID, change); err != nil {
+		return err
+	}
+	l.changes[change.ID] = change
+	return nil
+}
+
+func (l *QuoteStatusLog) ListByQuote(quoteID string) ([]quotes.StatusChange, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var list []quotes.StatusChange
+	for _, c := range l.changes {
+		if c.QuoteID == quoteID {
+			list = append(list, c)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].ChangedAt.Equal(list[j].ChangedAt) {
+			return list[i].ChangedAt.Before(list[j].ChangedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list, nil
+}
+
+// Table implements Persistent.
+func (l *QuoteStatusLog) Table() string { return "quote_status_changes" }
+
+// SetJournal implements Persistent.
+func (l *QuoteStatusLog) SetJournal(j Journal) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.journal = j
+}
+
+// Export implements Persistent.
+func (l *QuoteStatusLog) Export() any {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return exportRows(l.changes)
+}
+
+// Import implements Persistent.
+func (l *QuoteStatusLog) Import(raw json.RawMessage) error {
+	rows, err := importRows(raw, func(c quotes.StatusChange) string { return c.ID })
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.changes = rows
+	return nil
+}
+
+// Apply implements Persistent.
+func (l *QuoteStatusLog) Apply(op Op, id string, raw json.RawMessage) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return applyRow(l.changes, op, id, raw)
+}
@@ -1,26 +1,37 @@
 package memory
 
 import (
+	"encoding/json"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
 	"github.com/ezmobilemechanic/platform/internal/domain/quotes"
 )
 
 // QuoteRepository is an in-memory implementation of quotes.Repository.
 type QuoteRepository struct {
-	mu     sync.RWMutex
-	quotes map[string]quotes.Quote
+	mu      sync.RWMutex
+	quotes  map[string]quotes.Quote
+	journal Journal
+	history *QuoteStatusLog
 }
 
 // NewQuoteRepository creates an in-memory quote repo.
 func NewQuoteRepository() *QuoteRepository {
 	return &QuoteRepository{
-		quotes: make(map[string]quotes.Quote),
+		quotes:  make(map[string]quotes.Quote),
+		history: newQuoteStatusLog(),
 	}
 }
 
+// History returns the log of status changes, which UpdateStatus appends to.
+func (r *QuoteRepository) History() *QuoteStatusLog {
+	return r.history
+}
+
 func (r *QuoteRepository) FindByID(id string) (quotes.Quote, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -36,19 +47,22 @@ func (r *QuoteRepository) Save(quote quotes.Quote) (quotes.Quote, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	now := time.Now().UTC()
+	now := timestamp()
 	if quote.ID == "" {
 		quote.ID = newID()
 		quote.CreatedAt = now
 	} else {
 		existing, ok := r.quotes[quote.ID]
-		if ok && quote.CreatedAt.IsZero() {
-			quote.CreatedAt = existing.CreatedAt
+		if !ok {
+			return quotes.Quote{}, quotes.ErrNotFound
 		}
+		quote.CreatedAt = existing.CreatedAt
 	}
 	quote.UpdatedAt = now
 
-	// ensure line item IDs and quote ID assignment
+	// ensure line item IDs and quote ID assignment; copy so the stored quote
+	// does not alias the caller's slice
+	quote.LineItems = append([]quotes.LineItem(nil), quote.LineItems...)
 	for idx := range quote.LineItems {
 		if quote.LineItems[idx].ID == "" {
 			quote.LineItems[idx].ID = newID()
@@ -57,31 +71,155 @@ func (r *QuoteRepository) Save(quote quotes.Quote) (quotes.Quote, error) {
 		quote.LineItems[idx].SortOrder = idx
 	}
 
+	if err := record(r.journal, r.Table(), OpPut, quote.ID, quote); err != nil {
+		return quotes.Quote{}, err
+	}
 	r.quotes[quote.ID] = quote
 	return quote, nil
 }
 
-func (r *QuoteRepository) ListByCustomer(customerID string, offset, limit int) ([]quotes.Quote, error) {
+func (r *QuoteRepository) UpdateStatus(quote quotes.Quote, change quotes.StatusChange) (quotes.Quote, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.quotes[quote.ID]
+	if !ok {
+		return quotes.Quote{}, quotes.ErrNotFound
+	}
+	if existing.Status != change.From {
+		return quotes.Quote{}, quotes.ErrStatusChanged
+	}
+	now := timestamp()
+	quote.Status = change.To
+	quote.LineItems = existing.LineItems
+	quote.CreatedAt, quote.UpdatedAt = existing.CreatedAt, now
+	if err := record(r.journal, r.Table(), OpPut, quote.ID, quote); err != nil {
+		return quotes.Quote{}, err
+	}
+	if change.From != change.To {
+		change.ID = newID()
+		change.QuoteID = quote.ID
+		change.ChangedAt = now
+		if err := r.history.add(change); err != nil {
+			// Journal the old quote again so a replay does not apply a
+			// status change missing from the history.
+			record(r.journal, r.Table(), OpPut, existing.ID, existing)
+			return quotes.Quote{}, err
+		}
+	}
+	r.quotes[quote.ID] = quote
+	return quote, nil
+}
+
+// reparentLocked moves every quote of customer from to customer to and returns
+// their IDs. The caller holds r.mu.
+func (r *QuoteRepository) reparentLocked(from, to string, now time.Time) ([]string, error) {
+	ids := []string{}
+	for id, q := range r.quotes {
+		if q.CustomerID == from {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		q := r.quotes[id]
+		q.CustomerID, q.UpdatedAt = to, now
+		if err := record(r.journal, r.Table(), OpPut, id, q); err != nil {
+			return nil, err
+		}
+		r.quotes[id] = q
+	}
+	return ids, nil
+}
+
+func (r *QuoteRepository) ListByCustomer(customerID string, spec listing.Spec) (listing.Page[quotes.Quote], error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	spec = spec.Normalize()
+	needle := strings.ToLower(spec.Search)
+
 	var list []quotes.Quote
 	for _, q := range r.quotes {
-		if q.CustomerID == customerID {
-			list = append(list, q)
+		if q.CustomerID != customerID {
+			continue
+		}
+		if spec.Status != "" && string(q.Status) != spec.Status {
+			continue
 		}
+		if !spec.MatchesCreated(q.CreatedAt) {
+			continue
+		}
+		if needle != "" && !quoteMatches(q, needle) {
+			continue
+		}
+		list = append(list, q)
 	}
 
+	return listing.Paginate(list, spec, quotes.SortKey)
+}
+
+// ListByVehicle returns the vehicle's quotes oldest first.
+func (r *QuoteRepository) ListByVehicle(vehicleID string) ([]quotes.Quote, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := []quotes.Quote{}
+	for _, q := range r.quotes {
+		if q.VehicleID == vehicleID {
+			list = append(list, q)
+		}
+	}
 	sort.Slice(list, func(i, j int) bool {
-		return list[i].CreatedAt.Before(list[j].CreatedAt)
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		}
+		return list[i].ID < list[j].ID
 	})
+	return list, nil
+}
 
-	if offset > len(list) {
-		return []quotes.Quote{}, nil
+func quoteMatches(q quotes.Quote, needle string) bool {
+	for _, item := range q.LineItems {
+		if strings.Contains(strings.ToLower(item.Description), needle) {
+			return true
+		}
 	}
-	end := len(list)
-	if limit > 0 && offset+limit < end {
-		end = offset + limit
+	return false
+}
+
+// Table implements Persistent.
+func (r *QuoteRepository) Table() string { return "quotes" }
+
+// SetJournal implements Persistent.
+func (r *QuoteRepository) SetJournal(j Journal) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.journal = j
+}
+
+// Export implements Persistent.
+func (r *QuoteRepository) Export() any {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return exportRows(r.quotes)
+}
+
+// Import implements Persistent.
+func (r *QuoteRepository) Import(raw json.RawMessage) error {
+	rows, err := importRows(raw, func(q quotes.Quote) string { return q.ID })
+	if err != nil {
+		return err
 	}
-	return list[offset:end], nil
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.quotes = rows
+	return nil
+}
+
+// Apply implements Persistent.
+func (r *QuoteRepository) Apply(op Op, id string, raw json.RawMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return applyRow(r.quotes, op, id, raw)
 }
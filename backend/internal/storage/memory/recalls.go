@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+)
+
+// RecallMarkRepository is an in-memory implementation of
+// vehicles.RecallRepository. It belongs to a VehicleRepository; obtain it
+// with VehicleRepository.Recalls.
+type RecallMarkRepository struct {
+	mu      sync.RWMutex
+	marks   map[string]vehicles.RecallMark
+	journal Journal
+}
+
+func newRecallMarkRepository() *RecallMarkRepository {
+	return &RecallMarkRepository{marks: make(map[string]vehicles.RecallMark)}
+}
+
+// recallMarkKey identifies a mark by vehicle and bulletin.
+func recallMarkKey(vehicleID, bulletinID string) string {
+	return vehicleID + "/" + bulletinID
+}
+
+func (r *RecallMarkRepository) ListByVehicle(vehicleID string) ([]vehicles.RecallMark, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := []vehicles.RecallMark{}
+	for _, m := range r.marks {
+		if m.VehicleID == vehicleID {
+			list = append(list, m)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].MarkedAt.Equal(list[j].MarkedAt) {
+			return list[i].MarkedAt.Before(list[j].MarkedAt)
+		}
+		return list[i].BulletinID < list[j].BulletinID
+	})
+	return list, nil
+}
+
+func (r *RecallMarkRepository) Save(mark vehicles.RecallMark) (vehicles.RecallMark, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mark.MarkedAt = timestamp()
+	key := recallMarkKey(mark.VehicleID, mark.BulletinID)
+	if err := record(r.journal, r.Table(), OpPut, key, mark); err != nil {
+		return vehicles.RecallMark{}, err
+	}
+	r.marks[key] = mark
+	return mark, nil
+}
+
+func (r *RecallMarkRepository) Delete(vehicleID, bulletinID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := recallMarkKey(vehicleID, bulletinID)
+	if _, ok := r.marks[key]; !ok {
+		return vehicles.ErrRecallMarkNotFound
+	}
+	if err := record(r.journal, r.Table(), OpDelete, key, nil); err != nil {
+		return err
+	}
+	delete(r.marks, key)
+	return nil
+}
+
+// reparentLocked moves every mark made for customer from to customer to, so
+// that merging customers keeps them informed. The caller holds r.mu.
+func (r *RecallMarkRepository) reparentLocked(from, to string) error {
+	for key, m := range r.marks {
+		if m.CustomerID != from {
+			continue
+		}
+		m.CustomerID = to
+		if err := record(r.journal, r.Table(), OpPut, key, m); err != nil {
+			return err
+		}
+		r.marks[key] = m
+	}
+	return nil
+}
+
+// Table implements Persistent.
+func (r *RecallMarkRepository) Table() string { return "vehicle_recall_marks" }
+
+// SetJournal implements Persistent.
+func (r *RecallMarkRepository) SetJournal(j Journal) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.journal = j
+}
+
+// Export implements Persistent.
+func (r *RecallMarkRepository) Export() any {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return exportRows(r.marks)
+}
+
+// Import implements Persistent.
+func (r *RecallMarkRepository) Import(raw json.RawMessage) error {
+	rows, err := importRows(raw, func(m vehicles.RecallMark) string { return recallMarkKey(m.VehicleID, m.BulletinID) })
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.marks = rows
+	return nil
+}
+
+// Apply implements Persistent.
+func (r *RecallMarkRepository) Apply(op Op, id string, raw json.RawMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return applyRow(r.marks, op, id, raw)
+}
@@ -0,0 +1,9 @@
+package memory
+
+import "time"
+
+// timestamp returns the current time truncated to the microsecond precision
+// used by the SQL backends so both report identical values.
+func timestamp() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
@@ -1,18 +1,19 @@
 package memory
 
 import (
+	"encoding/json"
 	"sort"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/ezmobilemechanic/platform/internal/domain/users"
 )
 
 // UserRepository implements users.Repository in-memory.
 type UserRepository struct {
-	mu    sync.RWMutex
-	store map[string]users.User
+	mu      sync.RWMutex
+	store   map[string]users.User
+	journal Journal
 }
 
 // NewUserRepository constructs repository.
@@ -45,16 +46,27 @@ func (r *UserRepository) Save(user users.User) (users.User, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	now := time.Now().UTC()
+	now := timestamp()
 	if user.ID == "" {
 		user.ID = newID()
 		user.CreatedAt = now
-	} else if existing, ok := r.store[user.ID]; ok {
-		if user.CreatedAt.IsZero() {
-			user.CreatedAt = existing.CreatedAt
+	} else {
+		existing, ok := r.store[user.ID]
+		if !ok {
+			return users.User{}, users.ErrNotFound
+		}
+		user.CreatedAt = existing.CreatedAt
+	}
+	user.Email = strings.ToLower(user.Email)
+	for id, u := range r.store {
+		if id != user.ID && u.Email == user.Email {
+			return users.User{}, users.ErrEmailExists
 		}
 	}
 	user.UpdatedAt = now
+	if err := record(r.journal, r.Table(), OpPut, user.ID, user); err != nil {
+		return users.User{}, err
+	}
 	r.store[user.ID] = user
 	return user, nil
 }
@@ -74,3 +86,39 @@ func (r *UserRepository) List() []users.User {
 
 // Ensure interface satisfaction at compile time.
 var _ users.Repository = (*UserRepository)(nil)
+
+// Table implements Persistent.
+func (r *UserRepository) Table() string { return "users" }
+
+// SetJournal implements Persistent.
+func (r *UserRepository) SetJournal(j Journal) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.journal = j
+}
+
+// Export implements Persistent.
+func (r *UserRepository) Export() any {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return exportRows(r.store)
+}
+
+// Import implements Persistent.
+func (r *UserRepository) Import(raw json.RawMessage) error {
+	rows, err := importRows(raw, func(u users.User) string { return u.ID })
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.store = rows
+	return nil
+}
+
+// Apply implements Persistent.
+func (r *UserRepository) Apply(op Op, id string, raw json.RawMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return applyRow(r.store, op, id, raw)
+}
@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+)
+
+// VehicleTransferLog stores ownership transfers for a VehicleRepository. It
+// is a separate table so the file backend can persist it; register it
+// alongside the repository returned by VehicleRepository.Transfers.
+type VehicleTransferLog struct {
+	mu        sync.RWMutex
+	transfers map[string]vehicles.Transfer
+	journal   Journal
+}
+
+func newVehicleTransferLog() *VehicleTransferLog {
+	return &VehicleTransferLog{transfers: make(map[string]vehicles.Transfer)}
+}
+
+func (l *VehicleTransferLog) add(t vehicles.Transfer) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := record(l.journal, l.Table(), OpPut, t.ID, t); err != nil {
+		return err
+	}
+	l.transfers[t.ID] = t
+	return nil
+}
+
+// remove undoes add for a transfer that could not be completed. It is
+// called after another write has failed, so a failure to journal the removal
+// is not reported.
+func (l *VehicleTransferLog) remove(id string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	record(l.journal, l.Table(), OpDelete, id, nil)
+	delete(l.transfers, id)
+}
+
+func (l *VehicleTransferLog) list(vehicleID string) []vehicles.Transfer {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	list := []vehicles.Transfer{}
+	for _, t := range l.transfers {
+		if t.VehicleID == vehicleID {
+			list = append(list, t)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].TransferredAt.Equal(list[j].TransferredAt) {
+			return list[i].TransferredAt.Before(list[j].TransferredAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// Table implements Persistent.
+func (l *VehicleTransferLog) Table() string { return "vehicle_transfers" }
+
+// SetJournal implements Persistent.
+func (l *VehicleTransferLog) SetJournal(j Journal) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.journal = j
+}
+
+// Export implements Persistent.
+func (l *VehicleTransferLog) Export() any {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return exportRows(l.transfers)
+}
+
+// Import implements Persistent.
+func (l *VehicleTransferLog) Import(raw json.RawMessage) error {
+	rows, err := importRows(raw, func(t vehicles.Transfer) string { return t.ID })
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.transfers = rows
+	return nil
+}
+
+// Apply implements Persistent.
+func (l *VehicleTransferLog) Apply(op Op, id string, raw json.RawMessage) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return applyRow(l.transfers, op, id, raw)
+}
@@ -1,26 +1,51 @@
 package memory
 
 import (
+	"encoding/json"
 	"sort"
 	"sync"
 	"time"
 
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
 	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
 )
 
 // VehicleRepository is an in-memory implementation of vehicles.Repository.
 type VehicleRepository struct {
-	mu       sync.RWMutex
-	vehicles map[string]vehicles.Vehicle
+	mu        sync.RWMutex
+	vehicles  map[string]vehicles.Vehicle
+	journal   Journal
+	transfers *VehicleTransferLog
+	odometer  *OdometerRepository
+	recalls   *RecallMarkRepository
 }
 
 // NewVehicleRepository creates an in-memory vehicle repo.
 func NewVehicleRepository() *VehicleRepository {
 	return &VehicleRepository{
-		vehicles: make(map[string]vehicles.Vehicle),
+		vehicles:  make(map[string]vehicles.Vehicle),
+		transfers: newVehicleTransferLog(),
+		odometer:  newOdometerRepository(),
+		recalls:   newRecallMarkRepository(),
 	}
 }
 
+// Transfers returns the log of ownership transfers, for registration with
+// the file backend.
+func (r *VehicleRepository) Transfers() *VehicleTransferLog {
+	return r.transfers
+}
+
+// Odometer returns the repository of odometer readings.
+func (r *VehicleRepository) Odometer() *OdometerRepository {
+	return r.odometer
+}
+
+// Recalls returns the repository of recall marks.
+func (r *VehicleRepository) Recalls() *RecallMarkRepository {
+	return r.recalls
+}
+
 func (r *VehicleRepository) FindByID(id string) (vehicles.Vehicle, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -44,27 +69,156 @@ func (r *VehicleRepository) ListByCustomer(customerID string) ([]vehicles.Vehicl
 	}
 
 	sort.Slice(list, func(i, j int) bool {
-		return list[i].CreatedAt.Before(list[j].CreatedAt)
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		}
+		return list[i].ID < list[j].ID
 	})
 
 	return list, nil
 }
 
+// customersMatching returns the IDs of customers owning a vehicle whose VIN
+// matches terms.
+func (r *VehicleRepository) customersMatching(terms customers.SearchTerms) map[string]bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make(map[string]bool)
+	for _, v := range r.vehicles {
+		if terms.MatchesVIN(v.VIN) {
+			ids[v.CustomerID] = true
+		}
+	}
+	return ids
+}
+
+// reparentLocked moves every vehicle of customer from to customer to and
+// returns their IDs. The caller holds r.mu.
+func (r *VehicleRepository) reparentLocked(from, to string, now time.Time) ([]string, error) {
+	ids := []string{}
+	for id, v := range r.vehicles {
+		if v.CustomerID == from {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		v := r.vehicles[id]
+		v.CustomerID, v.UpdatedAt = to, now
+		if err := record(r.journal, r.Table(), OpPut, id, v); err != nil {
+			return nil, err
+		}
+		r.vehicles[id] = v
+	}
+	return ids, nil
+}
+
 func (r *VehicleRepository) Save(vehicle vehicles.Vehicle) (vehicles.Vehicle, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	now := time.Now().UTC()
+	now := timestamp()
 	if vehicle.ID == "" {
 		vehicle.ID = newID()
 		vehicle.CreatedAt = now
 	} else {
 		existing, ok := r.vehicles[vehicle.ID]
-		if ok && vehicle.CreatedAt.IsZero() {
-			vehicle.CreatedAt = existing.CreatedAt
+		if !ok {
+			return vehicles.Vehicle{}, vehicles.ErrNotFound
 		}
+		vehicle.CreatedAt = existing.CreatedAt
 	}
 	vehicle.UpdatedAt = now
+	if err := record(r.journal, r.Table(), OpPut, vehicle.ID, vehicle); err != nil {
+		return vehicles.Vehicle{}, err
+	}
 	r.vehicles[vehicle.ID] = vehicle
 	return vehicle, nil
 }
+
+// Transfer moves a vehicle to its new owner and records the transfer.
+func (r *VehicleRepository) Transfer(t vehicles.Transfer, readings []vehicles.Reading) (vehicles.Transfer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, ok := r.vehicles[t.VehicleID]
+	if !ok || v.CustomerID != t.FromCustomerID {
+		return vehicles.Transfer{}, vehicles.ErrNotFound
+	}
+	now := timestamp()
+	t.ID = newID()
+	t.TransferredAt = now
+	if err := r.transfers.add(t); err != nil {
+		return vehicles.Transfer{}, err
+	}
+	// A failed write takes back the ones before it, so the transfer is
+	// applied whole or not at all.
+	var added []string
+	undo := func() {
+		for _, id := range added {
+			r.odometer.remove(id)
+		}
+		r.transfers.remove(t.ID)
+	}
+	for _, reading := range readings {
+		saved, err := r.odometer.Add(reading)
+		if err != nil {
+			undo()
+			return vehicles.Transfer{}, err
+		}
+		added = append(added, saved.ID)
+	}
+
+	v.CustomerID, v.UnitNumber, v.UpdatedAt = t.ToCustomerID, "", now
+	if t.Mileage > v.Mileage {
+		v.Mileage = t.Mileage
+	}
+	if err := record(r.journal, r.Table(), OpPut, v.ID, v); err != nil {
+		undo()
+		return vehicles.Transfer{}, err
+	}
+	r.vehicles[v.ID] = v
+	return t, nil
+}
+
+// ListTransfers returns a vehicle's transfers, oldest first.
+func (r *VehicleRepository) ListTransfers(vehicleID string) ([]vehicles.Transfer, error) {
+	return r.transfers.list(vehicleID), nil
+}
+
+// Table implements Persistent.
+func (r *VehicleRepository) Table() string { return "vehicles" }
+
+// SetJournal implements Persistent.
+func (r *VehicleRepository) SetJournal(j Journal) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.journal = j
+}
+
+// Export implements Persistent.
+func (r *VehicleRepository) Export() any {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return exportRows(r.vehicles)
+}
+
+// Import implements Persistent.
+func (r *VehicleRepository) Import(raw json.RawMessage) error {
+	rows, err := importRows(raw, func(v vehicles.Vehicle) string { return v.ID })
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.vehicles = rows
+	return nil
+}
+
+// Apply implements Persistent.
+func (r *VehicleRepository) Apply(op Op, id string, raw json.RawMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return applyRow(r.vehicles, op, id, raw)
+}
@@ -0,0 +1,46 @@
+package memory_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+	"github.com/ezmobilemechanic/platform/internal/storage/memory"
+)
+
+// failingJournal refuses every record for one table.
+type failingJournal struct{ table string }
+
+func (j failingJournal) Record(table string, op memory.Op, id string, value any) error {
+	if table == j.table {
+		return errors.New("disk full")
+	}
+	return nil
+}
+
+func TestTransferRollsBackOnJournalFailure(t *testing.T) {
+	repo := memory.NewVehicleRepository()
+	v, err := repo.Save(vehicles.Vehicle{CustomerID: "seller", Make: "Ford", UnitNumber: "Van 1", Mileage: 40000})
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	journal := failingJournal{table: repo.Table()}
+	repo.SetJournal(journal)
+	repo.Transfers().SetJournal(journal)
+	repo.Odometer().SetJournal(journal)
+
+	_, err = repo.Transfer(
+		vehicles.Transfer{VehicleID: v.ID, FromCustomerID: "seller", ToCustomerID: "buyer", Mileage: 41000},
+		[]vehicles.Reading{{VehicleID: v.ID, Mileage: 41000, Source: vehicles.SourceTransfer}},
+	)
+	if err == nil {
+		t.Fatal("expected the journal failure")
+	}
+
+	got, _ := repo.FindByID(v.ID)
+	transfers, _ := repo.ListTransfers(v.ID)
+	readings, _ := repo.Odometer().ListByVehicle(v.ID)
+	if got.CustomerID != "seller" || got.UnitNumber != "Van 1" || len(transfers) != 0 || len(readings) != 0 {
+		t.Fatalf("expected nothing applied, got %+v, %+v and %+v", got, transfers, readings)
+	}
+}
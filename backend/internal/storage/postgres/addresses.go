@@ -0,0 +1,211 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/geo"
+	"github.com/ezmobilemechanic/platform/internal/pii"
+)
+
+// CustomerAddressRepository persists customer addresses in Postgres.
+type CustomerAddressRepository struct {
+	db   *sql.DB
+	keys *pii.Keyring
+}
+
+// NewCustomerAddressRepository constructs the repository.
+func NewCustomerAddressRepository(db *sql.DB) *CustomerAddressRepository {
+	return &CustomerAddressRepository{db: db}
+}
+
+// SetKeyring encrypts street lines with keys. City, region and postal code
+// stay in plaintext for travel pricing and reporting.
+func (r *CustomerAddressRepository) SetKeyring(keys *pii.Keyring) {
+	r.keys = keys
+}
+
+const addressColumns = `id, customer_id, label, line1, line2, city, region, postal_code, country,
+               latitude, longitude, geocoded_by, created_at, updated_at`
+
+// FindByID fetches an address by identifier.
+func (r *CustomerAddressRepository) FindByID(id string) (customers.Address, error) {
+	if !isUUID(id) {
+		return customers.Address{}, customers.ErrAddressNotFound
+	}
+
+	a, err := scanAddress(r.keys, r.db.QueryRow(`SELECT `+addressColumns+` FROM customer_addresses WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return customers.Address{}, customers.ErrAddressNotFound
+		}
+		return customers.Address{}, fmt.Errorf("find address: %w", err)
+	}
+	return a, nil
+}
+
+// ListByCustomer returns addresses of a customer ordered by creation.
+func (r *CustomerAddressRepository) ListByCustomer(customerID string) ([]customers.Address, error) {
+	if !isUUID(customerID) {
+		return nil, nil
+	}
+
+	rows, err := r.db.Query(`SELECT `+addressColumns+` FROM customer_addresses
+         WHERE customer_id = $1
+         ORDER BY created_at, id`, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("list addresses: %w", err)
+	}
+	defer rows.Close()
+
+	var result []customers.Address
+	for rows.Next() {
+		a, err := scanAddress(r.keys, rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan address: %w", err)
+		}
+		result = append(result, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return result, nil
+}
+
+// Save inserts or updates an address.
+func (r *CustomerAddressRepository) Save(a customers.Address) (customers.Address, error) {
+	now := timestamp()
+	lat, lng := pointArgs(a.Location)
+	line1, line2, err := sealAddress(r.keys, a)
+	if err != nil {
+		return customers.Address{}, err
+	}
+
+	if a.ID == "" {
+		const insert = `
+            INSERT INTO customer_addresses (customer_id, label, line1, line2, city, region, postal_code, country,
+                                            latitude, longitude, geocoded_by, created_at, updated_at)
+            VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$12)
+            RETURNING id
+        `
+		if err := r.db.QueryRow(insert,
+			a.CustomerID, a.Label, line1, line2, a.City, a.Region, a.PostalCode, a.Country,
+			lat, lng, a.GeocodedBy, now,
+		).Scan(&a.ID); err != nil {
+			return customers.Address{}, fmt.Errorf("insert address: %w", err)
+		}
+		a.CreatedAt = now
+		a.UpdatedAt = now
+		return a, nil
+	}
+
+	if !isUUID(a.ID) {
+		return customers.Address{}, customers.ErrAddressNotFound
+	}
+
+	const update = `
+        UPDATE customer_addresses
+           SET customer_id = $2,
+               label = $3,
+               line1 = $4,
+               line2 = $5,
+               city = $6,
+               region = $7,
+               postal_code = $8,
+               country = $9,
+               latitude = $10,
+               longitude = $11,
+               geocoded_by = $12,
+               updated_at = $13
+         WHERE id = $1
+        RETURNING created_at
+    `
+	var created time.Time
+	err = r.db.QueryRow(update,
+		a.ID, a.CustomerID, a.Label, line1, line2, a.City, a.Region, a.PostalCode, a.Country,
+		lat, lng, a.GeocodedBy, now,
+	).Scan(&created)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return customers.Address{}, customers.ErrAddressNotFound
+		}
+		return customers.Address{}, fmt.Errorf("update address: %w", err)
+	}
+	a.CreatedAt = created
+	a.UpdatedAt = now
+	return a, nil
+}
+
+// Delete removes an address.
+func (r *CustomerAddressRepository) Delete(id string) error {
+	if !isUUID(id) {
+		return customers.ErrAddressNotFound
+	}
+	res, err := r.db.Exec(`DELETE FROM customer_addresses WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete address: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("delete address: %w", err)
+	} else if n == 0 {
+		return customers.ErrAddressNotFound
+	}
+	return nil
+}
+
+func pointArgs(p *geo.Point) (sql.NullFloat64, sql.NullFloat64) {
+	if p == nil {
+		return sql.NullFloat64{}, sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: p.Lat, Valid: true}, sql.NullFloat64{Float64: p.Lng, Valid: true}
+}
+
+func sealAddress(keys *pii.Keyring, a customers.Address) (line1, line2 string, err error) {
+	if line1, err = keys.Encrypt(pii.FieldAddressLine1, a.Line1); err != nil {
+		return "", "", fmt.Errorf("encrypt address: %w", err)
+	}
+	if line2, err = keys.Encrypt(pii.FieldAddressLine2, a.Line2); err != nil {
+		return "", "", fmt.Errorf("encrypt address: %w", err)
+	}
+	return line1, line2, nil
+}
+
+// scanAddress reads an address row, decrypting its street lines.
+func scanAddress(keys *pii.Keyring, row rowScanner) (customers.Address, error) {
+	var (
+		a        customers.Address
+		lat, lng sql.NullFloat64
+	)
+	err := row.Scan(
+		&a.ID,
+		&a.CustomerID,
+		&a.Label,
+		&a.Line1,
+		&a.Line2,
+		&a.City,
+		&a.Region,
+		&a.PostalCode,
+		&a.Country,
+		&lat,
+		&lng,
+		&a.GeocodedBy,
+		&a.CreatedAt,
+		&a.UpdatedAt,
+	)
+	if err != nil {
+		return a, err
+	}
+	if lat.Valid && lng.Valid {
+		a.Location = &geo.Point{Lat: lat.Float64, Lng: lng.Float64}
+	}
+	if a.Line1, err = keys.Decrypt(pii.FieldAddressLine1, a.Line1); err != nil {
+		return a, fmt.Errorf("decrypt address %s: %w", a.ID, err)
+	}
+	if a.Line2, err = keys.Decrypt(pii.FieldAddressLine2, a.Line2); err != nil {
+		return a, fmt.Errorf("decrypt address %s: %w", a.ID, err)
+	}
+	return a, nil
+}
@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+)
+
+// CustomerConsentRepository persists the consent ledger in Postgres.
+type CustomerConsentRepository struct {
+	db *sql.DB
+}
+
+// NewCustomerConsentRepository constructs the repository.
+func NewCustomerConsentRepository(db *sql.DB) *CustomerConsentRepository {
+	return &CustomerConsentRepository{db: db}
+}
+
+// Append inserts a ledger entry.
+func (r *CustomerConsentRepository) Append(e customers.ConsentEntry) (customers.ConsentEntry, error) {
+	const insert = `
+        INSERT INTO customer_consents (customer_id, channel, purpose, granted, source, detail, ip_address, user_agent, recorded_at)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+        RETURNING id
+    `
+
+	now := timestamp()
+	if err := r.db.QueryRow(insert,
+		e.CustomerID,
+		string(e.Channel),
+		string(e.Purpose),
+		e.Granted,
+		e.Source,
+		e.Detail,
+		e.IPAddress,
+		e.UserAgent,
+		now,
+	).Scan(&e.ID); err != nil {
+		return customers.ConsentEntry{}, fmt.Errorf("insert consent: %w", err)
+	}
+	e.RecordedAt = now
+	return e, nil
+}
+
+// ListByCustomer returns the customer's ledger oldest first.
+func (r *CustomerConsentRepository) ListByCustomer(customerID string) ([]customers.ConsentEntry, error) {
+	const query = `
+        SELECT id, customer_id, channel, purpose, granted, source, detail, ip_address, user_agent, recorded_at
+          FROM customer_consents
+         WHERE customer_id = $1
+         ORDER BY recorded_at, seq
+    `
+
+	if !isUUID(customerID) {
+		return nil, nil
+	}
+
+	rows, err := r.db.Query(query, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("list consents: %w", err)
+	}
+	defer rows.Close()
+
+	var result []customers.ConsentEntry
+	for rows.Next() {
+		var e customers.ConsentEntry
+		if err := rows.Scan(
+			&e.ID,
+			&e.CustomerID,
+			&e.Channel,
+			&e.Purpose,
+			&e.Granted,
+			&e.Source,
+			&e.Detail,
+			&e.IPAddress,
+			&e.UserAgent,
+			&e.RecordedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan consent: %w", err)
+		}
+		result = append(result, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,192 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/pii"
+)
+
+// FleetContactRepository persists fleet contacts in Postgres.
+type FleetContactRepository struct {
+	db   *sql.DB
+	keys *pii.Keyring
+}
+
+// NewFleetContactRepository constructs the repository.
+func NewFleetContactRepository(db *sql.DB) *FleetContactRepository {
+	return &FleetContactRepository{db: db}
+}
+
+// SetKeyring encrypts contact emails and phones with keys, as for
+// customers. Names stay in plaintext.
+func (r *FleetContactRepository) SetKeyring(keys *pii.Keyring) {
+	r.keys = keys
+}
+
+const contactColumns = `id, customer_id, name, role, email, phone, phone_e164, created_at, updated_at`
+
+// FindByID fetches a contact by identifier.
+func (r *FleetContactRepository) FindByID(id string) (customers.Contact, error) {
+	if !isUUID(id) {
+		return customers.Contact{}, customers.ErrContactNotFound
+	}
+
+	c, err := scanContact(r.keys, r.db.QueryRow(`SELECT `+contactColumns+` FROM fleet_contacts WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return customers.Contact{}, customers.ErrContactNotFound
+		}
+		return customers.Contact{}, fmt.Errorf("find contact: %w", err)
+	}
+	return c, nil
+}
+
+// ListByCustomer returns contacts of a customer ordered by creation.
+func (r *FleetContactRepository) ListByCustomer(customerID string) ([]customers.Contact, error) {
+	if !isUUID(customerID) {
+		return nil, nil
+	}
+
+	rows, err := r.db.Query(`SELECT `+contactColumns+` FROM fleet_contacts
+         WHERE customer_id = $1
+         ORDER BY created_at, id`, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("list contacts: %w", err)
+	}
+	defer rows.Close()
+
+	var result []customers.Contact
+	for rows.Next() {
+		c, err := scanContact(r.keys, rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan contact: %w", err)
+		}
+		result = append(result, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return result, nil
+}
+
+// Save inserts or updates a contact.
+func (r *FleetContactRepository) Save(c customers.Contact) (customers.Contact, error) {
+	now := timestamp()
+	email, phone, e164, err := sealContact(r.keys, c)
+	if err != nil {
+		return customers.Contact{}, err
+	}
+
+	if c.ID == "" {
+		const insert = `
+            INSERT INTO fleet_contacts (customer_id, name, role, email, phone, phone_e164, created_at, updated_at)
+            VALUES ($1,$2,$3,$4,$5,$6,$7,$7)
+            RETURNING id
+        `
+		if err := r.db.QueryRow(insert,
+			c.CustomerID, c.Name, string(c.Role), email, phone, e164, now,
+		).Scan(&c.ID); err != nil {
+			return customers.Contact{}, fmt.Errorf("insert contact: %w", err)
+		}
+		c.CreatedAt = now
+		c.UpdatedAt = now
+		return c, nil
+	}
+
+	if !isUUID(c.ID) {
+		return customers.Contact{}, customers.ErrContactNotFound
+	}
+
+	const update = `
+        UPDATE fleet_contacts
+           SET customer_id = $2,
+               name = $3,
+               role = $4,
+               email = $5,
+               phone = $6,
+               phone_e164 = $7,
+               updated_at = $8
+         WHERE id = $1
+        RETURNING created_at
+    `
+	var created time.Time
+	err = r.db.QueryRow(update,
+		c.ID, c.CustomerID, c.Name, string(c.Role), email, phone, e164, now,
+	).Scan(&created)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return customers.Contact{}, customers.ErrContactNotFound
+		}
+		return customers.Contact{}, fmt.Errorf("update contact: %w", err)
+	}
+	c.CreatedAt = created
+	c.UpdatedAt = now
+	return c, nil
+}
+
+// Delete removes a contact.
+func (r *FleetContactRepository) Delete(id string) error {
+	if !isUUID(id) {
+		return customers.ErrContactNotFound
+	}
+	res, err := r.db.Exec(`DELETE FROM fleet_contacts WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete contact: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("delete contact: %w", err)
+	} else if n == 0 {
+		return customers.ErrContactNotFound
+	}
+	return nil
+}
+
+func sealContact(keys *pii.Keyring, c customers.Contact) (email, phone, e164 string, err error) {
+	if email, err = keys.Encrypt(pii.FieldContactEmail, c.Email); err != nil {
+		return "", "", "", fmt.Errorf("encrypt contact: %w", err)
+	}
+	if phone, err = keys.Encrypt(pii.FieldContactPhone, c.Phone); err != nil {
+		return "", "", "", fmt.Errorf("encrypt contact: %w", err)
+	}
+	if e164, err = keys.Encrypt(pii.FieldContactE164, c.PhoneE164); err != nil {
+		return "", "", "", fmt.Errorf("encrypt contact: %w", err)
+	}
+	return email, phone, e164, nil
+}
+
+// scanContact reads a contact row, decrypting its email and phones.
+func scanContact(keys *pii.Keyring, row rowScanner) (customers.Contact, error) {
+	var (
+		c    customers.Contact
+		role string
+	)
+	err := row.Scan(
+		&c.ID,
+		&c.CustomerID,
+		&c.Name,
+		&role,
+		&c.Email,
+		&c.Phone,
+		&c.PhoneE164,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+	if err != nil {
+		return c, err
+	}
+	c.Role = customers.ContactRole(role)
+	if c.Email, err = keys.Decrypt(pii.FieldContactEmail, c.Email); err != nil {
+		return c, fmt.Errorf("decrypt contact %s: %w", c.ID, err)
+	}
+	if c.Phone, err = keys.Decrypt(pii.FieldContactPhone, c.Phone); err != nil {
+		return c, fmt.Errorf("decrypt contact %s: %w", c.ID, err)
+	}
+	if c.PhoneE164, err = keys.Decrypt(pii.FieldContactE164, c.PhoneE164); err != nil {
+		return c, fmt.Errorf("decrypt contact %s: %w", c.ID, err)
+	}
+	return c, nil
+}
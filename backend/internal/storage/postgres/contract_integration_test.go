@@ -0,0 +1,33 @@
+//go:build integration
+
+package postgres_test
+
+import (
+	"testing"
+
+	pgstorage "github.com/ezmobilemechanic/platform/internal/storage/postgres"
+	"github.com/ezmobilemechanic/platform/internal/storage/storagetest"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	storagetest.Run(t, func(t *testing.T) storagetest.Backend {
+		cleanupTables(t, db)
+		return storagetest.Backend{
+			Customers:    pgstorage.NewCustomerRepository(db),
+			Addresses:    pgstorage.NewCustomerAddressRepository(db),
+			Consents:     pgstorage.NewCustomerConsentRepository(db),
+			Notes:        pgstorage.NewCustomerNoteRepository(db),
+			Contacts:     pgstorage.NewFleetContactRepository(db),
+			Vehicles:     pgstorage.NewVehicleRepository(db),
+			Odometer:     pgstorage.NewOdometerRepository(db),
+			Recalls:      pgstorage.NewRecallMarkRepository(db),
+			Quotes:       pgstorage.NewQuoteRepository(db),
+			QuoteHistory: pgstorage.NewQuoteStatusLog(db),
+			Users:        pgstorage.NewUserRepository(db),
+			Erasures:     pgstorage.NewErasureRequestRepository(db),
+		}
+	})
+}
@@ -2,44 +2,59 @@ package postgres
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
+	"github.com/ezmobilemechanic/platform/internal/phone"
+	"github.com/ezmobilemechanic/platform/internal/pii"
 )
 
 // CustomerRepository persists customers using a *sql.DB handle.
 type CustomerRepository struct {
-	db *sql.DB
+	db          *sql.DB
+	keys        *pii.Keyring
+	phoneRegion string
 }
 
 // NewCustomerRepository returns a repository backed by a pooled DB connection.
 func NewCustomerRepository(db *sql.DB) *CustomerRepository {
-	return &CustomerRepository{db: db}
+	return &CustomerRepository{db: db, phoneRegion: phone.DefaultRegion}
+}
+
+// SetKeyring encrypts emails and phones with keys. Without it they are
+// stored in plaintext.
+func (r *CustomerRepository) SetKeyring(keys *pii.Keyring) {
+	r.keys = keys
+}
+
+// SetPhoneRegion sets the region search reads phone numbers in when they are
+// typed without a country code. It must match the customer service's
+// (customers.WithPhoneRegion). The default is phone.DefaultRegion.
+func (r *CustomerRepository) SetPhoneRegion(region string) {
+	r.phoneRegion = region
 }
 
 // FindByID fetches a customer by primary key.
 func (r *CustomerRepository) FindByID(id string) (customers.Customer, error) {
 	const query = `
-        SELECT id, external_id, first_name, last_name, email, phone, marketing_opt,
-               created_at, updated_at
+        SELECT id, external_id, first_name, last_name, email, phone, phone_e164, tags::text,
+               account_type, company_name, po_required, billing,
+               created_at, updated_at, deleted_at
           FROM customers
-         WHERE id = $1
+         WHERE id = $1 AND deleted_at IS NULL
     `
 
-	var c customers.Customer
-	err := r.db.QueryRow(query, id).Scan(
-		&c.ID,
-		&c.ExternalID,
-		&c.FirstName,
-		&c.LastName,
-		&c.Email,
-		&c.Phone,
-		&c.MarketingOpt,
-		&c.CreatedAt,
-		&c.UpdatedAt,
-	)
+	if !isUUID(id) {
+		return customers.Customer{}, customers.ErrNotFound
+	}
+
+	c, err := scanCustomer(r.keys, r.db.QueryRow(query, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return customers.Customer{}, customers.ErrNotFound
@@ -52,24 +67,39 @@ func (r *CustomerRepository) FindByID(id string) (customers.Customer, error) {
 
 // Save inserts or updates a customer record.
 func (r *CustomerRepository) Save(customer customers.Customer) (customers.Customer, error) {
-	now := time.Now().UTC()
+	now := timestamp()
+	sealed, err := sealCustomer(r.keys, customer)
+	if err != nil {
+		return customers.Customer{}, err
+	}
 
 	if customer.ID == "" {
 		const insert = `
-            INSERT INTO customers (external_id, first_name, last_name, email, phone, marketing_opt, created_at, updated_at)
-            VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+            INSERT INTO customers (external_id, first_name, last_name, email, phone, phone_e164, tags, created_at, updated_at,
+                                   email_bidx, phone_bidx, account_type, company_name, po_required, billing)
+            VALUES ($1,$2,$3,$4,$5,$6,$7::jsonb,$8,$9,$10,$11,$12,$13,$14,$15)
             RETURNING id
         `
 		if err := r.db.QueryRow(insert,
 			customer.ExternalID,
 			customer.FirstName,
 			customer.LastName,
-			customer.Email,
-			customer.Phone,
-			customer.MarketingOpt,
+			sealed.email,
+			sealed.phone,
+			sealed.phoneE164,
+			tagsArg(customer.Tags),
 			now,
 			now,
+			sealed.emailIndex,
+			sealed.phoneIndex,
+			string(customer.Type),
+			customer.CompanyName,
+			customer.PORequired,
+			string(customer.Billing),
 		).Scan(&customer.ID); err != nil {
+			if isUniqueViolation(err) {
+				return customers.Customer{}, customers.ErrEmailExists
+			}
 			return customers.Customer{}, fmt.Errorf("insert customer: %w", err)
 		}
 		customer.CreatedAt = now
@@ -84,27 +114,48 @@ func (r *CustomerRepository) Save(customer customers.Customer) (customers.Custom
                last_name = $4,
                email = $5,
                phone = $6,
-               marketing_opt = $7,
-               updated_at = $8
-         WHERE id = $1
+               phone_e164 = $7,
+               tags = $8::jsonb,
+               updated_at = $9,
+               email_bidx = $10,
+               phone_bidx = $11,
+               account_type = $12,
+               company_name = $13,
+               po_required = $14,
+               billing = $15
+         WHERE id = $1 AND deleted_at IS NULL
         RETURNING created_at
     `
 
+	if !isUUID(customer.ID) {
+		return customers.Customer{}, customers.ErrNotFound
+	}
+
 	var created time.Time
-	err := r.db.QueryRow(update,
+	err = r.db.QueryRow(update,
 		customer.ID,
 		customer.ExternalID,
 		customer.FirstName,
 		customer.LastName,
-		customer.Email,
-		customer.Phone,
-		customer.MarketingOpt,
+		sealed.email,
+		sealed.phone,
+		sealed.phoneE164,
+		tagsArg(customer.Tags),
 		now,
+		sealed.emailIndex,
+		sealed.phoneIndex,
+		string(customer.Type),
+		customer.CompanyName,
+		customer.PORequired,
+		string(customer.Billing),
 	).Scan(&created)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return customers.Customer{}, customers.ErrNotFound
 		}
+		if isUniqueViolation(err) {
+			return customers.Customer{}, customers.ErrEmailExists
+		}
 		return customers.Customer{}, fmt.Errorf("update customer: %w", err)
 	}
 
@@ -113,45 +164,435 @@ func (r *CustomerRepository) Save(customer customers.Customer) (customers.Custom
 	return customer, nil
 }
 
-// List returns customers ordered by creation date.
-func (r *CustomerRepository) List(offset, limit int) ([]customers.Customer, error) {
+// List returns a keyset-paginated page of customers matching the spec.
+func (r *CustomerRepository) List(spec listing.Spec) (listing.Page[customers.Customer], error) {
+	const selectCustomers = `
+        SELECT id, external_id, first_name, last_name, email, phone, phone_e164, tags::text,
+               account_type, company_name, po_required, billing,
+               created_at, updated_at, deleted_at
+          FROM customers`
+
+	spec = spec.Normalize()
+
+	var q listQuery
+	q.filter("deleted_at IS NULL")
+	q.created("created_at", spec)
+	searchCustomers(&q, customers.ParseSearch(spec.Search, r.phoneRegion), r.keys)
+
+	countQuery, countArgs := q.countSQL("customers")
+	var total int
+	if err := r.db.QueryRow(countQuery, countArgs...).Scan(&total); err != nil {
+		return listing.Page[customers.Customer]{}, fmt.Errorf("count customers: %w", err)
+	}
+
+	query, err := q.page(selectCustomers, spec)
+	if err != nil {
+		return listing.Page[customers.Customer]{}, err
+	}
+
+	rows, err := r.db.Query(query, q.args...)
+	if err != nil {
+		return listing.Page[customers.Customer]{}, fmt.Errorf("list customers: %w", err)
+	}
+	defer rows.Close()
+
+	var result []customers.Customer
+	for rows.Next() {
+		c, err := scanCustomer(r.keys, rows)
+		if err != nil {
+			return listing.Page[customers.Customer]{}, fmt.Errorf("scan customer: %w", err)
+		}
+		result = append(result, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return listing.Page[customers.Customer]{}, fmt.Errorf("rows error: %w", err)
+	}
+
+	return cutPage(result, spec, total, customers.SortKey), nil
+}
+
+// Delete soft-deletes an active customer.
+func (r *CustomerRepository) Delete(id string) (customers.Customer, error) {
 	const query = `
-        SELECT id, external_id, first_name, last_name, email, phone, marketing_opt,
-               created_at, updated_at
+        UPDATE customers
+           SET deleted_at = $2,
+               updated_at = $2
+         WHERE id = $1 AND deleted_at IS NULL
+        RETURNING id, external_id, first_name, last_name, email, phone, phone_e164, tags::text,
+                  account_type, company_name, po_required, billing,
+                  created_at, updated_at, deleted_at
+    `
+
+	if !isUUID(id) {
+		return customers.Customer{}, customers.ErrNotFound
+	}
+
+	c, err := scanCustomer(r.keys, r.db.QueryRow(query, id, timestamp()))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return customers.Customer{}, customers.ErrNotFound
+		}
+		return customers.Customer{}, fmt.Errorf("delete customer: %w", err)
+	}
+	return c, nil
+}
+
+// Restore reactivates a soft-deleted customer. It fails with
+// customers.ErrEmailExists if an active customer has since taken the email.
+func (r *CustomerRepository) Restore(id string) (customers.Customer, error) {
+	const query = `
+        UPDATE customers
+           SET deleted_at = NULL,
+               updated_at = $2
+         WHERE id = $1 AND deleted_at IS NOT NULL
+        RETURNING id, external_id, first_name, last_name, email, phone, phone_e164, tags::text,
+                  account_type, company_name, po_required, billing,
+                  created_at, updated_at, deleted_at
+    `
+
+	if !isUUID(id) {
+		return customers.Customer{}, customers.ErrNotFound
+	}
+
+	c, err := scanCustomer(r.keys, r.db.QueryRow(query, id, timestamp()))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return customers.Customer{}, customers.ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return customers.Customer{}, customers.ErrEmailExists
+		}
+		return customers.Customer{}, fmt.Errorf("restore customer: %w", err)
+	}
+	return c, nil
+}
+
+// Purge removes customers soft-deleted before the cutoff. Their vehicles and
+// quotes are removed by the foreign key cascade.
+func (r *CustomerRepository) Purge(deletedBefore time.Time) (int, error) {
+	res, err := r.db.Exec(`DELETE FROM customers WHERE deleted_at < $1`, deletedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("purge customers: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("purge customers: %w", err)
+	}
+	return int(n), nil
+}
+
+// FindMatches returns active customers sharing any of the keys.
+func (r *CustomerRepository) FindMatches(keys customers.MatchKeys) ([]customers.Customer, error) {
+	const query = `
+        SELECT id, external_id, first_name, last_name, email, phone, phone_e164, tags::text,
+               account_type, company_name, po_required, billing,
+               created_at, updated_at, deleted_at
           FROM customers
-         ORDER BY created_at
-         OFFSET $1
-         LIMIT $2
+         WHERE deleted_at IS NULL
+           AND id::text <> $1
+           AND (($2 <> '' AND email_bidx = $2)
+             OR ($3 <> '' AND phone_bidx = $3)
+             OR ($4 <> '' AND lower(last_name) = lower($4)))
+         ORDER BY id
     `
 
-	rows, err := r.db.Query(query, offset, limit)
+	rows, err := r.db.Query(query, keys.ExcludeID, r.keys.EmailIndex(keys.Email), r.keys.PhoneIndex(keys.PhoneE164), keys.LastName)
 	if err != nil {
-		return nil, fmt.Errorf("list customers: %w", err)
+		return nil, fmt.Errorf("find customer matches: %w", err)
 	}
 	defer rows.Close()
 
 	var result []customers.Customer
 	for rows.Next() {
-		var c customers.Customer
-		if err := rows.Scan(
-			&c.ID,
-			&c.ExternalID,
-			&c.FirstName,
-			&c.LastName,
-			&c.Email,
-			&c.Phone,
-			&c.MarketingOpt,
-			&c.CreatedAt,
-			&c.UpdatedAt,
-		); err != nil {
+		c, err := scanCustomer(r.keys, rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan customer: %w", err)
 		}
 		result = append(result, c)
 	}
-
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("rows error: %w", err)
 	}
-
 	return result, nil
 }
+
+// Merge folds mergedID into survivor in a single transaction. Tables holding
+// customer_id references are listed in mergeReparent and must be extended as
+// new customer-owned records (jobs, invoices) are added.
+func (r *CustomerRepository) Merge(survivor customers.Customer, mergedID string) (customers.Merge, error) {
+	if !isUUID(survivor.ID) || !isUUID(mergedID) || survivor.ID == mergedID {
+		return customers.Merge{}, customers.ErrNotFound
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return customers.Merge{}, fmt.Errorf("begin tx: %w", err)
+	}
+	// Rollback after a successful Commit is a no-op.
+	defer tx.Rollback()
+
+	var locked int
+	if err := tx.QueryRow(`
+        SELECT COUNT(*) FROM (
+            SELECT id FROM customers
+             WHERE id IN ($1, $2) AND deleted_at IS NULL
+               FOR UPDATE
+        ) c`, survivor.ID, mergedID).Scan(&locked); err != nil {
+		return customers.Merge{}, fmt.Errorf("lock customers: %w", err)
+	}
+	if locked != 2 {
+		return customers.Merge{}, customers.ErrNotFound
+	}
+
+	sealed, err := sealCustomer(r.keys, survivor)
+	if err != nil {
+		return customers.Merge{}, err
+	}
+	now := timestamp()
+	m := customers.Merge{MergedID: mergedID, SurvivorID: survivor.ID, MergedAt: now}
+	for _, t := range mergeReparent {
+		ids, err := reparent(tx, t, mergedID, survivor.ID, now)
+		if err != nil {
+			return customers.Merge{}, err
+		}
+		switch t {
+		case "vehicles":
+			m.VehicleIDs = ids
+		case "quotes":
+			m.QuoteIDs = ids
+		}
+	}
+	for _, t := range mergeMove {
+		if _, err := tx.Exec(`UPDATE `+t+` SET customer_id = $2 WHERE customer_id = $1`, mergedID, survivor.ID); err != nil {
+			return customers.Merge{}, fmt.Errorf("move %s: %w", t, err)
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM customers WHERE id = $1`, mergedID); err != nil {
+		return customers.Merge{}, fmt.Errorf("delete merged customer: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+        UPDATE customers
+           SET external_id = $2,
+               first_name = $3,
+               last_name = $4,
+               email = $5,
+               phone = $6,
+               phone_e164 = $7,
+               tags = $8::jsonb,
+               updated_at = $9,
+               email_bidx = $10,
+               phone_bidx = $11,
+               account_type = $12,
+               company_name = $13,
+               po_required = $14,
+               billing = $15
+         WHERE id = $1`,
+		survivor.ID,
+		survivor.ExternalID,
+		survivor.FirstName,
+		survivor.LastName,
+		sealed.email,
+		sealed.phone,
+		sealed.phoneE164,
+		tagsArg(survivor.Tags),
+		now,
+		sealed.emailIndex,
+		sealed.phoneIndex,
+		string(survivor.Type),
+		survivor.CompanyName,
+		survivor.PORequired,
+		string(survivor.Billing),
+	); err != nil {
+		if isUniqueViolation(err) {
+			return customers.Merge{}, customers.ErrEmailExists
+		}
+		return customers.Merge{}, fmt.Errorf("update survivor: %w", err)
+	}
+
+	vehicleIDs, _ := json.Marshal(m.VehicleIDs)
+	quoteIDs, _ := json.Marshal(m.QuoteIDs)
+	if _, err := tx.Exec(`
+        INSERT INTO customer_merges (merged_id, survivor_id, vehicle_ids, quote_ids, merged_at)
+        VALUES ($1, $2, $3::jsonb, $4::jsonb, $5)`,
+		mergedID, survivor.ID, string(vehicleIDs), string(quoteIDs), now,
+	); err != nil {
+		return customers.Merge{}, fmt.Errorf("record merge: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return customers.Merge{}, fmt.Errorf("commit merge: %w", err)
+	}
+	return m, nil
+}
+
+// mergeReparent lists the tables whose customer_id is moved by Merge.
+var mergeReparent = []string{"vehicles", "quotes", "customer_addresses", "fleet_contacts"}
+
+// mergeMove lists tables of immutable rows, without updated_at, whose rows
+// Merge moves to the survivor unchanged otherwise.
+var mergeMove = []string{"customer_consents", "customer_notes", "vehicle_recall_marks"}
+
+// reparent moves rows of table from one customer to another and returns
+// their IDs in order.
+func reparent(tx *sql.Tx, table, from, to string, now time.Time) ([]string, error) {
+	rows, err := tx.Query(`UPDATE `+table+` SET customer_id = $2, updated_at = $3 WHERE customer_id = $1 RETURNING id`, from, to, now)
+	if err != nil {
+		return nil, fmt.Errorf("reparent %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("reparent %s: %w", table, err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reparent %s: %w", table, err)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// MergedInto resolves a merged customer ID to its survivor, following
+// chains of merges.
+func (r *CustomerRepository) MergedInto(id string) (string, error) {
+	const query = `
+        WITH RECURSIVE chain (id, depth) AS (
+            SELECT survivor_id, 1 FROM customer_merges WHERE merged_id = $1
+            UNION ALL
+            SELECT m.survivor_id, c.depth + 1
+              FROM customer_merges m
+              JOIN chain c ON m.merged_id = c.id
+             WHERE c.depth < 32
+        )
+        SELECT id FROM chain ORDER BY depth DESC LIMIT 1
+    `
+
+	if !isUUID(id) {
+		return "", customers.ErrNotFound
+	}
+
+	var survivor string
+	if err := r.db.QueryRow(query, id).Scan(&survivor); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", customers.ErrNotFound
+		}
+		return "", fmt.Errorf("resolve merged customer: %w", err)
+	}
+	return survivor, nil
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanCustomer reads a customer row, decrypting its email and phones.
+func scanCustomer(keys *pii.Keyring, row rowScanner) (customers.Customer, error) {
+	var c customers.Customer
+	var deletedAt sql.NullTime
+	err := row.Scan(
+		&c.ID,
+		&c.ExternalID,
+		&c.FirstName,
+		&c.LastName,
+		&c.Email,
+		&c.Phone,
+		&c.PhoneE164,
+		tagsDest(&c.Tags),
+		&c.Type,
+		&c.CompanyName,
+		&c.PORequired,
+		&c.Billing,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+		&deletedAt,
+	)
+	if err != nil {
+		return c, err
+	}
+	if deletedAt.Valid {
+		c.DeletedAt = &deletedAt.Time
+	}
+	return c, openCustomer(keys, &c)
+}
+
+// sealedCustomer is the stored form of a customer's encrypted columns and
+// their blind indexes.
+type sealedCustomer struct {
+	email, phone, phoneE164 string
+	emailIndex, phoneIndex  string
+}
+
+func sealCustomer(keys *pii.Keyring, c customers.Customer) (sealedCustomer, error) {
+	s := sealedCustomer{emailIndex: keys.EmailIndex(c.Email), phoneIndex: keys.PhoneIndex(c.PhoneE164)}
+	var err error
+	if s.email, err = keys.Encrypt(pii.FieldEmail, c.Email); err != nil {
+		return s, fmt.Errorf("encrypt customer: %w", err)
+	}
+	if s.phone, err = keys.Encrypt(pii.FieldPhone, c.Phone); err != nil {
+		return s, fmt.Errorf("encrypt customer: %w", err)
+	}
+	if s.phoneE164, err = keys.Encrypt(pii.FieldPhoneE164, c.PhoneE164); err != nil {
+		return s, fmt.Errorf("encrypt customer: %w", err)
+	}
+	return s, nil
+}
+
+func openCustomer(keys *pii.Keyring, c *customers.Customer) error {
+	var err error
+	if c.Email, err = keys.Decrypt(pii.FieldEmail, c.Email); err != nil {
+		return fmt.Errorf("decrypt customer %s: %w", c.ID, err)
+	}
+	if c.Phone, err = keys.Decrypt(pii.FieldPhone, c.Phone); err != nil {
+		return fmt.Errorf("decrypt customer %s: %w", c.ID, err)
+	}
+	if c.PhoneE164, err = keys.Decrypt(pii.FieldPhoneE164, c.PhoneE164); err != nil {
+		return fmt.Errorf("decrypt customer %s: %w", c.ID, err)
+	}
+	return nil
+}
+
+// searchCustomers adds the customer search filter. The expressions match the
+// trigram indexes from 003_customer_search and 014_fleet_accounts so substring searches stay fast.
+// Encrypted emails and phones can only be matched whole, through their blind
+// indexes.
+func searchCustomers(q *listQuery, terms customers.SearchTerms, keys *pii.Keyring) {
+	if terms.IsZero() {
+		return
+	}
+
+	if terms.Tag != "" {
+		q.filter("tags @> " + q.arg(tagsArg([]string{terms.Tag})) + "::jsonb")
+		return
+	}
+
+	var conds []string
+	if terms.Text != "" {
+		p := q.arg(containsPattern(terms.Text))
+		conds = append(conds, "lower(first_name || ' ' || last_name) LIKE "+p, "lower(company_name) LIKE "+p)
+		if keys.Enabled() {
+			conds = append(conds, "email_bidx = "+q.arg(keys.EmailIndex(terms.Text)))
+		} else {
+			conds = append(conds, "lower(email) LIKE "+p)
+		}
+	}
+	if keys.Enabled() {
+		if terms.PhoneE164 != "" {
+			conds = append(conds, "phone_bidx = "+q.arg(keys.PhoneIndex(terms.PhoneE164)))
+		}
+	} else if terms.Digits != "" {
+		conds = append(conds, "regexp_replace(phone, '[^0-9]', '', 'g') LIKE "+q.arg(containsPattern(terms.Digits)))
+	}
+	if terms.VIN != "" {
+		conds = append(conds, "EXISTS (SELECT 1 FROM vehicles v WHERE v.customer_id = customers.id AND upper(v.vin) LIKE "+
+			q.arg(containsPattern(terms.VIN))+")")
+	}
+	q.filter("(" + strings.Join(conds, " OR ") + ")")
+}
@@ -3,9 +3,12 @@
 package postgres_test
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
+	"github.com/ezmobilemechanic/platform/internal/pii"
 	pgstorage "github.com/ezmobilemechanic/platform/internal/storage/postgres"
 )
 
@@ -28,11 +31,45 @@ func TestCustomerRepositoryIntegration(t *testing.T) {
 		t.Fatalf("expected email %s, got %s", created.Email, fetched.Email)
 	}
 
-	list, err := repo.List(0, 10)
+	list, err := repo.List(listing.Spec{Limit: 10})
 	if err != nil {
 		t.Fatalf("list customers failed: %v", err)
 	}
-	if len(list) == 0 {
+	if len(list.Items) == 0 {
 		t.Fatalf("expected at least one customer in list")
 	}
 }
+
+func TestCustomerReencryptIntegration(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := pgstorage.NewCustomerRepository(db)
+	created, err := repo.Save(customers.Customer{FirstName: "Plain", Email: "plain@example.com", PhoneE164: "+19045550199"})
+	if err != nil {
+		t.Fatalf("save customer failed: %v", err)
+	}
+
+	keys, err := pii.NewKeyring(map[int][]byte{1: bytes.Repeat([]byte{1}, pii.KeySize)}, bytes.Repeat([]byte{2}, pii.KeySize))
+	if err != nil {
+		t.Fatalf("keyring: %v", err)
+	}
+	repo.SetKeyring(keys)
+
+	if n, err := repo.Reencrypt(10); err != nil || n != 1 {
+		t.Fatalf("reencrypt = %d, %v; want 1", n, err)
+	}
+
+	var raw string
+	if err := db.QueryRow(`SELECT email FROM customers WHERE id = $1`, created.ID).Scan(&raw); err != nil {
+		t.Fatalf("raw email: %v", err)
+	}
+	if !pii.IsEncrypted(raw) {
+		t.Fatalf("stored email %q is not encrypted", raw)
+	}
+
+	matches, err := repo.FindMatches(customers.MatchKeys{Email: "PLAIN@example.com", PhoneE164: "+19045550199"})
+	if err != nil || len(matches) != 1 || matches[0].Email != created.Email {
+		t.Fatalf("matches = %+v, %v", matches, err)
+	}
+}
@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ezmobilemechanic/platform/internal/privacy"
+)
+
+// ErasureRequestRepository persists erasure requests in Postgres.
+type ErasureRequestRepository struct {
+	db *sql.DB
+}
+
+// NewErasureRequestRepository constructs the repository.
+func NewErasureRequestRepository(db *sql.DB) *ErasureRequestRepository {
+	return &ErasureRequestRepository{db: db}
+}
+
+const erasureColumns = `id, customer_id, status, requested_by, reason, requested_at, due_at, closed_at, events::text`
+
+// FindByID fetches a request by identifier.
+func (r *ErasureRequestRepository) FindByID(id string) (privacy.ErasureRequest, error) {
+	if !isUUID(id) {
+		return privacy.ErasureRequest{}, privacy.ErrNotFound
+	}
+
+	req, err := scanErasureRequest(r.db.QueryRow(`SELECT `+erasureColumns+` FROM erasure_requests WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return privacy.ErasureRequest{}, privacy.ErrNotFound
+		}
+		return privacy.ErasureRequest{}, fmt.Errorf("find erasure request: %w", err)
+	}
+	return req, nil
+}
+
+// Save inserts a request without an ID and replaces an existing one.
+func (r *ErasureRequestRepository) Save(req privacy.ErasureRequest) (privacy.ErasureRequest, error) {
+	events, err := json.Marshal(req.Events)
+	if err != nil {
+		return privacy.ErasureRequest{}, fmt.Errorf("encode erasure events: %w", err)
+	}
+	var closedAt sql.NullTime
+	if req.ClosedAt != nil {
+		closedAt = sql.NullTime{Time: *req.ClosedAt, Valid: true}
+	}
+
+	if req.ID == "" {
+		const insert = `
+            INSERT INTO erasure_requests (customer_id, status, requested_by, reason, requested_at, due_at, closed_at, events)
+            VALUES ($1,$2,$3,$4,$5,$6,$7,$8::jsonb)
+            RETURNING id
+        `
+		if err := r.db.QueryRow(insert, req.CustomerID, string(req.Status), req.RequestedBy, req.Reason,
+			req.RequestedAt, req.DueAt, closedAt, string(events)).Scan(&req.ID); err != nil {
+			return privacy.ErasureRequest{}, fmt.Errorf("insert erasure request: %w", err)
+		}
+		return req, nil
+	}
+
+	if !isUUID(req.ID) {
+		return privacy.ErasureRequest{}, privacy.ErrNotFound
+	}
+	const update = `
+        UPDATE erasure_requests
+           SET customer_id = $2,
+               status = $3,
+               requested_by = $4,
+               reason = $5,
+               requested_at = $6,
+               due_at = $7,
+               closed_at = $8,
+               events = $9::jsonb
+         WHERE id = $1
+    `
+	res, err := r.db.Exec(update, req.ID, req.CustomerID, string(req.Status), req.RequestedBy, req.Reason,
+		req.RequestedAt, req.DueAt, closedAt, string(events))
+	if err != nil {
+		return privacy.ErasureRequest{}, fmt.Errorf("update erasure request: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return privacy.ErasureRequest{}, fmt.Errorf("update erasure request: %w", err)
+	} else if n == 0 {
+		return privacy.ErasureRequest{}, privacy.ErrNotFound
+	}
+	return req, nil
+}
+
+// List returns requests with the status, or all requests when it is empty,
+// oldest first.
+func (r *ErasureRequestRepository) List(status privacy.Status) ([]privacy.ErasureRequest, error) {
+	return r.query(`SELECT `+erasureColumns+` FROM erasure_requests
+         WHERE $1 = '' OR status = $1
+         ORDER BY requested_at, id`, string(status))
+}
+
+// ListByCustomer returns the customer's requests oldest first.
+func (r *ErasureRequestRepository) ListByCustomer(customerID string) ([]privacy.ErasureRequest, error) {
+	if !isUUID(customerID) {
+		return nil, nil
+	}
+	return r.query(`SELECT `+erasureColumns+` FROM erasure_requests
+         WHERE customer_id = $1
+         ORDER BY requested_at, id`, customerID)
+}
+
+func (r *ErasureRequestRepository) query(query string, args ...any) ([]privacy.ErasureRequest, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list erasure requests: %w", err)
+	}
+	defer rows.Close()
+
+	var result []privacy.ErasureRequest
+	for rows.Next() {
+		req, err := scanErasureRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan erasure request: %w", err)
+		}
+		result = append(result, req)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return result, nil
+}
+
+func scanErasureRequest(row rowScanner) (privacy.ErasureRequest, error) {
+	var (
+		req      privacy.ErasureRequest
+		closedAt sql.NullTime
+		events   string
+	)
+	err := row.Scan(&req.ID, &req.CustomerID, &req.Status, &req.RequestedBy, &req.Reason,
+		&req.RequestedAt, &req.DueAt, &closedAt, &events)
+	if err != nil {
+		return req, err
+	}
+	if closedAt.Valid {
+		t := closedAt.Time.UTC()
+		req.ClosedAt = &t
+	}
+	req.RequestedAt, req.DueAt = req.RequestedAt.UTC(), req.DueAt.UTC()
+	if err := json.Unmarshal([]byte(events), &req.Events); err != nil {
+		return req, fmt.Errorf("decode erasure events: %w", err)
+	}
+	return req, nil
+}
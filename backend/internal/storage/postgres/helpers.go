@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// sqlStater is implemented by driver errors that expose a SQLSTATE code
+// (pgconn.PgError, pq.Error).
+type sqlStater interface {
+	SQLState() string
+}
+
+// isUniqueViolation reports whether err is a Postgres unique_violation.
+func isUniqueViolation(err error) bool {
+	var state sqlStater
+	return errors.As(err, &state) && state.SQLState() == "23505"
+}
+
+// isUUID reports whether id can be parsed as a Postgres UUID. Lookups with
+// anything else are treated as not found instead of surfacing a cast error,
+// matching the memory backend.
+func isUUID(id string) bool {
+	hex := strings.ReplaceAll(strings.TrimSuffix(strings.TrimPrefix(id, "{"), "}"), "-", "")
+	if len(hex) != 32 {
+		return false
+	}
+	for _, r := range hex {
+		switch {
+		case r >= '0' && r <= '9', r >= 'a' && r <= 'f', r >= 'A' && r <= 'F':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// timestamp returns the current time at the precision Postgres stores, so
+// values returned from Save compare equal to those read back later.
+func timestamp() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// containsPattern returns a LIKE pattern matching s anywhere, with LIKE
+// wildcards in s escaped.
+func containsPattern(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// rowQuerier runs queries on a *sql.DB or within a *sql.Tx.
+type rowQuerier interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// nullString maps empty strings to SQL NULL for nullable reference columns.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// nullTime maps a nil time to SQL NULL for nullable timestamp columns.
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+// tagsArg encodes customer tags for the JSON tags column.
+func tagsArg(tags []string) string {
+	if len(tags) == 0 {
+		return "[]"
+	}
+	b, _ := json.Marshal(tags)
+	return string(b)
+}
+
+// tagsDest scans the JSON tags column into t.
+func tagsDest(t *[]string) sql.Scanner {
+	return tagsScanner{t}
+}
+
+type tagsScanner struct {
+	t *[]string
+}
+
+func (s tagsScanner) Scan(src any) error {
+	var raw []byte
+	switch v := src.(type) {
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	default:
+		return fmt.Errorf("scan tags: unsupported type %T", src)
+	}
+	tags := []string{}
+	if err := json.Unmarshal(raw, &tags); err != nil {
+		return fmt.Errorf("scan tags: %w", err)
+	}
+	*s.t = tags
+	return nil
+}
@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
+)
+
+// listQuery assembles a keyset-paginated SELECT that mirrors
+// listing.Paginate: filters first, then the cursor, ordered by the sort column
+// with the primary key as tie-breaker.
+type listQuery struct {
+	where []string
+	args  []any
+}
+
+// arg registers a bind parameter and returns its placeholder.
+func (q *listQuery) arg(v any) string {
+	q.args = append(q.args, v)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+// filter appends a WHERE condition. Placeholders must come from q.arg.
+func (q *listQuery) filter(cond string) {
+	q.where = append(q.where, cond)
+}
+
+// created adds the spec's created range.
+func (q *listQuery) created(column string, spec listing.Spec) {
+	if !spec.CreatedFrom.IsZero() {
+		q.filter(column + " >= " + q.arg(spec.CreatedFrom))
+	}
+	if !spec.CreatedTo.IsZero() {
+		q.filter(column + " < " + q.arg(spec.CreatedTo))
+	}
+}
+
+// search adds a case-insensitive substring match against any of columns.
+func (q *listQuery) search(needle string, columns ...string) {
+	if needle == "" {
+		return
+	}
+	p := q.arg(strings.ToLower(needle))
+	conds := make([]string, len(columns))
+	for i, col := range columns {
+		conds[i] = fmt.Sprintf("strpos(lower(%s), %s) > 0", col, p)
+	}
+	q.filter("(" + strings.Join(conds, " OR ") + ")")
+}
+
+func (q *listQuery) whereSQL() string {
+	if len(q.where) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(q.where, " AND ")
+}
+
+// countSQL returns a COUNT(*) over the filters added so far. Call it before
+// page so the cursor condition is not included.
+func (q *listQuery) countSQL(table string) (string, []any) {
+	args := make([]any, len(q.args))
+	copy(args, q.args)
+	return "SELECT COUNT(*) FROM " + table + q.whereSQL(), args
+}
+
+// page appends the cursor condition, ORDER BY and LIMIT to selectSQL. The
+// LIMIT fetches one extra row so callers can tell whether more pages exist.
+func (q *listQuery) page(selectSQL string, spec listing.Spec) (string, error) {
+	col, id := string(spec.Sort.Field), "id"
+	dir, cmp := "ASC", ">"
+	if spec.Sort.Desc {
+		dir, cmp = "DESC", "<"
+	}
+
+	if spec.Cursor != "" {
+		c, err := listing.DecodeCursor(spec.Cursor, spec.Sort.Field)
+		if err != nil {
+			return "", err
+		}
+		q.filter(fmt.Sprintf("(%s, %s) %s (%s, %s)", col, id, cmp, q.arg(c.Value), q.arg(c.ID)))
+	}
+
+	return fmt.Sprintf("%s%s ORDER BY %s %s, %s %s LIMIT %s",
+		selectSQL, q.whereSQL(), col, dir, id, dir, q.arg(spec.Limit+1)), nil
+}
+
+// cutPage trims the extra row fetched by page and builds the next cursor.
+func cutPage[T any](rows []T, spec listing.Spec, total int, key listing.KeyFunc[T]) listing.Page[T] {
+	page := listing.Page[T]{Items: rows, Total: total}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	if len(rows) > spec.Limit {
+		page.Items = rows[:spec.Limit]
+		page.HasMore = true
+		v, id := key(page.Items[spec.Limit-1], spec.Sort.Field)
+		page.NextCursor = listing.Cursor{Field: spec.Sort.Field, Value: v, ID: id}.Encode()
+	}
+	return page
+}
@@ -0,0 +1,131 @@
+//go:build integration
+
+package postgres_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
+	domainquotes "github.com/ezmobilemechanic/platform/internal/domain/quotes"
+	"github.com/ezmobilemechanic/platform/internal/storage/memory"
+	pgstorage "github.com/ezmobilemechanic/platform/internal/storage/postgres"
+)
+
+// TestListingMatchesMemoryBackend saves the same records into the memory and
+// Postgres repositories and walks every page under each sort, asserting that
+// both backends yield the same sequence.
+func TestListingMatchesMemoryBackend(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	backends := map[string]struct {
+		customers customers.Repository
+		quotes    domainquotes.Repository
+	}{
+		"memory":   {memory.NewCustomerRepository(), memory.NewQuoteRepository()},
+		"postgres": {pgstorage.NewCustomerRepository(db), pgstorage.NewQuoteRepository(db)},
+	}
+
+	customerIDs := make(map[string]string)
+	for name, b := range backends {
+		var ownerID string
+		for i := 0; i < 5; i++ {
+			c, err := b.customers.Save(customers.Customer{
+				FirstName: fmt.Sprintf("Customer%d", i),
+				Email:     fmt.Sprintf("listing%d@example.com", i),
+			})
+			if err != nil {
+				t.Fatalf("%s: save customer: %v", name, err)
+			}
+			if i == 0 {
+				ownerID = c.ID
+			}
+			// Distinct timestamps keep the expected order independent of IDs,
+			// which differ between backends.
+			time.Sleep(2 * time.Millisecond)
+		}
+		customerIDs[name] = ownerID
+
+		for i := 0; i < 5; i++ {
+			status := domainquotes.StatusDraft
+			if i%2 == 0 {
+				status = domainquotes.StatusSent
+			}
+			if _, err := b.quotes.Save(domainquotes.Quote{
+				CustomerID: ownerID,
+				Status:     status,
+				LineItems:  []domainquotes.LineItem{{Description: fmt.Sprintf("Item%d", i), Quantity: 1}},
+			}); err != nil {
+				t.Fatalf("%s: save quote: %v", name, err)
+			}
+			time.Sleep(2 * time.Millisecond)
+		}
+	}
+
+	sorts := []listing.Sort{
+		{Field: listing.SortCreatedAt, Desc: true},
+		{Field: listing.SortCreatedAt},
+		{Field: listing.SortUpdatedAt, Desc: true},
+	}
+
+	for _, sort := range sorts {
+		got := make(map[string][]string)
+		for name, b := range backends {
+			spec := listing.Spec{Limit: 2, Sort: sort}
+			for {
+				page, err := b.customers.List(spec)
+				if err != nil {
+					t.Fatalf("%s: list customers: %v", name, err)
+				}
+				if page.Total != 5 {
+					t.Fatalf("%s: expected total 5, got %d", name, page.Total)
+				}
+				for _, c := range page.Items {
+					got[name] = append(got[name], c.FirstName)
+				}
+				if !page.HasMore {
+					break
+				}
+				spec.Cursor = page.NextCursor
+			}
+		}
+		assertSameSequence(t, "customers "+sort.String(), got)
+	}
+
+	for _, sort := range sorts {
+		got := make(map[string][]string)
+		for name, b := range backends {
+			spec := listing.Spec{Limit: 2, Sort: sort, Status: string(domainquotes.StatusSent)}
+			for {
+				page, err := b.quotes.ListByCustomer(customerIDs[name], spec)
+				if err != nil {
+					t.Fatalf("%s: list quotes: %v", name, err)
+				}
+				for _, q := range page.Items {
+					got[name] = append(got[name], q.LineItems[0].Description)
+				}
+				if !page.HasMore {
+					break
+				}
+				spec.Cursor = page.NextCursor
+			}
+		}
+		assertSameSequence(t, "quotes "+sort.String(), got)
+	}
+}
+
+func assertSameSequence(t *testing.T, label string, got map[string][]string) {
+	t.Helper()
+	mem, pg := got["memory"], got["postgres"]
+	if len(mem) != len(pg) {
+		t.Fatalf("%s: memory returned %v, postgres returned %v", label, mem, pg)
+	}
+	for i := range mem {
+		if mem[i] != pg[i] {
+			t.Fatalf("%s: memory returned %v, postgres returned %v", label, mem, pg)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+)
+
+// OdometerRepository persists odometer readings in Postgres.
+type OdometerRepository struct {
+	db *sql.DB
+}
+
+// NewOdometerRepository constructs the repository.
+func NewOdometerRepository(db *sql.DB) *OdometerRepository {
+	return &OdometerRepository{db: db}
+}
+
+const readingColumns = `id, vehicle_id, mileage, source, read_at, note, flagged, recorded_at`
+
+// ListByVehicle returns the vehicle's readings in date order.
+func (r *OdometerRepository) ListByVehicle(vehicleID string) ([]vehicles.Reading, error) {
+	if !isUUID(vehicleID) {
+		return []vehicles.Reading{}, nil
+	}
+
+	rows, err := r.db.Query(`SELECT `+readingColumns+` FROM odometer_readings
+         WHERE vehicle_id = $1
+         ORDER BY read_at, recorded_at, id`, vehicleID)
+	if err != nil {
+		return nil, fmt.Errorf("list readings: %w", err)
+	}
+	defer rows.Close()
+
+	result := []vehicles.Reading{}
+	for rows.Next() {
+		reading, err := scanReading(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan reading: %w", err)
+		}
+		result = append(result, reading)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return result, nil
+}
+
+// Add inserts a reading.
+func (r *OdometerRepository) Add(reading vehicles.Reading) (vehicles.Reading, error) {
+	return insertReading(r.db, reading, timestamp())
+}
+
+// insertReading stores reading as recorded at now.
+func insertReading(db rowQuerier, reading vehicles.Reading, now time.Time) (vehicles.Reading, error) {
+	const insert = `
+        INSERT INTO odometer_readings (vehicle_id, mileage, source, read_at, note, flagged, recorded_at)
+        VALUES ($1,$2,$3,$4,$5,$6,$7)
+        RETURNING id
+    `
+
+	if err := db.QueryRow(insert,
+		reading.VehicleID,
+		reading.Mileage,
+		string(reading.Source),
+		reading.ReadAt,
+		reading.Note,
+		reading.Flagged,
+		now,
+	).Scan(&reading.ID); err != nil {
+		return vehicles.Reading{}, fmt.Errorf("insert reading: %w", err)
+	}
+	reading.RecordedAt = now
+	return reading, nil
+}
+
+// SetFlagged marks or clears a reading's flag.
+func (r *OdometerRepository) SetFlagged(id string, flagged bool) (vehicles.Reading, error) {
+	if !isUUID(id) {
+		return vehicles.Reading{}, vehicles.ErrReadingNotFound
+	}
+
+	reading, err := scanReading(r.db.QueryRow(`UPDATE odometer_readings SET flagged = $2 WHERE id = $1
+        RETURNING `+readingColumns, id, flagged))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return vehicles.Reading{}, vehicles.ErrReadingNotFound
+		}
+		return vehicles.Reading{}, fmt.Errorf("flag reading: %w", err)
+	}
+	return reading, nil
+}
+
+func scanReading(row rowScanner) (vehicles.Reading, error) {
+	var reading vehicles.Reading
+	err := row.Scan(
+		&reading.ID,
+		&reading.VehicleID,
+		&reading.Mileage,
+		&reading.Source,
+		&reading.ReadAt,
+		&reading.Note,
+		&reading.Flagged,
+		&reading.RecordedAt,
+	)
+	reading.ReadAt = reading.ReadAt.UTC()
+	reading.RecordedAt = reading.RecordedAt.UTC()
+	return reading, err
+}
@@ -41,6 +41,8 @@ func cleanupTables(t *testing.T, db *sql.DB) {
 		"TRUNCATE quotes CASCADE",
 		"TRUNCATE vehicles CASCADE",
 		"TRUNCATE customers CASCADE",
+		"TRUNCATE users CASCADE",
+		"TRUNCATE erasure_requests",
 	}
 	for _, stmt := range stmts {
 		if _, err := db.Exec(stmt); err != nil {
@@ -4,8 +4,10 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
 	"github.com/ezmobilemechanic/platform/internal/domain/quotes"
 )
 
@@ -19,24 +21,15 @@ func NewQuoteRepository(db *sql.DB) *QuoteRepository {
 	return &QuoteRepository{db: db}
 }
 
+const quoteColumns = `id, customer_id, vehicle_id, status, total_amount, technician_notes, created_at, updated_at, po_number, contact_id, parts_amount, labor_amount, fees_amount, discount_amount, tax_amount, technician_tier`
+
 // FindByID retrieves a quote and its line items.
 func (r *QuoteRepository) FindByID(id string) (quotes.Quote, error) {
-	const query = `
-        SELECT id, customer_id, vehicle_id, status, total_amount, created_at, updated_at
-          FROM quotes
-         WHERE id = $1
-    `
+	if !isUUID(id) {
+		return quotes.Quote{}, quotes.ErrNotFound
+	}
 
-	var q quotes.Quote
-	err := r.db.QueryRow(query, id).Scan(
-		&q.ID,
-		&q.CustomerID,
-		&q.VehicleID,
-		&q.Status,
-		&q.TotalAmount,
-		&q.CreatedAt,
-		&q.UpdatedAt,
-	)
+	q, err := scanQuote(r.db.QueryRow(`SELECT `+quoteColumns+` FROM quotes WHERE id = $1`, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return quotes.Quote{}, quotes.ErrNotFound
@@ -55,7 +48,7 @@ func (r *QuoteRepository) FindByID(id string) (quotes.Quote, error) {
 
 func (r *QuoteRepository) fetchLineItems(quoteID string) ([]quotes.LineItem, error) {
 	const query = `
-        SELECT id, description, quantity, unit_price, labor_hours, sort_order
+        SELECT id, description, quantity, unit_price, labor_hours, sort_order, category, labor_rate, labor_amount
           FROM quote_line_items
          WHERE quote_id = $1
          ORDER BY sort_order
@@ -78,6 +71,9 @@ func (r *QuoteRepository) fetchLineItems(quoteID string) ([]quotes.LineItem, err
 			&item.UnitPrice,
 			&item.LaborHours,
 			&item.SortOrder,
+			&item.Category,
+			&item.LaborRate,
+			&item.LaborAmount,
 		); err != nil {
 			return nil, fmt.Errorf("scan quote line item: %w", err)
 		}
@@ -97,20 +93,29 @@ func (r *QuoteRepository) Save(q quotes.Quote) (quotes.Quote, error) {
 		return quotes.Quote{}, fmt.Errorf("begin tx: %w", err)
 	}
 
-	now := time.Now().UTC()
+	now := timestamp()
 	if q.ID == "" {
 		const insert = `
-            INSERT INTO quotes (customer_id, vehicle_id, status, total_amount, created_at, updated_at)
-            VALUES ($1,$2,$3,$4,$5,$6)
+            INSERT INTO quotes (customer_id, vehicle_id, status, total_amount, technician_notes, created_at, updated_at, po_number, contact_id,
+                                parts_amount, labor_amount, fees_amount, discount_amount, tax_amount, technician_tier)
+            VALUES ($1,$2,$3,$4,$5,$6,$6,$7,$8,$9,$10,$11,$12,$13,$14)
             RETURNING id
         `
 		if err := tx.QueryRow(insert,
 			q.CustomerID,
-			q.VehicleID,
+			nullString(q.VehicleID),
 			q.Status,
 			q.TotalAmount,
+			q.TechnicianNotes,
 			now,
-			now,
+			q.PONumber,
+			q.ContactID,
+			q.PartsAmount,
+			q.LaborAmount,
+			q.FeesAmount,
+			q.DiscountAmount,
+			q.TaxAmount,
+			q.TechnicianTier,
 		).Scan(&q.ID); err != nil {
 			tx.Rollback()
 			return quotes.Quote{}, fmt.Errorf("insert quote: %w", err)
@@ -124,18 +129,40 @@ func (r *QuoteRepository) Save(q quotes.Quote) (quotes.Quote, error) {
                    vehicle_id = $3,
                    status = $4,
                    total_amount = $5,
-                   updated_at = $6
+                   technician_notes = $6,
+                   updated_at = $7,
+                   po_number = $8,
+                   contact_id = $9,
+                   parts_amount = $10,
+                   labor_amount = $11,
+                   fees_amount = $12,
+                   discount_amount = $13,
+                   tax_amount = $14,
+                   technician_tier = $15
              WHERE id = $1
             RETURNING created_at
         `
+		if !isUUID(q.ID) {
+			tx.Rollback()
+			return quotes.Quote{}, quotes.ErrNotFound
+		}
 		var created time.Time
 		if err := tx.QueryRow(update,
 			q.ID,
 			q.CustomerID,
-			q.VehicleID,
+			nullString(q.VehicleID),
 			q.Status,
 			q.TotalAmount,
+			q.TechnicianNotes,
 			now,
+			q.PONumber,
+			q.ContactID,
+			q.PartsAmount,
+			q.LaborAmount,
+			q.FeesAmount,
+			q.DiscountAmount,
+			q.TaxAmount,
+			q.TechnicianTier,
 		).Scan(&created); err != nil {
 			tx.Rollback()
 			if errors.Is(err, sql.ErrNoRows) {
@@ -152,7 +179,7 @@ func (r *QuoteRepository) Save(q quotes.Quote) (quotes.Quote, error) {
 		}
 	}
 
-	if err := insertLineItems(tx, q); err != nil {
+	if err := insertLineItems(tx, &q); err != nil {
 		tx.Rollback()
 		return quotes.Quote{}, err
 	}
@@ -164,6 +191,91 @@ func (r *QuoteRepository) Save(q quotes.Quote) (quotes.Quote, error) {
 	return q, nil
 }
 
+// UpdateStatus saves q with its new status and appends change to the status
+// history in one transaction, provided the stored status is still
+// change.From. A change that keeps the status records no history.
+func (r *QuoteRepository) UpdateStatus(q quotes.Quote, change quotes.StatusChange) (quotes.Quote, error) {
+	if !isUUID(q.ID) {
+		return quotes.Quote{}, quotes.ErrNotFound
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return quotes.Quote{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	const update = `
+        UPDATE quotes
+           SET customer_id = $2,
+               vehicle_id = $3,
+               status = $4,
+               total_amount = $5,
+               technician_notes = $6,
+               updated_at = $7,
+               po_number = $8,
+               contact_id = $9,
+               parts_amount = $10,
+               labor_amount = $11,
+               fees_amount = $12,
+               discount_amount = $13,
+               tax_amount = $14,
+               technician_tier = $15
+         WHERE id = $1 AND status = $16
+        RETURNING created_at
+    `
+	now := timestamp()
+	var created time.Time
+	err = tx.QueryRow(update,
+		q.ID,
+		q.CustomerID,
+		nullString(q.VehicleID),
+		change.To,
+		q.TotalAmount,
+		q.TechnicianNotes,
+		now,
+		q.PONumber,
+		q.ContactID,
+		q.PartsAmount,
+		q.LaborAmount,
+		q.FeesAmount,
+		q.DiscountAmount,
+		q.TaxAmount,
+		q.TechnicianTier,
+		change.From,
+	).Scan(&created)
+	if errors.Is(err, sql.ErrNoRows) {
+		var exists bool
+		if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM quotes WHERE id = $1)`, q.ID).Scan(&exists); err != nil {
+			return quotes.Quote{}, fmt.Errorf("find quote: %w", err)
+		}
+		if !exists {
+			return quotes.Quote{}, quotes.ErrNotFound
+		}
+		return quotes.Quote{}, quotes.ErrStatusChanged
+	}
+	if err != nil {
+		return quotes.Quote{}, fmt.Errorf("update quote status: %w", err)
+	}
+
+	if change.From != change.To {
+		const insert = `
+            INSERT INTO quote_status_changes (quote_id, from_status, to_status, actor, reason, changed_at)
+            VALUES ($1,$2,$3,$4,$5,$6)
+        `
+		if _, err := tx.Exec(insert, q.ID, string(change.From), string(change.To), change.Actor, change.Reason, now); err != nil {
+			return quotes.Quote{}, fmt.Errorf("insert quote status change: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return quotes.Quote{}, fmt.Errorf("commit quote status: %w", err)
+	}
+	q.Status = change.To
+	q.CreatedAt = created
+	q.UpdatedAt = now
+	return q, nil
+}
+
 func deleteLineItems(tx *sql.Tx, quoteID string) error {
 	if _, err := tx.Exec(`DELETE FROM quote_line_items WHERE quote_id = $1`, quoteID); err != nil {
 		return fmt.Errorf("delete quote line items: %w", err)
@@ -171,13 +283,16 @@ func deleteLineItems(tx *sql.Tx, quoteID string) error {
 	return nil
 }
 
-func insertLineItems(tx *sql.Tx, q quotes.Quote) error {
+// insertLineItems writes the quote's line items, assigning IDs, QuoteID and
+// SortOrder back onto q.
+func insertLineItems(tx *sql.Tx, q *quotes.Quote) error {
 	const insert = `
-        INSERT INTO quote_line_items (id, quote_id, description, quantity, unit_price, labor_hours, sort_order)
-        VALUES ($1,$2,$3,$4,$5,$6,$7)
+        INSERT INTO quote_line_items (id, quote_id, description, quantity, unit_price, labor_hours, sort_order, category, labor_rate, labor_amount)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
     `
 
-	for idx, item := range q.LineItems {
+	for idx := range q.LineItems {
+		item := &q.LineItems[idx]
 		id := item.ID
 		if id == "" {
 			if err := tx.QueryRow(`SELECT gen_random_uuid()`).Scan(&id); err != nil {
@@ -193,55 +308,140 @@ func insertLineItems(tx *sql.Tx, q quotes.Quote) error {
 			item.UnitPrice,
 			item.LaborHours,
 			idx,
+			item.Category,
+			item.LaborRate,
+			item.LaborAmount,
 		); err != nil {
 			return fmt.Errorf("insert quote line item: %w", err)
 		}
+		item.ID = id
+		item.QuoteID = q.ID
+		item.SortOrder = idx
 	}
 
 	return nil
 }
 
-// ListByCustomer returns paginated quotes for a customer.
-func (r *QuoteRepository) ListByCustomer(customerID string, offset, limit int) ([]quotes.Quote, error) {
-	const query = `
-        SELECT id, customer_id, vehicle_id, status, total_amount, created_at, updated_at
-          FROM quotes
-         WHERE customer_id = $1
-         ORDER BY created_at DESC
-         OFFSET $2
-         LIMIT $3
-    `
+// ListByCustomer returns a keyset-paginated page of quotes for a customer.
+func (r *QuoteRepository) ListByCustomer(customerID string, spec listing.Spec) (listing.Page[quotes.Quote], error) {
+	const selectQuotes = `SELECT ` + quoteColumns + ` FROM quotes`
+
+	spec = spec.Normalize()
+	if !isUUID(customerID) {
+		return listing.Page[quotes.Quote]{Items: []quotes.Quote{}}, nil
+	}
 
-	rows, err := r.db.Query(query, customerID, offset, limit)
+	var lq listQuery
+	lq.filter("customer_id = " + lq.arg(customerID))
+	if spec.Status != "" {
+		lq.filter("status = " + lq.arg(spec.Status))
+	}
+	lq.created("created_at", spec)
+	if spec.Search != "" {
+		lq.filter(`EXISTS (SELECT 1 FROM quote_line_items li
+                           WHERE li.quote_id = quotes.id
+                             AND strpos(lower(li.description), ` + lq.arg(strings.ToLower(spec.Search)) + `) > 0)`)
+	}
+
+	countQuery, countArgs := lq.countSQL("quotes")
+	var total int
+	if err := r.db.QueryRow(countQuery, countArgs...).Scan(&total); err != nil {
+		return listing.Page[quotes.Quote]{}, fmt.Errorf("count quotes: %w", err)
+	}
+
+	query, err := lq.page(selectQuotes, spec)
+	if err != nil {
+		return listing.Page[quotes.Quote]{}, err
+	}
+
+	rows, err := r.db.Query(query, lq.args...)
 	if err != nil {
-		return nil, fmt.Errorf("list quotes: %w", err)
+		return listing.Page[quotes.Quote]{}, fmt.Errorf("list quotes: %w", err)
 	}
 	defer rows.Close()
 
 	var result []quotes.Quote
 	for rows.Next() {
-		var q quotes.Quote
-		if err := rows.Scan(
-			&q.ID,
-			&q.CustomerID,
-			&q.VehicleID,
-			&q.Status,
-			&q.TotalAmount,
-			&q.CreatedAt,
-			&q.UpdatedAt,
-		); err != nil {
-			return nil, fmt.Errorf("scan quote: %w", err)
+		q, err := scanQuote(rows)
+		if err != nil {
+			return listing.Page[quotes.Quote]{}, fmt.Errorf("scan quote: %w", err)
 		}
-		items, err := r.fetchLineItems(q.ID)
+		result = append(result, q)
+	}
+	if err := rows.Err(); err != nil {
+		return listing.Page[quotes.Quote]{}, fmt.Errorf("rows err: %w", err)
+	}
+	rows.Close()
+
+	for i := range result {
+		items, err := r.fetchLineItems(result[i].ID)
 		if err != nil {
-			return nil, err
+			return listing.Page[quotes.Quote]{}, err
+		}
+		result[i].LineItems = items
+	}
+
+	return cutPage(result, spec, total, quotes.SortKey), nil
+}
+
+// ListByVehicle returns the vehicle's quotes oldest first.
+func (r *QuoteRepository) ListByVehicle(vehicleID string) ([]quotes.Quote, error) {
+	if !isUUID(vehicleID) {
+		return []quotes.Quote{}, nil
+	}
+
+	rows, err := r.db.Query(`SELECT `+quoteColumns+` FROM quotes
+         WHERE vehicle_id = $1
+         ORDER BY created_at, id`, vehicleID)
+	if err != nil {
+		return nil, fmt.Errorf("list vehicle quotes: %w", err)
+	}
+	defer rows.Close()
+
+	result := []quotes.Quote{}
+	for rows.Next() {
+		q, err := scanQuote(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan quote: %w", err)
 		}
-		q.LineItems = items
 		result = append(result, q)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("rows err: %w", err)
 	}
+	rows.Close()
 
+	for i := range result {
+		items, err := r.fetchLineItems(result[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		result[i].LineItems = items
+	}
 	return result, nil
 }
+
+func scanQuote(row rowScanner) (quotes.Quote, error) {
+	var q quotes.Quote
+	var vehicleID sql.NullString
+	err := row.Scan(
+		&q.ID,
+		&q.CustomerID,
+		&vehicleID,
+		&q.Status,
+		&q.TotalAmount,
+		&q.TechnicianNotes,
+		&q.CreatedAt,
+		&q.UpdatedAt,
+		&q.PONumber,
+		&q.ContactID,
+		&q.PartsAmount,
+		&q.LaborAmount,
+		&q.FeesAmount,
+		&q.DiscountAmount,
+		&q.TaxAmount,
+		&q.TechnicianTier,
+	)
+	q.VehicleID = vehicleID.String
+	return q, err
+}
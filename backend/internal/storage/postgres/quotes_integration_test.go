@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
 	domainquotes "github.com/ezmobilemechanic/platform/internal/domain/quotes"
 	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
 	pgstorage "github.com/ezmobilemechanic/platform/internal/storage/postgres"
@@ -54,11 +55,11 @@ func TestQuoteRepositoryIntegration(t *testing.T) {
 		t.Fatalf("expected 1 line item, got %d", len(fetched.LineItems))
 	}
 
-	list, err := quoteRepo.ListByCustomer(customer.ID, 0, 10)
+	list, err := quoteRepo.ListByCustomer(customer.ID, listing.Spec{Limit: 10})
 	if err != nil {
 		t.Fatalf("list quotes: %v", err)
 	}
-	if len(list) == 0 {
+	if len(list.Items) == 0 {
 		t.Fatalf("expected at least one quote")
 	}
 }
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+)
+
+// RecallMarkRepository persists recall marks in Postgres.
+type RecallMarkRepository struct {
+	db *sql.DB
+}
+
+// NewRecallMarkRepository constructs the repository.
+func NewRecallMarkRepository(db *sql.DB) *RecallMarkRepository {
+	return &RecallMarkRepository{db: db}
+}
+
+const recallMarkColumns = `vehicle_id, bulletin_id, status, note, customer_id, marked_at`
+
+// ListByVehicle returns the vehicle's marks, oldest first.
+func (r *RecallMarkRepository) ListByVehicle(vehicleID string) ([]vehicles.RecallMark, error) {
+	if !isUUID(vehicleID) {
+		return []vehicles.RecallMark{}, nil
+	}
+
+	rows, err := r.db.Query(`SELECT `+recallMarkColumns+` FROM vehicle_recall_marks
+         WHERE vehicle_id = $1
+         ORDER BY marked_at, bulletin_id`, vehicleID)
+	if err != nil {
+		return nil, fmt.Errorf("list recall marks: %w", err)
+	}
+	defer rows.Close()
+
+	result := []vehicles.RecallMark{}
+	for rows.Next() {
+		mark, err := scanRecallMark(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan recall mark: %w", err)
+		}
+		result = append(result, mark)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return result, nil
+}
+
+// Save inserts the mark or replaces the vehicle's mark for the bulletin.
+func (r *RecallMarkRepository) Save(mark vehicles.RecallMark) (vehicles.RecallMark, error) {
+	const upsert = `
+        INSERT INTO vehicle_recall_marks (vehicle_id, bulletin_id, status, note, customer_id, marked_at)
+        VALUES ($1,$2,$3,$4,$5,$6)
+        ON CONFLICT (vehicle_id, bulletin_id) DO UPDATE
+           SET status = excluded.status,
+               note = excluded.note,
+               customer_id = excluded.customer_id,
+               marked_at = excluded.marked_at
+    `
+
+	now := timestamp()
+	if _, err := r.db.Exec(upsert,
+		mark.VehicleID,
+		mark.BulletinID,
+		string(mark.Status),
+		mark.Note,
+		mark.CustomerID,
+		now,
+	); err != nil {
+		return vehicles.RecallMark{}, fmt.Errorf("save recall mark: %w", err)
+	}
+	mark.MarkedAt = now
+	return mark, nil
+}
+
+// Delete removes the vehicle's mark for the bulletin.
+func (r *RecallMarkRepository) Delete(vehicleID, bulletinID string) error {
+	if !isUUID(vehicleID) {
+		return vehicles.ErrRecallMarkNotFound
+	}
+	res, err := r.db.Exec(`DELETE FROM vehicle_recall_marks WHERE vehicle_id = $1 AND bulletin_id = $2`, vehicleID, bulletinID)
+	if err != nil {
+		return fmt.Errorf("delete recall mark: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("delete recall mark: %w", err)
+	} else if n == 0 {
+		return vehicles.ErrRecallMarkNotFound
+	}
+	return nil
+}
+
+func scanRecallMark(row rowScanner) (vehicles.RecallMark, error) {
+	var mark vehicles.RecallMark
+	err := row.Scan(
+		&mark.VehicleID,
+		&mark.BulletinID,
+		&mark.Status,
+		&mark.Note,
+		&mark.CustomerID,
+		&mark.MarkedAt,
+	)
+	return mark, err
+}
@@ -26,6 +26,9 @@ func (r *UserRepository) FindByID(id string) (users.User, error) {
           FROM users
          WHERE id = $1
     `
+	if !isUUID(id) {
+		return users.User{}, users.ErrNotFound
+	}
 	var u users.User
 	err := r.db.QueryRow(query, id).Scan(&u.ID, &u.Email, &u.Name, &u.PasswordHash, &u.PasswordSalt, &u.CreatedAt, &u.UpdatedAt)
 	if err != nil {
@@ -55,7 +58,7 @@ func (r *UserRepository) FindByEmail(email string) (users.User, error) {
 }
 
 func (r *UserRepository) Save(user users.User) (users.User, error) {
-	now := time.Now().UTC()
+	now := timestamp()
 
 	if user.ID == "" {
 		const insert = `
@@ -71,8 +74,12 @@ func (r *UserRepository) Save(user users.User) (users.User, error) {
 			now,
 			now,
 		).Scan(&user.ID); err != nil {
+			if isUniqueViolation(err) {
+				return users.User{}, users.ErrEmailExists
+			}
 			return users.User{}, fmt.Errorf("insert user: %w", err)
 		}
+		user.Email = strings.ToLower(user.Email)
 		user.CreatedAt = now
 		user.UpdatedAt = now
 		return user, nil
@@ -88,6 +95,9 @@ func (r *UserRepository) Save(user users.User) (users.User, error) {
          WHERE id = $1
         RETURNING created_at
     `
+	if !isUUID(user.ID) {
+		return users.User{}, users.ErrNotFound
+	}
 	var created time.Time
 	err := r.db.QueryRow(update,
 		user.ID,
@@ -101,8 +111,12 @@ func (r *UserRepository) Save(user users.User) (users.User, error) {
 		if errors.Is(err, sql.ErrNoRows) {
 			return users.User{}, users.ErrNotFound
 		}
+		if isUniqueViolation(err) {
+			return users.User{}, users.ErrEmailExists
+		}
 		return users.User{}, fmt.Errorf("update user: %w", err)
 	}
+	user.Email = strings.ToLower(user.Email)
 	user.CreatedAt = created
 	user.UpdatedAt = now
 	return user, nil
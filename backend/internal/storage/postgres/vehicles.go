@@ -19,29 +19,16 @@ func NewVehicleRepository(db *sql.DB) *VehicleRepository {
 	return &VehicleRepository{db: db}
 }
 
+const vehicleColumns = `id, customer_id, vin, year, make, model, trim, engine, color, plate, mileage,
+               created_at, updated_at, archived_at, unit_number`
+
 // FindByID fetches a vehicle by identifier.
 func (r *VehicleRepository) FindByID(id string) (vehicles.Vehicle, error) {
-	const query = `
-        SELECT id, customer_id, vin, year, make, model, trim, engine, mileage,
-               created_at, updated_at
-          FROM vehicles
-         WHERE id = $1
-    `
+	if !isUUID(id) {
+		return vehicles.Vehicle{}, vehicles.ErrNotFound
+	}
 
-	var v vehicles.Vehicle
-	err := r.db.QueryRow(query, id).Scan(
-		&v.ID,
-		&v.CustomerID,
-		&v.VIN,
-		&v.Year,
-		&v.Make,
-		&v.Model,
-		&v.Trim,
-		&v.Engine,
-		&v.Mileage,
-		&v.CreatedAt,
-		&v.UpdatedAt,
-	)
+	v, err := scanVehicle(r.db.QueryRow(`SELECT `+vehicleColumns+` FROM vehicles WHERE id = $1`, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return vehicles.Vehicle{}, vehicles.ErrNotFound
@@ -52,17 +39,16 @@ func (r *VehicleRepository) FindByID(id string) (vehicles.Vehicle, error) {
 	return v, nil
 }
 
-// ListByCustomer returns vehicles for a customer ordered by creation.
+// ListByCustomer returns vehicles for a customer ordered by creation,
+// archived ones included.
 func (r *VehicleRepository) ListByCustomer(customerID string) ([]vehicles.Vehicle, error) {
-	const query = `
-        SELECT id, customer_id, vin, year, make, model, trim, engine, mileage,
-               created_at, updated_at
-          FROM vehicles
-         WHERE customer_id = $1
-         ORDER BY created_at
-    `
+	if !isUUID(customerID) {
+		return nil, nil
+	}
 
-	rows, err := r.db.Query(query, customerID)
+	rows, err := r.db.Query(`SELECT `+vehicleColumns+` FROM vehicles
+         WHERE customer_id = $1
+         ORDER BY created_at, id`, customerID)
 	if err != nil {
 		return nil, fmt.Errorf("list vehicles: %w", err)
 	}
@@ -70,20 +56,8 @@ func (r *VehicleRepository) ListByCustomer(customerID string) ([]vehicles.Vehicl
 
 	var result []vehicles.Vehicle
 	for rows.Next() {
-		var v vehicles.Vehicle
-		if err := rows.Scan(
-			&v.ID,
-			&v.CustomerID,
-			&v.VIN,
-			&v.Year,
-			&v.Make,
-			&v.Model,
-			&v.Trim,
-			&v.Engine,
-			&v.Mileage,
-			&v.CreatedAt,
-			&v.UpdatedAt,
-		); err != nil {
+		v, err := scanVehicle(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan vehicle: %w", err)
 		}
 		result = append(result, v)
@@ -98,12 +72,14 @@ func (r *VehicleRepository) ListByCustomer(customerID string) ([]vehicles.Vehicl
 
 // Save inserts or updates vehicle data.
 func (r *VehicleRepository) Save(vehicle vehicles.Vehicle) (vehicles.Vehicle, error) {
-	now := time.Now().UTC()
+	now := timestamp()
+	archivedAt := nullTime(vehicle.ArchivedAt)
 
 	if vehicle.ID == "" {
 		const insert = `
-            INSERT INTO vehicles (customer_id, vin, year, make, model, trim, engine, mileage, created_at, updated_at)
-            VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+            INSERT INTO vehicles (customer_id, vin, year, make, model, trim, engine, color, plate, mileage,
+                                  created_at, updated_at, archived_at, unit_number)
+            VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$11,$12,$13)
             RETURNING id
         `
 		if err := r.db.QueryRow(insert,
@@ -114,9 +90,12 @@ func (r *VehicleRepository) Save(vehicle vehicles.Vehicle) (vehicles.Vehicle, er
 			vehicle.Model,
 			vehicle.Trim,
 			vehicle.Engine,
+			vehicle.Color,
+			vehicle.Plate,
 			vehicle.Mileage,
 			now,
-			now,
+			archivedAt,
+			vehicle.UnitNumber,
 		).Scan(&vehicle.ID); err != nil {
 			return vehicles.Vehicle{}, fmt.Errorf("insert vehicle: %w", err)
 		}
@@ -134,12 +113,20 @@ func (r *VehicleRepository) Save(vehicle vehicles.Vehicle) (vehicles.Vehicle, er
                model = $6,
                trim = $7,
                engine = $8,
-               mileage = $9,
-               updated_at = $10
+               color = $9,
+               plate = $10,
+               mileage = $11,
+               updated_at = $12,
+               archived_at = $13,
+               unit_number = $14
          WHERE id = $1
         RETURNING created_at
     `
 
+	if !isUUID(vehicle.ID) {
+		return vehicles.Vehicle{}, vehicles.ErrNotFound
+	}
+
 	var created time.Time
 	err := r.db.QueryRow(update,
 		vehicle.ID,
@@ -150,8 +137,12 @@ func (r *VehicleRepository) Save(vehicle vehicles.Vehicle) (vehicles.Vehicle, er
 		vehicle.Model,
 		vehicle.Trim,
 		vehicle.Engine,
+		vehicle.Color,
+		vehicle.Plate,
 		vehicle.Mileage,
 		now,
+		archivedAt,
+		vehicle.UnitNumber,
 	).Scan(&created)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -163,3 +154,111 @@ func (r *VehicleRepository) Save(vehicle vehicles.Vehicle) (vehicles.Vehicle, er
 	vehicle.UpdatedAt = now
 	return vehicle, nil
 }
+
+// Transfer moves a vehicle to its new owner and records the transfer in one
+// transaction.
+func (r *VehicleRepository) Transfer(t vehicles.Transfer, readings []vehicles.Reading) (vehicles.Transfer, error) {
+	if !isUUID(t.VehicleID) || !isUUID(t.FromCustomerID) || !isUUID(t.ToCustomerID) {
+		return vehicles.Transfer{}, vehicles.ErrNotFound
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return vehicles.Transfer{}, fmt.Errorf("begin transfer: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := timestamp()
+	res, err := tx.Exec(`
+        UPDATE vehicles
+           SET customer_id = $3, unit_number = '', mileage = GREATEST(mileage, $4), updated_at = $5
+         WHERE id = $1 AND customer_id = $2`,
+		t.VehicleID, t.FromCustomerID, t.ToCustomerID, t.Mileage, now)
+	if err != nil {
+		return vehicles.Transfer{}, fmt.Errorf("transfer vehicle: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return vehicles.Transfer{}, fmt.Errorf("transfer vehicle: %w", err)
+	} else if n == 0 {
+		return vehicles.Transfer{}, vehicles.ErrNotFound
+	}
+
+	if err := tx.QueryRow(`
+        INSERT INTO vehicle_transfers (vehicle_id, from_customer_id, to_customer_id, mileage, note, transferred_at)
+        VALUES ($1,$2,$3,$4,$5,$6)
+        RETURNING id`,
+		t.VehicleID, t.FromCustomerID, t.ToCustomerID, t.Mileage, t.Note, now,
+	).Scan(&t.ID); err != nil {
+		return vehicles.Transfer{}, fmt.Errorf("record transfer: %w", err)
+	}
+	for _, reading := range readings {
+		if _, err := insertReading(tx, reading, now); err != nil {
+			return vehicles.Transfer{}, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return vehicles.Transfer{}, fmt.Errorf("commit transfer: %w", err)
+	}
+	t.TransferredAt = now
+	return t, nil
+}
+
+// ListTransfers returns a vehicle's transfers, oldest first.
+func (r *VehicleRepository) ListTransfers(vehicleID string) ([]vehicles.Transfer, error) {
+	if !isUUID(vehicleID) {
+		return nil, nil
+	}
+
+	rows, err := r.db.Query(`
+        SELECT id, vehicle_id, from_customer_id, to_customer_id, mileage, note, transferred_at
+          FROM vehicle_transfers
+         WHERE vehicle_id = $1
+         ORDER BY transferred_at, id`, vehicleID)
+	if err != nil {
+		return nil, fmt.Errorf("list transfers: %w", err)
+	}
+	defer rows.Close()
+
+	result := []vehicles.Transfer{}
+	for rows.Next() {
+		var t vehicles.Transfer
+		if err := rows.Scan(&t.ID, &t.VehicleID, &t.FromCustomerID, &t.ToCustomerID, &t.Mileage, &t.Note, &t.TransferredAt); err != nil {
+			return nil, fmt.Errorf("scan transfer: %w", err)
+		}
+		t.TransferredAt = t.TransferredAt.UTC()
+		result = append(result, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return result, nil
+}
+
+func scanVehicle(row rowScanner) (vehicles.Vehicle, error) {
+	var (
+		v        vehicles.Vehicle
+		archived sql.NullTime
+	)
+	err := row.Scan(
+		&v.ID,
+		&v.CustomerID,
+		&v.VIN,
+		&v.Year,
+		&v.Make,
+		&v.Model,
+		&v.Trim,
+		&v.Engine,
+		&v.Color,
+		&v.Plate,
+		&v.Mileage,
+		&v.CreatedAt,
+		&v.UpdatedAt,
+		&archived,
+		&v.UnitNumber,
+	)
+	if archived.Valid {
+		at := archived.Time.UTC()
+		v.ArchivedAt = &at
+	}
+	return v, err
+}
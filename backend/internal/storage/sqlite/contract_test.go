@@ -0,0 +1,56 @@
+//go:build sqlite
+
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/ezmobilemechanic/platform/internal/storage/sqlite"
+	"github.com/ezmobilemechanic/platform/internal/storage/storagetest"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open(sqlite.DriverName, sqlite.DSN(filepath.Join(t.TempDir(), "test.db")))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	if err := sqlite.NewMigrator(db, nil).Up(context.Background()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func TestRepositoryContract(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storagetest.Backend {
+		db := openTestDB(t)
+		return storagetest.Backend{
+			Customers:    sqlite.NewCustomerRepository(db),
+			Addresses:    sqlite.NewCustomerAddressRepository(db),
+			Consents:     sqlite.NewCustomerConsentRepository(db),
+			Notes:        sqlite.NewCustomerNoteRepository(db),
+			Contacts:     sqlite.NewFleetContactRepository(db),
+			Vehicles:     sqlite.NewVehicleRepository(db),
+			Odometer:     sqlite.NewOdometerRepository(db),
+			Recalls:      sqlite.NewRecallMarkRepository(db),
+			Quotes:       sqlite.NewQuoteRepository(db),
+			QuoteHistory: sqlite.NewQuoteStatusLog(db),
+			Users:        sqlite.NewUserRepository(db),
+			Erasures:     sqlite.NewErasureRequestRepository(db),
+		}
+	})
+}
+
+func TestMigrationsAreIdempotent(t *testing.T) {
+	db := openTestDB(t)
+	if err := sqlite.NewMigrator(db, nil).Up(context.Background()); err != nil {
+		t.Fatalf("re-run migrations: %v", err)
+	}
+}
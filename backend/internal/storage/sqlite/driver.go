@@ -0,0 +1,8 @@
+//go:build sqlite
+
+package sqlite
+
+// The pure-Go driver is only linked with `-tags sqlite`, so the default build
+// stays free of it; binaries built without the tag refuse DATA_BACKEND=sqlite
+// at startup (see CheckDriver).
+import _ "modernc.org/sqlite"
@@ -0,0 +1,258 @@
+package sqlite
+
+import (
+	"fmt"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/pii"
+)
+
+// Reencrypt rewrites customer emails and phones stored in plaintext or under
+// an older key version, and refreshes their blind indexes. Soft-deleted
+// customers are included. Rows are read batchSize at a time in ID order and
+// only updated if unchanged since they were read, so it is safe to run while
+// the API is serving. It returns the number of rows rewritten.
+func (r *CustomerRepository) Reencrypt(batchSize int) (int, error) {
+	const selectBatch = `
+        SELECT id, email, phone, phone_e164, email_bidx, phone_bidx
+          FROM customers
+         WHERE id > ?1
+         ORDER BY id
+         LIMIT ?2
+    `
+	const update = `
+        UPDATE customers
+           SET email = ?2, phone = ?3, phone_e164 = ?4, email_bidx = ?5, phone_bidx = ?6
+         WHERE id = ?1 AND email = ?7 AND phone = ?8 AND phone_e164 = ?9
+    `
+
+	rewritten := 0
+	after := ""
+	for {
+		batch, err := r.reencryptBatch(selectBatch, after, batchSize)
+		if err != nil {
+			return rewritten, err
+		}
+		for _, row := range batch {
+			c := customers.Customer{ID: row.id, Email: row.email, Phone: row.phone, PhoneE164: row.phoneE164}
+			if err := openCustomer(r.keys, &c); err != nil {
+				return rewritten, err
+			}
+			sealed, err := sealCustomer(r.keys, c)
+			if err != nil {
+				return rewritten, err
+			}
+			if r.keys.Current(row.email) && r.keys.Current(row.phone) && r.keys.Current(row.phoneE164) &&
+				row.emailIndex == sealed.emailIndex && row.phoneIndex == sealed.phoneIndex {
+				continue
+			}
+			res, err := r.db.Exec(update, row.id, sealed.email, sealed.phone, sealed.phoneE164, sealed.emailIndex, sealed.phoneIndex,
+				row.email, row.phone, row.phoneE164)
+			if err != nil {
+				return rewritten, fmt.Errorf("reencrypt customer %s: %w", row.id, err)
+			}
+			// A row changed since it was read was written with the current key.
+			if n, err := res.RowsAffected(); err != nil {
+				return rewritten, fmt.Errorf("reencrypt customer %s: %w", row.id, err)
+			} else if n == 1 {
+				rewritten++
+			}
+		}
+		if len(batch) < batchSize {
+			return rewritten, nil
+		}
+		after = batch[len(batch)-1].id
+	}
+}
+
+// storedCustomerPII is a customer's encrypted columns as stored.
+type storedCustomerPII struct {
+	id, email, phone, phoneE164 string
+	emailIndex, phoneIndex      string
+}
+
+func (r *CustomerRepository) reencryptBatch(query, after string, limit int) ([]storedCustomerPII, error) {
+	rows, err := r.db.Query(query, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("reencrypt customers: %w", err)
+	}
+	defer rows.Close()
+
+	var batch []storedCustomerPII
+	for rows.Next() {
+		var s storedCustomerPII
+		if err := rows.Scan(&s.id, &s.email, &s.phone, &s.phoneE164, &s.emailIndex, &s.phoneIndex); err != nil {
+			return nil, fmt.Errorf("reencrypt customers: %w", err)
+		}
+		batch = append(batch, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reencrypt customers: %w", err)
+	}
+	return batch, nil
+}
+
+// Reencrypt rewrites address street lines stored in plaintext or under an
+// older key version, like CustomerRepository.Reencrypt.
+func (r *CustomerAddressRepository) Reencrypt(batchSize int) (int, error) {
+	const selectBatch = `
+        SELECT id, line1, line2
+          FROM customer_addresses
+         WHERE id > ?1
+         ORDER BY id
+         LIMIT ?2
+    `
+	const update = `
+        UPDATE customer_addresses
+           SET line1 = ?2, line2 = ?3
+         WHERE id = ?1 AND line1 = ?4 AND line2 = ?5
+    `
+
+	rewritten := 0
+	after := ""
+	for {
+		batch, err := r.reencryptBatch(selectBatch, after, batchSize)
+		if err != nil {
+			return rewritten, err
+		}
+		for _, row := range batch {
+			if r.keys.Current(row.line1) && r.keys.Current(row.line2) {
+				continue
+			}
+			a := customers.Address{ID: row.id}
+			if a.Line1, err = r.keys.Decrypt(pii.FieldAddressLine1, row.line1); err != nil {
+				return rewritten, fmt.Errorf("decrypt address %s: %w", row.id, err)
+			}
+			if a.Line2, err = r.keys.Decrypt(pii.FieldAddressLine2, row.line2); err != nil {
+				return rewritten, fmt.Errorf("decrypt address %s: %w", row.id, err)
+			}
+			line1, line2, err := sealAddress(r.keys, a)
+			if err != nil {
+				return rewritten, err
+			}
+			res, err := r.db.Exec(update, row.id, line1, line2, row.line1, row.line2)
+			if err != nil {
+				return rewritten, fmt.Errorf("reencrypt address %s: %w", row.id, err)
+			}
+			if n, err := res.RowsAffected(); err != nil {
+				return rewritten, fmt.Errorf("reencrypt address %s: %w", row.id, err)
+			} else if n == 1 {
+				rewritten++
+			}
+		}
+		if len(batch) < batchSize {
+			return rewritten, nil
+		}
+		after = batch[len(batch)-1].id
+	}
+}
+
+// storedAddressLines is an address's encrypted columns as stored.
+type storedAddressLines struct {
+	id, line1, line2 string
+}
+
+func (r *CustomerAddressRepository) reencryptBatch(query, after string, limit int) ([]storedAddressLines, error) {
+	rows, err := r.db.Query(query, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("reencrypt addresses: %w", err)
+	}
+	defer rows.Close()
+
+	var batch []storedAddressLines
+	for rows.Next() {
+		var s storedAddressLines
+		if err := rows.Scan(&s.id, &s.line1, &s.line2); err != nil {
+			return nil, fmt.Errorf("reencrypt addresses: %w", err)
+		}
+		batch = append(batch, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reencrypt addresses: %w", err)
+	}
+	return batch, nil
+}
+
+// Reencrypt rewrites contact emails and phones stored in plaintext or under
+// an older key version, like CustomerRepository.Reencrypt.
+func (r *FleetContactRepository) Reencrypt(batchSize int) (int, error) {
+	const selectBatch = `
+        SELECT id, email, phone, phone_e164
+          FROM fleet_contacts
+         WHERE id > ?1
+         ORDER BY id
+         LIMIT ?2
+    `
+	const update = `
+        UPDATE fleet_contacts
+           SET email = ?2, phone = ?3, phone_e164 = ?4
+         WHERE id = ?1 AND email = ?5 AND phone = ?6 AND phone_e164 = ?7
+    `
+
+	rewritten := 0
+	after := ""
+	for {
+		batch, err := r.reencryptBatch(selectBatch, after, batchSize)
+		if err != nil {
+			return rewritten, err
+		}
+		for _, row := range batch {
+			if r.keys.Current(row.email) && r.keys.Current(row.phone) && r.keys.Current(row.phoneE164) {
+				continue
+			}
+			c := customers.Contact{ID: row.id}
+			if c.Email, err = r.keys.Decrypt(pii.FieldContactEmail, row.email); err != nil {
+				return rewritten, fmt.Errorf("decrypt contact %s: %w", row.id, err)
+			}
+			if c.Phone, err = r.keys.Decrypt(pii.FieldContactPhone, row.phone); err != nil {
+				return rewritten, fmt.Errorf("decrypt contact %s: %w", row.id, err)
+			}
+			if c.PhoneE164, err = r.keys.Decrypt(pii.FieldContactE164, row.phoneE164); err != nil {
+				return rewritten, fmt.Errorf("decrypt contact %s: %w", row.id, err)
+			}
+			email, phone, e164, err := sealContact(r.keys, c)
+			if err != nil {
+				return rewritten, err
+			}
+			res, err := r.db.Exec(update, row.id, email, phone, e164, row.email, row.phone, row.phoneE164)
+			if err != nil {
+				return rewritten, fmt.Errorf("reencrypt contact %s: %w", row.id, err)
+			}
+			if n, err := res.RowsAffected(); err != nil {
+				return rewritten, fmt.Errorf("reencrypt contact %s: %w", row.id, err)
+			} else if n == 1 {
+				rewritten++
+			}
+		}
+		if len(batch) < batchSize {
+			return rewritten, nil
+		}
+		after = batch[len(batch)-1].id
+	}
+}
+
+// storedContactPII is a contact's encrypted columns as stored.
+type storedContactPII struct {
+	id, email, phone, phoneE164 string
+}
+
+func (r *FleetContactRepository) reencryptBatch(query, after string, limit int) ([]storedContactPII, error) {
+	rows, err := r.db.Query(query, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("reencrypt contacts: %w", err)
+	}
+	defer rows.Close()
+
+	var batch []storedContactPII
+	for rows.Next() {
+		var s storedContactPII
+		if err := rows.Scan(&s.id, &s.email, &s.phone, &s.phoneE164); err != nil {
+			return nil, fmt.Errorf("reencrypt contacts: %w", err)
+		}
+		batch = append(batch, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reencrypt contacts: %w", err)
+	}
+	return batch, nil
+}
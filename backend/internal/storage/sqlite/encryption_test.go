@@ -0,0 +1,145 @@
+//go:build sqlite
+
+package sqlite_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
+	"github.com/ezmobilemechanic/platform/internal/pii"
+	"github.com/ezmobilemechanic/platform/internal/storage/sqlite"
+)
+
+func testKeyring(t *testing.T, versions ...int) *pii.Keyring {
+	t.Helper()
+	keys := map[int][]byte{}
+	for _, v := range versions {
+		keys[v] = bytes.Repeat([]byte{byte(v)}, pii.KeySize)
+	}
+	k, err := pii.NewKeyring(keys, bytes.Repeat([]byte{0xAA}, pii.KeySize))
+	if err != nil {
+		t.Fatalf("keyring: %v", err)
+	}
+	return k
+}
+
+func TestReencrypt(t *testing.T) {
+	db := openTestDB(t)
+	repo := sqlite.NewCustomerRepository(db)
+	addresses := sqlite.NewCustomerAddressRepository(db)
+
+	// Written before encryption was enabled.
+	c, err := repo.Save(customers.Customer{
+		FirstName: "Alex", LastName: "Driver",
+		Email: "Alex@Example.com", Phone: "904-555-0101", PhoneE164: "+19045550101",
+	})
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	a, err := addresses.Save(customers.Address{CustomerID: c.ID, Label: "Home", Line1: "1 Main St", City: "Jacksonville"})
+	if err != nil {
+		t.Fatalf("save address: %v", err)
+	}
+
+	rawColumns := func() (email, phone, line1 string) {
+		t.Helper()
+		if err := db.QueryRow(`SELECT email, phone FROM customers WHERE id = ?1`, c.ID).Scan(&email, &phone); err != nil {
+			t.Fatalf("raw customer: %v", err)
+		}
+		if err := db.QueryRow(`SELECT line1 FROM customer_addresses WHERE id = ?1`, a.ID).Scan(&line1); err != nil {
+			t.Fatalf("raw address: %v", err)
+		}
+		return email, phone, line1
+	}
+
+	for _, version := range []int{1, 2} {
+		keys := testKeyring(t, 1, version)
+		repo.SetKeyring(keys)
+		addresses.SetKeyring(keys)
+
+		if n, err := repo.Reencrypt(1); err != nil || n != 1 {
+			t.Fatalf("v%d: reencrypt customers = %d, %v; want 1", version, n, err)
+		}
+		if n, err := addresses.Reencrypt(1); err != nil || n != 1 {
+			t.Fatalf("v%d: reencrypt addresses = %d, %v; want 1", version, n, err)
+		}
+		if n, err := repo.Reencrypt(1); err != nil || n != 0 {
+			t.Fatalf("v%d: second run rewrote %d, %v; want 0", version, n, err)
+		}
+
+		prefix := fmt.Sprintf("enc:v%d:", version)
+		email, phone, line1 := rawColumns()
+		for _, raw := range []string{email, phone, line1} {
+			if !strings.HasPrefix(raw, prefix) {
+				t.Fatalf("v%d: stored %q, want %s prefix", version, raw, prefix)
+			}
+		}
+
+		got, err := repo.FindByID(c.ID)
+		if err != nil || got.Email != c.Email || got.Phone != c.Phone || got.PhoneE164 != c.PhoneE164 {
+			t.Fatalf("v%d: FindByID = %+v, %v", version, got, err)
+		}
+		gotAddr, err := addresses.FindByID(a.ID)
+		if err != nil || gotAddr.Line1 != a.Line1 {
+			t.Fatalf("v%d: address = %+v, %v", version, gotAddr, err)
+		}
+
+		matches, err := repo.FindMatches(customers.MatchKeys{Email: "alex@example.com"})
+		if err != nil || len(matches) != 1 || matches[0].ID != c.ID {
+			t.Fatalf("v%d: match by email = %+v, %v", version, matches, err)
+		}
+		matches, err = repo.FindMatches(customers.MatchKeys{PhoneE164: "+19045550101"})
+		if err != nil || len(matches) != 1 {
+			t.Fatalf("v%d: match by phone = %+v, %v", version, matches, err)
+		}
+
+		for _, q := range []string{"ALEX@example.com", "(904) 555-0101", "driver"} {
+			page, err := repo.List(listing.Spec{Search: q})
+			if err != nil || len(page.Items) != 1 {
+				t.Fatalf("v%d: search %q = %+v, %v", version, q, page.Items, err)
+			}
+		}
+	}
+
+	// Values sealed with a retired key no longer open.
+	repo.SetKeyring(testKeyring(t, 3))
+	if _, err := repo.FindByID(c.ID); err == nil {
+		t.Fatal("read with unknown key version succeeded")
+	}
+}
+
+func TestEncryptedEmailStaysUnique(t *testing.T) {
+	db := openTestDB(t)
+	repo := sqlite.NewCustomerRepository(db)
+	repo.SetKeyring(testKeyring(t, 1))
+
+	if _, err := repo.Save(customers.Customer{FirstName: "A", LastName: "B", Email: "dup@example.com"}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if _, err := repo.Save(customers.Customer{FirstName: "C", LastName: "D", Email: "DUP@example.com"}); !errors.Is(err, customers.ErrEmailExists) {
+		t.Fatalf("duplicate email: err = %v, want ErrEmailExists", err)
+	}
+}
+
+func TestEncryptedPhoneSearchUsesRegion(t *testing.T) {
+	db := openTestDB(t)
+	repo := sqlite.NewCustomerRepository(db)
+	repo.SetKeyring(testKeyring(t, 1))
+	repo.SetPhoneRegion("GB")
+
+	c, err := repo.Save(customers.Customer{FirstName: "Sam", LastName: "Fitter", Phone: "01632 960000", PhoneE164: "+441632960000"})
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	for _, q := range []string{"01632 960000", "+44 1632 960000"} {
+		page, err := repo.List(listing.Spec{Search: q})
+		if err != nil || len(page.Items) != 1 || page.Items[0].ID != c.ID {
+			t.Fatalf("search %q = %+v, %v", q, page.Items, err)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package sqlite
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// timeLayout is fixed-width so TEXT comparison orders chronologically.
+const timeLayout = "2006-01-02T15:04:05.000000Z"
+
+// timestamp returns the current time at the precision stored, so values
+// returned from Save compare equal to those read back later.
+func timestamp() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// timeDest scans a stored timestamp into t.
+func timeDest(t *time.Time) sql.Scanner {
+	return timeScanner{t}
+}
+
+// nullTimeDest scans a nullable timestamp, leaving *t nil for NULL.
+func nullTimeDest(t **time.Time) sql.Scanner {
+	return nullTimeScanner{t}
+}
+
+type nullTimeScanner struct {
+	t **time.Time
+}
+
+func (s nullTimeScanner) Scan(src any) error {
+	if src == nil {
+		*s.t = nil
+		return nil
+	}
+	var v time.Time
+	if err := (timeScanner{&v}).Scan(src); err != nil {
+		return err
+	}
+	*s.t = &v
+	return nil
+}
+
+type timeScanner struct {
+	t *time.Time
+}
+
+func (s timeScanner) Scan(src any) error {
+	switch v := src.(type) {
+	case time.Time:
+		*s.t = v.UTC()
+		return nil
+	case string:
+		return s.parse(v)
+	case []byte:
+		return s.parse(string(v))
+	default:
+		return fmt.Errorf("cannot scan %T into time", src)
+	}
+}
+
+func (s timeScanner) parse(v string) error {
+	t, err := time.Parse(time.RFC3339Nano, v)
+	if err != nil {
+		return err
+	}
+	*s.t = t.UTC()
+	return nil
+}
+
+// newID returns a random (version 4) UUID, matching the identifiers Postgres
+// issues with gen_random_uuid().
+func newID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// isUniqueViolation reports whether err is a SQLite UNIQUE constraint
+// failure. Drivers differ in their error types, but not in the message.
+func isUniqueViolation(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
+// execer runs statements on a *sql.DB or within a *sql.Tx.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// nullString maps empty strings to SQL NULL for nullable reference columns.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// nullTime formats a nullable timestamp, mapping nil to SQL NULL.
+func nullTime(t *time.Time) sql.NullString {
+	if t == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: formatTime(*t), Valid: true}
+}
+
+// tagsArg encodes customer tags for the JSON tags column.
+func tagsArg(tags []string) string {
+	if len(tags) == 0 {
+		return "[]"
+	}
+	b, _ := json.Marshal(tags)
+	return string(b)
+}
+
+// tagsDest scans the JSON tags column into t.
+func tagsDest(t *[]string) sql.Scanner {
+	return tagsScanner{t}
+}
+
+type tagsScanner struct {
+	t *[]string
+}
+
+func (s tagsScanner) Scan(src any) error {
+	var raw []byte
+	switch v := src.(type) {
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	default:
+		return fmt.Errorf("scan tags: unsupported type %T", src)
+	}
+	tags := []string{}
+	if err := json.Unmarshal(raw, &tags); err != nil {
+		return fmt.Errorf("scan tags: %w", err)
+	}
+	*s.t = tags
+	return nil
+}
@@ -0,0 +1,75 @@
+-- SQLite schema mirroring db/migrations/001_init_schema.up.sql.
+-- IDs are UUID strings generated by the application and timestamps are UTC
+-- text in a fixed-width layout so they sort chronologically.
+
+-- Users for authentication
+CREATE TABLE IF NOT EXISTS users (
+    id TEXT PRIMARY KEY,
+    email TEXT NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    password_hash TEXT NOT NULL,
+    password_salt TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_idx ON users (lower(email));
+
+-- Customers store core account/contact info
+CREATE TABLE IF NOT EXISTS customers (
+    id TEXT PRIMARY KEY,
+    external_id TEXT,
+    first_name TEXT NOT NULL DEFAULT '',
+    last_name  TEXT NOT NULL DEFAULT '',
+    email      TEXT NOT NULL DEFAULT '',
+    phone      TEXT NOT NULL DEFAULT '',
+    marketing_opt INTEGER NOT NULL DEFAULT 0,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS customers_email_idx ON customers (lower(email)) WHERE email <> '';
+CREATE INDEX IF NOT EXISTS customers_created_idx ON customers (created_at, id);
+
+-- Vehicles owned by customers
+CREATE TABLE IF NOT EXISTS vehicles (
+    id TEXT PRIMARY KEY,
+    customer_id TEXT NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    vin TEXT NOT NULL DEFAULT '',
+    year INTEGER,
+    make TEXT NOT NULL DEFAULT '',
+    model TEXT NOT NULL DEFAULT '',
+    trim TEXT NOT NULL DEFAULT '',
+    engine TEXT NOT NULL DEFAULT '',
+    mileage INTEGER NOT NULL DEFAULT 0,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS vehicles_customer_idx ON vehicles (customer_id);
+
+-- Quotes capture pricing proposals
+CREATE TABLE IF NOT EXISTS quotes (
+    id TEXT PRIMARY KEY,
+    customer_id TEXT NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    vehicle_id TEXT REFERENCES vehicles(id) ON DELETE SET NULL,
+    status TEXT NOT NULL DEFAULT 'draft',
+    total_amount INTEGER NOT NULL DEFAULT 0,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS quotes_customer_idx ON quotes (customer_id, created_at DESC);
+
+-- Line items for quotes
+CREATE TABLE IF NOT EXISTS quote_line_items (
+    id TEXT PRIMARY KEY,
+    quote_id TEXT NOT NULL REFERENCES quotes(id) ON DELETE CASCADE,
+    description TEXT NOT NULL,
+    quantity INTEGER NOT NULL DEFAULT 1,
+    unit_price INTEGER NOT NULL DEFAULT 0,
+    labor_hours REAL NOT NULL DEFAULT 0,
+    sort_order INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS quote_line_items_quote_idx ON quote_line_items (quote_id, sort_order);
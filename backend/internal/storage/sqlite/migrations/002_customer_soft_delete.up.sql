@@ -0,0 +1,8 @@
+-- Soft deletes for customers: deleted rows keep their history until purged
+ALTER TABLE customers ADD COLUMN deleted_at TEXT;
+
+-- Emails only need to be unique among active customers
+DROP INDEX IF EXISTS customers_email_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS customers_active_email_idx ON customers (lower(email)) WHERE email <> '' AND deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS customers_deleted_idx ON customers (deleted_at) WHERE deleted_at IS NOT NULL;
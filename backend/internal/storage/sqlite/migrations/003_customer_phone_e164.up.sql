@@ -0,0 +1,18 @@
+-- Normalized (E.164) customer phone numbers, mirroring
+-- db/migrations/004_customer_phone_e164.up.sql.
+ALTER TABLE customers ADD COLUMN phone_e164 TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS customers_phone_e164_idx ON customers (phone_e164) WHERE phone_e164 <> '';
+
+-- Backfill valid North American numbers. SQLite has no regexp_replace, so
+-- common punctuation is stripped and the digits matched with GLOB.
+UPDATE customers
+   SET phone_e164 = '+1' || substr(d.digits, -10),
+       phone = '(' || substr(d.digits, -10, 3) || ') ' || substr(d.digits, -7, 3) || '-' || substr(d.digits, -4)
+  FROM (SELECT id,
+               replace(replace(replace(replace(replace(replace(phone, ' ', ''), '-', ''), '(', ''), ')', ''), '.', ''), '+', '') AS digits
+          FROM customers) AS d
+ WHERE customers.id = d.id
+   AND customers.phone_e164 = ''
+   AND (d.digits GLOB '[2-9][0-9][0-9][2-9][0-9][0-9][0-9][0-9][0-9][0-9]'
+        OR d.digits GLOB '1[2-9][0-9][0-9][2-9][0-9][0-9][0-9][0-9][0-9][0-9]');
@@ -0,0 +1,11 @@
+-- Audit trail of customer merges, mirroring
+-- db/migrations/005_customer_merges.up.sql. The ID lists are JSON arrays.
+CREATE TABLE IF NOT EXISTS customer_merges (
+    merged_id TEXT PRIMARY KEY,
+    survivor_id TEXT NOT NULL,
+    vehicle_ids TEXT NOT NULL DEFAULT '[]',
+    quote_ids TEXT NOT NULL DEFAULT '[]',
+    merged_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS customer_merges_survivor_idx ON customer_merges (survivor_id);
@@ -0,0 +1,28 @@
+-- Append-only TCPA consent ledger, mirroring
+-- db/migrations/007_customer_consents.up.sql. Ties on recorded_at are broken
+-- by rowid.
+CREATE TABLE IF NOT EXISTS customer_consents (
+    id TEXT PRIMARY KEY,
+    customer_id TEXT NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    channel TEXT NOT NULL,
+    purpose TEXT NOT NULL,
+    granted INTEGER NOT NULL,
+    source TEXT NOT NULL,
+    detail TEXT NOT NULL DEFAULT '',
+    ip_address TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    recorded_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS customer_consents_customer_idx ON customer_consents (customer_id, recorded_at);
+
+-- customers.marketing_opt is no longer written. Existing opt-ins become email
+-- marketing consent only, since the flag never recorded a channel. IDs are
+-- random version 4 UUIDs like those from newID.
+INSERT INTO customer_consents (id, customer_id, channel, purpose, granted, source, detail, recorded_at)
+SELECT lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' ||
+       substr(lower(hex(randomblob(2))), 2) || '-' || substr('89ab', 1 + abs(random()) % 4, 1) ||
+       substr(lower(hex(randomblob(2))), 2) || '-' || lower(hex(randomblob(6))),
+       id, 'email', 'marketing', 1, 'migration', 'customers.marketing_opt', updated_at
+  FROM customers
+ WHERE marketing_opt = 1;
@@ -0,0 +1,25 @@
+-- Customer tags, notes and quote status changes, mirroring
+-- db/migrations/008_customer_notes_tags.up.sql. Tags are a JSON array
+-- searched with json_each, without an index.
+ALTER TABLE customers ADD COLUMN tags TEXT NOT NULL DEFAULT '[]';
+
+CREATE TABLE IF NOT EXISTS customer_notes (
+    id TEXT PRIMARY KEY,
+    customer_id TEXT NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL DEFAULT 'note',
+    body TEXT NOT NULL,
+    author TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS customer_notes_customer_idx ON customer_notes (customer_id, created_at);
+
+CREATE TABLE IF NOT EXISTS quote_status_changes (
+    id TEXT PRIMARY KEY,
+    quote_id TEXT NOT NULL REFERENCES quotes(id) ON DELETE CASCADE,
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    changed_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS quote_status_changes_quote_idx ON quote_status_changes (quote_id, changed_at);
@@ -0,0 +1,16 @@
+-- Right-to-erasure requests, mirroring db/migrations/009_erasure_requests.up.sql.
+-- customer_id has no foreign key so requests outlive purged customers.
+CREATE TABLE IF NOT EXISTS erasure_requests (
+    id TEXT PRIMARY KEY,
+    customer_id TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    requested_by TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    requested_at TEXT NOT NULL,
+    due_at TEXT NOT NULL,
+    closed_at TEXT,
+    events TEXT NOT NULL DEFAULT '[]'
+);
+
+CREATE INDEX IF NOT EXISTS erasure_requests_customer_idx ON erasure_requests (customer_id, requested_at);
+CREATE INDEX IF NOT EXISTS erasure_requests_status_idx ON erasure_requests (status, requested_at);
@@ -0,0 +1,10 @@
+-- Blind indexes for encrypted customer emails and phones, mirroring
+-- db/migrations/010_customer_pii_encryption.up.sql.
+ALTER TABLE customers ADD COLUMN email_bidx TEXT NOT NULL DEFAULT '';
+ALTER TABLE customers ADD COLUMN phone_bidx TEXT NOT NULL DEFAULT '';
+
+UPDATE customers SET email_bidx = lower(email) WHERE email <> '';
+UPDATE customers SET phone_bidx = phone_e164 WHERE phone_e164 <> '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS customers_active_email_bidx_idx ON customers (email_bidx) WHERE email_bidx <> '' AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS customers_phone_bidx_idx ON customers (phone_bidx) WHERE phone_bidx <> '';
@@ -0,0 +1,17 @@
+-- Vehicle colour, plate, archival and ownership transfers, mirroring
+-- db/migrations/011_vehicle_ownership.up.sql.
+ALTER TABLE vehicles ADD COLUMN color TEXT NOT NULL DEFAULT '';
+ALTER TABLE vehicles ADD COLUMN plate TEXT NOT NULL DEFAULT '';
+ALTER TABLE vehicles ADD COLUMN archived_at TEXT;
+
+CREATE TABLE IF NOT EXISTS vehicle_transfers (
+    id TEXT PRIMARY KEY,
+    vehicle_id TEXT NOT NULL REFERENCES vehicles(id) ON DELETE CASCADE,
+    from_customer_id TEXT NOT NULL,
+    to_customer_id TEXT NOT NULL,
+    mileage INTEGER NOT NULL DEFAULT 0,
+    note TEXT NOT NULL DEFAULT '',
+    transferred_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS vehicle_transfers_vehicle_idx ON vehicle_transfers (vehicle_id, transferred_at);
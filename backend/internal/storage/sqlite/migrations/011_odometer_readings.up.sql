@@ -0,0 +1,13 @@
+-- Odometer readings, mirroring db/migrations/012_odometer_readings.up.sql.
+CREATE TABLE IF NOT EXISTS odometer_readings (
+    id TEXT PRIMARY KEY,
+    vehicle_id TEXT NOT NULL REFERENCES vehicles(id) ON DELETE CASCADE,
+    mileage INTEGER NOT NULL,
+    source TEXT NOT NULL,
+    read_at TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    flagged INTEGER NOT NULL DEFAULT 0,
+    recorded_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS odometer_readings_vehicle_idx ON odometer_readings (vehicle_id, read_at);
@@ -0,0 +1,5 @@
+-- Technician notes on quotes, mirroring
+-- db/migrations/013_quote_technician_notes.up.sql.
+ALTER TABLE quotes ADD COLUMN technician_notes TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS quotes_vehicle_idx ON quotes (vehicle_id, created_at);
@@ -0,0 +1,24 @@
+-- Fleet and business accounts, mirroring
+-- db/migrations/014_fleet_accounts.up.sql.
+ALTER TABLE customers ADD COLUMN account_type TEXT NOT NULL DEFAULT 'individual';
+ALTER TABLE customers ADD COLUMN company_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE customers ADD COLUMN po_required INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE customers ADD COLUMN billing TEXT NOT NULL DEFAULT 'per_visit';
+
+CREATE TABLE IF NOT EXISTS fleet_contacts (
+    id TEXT PRIMARY KEY,
+    customer_id TEXT NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    role TEXT NOT NULL,
+    email TEXT NOT NULL DEFAULT '',
+    phone TEXT NOT NULL DEFAULT '',
+    phone_e164 TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS fleet_contacts_customer_idx ON fleet_contacts (customer_id, created_at);
+
+ALTER TABLE vehicles ADD COLUMN unit_number TEXT NOT NULL DEFAULT '';
+ALTER TABLE quotes ADD COLUMN po_number TEXT NOT NULL DEFAULT '';
+ALTER TABLE quotes ADD COLUMN contact_id TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,10 @@
+-- Recall marks, mirroring db/migrations/015_vehicle_recall_marks.up.sql.
+CREATE TABLE IF NOT EXISTS vehicle_recall_marks (
+    vehicle_id TEXT NOT NULL REFERENCES vehicles(id) ON DELETE CASCADE,
+    bulletin_id TEXT NOT NULL,
+    status TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    customer_id TEXT NOT NULL DEFAULT '',
+    marked_at TEXT NOT NULL,
+    PRIMARY KEY (vehicle_id, bulletin_id)
+);
@@ -0,0 +1,4 @@
+-- Who moved a quote to a new status, and why, mirroring
+-- db/migrations/016_quote_status_actor.up.sql.
+ALTER TABLE quote_status_changes ADD COLUMN actor TEXT NOT NULL DEFAULT '';
+ALTER TABLE quote_status_changes ADD COLUMN reason TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,14 @@
+-- Quote totals broken down into parts, labor, fees, discount and tax,
+-- mirroring db/migrations/017_quote_pricing.up.sql.
+ALTER TABLE quotes ADD COLUMN parts_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE quotes ADD COLUMN labor_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE quotes ADD COLUMN fees_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE quotes ADD COLUMN discount_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE quotes ADD COLUMN tax_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE quotes ADD COLUMN technician_tier TEXT NOT NULL DEFAULT '';
+
+UPDATE quotes SET parts_amount = total_amount;
+
+ALTER TABLE quote_line_items ADD COLUMN category TEXT NOT NULL DEFAULT '';
+ALTER TABLE quote_line_items ADD COLUMN labor_rate INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE quote_line_items ADD COLUMN labor_amount INTEGER NOT NULL DEFAULT 0;
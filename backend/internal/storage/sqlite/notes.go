@@ -0,0 +1,94 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+)
+
+// CustomerNoteRepository persists customer notes in SQLite.
+type CustomerNoteRepository struct {
+	db *sql.DB
+}
+
+// NewCustomerNoteRepository constructs the repository.
+func NewCustomerNoteRepository(db *sql.DB) *CustomerNoteRepository {
+	return &CustomerNoteRepository{db: db}
+}
+
+const noteColumns = `id, customer_id, kind, body, author, created_at`
+
+// FindByID fetches a note by identifier.
+func (r *CustomerNoteRepository) FindByID(id string) (customers.Note, error) {
+	n, err := scanNote(r.db.QueryRow(`SELECT `+noteColumns+` FROM customer_notes WHERE id = ?1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return customers.Note{}, customers.ErrNoteNotFound
+		}
+		return customers.Note{}, fmt.Errorf("find note: %w", err)
+	}
+	return n, nil
+}
+
+// ListByCustomer returns the customer's notes oldest first.
+func (r *CustomerNoteRepository) ListByCustomer(customerID string) ([]customers.Note, error) {
+	rows, err := r.db.Query(`SELECT `+noteColumns+` FROM customer_notes
+         WHERE customer_id = ?1
+         ORDER BY created_at, id`, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("list notes: %w", err)
+	}
+	defer rows.Close()
+
+	var result []customers.Note
+	for rows.Next() {
+		n, err := scanNote(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan note: %w", err)
+		}
+		result = append(result, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return result, nil
+}
+
+// Add inserts a note.
+func (r *CustomerNoteRepository) Add(n customers.Note) (customers.Note, error) {
+	const insert = `
+        INSERT INTO customer_notes (id, customer_id, kind, body, author, created_at)
+        VALUES (?1,?2,?3,?4,?5,?6)
+    `
+
+	id := newID()
+	now := timestamp()
+	if _, err := r.db.Exec(insert, id, n.CustomerID, string(n.Kind), n.Body, n.Author, formatTime(now)); err != nil {
+		return customers.Note{}, fmt.Errorf("insert note: %w", err)
+	}
+	n.ID = id
+	n.CreatedAt = now
+	return n, nil
+}
+
+// Delete removes a note.
+func (r *CustomerNoteRepository) Delete(id string) error {
+	res, err := r.db.Exec(`DELETE FROM customer_notes WHERE id = ?1`, id)
+	if err != nil {
+		return fmt.Errorf("delete note: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("delete note: %w", err)
+	} else if n == 0 {
+		return customers.ErrNoteNotFound
+	}
+	return nil
+}
+
+func scanNote(row rowScanner) (customers.Note, error) {
+	var n customers.Note
+	err := row.Scan(&n.ID, &n.CustomerID, &n.Kind, &n.Body, &n.Author, timeDest(&n.CreatedAt))
+	return n, err
+}
@@ -0,0 +1,64 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/quotes"
+)
+
+// QuoteStatusLog persists quote status changes in SQLite.
+type QuoteStatusLog struct {
+	db *sql.DB
+}
+
+// NewQuoteStatusLog constructs the repository.
+func NewQuoteStatusLog(db *sql.DB) *QuoteStatusLog {
+	return &QuoteStatusLog{db: db}
+}
+
+// Append records a status change.
+func (l *QuoteStatusLog) Append(c quotes.StatusChange) (quotes.StatusChange, error) {
+	const insert = `
+        INSERT INTO quote_status_changes (id, quote_id, from_status, to_status, actor, reason, changed_at)
+        VALUES (?1,?2,?3,?4,?5,?6,?7)
+    `
+
+	id := newID()
+	now := timestamp()
+	if _, err := l.db.Exec(insert, id, c.QuoteID, string(c.From), string(c.To), c.Actor, c.Reason, formatTime(now)); err != nil {
+		return quotes.StatusChange{}, fmt.Errorf("insert quote status change: %w", err)
+	}
+	c.ID = id
+	c.ChangedAt = now
+	return c, nil
+}
+
+// ListByQuote returns the quote's status changes oldest first.
+func (l *QuoteStatusLog) ListByQuote(quoteID string) ([]quotes.StatusChange, error) {
+	const query = `
+        SELECT id, quote_id, from_status, to_status, actor, reason, changed_at
+          FROM quote_status_changes
+         WHERE quote_id = ?1
+         ORDER BY changed_at, id
+    `
+
+	rows, err := l.db.Query(query, quoteID)
+	if err != nil {
+		return nil, fmt.Errorf("list quote status changes: %w", err)
+	}
+	defer rows.Close()
+
+	var result []quotes.StatusChange
+	for rows.Next() {
+		var c quotes.StatusChange
+		if err := rows.Scan(&c.ID, &c.QuoteID, &c.From, &c.To, &c.Actor, &c.Reason, timeDest(&c.ChangedAt)); err != nil {
+			return nil, fmt.Errorf("scan quote status change: %w", err)
+		}
+		result = append(result, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,421 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
+	"github.com/ezmobilemechanic/platform/internal/domain/quotes"
+)
+
+// QuoteRepository persists quotes and their line items.
+type QuoteRepository struct {
+	db *sql.DB
+}
+
+// NewQuoteRepository constructs a repository using a SQLite handle.
+func NewQuoteRepository(db *sql.DB) *QuoteRepository {
+	return &QuoteRepository{db: db}
+}
+
+const quoteColumns = `id, customer_id, vehicle_id, status, total_amount, technician_notes, created_at, updated_at, po_number, contact_id, parts_amount, labor_amount, fees_amount, discount_amount, tax_amount, technician_tier`
+
+// FindByID retrieves a quote and its line items.
+func (r *QuoteRepository) FindByID(id string) (quotes.Quote, error) {
+	q, err := scanQuote(r.db.QueryRow(`SELECT `+quoteColumns+` FROM quotes WHERE id = ?1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return quotes.Quote{}, quotes.ErrNotFound
+		}
+		return quotes.Quote{}, fmt.Errorf("find quote: %w", err)
+	}
+
+	items, err := r.fetchLineItems(q.ID)
+	if err != nil {
+		return quotes.Quote{}, err
+	}
+	q.LineItems = items
+
+	return q, nil
+}
+
+func (r *QuoteRepository) fetchLineItems(quoteID string) ([]quotes.LineItem, error) {
+	const query = `
+        SELECT id, description, quantity, unit_price, labor_hours, sort_order, category, labor_rate, labor_amount
+          FROM quote_line_items
+         WHERE quote_id = ?1
+         ORDER BY sort_order
+    `
+
+	rows, err := r.db.Query(query, quoteID)
+	if err != nil {
+		return nil, fmt.Errorf("list quote line items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []quotes.LineItem
+	for rows.Next() {
+		var item quotes.LineItem
+		item.QuoteID = quoteID
+		if err := rows.Scan(
+			&item.ID,
+			&item.Description,
+			&item.Quantity,
+			&item.UnitPrice,
+			&item.LaborHours,
+			&item.SortOrder,
+			&item.Category,
+			&item.LaborRate,
+			&item.LaborAmount,
+		); err != nil {
+			return nil, fmt.Errorf("scan quote line item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("line items rows err: %w", err)
+	}
+
+	return items, nil
+}
+
+// Save inserts or updates a quote with its line items.
+func (r *QuoteRepository) Save(q quotes.Quote) (quotes.Quote, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return quotes.Quote{}, fmt.Errorf("begin tx: %w", err)
+	}
+
+	now := timestamp()
+	if q.ID == "" {
+		const insert = `
+            INSERT INTO quotes (id, customer_id, vehicle_id, status, total_amount, technician_notes, created_at, updated_at, po_number, contact_id,
+                                parts_amount, labor_amount, fees_amount, discount_amount, tax_amount, technician_tier)
+            VALUES (?1,?2,?3,?4,?5,?6,?7,?7,?8,?9,?10,?11,?12,?13,?14,?15)
+        `
+		id := newID()
+		if _, err := tx.Exec(insert,
+			id,
+			q.CustomerID,
+			nullString(q.VehicleID),
+			q.Status,
+			q.TotalAmount,
+			q.TechnicianNotes,
+			formatTime(now),
+			q.PONumber,
+			q.ContactID,
+			q.PartsAmount,
+			q.LaborAmount,
+			q.FeesAmount,
+			q.DiscountAmount,
+			q.TaxAmount,
+			q.TechnicianTier,
+		); err != nil {
+			tx.Rollback()
+			return quotes.Quote{}, fmt.Errorf("insert quote: %w", err)
+		}
+		q.ID = id
+		q.CreatedAt = now
+		q.UpdatedAt = now
+	} else {
+		const update = `
+            UPDATE quotes
+               SET customer_id = ?2,
+                   vehicle_id = ?3,
+                   status = ?4,
+                   total_amount = ?5,
+                   technician_notes = ?6,
+                   updated_at = ?7,
+                   po_number = ?8,
+                   contact_id = ?9,
+                   parts_amount = ?10,
+                   labor_amount = ?11,
+                   fees_amount = ?12,
+                   discount_amount = ?13,
+                   tax_amount = ?14,
+                   technician_tier = ?15
+             WHERE id = ?1
+            RETURNING created_at
+        `
+		var created time.Time
+		if err := tx.QueryRow(update,
+			q.ID,
+			q.CustomerID,
+			nullString(q.VehicleID),
+			q.Status,
+			q.TotalAmount,
+			q.TechnicianNotes,
+			formatTime(now),
+			q.PONumber,
+			q.ContactID,
+			q.PartsAmount,
+			q.LaborAmount,
+			q.FeesAmount,
+			q.DiscountAmount,
+			q.TaxAmount,
+			q.TechnicianTier,
+		).Scan(timeDest(&created)); err != nil {
+			tx.Rollback()
+			if errors.Is(err, sql.ErrNoRows) {
+				return quotes.Quote{}, quotes.ErrNotFound
+			}
+			return quotes.Quote{}, fmt.Errorf("update quote: %w", err)
+		}
+		q.CreatedAt = created
+		q.UpdatedAt = now
+
+		if _, err := tx.Exec(`DELETE FROM quote_line_items WHERE quote_id = ?1`, q.ID); err != nil {
+			tx.Rollback()
+			return quotes.Quote{}, fmt.Errorf("delete quote line items: %w", err)
+		}
+	}
+
+	if err := insertLineItems(tx, &q); err != nil {
+		tx.Rollback()
+		return quotes.Quote{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return quotes.Quote{}, fmt.Errorf("commit quote save: %w", err)
+	}
+
+	return q, nil
+}
+
+// UpdateStatus saves q with its new status and appends change to the status
+// history in one transaction, provided the stored status is still
+// change.From. A change that keeps the status records no history.
+func (r *QuoteRepository) UpdateStatus(q quotes.Quote, change quotes.StatusChange) (quotes.Quote, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return quotes.Quote{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	const update = `
+        UPDATE quotes
+           SET customer_id = ?2,
+               vehicle_id = ?3,
+               status = ?4,
+               total_amount = ?5,
+               technician_notes = ?6,
+               updated_at = ?7,
+               po_number = ?8,
+               contact_id = ?9,
+               parts_amount = ?10,
+               labor_amount = ?11,
+               fees_amount = ?12,
+               discount_amount = ?13,
+               tax_amount = ?14,
+               technician_tier = ?15
+         WHERE id = ?1 AND status = ?16
+        RETURNING created_at
+    `
+	now := timestamp()
+	var created time.Time
+	err = tx.QueryRow(update,
+		q.ID,
+		q.CustomerID,
+		nullString(q.VehicleID),
+		change.To,
+		q.TotalAmount,
+		q.TechnicianNotes,
+		formatTime(now),
+		q.PONumber,
+		q.ContactID,
+		q.PartsAmount,
+		q.LaborAmount,
+		q.FeesAmount,
+		q.DiscountAmount,
+		q.TaxAmount,
+		q.TechnicianTier,
+		change.From,
+	).Scan(timeDest(&created))
+	if errors.Is(err, sql.ErrNoRows) {
+		var exists bool
+		if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM quotes WHERE id = ?1)`, q.ID).Scan(&exists); err != nil {
+			return quotes.Quote{}, fmt.Errorf("find quote: %w", err)
+		}
+		if !exists {
+			return quotes.Quote{}, quotes.ErrNotFound
+		}
+		return quotes.Quote{}, quotes.ErrStatusChanged
+	}
+	if err != nil {
+		return quotes.Quote{}, fmt.Errorf("update quote status: %w", err)
+	}
+
+	if change.From != change.To {
+		const insert = `
+            INSERT INTO quote_status_changes (id, quote_id, from_status, to_status, actor, reason, changed_at)
+            VALUES (?1,?2,?3,?4,?5,?6,?7)
+        `
+		if _, err := tx.Exec(insert, newID(), q.ID, string(change.From), string(change.To), change.Actor, change.Reason, formatTime(now)); err != nil {
+			return quotes.Quote{}, fmt.Errorf("insert quote status change: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return quotes.Quote{}, fmt.Errorf("commit quote status: %w", err)
+	}
+	q.Status = change.To
+	q.CreatedAt = created
+	q.UpdatedAt = now
+	return q, nil
+}
+
+// insertLineItems writes the quote's line items, assigning IDs, QuoteID and
+// SortOrder back onto q.
+func insertLineItems(tx *sql.Tx, q *quotes.Quote) error {
+	const insert = `
+        INSERT INTO quote_line_items (id, quote_id, description, quantity, unit_price, labor_hours, sort_order, category, labor_rate, labor_amount)
+        VALUES (?1,?2,?3,?4,?5,?6,?7,?8,?9,?10)
+    `
+
+	for idx := range q.LineItems {
+		item := &q.LineItems[idx]
+		if item.ID == "" {
+			item.ID = newID()
+		}
+		if _, err := tx.Exec(insert,
+			item.ID,
+			q.ID,
+			item.Description,
+			item.Quantity,
+			item.UnitPrice,
+			item.LaborHours,
+			idx,
+			item.Category,
+			item.LaborRate,
+			item.LaborAmount,
+		); err != nil {
+			return fmt.Errorf("insert quote line item: %w", err)
+		}
+		item.QuoteID = q.ID
+		item.SortOrder = idx
+	}
+
+	return nil
+}
+
+// ListByCustomer returns a keyset-paginated page of quotes for a customer.
+func (r *QuoteRepository) ListByCustomer(customerID string, spec listing.Spec) (listing.Page[quotes.Quote], error) {
+	const selectQuotes = `SELECT ` + quoteColumns + ` FROM quotes`
+
+	spec = spec.Normalize()
+
+	var lq listQuery
+	lq.filter("customer_id = " + lq.arg(customerID))
+	if spec.Status != "" {
+		lq.filter("status = " + lq.arg(spec.Status))
+	}
+	lq.created("created_at", spec)
+	if spec.Search != "" {
+		lq.filter(`EXISTS (SELECT 1 FROM quote_line_items li
+                           WHERE li.quote_id = quotes.id
+                             AND instr(lower(li.description), ` + lq.arg(strings.ToLower(spec.Search)) + `) > 0)`)
+	}
+
+	countQuery, countArgs := lq.countSQL("quotes")
+	var total int
+	if err := r.db.QueryRow(countQuery, countArgs...).Scan(&total); err != nil {
+		return listing.Page[quotes.Quote]{}, fmt.Errorf("count quotes: %w", err)
+	}
+
+	query, err := lq.page(selectQuotes, spec)
+	if err != nil {
+		return listing.Page[quotes.Quote]{}, err
+	}
+
+	rows, err := r.db.Query(query, lq.args...)
+	if err != nil {
+		return listing.Page[quotes.Quote]{}, fmt.Errorf("list quotes: %w", err)
+	}
+	defer rows.Close()
+
+	var result []quotes.Quote
+	for rows.Next() {
+		q, err := scanQuote(rows)
+		if err != nil {
+			return listing.Page[quotes.Quote]{}, fmt.Errorf("scan quote: %w", err)
+		}
+		result = append(result, q)
+	}
+	if err := rows.Err(); err != nil {
+		return listing.Page[quotes.Quote]{}, fmt.Errorf("rows err: %w", err)
+	}
+	// Release the connection before fetching line items; the pool is sized
+	// to a single connection for SQLite.
+	rows.Close()
+
+	for i := range result {
+		items, err := r.fetchLineItems(result[i].ID)
+		if err != nil {
+			return listing.Page[quotes.Quote]{}, err
+		}
+		result[i].LineItems = items
+	}
+
+	return cutPage(result, spec, total, quotes.SortKey), nil
+}
+
+// ListByVehicle returns the vehicle's quotes oldest first.
+func (r *QuoteRepository) ListByVehicle(vehicleID string) ([]quotes.Quote, error) {
+	rows, err := r.db.Query(`SELECT `+quoteColumns+` FROM quotes
+         WHERE vehicle_id = ?1
+         ORDER BY created_at, id`, vehicleID)
+	if err != nil {
+		return nil, fmt.Errorf("list vehicle quotes: %w", err)
+	}
+	defer rows.Close()
+
+	result := []quotes.Quote{}
+	for rows.Next() {
+		q, err := scanQuote(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan quote: %w", err)
+		}
+		result = append(result, q)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+	rows.Close()
+
+	for i := range result {
+		items, err := r.fetchLineItems(result[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		result[i].LineItems = items
+	}
+	return result, nil
+}
+
+func scanQuote(row rowScanner) (quotes.Quote, error) {
+	var q quotes.Quote
+	var vehicleID sql.NullString
+	err := row.Scan(
+		&q.ID,
+		&q.CustomerID,
+		&vehicleID,
+		&q.Status,
+		&q.TotalAmount,
+		&q.TechnicianNotes,
+		timeDest(&q.CreatedAt),
+		timeDest(&q.UpdatedAt),
+		&q.PONumber,
+		&q.ContactID,
+		&q.PartsAmount,
+		&q.LaborAmount,
+		&q.FeesAmount,
+		&q.DiscountAmount,
+		&q.TaxAmount,
+		&q.TechnicianTier,
+	)
+	q.VehicleID = vehicleID.String
+	return q, err
+}
@@ -0,0 +1,55 @@
+// Package sqlite implements the repositories on an embedded SQLite database
+// (DATA_BACKEND=sqlite) so a single-location shop can run the platform as one
+// binary. Behaviour matches the Postgres repositories; both run the shared
+// storagetest suite.
+package sqlite
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+
+	"github.com/ezmobilemechanic/platform/internal/database"
+)
+
+// DriverName is the database/sql driver registered by modernc.org/sqlite.
+const DriverName = "sqlite"
+
+// CheckDriver returns an error naming the fix when no database/sql driver is
+// registered as name. The SQLite driver is only linked into binaries built
+// with -tags sqlite.
+func CheckDriver(name string) error {
+	if slices.Contains(sql.Drivers(), name) {
+		return nil
+	}
+	if name == DriverName {
+		return errors.New("DATA_BACKEND=sqlite needs the SQLite driver, which this binary was built without: rebuild with -tags sqlite")
+	}
+	return fmt.Errorf("database driver %q is not linked into this binary", name)
+}
+
+//go:embed migrations/*.up.sql
+var migrationsFS embed.FS
+
+// DSN returns a connection string for the database file at path. Foreign keys
+// are enforced and WAL journaling lets readers proceed during writes.
+func DSN(path string) string {
+	q := url.Values{}
+	q.Add("_pragma", "foreign_keys(1)")
+	q.Add("_pragma", "busy_timeout(5000)")
+	q.Add("_pragma", "journal_mode(WAL)")
+	return "file:" + path + "?" + q.Encode()
+}
+
+// NewMigrator returns a migrator for the embedded SQLite schema. SQLite has no
+// ADD COLUMN IF NOT EXISTS, so applied files are tracked in schema_migrations
+// and each runs once.
+func NewMigrator(db *sql.DB, logger *slog.Logger) *database.SQLMigrator {
+	m := database.NewSQLMigrator(db, migrationsFS, "migrations", logger)
+	m.VersionTable = "schema_migrations"
+	return m
+}
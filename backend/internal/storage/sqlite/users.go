@@ -0,0 +1,123 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/users"
+)
+
+// UserRepository persists users in SQLite.
+type UserRepository struct {
+	db *sql.DB
+}
+
+// NewUserRepository constructs a SQLite-backed user repository.
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+func (r *UserRepository) FindByID(id string) (users.User, error) {
+	const query = `
+        SELECT id, email, name, password_hash, password_salt, created_at, updated_at
+          FROM users
+         WHERE id = ?1
+    `
+	u, err := scanUser(r.db.QueryRow(query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return users.User{}, users.ErrNotFound
+		}
+		return users.User{}, fmt.Errorf("find user: %w", err)
+	}
+	return u, nil
+}
+
+func (r *UserRepository) FindByEmail(email string) (users.User, error) {
+	const query = `
+        SELECT id, email, name, password_hash, password_salt, created_at, updated_at
+          FROM users
+         WHERE lower(email) = lower(?1)
+    `
+	u, err := scanUser(r.db.QueryRow(query, email))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return users.User{}, users.ErrNotFound
+		}
+		return users.User{}, fmt.Errorf("find user by email: %w", err)
+	}
+	return u, nil
+}
+
+func (r *UserRepository) Save(user users.User) (users.User, error) {
+	now := timestamp()
+	user.Email = strings.ToLower(user.Email)
+
+	if user.ID == "" {
+		const insert = `
+            INSERT INTO users (id, email, name, password_hash, password_salt, created_at, updated_at)
+            VALUES (?1,?2,?3,?4,?5,?6,?6)
+        `
+		id := newID()
+		if _, err := r.db.Exec(insert,
+			id,
+			user.Email,
+			user.Name,
+			user.PasswordHash,
+			user.PasswordSalt,
+			formatTime(now),
+		); err != nil {
+			if isUniqueViolation(err) {
+				return users.User{}, users.ErrEmailExists
+			}
+			return users.User{}, fmt.Errorf("insert user: %w", err)
+		}
+		user.ID = id
+		user.CreatedAt = now
+		user.UpdatedAt = now
+		return user, nil
+	}
+
+	const update = `
+        UPDATE users
+           SET email = ?2,
+               name = ?3,
+               password_hash = ?4,
+               password_salt = ?5,
+               updated_at = ?6
+         WHERE id = ?1
+        RETURNING created_at
+    `
+	var created time.Time
+	err := r.db.QueryRow(update,
+		user.ID,
+		user.Email,
+		user.Name,
+		user.PasswordHash,
+		user.PasswordSalt,
+		formatTime(now),
+	).Scan(timeDest(&created))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return users.User{}, users.ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return users.User{}, users.ErrEmailExists
+		}
+		return users.User{}, fmt.Errorf("update user: %w", err)
+	}
+	user.CreatedAt = created
+	user.UpdatedAt = now
+	return user, nil
+}
+
+func scanUser(row rowScanner) (users.User, error) {
+	var u users.User
+	err := row.Scan(&u.ID, &u.Email, &u.Name, &u.PasswordHash, &u.PasswordSalt, timeDest(&u.CreatedAt), timeDest(&u.UpdatedAt))
+	return u, err
+}
+
+var _ users.Repository = (*UserRepository)(nil)
@@ -0,0 +1,238 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+)
+
+// VehicleRepository persists vehicles in SQLite.
+type VehicleRepository struct {
+	db *sql.DB
+}
+
+// NewVehicleRepository constructs the repository.
+func NewVehicleRepository(db *sql.DB) *VehicleRepository {
+	return &VehicleRepository{db: db}
+}
+
+const vehicleColumns = `id, customer_id, vin, year, make, model, trim, engine, color, plate, mileage,
+               created_at, updated_at, archived_at, unit_number`
+
+// FindByID fetches a vehicle by identifier.
+func (r *VehicleRepository) FindByID(id string) (vehicles.Vehicle, error) {
+	v, err := scanVehicle(r.db.QueryRow(`SELECT `+vehicleColumns+` FROM vehicles WHERE id = ?1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return vehicles.Vehicle{}, vehicles.ErrNotFound
+		}
+		return vehicles.Vehicle{}, fmt.Errorf("find vehicle: %w", err)
+	}
+
+	return v, nil
+}
+
+// ListByCustomer returns vehicles for a customer ordered by creation,
+// archived ones included.
+func (r *VehicleRepository) ListByCustomer(customerID string) ([]vehicles.Vehicle, error) {
+	rows, err := r.db.Query(`SELECT `+vehicleColumns+` FROM vehicles
+         WHERE customer_id = ?1
+         ORDER BY created_at, id`, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("list vehicles: %w", err)
+	}
+	defer rows.Close()
+
+	var result []vehicles.Vehicle
+	for rows.Next() {
+		v, err := scanVehicle(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan vehicle: %w", err)
+		}
+		result = append(result, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return result, nil
+}
+
+// Save inserts or updates vehicle data.
+func (r *VehicleRepository) Save(vehicle vehicles.Vehicle) (vehicles.Vehicle, error) {
+	now := timestamp()
+	archivedAt := nullTime(vehicle.ArchivedAt)
+
+	if vehicle.ID == "" {
+		const insert = `
+            INSERT INTO vehicles (id, customer_id, vin, year, make, model, trim, engine, color, plate, mileage,
+                                  created_at, updated_at, archived_at, unit_number)
+            VALUES (?1,?2,?3,?4,?5,?6,?7,?8,?9,?10,?11,?12,?12,?13,?14)
+        `
+		id := newID()
+		if _, err := r.db.Exec(insert,
+			id,
+			vehicle.CustomerID,
+			vehicle.VIN,
+			vehicle.Year,
+			vehicle.Make,
+			vehicle.Model,
+			vehicle.Trim,
+			vehicle.Engine,
+			vehicle.Color,
+			vehicle.Plate,
+			vehicle.Mileage,
+			formatTime(now),
+			archivedAt,
+			vehicle.UnitNumber,
+		); err != nil {
+			return vehicles.Vehicle{}, fmt.Errorf("insert vehicle: %w", err)
+		}
+		vehicle.ID = id
+		vehicle.CreatedAt = now
+		vehicle.UpdatedAt = now
+		return vehicle, nil
+	}
+
+	const update = `
+        UPDATE vehicles
+           SET customer_id = ?2,
+               vin = ?3,
+               year = ?4,
+               make = ?5,
+               model = ?6,
+               trim = ?7,
+               engine = ?8,
+               color = ?9,
+               plate = ?10,
+               mileage = ?11,
+               updated_at = ?12,
+               archived_at = ?13,
+               unit_number = ?14
+         WHERE id = ?1
+        RETURNING created_at
+    `
+
+	var created time.Time
+	err := r.db.QueryRow(update,
+		vehicle.ID,
+		vehicle.CustomerID,
+		vehicle.VIN,
+		vehicle.Year,
+		vehicle.Make,
+		vehicle.Model,
+		vehicle.Trim,
+		vehicle.Engine,
+		vehicle.Color,
+		vehicle.Plate,
+		vehicle.Mileage,
+		formatTime(now),
+		archivedAt,
+		vehicle.UnitNumber,
+	).Scan(timeDest(&created))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return vehicles.Vehicle{}, vehicles.ErrNotFound
+		}
+		return vehicles.Vehicle{}, fmt.Errorf("update vehicle: %w", err)
+	}
+	vehicle.CreatedAt = created
+	vehicle.UpdatedAt = now
+	return vehicle, nil
+}
+
+// Transfer moves a vehicle to its new owner and records the transfer in one
+// transaction.
+func (r *VehicleRepository) Transfer(t vehicles.Transfer, readings []vehicles.Reading) (vehicles.Transfer, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return vehicles.Transfer{}, fmt.Errorf("begin transfer: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := timestamp()
+	res, err := tx.Exec(`
+        UPDATE vehicles
+           SET customer_id = ?3, unit_number = '', mileage = MAX(mileage, ?4), updated_at = ?5
+         WHERE id = ?1 AND customer_id = ?2`,
+		t.VehicleID, t.FromCustomerID, t.ToCustomerID, t.Mileage, formatTime(now))
+	if err != nil {
+		return vehicles.Transfer{}, fmt.Errorf("transfer vehicle: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return vehicles.Transfer{}, fmt.Errorf("transfer vehicle: %w", err)
+	} else if n == 0 {
+		return vehicles.Transfer{}, vehicles.ErrNotFound
+	}
+
+	t.ID = newID()
+	if _, err := tx.Exec(`
+        INSERT INTO vehicle_transfers (id, vehicle_id, from_customer_id, to_customer_id, mileage, note, transferred_at)
+        VALUES (?1,?2,?3,?4,?5,?6,?7)`,
+		t.ID, t.VehicleID, t.FromCustomerID, t.ToCustomerID, t.Mileage, t.Note, formatTime(now),
+	); err != nil {
+		return vehicles.Transfer{}, fmt.Errorf("record transfer: %w", err)
+	}
+	for _, reading := range readings {
+		if _, err := insertReading(tx, reading, now); err != nil {
+			return vehicles.Transfer{}, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return vehicles.Transfer{}, fmt.Errorf("commit transfer: %w", err)
+	}
+	t.TransferredAt = now
+	return t, nil
+}
+
+// ListTransfers returns a vehicle's transfers, oldest first.
+func (r *VehicleRepository) ListTransfers(vehicleID string) ([]vehicles.Transfer, error) {
+	rows, err := r.db.Query(`
+        SELECT id, vehicle_id, from_customer_id, to_customer_id, mileage, note, transferred_at
+          FROM vehicle_transfers
+         WHERE vehicle_id = ?1
+         ORDER BY transferred_at, id`, vehicleID)
+	if err != nil {
+		return nil, fmt.Errorf("list transfers: %w", err)
+	}
+	defer rows.Close()
+
+	result := []vehicles.Transfer{}
+	for rows.Next() {
+		var t vehicles.Transfer
+		if err := rows.Scan(&t.ID, &t.VehicleID, &t.FromCustomerID, &t.ToCustomerID, &t.Mileage, &t.Note, timeDest(&t.TransferredAt)); err != nil {
+			return nil, fmt.Errorf("scan transfer: %w", err)
+		}
+		result = append(result, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return result, nil
+}
+
+func scanVehicle(row rowScanner) (vehicles.Vehicle, error) {
+	var v vehicles.Vehicle
+	err := row.Scan(
+		&v.ID,
+		&v.CustomerID,
+		&v.VIN,
+		&v.Year,
+		&v.Make,
+		&v.Model,
+		&v.Trim,
+		&v.Engine,
+		&v.Color,
+		&v.Plate,
+		&v.Mileage,
+		timeDest(&v.CreatedAt),
+		timeDest(&v.UpdatedAt),
+		nullTimeDest(&v.ArchivedAt),
+		&v.UnitNumber,
+	)
+	return v, err
+}
@@ -0,0 +1,164 @@
+package storagetest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/geo"
+)
+
+// AddressRepository verifies the customers.AddressRepository contract and
+// how addresses follow their customer through Merge and Purge.
+func AddressRepository(t *testing.T, newBackend Factory) {
+	t.Run("SaveFindListDelete", func(t *testing.T) {
+		b := newBackend(t)
+		owner := saveCustomer(t, b.Customers, "owner@example.com")
+
+		home, err := b.Addresses.Save(customers.Address{
+			CustomerID: owner.ID,
+			Label:      customers.LabelHome,
+			Line1:      "1 Main St",
+			City:       "Jacksonville",
+			Region:     "FL",
+			PostalCode: "32207",
+			Country:    "US",
+			Location:   &geo.Point{Lat: 30.292, Lng: -81.636},
+			GeocodedBy: geo.SourceZIPCentroid,
+		})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		if home.ID == "" || home.CreatedAt.IsZero() || !home.CreatedAt.Equal(home.UpdatedAt) {
+			t.Fatalf("expected ID and timestamps, got %+v", home)
+		}
+		got, err := b.Addresses.FindByID(home.ID)
+		if err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		assertAddressEqual(t, home, got)
+
+		tick()
+		yard, err := b.Addresses.Save(customers.Address{CustomerID: owner.ID, Label: customers.LabelFleetYard, Line1: "9 Depot Rd", City: "Orange Park", Country: "US"})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		if got, err := b.Addresses.FindByID(yard.ID); err != nil || got.Location != nil {
+			t.Fatalf("expected address without location, got %+v (%v)", got, err)
+		}
+
+		tick()
+		home.Line2 = "Apt 4"
+		home.Location = nil
+		home.GeocodedBy = ""
+		updated, err := b.Addresses.Save(home)
+		if err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		if !updated.CreatedAt.Equal(got.CreatedAt) || !updated.UpdatedAt.After(updated.CreatedAt) {
+			t.Fatalf("unexpected timestamps %+v", updated)
+		}
+		got, err = b.Addresses.FindByID(home.ID)
+		if err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		assertAddressEqual(t, updated, got)
+
+		list, err := b.Addresses.ListByCustomer(owner.ID)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		assertSequence(t, "addresses", []string{home.ID, yard.ID}, addressIDs(list))
+
+		if err := b.Addresses.Delete(home.ID); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if _, err := b.Addresses.FindByID(home.ID); !errors.Is(err, customers.ErrAddressNotFound) {
+			t.Fatalf("expected ErrAddressNotFound after delete, got %v", err)
+		}
+		if err := b.Addresses.Delete(home.ID); !errors.Is(err, customers.ErrAddressNotFound) {
+			t.Fatalf("delete twice: expected ErrAddressNotFound, got %v", err)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		b := newBackend(t)
+		if _, err := b.Addresses.FindByID(missingID); !errors.Is(err, customers.ErrAddressNotFound) {
+			t.Fatalf("find: expected ErrAddressNotFound, got %v", err)
+		}
+		if _, err := b.Addresses.Save(customers.Address{ID: missingID, Line1: "x"}); !errors.Is(err, customers.ErrAddressNotFound) {
+			t.Fatalf("save: expected ErrAddressNotFound, got %v", err)
+		}
+		if list, err := b.Addresses.ListByCustomer(missingID); err != nil || len(list) != 0 {
+			t.Fatalf("list: expected no addresses, got %v (%v)", list, err)
+		}
+	})
+
+	t.Run("FollowCustomer", func(t *testing.T) {
+		b := newBackend(t)
+		survivor := saveCustomer(t, b.Customers, "survivor@example.com")
+		dup := saveCustomer(t, b.Customers, "dup@example.com")
+		gone := saveCustomer(t, b.Customers, "gone@example.com")
+
+		work, err := b.Addresses.Save(customers.Address{CustomerID: dup.ID, Label: customers.LabelWork, Line1: "5 Office Pkwy", PostalCode: "32256", Country: "US"})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		purged, err := b.Addresses.Save(customers.Address{CustomerID: gone.ID, Label: customers.LabelHome, Line1: "7 Elm St", PostalCode: "32207", Country: "US"})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+
+		if _, err := b.Customers.Merge(survivor, dup.ID); err != nil {
+			t.Fatalf("merge: %v", err)
+		}
+		list, err := b.Addresses.ListByCustomer(survivor.ID)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		assertSequence(t, "survivor addresses", []string{work.ID}, addressIDs(list))
+
+		if _, err := b.Customers.Delete(gone.ID); err != nil {
+			t.Fatalf("delete customer: %v", err)
+		}
+		if _, err := b.Addresses.FindByID(purged.ID); err != nil {
+			t.Fatalf("soft delete must keep addresses: %v", err)
+		}
+		if _, err := b.Customers.Purge(time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("purge: %v", err)
+		}
+		if _, err := b.Addresses.FindByID(purged.ID); !errors.Is(err, customers.ErrAddressNotFound) {
+			t.Fatalf("expected purge to remove addresses, got %v", err)
+		}
+	})
+}
+
+func addressIDs(list []customers.Address) []string {
+	ids := make([]string, len(list))
+	for i, a := range list {
+		ids[i] = a.ID
+	}
+	return ids
+}
+
+func assertAddressEqual(t *testing.T, want, got customers.Address) {
+	t.Helper()
+	sameLocation := (want.Location == nil) == (got.Location == nil) &&
+		(want.Location == nil || *want.Location == *got.Location)
+	if want.ID != got.ID ||
+		want.CustomerID != got.CustomerID ||
+		want.Label != got.Label ||
+		want.Line1 != got.Line1 ||
+		want.Line2 != got.Line2 ||
+		want.City != got.City ||
+		want.Region != got.Region ||
+		want.PostalCode != got.PostalCode ||
+		want.Country != got.Country ||
+		!sameLocation ||
+		want.GeocodedBy != got.GeocodedBy ||
+		!want.CreatedAt.Equal(got.CreatedAt) ||
+		!want.UpdatedAt.Equal(got.UpdatedAt) {
+		t.Fatalf("address mismatch:\nwant %+v\ngot  %+v", want, got)
+	}
+}
@@ -0,0 +1,128 @@
+package storagetest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+)
+
+// ConsentRepository verifies the customers.ConsentRepository contract and
+// how the ledger follows its customer through Merge and Purge.
+func ConsentRepository(t *testing.T, newBackend Factory) {
+	t.Run("AppendList", func(t *testing.T) {
+		b := newBackend(t)
+		owner := saveCustomer(t, b.Customers, "owner@example.com")
+		other := saveCustomer(t, b.Customers, "other@example.com")
+
+		grant, err := b.Consents.Append(customers.ConsentEntry{
+			CustomerID: owner.ID,
+			Channel:    customers.ChannelSMS,
+			Purpose:    customers.PurposeMarketing,
+			Granted:    true,
+			Source:     customers.SourceWebForm,
+			Detail:     "https://example.com/quote",
+			IPAddress:  "203.0.113.7",
+			UserAgent:  "Mozilla/5.0",
+		})
+		if err != nil {
+			t.Fatalf("append: %v", err)
+		}
+		if grant.ID == "" || grant.RecordedAt.IsZero() {
+			t.Fatalf("expected ID and RecordedAt, got %+v", grant)
+		}
+		// Entries appended back to back must keep their order even if they
+		// share a timestamp.
+		var ids []string
+		for _, p := range customers.Purposes {
+			e, err := b.Consents.Append(customers.ConsentEntry{CustomerID: owner.ID, Channel: customers.ChannelSMS, Purpose: p, Source: customers.SourceKeyword, Detail: "STOP"})
+			if err != nil {
+				t.Fatalf("append: %v", err)
+			}
+			ids = append(ids, e.ID)
+		}
+		if _, err := b.Consents.Append(customers.ConsentEntry{CustomerID: other.ID, Channel: customers.ChannelEmail, Purpose: customers.PurposeMarketing, Granted: true, Source: customers.SourceStaff}); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+
+		list, err := b.Consents.ListByCustomer(owner.ID)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		assertSequence(t, "ledger", append([]string{grant.ID}, ids...), consentIDs(list))
+		assertConsentEqual(t, grant, list[0])
+
+		if list, err := b.Consents.ListByCustomer(missingID); err != nil || len(list) != 0 {
+			t.Fatalf("list: expected no entries, got %v (%v)", list, err)
+		}
+	})
+
+	t.Run("FollowCustomer", func(t *testing.T) {
+		b := newBackend(t)
+		survivor := saveCustomer(t, b.Customers, "survivor@example.com")
+		dup := saveCustomer(t, b.Customers, "dup@example.com")
+		gone := saveCustomer(t, b.Customers, "gone@example.com")
+
+		kept, err := b.Consents.Append(customers.ConsentEntry{CustomerID: survivor.ID, Channel: customers.ChannelEmail, Purpose: customers.PurposeMarketing, Granted: true, Source: customers.SourceStaff})
+		if err != nil {
+			t.Fatalf("append: %v", err)
+		}
+		tick()
+		moved, err := b.Consents.Append(customers.ConsentEntry{CustomerID: dup.ID, Channel: customers.ChannelSMS, Purpose: customers.PurposeTransactional, Source: customers.SourceKeyword, Detail: "STOP"})
+		if err != nil {
+			t.Fatalf("append: %v", err)
+		}
+		if _, err := b.Consents.Append(customers.ConsentEntry{CustomerID: gone.ID, Channel: customers.ChannelVoice, Purpose: customers.PurposeMarketing, Granted: true, Source: customers.SourceStaff}); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+
+		if _, err := b.Customers.Merge(survivor, dup.ID); err != nil {
+			t.Fatalf("merge: %v", err)
+		}
+		list, err := b.Consents.ListByCustomer(survivor.ID)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		assertSequence(t, "survivor ledger", []string{kept.ID, moved.ID}, consentIDs(list))
+		if !list[1].RecordedAt.Equal(moved.RecordedAt) {
+			t.Fatalf("merge must keep RecordedAt, got %v want %v", list[1].RecordedAt, moved.RecordedAt)
+		}
+
+		if _, err := b.Customers.Delete(gone.ID); err != nil {
+			t.Fatalf("delete customer: %v", err)
+		}
+		if list, err := b.Consents.ListByCustomer(gone.ID); err != nil || len(list) != 1 {
+			t.Fatalf("soft delete must keep the ledger, got %v (%v)", list, err)
+		}
+		if _, err := b.Customers.Purge(time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("purge: %v", err)
+		}
+		if list, err := b.Consents.ListByCustomer(gone.ID); err != nil || len(list) != 0 {
+			t.Fatalf("expected purge to remove the ledger, got %v (%v)", list, err)
+		}
+	})
+}
+
+func consentIDs(list []customers.ConsentEntry) []string {
+	ids := make([]string, len(list))
+	for i, e := range list {
+		ids[i] = e.ID
+	}
+	return ids
+}
+
+func assertConsentEqual(t *testing.T, want, got customers.ConsentEntry) {
+	t.Helper()
+	if want.ID != got.ID ||
+		want.CustomerID != got.CustomerID ||
+		want.Channel != got.Channel ||
+		want.Purpose != got.Purpose ||
+		want.Granted != got.Granted ||
+		want.Source != got.Source ||
+		want.Detail != got.Detail ||
+		want.IPAddress != got.IPAddress ||
+		want.UserAgent != got.UserAgent ||
+		!want.RecordedAt.Equal(got.RecordedAt) {
+		t.Fatalf("consent mismatch:\nwant %+v\ngot  %+v", want, got)
+	}
+}
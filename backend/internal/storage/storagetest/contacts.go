@@ -0,0 +1,150 @@
+package storagetest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+)
+
+// ContactRepository verifies the customers.ContactRepository contract and
+// how contacts follow their customer through Merge and Purge.
+func ContactRepository(t *testing.T, newBackend Factory) {
+	t.Run("SaveFindListDelete", func(t *testing.T) {
+		b := newBackend(t)
+		fleet := saveCustomer(t, b.Customers, "fleet@example.com")
+
+		manager, err := b.Contacts.Save(customers.Contact{
+			CustomerID: fleet.ID,
+			Name:       "Dana Reyes",
+			Role:       customers.RoleFleetManager,
+			Email:      "dana@example.com",
+			Phone:      "(904) 555-0100",
+			PhoneE164:  "+19045550100",
+		})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		if manager.ID == "" || manager.CreatedAt.IsZero() || !manager.CreatedAt.Equal(manager.UpdatedAt) {
+			t.Fatalf("expected ID and timestamps, got %+v", manager)
+		}
+		got, err := b.Contacts.FindByID(manager.ID)
+		if err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		assertContactEqual(t, manager, got)
+
+		tick()
+		driver, err := b.Contacts.Save(customers.Contact{CustomerID: fleet.ID, Name: "Lee", Role: customers.RoleDriver, Phone: "904-555-0101", PhoneE164: "+19045550101"})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+
+		tick()
+		manager.Role = customers.RoleBilling
+		manager.Email = "billing@example.com"
+		updated, err := b.Contacts.Save(manager)
+		if err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		if !updated.CreatedAt.Equal(got.CreatedAt) || !updated.UpdatedAt.After(updated.CreatedAt) {
+			t.Fatalf("unexpected timestamps %+v", updated)
+		}
+		got, err = b.Contacts.FindByID(manager.ID)
+		if err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		assertContactEqual(t, updated, got)
+
+		list, err := b.Contacts.ListByCustomer(fleet.ID)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		assertSequence(t, "contacts", []string{manager.ID, driver.ID}, contactIDs(list))
+
+		if err := b.Contacts.Delete(manager.ID); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if _, err := b.Contacts.FindByID(manager.ID); !errors.Is(err, customers.ErrContactNotFound) {
+			t.Fatalf("expected ErrContactNotFound after delete, got %v", err)
+		}
+		if err := b.Contacts.Delete(manager.ID); !errors.Is(err, customers.ErrContactNotFound) {
+			t.Fatalf("delete twice: expected ErrContactNotFound, got %v", err)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		b := newBackend(t)
+		if _, err := b.Contacts.FindByID(missingID); !errors.Is(err, customers.ErrContactNotFound) {
+			t.Fatalf("find: expected ErrContactNotFound, got %v", err)
+		}
+		if _, err := b.Contacts.Save(customers.Contact{ID: missingID, Name: "x"}); !errors.Is(err, customers.ErrContactNotFound) {
+			t.Fatalf("save: expected ErrContactNotFound, got %v", err)
+		}
+		if list, err := b.Contacts.ListByCustomer(missingID); err != nil || len(list) != 0 {
+			t.Fatalf("list: expected no contacts, got %v (%v)", list, err)
+		}
+	})
+
+	t.Run("FollowCustomer", func(t *testing.T) {
+		b := newBackend(t)
+		survivor := saveCustomer(t, b.Customers, "survivor@example.com")
+		dup := saveCustomer(t, b.Customers, "dup@example.com")
+		gone := saveCustomer(t, b.Customers, "gone@example.com")
+
+		moved, err := b.Contacts.Save(customers.Contact{CustomerID: dup.ID, Name: "Sam", Role: customers.RoleBilling, Email: "ap@example.com"})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		purged, err := b.Contacts.Save(customers.Contact{CustomerID: gone.ID, Name: "Kim", Role: customers.RoleDriver, Email: "kim@example.com"})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+
+		if _, err := b.Customers.Merge(survivor, dup.ID); err != nil {
+			t.Fatalf("merge: %v", err)
+		}
+		list, err := b.Contacts.ListByCustomer(survivor.ID)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		assertSequence(t, "survivor contacts", []string{moved.ID}, contactIDs(list))
+
+		if _, err := b.Customers.Delete(gone.ID); err != nil {
+			t.Fatalf("delete customer: %v", err)
+		}
+		if _, err := b.Contacts.FindByID(purged.ID); err != nil {
+			t.Fatalf("soft delete must keep contacts: %v", err)
+		}
+		if _, err := b.Customers.Purge(time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("purge: %v", err)
+		}
+		if _, err := b.Contacts.FindByID(purged.ID); !errors.Is(err, customers.ErrContactNotFound) {
+			t.Fatalf("expected purge to remove contacts, got %v", err)
+		}
+	})
+}
+
+func contactIDs(list []customers.Contact) []string {
+	ids := make([]string, len(list))
+	for i, c := range list {
+		ids[i] = c.ID
+	}
+	return ids
+}
+
+func assertContactEqual(t *testing.T, want, got customers.Contact) {
+	t.Helper()
+	if want.ID != got.ID ||
+		want.CustomerID != got.CustomerID ||
+		want.Name != got.Name ||
+		want.Role != got.Role ||
+		want.Email != got.Email ||
+		want.Phone != got.Phone ||
+		want.PhoneE164 != got.PhoneE164 ||
+		!want.CreatedAt.Equal(got.CreatedAt) ||
+		!want.UpdatedAt.Equal(got.UpdatedAt) {
+		t.Fatalf("contact mismatch:\nwant %+v\ngot  %+v", want, got)
+	}
+}
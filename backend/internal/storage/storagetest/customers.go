@@ -0,0 +1,515 @@
+package storagetest
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
+	"github.com/ezmobilemechanic/platform/internal/domain/quotes"
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+)
+
+// CustomerRepository verifies the customers.Repository contract.
+func CustomerRepository(t *testing.T, newBackend Factory) {
+	t.Run("SaveAssignsIdentity", func(t *testing.T) {
+		repo := newBackend(t).Customers
+
+		saved, err := repo.Save(customers.Customer{
+			FirstName: "Alex",
+			LastName:  "Driver",
+			Email:     "alex@example.com",
+			Phone:     "(904) 555-0101",
+			PhoneE164: "+19045550101",
+			Tags:      []string{"fleet", "net 30"},
+		})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		if saved.ID == "" {
+			t.Fatalf("expected ID to be assigned")
+		}
+		if saved.CreatedAt.IsZero() || !saved.CreatedAt.Equal(saved.UpdatedAt) {
+			t.Fatalf("expected equal non-zero timestamps, got created=%v updated=%v", saved.CreatedAt, saved.UpdatedAt)
+		}
+
+		fetched, err := repo.FindByID(saved.ID)
+		if err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		assertCustomerEqual(t, saved, fetched)
+	})
+
+	t.Run("FindByIDMissing", func(t *testing.T) {
+		repo := newBackend(t).Customers
+
+		for _, id := range []string{missingID, "not-an-id"} {
+			if _, err := repo.FindByID(id); !errors.Is(err, customers.ErrNotFound) {
+				t.Fatalf("find %q: expected ErrNotFound, got %v", id, err)
+			}
+		}
+	})
+
+	t.Run("UpdatePreservesCreatedAt", func(t *testing.T) {
+		repo := newBackend(t).Customers
+
+		saved, err := repo.Save(customers.Customer{FirstName: "Jo", Email: "jo@example.com"})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		tick()
+
+		saved.LastName = "Updated"
+		saved.Tags = []string{"prefers text"}
+		saved.Type = customers.AccountFleet
+		saved.CompanyName = "Jo's Plumbing"
+		saved.PORequired = true
+		saved.Billing = customers.BillingMonthly
+		saved.CreatedAt = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+		updated, err := repo.Save(saved)
+		if err != nil {
+			t.Fatalf("update: %v", err)
+		}
+
+		fetched, err := repo.FindByID(saved.ID)
+		if err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		if !updated.CreatedAt.Equal(fetched.CreatedAt) || fetched.CreatedAt.Year() == 2001 {
+			t.Fatalf("expected stored CreatedAt to be preserved, got %v", fetched.CreatedAt)
+		}
+		if !fetched.UpdatedAt.After(fetched.CreatedAt) {
+			t.Fatalf("expected UpdatedAt to advance past CreatedAt")
+		}
+		assertCustomerEqual(t, updated, fetched)
+	})
+
+	t.Run("UpdateUnknownID", func(t *testing.T) {
+		repo := newBackend(t).Customers
+
+		_, err := repo.Save(customers.Customer{ID: missingID, FirstName: "Ghost"})
+		if !errors.Is(err, customers.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("EmailUnique", func(t *testing.T) {
+		repo := newBackend(t).Customers
+
+		first, err := repo.Save(customers.Customer{FirstName: "A", Email: "dup@example.com"})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		if _, err := repo.Save(customers.Customer{FirstName: "B", Email: "DUP@example.com"}); !errors.Is(err, customers.ErrEmailExists) {
+			t.Fatalf("expected ErrEmailExists, got %v", err)
+		}
+		if _, err := repo.Save(first); err != nil {
+			t.Fatalf("re-saving with own email: %v", err)
+		}
+
+		for i := 0; i < 2; i++ {
+			if _, err := repo.Save(customers.Customer{FirstName: "NoEmail", Phone: "555"}); err != nil {
+				t.Fatalf("blank emails must not collide: %v", err)
+			}
+		}
+	})
+
+	t.Run("ListPagination", func(t *testing.T) {
+		repo := newBackend(t).Customers
+
+		var names []string
+		for i := 0; i < 5; i++ {
+			name := fmt.Sprintf("Customer%d", i)
+			if _, err := repo.Save(customers.Customer{FirstName: name, Email: fmt.Sprintf("c%d@example.com", i)}); err != nil {
+				t.Fatalf("save: %v", err)
+			}
+			names = append(names, name)
+			tick()
+		}
+
+		asc := walkCustomers(t, repo, listing.Spec{Limit: 2, Sort: listing.Sort{Field: listing.SortCreatedAt}})
+		assertSequence(t, "ascending", names, asc)
+
+		desc := walkCustomers(t, repo, listing.Spec{Limit: 2})
+		assertSequence(t, "default", reversed(names), desc)
+	})
+
+	t.Run("ListFilters", func(t *testing.T) {
+		repo := newBackend(t).Customers
+
+		var saved []customers.Customer
+		for _, c := range []customers.Customer{
+			{FirstName: "Alex", LastName: "Driver", Email: "alex@example.com"},
+			{FirstName: "Jordan", LastName: "Mechanic", Email: "jordan@example.com"},
+			{FirstName: "Sam", LastName: "Fleet", Phone: "904-555-0303"},
+		} {
+			s, err := repo.Save(c)
+			if err != nil {
+				t.Fatalf("save: %v", err)
+			}
+			saved = append(saved, s)
+			tick()
+		}
+
+		page, err := repo.List(listing.Spec{Search: "MECHANIC"})
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if len(page.Items) != 1 || page.Items[0].ID != saved[1].ID || page.Total != 1 {
+			t.Fatalf("search: expected only Jordan, got %d items (total %d)", len(page.Items), page.Total)
+		}
+
+		page, err = repo.List(listing.Spec{Search: "555-03"})
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if len(page.Items) != 1 || page.Items[0].ID != saved[2].ID {
+			t.Fatalf("phone search: expected only Sam, got %d items", len(page.Items))
+		}
+
+		page, err = repo.List(listing.Spec{CreatedFrom: saved[1].CreatedAt, CreatedTo: saved[2].CreatedAt})
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if len(page.Items) != 1 || page.Items[0].ID != saved[1].ID {
+			t.Fatalf("created range: expected only Jordan, got %d items", len(page.Items))
+		}
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		b := newBackend(t)
+
+		alex, err := b.Customers.Save(customers.Customer{FirstName: "Alex", LastName: "Driver", Email: "alex@example.com", Phone: "(904) 555-0101", Tags: []string{"vip"}})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		sam, err := b.Customers.Save(customers.Customer{FirstName: "Sam", LastName: "Fleet", Email: "dispatch@fleet.example", Phone: "+1 904.555.0303", Tags: []string{"fleet", "net 30"}})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		if _, err := b.Vehicles.Save(vehicles.Vehicle{CustomerID: sam.ID, VIN: "1FTBW2CM5HKA12345"}); err != nil {
+			t.Fatalf("save vehicle: %v", err)
+		}
+		acme, err := b.Customers.Save(customers.Customer{Type: customers.AccountFleet, CompanyName: "Acme Plumbing", Email: "office@acme.example"})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+
+		for _, tc := range []struct {
+			q    string
+			want []string
+		}{
+			{"alex driver", []string{alex.ID}},
+			{"FLEET", []string{sam.ID}},
+			{"904-555-0303", []string{sam.ID}},
+			{"1 (904) 555-0101", []string{alex.ID}},
+			{"5550", []string{alex.ID, sam.ID}},
+			{"hka12345", []string{sam.ID}},
+			{"acme PLUMB", []string{acme.ID}},
+			{"1FTB W2CM", []string{sam.ID}},
+			{"tag:fleet", []string{sam.ID}},
+			{"TAG: Net  30", []string{sam.ID}},
+			{"tag:vip", []string{alex.ID}},
+			{"tag:fle", nil},
+			{"100%", nil},
+			{"zzzz", nil},
+		} {
+			page, err := b.Customers.List(listing.Spec{Search: tc.q, Sort: listing.Sort{Field: listing.SortCreatedAt}})
+			if err != nil {
+				t.Fatalf("search %q: %v", tc.q, err)
+			}
+			var got []string
+			for _, c := range page.Items {
+				got = append(got, c.ID)
+			}
+			assertSequence(t, fmt.Sprintf("search %q", tc.q), tc.want, got)
+			if page.Total != len(tc.want) {
+				t.Fatalf("search %q: expected total %d, got %d", tc.q, len(tc.want), page.Total)
+			}
+		}
+	})
+
+	t.Run("SoftDelete", func(t *testing.T) {
+		repo := newBackend(t).Customers
+
+		kept, err := repo.Save(customers.Customer{FirstName: "Kept", Email: "kept@example.com"})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		gone, err := repo.Save(customers.Customer{FirstName: "Gone", Email: "gone@example.com"})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+
+		deleted, err := repo.Delete(gone.ID)
+		if err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if deleted.DeletedAt == nil || deleted.ID != gone.ID {
+			t.Fatalf("expected DeletedAt to be set, got %+v", deleted)
+		}
+		if _, err := repo.FindByID(gone.ID); !errors.Is(err, customers.ErrNotFound) {
+			t.Fatalf("find deleted: expected ErrNotFound, got %v", err)
+		}
+		if _, err := repo.Save(deleted); !errors.Is(err, customers.ErrNotFound) {
+			t.Fatalf("save deleted: expected ErrNotFound, got %v", err)
+		}
+		for _, id := range []string{gone.ID, missingID, "not-an-id"} {
+			if _, err := repo.Delete(id); !errors.Is(err, customers.ErrNotFound) {
+				t.Fatalf("delete %q: expected ErrNotFound, got %v", id, err)
+			}
+		}
+
+		page, err := repo.List(listing.Spec{})
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if len(page.Items) != 1 || page.Items[0].ID != kept.ID || page.Total != 1 {
+			t.Fatalf("expected only the active customer to be listed, got %d (total %d)", len(page.Items), page.Total)
+		}
+
+		restored, err := repo.Restore(gone.ID)
+		if err != nil {
+			t.Fatalf("restore: %v", err)
+		}
+		if restored.DeletedAt != nil || !restored.CreatedAt.Equal(gone.CreatedAt) {
+			t.Fatalf("expected restored customer, got %+v", restored)
+		}
+		fetched, err := repo.FindByID(gone.ID)
+		if err != nil {
+			t.Fatalf("find restored: %v", err)
+		}
+		assertCustomerEqual(t, restored, fetched)
+		for _, id := range []string{gone.ID, missingID, "not-an-id"} {
+			if _, err := repo.Restore(id); !errors.Is(err, customers.ErrNotFound) {
+				t.Fatalf("restore %q: expected ErrNotFound, got %v", id, err)
+			}
+		}
+	})
+
+	t.Run("DeletedEmailReusable", func(t *testing.T) {
+		repo := newBackend(t).Customers
+
+		original, err := repo.Save(customers.Customer{FirstName: "Old", Email: "reuse@example.com"})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		if _, err := repo.Delete(original.ID); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if _, err := repo.Save(customers.Customer{FirstName: "New", Email: "REUSE@example.com"}); err != nil {
+			t.Fatalf("expected deleted customer's email to be reusable: %v", err)
+		}
+		if _, err := repo.Restore(original.ID); !errors.Is(err, customers.ErrEmailExists) {
+			t.Fatalf("restore over a taken email: expected ErrEmailExists, got %v", err)
+		}
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		repo := newBackend(t).Customers
+
+		old, err := repo.Save(customers.Customer{FirstName: "Old", Email: "old@example.com"})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		active, err := repo.Save(customers.Customer{FirstName: "Active", Email: "active@example.com"})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		deleted, err := repo.Delete(old.ID)
+		if err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+
+		n, err := repo.Purge(deleted.DeletedAt.Add(-time.Second))
+		if err != nil || n != 0 {
+			t.Fatalf("purge before deletion: expected 0, got %d (%v)", n, err)
+		}
+		n, err = repo.Purge(deleted.DeletedAt.Add(time.Second))
+		if err != nil || n != 1 {
+			t.Fatalf("purge after deletion: expected 1, got %d (%v)", n, err)
+		}
+		if _, err := repo.Restore(old.ID); !errors.Is(err, customers.ErrNotFound) {
+			t.Fatalf("restore purged: expected ErrNotFound, got %v", err)
+		}
+		if _, err := repo.FindByID(active.ID); err != nil {
+			t.Fatalf("active customer must survive purge: %v", err)
+		}
+	})
+
+	t.Run("FindMatches", func(t *testing.T) {
+		repo := newBackend(t).Customers
+
+		var ids []string
+		for _, c := range []customers.Customer{
+			{FirstName: "A", Email: "alex@example.com"},
+			{FirstName: "B", PhoneE164: "+19045550101"},
+			{FirstName: "Alec", LastName: "DRIVER"},
+		} {
+			saved, err := repo.Save(c)
+			if err != nil {
+				t.Fatalf("save: %v", err)
+			}
+			ids = append(ids, saved.ID)
+		}
+		if _, err := repo.Save(customers.Customer{FirstName: "Unrelated", LastName: "Person", Email: "other@example.com", PhoneE164: "+19045550202"}); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		gone, err := repo.Save(customers.Customer{FirstName: "Deleted", LastName: "Driver", PhoneE164: "+19045550101"})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		if _, err := repo.Delete(gone.ID); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+
+		keys := customers.MatchKeys{Email: "ALEX@example.com", PhoneE164: "+19045550101", LastName: "Driver"}
+		find := func(keys customers.MatchKeys) []string {
+			t.Helper()
+			matches, err := repo.FindMatches(keys)
+			if err != nil {
+				t.Fatalf("find matches: %v", err)
+			}
+			var got []string
+			for _, c := range matches {
+				got = append(got, c.ID)
+			}
+			return got
+		}
+
+		want := append([]string(nil), ids...)
+		sort.Strings(want)
+		assertSequence(t, "matches", want, find(keys))
+
+		keys.ExcludeID = ids[2]
+		want = append([]string(nil), ids[:2]...)
+		sort.Strings(want)
+		assertSequence(t, "matches excluding", want, find(keys))
+
+		if got := find(customers.MatchKeys{}); len(got) != 0 {
+			t.Fatalf("empty keys must match nothing, got %v", got)
+		}
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		b := newBackend(t)
+
+		survivor, err := b.Customers.Save(customers.Customer{FirstName: "Alex", LastName: "Driver", Phone: "(904) 555-0101", PhoneE164: "+19045550101"})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		dup, err := b.Customers.Save(customers.Customer{FirstName: "Alex", LastName: "Driver", Email: "alex@example.com"})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		vehicle, err := b.Vehicles.Save(vehicles.Vehicle{CustomerID: dup.ID, Make: "Ford"})
+		if err != nil {
+			t.Fatalf("save vehicle: %v", err)
+		}
+		quote, err := b.Quotes.Save(quotes.Quote{CustomerID: dup.ID, VehicleID: vehicle.ID, Status: quotes.StatusDraft})
+		if err != nil {
+			t.Fatalf("save quote: %v", err)
+		}
+
+		if _, err := b.Customers.Merge(survivor, missingID); !errors.Is(err, customers.ErrNotFound) {
+			t.Fatalf("merge missing: expected ErrNotFound, got %v", err)
+		}
+
+		survivor.Email = dup.Email
+		m, err := b.Customers.Merge(survivor, dup.ID)
+		if err != nil {
+			t.Fatalf("merge: %v", err)
+		}
+		if m.MergedID != dup.ID || m.SurvivorID != survivor.ID || m.MergedAt.IsZero() {
+			t.Fatalf("unexpected merge record %+v", m)
+		}
+		assertSequence(t, "vehicle ids", []string{vehicle.ID}, m.VehicleIDs)
+		assertSequence(t, "quote ids", []string{quote.ID}, m.QuoteIDs)
+
+		fetched, err := b.Customers.FindByID(survivor.ID)
+		if err != nil {
+			t.Fatalf("find survivor: %v", err)
+		}
+		if fetched.Email != dup.Email || fetched.PhoneE164 != survivor.PhoneE164 {
+			t.Fatalf("expected survivor to take the merged email, got %+v", fetched)
+		}
+		if _, err := b.Customers.FindByID(dup.ID); !errors.Is(err, customers.ErrNotFound) {
+			t.Fatalf("find merged: expected ErrNotFound, got %v", err)
+		}
+		if v, err := b.Vehicles.FindByID(vehicle.ID); err != nil || v.CustomerID != survivor.ID {
+			t.Fatalf("expected vehicle to move to survivor, got %+v (%v)", v, err)
+		}
+		if q, err := b.Quotes.FindByID(quote.ID); err != nil || q.CustomerID != survivor.ID {
+			t.Fatalf("expected quote to move to survivor, got %+v (%v)", q, err)
+		}
+
+		if id, err := b.Customers.MergedInto(dup.ID); err != nil || id != survivor.ID {
+			t.Fatalf("merged into: expected %s, got %q (%v)", survivor.ID, id, err)
+		}
+		if _, err := b.Customers.MergedInto(survivor.ID); !errors.Is(err, customers.ErrNotFound) {
+			t.Fatalf("unmerged id: expected ErrNotFound, got %v", err)
+		}
+
+		final, err := b.Customers.Save(customers.Customer{FirstName: "Final"})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		if _, err := b.Customers.Merge(final, survivor.ID); err != nil {
+			t.Fatalf("second merge: %v", err)
+		}
+		if id, err := b.Customers.MergedInto(dup.ID); err != nil || id != final.ID {
+			t.Fatalf("chained merge: expected %s, got %q (%v)", final.ID, id, err)
+		}
+	})
+
+	t.Run("ListInvalidCursor", func(t *testing.T) {
+		repo := newBackend(t).Customers
+
+		if _, err := repo.List(listing.Spec{Cursor: "garbage"}); !errors.Is(err, listing.ErrInvalidCursor) {
+			t.Fatalf("expected ErrInvalidCursor, got %v", err)
+		}
+	})
+}
+
+func walkCustomers(t *testing.T, repo customers.Repository, spec listing.Spec) []string {
+	t.Helper()
+	var out []string
+	for {
+		page, err := repo.List(spec)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		for _, c := range page.Items {
+			out = append(out, c.FirstName)
+		}
+		if !page.HasMore {
+			return out
+		}
+		spec.Cursor = page.NextCursor
+	}
+}
+
+func assertCustomerEqual(t *testing.T, want, got customers.Customer) {
+	t.Helper()
+	if want.ID != got.ID ||
+		want.ExternalID != got.ExternalID ||
+		want.FirstName != got.FirstName ||
+		want.LastName != got.LastName ||
+		want.Email != got.Email ||
+		want.Phone != got.Phone ||
+		want.PhoneE164 != got.PhoneE164 ||
+		!slices.Equal(want.Tags, got.Tags) ||
+		want.Type != got.Type ||
+		want.CompanyName != got.CompanyName ||
+		want.PORequired != got.PORequired ||
+		want.Billing != got.Billing ||
+		(want.DeletedAt == nil) != (got.DeletedAt == nil) ||
+		!want.CreatedAt.Equal(got.CreatedAt) ||
+		!want.UpdatedAt.Equal(got.UpdatedAt) {
+		t.Fatalf("customer mismatch:\nwant %+v\n got %+v", want, got)
+	}
+}
@@ -0,0 +1,134 @@
+package storagetest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/privacy"
+)
+
+// ErasureRequestRepository verifies the privacy.Repository contract.
+func ErasureRequestRepository(t *testing.T, newBackend Factory) {
+	t.Run("SaveFindList", func(t *testing.T) {
+		b := newBackend(t)
+		owner := saveCustomer(t, b.Customers, "owner@example.com")
+		other := saveCustomer(t, b.Customers, "other@example.com")
+
+		first, err := b.Erasures.Save(pendingErasure(owner.ID, time.Now()))
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		if first.ID == "" {
+			t.Fatalf("expected ID, got %+v", first)
+		}
+		got, err := b.Erasures.FindByID(first.ID)
+		if err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		assertErasureEqual(t, first, got)
+
+		second, err := b.Erasures.Save(pendingErasure(other.ID, time.Now().Add(time.Minute)))
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+
+		closed := time.Now().UTC().Add(2 * time.Minute).Truncate(time.Microsecond)
+		first.Status = privacy.StatusRejected
+		first.ClosedAt = &closed
+		first.Events = append(first.Events, privacy.Event{At: closed, Action: privacy.ActionRejected, Actor: "privacy-officer", Detail: "identity not verified"})
+		if _, err := b.Erasures.Save(first); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		got, err = b.Erasures.FindByID(first.ID)
+		if err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		assertErasureEqual(t, first, got)
+
+		all, err := b.Erasures.List("")
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		assertSequence(t, "all requests", []string{first.ID, second.ID}, erasureIDs(all))
+		pending, err := b.Erasures.List(privacy.StatusPending)
+		if err != nil {
+			t.Fatalf("list pending: %v", err)
+		}
+		assertSequence(t, "pending requests", []string{second.ID}, erasureIDs(pending))
+		mine, err := b.Erasures.ListByCustomer(owner.ID)
+		if err != nil {
+			t.Fatalf("list by customer: %v", err)
+		}
+		assertSequence(t, "customer requests", []string{first.ID}, erasureIDs(mine))
+
+		if _, err := b.Erasures.FindByID(missingID); !errors.Is(err, privacy.ErrNotFound) {
+			t.Fatalf("find missing: expected ErrNotFound, got %v", err)
+		}
+		missing := pendingErasure(owner.ID, time.Now())
+		missing.ID = missingID
+		if _, err := b.Erasures.Save(missing); !errors.Is(err, privacy.ErrNotFound) {
+			t.Fatalf("update missing: expected ErrNotFound, got %v", err)
+		}
+		if list, err := b.Erasures.ListByCustomer(missingID); err != nil || len(list) != 0 {
+			t.Fatalf("list: expected no requests, got %v (%v)", list, err)
+		}
+	})
+
+	t.Run("OutlivePurge", func(t *testing.T) {
+		b := newBackend(t)
+		gone := saveCustomer(t, b.Customers, "gone@example.com")
+		req, err := b.Erasures.Save(pendingErasure(gone.ID, time.Now()))
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		if _, err := b.Customers.Delete(gone.ID); err != nil {
+			t.Fatalf("delete customer: %v", err)
+		}
+		if _, err := b.Customers.Purge(time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("purge: %v", err)
+		}
+		if _, err := b.Erasures.FindByID(req.ID); err != nil {
+			t.Fatalf("purge must keep erasure requests: %v", err)
+		}
+	})
+}
+
+func pendingErasure(customerID string, at time.Time) privacy.ErasureRequest {
+	at = at.UTC().Truncate(time.Microsecond)
+	return privacy.ErasureRequest{
+		CustomerID:  customerID,
+		Status:      privacy.StatusPending,
+		RequestedBy: "front-desk",
+		Reason:      "emailed privacy@",
+		RequestedAt: at,
+		DueAt:       at.Add(privacy.ResponseWindow),
+		Events:      []privacy.Event{{At: at, Action: privacy.ActionRequested, Actor: "front-desk", Detail: "emailed privacy@"}},
+	}
+}
+
+func assertErasureEqual(t *testing.T, want, got privacy.ErasureRequest) {
+	t.Helper()
+	same := want.ID == got.ID && want.CustomerID == got.CustomerID && want.Status == got.Status &&
+		want.RequestedBy == got.RequestedBy && want.Reason == got.Reason &&
+		want.RequestedAt.Equal(got.RequestedAt) && want.DueAt.Equal(got.DueAt) &&
+		(want.ClosedAt == nil) == (got.ClosedAt == nil) && len(want.Events) == len(got.Events)
+	if same && want.ClosedAt != nil {
+		same = want.ClosedAt.Equal(*got.ClosedAt)
+	}
+	for i := 0; same && i < len(want.Events); i++ {
+		w, g := want.Events[i], got.Events[i]
+		same = w.At.Equal(g.At) && w.Action == g.Action && w.Actor == g.Actor && w.Detail == g.Detail
+	}
+	if !same {
+		t.Fatalf("erasure request mismatch:\nwant %+v\ngot  %+v", want, got)
+	}
+}
+
+func erasureIDs(list []privacy.ErasureRequest) []string {
+	ids := make([]string, len(list))
+	for i, r := range list {
+		ids[i] = r.ID
+	}
+	return ids
+}
@@ -0,0 +1,114 @@
+package storagetest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+)
+
+// NoteRepository verifies the customers.NoteRepository contract and how
+// notes follow their customer through Merge and Purge.
+func NoteRepository(t *testing.T, newBackend Factory) {
+	t.Run("AddFindListDelete", func(t *testing.T) {
+		b := newBackend(t)
+		owner := saveCustomer(t, b.Customers, "owner@example.com")
+
+		first, err := b.Notes.Add(customers.Note{CustomerID: owner.ID, Kind: customers.NoteKindNote, Body: "Gate code 4411", Author: "dispatch"})
+		if err != nil {
+			t.Fatalf("add: %v", err)
+		}
+		if first.ID == "" || first.CreatedAt.IsZero() {
+			t.Fatalf("expected ID and CreatedAt, got %+v", first)
+		}
+		got, err := b.Notes.FindByID(first.ID)
+		if err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		assertNoteEqual(t, first, got)
+
+		tick()
+		call, err := b.Notes.Add(customers.Note{CustomerID: owner.ID, Kind: customers.NoteKindCall, Body: "Asked to move to Friday"})
+		if err != nil {
+			t.Fatalf("add: %v", err)
+		}
+
+		list, err := b.Notes.ListByCustomer(owner.ID)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		assertSequence(t, "notes", []string{first.ID, call.ID}, noteIDs(list))
+
+		if err := b.Notes.Delete(first.ID); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if _, err := b.Notes.FindByID(first.ID); !errors.Is(err, customers.ErrNoteNotFound) {
+			t.Fatalf("expected ErrNoteNotFound after delete, got %v", err)
+		}
+		if err := b.Notes.Delete(first.ID); !errors.Is(err, customers.ErrNoteNotFound) {
+			t.Fatalf("delete twice: expected ErrNoteNotFound, got %v", err)
+		}
+		if list, err := b.Notes.ListByCustomer(missingID); err != nil || len(list) != 0 {
+			t.Fatalf("list: expected no notes, got %v (%v)", list, err)
+		}
+	})
+
+	t.Run("FollowCustomer", func(t *testing.T) {
+		b := newBackend(t)
+		survivor := saveCustomer(t, b.Customers, "survivor@example.com")
+		dup := saveCustomer(t, b.Customers, "dup@example.com")
+		gone := saveCustomer(t, b.Customers, "gone@example.com")
+
+		moved, err := b.Notes.Add(customers.Note{CustomerID: dup.ID, Kind: customers.NoteKindNote, Body: "Second account"})
+		if err != nil {
+			t.Fatalf("add: %v", err)
+		}
+		purged, err := b.Notes.Add(customers.Note{CustomerID: gone.ID, Kind: customers.NoteKindEmail, Body: "Sent invoice"})
+		if err != nil {
+			t.Fatalf("add: %v", err)
+		}
+
+		if _, err := b.Customers.Merge(survivor, dup.ID); err != nil {
+			t.Fatalf("merge: %v", err)
+		}
+		list, err := b.Notes.ListByCustomer(survivor.ID)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		assertSequence(t, "survivor notes", []string{moved.ID}, noteIDs(list))
+
+		if _, err := b.Customers.Delete(gone.ID); err != nil {
+			t.Fatalf("delete customer: %v", err)
+		}
+		if _, err := b.Notes.FindByID(purged.ID); err != nil {
+			t.Fatalf("soft delete must keep notes: %v", err)
+		}
+		if _, err := b.Customers.Purge(time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("purge: %v", err)
+		}
+		if _, err := b.Notes.FindByID(purged.ID); !errors.Is(err, customers.ErrNoteNotFound) {
+			t.Fatalf("expected purge to remove notes, got %v", err)
+		}
+	})
+}
+
+func noteIDs(list []customers.Note) []string {
+	ids := make([]string, len(list))
+	for i, n := range list {
+		ids[i] = n.ID
+	}
+	return ids
+}
+
+func assertNoteEqual(t *testing.T, want, got customers.Note) {
+	t.Helper()
+	if want.ID != got.ID ||
+		want.CustomerID != got.CustomerID ||
+		want.Kind != got.Kind ||
+		want.Body != got.Body ||
+		want.Author != got.Author ||
+		!want.CreatedAt.Equal(got.CreatedAt) {
+		t.Fatalf("note mismatch:\nwant %+v\ngot  %+v", want, got)
+	}
+}
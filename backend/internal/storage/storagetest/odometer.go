@@ -0,0 +1,101 @@
+package storagetest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+)
+
+// OdometerRepository verifies the vehicles.OdometerRepository contract.
+func OdometerRepository(t *testing.T, newBackend Factory) {
+	t.Run("AddListFlag", func(t *testing.T) {
+		b := newBackend(t)
+		owner := saveCustomer(t, b.Customers, "owner@example.com")
+		vehicle, err := b.Vehicles.Save(vehicles.Vehicle{CustomerID: owner.ID, Make: "Ford"})
+		if err != nil {
+			t.Fatalf("save vehicle: %v", err)
+		}
+
+		day := time.Date(2025, 3, 14, 9, 30, 0, 123456000, time.UTC)
+		later, err := b.Odometer.Add(vehicles.Reading{
+			VehicleID: vehicle.ID,
+			Mileage:   42000,
+			Source:    vehicles.SourceTechnician,
+			ReadAt:    day,
+			Note:      "dash photo",
+		})
+		if err != nil {
+			t.Fatalf("add: %v", err)
+		}
+		if later.ID == "" || later.RecordedAt.IsZero() {
+			t.Fatalf("expected ID and RecordedAt, got %+v", later)
+		}
+		tick()
+		// Recorded second but read first: invoices are often entered late.
+		earlier, err := b.Odometer.Add(vehicles.Reading{
+			VehicleID: vehicle.ID,
+			Mileage:   39000,
+			Source:    vehicles.SourceInvoice,
+			ReadAt:    day.AddDate(0, -2, 0),
+		})
+		if err != nil {
+			t.Fatalf("add: %v", err)
+		}
+
+		list, err := b.Odometer.ListByVehicle(vehicle.ID)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if len(list) != 2 {
+			t.Fatalf("expected 2 readings, got %d", len(list))
+		}
+		assertReadingEqual(t, earlier, list[0])
+		assertReadingEqual(t, later, list[1])
+
+		flagged, err := b.Odometer.SetFlagged(later.ID, true)
+		if err != nil {
+			t.Fatalf("flag: %v", err)
+		}
+		later.Flagged = true
+		assertReadingEqual(t, later, flagged)
+		list, err = b.Odometer.ListByVehicle(vehicle.ID)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		assertReadingEqual(t, later, list[1])
+
+		for _, id := range []string{missingID, "not-an-id"} {
+			if _, err := b.Odometer.SetFlagged(id, true); !errors.Is(err, vehicles.ErrReadingNotFound) {
+				t.Fatalf("flag %q: expected ErrReadingNotFound, got %v", id, err)
+			}
+		}
+	})
+
+	t.Run("ListUnknownVehicle", func(t *testing.T) {
+		b := newBackend(t)
+
+		list, err := b.Odometer.ListByVehicle(missingID)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if list == nil || len(list) != 0 {
+			t.Fatalf("expected an empty list, got %#v", list)
+		}
+	})
+}
+
+func assertReadingEqual(t *testing.T, want, got vehicles.Reading) {
+	t.Helper()
+	if want.ID != got.ID ||
+		want.VehicleID != got.VehicleID ||
+		want.Mileage != got.Mileage ||
+		want.Source != got.Source ||
+		!want.ReadAt.Equal(got.ReadAt) ||
+		want.Note != got.Note ||
+		want.Flagged != got.Flagged ||
+		!want.RecordedAt.Equal(got.RecordedAt) {
+		t.Fatalf("reading mismatch:\nwant %+v\n got %+v", want, got)
+	}
+}
@@ -0,0 +1,57 @@
+package storagetest
+
+import (
+	"testing"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/quotes"
+)
+
+// QuoteHistoryRepository verifies the quotes.HistoryRepository contract.
+func QuoteHistoryRepository(t *testing.T, newBackend Factory) {
+	t.Run("AppendList", func(t *testing.T) {
+		b := newBackend(t)
+		owner := saveCustomer(t, b.Customers, "owner@example.com")
+		quote, err := b.Quotes.Save(quotes.Quote{CustomerID: owner.ID, Status: quotes.StatusDraft})
+		if err != nil {
+			t.Fatalf("save quote: %v", err)
+		}
+		other, err := b.Quotes.Save(quotes.Quote{CustomerID: owner.ID, Status: quotes.StatusDraft})
+		if err != nil {
+			t.Fatalf("save quote: %v", err)
+		}
+
+		sent, err := b.QuoteHistory.Append(quotes.StatusChange{QuoteID: quote.ID, From: quotes.StatusDraft, To: quotes.StatusSent, Actor: "sam", Reason: "Customer asked by text"})
+		if err != nil {
+			t.Fatalf("append: %v", err)
+		}
+		if sent.ID == "" || sent.ChangedAt.IsZero() {
+			t.Fatalf("expected ID and ChangedAt, got %+v", sent)
+		}
+		tick()
+		accepted, err := b.QuoteHistory.Append(quotes.StatusChange{QuoteID: quote.ID, From: quotes.StatusSent, To: quotes.StatusAccepted})
+		if err != nil {
+			t.Fatalf("append: %v", err)
+		}
+		if _, err := b.QuoteHistory.Append(quotes.StatusChange{QuoteID: other.ID, From: quotes.StatusDraft, To: quotes.StatusDeclined}); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+
+		list, err := b.QuoteHistory.ListByQuote(quote.ID)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		ids := make([]string, len(list))
+		for i, c := range list {
+			ids[i] = c.ID
+		}
+		assertSequence(t, "status changes", []string{sent.ID, accepted.ID}, ids)
+		got := list[0]
+		if got.QuoteID != quote.ID || got.From != quotes.StatusDraft || got.To != quotes.StatusSent || got.Actor != "sam" || got.Reason != "Customer asked by text" || !got.ChangedAt.Equal(sent.ChangedAt) {
+			t.Fatalf("status change mismatch:\nwant %+v\ngot  %+v", sent, got)
+		}
+
+		if list, err := b.QuoteHistory.ListByQuote(missingID); err != nil || len(list) != 0 {
+			t.Fatalf("list: expected no changes, got %v (%v)", list, err)
+		}
+	})
+}
@@ -0,0 +1,349 @@
+package storagetest
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
+	"github.com/ezmobilemechanic/platform/internal/domain/quotes"
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+)
+
+// QuoteRepository verifies the quotes.Repository contract.
+func QuoteRepository(t *testing.T, newBackend Factory) {
+	t.Run("SaveAssignsLineItems", func(t *testing.T) {
+		b := newBackend(t)
+		owner := saveCustomer(t, b.Customers, "owner@example.com")
+		vehicle, err := b.Vehicles.Save(vehicles.Vehicle{CustomerID: owner.ID, Make: "Ford"})
+		if err != nil {
+			t.Fatalf("save vehicle: %v", err)
+		}
+
+		saved, err := b.Quotes.Save(quotes.Quote{
+			CustomerID:     owner.ID,
+			VehicleID:      vehicle.ID,
+			Status:         quotes.StatusDraft,
+			PartsAmount:    39000,
+			LaborAmount:    46875,
+			FeesAmount:     1500,
+			DiscountAmount: 5000,
+			TaxAmount:      2730,
+			TotalAmount:    85105,
+			TechnicianTier: "master",
+			// Technicians write multi-line notes.
+			TechnicianNotes: "Front pads at 2mm.\nRotors scored, replaced.",
+			PONumber:        "PO-1042",
+			ContactID:       "dispatch",
+			LineItems: []quotes.LineItem{
+				{Description: "Brake Pads", Quantity: 1, UnitPrice: 15000, LaborHours: 1.5, Category: "brakes", LaborRate: 12500, LaborAmount: 18750},
+				{Description: "Rotor", Quantity: 2, UnitPrice: 12000, LaborHours: 2.25, Category: "brakes", LaborRate: 12500, LaborAmount: 28125},
+			},
+		})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		if saved.ID == "" || saved.CreatedAt.IsZero() {
+			t.Fatalf("expected ID and timestamps to be assigned")
+		}
+		for idx, item := range saved.LineItems {
+			if item.ID == "" || item.QuoteID != saved.ID || item.SortOrder != idx {
+				t.Fatalf("line item %d not assigned: %+v", idx, item)
+			}
+		}
+
+		fetched, err := b.Quotes.FindByID(saved.ID)
+		if err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		assertQuoteEqual(t, saved, fetched)
+	})
+
+	t.Run("SaveWithoutVehicle", func(t *testing.T) {
+		b := newBackend(t)
+		owner := saveCustomer(t, b.Customers, "owner@example.com")
+
+		saved, err := b.Quotes.Save(quotes.Quote{CustomerID: owner.ID, Status: quotes.StatusDraft})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		fetched, err := b.Quotes.FindByID(saved.ID)
+		if err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		if fetched.VehicleID != "" || len(fetched.LineItems) != 0 {
+			t.Fatalf("expected empty vehicle and no line items, got %+v", fetched)
+		}
+	})
+
+	t.Run("FindByIDMissing", func(t *testing.T) {
+		b := newBackend(t)
+
+		for _, id := range []string{missingID, "not-an-id"} {
+			if _, err := b.Quotes.FindByID(id); !errors.Is(err, quotes.ErrNotFound) {
+				t.Fatalf("find %q: expected ErrNotFound, got %v", id, err)
+			}
+		}
+	})
+
+	t.Run("UpdateReplacesLineItems", func(t *testing.T) {
+		b := newBackend(t)
+		owner := saveCustomer(t, b.Customers, "owner@example.com")
+
+		saved, err := b.Quotes.Save(quotes.Quote{
+			CustomerID: owner.ID,
+			Status:     quotes.StatusDraft,
+			LineItems:  []quotes.LineItem{{Description: "Old", Quantity: 1}},
+		})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		tick()
+
+		saved.Status = quotes.StatusSent
+		saved.TechnicianNotes = "Customer supplied parts"
+		saved.LineItems = []quotes.LineItem{{Description: "New A", Quantity: 1}, {Description: "New B", Quantity: 3}}
+		updated, err := b.Quotes.Save(saved)
+		if err != nil {
+			t.Fatalf("update: %v", err)
+		}
+
+		fetched, err := b.Quotes.FindByID(saved.ID)
+		if err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		if fetched.Status != quotes.StatusSent || !fetched.UpdatedAt.After(fetched.CreatedAt) {
+			t.Fatalf("expected status and UpdatedAt to change, got %+v", fetched)
+		}
+		assertQuoteEqual(t, updated, fetched)
+	})
+
+	t.Run("UpdateUnknownID", func(t *testing.T) {
+		b := newBackend(t)
+		owner := saveCustomer(t, b.Customers, "owner@example.com")
+
+		_, err := b.Quotes.Save(quotes.Quote{ID: missingID, CustomerID: owner.ID, Status: quotes.StatusDraft})
+		if !errors.Is(err, quotes.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("UpdateStatus", func(t *testing.T) {
+		b := newBackend(t)
+		owner := saveCustomer(t, b.Customers, "owner@example.com")
+
+		saved, err := b.Quotes.Save(quotes.Quote{
+			CustomerID: owner.ID,
+			Status:     quotes.StatusDraft,
+			LineItems:  []quotes.LineItem{{Description: "Brake pads", Quantity: 1, UnitPrice: 8999}},
+		})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		tick()
+
+		saved.TechnicianNotes = "Sent by text"
+		updated, err := b.Quotes.UpdateStatus(saved, quotes.StatusChange{QuoteID: saved.ID, From: quotes.StatusDraft, To: quotes.StatusSent, Actor: "sam", Reason: "Customer asked"})
+		if err != nil {
+			t.Fatalf("update status: %v", err)
+		}
+		if updated.Status != quotes.StatusSent || !updated.UpdatedAt.After(updated.CreatedAt) {
+			t.Fatalf("expected status and UpdatedAt to change, got %+v", updated)
+		}
+		fetched, err := b.Quotes.FindByID(saved.ID)
+		if err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		assertQuoteEqual(t, updated, fetched)
+		changes, err := b.QuoteHistory.ListByQuote(saved.ID)
+		if err != nil || len(changes) != 1 || changes[0].To != quotes.StatusSent || changes[0].Actor != "sam" || changes[0].Reason != "Customer asked" {
+			t.Fatalf("expected the change recorded, got %+v, %v", changes, err)
+		}
+
+		// A change made from a status the quote has left saves nothing.
+		fetched.TechnicianNotes = "Voided twice"
+		_, err = b.Quotes.UpdateStatus(fetched, quotes.StatusChange{QuoteID: saved.ID, From: quotes.StatusDraft, To: quotes.StatusVoid, Reason: "Duplicate"})
+		if !errors.Is(err, quotes.ErrStatusChanged) {
+			t.Fatalf("expected ErrStatusChanged, got %v", err)
+		}
+		if again, _ := b.Quotes.FindByID(saved.ID); again.Status != quotes.StatusSent || again.TechnicianNotes != "Sent by text" {
+			t.Fatalf("expected the stale change to save nothing, got %+v", again)
+		}
+		if changes, _ := b.QuoteHistory.ListByQuote(saved.ID); len(changes) != 1 {
+			t.Fatalf("expected one change, got %+v", changes)
+		}
+
+		// An edit keeping the status is saved under the same check, without
+		// recording a change.
+		fetched.TechnicianNotes = "Edited after a race"
+		_, err = b.Quotes.UpdateStatus(fetched, quotes.StatusChange{QuoteID: saved.ID, From: quotes.StatusDraft, To: quotes.StatusDraft})
+		if !errors.Is(err, quotes.ErrStatusChanged) {
+			t.Fatalf("expected ErrStatusChanged for a stale edit, got %v", err)
+		}
+		if again, _ := b.Quotes.FindByID(saved.ID); again.Status != quotes.StatusSent || again.TechnicianNotes != "Sent by text" {
+			t.Fatalf("expected the stale edit to save nothing, got %+v", again)
+		}
+		edit := updated
+		edit.PONumber = "PO-7"
+		edited, err := b.Quotes.UpdateStatus(edit, quotes.StatusChange{QuoteID: saved.ID, From: quotes.StatusSent, To: quotes.StatusSent})
+		if err != nil {
+			t.Fatalf("edit: %v", err)
+		}
+		if again, _ := b.Quotes.FindByID(saved.ID); again.Status != quotes.StatusSent || again.PONumber != "PO-7" || !again.UpdatedAt.Equal(edited.UpdatedAt) {
+			t.Fatalf("expected the edit saved, got %+v", again)
+		}
+		if changes, _ := b.QuoteHistory.ListByQuote(saved.ID); len(changes) != 1 {
+			t.Fatalf("expected an edit to record no change, got %+v", changes)
+		}
+
+		for _, id := range []string{missingID, "not-an-id"} {
+			_, err := b.Quotes.UpdateStatus(quotes.Quote{ID: id, CustomerID: owner.ID}, quotes.StatusChange{QuoteID: id, From: quotes.StatusDraft, To: quotes.StatusSent})
+			if !errors.Is(err, quotes.ErrNotFound) {
+				t.Fatalf("update %q: expected ErrNotFound, got %v", id, err)
+			}
+		}
+	})
+
+	t.Run("ListByCustomer", func(t *testing.T) {
+		b := newBackend(t)
+		owner := saveCustomer(t, b.Customers, "owner@example.com")
+		other := saveCustomer(t, b.Customers, "other@example.com")
+
+		var all, sent []string
+		for i := 0; i < 5; i++ {
+			desc := fmt.Sprintf("Job%d", i)
+			status := quotes.StatusDraft
+			if i%2 == 0 {
+				status = quotes.StatusSent
+				sent = append(sent, desc)
+			}
+			if _, err := b.Quotes.Save(quotes.Quote{
+				CustomerID: owner.ID,
+				Status:     status,
+				LineItems:  []quotes.LineItem{{Description: desc, Quantity: 1}},
+			}); err != nil {
+				t.Fatalf("save: %v", err)
+			}
+			all = append(all, desc)
+			tick()
+		}
+		if _, err := b.Quotes.Save(quotes.Quote{CustomerID: other.ID, Status: quotes.StatusDraft}); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+
+		assertSequence(t, "default", reversed(all), walkQuotes(t, b.Quotes, owner.ID, listing.Spec{Limit: 2}))
+		assertSequence(t, "ascending", all, walkQuotes(t, b.Quotes, owner.ID, listing.Spec{Limit: 2, Sort: listing.Sort{Field: listing.SortCreatedAt}}))
+		assertSequence(t, "status", reversed(sent), walkQuotes(t, b.Quotes, owner.ID, listing.Spec{Limit: 2, Status: string(quotes.StatusSent)}))
+		assertSequence(t, "search", []string{"Job3"}, walkQuotes(t, b.Quotes, owner.ID, listing.Spec{Search: "job3"}))
+
+		page, err := b.Quotes.ListByCustomer(missingID, listing.Spec{})
+		if err != nil {
+			t.Fatalf("list unknown customer: %v", err)
+		}
+		if len(page.Items) != 0 || page.Total != 0 {
+			t.Fatalf("expected empty page for unknown customer")
+		}
+	})
+
+	t.Run("ListByVehicle", func(t *testing.T) {
+		b := newBackend(t)
+		seller := saveCustomer(t, b.Customers, "seller@example.com")
+		buyer := saveCustomer(t, b.Customers, "buyer@example.com")
+		vehicle, err := b.Vehicles.Save(vehicles.Vehicle{CustomerID: seller.ID, Make: "Ford"})
+		if err != nil {
+			t.Fatalf("save vehicle: %v", err)
+		}
+		other, err := b.Vehicles.Save(vehicles.Vehicle{CustomerID: seller.ID, Make: "Honda"})
+		if err != nil {
+			t.Fatalf("save vehicle: %v", err)
+		}
+
+		var want []quotes.Quote
+		for _, owner := range []string{seller.ID, buyer.ID} {
+			q, err := b.Quotes.Save(quotes.Quote{
+				CustomerID: owner,
+				VehicleID:  vehicle.ID,
+				Status:     quotes.StatusAccepted,
+				LineItems:  []quotes.LineItem{{Description: "Oil change", Quantity: 1, UnitPrice: 5000}},
+			})
+			if err != nil {
+				t.Fatalf("save: %v", err)
+			}
+			want = append(want, q)
+			tick()
+		}
+		if _, err := b.Quotes.Save(quotes.Quote{CustomerID: seller.ID, VehicleID: other.ID, Status: quotes.StatusDraft}); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+
+		list, err := b.Quotes.ListByVehicle(vehicle.ID)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if len(list) != len(want) {
+			t.Fatalf("expected %d quotes, got %d", len(want), len(list))
+		}
+		for i := range want {
+			assertQuoteEqual(t, want[i], list[i])
+		}
+
+		for _, id := range []string{missingID, "not-an-id"} {
+			empty, err := b.Quotes.ListByVehicle(id)
+			if err != nil {
+				t.Fatalf("list %q: %v", id, err)
+			}
+			if len(empty) != 0 {
+				t.Fatalf("list %q: expected no quotes, got %d", id, len(empty))
+			}
+		}
+	})
+}
+
+func walkQuotes(t *testing.T, repo quotes.Repository, customerID string, spec listing.Spec) []string {
+	t.Helper()
+	var out []string
+	for {
+		page, err := repo.ListByCustomer(customerID, spec)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		for _, q := range page.Items {
+			if len(q.LineItems) == 0 {
+				t.Fatalf("expected listed quotes to include line items")
+			}
+			out = append(out, q.LineItems[0].Description)
+		}
+		if !page.HasMore {
+			return out
+		}
+		spec.Cursor = page.NextCursor
+	}
+}
+
+func assertQuoteEqual(t *testing.T, want, got quotes.Quote) {
+	t.Helper()
+	if want.ID != got.ID ||
+		want.CustomerID != got.CustomerID ||
+		want.VehicleID != got.VehicleID ||
+		want.Status != got.Status ||
+		want.PartsAmount != got.PartsAmount ||
+		want.LaborAmount != got.LaborAmount ||
+		want.FeesAmount != got.FeesAmount ||
+		want.DiscountAmount != got.DiscountAmount ||
+		want.TaxAmount != got.TaxAmount ||
+		want.TotalAmount != got.TotalAmount ||
+		want.TechnicianTier != got.TechnicianTier ||
+		want.TechnicianNotes != got.TechnicianNotes ||
+		want.PONumber != got.PONumber ||
+		want.ContactID != got.ContactID ||
+		!want.CreatedAt.Equal(got.CreatedAt) ||
+		!want.UpdatedAt.Equal(got.UpdatedAt) ||
+		len(want.LineItems) != len(got.LineItems) {
+		t.Fatalf("quote mismatch:\nwant %+v\n got %+v", want, got)
+	}
+	for i := range want.LineItems {
+		if want.LineItems[i] != got.LineItems[i] {
+			t.Fatalf("line item %d mismatch:\nwant %+v\n got %+v", i, want.LineItems[i], got.LineItems[i])
+		}
+	}
+}
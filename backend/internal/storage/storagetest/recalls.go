@@ -0,0 +1,145 @@
+package storagetest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+)
+
+// RecallRepository verifies the vehicles.RecallRepository contract.
+func RecallRepository(t *testing.T, newBackend Factory) {
+	t.Run("SaveReplaceListDelete", func(t *testing.T) {
+		b := newBackend(t)
+		owner := saveCustomer(t, b.Customers, "owner@example.com")
+		vehicle, err := b.Vehicles.Save(vehicles.Vehicle{CustomerID: owner.ID, Make: "Ford"})
+		if err != nil {
+			t.Fatalf("save vehicle: %v", err)
+		}
+
+		informed, err := b.Recalls.Save(vehicles.RecallMark{
+			VehicleID:  vehicle.ID,
+			BulletinID: "23V123000",
+			Status:     vehicles.RecallInformed,
+			Note:       "told at drop-off",
+			CustomerID: owner.ID,
+		})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		if informed.MarkedAt.IsZero() {
+			t.Fatalf("expected MarkedAt, got %+v", informed)
+		}
+		tick()
+		tsb, err := b.Recalls.Save(vehicles.RecallMark{
+			VehicleID:  vehicle.ID,
+			BulletinID: "10201234",
+			Status:     vehicles.RecallAddressed,
+			CustomerID: owner.ID,
+		})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+
+		list, err := b.Recalls.ListByVehicle(vehicle.ID)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if len(list) != 2 {
+			t.Fatalf("expected 2 marks, got %+v", list)
+		}
+		assertRecallMarkEqual(t, informed, list[0])
+		assertRecallMarkEqual(t, tsb, list[1])
+
+		// Marking the recall again replaces the mark.
+		tick()
+		addressed, err := b.Recalls.Save(vehicles.RecallMark{
+			VehicleID:  vehicle.ID,
+			BulletinID: "23V123000",
+			Status:     vehicles.RecallAddressed,
+			Note:       "dealer invoice seen",
+			CustomerID: owner.ID,
+		})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		list, err = b.Recalls.ListByVehicle(vehicle.ID)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if len(list) != 2 {
+			t.Fatalf("expected the mark to be replaced, got %+v", list)
+		}
+		assertRecallMarkEqual(t, tsb, list[0])
+		assertRecallMarkEqual(t, addressed, list[1])
+
+		if err := b.Recalls.Delete(vehicle.ID, "10201234"); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		for _, id := range []string{vehicle.ID, missingID, "not-an-id"} {
+			if err := b.Recalls.Delete(id, "10201234"); !errors.Is(err, vehicles.ErrRecallMarkNotFound) {
+				t.Fatalf("delete %q: expected ErrRecallMarkNotFound, got %v", id, err)
+			}
+		}
+		list, err = b.Recalls.ListByVehicle(vehicle.ID)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if len(list) != 1 {
+			t.Fatalf("expected 1 mark after delete, got %+v", list)
+		}
+	})
+
+	t.Run("FollowMergedCustomer", func(t *testing.T) {
+		b := newBackend(t)
+		survivor := saveCustomer(t, b.Customers, "survivor@example.com")
+		dup := saveCustomer(t, b.Customers, "dup@example.com")
+		vehicle, err := b.Vehicles.Save(vehicles.Vehicle{CustomerID: dup.ID, Make: "Ford"})
+		if err != nil {
+			t.Fatalf("save vehicle: %v", err)
+		}
+		if _, err := b.Recalls.Save(vehicles.RecallMark{
+			VehicleID:  vehicle.ID,
+			BulletinID: "23V123000",
+			Status:     vehicles.RecallInformed,
+			CustomerID: dup.ID,
+		}); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+
+		if _, err := b.Customers.Merge(survivor, dup.ID); err != nil {
+			t.Fatalf("merge: %v", err)
+		}
+		list, err := b.Recalls.ListByVehicle(vehicle.ID)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if len(list) != 1 || list[0].CustomerID != survivor.ID {
+			t.Fatalf("expected the mark to move to the survivor, got %+v", list)
+		}
+	})
+
+	t.Run("ListUnknownVehicle", func(t *testing.T) {
+		b := newBackend(t)
+
+		list, err := b.Recalls.ListByVehicle(missingID)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if list == nil || len(list) != 0 {
+			t.Fatalf("expected an empty list, got %#v", list)
+		}
+	})
+}
+
+func assertRecallMarkEqual(t *testing.T, want, got vehicles.RecallMark) {
+	t.Helper()
+	if want.VehicleID != got.VehicleID ||
+		want.BulletinID != got.BulletinID ||
+		want.Status != got.Status ||
+		want.Note != got.Note ||
+		want.CustomerID != got.CustomerID ||
+		!want.MarkedAt.Equal(got.MarkedAt) {
+		t.Fatalf("recall mark mismatch:\nwant %+v\n got %+v", want, got)
+	}
+}
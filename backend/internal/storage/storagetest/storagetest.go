@@ -0,0 +1,82 @@
+// Package storagetest is a conformance suite for repository implementations.
+// Every storage backend runs the same tests so that DATA_BACKEND can be
+// switched without changing observable behaviour.
+package storagetest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/quotes"
+	"github.com/ezmobilemechanic/platform/internal/domain/users"
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+	"github.com/ezmobilemechanic/platform/internal/privacy"
+)
+
+// Backend bundles the repositories of one storage implementation. Suites for
+// vehicles and quotes use Customers (and Vehicles) to create parent rows so
+// that backends with foreign keys can be exercised.
+type Backend struct {
+	Customers    customers.Repository
+	Addresses    customers.AddressRepository
+	Consents     customers.ConsentRepository
+	Notes        customers.NoteRepository
+	Contacts     customers.ContactRepository
+	Vehicles     vehicles.Repository
+	Odometer     vehicles.OdometerRepository
+	Recalls      vehicles.RecallRepository
+	Quotes       quotes.Repository
+	QuoteHistory quotes.HistoryRepository
+	Users        users.Repository
+	Erasures     privacy.Repository
+}
+
+// Factory returns a Backend with empty storage. It is called once per
+// subtest; implementations should register cleanup with t.Cleanup.
+type Factory func(t *testing.T) Backend
+
+// Run executes every repository suite against the backend.
+func Run(t *testing.T, newBackend Factory) {
+	t.Run("Customers", func(t *testing.T) { CustomerRepository(t, newBackend) })
+	t.Run("Addresses", func(t *testing.T) { AddressRepository(t, newBackend) })
+	t.Run("Consents", func(t *testing.T) { ConsentRepository(t, newBackend) })
+	t.Run("Notes", func(t *testing.T) { NoteRepository(t, newBackend) })
+	t.Run("Contacts", func(t *testing.T) { ContactRepository(t, newBackend) })
+	t.Run("Vehicles", func(t *testing.T) { VehicleRepository(t, newBackend) })
+	t.Run("Odometer", func(t *testing.T) { OdometerRepository(t, newBackend) })
+	t.Run("Recalls", func(t *testing.T) { RecallRepository(t, newBackend) })
+	t.Run("Quotes", func(t *testing.T) { QuoteRepository(t, newBackend) })
+	t.Run("QuoteHistory", func(t *testing.T) { QuoteHistoryRepository(t, newBackend) })
+	t.Run("Users", func(t *testing.T) { UserRepository(t, newBackend) })
+	t.Run("Erasures", func(t *testing.T) { ErasureRequestRepository(t, newBackend) })
+}
+
+// missingID is a well-formed identifier that no backend will have issued.
+const missingID = "00000000-0000-4000-8000-000000000000"
+
+// tick waits long enough for the next write to receive a distinct timestamp
+// at microsecond precision, so ordering assertions do not depend on IDs.
+func tick() {
+	time.Sleep(2 * time.Millisecond)
+}
+
+func reversed(in []string) []string {
+	out := make([]string, len(in))
+	for i, v := range in {
+		out[len(in)-1-i] = v
+	}
+	return out
+}
+
+func assertSequence(t *testing.T, label string, want, got []string) {
+	t.Helper()
+	if len(want) != len(got) {
+		t.Fatalf("%s: expected %v, got %v", label, want, got)
+	}
+	for i := range want {
+		if want[i] != got[i] {
+			t.Fatalf("%s: expected %v, got %v", label, want, got)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package storagetest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/users"
+)
+
+// UserRepository verifies the users.Repository contract.
+func UserRepository(t *testing.T, newBackend Factory) {
+	t.Run("SaveAndFind", func(t *testing.T) {
+		repo := newBackend(t).Users
+
+		saved, err := repo.Save(users.User{
+			Email:        "Tech@Example.com",
+			Name:         "Tech",
+			PasswordHash: "hash",
+			PasswordSalt: "salt",
+		})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		if saved.ID == "" || saved.CreatedAt.IsZero() {
+			t.Fatalf("expected ID and timestamps to be assigned")
+		}
+		if saved.Email != "tech@example.com" {
+			t.Fatalf("expected email to be stored lowercased, got %q", saved.Email)
+		}
+
+		byID, err := repo.FindByID(saved.ID)
+		if err != nil {
+			t.Fatalf("find by id: %v", err)
+		}
+		assertUserEqual(t, saved, byID)
+
+		byEmail, err := repo.FindByEmail("TECH@example.com")
+		if err != nil {
+			t.Fatalf("find by email: %v", err)
+		}
+		assertUserEqual(t, saved, byEmail)
+	})
+
+	t.Run("FindMissing", func(t *testing.T) {
+		repo := newBackend(t).Users
+
+		for _, id := range []string{missingID, "not-an-id"} {
+			if _, err := repo.FindByID(id); !errors.Is(err, users.ErrNotFound) {
+				t.Fatalf("find %q: expected ErrNotFound, got %v", id, err)
+			}
+		}
+		if _, err := repo.FindByEmail("nobody@example.com"); !errors.Is(err, users.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("EmailUnique", func(t *testing.T) {
+		repo := newBackend(t).Users
+
+		if _, err := repo.Save(users.User{Email: "dup@example.com", PasswordHash: "h", PasswordSalt: "s"}); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		_, err := repo.Save(users.User{Email: "DUP@example.com", PasswordHash: "h", PasswordSalt: "s"})
+		if !errors.Is(err, users.ErrEmailExists) {
+			t.Fatalf("expected ErrEmailExists, got %v", err)
+		}
+	})
+
+	t.Run("UpdatePreservesCreatedAt", func(t *testing.T) {
+		repo := newBackend(t).Users
+
+		saved, err := repo.Save(users.User{Email: "u@example.com", Name: "Before", PasswordHash: "h", PasswordSalt: "s"})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		tick()
+
+		saved.Name = "After"
+		updated, err := repo.Save(saved)
+		if err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		fetched, err := repo.FindByID(saved.ID)
+		if err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		if fetched.Name != "After" || !fetched.UpdatedAt.After(fetched.CreatedAt) {
+			t.Fatalf("expected name and UpdatedAt to change, got %+v", fetched)
+		}
+		assertUserEqual(t, updated, fetched)
+	})
+
+	t.Run("UpdateUnknownID", func(t *testing.T) {
+		repo := newBackend(t).Users
+
+		_, err := repo.Save(users.User{ID: missingID, Email: "ghost@example.com", PasswordHash: "h", PasswordSalt: "s"})
+		if !errors.Is(err, users.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+func assertUserEqual(t *testing.T, want, got users.User) {
+	t.Helper()
+	if want.ID != got.ID ||
+		want.Email != got.Email ||
+		want.Name != got.Name ||
+		want.PasswordHash != got.PasswordHash ||
+		want.PasswordSalt != got.PasswordSalt ||
+		!want.CreatedAt.Equal(got.CreatedAt) ||
+		!want.UpdatedAt.Equal(got.UpdatedAt) {
+		t.Fatalf("user mismatch:\nwant %+v\n got %+v", want, got)
+	}
+}
@@ -0,0 +1,284 @@
+package storagetest
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+)
+
+// VehicleRepository verifies the vehicles.Repository contract.
+func VehicleRepository(t *testing.T, newBackend Factory) {
+	t.Run("SaveAndFind", func(t *testing.T) {
+		b := newBackend(t)
+		owner := saveCustomer(t, b.Customers, "owner@example.com")
+
+		saved, err := b.Vehicles.Save(vehicles.Vehicle{
+			CustomerID: owner.ID,
+			VIN:        "1FTNE14W67DA12345",
+			Year:       2017,
+			Make:       "Ford",
+			Model:      "Transit",
+			Trim:       "250",
+			Engine:     "3.7L V6",
+			Color:      "Oxford White",
+			Plate:      "FL ABC1234",
+			UnitNumber: "Van 12",
+			Mileage:    120000,
+		})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		if saved.ID == "" || saved.CreatedAt.IsZero() {
+			t.Fatalf("expected ID and timestamps to be assigned")
+		}
+
+		fetched, err := b.Vehicles.FindByID(saved.ID)
+		if err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		assertVehicleEqual(t, saved, fetched)
+	})
+
+	t.Run("FindByIDMissing", func(t *testing.T) {
+		b := newBackend(t)
+
+		for _, id := range []string{missingID, "not-an-id"} {
+			if _, err := b.Vehicles.FindByID(id); !errors.Is(err, vehicles.ErrNotFound) {
+				t.Fatalf("find %q: expected ErrNotFound, got %v", id, err)
+			}
+		}
+	})
+
+	t.Run("UpdatePreservesCreatedAt", func(t *testing.T) {
+		b := newBackend(t)
+		owner := saveCustomer(t, b.Customers, "owner@example.com")
+
+		saved, err := b.Vehicles.Save(vehicles.Vehicle{CustomerID: owner.ID, Make: "Honda", Mileage: 10})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		tick()
+
+		saved.Mileage = 20
+		saved.CreatedAt = time.Time{}
+		updated, err := b.Vehicles.Save(saved)
+		if err != nil {
+			t.Fatalf("update: %v", err)
+		}
+
+		fetched, err := b.Vehicles.FindByID(saved.ID)
+		if err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		if fetched.CreatedAt.IsZero() || !fetched.UpdatedAt.After(fetched.CreatedAt) {
+			t.Fatalf("expected CreatedAt preserved and UpdatedAt advanced, got %v / %v", fetched.CreatedAt, fetched.UpdatedAt)
+		}
+		assertVehicleEqual(t, updated, fetched)
+	})
+
+	t.Run("UpdateUnknownID", func(t *testing.T) {
+		b := newBackend(t)
+		owner := saveCustomer(t, b.Customers, "owner@example.com")
+
+		_, err := b.Vehicles.Save(vehicles.Vehicle{ID: missingID, CustomerID: owner.ID})
+		if !errors.Is(err, vehicles.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("ArchiveRoundTrip", func(t *testing.T) {
+		b := newBackend(t)
+		owner := saveCustomer(t, b.Customers, "owner@example.com")
+
+		saved, err := b.Vehicles.Save(vehicles.Vehicle{CustomerID: owner.ID, Make: "Honda"})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		at := time.Now().UTC().Truncate(time.Microsecond)
+		saved.ArchivedAt = &at
+		archived, err := b.Vehicles.Save(saved)
+		if err != nil {
+			t.Fatalf("archive: %v", err)
+		}
+		fetched, err := b.Vehicles.FindByID(saved.ID)
+		if err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		assertVehicleEqual(t, archived, fetched)
+
+		list, err := b.Vehicles.ListByCustomer(owner.ID)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if len(list) != 1 || list[0].ArchivedAt == nil {
+			t.Fatalf("expected the archived vehicle listed, got %+v", list)
+		}
+
+		fetched.ArchivedAt = nil
+		restored, err := b.Vehicles.Save(fetched)
+		if err != nil {
+			t.Fatalf("unarchive: %v", err)
+		}
+		fetched, err = b.Vehicles.FindByID(saved.ID)
+		if err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		assertVehicleEqual(t, restored, fetched)
+	})
+
+	t.Run("Transfer", func(t *testing.T) {
+		b := newBackend(t)
+		seller := saveCustomer(t, b.Customers, "seller@example.com")
+		buyer := saveCustomer(t, b.Customers, "buyer@example.com")
+
+		saved, err := b.Vehicles.Save(vehicles.Vehicle{CustomerID: seller.ID, Make: "Ford", Mileage: 50000, UnitNumber: "Van 12"})
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		reading := vehicles.Reading{VehicleID: saved.ID, Mileage: 51200, Source: vehicles.SourceTransfer, ReadAt: time.Now().UTC().Truncate(time.Microsecond), Note: "private sale"}
+
+		if _, err := b.Vehicles.Transfer(vehicles.Transfer{VehicleID: saved.ID, FromCustomerID: buyer.ID, ToCustomerID: seller.ID}, []vehicles.Reading{reading}); !errors.Is(err, vehicles.ErrNotFound) {
+			t.Fatalf("transfer from non-owner: expected ErrNotFound, got %v", err)
+		}
+		if readings, err := b.Odometer.ListByVehicle(saved.ID); err != nil || len(readings) != 0 {
+			t.Fatalf("expected a refused transfer to add no readings, got %+v, %v", readings, err)
+		}
+		if _, err := b.Vehicles.Transfer(vehicles.Transfer{VehicleID: missingID, FromCustomerID: seller.ID, ToCustomerID: buyer.ID}, nil); !errors.Is(err, vehicles.ErrNotFound) {
+			t.Fatalf("transfer unknown vehicle: expected ErrNotFound, got %v", err)
+		}
+
+		first, err := b.Vehicles.Transfer(vehicles.Transfer{
+			VehicleID:      saved.ID,
+			FromCustomerID: seller.ID,
+			ToCustomerID:   buyer.ID,
+			Mileage:        51200,
+			Note:           "private sale",
+		}, []vehicles.Reading{reading})
+		if err != nil {
+			t.Fatalf("transfer: %v", err)
+		}
+		if first.ID == "" || first.TransferredAt.IsZero() {
+			t.Fatalf("expected ID and time to be assigned, got %+v", first)
+		}
+
+		moved, err := b.Vehicles.FindByID(saved.ID)
+		if err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		if moved.CustomerID != buyer.ID || moved.Mileage != 51200 || moved.UnitNumber != "" {
+			t.Fatalf("expected vehicle with buyer at 51200 and no unit number, got %+v", moved)
+		}
+		readings, err := b.Odometer.ListByVehicle(saved.ID)
+		if err != nil || len(readings) != 1 || readings[0].ID == "" || readings[0].Mileage != 51200 || readings[0].Source != vehicles.SourceTransfer {
+			t.Fatalf("expected the handover reading recorded, got %+v, %v", readings, err)
+		}
+		tick()
+
+		// A lower reading at handover never winds the odometer back.
+		if _, err := b.Vehicles.Transfer(vehicles.Transfer{VehicleID: saved.ID, FromCustomerID: buyer.ID, ToCustomerID: seller.ID, Mileage: 100}, nil); err != nil {
+			t.Fatalf("transfer back: %v", err)
+		}
+		moved, err = b.Vehicles.FindByID(saved.ID)
+		if err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		if moved.CustomerID != seller.ID || moved.Mileage != 51200 {
+			t.Fatalf("expected vehicle back with seller at 51200, got %+v", moved)
+		}
+
+		history, err := b.Vehicles.ListTransfers(saved.ID)
+		if err != nil {
+			t.Fatalf("list transfers: %v", err)
+		}
+		if len(history) != 2 {
+			t.Fatalf("expected 2 transfers, got %d", len(history))
+		}
+		if history[0].ID != first.ID || history[0].Note != "private sale" || history[0].Mileage != 51200 ||
+			!history[0].TransferredAt.Equal(first.TransferredAt) {
+			t.Fatalf("transfer mismatch:\nwant %+v\n got %+v", first, history[0])
+		}
+		if history[1].FromCustomerID != buyer.ID || history[1].ToCustomerID != seller.ID {
+			t.Fatalf("expected the transfer back second, got %+v", history[1])
+		}
+
+		empty, err := b.Vehicles.ListTransfers(missingID)
+		if err != nil {
+			t.Fatalf("list transfers for unknown vehicle: %v", err)
+		}
+		if len(empty) != 0 {
+			t.Fatalf("expected no transfers for unknown vehicle, got %d", len(empty))
+		}
+	})
+
+	t.Run("ListByCustomer", func(t *testing.T) {
+		b := newBackend(t)
+		ownerA := saveCustomer(t, b.Customers, "a@example.com")
+		ownerB := saveCustomer(t, b.Customers, "b@example.com")
+
+		var want []string
+		for i := 0; i < 3; i++ {
+			label := fmt.Sprintf("Make%d", i)
+			if _, err := b.Vehicles.Save(vehicles.Vehicle{CustomerID: ownerA.ID, Make: label}); err != nil {
+				t.Fatalf("save: %v", err)
+			}
+			want = append(want, label)
+			tick()
+		}
+		if _, err := b.Vehicles.Save(vehicles.Vehicle{CustomerID: ownerB.ID, Make: "Other"}); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+
+		list, err := b.Vehicles.ListByCustomer(ownerA.ID)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		var got []string
+		for _, v := range list {
+			got = append(got, v.Make)
+		}
+		assertSequence(t, "oldest first", want, got)
+
+		empty, err := b.Vehicles.ListByCustomer(missingID)
+		if err != nil {
+			t.Fatalf("list unknown customer: %v", err)
+		}
+		if len(empty) != 0 {
+			t.Fatalf("expected no vehicles for unknown customer, got %d", len(empty))
+		}
+	})
+}
+
+func saveCustomer(t *testing.T, repo customers.Repository, email string) customers.Customer {
+	t.Helper()
+	c, err := repo.Save(customers.Customer{FirstName: "Owner", Email: email})
+	if err != nil {
+		t.Fatalf("save customer: %v", err)
+	}
+	return c
+}
+
+func assertVehicleEqual(t *testing.T, want, got vehicles.Vehicle) {
+	t.Helper()
+	if want.ID != got.ID ||
+		want.CustomerID != got.CustomerID ||
+		want.VIN != got.VIN ||
+		want.Year != got.Year ||
+		want.Make != got.Make ||
+		want.Model != got.Model ||
+		want.Trim != got.Trim ||
+		want.Engine != got.Engine ||
+		want.Color != got.Color ||
+		want.Plate != got.Plate ||
+		want.UnitNumber != got.UnitNumber ||
+		want.Mileage != got.Mileage ||
+		(want.ArchivedAt == nil) != (got.ArchivedAt == nil) ||
+		(want.ArchivedAt != nil && !want.ArchivedAt.Equal(*got.ArchivedAt)) ||
+		!want.CreatedAt.Equal(got.CreatedAt) ||
+		!want.UpdatedAt.Equal(got.UpdatedAt) {
+		t.Fatalf("vehicle mismatch:\nwant %+v\n got %+v", want, got)
+	}
+}
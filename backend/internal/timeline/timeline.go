@@ -0,0 +1,242 @@
+// Package timeline merges everything recorded about a customer (quotes and
+// their status changes, vehicles, notes, logged communications and consent
+// changes) into one chronological history for the dispatcher.
+package timeline
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
+	"github.com/ezmobilemechanic/platform/internal/domain/quotes"
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+)
+
+// Kind classifies timeline events.
+type Kind string
+
+const (
+	KindQuoteCreated  Kind = "quote_created"
+	KindQuoteStatus   Kind = "quote_status"
+	KindVehicleAdded  Kind = "vehicle_added"
+	KindNote          Kind = "note"
+	KindCommunication Kind = "communication"
+	KindConsent       Kind = "consent"
+)
+
+// Event is one entry on a customer's timeline.
+type Event struct {
+	// ID is unique across kinds and stable, so it can break ties in cursors.
+	ID   string
+	Kind Kind
+	At   time.Time
+	// Summary is a one-line description for display. Notes and
+	// communications carry their full text.
+	Summary string
+	// RefID is the ID of the quote, vehicle, note or consent entry the event
+	// is about.
+	RefID string
+	// Author is set on notes, communications and quote status changes.
+	Author string
+}
+
+// SortKey exposes the time and ID of an event for paginators. Events have a
+// single time, so both sort fields order by it.
+func SortKey(e Event, _ listing.SortField) (time.Time, string) {
+	return e.At, e.ID
+}
+
+// Service builds customer timelines.
+type Service interface {
+	// ForCustomer returns a page of the customer's events. The spec's sort
+	// direction, cursor, limit and created range (applied to event times) are
+	// honoured. Merged customer IDs resolve to the survivor.
+	ForCustomer(customerID string, spec listing.Spec) (listing.Page[Event], error)
+}
+
+// NewService builds a timeline over the domain services.
+func NewService(c customers.Service, v vehicles.Service, q quotes.Service) Service {
+	return &service{customers: c, vehicles: v, quotes: q}
+}
+
+type service struct {
+	customers customers.Service
+	vehicles  vehicles.Service
+	quotes    quotes.Service
+}
+
+func (s *service) ForCustomer(customerID string, spec listing.Spec) (listing.Page[Event], error) {
+	c, err := s.customers.Get(customerID)
+	if err != nil {
+		return listing.Page[Event]{}, err
+	}
+
+	var events []Event
+	for _, collect := range []func(string) ([]Event, error){s.quoteEvents, s.vehicleEvents, s.noteEvents, s.consentEvents} {
+		list, err := collect(c.ID)
+		if err != nil {
+			return listing.Page[Event]{}, err
+		}
+		for _, e := range list {
+			if spec.MatchesCreated(e.At) {
+				events = append(events, e)
+			}
+		}
+	}
+	return listing.Paginate(events, spec, SortKey)
+}
+
+func (s *service) quoteEvents(customerID string) ([]Event, error) {
+	var events []Event
+	spec := listing.Spec{Limit: listing.MaxLimit, Sort: listing.Sort{Field: listing.SortCreatedAt}}
+	for {
+		page, err := s.quotes.ListForCustomer(customerID, spec)
+		if err != nil {
+			return nil, err
+		}
+		for _, q := range page.Items {
+			events = append(events, Event{
+				ID:      string(KindQuoteCreated) + ":" + q.ID,
+				Kind:    KindQuoteCreated,
+				At:      q.CreatedAt,
+				Summary: fmt.Sprintf("Quote created: %s, %s", formatCents(q.TotalAmount), plural(len(q.LineItems), "item")),
+				RefID:   q.ID,
+			})
+			// Stores without a status log still show the quote itself.
+			changes, err := s.quotes.StatusHistory(q.ID)
+			if err != nil && !errors.Is(err, quotes.ErrNotImplemented) {
+				return nil, err
+			}
+			for _, ch := range changes {
+				summary := fmt.Sprintf("Quote %s (was %s)", ch.To, ch.From)
+				if ch.Reason != "" {
+					summary += ": " + ch.Reason
+				}
+				events = append(events, Event{
+					ID:      string(KindQuoteStatus) + ":" + ch.ID,
+					Kind:    KindQuoteStatus,
+					At:      ch.ChangedAt,
+					Summary: summary,
+					RefID:   q.ID,
+					Author:  ch.Actor,
+				})
+			}
+		}
+		if !page.HasMore {
+			return events, nil
+		}
+		spec.Cursor = page.NextCursor
+	}
+}
+
+func (s *service) vehicleEvents(customerID string) ([]Event, error) {
+	list, err := s.vehicles.ListForCustomer(customerID, true)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]Event, 0, len(list))
+	for _, v := range list {
+		summary := "Vehicle added: " + vehicleName(v)
+		if v.VIN != "" {
+			summary += " (VIN " + v.VIN + ")"
+		}
+		events = append(events, Event{
+			ID:      string(KindVehicleAdded) + ":" + v.ID,
+			Kind:    KindVehicleAdded,
+			At:      v.CreatedAt,
+			Summary: summary,
+			RefID:   v.ID,
+		})
+	}
+	return events, nil
+}
+
+func (s *service) noteEvents(customerID string) ([]Event, error) {
+	list, err := s.customers.Notes(customerID)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]Event, 0, len(list))
+	for _, n := range list {
+		e := Event{
+			ID:      string(KindNote) + ":" + n.ID,
+			Kind:    KindNote,
+			At:      n.CreatedAt,
+			Summary: n.Body,
+			RefID:   n.ID,
+			Author:  n.Author,
+		}
+		if n.Kind.IsCommunication() {
+			e.Kind = KindCommunication
+			e.Summary = communicationLabels[n.Kind] + ": " + n.Body
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+func (s *service) consentEvents(customerID string) ([]Event, error) {
+	list, err := s.customers.ConsentHistory(customerID)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]Event, 0, len(list))
+	for _, c := range list {
+		verb := "revoked"
+		if c.Granted {
+			verb = "granted"
+		}
+		summary := fmt.Sprintf("%s %s consent %s (%s", strings.ToUpper(string(c.Channel)), c.Purpose, verb, c.Source)
+		if c.Detail != "" {
+			summary += " " + c.Detail
+		}
+		events = append(events, Event{
+			ID:      string(KindConsent) + ":" + c.ID,
+			Kind:    KindConsent,
+			At:      c.RecordedAt,
+			Summary: summary + ")",
+			RefID:   c.ID,
+		})
+	}
+	return events, nil
+}
+
+var communicationLabels = map[customers.NoteKind]string{
+	customers.NoteKindCall:  "Call",
+	customers.NoteKindSMS:   "SMS",
+	customers.NoteKindEmail: "Email",
+}
+
+func vehicleName(v vehicles.Vehicle) string {
+	var parts []string
+	if v.Year != 0 {
+		parts = append(parts, fmt.Sprint(v.Year))
+	}
+	for _, p := range []string{v.Make, v.Model, v.Trim} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	if len(parts) == 0 {
+		return "unnamed vehicle"
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatCents(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s$%d.%02d", sign, cents/100, cents%100)
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
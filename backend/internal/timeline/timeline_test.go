@@ -0,0 +1,117 @@
+package timeline_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ezmobilemechanic/platform/internal/domain/customers"
+	"github.com/ezmobilemechanic/platform/internal/domain/listing"
+	"github.com/ezmobilemechanic/platform/internal/domain/quotes"
+	"github.com/ezmobilemechanic/platform/internal/domain/vehicles"
+	"github.com/ezmobilemechanic/platform/internal/storage/memory"
+	"github.com/ezmobilemechanic/platform/internal/timeline"
+)
+
+// tick keeps successive records apart so the expected order is unambiguous.
+func tick() { time.Sleep(2 * time.Millisecond) }
+
+func TestForCustomer(t *testing.T) {
+	customerRepo := memory.NewCustomerRepository()
+	vehicleRepo := memory.NewVehicleRepository()
+	quoteRepo := memory.NewQuoteRepository()
+	customerRepo.SetVehicles(vehicleRepo)
+	customerRepo.SetQuotes(quoteRepo)
+
+	customerSvc := customers.NewService(customerRepo,
+		customers.WithConsents(customerRepo.Consents()),
+		customers.WithNotes(customerRepo.Notes()),
+	)
+	vehicleSvc := vehicles.NewService(vehicleRepo)
+	quoteSvc := quotes.NewService(quoteRepo, quotes.WithHistory(quoteRepo.History()))
+	svc := timeline.NewService(customerSvc, vehicleSvc, quoteSvc)
+
+	c, err := customerSvc.Create(customers.CreateInput{FirstName: "Alex", Email: "alex@example.com"})
+	if err != nil {
+		t.Fatalf("create customer: %v", err)
+	}
+	v, err := vehicleSvc.Create(vehicles.CreateInput{CustomerID: c.ID, Year: 2017, Make: "Ford", Model: "Transit"})
+	if err != nil {
+		t.Fatalf("create vehicle: %v", err)
+	}
+	tick()
+	q, err := quoteSvc.Create(quotes.CreateInput{CustomerID: c.ID, VehicleID: v.ID, LineItems: []quotes.CreateLineItem{
+		{Description: "Brake Pads", Quantity: 1, UnitPrice: 15000},
+	}})
+	if err != nil {
+		t.Fatalf("create quote: %v", err)
+	}
+	tick()
+	if _, err := quoteSvc.UpdateStatus(q.ID, quotes.StatusInput{Status: quotes.StatusSent, Actor: "sam"}); err != nil {
+		t.Fatalf("update status: %v", err)
+	}
+	tick()
+	if _, err := customerSvc.AddNote(c.ID, customers.NoteInput{Kind: customers.NoteKindCall, Body: "Confirmed Tuesday", Author: "dispatch"}); err != nil {
+		t.Fatalf("add call: %v", err)
+	}
+	tick()
+	if _, err := customerSvc.RecordConsent(c.ID, customers.ConsentInput{Channel: customers.ChannelSMS, Purpose: customers.PurposeMarketing, Granted: true, Source: customers.SourceStaff}); err != nil {
+		t.Fatalf("record consent: %v", err)
+	}
+	tick()
+	if _, err := customerSvc.AddNote(c.ID, customers.NoteInput{Body: "Gate code 4411"}); err != nil {
+		t.Fatalf("add note: %v", err)
+	}
+
+	want := []timeline.Kind{
+		timeline.KindVehicleAdded,
+		timeline.KindQuoteCreated,
+		timeline.KindQuoteStatus,
+		timeline.KindCommunication,
+		timeline.KindConsent,
+		timeline.KindNote,
+	}
+
+	var got []timeline.Event
+	spec := listing.Spec{Limit: 4, Sort: listing.Sort{Field: listing.SortCreatedAt}}
+	for {
+		page, err := svc.ForCustomer(c.ID, spec)
+		if err != nil {
+			t.Fatalf("timeline: %v", err)
+		}
+		got = append(got, page.Items...)
+		if !page.HasMore {
+			break
+		}
+		spec.Cursor = page.NextCursor
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d events, got %+v", len(want), got)
+	}
+	for i, e := range got {
+		if e.Kind != want[i] {
+			t.Fatalf("event %d: expected %s, got %+v", i, want[i], e)
+		}
+	}
+	if got[1].Summary != "Quote created: $150.00, 1 item" || got[1].RefID != q.ID {
+		t.Fatalf("unexpected quote event %+v", got[1])
+	}
+	if got[2].Summary != "Quote sent (was draft)" || got[2].Author != "sam" {
+		t.Fatalf("unexpected status event %+v", got[2])
+	}
+	if got[3].Summary != "Call: Confirmed Tuesday" || got[3].Author != "dispatch" {
+		t.Fatalf("unexpected communication event %+v", got[3])
+	}
+
+	newest, err := svc.ForCustomer(c.ID, listing.Spec{Limit: 1, Sort: listing.Sort{Field: listing.SortCreatedAt, Desc: true}})
+	if err != nil {
+		t.Fatalf("timeline: %v", err)
+	}
+	if len(newest.Items) != 1 || newest.Items[0].Kind != timeline.KindNote || newest.Total != len(want) {
+		t.Fatalf("unexpected newest page %+v", newest)
+	}
+
+	if _, err := svc.ForCustomer("missing", listing.Spec{}); !errors.Is(err, customers.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,209 @@
+// Package vin validates and decodes 17-character vehicle identification
+// numbers (ISO 3779, 49 CFR 565) without network access. Manufacturer and
+// plant names come from tables embedded in the binary; codes missing from
+// them decode to blank names rather than errors.
+package vin
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalid is returned for VINs of the wrong length, with characters a VIN
+// cannot contain, or whose check digit does not match.
+var ErrInvalid = errors.New("invalid VIN")
+
+// Length is the length of VINs issued from model year 1981.
+const Length = 17
+
+// Info is a decoded VIN.
+type Info struct {
+	VIN string
+	// WMI is the world manufacturer identifier, the first three characters.
+	WMI string
+	// Manufacturer and Make are blank when the WMI is not in the table. Make
+	// is also blank for WMIs shared by several brands.
+	Manufacturer string
+	Make         string
+	Country      string
+	// Region is the continent assigned the WMI's first character.
+	Region string
+	// Year is the model year. Position 10 repeats every 30 years; position 7
+	// tells the cycles apart for cars and light trucks sold in North America.
+	Year int
+	// PlantCode is position 11. Plant is its name when known.
+	PlantCode string
+	Plant     string
+	Serial    string
+}
+
+// Normalize upper-cases s and drops spaces and dashes, as VINs are often
+// written in groups.
+func Normalize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == ' ' || r == '-':
+			return -1
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		}
+		return r
+	}, s)
+}
+
+// Validate checks the length, characters and check digit of a normalized
+// VIN.
+func Validate(v string) error {
+	if len(v) != Length {
+		return fmt.Errorf("%w: must be %d characters, got %d", ErrInvalid, Length, len(v))
+	}
+	for i := 0; i < len(v); i++ {
+		if _, ok := transliterate(v[i]); !ok {
+			return fmt.Errorf("%w: %q is not allowed at position %d", ErrInvalid, v[i], i+1)
+		}
+	}
+	if want := CheckDigit(v); v[8] != want {
+		return fmt.Errorf("%w: check digit is %q, expected %q", ErrInvalid, v[8], want)
+	}
+	return nil
+}
+
+// CheckDigit computes the check digit, position 9, of a VIN with valid
+// characters.
+func CheckDigit(v string) byte {
+	sum := 0
+	for i := 0; i < Length && i < len(v); i++ {
+		n, _ := transliterate(v[i])
+		sum += n * weights[i]
+	}
+	if r := sum % 11; r < 10 {
+		return byte('0' + r)
+	}
+	return 'X'
+}
+
+// Decode validates a VIN and decodes it. The VIN is normalized first.
+func Decode(s string) (Info, error) {
+	v := Normalize(s)
+	if err := Validate(v); err != nil {
+		return Info{}, err
+	}
+	info := Info{
+		VIN:       v,
+		WMI:       v[:3],
+		Region:    region(v[0]),
+		Year:      modelYear(v[9], v[6], time.Now().Year()),
+		PlantCode: v[10:11],
+		Serial:    v[11:],
+	}
+	t := loadTables()
+	if m, ok := t.wmi[info.WMI]; ok {
+		info.Manufacturer, info.Make, info.Country = m.manufacturer, m.make, m.country
+		info.Plant = t.plants[info.Manufacturer+"/"+info.PlantCode]
+	}
+	return info, nil
+}
+
+var weights = [Length]int{8, 7, 6, 5, 4, 3, 2, 10, 0, 9, 8, 7, 6, 5, 4, 3, 2}
+
+// transliterate returns the value of a VIN character. I, O and Q are never
+// used, to avoid confusion with 1 and 0.
+func transliterate(c byte) (int, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0'), true
+	case c >= 'A' && c <= 'H':
+		return int(c-'A') + 1, true
+	case c >= 'J' && c <= 'N':
+		return int(c-'J') + 1, true
+	case c == 'P':
+		return 7, true
+	case c == 'R':
+		return 9, true
+	case c >= 'S' && c <= 'Z':
+		return int(c-'S') + 2, true
+	}
+	return 0, false
+}
+
+// yearCodes lists the position 10 codes from 1980, repeating every 30 years.
+const yearCodes = "ABCDEFGHJKLMNPRSTVWXY123456789"
+
+// modelYear decodes position 10. A letter in position 7 puts North American
+// light vehicles in the 2010 cycle and a digit in the 1980 cycle. Other
+// vehicles do not follow that rule, so years beyond next year fall back a
+// cycle.
+func modelYear(code, pos7 byte, thisYear int) int {
+	i := strings.IndexByte(yearCodes, code)
+	if i < 0 {
+		return 0
+	}
+	year := 1980 + i
+	if pos7 < '0' || pos7 > '9' {
+		year += 30
+	}
+	for year > thisYear+1 {
+		year -= 30
+	}
+	return year
+}
+
+func region(c byte) string {
+	switch {
+	case c >= '1' && c <= '5':
+		return "North America"
+	case c == '6' || c == '7':
+		return "Oceania"
+	case c == '8' || c == '9':
+		return "South America"
+	case c >= 'A' && c <= 'H':
+		return "Africa"
+	case c >= 'J' && c <= 'R':
+		return "Asia"
+	case c >= 'S' && c <= 'Z':
+		return "Europe"
+	}
+	return ""
+}
+
+//go:embed wmi.csv
+var wmiCSV string
+
+//go:embed plants.csv
+var plantsCSV string
+
+type manufacturer struct {
+	manufacturer, make, country string
+}
+
+type tables struct {
+	wmi map[string]manufacturer
+	// plants is keyed by manufacturer and plant code, "Ford/B".
+	plants map[string]string
+}
+
+var loadTables = sync.OnceValue(func() tables {
+	t := tables{wmi: map[string]manufacturer{}, plants: map[string]string{}}
+	for _, rec := range readTable(wmiCSV) {
+		t.wmi[rec[0]] = manufacturer{manufacturer: rec[1], make: rec[2], country: rec[3]}
+	}
+	for _, rec := range readTable(plantsCSV) {
+		t.plants[rec[0]+"/"+rec[1]] = rec[2]
+	}
+	return t
+})
+
+// readTable parses an embedded CSV table, skipping its header. The tables
+// are fixed at build time and covered by tests, so a malformed one panics.
+func readTable(data string) [][]string {
+	records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	if err != nil {
+		panic("vin: embedded table: " + err.Error())
+	}
+	return records[1:]
+}
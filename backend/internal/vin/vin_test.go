@@ -0,0 +1,87 @@
+package vin
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	cases := []struct {
+		in              string
+		make, plant     string
+		year            int
+		country, region string
+	}{
+		{"1FTNE14W67DA12345", "Ford", "Avon Lake, Ohio", 2007, "United States", "North America"},
+		{"5yj-sa1e2-8jf-123456", "Tesla", "Fremont, California", 2018, "United States", "North America"},
+		{"1HGCM82633A004352", "Honda", "", 2003, "United States", "North America"},
+		{"JT2AE92E7H3012345", "Toyota", "", 1987, "Japan", "Asia"},
+		// Not in the table: decodes without names.
+		{"1M8GDM9AXKP042788", "", "", 1989, "", "North America"},
+	}
+	for _, tc := range cases {
+		info, err := Decode(tc.in)
+		if err != nil {
+			t.Fatalf("Decode(%q): %v", tc.in, err)
+		}
+		if info.Make != tc.make || info.Plant != tc.plant || info.Year != tc.year ||
+			info.Country != tc.country || info.Region != tc.region {
+			t.Errorf("Decode(%q) = %+v", tc.in, info)
+		}
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"1FTNE14W67DA1234",   // 16 characters
+		"1FTNE14W67DA123456", // 18 characters
+		"1FTNE14W67DO12345",  // O is never used
+		"1FTNE14W57DA12345",  // wrong check digit
+	} {
+		if _, err := Decode(in); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Decode(%q) error = %v, want ErrInvalid", in, err)
+		}
+	}
+}
+
+func TestModelYear(t *testing.T) {
+	cases := []struct {
+		code, pos7 byte
+		want       int
+	}{
+		{'A', '1', 1980},
+		{'A', 'C', 2010},
+		{'9', 'C', 2039 - 30}, // 2039 is in the future
+		{'R', 'C', 2024},
+		{'S', 'C', 2025},
+		{'T', 'C', 2026},
+		{'V', 'C', 1997}, // 2027 is more than a year away
+		{'I', 'C', 0},
+	}
+	for _, tc := range cases {
+		if got := modelYear(tc.code, tc.pos7, 2025); got != tc.want {
+			t.Errorf("modelYear(%c, %c) = %d, want %d", tc.code, tc.pos7, got, tc.want)
+		}
+	}
+}
+
+func TestTables(t *testing.T) {
+	tables := loadTables()
+	for wmi, m := range tables.wmi {
+		if len(wmi) != 3 || m.manufacturer == "" || m.country == "" {
+			t.Errorf("wmi %q: %+v", wmi, m)
+		}
+	}
+	manufacturers := map[string]bool{}
+	for _, m := range tables.wmi {
+		manufacturers[m.manufacturer] = true
+	}
+	for key, plant := range tables.plants {
+		name, code, _ := strings.Cut(key, "/")
+		if !manufacturers[name] || len(code) != 1 || plant == "" {
+			t.Errorf("plant %q: %q", key, plant)
+		}
+	}
+}